@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/logger"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// autoGenerator regenerates the documentation of a running proxy on a schedule, with the default
+// generate settings, whenever transactions were captured since the last generation
+type autoGenerator struct {
+	storage *captureStorage
+	dataDir string
+	output  string
+
+	captured  atomic.Int64 // transactions captured so far
+	mutex     sync.Mutex
+	generated int64 // transactions captured at the last successful generation, -1 before it
+}
+
+// newAutoGenerator creates a generator writing the documentation of the transactions stored in
+// the data directory to output, a file or an s3:// or gs:// URL
+func newAutoGenerator(storage *captureStorage, dataDir string, output string) *autoGenerator {
+	return &autoGenerator{storage: storage, dataDir: dataDir, output: output, generated: -1}
+}
+
+// intercept counts the transactions passed to an interceptor
+func (a *autoGenerator) intercept(interceptor proxy.APIInterceptor) proxy.APIInterceptor {
+	return func(transaction proxy.APITransaction) {
+		a.captured.Add(1)
+		interceptor(transaction)
+	}
+}
+
+// run generates the documentation every interval until the context is done. The first
+// generation also documents the transactions captured before the proxy started.
+func (a *autoGenerator) run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.generate()
+		}
+	}
+}
+
+// generate writes the stored transactions and generates the documentation from them, unless none
+// were captured since the last generation. Failures are reported and retried on the next run.
+func (a *autoGenerator) generate() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	captured := a.captured.Load()
+	if a.generated >= 0 && captured == a.generated {
+		return
+	}
+	a.storage.Flush()
+	if err := generateDocs(a.output, a.dataDir, generateTitle, generateDescription, generateVersion, generateBasePath, false); err != nil {
+		logger.PrintWarning("Automatic documentation generation failed, retrying at the next run: %v", err)
+		return
+	}
+	a.generated = captured
+}
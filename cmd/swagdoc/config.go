@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/logger"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// configEnvPrefix prefixes the environment variables that set flags, e.g. SWAGDOC_PROXY_TARGET
+const configEnvPrefix = "SWAGDOC"
+
+// applyConfig sets the flags of a command that were not given on the command line from the
+// configuration file and SWAGDOC_* environment variables. Settings live under the name of the
+// command and use the flag names as keys, e.g. generate.tag-mapping or
+// SWAGDOC_GENERATE_TAG_MAPPING; flags given on the command line always win. The settings of a
+// profile, under profiles.<name>, override the top-level ones but not environment variables. It
+// returns the path of the configuration file used, if any
+func applyConfig(cmd *cobra.Command, configPath string, profile string) (string, error) {
+	v := viper.New()
+	v.SetEnvPrefix(configEnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	v.AutomaticEnv()
+
+	if configPath == "" {
+		configPath = os.Getenv(configEnvPrefix + "_CONFIG")
+	}
+	if configPath != "" {
+		v.SetConfigFile(configPath)
+	} else {
+		// Pick up swagdoc.yaml (or .yml, .json, .toml) from the working directory
+		v.SetConfigName("swagdoc")
+		v.AddConfigPath(".")
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if configPath != "" || !errors.As(err, &notFound) {
+			logger.PrintError("Failed to read config file: %v", err)
+			return "", fmt.Errorf("failed to read config file: %v", err)
+		}
+	}
+
+	if profile == "" {
+		profile = os.Getenv(configEnvPrefix + "_PROFILE")
+	}
+	if profile != "" {
+		if err := applyProfile(v, profile); err != nil {
+			logger.PrintError("%v", err)
+			return "", err
+		}
+	}
+
+	section := configSection(cmd)
+	if section == "" {
+		return v.ConfigFileUsed(), nil
+	}
+
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "config" || flag.Name == "profile" || flag.Name == "help" {
+			return
+		}
+		key := section + "." + flag.Name
+		if !v.IsSet(key) {
+			return
+		}
+		if setErr := setFlagFromConfig(cmd.Flags(), flag.Name, v.Get(key)); setErr != nil {
+			logger.PrintError("Invalid value for %s: %v", key, setErr)
+			err = fmt.Errorf("invalid value for %s: %v", key, setErr)
+		}
+	})
+	return v.ConfigFileUsed(), err
+}
+
+// applyProfile merges the settings of a profile over the top-level settings of a configuration,
+// e.g. profiles.staging.proxy.target over proxy.target
+func applyProfile(v *viper.Viper, profile string) error {
+	if v.ConfigFileUsed() == "" {
+		return fmt.Errorf("profile %q needs a config file, none was found", profile)
+	}
+	settings := v.GetStringMap("profiles." + profile)
+	if len(settings) == 0 {
+		var names []string
+		for name := range v.GetStringMap("profiles") {
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("profile %q not found in %s, which defines no profiles", profile, v.ConfigFileUsed())
+		}
+		sort.Strings(names)
+		return fmt.Errorf("profile %q not found in %s, available profiles: %s", profile, v.ConfigFileUsed(), strings.Join(names, ", "))
+	}
+	return v.MergeConfigMap(settings)
+}
+
+// configSection returns the configuration section of a command, the name of the top-level
+// command it belongs to, so that export subcommands share the export section
+func configSection(cmd *cobra.Command) string {
+	for cmd.HasParent() && cmd.Parent().HasParent() {
+		cmd = cmd.Parent()
+	}
+	if !cmd.HasParent() {
+		return ""
+	}
+	return cmd.Name()
+}
+
+// setFlagFromConfig sets a flag to a configured value. Lists set repeatable flags once per
+// item, as if the flag was given multiple times
+func setFlagFromConfig(flags *pflag.FlagSet, name string, value interface{}) error {
+	items, ok := value.([]interface{})
+	if !ok {
+		return flags.Set(name, fmt.Sprint(value))
+	}
+	for _, item := range items {
+		if err := flags.Set(name, fmt.Sprint(item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plainConfigValue matches values written to configuration files without quotes
+var plainConfigValue = regexp.MustCompile(`^[A-Za-z0-9_./-]+$`)
+
+// addConfigValues appends values missing from the list at a key path of a YAML configuration
+// file, e.g. generate, exclude-path, creating the file, sections, and list as needed. Comments
+// and the order of existing settings are kept. It returns the values added.
+func addConfigValues(path string, keys []string, values []string) ([]string, error) {
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %v", err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid config file: expected a mapping of command sections")
+	}
+
+	mapping := root
+	for _, key := range keys[:len(keys)-1] {
+		if mapping, err = yamlMappingValue(mapping, key, yaml.MappingNode); err != nil {
+			return nil, err
+		}
+	}
+	list, err := yamlMappingValue(mapping, keys[len(keys)-1], yaml.SequenceNode)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool)
+	for _, item := range list.Content {
+		existing[item.Value] = true
+	}
+	var added []string
+	for _, value := range values {
+		if existing[value] {
+			continue
+		}
+		existing[value] = true
+		item := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+		if !plainConfigValue.MatchString(value) {
+			// Quote regular expressions so that backslashes stay as they are
+			item.Style = yaml.SingleQuotedStyle
+		}
+		list.Content = append(list.Content, item)
+		added = append(added, value)
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return added, os.WriteFile(path, out.Bytes(), 0644)
+}
+
+// yamlMappingValue returns the value of a key in a YAML mapping, adding an empty value of the
+// given kind when the key is missing. A single scalar value is turned into a list of it.
+func yamlMappingValue(mapping *yaml.Node, key string, kind yaml.Kind) (*yaml.Node, error) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		value := mapping.Content[i+1]
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+			*value = yaml.Node{Kind: kind}
+		} else if value.Kind == yaml.ScalarNode && kind == yaml.SequenceNode {
+			item := *value
+			*value = yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{&item}}
+		}
+		if value.Kind != kind {
+			return nil, fmt.Errorf("invalid config file: unexpected value of %s", key)
+		}
+		return value, nil
+	}
+
+	value := &yaml.Node{Kind: kind}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value, nil
+}
+
+// configWritePath returns the configuration file that commands write settings to: the one
+// given with --config or SWAGDOC_CONFIG, or swagdoc.yaml (or .yml) in the working directory
+func configWritePath() string {
+	if configFile != "" {
+		return configFile
+	}
+	if path := os.Getenv(configEnvPrefix + "_CONFIG"); path != "" {
+		return path
+	}
+	if _, err := os.Stat("swagdoc.yml"); err == nil {
+		return "swagdoc.yml"
+	}
+	return "swagdoc.yaml"
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// configTestCommand returns a generate command under a root command, with flags of the kinds the
+// configuration sets
+func configTestCommand() *cobra.Command {
+	root := &cobra.Command{Use: "swagdoc"}
+	cmd := &cobra.Command{Use: "generate", Run: func(*cobra.Command, []string) {}}
+	cmd.Flags().String("title", "Default", "")
+	cmd.Flags().String("server", "", "")
+	cmd.Flags().Int("port", 8080, "")
+	cmd.Flags().StringArray("exclude-path", nil, "")
+	root.AddCommand(cmd)
+	return cmd
+}
+
+// writeConfigTestFile writes a configuration file to a temporary directory and returns its path
+func writeConfigTestFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "swagdoc.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+// isolateConfigEnv clears the environment variables that pick a configuration
+func isolateConfigEnv(t *testing.T) {
+	t.Setenv(configEnvPrefix+"_CONFIG", "")
+	t.Setenv(configEnvPrefix+"_PROFILE", "")
+}
+
+func TestApplyConfigPrecedence(t *testing.T) {
+	isolateConfigEnv(t)
+	path := writeConfigTestFile(t, `generate:
+  title: File
+  server: file
+  port: 1
+  exclude-path:
+    - ^/internal/
+    - ^/health$
+profiles:
+  staging:
+    generate:
+      title: Profile
+      server: profile
+      port: 2
+`)
+	t.Setenv("SWAGDOC_GENERATE_TITLE", "Env")
+	t.Setenv("SWAGDOC_GENERATE_SERVER", "env")
+
+	// Flags given on the command line win over the environment, which wins over the profile,
+	// which wins over the top-level settings
+	cmd := configTestCommand()
+	require.NoError(t, cmd.Flags().Set("title", "Flag"))
+	used, err := applyConfig(cmd, path, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, path, used)
+
+	flags := cmd.Flags()
+	title, _ := flags.GetString("title")
+	server, _ := flags.GetString("server")
+	port, _ := flags.GetInt("port")
+	excluded, _ := flags.GetStringArray("exclude-path")
+	assert.Equal(t, "Flag", title)
+	assert.Equal(t, "env", server)
+	assert.Equal(t, 2, port)
+	assert.Equal(t, []string{"^/internal/", "^/health$"}, excluded)
+
+	// Without the profile the top-level settings apply
+	cmd = configTestCommand()
+	_, err = applyConfig(cmd, path, "")
+	require.NoError(t, err)
+	port, _ = cmd.Flags().GetInt("port")
+	assert.Equal(t, 1, port)
+
+	// The profile can also be picked from the environment
+	t.Setenv("SWAGDOC_PROFILE", "staging")
+	cmd = configTestCommand()
+	_, err = applyConfig(cmd, path, "")
+	require.NoError(t, err)
+	port, _ = cmd.Flags().GetInt("port")
+	assert.Equal(t, 2, port)
+}
+
+func TestApplyConfigUnknownKeys(t *testing.T) {
+	isolateConfigEnv(t)
+	path := writeConfigTestFile(t, `generate:
+  title: File
+  no-such-flag: ignored
+no-such-command:
+  title: ignored
+`)
+
+	// Keys that name no flag of the command are left alone
+	cmd := configTestCommand()
+	_, err := applyConfig(cmd, path, "")
+	require.NoError(t, err)
+	title, _ := cmd.Flags().GetString("title")
+	assert.Equal(t, "File", title)
+	assert.Nil(t, cmd.Flags().Lookup("no-such-flag"))
+}
+
+func TestApplyConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		env     map[string]string
+		profile string
+		wantErr string
+	}{
+		{
+			name:    "bad value",
+			config:  "generate:\n  port: many\n",
+			wantErr: "invalid value for generate.port",
+		},
+		{
+			name:    "bad list item",
+			config:  "generate:\n  port: [1, many]\n",
+			wantErr: "invalid value for generate.port",
+		},
+		{
+			name:    "bad environment value",
+			config:  "generate:\n  title: File\n",
+			env:     map[string]string{"SWAGDOC_GENERATE_PORT": "many"},
+			wantErr: "invalid value for generate.port",
+		},
+		{
+			name:    "invalid YAML",
+			config:  "generate: [\n",
+			wantErr: "failed to read config file",
+		},
+		{
+			name:    "unknown profile",
+			config:  "profiles:\n  staging:\n    generate:\n      port: 2\n  prod:\n    generate:\n      port: 3\n",
+			profile: "local",
+			wantErr: `profile "local" not found in`,
+		},
+		{
+			name:    "no profiles",
+			config:  "generate:\n  port: 1\n",
+			profile: "staging",
+			wantErr: "defines no profiles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateConfigEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			_, err := applyConfig(configTestCommand(), writeConfigTestFile(t, tt.config), tt.profile)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("available profiles", func(t *testing.T) {
+		isolateConfigEnv(t)
+		path := writeConfigTestFile(t, "profiles:\n  staging: {generate: {port: 2}}\n  prod: {generate: {port: 3}}\n")
+		_, err := applyConfig(configTestCommand(), path, "local")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "available profiles: prod, staging")
+	})
+
+	t.Run("missing config file", func(t *testing.T) {
+		isolateConfigEnv(t)
+		_, err := applyConfig(configTestCommand(), filepath.Join(t.TempDir(), "missing.yaml"), "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read config file")
+	})
+}
+
+func TestAddConfigValues(t *testing.T) {
+	path := writeConfigTestFile(t, `# swagdoc settings
+proxy:
+  target: http://localhost:3000 # the API
+generate:
+  title: Shop API
+  exclude-path: ^/internal/
+`)
+
+	// Values are appended to the existing list, keeping the comments and the order of settings
+	added, err := addConfigValues(path, []string{"generate", "exclude-path"}, []string{"^/internal/", `^/health$`, "/metrics"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`^/health$`, "/metrics"}, added)
+	added, err = addConfigValues(path, []string{"generate", "exclude-method"}, []string{"OPTIONS"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"OPTIONS"}, added)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `# swagdoc settings
+proxy:
+  target: http://localhost:3000 # the API
+generate:
+  title: Shop API
+  exclude-path:
+    - ^/internal/
+    - '^/health$'
+    - /metrics
+  exclude-method:
+    - OPTIONS
+`, string(data))
+
+	// Values already listed leave the file alone
+	added, err = addConfigValues(path, []string{"generate", "exclude-path"}, []string{"/metrics"})
+	require.NoError(t, err)
+	assert.Empty(t, added)
+	unchanged, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, data, unchanged)
+
+	// The added values are read back as the list of the flag
+	isolateConfigEnv(t)
+	cmd := configTestCommand()
+	_, err = applyConfig(cmd, path, "")
+	require.NoError(t, err)
+	excluded, _ := cmd.Flags().GetStringArray("exclude-path")
+	assert.Equal(t, []string{"^/internal/", `^/health$`, "/metrics"}, excluded)
+}
+
+func TestAddConfigValuesNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swagdoc.yaml")
+	added, err := addConfigValues(path, []string{"generate", "exclude-path"}, []string{"^/internal/"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"^/internal/"}, added)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "generate:\n  exclude-path:\n    - '^/internal/'\n", string(data))
+}
+
+func TestAddConfigValuesInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{name: "invalid YAML", config: "generate: [\n", wantErr: "invalid YAML"},
+		{name: "list at the top", config: "- generate\n", wantErr: "expected a mapping of command sections"},
+		{name: "section is a list", config: "generate:\n  - title\n", wantErr: "unexpected value of generate"},
+		{name: "list is a mapping", config: "generate:\n  exclude-path:\n    a: b\n", wantErr: "unexpected value of exclude-path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigTestFile(t, tt.config)
+			_, err := addConfigValues(path, []string{"generate", "exclude-path"}, []string{"/metrics"})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+
+			// The file is left as it was
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.config, string(data))
+		})
+	}
+}
+
+func TestYAMLMappingValue(t *testing.T) {
+	var doc yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte("empty:\nsingle: /metrics\nlist: [a]\n"), &doc))
+	mapping := doc.Content[0]
+
+	// An empty value becomes an empty value of the kind
+	empty, err := yamlMappingValue(mapping, "empty", yaml.SequenceNode)
+	require.NoError(t, err)
+	assert.Equal(t, yaml.SequenceNode, empty.Kind)
+	assert.Empty(t, empty.Content)
+
+	// A single scalar becomes a list of it
+	single, err := yamlMappingValue(mapping, "single", yaml.SequenceNode)
+	require.NoError(t, err)
+	require.Len(t, single.Content, 1)
+	assert.Equal(t, "/metrics", single.Content[0].Value)
+
+	// A missing key is appended after the existing ones
+	added, err := yamlMappingValue(mapping, "missing", yaml.MappingNode)
+	require.NoError(t, err)
+	assert.Equal(t, yaml.MappingNode, added.Kind)
+	assert.Equal(t, "missing", mapping.Content[len(mapping.Content)-2].Value)
+
+	// A value of another kind is an error
+	_, err = yamlMappingValue(mapping, "list", yaml.MappingNode)
+	assert.Error(t, err)
+}
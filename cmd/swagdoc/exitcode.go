@@ -0,0 +1,34 @@
+package main
+
+import "errors"
+
+// Exit codes of failures that pipelines may want to tell apart; any other error exits with 1
+const (
+	exitNoTransactions = 2 // --ci found no captured transactions
+	exitInvalidSpec    = 3 // the generated document is not valid OpenAPI (--ci or --strict)
+	exitSpecChanged    = 4 // --fail-on-diff found the document differs from the existing one
+	exitLintErrors     = 5 // lint found issues with the error severity
+)
+
+// exitError is an error that makes swagdoc exit with a specific code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// exitCode returns the exit code for an error returned by a command
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return 1
+}
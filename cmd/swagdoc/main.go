@@ -1,41 +1,201 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/parnexcodes/swag-doc/pkg/logger"
 	"github.com/parnexcodes/swag-doc/pkg/openapi"
 	"github.com/parnexcodes/swag-doc/pkg/proxy"
+	"github.com/parnexcodes/swag-doc/pkg/publish"
+	"github.com/parnexcodes/swag-doc/pkg/sink"
+	"github.com/parnexcodes/swag-doc/pkg/tui"
+	"github.com/parnexcodes/swag-doc/pkg/update"
 
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
 )
 
 const (
 	defaultDataDir = "./swagdoc-data"
 	version        = "1.0.0"
+
+	// stdoutOutput is the output file that writes to stdout
+	stdoutOutput = "-"
 )
 
 var (
+	// Root command flags
+	configFile string
+	profile    string
+	logFormat  string
+	logLevel   string
+	logFile    string
+	noColor    bool
+
 	// Proxy command flags
-	proxyPort    int
-	proxyTarget  string
-	proxyDataDir string
+	proxyPort       int
+	proxyTarget     string
+	proxyDataDir    string
+	proxyTUI        bool
+	proxyQuiet      bool
+	proxyRedact     []string
+	proxyPprof      string
+	proxySinks      []string
+	proxySinkSource string
+	proxyAutoEvery  time.Duration
+	proxyAutoOutput string
 
 	// Generate command flags
-	generateOutput        string
-	generateDataDir       string
-	generateTitle         string
-	generateDescription   string
-	generateVersion       string
-	generateBasePath      string
-	generateCleanup       bool
-	generateUsePathGroups bool
-	generateTagMapping    []string
-	generateVersionPrefix []string
+	generateOutput          string
+	generateFormat          string
+	generateHTMLBundle      string
+	generateDataDir         string
+	generateTitle           string
+	generateDescription     string
+	generateVersion         string
+	generateBasePath        string
+	generateCleanup         bool
+	generateYes             bool
+	generateUsePathGroups   bool
+	generateTagMapping      []string
+	generateTagDesc         []string
+	generateTagGroup        []string
+	generateServers         []string
+	generateServerVars      []string
+	generateInferServers    bool
+	generateVersionPrefix   []string
+	generatePathTemplate    []string
+	generateStripPrefix     []string
+	generateConstraints     bool
+	generateRequired        float64
+	generateSplitMedia      bool
+	generateCodeSamples     bool
+	generateResponseTimes   bool
+	generateCallFrequency   bool
+	generateMinCalls        int
+	generateErrorRates      bool
+	generateErrorThreshold  float64
+	generateStaleAfter      int
+	generateDeprecateStale  bool
+	generateOwners          string
+	generateDescriptions    string
+	generateStrategy        string
+	generateStatusDesc      []string
+	generateIncludePaths    []string
+	generateExcludePaths    []string
+	generateIncludeMethod   []string
+	generateExcludeMethod   []string
+	generateIncludeHeader   []string
+	generateExcludeHeader   []string
+	generateRequireHeader   []string
+	generateWorkers         int
+	generateFieldSamples    int
+	generateEndpointSamples int
+	generateCache           string
+	generateMaxMemory       string
+	generateCPUProfile      string
+	generateMemProfile      string
+	generateStrict          bool
+	generateCI              bool
+	generateFailOnDiff      bool
+	generateReview          bool
+	generateDryRun          bool
+	generateReport          string
+	generateNoBackup        bool
+	generateMergeInto       string
+	generateOverlays        []string
+	generatePlugins         []string
+	generateOutputURL       string
+	generateOutputHeaders   []string
+	generateEmitSchemas     string
+	generateSplit           bool
+	generateSplitVersions   bool
+	generateContact         openapi.OpenAPIContact
+	generateLicense         openapi.OpenAPILicense
+	generateTerms           string
+	generateExternalDocs    openapi.OpenAPIExternalDocs
+
+	// Record command flags
+	recordDuration time.Duration
+
+	// Serve command flags
+	servePort int
+	serveUI   string
+
+	// Lint command flags
+	lintRules  []string
+	lintFormat string
+
+	// Doctor command flags
+	doctorDataDir string
+	doctorTarget  string
+	doctorPort    int
+
+	// Suggest filters command flags
+	suggestDataDir string
+	suggestWrite   bool
+
+	// Export command flags
+	exportOutput       string
+	exportDataDir      string
+	exportTitle        string
+	exportDescription  string
+	exportVersion      string
+	exportBasePath     string
+	exportTestsLang    string
+	exportTestsPkg     string
+	exportIntegration  string
+	exportBackendURL   string
+	exportSDKLang      string
+	exportSDKGenerator string
+	exportSDKPackage   string
+	exportSDKArgs      []string
+
+	// Publish command flags
+	publishProvider    string
+	publishAPIKey      string
+	publishOwner       string
+	publishName        string
+	publishBump        string
+	publishPrivate     bool
+	publishRegistry    string
+	publishBranch      string
+	publishOutput      string
+	publishLifecycle   string
+	publishSystem      string
+	publishDataDir     string
+	publishTitle       string
+	publishDescription string
+	publishVersion     string
+	publishBasePath    string
+
+	// Version command flags
+	versionCheck bool
+
+	// Self-update command flags
+	selfUpdateCheck bool
 
 	// Root command
 	rootCmd = &cobra.Command{
@@ -51,7 +211,32 @@ It works in two steps:
   swagdoc proxy --target http://api.example.com
 
   # Generate documentation from captured transactions
-  swagdoc generate --output swagger.json`,
+  swagdoc generate --output swagger.json
+
+  # Read the settings of every command from a configuration file
+  swagdoc --config swagdoc.yaml generate`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			configUsed, err := applyConfig(cmd, configFile, profile)
+			if err != nil {
+				return err
+			}
+			// Keep stdout free for the output when it is written there
+			if output := cmd.Flags().Lookup("output"); output != nil && output.Value.String() == stdoutOutput {
+				logger.SetOutput(os.Stderr)
+			}
+			if err := configureLogging(logFormat, logLevel, logFile); err != nil {
+				return err
+			}
+			if noColor {
+				logger.SetColor(false)
+			}
+			if configUsed != "" && profile != "" {
+				logger.PrintInfo("Using profile %s of config file %s", profile, configUsed)
+			} else if configUsed != "" {
+				logger.PrintInfo("Using config file %s", configUsed)
+			}
+			return nil
+		},
 	}
 
 	// Proxy command
@@ -68,11 +253,37 @@ type placeholders to ensure privacy and security.`,
   swagdoc proxy --target http://api.example.com
 
   # Start a proxy on a custom port with a specific data directory
-  swagdoc proxy --target http://api.example.com --port 9000 --data-dir ./api-data`,
+  swagdoc proxy --target http://api.example.com --port 9000 --data-dir ./api-data
+
+  # Watch the captured endpoints in a live dashboard instead of the request log
+  swagdoc proxy --target http://api.example.com --tui
+
+  # Only print errors and the summary when the proxy stops
+  swagdoc proxy --target http://api.example.com --quiet
+
+  # Refresh the documentation every 10 minutes while the proxy runs
+  swagdoc proxy --target http://api.example.com --auto-generate-every 10m --auto-output swagger.json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if proxyTarget == "" {
 				return fmt.Errorf("target API server URL is required")
 			}
+			if proxyAutoEvery < 0 {
+				return fmt.Errorf("--auto-generate-every must be positive")
+			}
+			if proxyAutoEvery > 0 && proxyTUI {
+				return fmt.Errorf("--auto-generate-every can't be used with --tui, which generates the documentation on demand")
+			}
+			if proxyQuiet {
+				logger.SetLevel(logger.LevelError)
+			}
+			if proxyPprof != "" {
+				if err := startPprofServer(proxyPprof); err != nil {
+					return err
+				}
+			}
+			if proxyTUI {
+				return runProxyTUI(proxyPort, proxyTarget, proxyDataDir)
+			}
 			return runProxy(proxyPort, proxyTarget, proxyDataDir)
 		},
 	}
@@ -93,10 +304,362 @@ captured API transactions from the proxy server.`,
   swagdoc generate --output api-docs.json --cleanup
   
   # Generate documentation with custom tag mappings
-  swagdoc generate --tag-mapping "auth:Authentication" --tag-mapping "users:User Management"`,
+  swagdoc generate --tag-mapping "auth:Authentication" --tag-mapping "users:User Management"
+
+  # Describe tags and group them into sections
+  swagdoc generate --tag-description "Users:Manage user accounts" --tag-group "Accounts:Users,Authentication"
+
+  # Update a hand-edited spec, keeping its descriptions and examples
+  swagdoc generate --merge-into openapi.yaml
+
+  # Document several environments with a templated server URL
+  swagdoc generate --server "https://{env}.api.example.com=Hosted API" --server-variable "env=prod|staging"
+
+  # Write a standalone HTML page to attach to a ticket
+  swagdoc generate --format html --output api-docs.html
+
+  # Write per-tag Markdown pages for a docs repository or wiki
+  swagdoc generate --format markdown --output docs/api
+
+  # Split the document into per-path and per-component files for code review
+  swagdoc generate --split --output api/openapi.yaml
+
+  # Reuse the inferred models for validation outside OpenAPI
+  swagdoc generate --emit-schemas schemas/
+
+  # Apply permanent corrections after every regeneration
+  swagdoc generate --overlay overrides.yaml
+
+  # Force a path template where detection gets it wrong
+  swagdoc generate --path-template "/users/*/orders/*=/users/{userId}/orders/{orderId}"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGenerate(cmd)
+		},
+	}
+
+	// Record command
+	recordCmd = &cobra.Command{
+		Use:   "record",
+		Short: "Capture API transactions and generate documentation in one step",
+		Long: `Starts a proxy server that captures API transactions like the proxy command,
+and generates documentation from them like the generate command once it is
+stopped with Ctrl+C or after --duration. All generate flags are available,
+and --data-dir is used for both capturing and generating.`,
+		Example: `  # Record until Ctrl+C, then write swagger.json
+  swagdoc record --target http://api.example.com
+
+  # Record while the integration tests run for five minutes
+  swagdoc record --target http://api.example.com --duration 5m --output openapi.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if proxyTarget == "" {
+				return fmt.Errorf("target API server URL is required")
+			}
+			if proxyQuiet {
+				logger.SetLevel(logger.LevelError)
+			}
+			if proxyPprof != "" {
+				if err := startPprofServer(proxyPprof); err != nil {
+					return err
+				}
+			}
+			if err := runRecord(proxyPort, proxyTarget, generateDataDir, recordDuration); err != nil {
+				return err
+			}
+			return runGenerate(cmd)
+		},
+	}
+
+	// Serve command
+	serveCmd = &cobra.Command{
+		Use:   "serve [file]",
+		Short: "Serve an OpenAPI document with Redoc or Swagger UI",
+		Long: `Serves an OpenAPI document (JSON or YAML, default swagger.json) at a local
+port with Redoc or Swagger UI. The page reloads itself whenever the file
+changes, e.g. after running generate again.`,
+		Example: `  # Review the generated documentation at http://localhost:8090
+  swagdoc serve swagger.json
+
+  # Try out requests with Swagger UI on another port
+  swagdoc serve openapi.yaml --ui swagger-ui --port 9000`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "swagger.json"
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return serveDocs(path, servePort, serveUI)
+		},
+	}
+
+	// Lint command
+	lintCmd = &cobra.Command{
+		Use:   "lint [file]",
+		Short: "Check an OpenAPI document against a core ruleset",
+		Long: `Checks an OpenAPI document (JSON or YAML, default swagger.json), generated by
+swagdoc or written by hand, against a core ruleset. The severity of every rule
+can be changed with --rule, and swagdoc exits non-zero when issues with the
+error severity are found.`,
+		Example: `  # Lint the generated documentation
+  swagdoc lint swagger.json
+
+  # Treat unused components as errors and skip the tag check
+  swagdoc lint openapi.yaml --rule unused-component=error --rule operation-tags=off`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "swagger.json"
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return lintDocs(path, lintRules, lintFormat)
+		},
+	}
+
+	// Doctor command
+	doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose problems with captured data and the proxy setup",
+		Long: `Checks that the data directory is writable, reads and validates every session
+file, reports transactions that can't be documented, and checks that the target
+API server is reachable and the proxy port is free, suggesting a fix for every
+problem found. Run it when generate produces an empty or incomplete document.`,
+		Example: `  # Check the default data directory
+  swagdoc doctor
+
+  # Also check that the API server is reachable before starting the proxy
+  swagdoc doctor --data-dir ./api-data --target http://api.example.com`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor(doctorDataDir, doctorTarget, doctorPort)
+		},
+	}
+
+	// Suggest filters command
+	suggestFiltersCmd = &cobra.Command{
+		Use:   "suggest-filters",
+		Short: "Suggest exclude rules for traffic that doesn't belong in the documentation",
+		Long: `Analyzes the captured API transactions for traffic that rarely belongs in API
+documentation, such as health checks, metrics, static assets, and CORS preflight
+OPTIONS requests, and suggests generate exclude rules for it. The rules are
+printed as a configuration snippet, or added to the configuration file with
+--write (to the section of the --profile, if given).`,
+		Example: `  # Print the suggested rules
+  swagdoc suggest-filters
+
+  # Add them to swagdoc.yaml, which generate reads automatically
+  swagdoc suggest-filters --write`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return suggestFilters(suggestDataDir, suggestWrite)
+		},
+	}
+
+	// Export command
+	exportCmd = &cobra.Command{
+		Use:   "export [plugin]",
+		Short: "Export captured API transactions for API clients",
+		Long: `Exports the captured API transactions in formats that API clients can import,
+generated from the same transactions and grouping as the OpenAPI documentation.
+Other formats are exported by plugins: swagdoc export <name> runs the
+swagdoc-plugin-<name> executable on PATH.`,
+		Example: `  # Export with the swagdoc-plugin-asciidoc plugin
+  swagdoc export asciidoc -o api.adoc`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return cmd.Help()
+			}
+			return exportPlugin(args[0])
+		},
+	}
+
+	// Export Postman command
+	exportPostmanCmd = &cobra.Command{
+		Use:   "postman",
+		Short: "Export a Postman v2.1 collection",
+		Long: `Exports a Postman v2.1 collection with a folder per tag, path parameters as
+templated variables, and the observed responses saved as examples. The server
+URL is stored in the {{baseUrl}} collection variable.`,
+		Example: `  # Export a collection from the default data directory
+  swagdoc export postman -o collection.json
+
+  # Point the collection at a staging server
+  swagdoc export postman --base-path https://staging.example.com`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportCollection("Postman collection", exportOutputOr("collection.json"), exportFile(openapi.RenderPostman))
+		},
+	}
+
+	// Export Insomnia command
+	exportInsomniaCmd = &cobra.Command{
+		Use:   "insomnia",
+		Short: "Export an Insomnia v4 collection",
+		Long: `Exports an Insomnia v4 export file with a folder per tag. The server URL is
+stored in the base_url variable of the base environment, and path parameters
+are environment variables holding their example values.`,
+		Example: `  # Export a collection to import with Insomnia's "Import from File"
+  swagdoc export insomnia -o insomnia.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportCollection("Insomnia collection", exportOutputOr("insomnia.json"), exportFile(openapi.RenderInsomnia))
+		},
+	}
+
+	// Export Bruno command
+	exportBrunoCmd = &cobra.Command{
+		Use:   "bruno",
+		Short: "Export a Bruno collection directory",
+		Long: `Exports a Bruno collection directory with bruno.json, a Default environment
+holding the server URL as baseUrl, and a folder per tag with a .bru file per
+request, ready to be opened with Bruno or committed next to the code.`,
+		Example: `  # Export a collection into ./bruno
+  swagdoc export bruno -o bruno`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if exportOutput == stdoutOutput {
+				return fmt.Errorf("bruno collections are directories and can't be written to stdout")
+			}
+			return exportCollection("Bruno collection", exportOutputOr("bruno"), openapi.WriteBruno)
+		},
+	}
+
+	// Export AWS API Gateway command
+	exportAWSCmd = &cobra.Command{
+		Use:   "aws-apigateway",
+		Short: "Export an OpenAPI document for AWS API Gateway",
+		Long: `Exports the OpenAPI 3.0 dialect AWS API Gateway imports as a REST API. Every
+operation gets an x-amazon-apigateway-integration stub: http_proxy forwards
+requests to the backend, with the path parameters mapped, and mock answers with
+the documented success status. Model names are made alphanumeric, and the
+discriminator, readOnly, writeOnly, and exclusive bound keywords API Gateway
+rejects are left out.`,
+		Example: `  # Export with integrations forwarding to the backend
+  swagdoc export aws-apigateway --backend-url https://backend.example.com -o apigateway.json
+  aws apigateway import-rest-api --body fileb://apigateway.json
+
+  # Export with mock integrations
+  swagdoc export aws-apigateway --integration mock`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportCollection("AWS API Gateway document", exportOutputOr("apigateway.json"), exportFile(func(spec *openapi.OpenAPISpec) ([]byte, error) {
+				return openapi.RenderAWSAPIGateway(spec, openapi.AWSGatewayOptions{
+					Integration: exportIntegration,
+					BackendURL:  exportBackendURL,
+				})
+			}))
+		},
+	}
+
+	// Export Azure API Management command
+	exportAPIMCmd = &cobra.Command{
+		Use:   "azure-apim",
+		Short: "Export a Swagger 2.0 document for Azure API Management",
+		Long: `Exports the Swagger 2.0 dialect Azure API Management imports, with the host,
+base path, and schemes taken from the first server. Operation IDs, which name
+the imported operations, are shortened to 80 characters and stripped of the
+characters API Management doesn't accept.`,
+		Example: `  # Export a document to import with az apim api import --specification-format SwaggerJson
+  swagdoc export azure-apim -o apim.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportCollection("Azure API Management document", exportOutputOr("apim.json"), exportFile(openapi.RenderAzureAPIM))
+		},
+	}
+
+	// Export tests command
+	exportTestsCmd = &cobra.Command{
+		Use:   "tests",
+		Short: "Export contract tests replaying the captured requests",
+		Long: `Exports contract tests from the captured API transactions: the first request
+captured of every documented operation and status code is replayed against a
+target, asserting the status code and that the response conforms to the
+documented schema. Server errors and undocumented requests are left out.
+
+--lang go writes a table-driven Go test file that only uses the standard
+library, to run with go test. --lang json writes the same tests as a JSON test
+plan for runners in other languages, with the response schemas as JSON Schema.
+
+The tests are sent to the server given with --base-path, or to the one in
+SWAGDOC_CONTRACT_TARGET when they run. Redacted request headers are read from
+environment variables when they run, e.g. SWAGDOC_AUTHORIZATION.`,
+		Example: `  # Export Go contract tests and run them against staging
+  swagdoc export tests --lang go -o contract/contract_test.go
+  SWAGDOC_CONTRACT_TARGET=https://staging.example.com go test ./contract
+
+  # Export a JSON test plan
+  swagdoc export tests --lang json -o contract-tests.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportContractTests()
+		},
+	}
+
+	// Export SDK command
+	exportSDKCmd = &cobra.Command{
+		Use:   "sdk",
+		Short: "Export a client SDK generated from the captured API transactions",
+		Long: `Generates the document from the captured API transactions and a client SDK
+from it, in one step. --generator openapi-generator runs OpenAPI Generator
+(npm install -g @openapitools/openapi-generator-cli) with the generator of
+--lang, e.g. python, typescript-fetch, or java, into the output directory.
+
+--generator builtin writes a minimal Go client without installing anything: a
+single client.go that only uses the standard library, with a struct per schema
+and a method per operation. By default, OpenAPI Generator is used when it is
+installed, and the built-in generator for Go otherwise.`,
+		Example: `  # Generate a Python SDK with OpenAPI Generator
+  swagdoc export sdk --generator openapi-generator --lang python --package orders_client -o sdk/python
+
+  # Write the built-in Go client
+  swagdoc export sdk --generator builtin --package orders -o internal/orders/client.go
+
+  # Pass options through to OpenAPI Generator
+  swagdoc export sdk --lang typescript-fetch --generator-arg=--additional-properties=npmName=orders-client`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportSDK()
+		},
+	}
+
+	// Publish command
+	publishCmd = &cobra.Command{
+		Use:   "publish [file]",
+		Short: "Publish the documentation to an API catalog",
+		Long: `Publishes an OpenAPI document (JSON or YAML) to an API catalog: SwaggerHub,
+Stoplight, or Backstage. Without a file, the document is generated from the
+captured API transactions first, so generation and publication are one step.
+
+SwaggerHub versions are saved through the registry API, Stoplight projects are
+pushed to with the Stoplight CLI (npm install -g @stoplight/cli), and Backstage
+gets an API entity file holding the document, to commit or register with the
+catalog. --bump publishes the next version after the latest one published to
+SwaggerHub or written to the Backstage entity file.`,
+		Example: `  # Generate and publish the next minor version to SwaggerHub
+  swagdoc publish --provider swaggerhub --owner acme --api-key $SWAGGERHUB_API_KEY --bump minor
+
+  # Publish an existing document to Stoplight
+  swagdoc publish openapi.yaml --provider stoplight --api-key $STOPLIGHT_TOKEN
+
+  # Write a Backstage entity for the catalog
+  swagdoc publish --provider backstage --owner team-orders --system commerce`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return publishDocs(path, cmd.Flags().Changed("version"))
+		},
+	}
+
+	// Plugins command
+	pluginsCmd = &cobra.Command{
+		Use:   "plugins",
+		Short: "List the plugins found on PATH",
+		Long: `Lists the swagdoc-plugin-<name> executables on PATH and the hooks they support.
+Plugins processing transactions or documents are enabled with generate --plugin,
+and export plugins run with swagdoc export <name>.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return generateDocs(generateOutput, generateDataDir, generateTitle, generateDescription,
-				generateVersion, generateBasePath, generateCleanup)
+			return listPlugins()
 		},
 	}
 
@@ -105,8 +668,35 @@ captured API transactions from the proxy server.`,
 		Use:   "version",
 		Short: "Print the version number of swagdoc",
 		Long:  `All software has versions. This is swagdoc's.`,
-		Run: func(cmd *cobra.Command, args []string) {
+		Example: `  # Also check GitHub for a newer release
+  swagdoc version --check`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			fmt.Printf("swagdoc version %s\n", version)
+			if versionCheck {
+				_, err := checkForUpdate()
+				return err
+			}
+			return nil
+		},
+	}
+
+	// Self-update command
+	selfUpdateCmd = &cobra.Command{
+		Use:   "self-update",
+		Short: "Update swagdoc to the latest release",
+		Long: `Checks GitHub for the latest swagdoc release and, when it is newer than this
+binary, downloads it for this platform and replaces the running executable with
+it. Binaries installed with go install are better updated the same way.`,
+		Example: `  # Update to the latest release
+  swagdoc self-update
+
+  # Only report whether a newer release exists
+  swagdoc self-update --check`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSelfUpdate(selfUpdateCheck)
 		},
 	}
 
@@ -165,36 +755,151 @@ PowerShell:
 )
 
 func init() {
+	// Add root command flags
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Configuration file (YAML, JSON, or TOML) with settings per command (default: ./swagdoc.yaml if present)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Profile of the configuration file whose settings override the top-level ones, e.g. staging")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logger.FormatText, "Log format: text or json")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Least severe log level printed: debug, info, warning, or error")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "File to append logs to instead of the terminal")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Print plain output without colors, also set by the NO_COLOR environment variable")
+
 	// Add proxy command flags
 	proxyCmd.Flags().IntVarP(&proxyPort, "port", "p", 8080, "Port to run the proxy server on")
 	proxyCmd.Flags().StringVarP(&proxyTarget, "target", "t", "", "Target API server URL")
 	proxyCmd.Flags().StringVarP(&proxyDataDir, "data-dir", "d", defaultDataDir, "Directory to store API transaction data")
+	proxyCmd.Flags().BoolVar(&proxyTUI, "tui", false, "Show a live dashboard of the captured endpoints instead of the request log")
+	proxyCmd.Flags().BoolVarP(&proxyQuiet, "quiet", "q", false, "Only print errors and the summary, not every proxied request")
+	proxyCmd.Flags().StringSliceVar(&proxyRedact, "redact-header", []string{}, "Header whose values are redacted from the captured data, besides the built-in credential headers (can be used multiple times)")
+	proxyCmd.Flags().StringVar(&proxyPprof, "pprof", "", "Address to serve runtime profiles on under /debug/pprof/, e.g. :6060 (default: not served)")
+	proxyCmd.Flags().StringArrayVar(&proxySinks, "sink", []string{}, "Also publish captured transactions to kafka://brokers/topic or nats://host/subject, with ?tls=true to connect over TLS (can be used multiple times)")
+	proxyCmd.Flags().StringVar(&proxySinkSource, "sink-source", "", "Environment name sent with every published transaction in the Swagdoc-Source header")
+	proxyCmd.Flags().DurationVar(&proxyAutoEvery, "auto-generate-every", 0, "Regenerate the documentation this often while the proxy runs, e.g. 10m (default: never)")
+	proxyCmd.Flags().StringVar(&proxyAutoOutput, "auto-output", "swagger.json", "Output file, or s3:// or gs:// URL, of the regenerated documentation")
 	proxyCmd.MarkFlagRequired("target")
 
 	// Add generate command flags
-	generateCmd.Flags().StringVarP(&generateOutput, "output", "o", "swagger.json", "Output file for Swagger documentation")
-	generateCmd.Flags().StringVarP(&generateDataDir, "data-dir", "d", defaultDataDir, "Directory to read API transaction data from")
-	generateCmd.Flags().StringVar(&generateTitle, "title", "API Documentation", "Title for the API documentation")
-	generateCmd.Flags().StringVar(&generateDescription, "description", "Generated API documentation", "Description for the API documentation")
-	generateCmd.Flags().StringVarP(&generateVersion, "version", "v", "1.0.0", "API version")
-	generateCmd.Flags().StringVar(&generateBasePath, "base-path", "http://localhost:8080", "Base path for the API")
-	generateCmd.Flags().BoolVar(&generateCleanup, "cleanup", false, "Delete the data directory after generating documentation")
-	generateCmd.Flags().BoolVar(&generateUsePathGroups, "group-by-path", true, "Group API endpoints by path segments")
-	generateCmd.Flags().StringSliceVar(&generateTagMapping, "tag-mapping", []string{}, "Custom tag mappings in format 'path:tag' (can be used multiple times)")
-	generateCmd.Flags().StringSliceVar(&generateVersionPrefix, "version-prefix", []string{}, "Custom version prefixes (can be used multiple times)")
+	addGenerateFlags(generateCmd.Flags())
+
+	// Add record command flags, the proxy flags plus every generate flag
+	recordCmd.Flags().IntVarP(&proxyPort, "port", "p", 8080, "Port to run the proxy server on")
+	recordCmd.Flags().StringVarP(&proxyTarget, "target", "t", "", "Target API server URL")
+	recordCmd.Flags().DurationVar(&recordDuration, "duration", 0, "Stop recording after this long, e.g. 5m (default: until Ctrl+C)")
+	recordCmd.Flags().BoolVarP(&proxyQuiet, "quiet", "q", false, "Only print errors and the summary, not every proxied request")
+	recordCmd.Flags().StringSliceVar(&proxyRedact, "redact-header", []string{}, "Header whose values are redacted from the captured data, besides the built-in credential headers (can be used multiple times)")
+	recordCmd.Flags().StringVar(&proxyPprof, "pprof", "", "Address to serve runtime profiles on under /debug/pprof/, e.g. :6060 (default: not served)")
+	recordCmd.Flags().StringArrayVar(&proxySinks, "sink", []string{}, "Also publish captured transactions to kafka://brokers/topic or nats://host/subject, with ?tls=true to connect over TLS (can be used multiple times)")
+	recordCmd.Flags().StringVar(&proxySinkSource, "sink-source", "", "Environment name sent with every published transaction in the Swagdoc-Source header")
+	addGenerateFlags(recordCmd.Flags())
+
+	// Add serve command flags
+	serveCmd.Flags().IntVarP(&servePort, "port", "p", 8090, "Port to serve the documentation on")
+	serveCmd.Flags().StringVar(&serveUI, "ui", openapi.UIRedoc, "Documentation UI: "+strings.Join(openapi.UIs, " or "))
+
+	// Add lint command flags, and list the rules in the help
+	lintCmd.Flags().StringArrayVar(&lintRules, "rule", []string{}, "Rule severity in format 'rule=severity', the severity being error, warning, info, or off (can be used multiple times)")
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format: text or json")
+	lintCmd.Long += "\n\nRules:\n"
+	for _, rule := range openapi.LintRules() {
+		lintCmd.Long += fmt.Sprintf("  %-24s %s (default: %s)\n", rule.Name, rule.Description, rule.Severity)
+	}
+
+	// Add doctor command flags
+	doctorCmd.Flags().StringVarP(&doctorDataDir, "data-dir", "d", defaultDataDir, "Directory of the captured API transaction data")
+	doctorCmd.Flags().StringVarP(&doctorTarget, "target", "t", "", "Target API server URL to check (default: not checked)")
+	doctorCmd.Flags().IntVarP(&doctorPort, "port", "p", 8080, "Port the proxy server will run on")
+
+	// Add suggest filters command flags
+	suggestFiltersCmd.Flags().StringVarP(&suggestDataDir, "data-dir", "d", defaultDataDir, "Directory of the captured API transaction data")
+	suggestFiltersCmd.Flags().BoolVar(&suggestWrite, "write", false, "Add the suggested rules to the YAML configuration file (default: ./swagdoc.yaml)")
+
+	// Add version and self-update command flags
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "Check GitHub for a newer release")
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateCheck, "check", false, "Only report whether a newer release exists, without installing it")
+
+	// Add export command flags, shared by every export format
+	exportCmd.PersistentFlags().StringVarP(&exportOutput, "output", "o", "", "Output file, or directory for bruno and sdk, of the export (default depends on the format)")
+	exportCmd.PersistentFlags().StringVarP(&exportDataDir, "data-dir", "d", defaultDataDir, "Directory to read API transaction data from")
+	exportCmd.PersistentFlags().StringVar(&exportTitle, "title", "API Documentation", "Name of the exported collection")
+	exportCmd.PersistentFlags().StringVar(&exportDescription, "description", "Generated API documentation", "Description of the exported collection")
+	exportCmd.PersistentFlags().StringVarP(&exportVersion, "version", "v", "1.0.0", "API version")
+	exportCmd.PersistentFlags().StringVar(&exportBasePath, "base-path", "http://localhost:8080", "Base URL the exported requests are sent to")
+	exportCmd.AddCommand(exportPostmanCmd)
+	exportCmd.AddCommand(exportInsomniaCmd)
+	exportCmd.AddCommand(exportBrunoCmd)
+	exportTestsCmd.Flags().StringVar(&exportTestsLang, "lang", "go", "Language of the contract tests: go, or json for a test plan")
+	exportTestsCmd.Flags().StringVar(&exportTestsPkg, "package", "contract", "Package of the Go contract tests")
+	exportCmd.AddCommand(exportTestsCmd)
+	exportAWSCmd.Flags().StringVar(&exportIntegration, "integration", openapi.AWSIntegrationHTTPProxy, "Integration stub of every operation: http_proxy to forward to the backend, or mock")
+	exportAWSCmd.Flags().StringVar(&exportBackendURL, "backend-url", "", "Backend the http_proxy integrations forward to (default: the server of --base-path)")
+	exportCmd.AddCommand(exportAWSCmd)
+	exportCmd.AddCommand(exportAPIMCmd)
+	exportSDKCmd.Flags().StringVar(&exportSDKLang, "lang", "go", "Language of the SDK, an OpenAPI Generator generator name such as python or typescript-fetch")
+	exportSDKCmd.Flags().StringVar(&exportSDKGenerator, "generator", sdkGeneratorAuto, "SDK generator: openapi-generator, builtin for the Go client, or auto")
+	exportSDKCmd.Flags().StringVar(&exportSDKPackage, "package", "", "Package name of the SDK (default: the generator's, client for the built-in Go client)")
+	exportSDKCmd.Flags().StringArrayVar(&exportSDKArgs, "generator-arg", []string{}, "Argument passed through to OpenAPI Generator (can be used multiple times)")
+	exportCmd.AddCommand(exportSDKCmd)
+
+	// Add publish command flags
+	publishCmd.Flags().StringVar(&publishProvider, "provider", "", "API catalog to publish to: "+strings.Join(publish.Providers, ", "))
+	publishCmd.Flags().StringVar(&publishAPIKey, "api-key", "", "SwaggerHub API key or Stoplight CI token, also set by SWAGDOC_PUBLISH_API_KEY")
+	publishCmd.Flags().StringVar(&publishOwner, "owner", "", "SwaggerHub owner of the API, or owner of the Backstage entity")
+	publishCmd.Flags().StringVar(&publishName, "name", "", "Name of the API in the catalog (default: from the title, e.g. orders-api)")
+	publishCmd.Flags().StringVar(&publishBump, "bump", "", "Publish the next patch, minor, or major version after the latest published one")
+	publishCmd.Flags().BoolVar(&publishPrivate, "private", false, "Publish a private SwaggerHub API")
+	publishCmd.Flags().StringVar(&publishRegistry, "registry-url", publish.SwaggerHubURL, "Registry API of an on-premise SwaggerHub")
+	publishCmd.Flags().StringVar(&publishBranch, "branch", "", "Stoplight branch to push to (default: the project's default branch)")
+	publishCmd.Flags().StringVarP(&publishOutput, "output", "o", publish.DefaultBackstageOutput, "Backstage entity file to write")
+	publishCmd.Flags().StringVar(&publishLifecycle, "lifecycle", "production", "Lifecycle of the Backstage entity")
+	publishCmd.Flags().StringVar(&publishSystem, "system", "", "System the Backstage entity belongs to")
+	publishCmd.Flags().StringVarP(&publishDataDir, "data-dir", "d", defaultDataDir, "Directory to read API transaction data from when no file is given")
+	publishCmd.Flags().StringVar(&publishTitle, "title", "API Documentation", "Title of the generated documentation")
+	publishCmd.Flags().StringVar(&publishDescription, "description", "Generated API documentation", "Description of the generated documentation")
+	publishCmd.Flags().StringVarP(&publishVersion, "version", "v", "1.0.0", "API version, replacing the version of a given file")
+	publishCmd.Flags().StringVar(&publishBasePath, "base-path", "http://localhost:8080", "Base path for the API")
+	publishCmd.MarkFlagRequired("provider")
 
 	// Add commands to root
 	rootCmd.AddCommand(proxyCmd)
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(recordCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(suggestFiltersCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(publishCmd)
+	rootCmd.AddCommand(pluginsCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(selfUpdateCmd)
 	rootCmd.AddCommand(completionCmd)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// configureLogging applies the log format, level, and file of the root command
+func configureLogging(format string, level string, file string) error {
+	if err := logger.SetFormat(format); err != nil {
+		return err
+	}
+	parsed, err := logger.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logger.SetLevel(parsed)
+
+	if file != "" {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %v", err)
+		}
+		logger.SetOutput(f)
 	}
+	return nil
 }
 
 func runProxy(port int, target string, dataDir string) error {
@@ -205,15 +910,27 @@ func runProxy(port int, target string, dataDir string) error {
 	logger.PrintInfo("All transactions will be saved to a single consolidated session file")
 	logger.PrintInfo("Press Ctrl+C to stop the server")
 
-	// Create storage for API transactions
-	storage, err := proxy.NewFileStorage(dataDir)
+	// Create storage for API transactions, written in the background
+	storage, err := newCaptureStorage(dataDir)
 	if err != nil {
-		logger.PrintError("Failed to create storage: %v", err)
-		return fmt.Errorf("failed to create storage: %v", err)
+		return err
+	}
+	defer storage.Close()
+
+	// Create interceptor function, which stores and logs every transaction
+	requests := newRequestLogger()
+	interceptor := captureInterceptor(storage, requests, nil)
+
+	// Regenerate the documentation on a schedule, and once more when the proxy stops
+	var generator *autoGenerator
+	if proxyAutoEvery > 0 {
+		logger.PrintInfo("Documentation will be generated to %s every %s", proxyAutoOutput, proxyAutoEvery)
+		generator = newAutoGenerator(storage, dataDir, proxyAutoOutput)
+		interceptor = generator.intercept(interceptor)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go generator.run(ctx, proxyAutoEvery)
 	}
-
-	// Create interceptor function
-	interceptor := proxy.TransactionInterceptor(storage)
 
 	// Create and start proxy server
 	server, err := proxy.NewProxyServer(port, target, interceptor)
@@ -221,182 +938,1557 @@ func runProxy(port int, target string, dataDir string) error {
 		logger.PrintError("Failed to create proxy server: %v", err)
 		return fmt.Errorf("failed to create proxy server: %v", err)
 	}
-
-	if err := server.Start(); err != nil {
+	server.SetRedactHeaders(proxyRedact)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Start(context.Background())
+	}()
+
+	// Wait for Ctrl+C or a server failure
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	select {
+	case err := <-serverErr:
+		if err == nil {
+			err = fmt.Errorf("stopped unexpectedly")
+		}
 		logger.PrintError("Proxy server error: %v", err)
 		return fmt.Errorf("proxy server error: %v", err)
+	case <-interrupt:
 	}
 
+	// Let the requests in flight finish so that their transactions are stored
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logger.PrintWarning("Failed to stop the proxy server gracefully: %v", err)
+	}
+	requests.printSummary()
+	if generator != nil {
+		generator.generate()
+	}
 	return nil
 }
 
-// Function to filter transactions to prioritize successful responses
-func prioritizeSuccessfulResponses(transactions []proxy.APITransaction) []proxy.APITransaction {
-	// Group transactions by endpoint (method + path)
-	endpointMap := make(map[string][]proxy.APITransaction)
-	for _, tx := range transactions {
-		key := tx.Request.Method + ":" + tx.Request.Path
-		endpointMap[key] = append(endpointMap[key], tx)
+// runProxyTUI runs the proxy server behind a dashboard of the captured endpoints, from which
+// capture can be paused and documentation generated with the default generate settings
+func runProxyTUI(port int, target string, dataDir string) error {
+	// Create storage for API transactions, written in the background
+	storage, err := newCaptureStorage(dataDir)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	stats := tui.NewStats()
+	var capturing atomic.Bool
+	capturing.Store(true)
+	store := captureInterceptor(storage, newRequestLogger(), capturing.Load)
+	interceptor := func(tx proxy.APITransaction) {
+		if capturing.Load() {
+			stats.Add(tx)
+		}
+		store(tx)
 	}
 
-	// Select the best transaction for each endpoint based on status code
-	var filteredTransactions []proxy.APITransaction
-	for _, txs := range endpointMap {
-		// Sort by status code preference
-		best := selectBestTransaction(txs)
-		filteredTransactions = append(filteredTransactions, best)
+	server, err := proxy.NewProxyServer(port, target, interceptor)
+	if err != nil {
+		logger.PrintError("Failed to create proxy server: %v", err)
+		return fmt.Errorf("failed to create proxy server: %v", err)
+	}
+	server.SetRedactHeaders(proxyRedact)
+
+	// The dashboard replaces the request log, which would otherwise scroll over it
+	if logFile == "" {
+		output := logger.Output()
+		logger.SetOutput(io.Discard)
+		defer logger.SetOutput(output)
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.Start(context.Background()); err != nil {
+			serverErr <- err
+		}
+	}()
+
+	err = tui.Run(tui.Options{
+		Port:      port,
+		Target:    target,
+		DataDir:   dataDir,
+		Stats:     stats,
+		Capturing: &capturing,
+		Generate: func() (string, error) {
+			storage.Flush()
+			err := generateDocs(generateOutput, dataDir, generateTitle, generateDescription,
+				generateVersion, generateBasePath, false)
+			return generateOutput, err
+		},
+		ServerErrors: serverErr,
+	})
+
+	// Let the requests in flight finish so that their transactions are stored
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if shutdownErr := server.Shutdown(ctx); shutdownErr != nil && err == nil {
+		err = fmt.Errorf("failed to stop the proxy server gracefully: %v", shutdownErr)
+	}
+	return err
+}
+
+// captureStorage is the storage of captured transactions, with the sinks they are published to
+// and the queues publishing to them
+type captureStorage struct {
+	*proxy.AsyncStorage
+	sinkQueues []*proxy.AsyncStorage
+	sinks      []sink.Sink
+}
+
+// Close writes the queued transactions, publishes the ones queued for the sinks, and closes the
+// sinks
+func (c *captureStorage) Close() error {
+	err := c.AsyncStorage.Close()
+	for _, queue := range c.sinkQueues {
+		queue.Close()
+	}
+	for _, s := range c.sinks {
+		s.Close()
+	}
+	return err
+}
+
+// newCaptureStorage creates the storage of captured transactions, which queues them and writes
+// them to the data directory in the background, publishing them to the --sink destinations too.
+// Every sink has a queue of its own that drops transactions when full, so that an unreachable
+// broker never holds up writing the session file or the proxied requests.
+func newCaptureStorage(dataDir string) (*captureStorage, error) {
+	storage, err := proxy.NewFileStorage(dataDir)
+	if err != nil {
+		logger.PrintError("Failed to create storage: %v", err)
+		return nil, fmt.Errorf("failed to create storage: %v", err)
+	}
+
+	var sinks []sink.Sink
+	var queues []*proxy.AsyncStorage
+	var copies []proxy.Storage
+	for _, destination := range proxySinks {
+		s, err := sink.Open(destination, proxySinkSource)
+		if err != nil {
+			logger.PrintError("%v", err)
+			return nil, err
+		}
+		// Credentials in the URL are left out
+		u, _ := url.Parse(destination)
+		name := fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, u.Path)
+		queue := proxy.NewLossyAsyncStorage(s, proxy.DefaultQueueSize, func(err error) {
+			logger.PrintWarning("Failed to publish captured transactions to %s: %v", name, err)
+		})
+		sinks = append(sinks, s)
+		queues = append(queues, queue)
+		copies = append(copies, queue)
+		logger.PrintInfo("Publishing captured transactions to %s", name)
 	}
 
-	return filteredTransactions
+	var backend proxy.Storage = storage
+	if len(copies) > 0 {
+		backend = proxy.NewTeeStorage(storage, copies...)
+	}
+	return &captureStorage{
+		AsyncStorage: proxy.NewAsyncStorage(backend, proxy.DefaultQueueSize, func(err error) {
+			logger.PrintError("Error storing API transaction: %v", err)
+		}),
+		sinkQueues: queues,
+		sinks:      sinks,
+	}, nil
 }
 
-// Function to select the best transaction from a group with the same endpoint
-func selectBestTransaction(transactions []proxy.APITransaction) proxy.APITransaction {
-	if len(transactions) == 1 {
-		return transactions[0]
+// runRecord runs the proxy server until it is interrupted or the duration has passed, storing
+// the captured transactions in the data directory
+func runRecord(port int, target string, dataDir string, duration time.Duration) error {
+	logger.PrintStartupBanner(port, target, dataDir)
+	if duration > 0 {
+		logger.PrintInfo("Recording for %s, press Ctrl+C to stop early and generate documentation", duration)
+	} else {
+		logger.PrintInfo("Press Ctrl+C to stop recording and generate documentation")
 	}
 
-	// Priority order: 2xx > 3xx > 4xx > 5xx
-	var best proxy.APITransaction
-	bestPriority := 4 // Default to lowest priority
+	// Create storage for API transactions, written in the background
+	storage, err := newCaptureStorage(dataDir)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
 
-	for _, tx := range transactions {
-		var priority int
-		statusCode := tx.Response.StatusCode
+	requests := newRequestLogger()
+	server, err := proxy.NewProxyServer(port, target, captureInterceptor(storage, requests, nil))
+	if err != nil {
+		logger.PrintError("Failed to create proxy server: %v", err)
+		return fmt.Errorf("failed to create proxy server: %v", err)
+	}
+	server.SetRedactHeaders(proxyRedact)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Start(context.Background())
+	}()
+
+	// Wait for Ctrl+C, the end of the recording, or a server failure
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
 
-		switch {
-		case statusCode >= 200 && statusCode < 300:
-			priority = 0 // Highest priority
-		case statusCode >= 300 && statusCode < 400:
-			priority = 1
-		case statusCode >= 400 && statusCode < 500:
-			priority = 2
-		default:
-			priority = 3 // Lowest priority
+	var timeout <-chan time.Time
+	if duration > 0 {
+		timeout = time.After(duration)
+	}
+
+	select {
+	case err := <-serverErr:
+		if err == nil {
+			err = fmt.Errorf("stopped unexpectedly")
 		}
+		logger.PrintError("Proxy server error: %v", err)
+		return fmt.Errorf("proxy server error: %v", err)
+	case <-interrupt:
+		logger.PrintInfo("Recording stopped")
+	case <-timeout:
+		logger.PrintInfo("Recording finished after %s", duration)
+	}
+
+	// Let the requests in flight finish so that their transactions are stored
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logger.PrintWarning("Failed to stop the proxy server gracefully: %v", err)
+	}
+	requests.printSummary()
+	return nil
+}
 
-		// Update best if this transaction has higher priority (lower number)
-		if priority < bestPriority {
-			bestPriority = priority
-			best = tx
-		} else if priority == bestPriority && statusCode < best.Response.StatusCode {
-			// If same priority band, prefer lower status code
-			best = tx
+// runGenerate generates documentation with the generate flags of a command
+func runGenerate(cmd *cobra.Command) error {
+	// Merged documents are written back to the existing file unless told otherwise
+	if generateMergeInto != "" && !cmd.Flags().Changed("output") {
+		generateOutput = generateMergeInto
+	}
+	// The default output file follows the requested format
+	if generateFormat != "" && !cmd.Flags().Changed("output") && generateMergeInto == "" {
+		generateOutput = "swagger." + generateFormat
+		if generateFormat == openapi.FormatMarkdown {
+			generateOutput = "docs"
 		}
 	}
+	// Explicit servers replace the default base path
+	basePath := generateBasePath
+	if len(generateServers) > 0 && !cmd.Flags().Changed("base-path") {
+		basePath = ""
+	}
+	stopProfiling, err := startProfiling(generateCPUProfile, generateMemProfile)
+	if err != nil {
+		return err
+	}
+	defer stopProfiling()
+	return generateDocs(generateOutput, generateDataDir, generateTitle, generateDescription,
+		generateVersion, basePath, generateCleanup)
+}
 
-	return best
+// addGenerateFlags registers the documentation generation flags, shared by the generate and
+// record commands
+func addGenerateFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&generateOutput, "output", "o", "swagger.json", "Output file for Swagger documentation, or s3://bucket/key or gs://bucket/key to upload it to object storage")
+	flags.StringVar(&generateOutputURL, "output-url", "", "URL to upload the documentation to with an HTTP PUT, besides writing it to --output")
+	flags.StringArrayVar(&generateOutputHeaders, "output-header", []string{}, "Header of the --output-url upload in format 'Name: value', e.g. for authorization (can be used multiple times)")
+	flags.StringVar(&generateFormat, "format", "", "Output format: json, yaml, html, or markdown (default: from the output file extension)")
+	flags.StringVar(&generateHTMLBundle, "html-bundle", "", "Path to a local redoc.standalone.js to inline into html output, so the page works offline")
+	flags.StringVarP(&generateDataDir, "data-dir", "d", defaultDataDir, "Directory to read API transaction data from")
+	flags.StringVar(&generateTitle, "title", "API Documentation", "Title for the API documentation")
+	flags.StringVar(&generateDescription, "description", "Generated API documentation", "Description for the API documentation")
+	flags.StringVarP(&generateVersion, "version", "v", "1.0.0", "API version")
+	flags.StringVar(&generateBasePath, "base-path", "http://localhost:8080", "Base path for the API")
+	flags.StringArrayVar(&generateServers, "server", []string{}, "Server in format 'url=description', the URL may contain {variables} (can be used multiple times)")
+	flags.StringArrayVar(&generateServerVars, "server-variable", []string{}, "Server URL variable in format 'name=default|other|...' (can be used multiple times)")
+	flags.BoolVar(&generateInferServers, "infer-servers", false, "Add servers for the Host/X-Forwarded-Host headers of captured requests")
+	flags.BoolVar(&generateCleanup, "cleanup", false, "Delete the session files of the data directory after generating documentation")
+	flags.BoolVarP(&generateYes, "yes", "y", false, "Clean up without asking for confirmation, e.g. in scripts")
+	flags.BoolVar(&generateUsePathGroups, "group-by-path", true, "Group API endpoints by path segments")
+	flags.StringSliceVar(&generateTagMapping, "tag-mapping", []string{}, "Custom tag mappings in format 'path:tag' (can be used multiple times)")
+	flags.StringArrayVar(&generateTagDesc, "tag-description", []string{}, "Tag description in format 'tag:description' (can be used multiple times)")
+	flags.StringArrayVar(&generateTagGroup, "tag-group", []string{}, "Tag group emitted as x-tagGroups in format 'group:tag1,tag2' (can be used multiple times)")
+	flags.StringSliceVar(&generateVersionPrefix, "version-prefix", []string{}, "Custom version prefixes (can be used multiple times)")
+	flags.StringSliceVar(&generateStripPrefix, "strip-prefix", []string{}, "Path prefix to move from the paths into the server URLs, e.g. /api/v2 (can be used multiple times)")
+	flags.StringSliceVar(&generatePathTemplate, "path-template", []string{}, "Path template override in format 'pattern=template', '*' matches one segment (can be used multiple times)")
+	flags.StringArrayVar(&generateIncludePaths, "include-path", []string{}, "Regular expression for request paths to document, others are left out (can be used multiple times)")
+	flags.StringArrayVar(&generateExcludePaths, "exclude-path", []string{}, "Regular expression for request paths to leave out, e.g. '^/internal/' (can be used multiple times)")
+	flags.StringSliceVar(&generateIncludeMethod, "include-method", []string{}, "HTTP methods to document, others are left out (can be used multiple times)")
+	flags.StringSliceVar(&generateExcludeMethod, "exclude-method", []string{}, "HTTP methods to leave out, e.g. OPTIONS (can be used multiple times)")
+	flags.StringSliceVar(&generateIncludeHeader, "include-header", []string{}, "Request headers to document as parameters even if they are common, e.g. Accept-Language (can be used multiple times)")
+	flags.StringSliceVar(&generateExcludeHeader, "exclude-header", []string{}, "Request headers never to document as parameters, e.g. X-Request-Id (can be used multiple times)")
+	flags.StringSliceVar(&generateRequireHeader, "require-header", []string{}, "Request headers to document as required parameters of every operation, e.g. X-Org-Id (can be used multiple times)")
+	flags.StringVar(&generateStrategy, "response-strategy", string(openapi.StrategyBestSuccess), "Which transactions of every endpoint are documented: best-success, all-statuses, latest, or most-samples")
+	flags.StringArrayVar(&generateStatusDesc, "status-description", []string{}, "Response description in format 'code=description', replacing the standard reason phrase (can be used multiple times)")
+	flags.BoolVar(&generateConstraints, "infer-constraints", false, "Add observed min/max, length, and pattern constraints to schemas")
+	flags.Float64Var(&generateRequired, "required-threshold", 1.0, "Fraction of samples (0-1] a property must appear in to be marked required")
+	flags.BoolVar(&generateSplitMedia, "split-media-types", false, "Infer a separate schema for every versioned vendor media type (e.g. application/vnd.acme.v2+json) instead of one merged schema")
+	flags.BoolVar(&generateCodeSamples, "code-samples", true, "Add x-codeSamples snippets (curl, JavaScript, Go, Python) to every operation")
+	flags.BoolVar(&generateResponseTimes, "response-times", false, "Add x-response-time with the p50, p95, and p99 latencies of the captured requests to every operation")
+	flags.BoolVar(&generateCallFrequency, "call-frequency", false, "Add x-call-frequency with the number and share of captured requests to every operation, and list the busiest tags and operations first")
+	flags.BoolVar(&generateErrorRates, "error-rates", false, "Add x-observed-error-rate with the 5xx and 4xx responses of the captured requests to every operation, and warn about endpoints failing often")
+	flags.Float64Var(&generateErrorThreshold, "error-rate-threshold", 0.05, "Share of 5xx responses above which --error-rates warns about an endpoint")
+	flags.IntVar(&generateStaleAfter, "stale-after", 0, "Report endpoints not called in this many most recent capture sessions as stale, possibly removed routes (0 disables)")
+	flags.BoolVar(&generateDeprecateStale, "deprecate-stale", false, "Mark the endpoints --stale-after reports as deprecated")
+	flags.IntVar(&generateMinCalls, "min-calls", 0, "Leave out endpoints captured fewer than this many times, such as typos and scanner probes (0 keeps every endpoint)")
+	flags.StringVar(&generateOwners, "owners", "", "YAML or JSON file assigning owners and teams to path prefixes, emitted as x-owner/x-team")
+	flags.StringVar(&generateDescriptions, "descriptions", "", "YAML or JSON file mapping 'METHOD /templated/path' to a summary, description, and tag that replace the generated ones")
+	flags.BoolVar(&generateStrict, "strict", false, "Fail instead of warning when the generated document is not valid OpenAPI")
+	flags.BoolVar(&generateReview, "review", false, "Review every endpoint on the terminal before the document is written: keep or remove it, rename its tag, or edit its summary")
+	flags.BoolVar(&generateCI, "ci", false, "Exit non-zero when no transactions were captured or the document is not valid OpenAPI, see the exit codes in the README")
+	flags.BoolVar(&generateFailOnDiff, "fail-on-diff", false, "Exit non-zero when the generated document differs from the existing output file, e.g. a committed spec")
+	flags.BoolVar(&generateDryRun, "dry-run", false, "Print a summary of the generated document (endpoints, operations, schemas, warnings) without writing any files")
+	flags.BoolVar(&generateNoBackup, "no-backup", false, "Overwrite the output file without keeping a timestamped backup of the previous document")
+	flags.StringVar(&generateReport, "report", "", "JSON file to write the quality report of the generated document to, besides printing it")
+	flags.StringVar(&generateContact.Name, "contact-name", "", "Name of the API contact person or organization")
+	flags.StringVar(&generateContact.Email, "contact-email", "", "Email address of the API contact")
+	flags.StringVar(&generateContact.URL, "contact-url", "", "URL of the API contact information")
+	flags.StringVar(&generateLicense.Name, "license-name", "", "License name for the API, e.g. 'Apache 2.0'")
+	flags.StringVar(&generateLicense.URL, "license-url", "", "URL of the API license")
+	flags.StringVar(&generateTerms, "terms-of-service", "", "URL of the terms of service for the API")
+	flags.StringVar(&generateExternalDocs.URL, "external-docs-url", "", "URL of additional external documentation")
+	flags.StringVar(&generateExternalDocs.Description, "external-docs-description", "", "Description of the external documentation")
+	flags.StringSliceVar(&generateOverlays, "overlay", []string{}, "OpenAPI Overlay or JSON merge patch file applied to the generated document (can be used multiple times)")
+	flags.StringSliceVar(&generatePlugins, "plugin", []string{}, "Plugin processing the captured transactions or the generated document, e.g. pii for the swagdoc-plugin-pii executable on PATH (can be used multiple times)")
+	flags.BoolVar(&generateSplit, "split", false, "Write a root document plus paths/ and components/ files connected by relative $refs (json or yaml output)")
+	flags.BoolVar(&generateSplitVersions, "split-by-version", false, "Write a document per API version prefix such as /v1, into a directory per version next to the output")
+	flags.StringVar(&generateEmitSchemas, "emit-schemas", "", "Directory to write every component schema to as a standalone JSON Schema (draft 2020-12) file")
+	flags.StringVar(&generateMergeInto, "merge-into", "", "Existing OpenAPI document (JSON or YAML) to update, preserving its hand-written documentation")
+	flags.IntVar(&generateWorkers, "workers", 0, "Goroutines decoding bodies and merging schemas in parallel (default: the number of CPUs)")
+	flags.IntVar(&generateFieldSamples, "max-samples-per-field", 10, "Values of every field looked at to infer its type and format; more is more accurate but slower")
+	flags.IntVar(&generateEndpointSamples, "max-samples-per-endpoint", 1000, "Bodies kept per endpoint for inferring required fields and constraints, and distinct schemas merged; more is more accurate but slower")
+	flags.StringVar(&generateCache, "cache", "", "Analysis cache file, so that repeated runs only re-analyze endpoints with new transactions")
+	flags.StringVar(&generateCPUProfile, "cpuprofile", "", "File to write a CPU profile of the generation to, for go tool pprof")
+	flags.StringVar(&generateMemProfile, "memprofile", "", "File to write a heap profile to once the documentation is generated, for go tool pprof")
+	flags.StringVar(&generateMaxMemory, "max-memory", "", "Memory to generate within, e.g. 2GB; beyond it every endpoint is documented from a random sample of its transactions")
 }
 
 // generateDocs generates Swagger/OpenAPI documentation from API transactions
 func generateDocs(output string, dataDir string, title string, description string, version string, basePath string, cleanup bool) error {
 	// Print header
-	fmt.Println(logger.HighlightHeader(" SwagDoc Documentation Generator "))
+	logger.PrintHeader(" SwagDoc Documentation Generator ")
 	logger.PrintInfo("Generating Swagger documentation to %s", output)
 	logger.PrintInfo("Reading API transaction data from %s", dataDir)
 
 	// Create absolute path for output file
-	absOutput, err := filepath.Abs(output)
-	if err != nil {
-		logger.PrintError("Failed to get absolute path for output file: %v", err)
-		return fmt.Errorf("failed to get absolute path for output file: %v", err)
+	toStdout := output == stdoutOutput
+	remote := publish.IsRemote(output)
+	absOutput := output
+	if !toStdout && !remote {
+		var err error
+		if absOutput, err = filepath.Abs(output); err != nil {
+			logger.PrintError("Failed to get absolute path for output file: %v", err)
+			return fmt.Errorf("failed to get absolute path for output file: %v", err)
+		}
+	}
+	if toStdout && generateSplit {
+		logger.PrintError("--split writes several files and can't be used with --output -")
+		return fmt.Errorf("--split writes several files and can't be used with --output -")
+	}
+	if generateFailOnDiff && (toStdout || generateSplit) {
+		logger.PrintError("--fail-on-diff compares a single output file and can't be used with --split or --output -")
+		return fmt.Errorf("--fail-on-diff compares a single output file and can't be used with --split or --output -")
+	}
+	if generateSplitVersions && toStdout {
+		logger.PrintError("--split-by-version writes several documents and can't be used with --output -")
+		return fmt.Errorf("--split-by-version writes several documents and can't be used with --output -")
+	}
+	if remote && (generateSplit || generateSplitVersions || generateFailOnDiff) {
+		logger.PrintError("--split, --split-by-version, and --fail-on-diff write local files and can't be used with an object storage output")
+		return fmt.Errorf("--split, --split-by-version, and --fail-on-diff write local files and can't be used with an object storage output")
+	}
+	if generateOutputURL != "" && (generateSplit || generateSplitVersions) {
+		logger.PrintError("--output-url uploads a single document and can't be used with --split or --split-by-version")
+		return fmt.Errorf("--output-url uploads a single document and can't be used with --split or --split-by-version")
+	}
+	if _, err := outputHeaders(); err != nil {
+		return err
+	}
+	if generateSplitVersions && generateMergeInto != "" {
+		logger.PrintError("--split-by-version can't be used with --merge-into")
+		return fmt.Errorf("--split-by-version can't be used with --merge-into")
 	}
 
-	// Create storage to read API transactions
-	storage, err := proxy.NewFileStorage(dataDir)
+	progress := newProgressReporter()
+	progress.startPhase(phaseLoad)
+	transactions, sessions, err := loadTransactions(dataDir)
 	if err != nil {
-		logger.PrintError("Failed to create storage: %v", err)
-		return fmt.Errorf("failed to create storage: %v", err)
+		return err
 	}
 
-	// Get all transactions
-	transactions, err := storage.GetAll()
-	if err != nil {
-		logger.PrintError("Failed to read API transactions: %v", err)
-		return fmt.Errorf("failed to read API transactions: %v", err)
+	// Document every API version on its own, with its prefix in the server URLs
+	var versions []openapi.APIVersion
+	if generateSplitVersions {
+		versions = openapi.SplitByVersion(transactions)
+		if len(versions) == 0 {
+			logger.PrintWarning("No version prefix such as /v1 found in the captured paths, generating a single document")
+		}
+	}
+
+	var changed []string
+	if len(versions) == 0 {
+		cache := loadAnalysisCache(generateCache)
+		spec, stats, err := specFromTransactions(transactions, sessions, title, description, version, basePath, nil, cache, progress.update)
+		if err != nil {
+			return err
+		}
+		saveAnalysisCache(cache, generateCache, stats)
+		progress.startPhase(phaseWrite)
+		fileChanged, err := writeDocs(spec, absOutput, toStdout, generateEmitSchemas)
+		if err != nil {
+			return err
+		}
+		if err := reportQuality(spec, stats, generateReport); err != nil {
+			return err
+		}
+		if fileChanged {
+			changed = append(changed, absOutput)
+		}
+	}
+	for _, apiVersion := range versions {
+		logger.PrintInfo("Generating documentation of %s from %d API transactions", apiVersion.Name, len(apiVersion.Transactions))
+		cachePath := generateCache
+		if cachePath != "" {
+			cachePath = versionOutput(cachePath, apiVersion.Name)
+		}
+		cache := loadAnalysisCache(cachePath)
+		spec, stats, err := specFromTransactions(apiVersion.Transactions, sessions, title, description, version, basePath, apiVersion.Prefixes, cache, progress.update)
+		if err != nil {
+			return err
+		}
+		saveAnalysisCache(cache, cachePath, stats)
+		progress.startPhase(phaseWrite)
+		emitSchemas := generateEmitSchemas
+		if emitSchemas != "" {
+			emitSchemas = filepath.Join(emitSchemas, apiVersion.Name)
+		}
+		versionPath := versionOutput(absOutput, apiVersion.Name)
+		fileChanged, err := writeDocs(spec, versionPath, false, emitSchemas)
+		if err != nil {
+			return err
+		}
+		reportPath := generateReport
+		if reportPath != "" {
+			reportPath = versionOutput(reportPath, apiVersion.Name)
+		}
+		if err := reportQuality(spec, stats, reportPath); err != nil {
+			return err
+		}
+		if fileChanged {
+			changed = append(changed, versionPath)
+		}
 	}
 
-	logger.PrintInfo("Found %d API transactions across all session files", len(transactions))
+	progress.printTimings()
+	if generateDryRun {
+		return nil
+	}
 
-	// Filter transactions to prioritize successful responses
-	filteredTransactions := prioritizeSuccessfulResponses(transactions)
+	// The files are updated anyway, so the changes can be inspected with e.g. git diff
+	if len(changed) > 0 {
+		files := strings.Join(changed, ", ")
+		logger.PrintError("Generated documentation differs from %s", files)
+		return &exitError{exitSpecChanged, fmt.Errorf("generated documentation differs from %s", files)}
+	}
 
-	logger.PrintSuccess("Using %d API transactions after prioritizing successful responses", len(filteredTransactions))
+	return cleanupDataDir(dataDir, cleanup)
+}
 
-	// Create OpenAPI generator with configuration
-	config := openapi.OpenAPIConfig{
-		Title:           title,
-		Description:     description,
-		Version:         version,
-		UsePathGroups:   generateUsePathGroups,
-		TagMappings:     make(map[string]string),
-		VersionPrefixes: make(map[string]bool),
-		Servers: []openapi.OpenAPIServer{
-			{
-				URL:         basePath,
-				Description: "API Server",
-			},
-		},
+// versionOutput is the output path of the documentation of an API version: the output file or
+// directory in a directory named after the version, e.g. v1/swagger.json
+func versionOutput(output string, version string) string {
+	return filepath.Join(filepath.Dir(output), version, filepath.Base(output))
+}
+
+// writeDocs reviews, validates, and writes a generated document to the output path in the
+// requested format, and reports whether it differs from the existing file with --fail-on-diff
+func writeDocs(spec *openapi.OpenAPISpec, absOutput string, toStdout bool, emitSchemas string) (bool, error) {
+	// Let the user curate the endpoints
+	if generateReview {
+		if err := openapi.ReviewSpec(spec, os.Stdin, logger.Output()); err != nil {
+			logger.PrintError("Failed to review endpoints: %v", err)
+			return false, fmt.Errorf("failed to review endpoints: %v", err)
+		}
 	}
 
-	// Process tag mappings from command line
-	for _, mapping := range generateTagMapping {
-		parts := strings.SplitN(mapping, ":", 2)
-		if len(parts) == 2 {
-			config.TagMappings[parts[0]] = parts[1]
+	// Validate the document before anything is written
+	if err := openapi.ValidateSpec(spec); err != nil {
+		if generateStrict || generateCI {
+			logger.PrintError("Generated specification failed validation: %v", err)
+			return false, &exitError{exitInvalidSpec, fmt.Errorf("generated specification failed validation: %v", err)}
 		}
+		logger.PrintWarning("Generated specification failed validation: %v", err)
 	}
 
-	// Process version prefixes from command line
-	for _, prefix := range generateVersionPrefix {
-		config.VersionPrefixes[prefix] = true
+	// Preview the document instead of writing it
+	if generateDryRun {
+		printDryRun(spec, absOutput, toStdout)
+		return false, nil
 	}
 
-	// Create generator
-	generator := openapi.NewOpenAPIGenerator(config)
+	// Write the component schemas for validation outside OpenAPI
+	if emitSchemas != "" {
+		if err := openapi.WriteJSONSchemas(spec, emitSchemas); err != nil {
+			logger.PrintError("Failed to write JSON Schemas: %v", err)
+			return false, fmt.Errorf("failed to write JSON Schemas: %v", err)
+		}
+		logger.PrintSuccess("JSON Schemas written to %s", emitSchemas)
+	}
 
-	// Add all transactions
-	for _, tx := range filteredTransactions {
-		generator.AddTransaction(tx)
+	// Marshal spec in the requested format, or the one implied by the output file extension
+	format := generateFormat
+	if format == "" {
+		format = openapi.FormatForPath(absOutput)
 	}
 
-	// Generate specification
-	spec, err := generator.GenerateSpec()
+	// Markdown pages go into a directory unless a single .md file or stdout was asked for
+	remote := publish.IsRemote(absOutput)
+	if format == openapi.FormatMarkdown && !toStdout && openapi.FormatForPath(absOutput) != openapi.FormatMarkdown {
+		if generateFailOnDiff {
+			logger.PrintError("--fail-on-diff compares a single output file and can't be used with Markdown directories")
+			return false, fmt.Errorf("--fail-on-diff compares a single output file and can't be used with Markdown directories")
+		}
+		if remote || generateOutputURL != "" {
+			logger.PrintError("Markdown directories can't be uploaded, use a .md output for a single Markdown page")
+			return false, fmt.Errorf("markdown directories can't be uploaded, use a .md output for a single Markdown page")
+		}
+		if err := openapi.WriteMarkdown(spec, absOutput); err != nil {
+			logger.PrintError("Failed to write Markdown documentation: %v", err)
+			return false, fmt.Errorf("failed to write Markdown documentation: %v", err)
+		}
+		logger.PrintSuccess("Markdown documentation generated successfully: %s", absOutput)
+		return false, nil
+	}
+
+	// Split documents are written as a root file with the paths and components next to it
+	if generateSplit {
+		if err := openapi.WriteSplit(spec, absOutput, format); err != nil {
+			logger.PrintError("Failed to write split specification: %v", err)
+			return false, fmt.Errorf("failed to write split specification: %v", err)
+		}
+		logger.PrintSuccess("Split Swagger documentation generated successfully: %s", absOutput)
+		return false, nil
+	}
+
+	var data []byte
+	var err error
+	if format == openapi.FormatHTML && generateHTMLBundle != "" {
+		bundle, readErr := os.ReadFile(generateHTMLBundle)
+		if readErr != nil {
+			logger.PrintError("Failed to read HTML bundle: %v", readErr)
+			return false, fmt.Errorf("failed to read HTML bundle: %v", readErr)
+		}
+		data, err = openapi.RenderHTML(spec, bundle)
+	} else {
+		data, err = openapi.MarshalSpecFormat(spec, format)
+	}
 	if err != nil {
-		logger.PrintError("Failed to generate specification: %v", err)
-		return fmt.Errorf("failed to generate specification: %v", err)
+		logger.PrintError("Failed to marshal specification: %v", err)
+		return false, fmt.Errorf("failed to marshal specification: %v", err)
+	}
+
+	// Upload to object storage instead of writing a local file
+	if remote {
+		if err := uploadDocs(absOutput, data, format); err != nil {
+			return false, err
+		}
+		return false, putDocs(data, format)
+	}
+
+	// Stream to stdout for shell pipelines
+	if toStdout {
+		if _, err := os.Stdout.Write(data); err != nil {
+			logger.PrintError("Failed to write specification to stdout: %v", err)
+			return false, fmt.Errorf("failed to write specification to stdout: %v", err)
+		}
+		logger.PrintSuccess("Swagger documentation written to stdout")
+		return false, putDocs(data, format)
 	}
 
 	// Create output directory if needed
 	outDir := filepath.Dir(absOutput)
 	if err := os.MkdirAll(outDir, 0755); err != nil {
 		logger.PrintError("Failed to create output directory: %v", err)
-		return fmt.Errorf("failed to create output directory: %v", err)
+		return false, fmt.Errorf("failed to create output directory: %v", err)
 	}
 
-	// Marshal spec to JSON
-	data, err := json.MarshalIndent(spec, "", "  ")
-	if err != nil {
-		logger.PrintError("Failed to marshal specification: %v", err)
-		return fmt.Errorf("failed to marshal specification: %v", err)
+	// Compare with the existing document before it is replaced
+	existing, readErr := os.ReadFile(absOutput)
+	changed := readErr != nil || !bytes.Equal(existing, data)
+
+	// Keep the previous document, which may have been curated by hand, unless it is unchanged
+	if readErr == nil && changed && !generateNoBackup {
+		backup, err := openapi.BackupFile(absOutput, time.Now())
+		if err != nil {
+			logger.PrintError("Failed to back up the previous documentation: %v", err)
+			return false, fmt.Errorf("failed to back up the previous documentation: %v", err)
+		}
+		logger.PrintInfo("Previous documentation backed up to %s", backup)
 	}
 
-	// Write to output file
-	if err := os.WriteFile(absOutput, data, 0644); err != nil {
+	// Write to output file through a temporary file, so that a failed write keeps the old one
+	if err := openapi.WriteFileAtomic(absOutput, data); err != nil {
 		logger.PrintError("Failed to write specification to file: %v", err)
-		return fmt.Errorf("failed to write specification to file: %v", err)
+		return false, fmt.Errorf("failed to write specification to file: %v", err)
 	}
 
 	logger.PrintSuccess("Swagger documentation generated successfully: %s", absOutput)
+	if err := putDocs(data, format); err != nil {
+		return false, err
+	}
+	return changed && generateFailOnDiff, nil
+}
+
+// printDryRun prints a summary of a generated document and where it would have been written
+func printDryRun(spec *openapi.OpenAPISpec, absOutput string, toStdout bool) {
+	summary := openapi.SummarizeSpec(spec)
+	destination := absOutput
+	if toStdout {
+		destination = "stdout"
+	}
+	logger.PrintSummary("Dry run, nothing written to %s", destination)
+	logger.PrintSummary("Endpoints: %d, operations: %d, schemas: %d, warnings: %d",
+		summary.Endpoints, summary.Operations, summary.Schemas, len(summary.Warnings))
+	for _, warning := range summary.Warnings {
+		logger.PrintWarning("%s", warning)
+	}
+}
+
+// reportQuality prints the quality report of a written document, and writes it as JSON to
+// reportPath unless that is empty
+func reportQuality(spec *openapi.OpenAPISpec, stats openapi.GenerationStats, reportPath string) error {
+	if generateDryRun {
+		return nil
+	}
+
+	report := openapi.NewQualityReport(spec, stats)
+	logger.PrintInfo("Quality: %d operations, %d schemas, %.1f%% of operations with examples, %.1f%% with security",
+		report.Operations, report.Schemas, report.OperationsWithExamples, report.OperationsWithSecurity)
+	if len(report.MixedTypeFields) > 0 {
+		logger.PrintWarning("%d fields were captured with mixed types, which no single schema type describes:", len(report.MixedTypeFields))
+		for _, field := range report.MixedTypeFields {
+			logger.PrintWarning("  %s", field)
+		}
+	}
+	if len(report.SingleSampleEndpoints) > 0 {
+		logger.PrintInfo("%d endpoints were documented from a single sample, capture more traffic to refine them:", len(report.SingleSampleEndpoints))
+		for _, endpoint := range report.SingleSampleEndpoints {
+			logger.PrintInfo("  %s", endpoint)
+		}
+	}
+
+	if len(report.StaleEndpoints) > 0 {
+		logger.PrintWarning("%d endpoints were not called in the last %d sessions and may have been removed:", len(report.StaleEndpoints), generateStaleAfter)
+		for _, endpoint := range report.StaleEndpoints {
+			logger.PrintWarning("  %s", endpoint)
+		}
+	}
+
+	if reportPath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(reportPath), 0755); err != nil {
+		logger.PrintError("Failed to create report directory: %v", err)
+		return fmt.Errorf("failed to create report directory: %v", err)
+	}
+	if err := openapi.WriteQualityReport(report, reportPath); err != nil {
+		logger.PrintError("Failed to write quality report: %v", err)
+		return fmt.Errorf("failed to write quality report: %v", err)
+	}
+	logger.PrintSuccess("Quality report written to %s", reportPath)
+	return nil
+}
+
+// loadAnalysisCache reads the analysis cache of --cache, or returns nil without one. An unreadable
+// cache is only a reason to analyze everything again, not to fail.
+func loadAnalysisCache(path string) *openapi.AnalysisCache {
+	if path == "" {
+		return nil
+	}
+	cache, err := openapi.LoadAnalysisCache(path)
+	if err != nil {
+		logger.PrintWarning("Ignoring the analysis cache: %v", err)
+		return openapi.NewAnalysisCache()
+	}
+	return cache
+}
+
+// saveAnalysisCache writes the updated analysis cache for the next run
+func saveAnalysisCache(cache *openapi.AnalysisCache, path string, stats openapi.GenerationStats) {
+	if cache == nil {
+		return
+	}
+	logger.PrintInfo("Reused the cached analysis of %d of %d endpoints", stats.CachedEndpoints, len(cache.Endpoints))
+	if err := cache.Save(path); err != nil {
+		logger.PrintWarning("Failed to write the analysis cache: %v", err)
+	}
+}
+
+// suggestFilters prints the exclude rules suggested for the captured transactions, or adds them
+// to the configuration file
+func suggestFilters(dataDir string, write bool) error {
+	storage, err := proxy.NewFileStorage(dataDir)
+	if err != nil {
+		logger.PrintError("Failed to create storage: %v", err)
+		return fmt.Errorf("failed to create storage: %v", err)
+	}
+	// Stream the transactions, so that huge captures don't have to fit into memory
+	suggester := openapi.NewFilterSuggester()
+	seen := 0
+	if err := proxy.EachTransaction(storage, func(tx proxy.APITransaction) error {
+		suggester.Add(tx)
+		seen++
+		return nil
+	}); err != nil {
+		logger.PrintError("Failed to read API transactions: %v", err)
+		return fmt.Errorf("failed to read API transactions: %v", err)
+	}
+
+	suggestions := suggester.Suggestions()
+	if len(suggestions) == 0 {
+		logger.PrintSuccess("Nothing to exclude among %d API transactions", seen)
+		return nil
+	}
+
+	values := make(map[string][]string)
+	for _, suggestion := range suggestions {
+		logger.PrintInfo("--%s '%s' leaves out %d %s, e.g. %s", suggestion.Kind, suggestion.Value,
+			suggestion.Matches, suggestion.Reason, strings.Join(suggestion.Examples, ", "))
+		values[suggestion.Kind] = append(values[suggestion.Kind], suggestion.Value)
+	}
+	kinds := []string{openapi.FilterExcludePath, openapi.FilterExcludeMethod}
+
+	// Rules of a profile go into the profile's generate section
+	keys := []string{"generate"}
+	if profile != "" {
+		keys = []string{"profiles", profile, "generate"}
+	}
+
+	if !write {
+		snippet := make(map[string]interface{})
+		for _, kind := range kinds {
+			if len(values[kind]) > 0 {
+				snippet[kind] = values[kind]
+			}
+		}
+		for i := len(keys) - 1; i >= 0; i-- {
+			snippet = map[string]interface{}{keys[i]: snippet}
+		}
+		logger.PrintInfo("Add the rules to the configuration file, or run again with --write:")
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(snippet); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
 
-	// Clean up data directory if requested
-	if cleanup {
-		logger.PrintInfo("Cleaning up data directory: %s", dataDir)
-		if err := os.RemoveAll(dataDir); err != nil {
-			logger.PrintError("Failed to clean up data directory: %v", err)
-			return fmt.Errorf("failed to clean up data directory: %v", err)
+	path := configWritePath()
+	if format := strings.ToLower(filepath.Ext(path)); format != ".yaml" && format != ".yml" {
+		logger.PrintError("--write only supports YAML configuration files, not %s", path)
+		return fmt.Errorf("--write only supports YAML configuration files, not %s", path)
+	}
+	total := 0
+	for _, kind := range kinds {
+		if len(values[kind]) == 0 {
+			continue
+		}
+		added, err := addConfigValues(path, append(append([]string(nil), keys...), kind), values[kind])
+		if err != nil {
+			logger.PrintError("Failed to update %s: %v", path, err)
+			return fmt.Errorf("failed to update %s: %v", path, err)
 		}
-		logger.PrintSuccess("Data directory cleaned up successfully")
+		total += len(added)
+	}
+	if total == 0 {
+		logger.PrintSuccess("%s already has every suggested rule", path)
+		return nil
+	}
+	logger.PrintSuccess("Added %d rules to %s", total, path)
+	return nil
+}
+
+// checkForUpdate fetches the latest release and reports whether it is newer than this binary.
+// The release is returned only when it is newer.
+func checkForUpdate() (*update.Release, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	release, err := update.LatestRelease(ctx, http.DefaultClient, update.LatestReleaseURL)
+	if err != nil {
+		logger.PrintError("Failed to check for a newer release: %v", err)
+		return nil, fmt.Errorf("failed to check for a newer release: %v", err)
+	}
+	if !update.IsNewer(release.Version(), version) {
+		logger.PrintSuccess("swagdoc %s is the latest release", version)
+		return nil, nil
+	}
+	logger.PrintInfo("swagdoc %s is available, you have %s: %s", release.Version(), version, release.HTMLURL)
+	return release, nil
+}
+
+// runSelfUpdate replaces the running executable with the binary of the latest release for this
+// platform, if that release is newer
+func runSelfUpdate(checkOnly bool) error {
+	release, err := checkForUpdate()
+	if err != nil || release == nil {
+		return err
+	}
+	if checkOnly {
+		logger.PrintInfo("Run swagdoc self-update to install it")
+		return nil
+	}
+
+	name, ok := update.AssetName(runtime.GOOS, runtime.GOARCH)
+	if !ok {
+		logger.PrintError("No prebuilt binary for %s/%s, install with go install github.com/parnexcodes/swag-doc/cmd/swagdoc@latest", runtime.GOOS, runtime.GOARCH)
+		return fmt.Errorf("no prebuilt binary for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	asset, ok := release.Asset(name)
+	if !ok {
+		logger.PrintError("Release %s has no %s binary", release.TagName, name)
+		return fmt.Errorf("release %s has no %s binary", release.TagName, name)
+	}
+
+	executable, err := os.Executable()
+	if err == nil {
+		executable, err = filepath.EvalSymlinks(executable)
+	}
+	if err != nil {
+		logger.PrintError("Failed to locate the swagdoc executable: %v", err)
+		return fmt.Errorf("failed to locate the swagdoc executable: %v", err)
+	}
+
+	logger.PrintInfo("Downloading %s", asset.DownloadURL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	checksum, err := update.Checksum(ctx, http.DefaultClient, release, name)
+	if err != nil {
+		logger.PrintError("Failed to verify swagdoc %s: %v", release.Version(), err)
+		return fmt.Errorf("failed to verify swagdoc %s: %v", release.Version(), err)
+	}
+	if err := update.Install(ctx, http.DefaultClient, asset.DownloadURL, executable, checksum); err != nil {
+		logger.PrintError("Failed to install swagdoc %s: %v", release.Version(), err)
+		return fmt.Errorf("failed to install swagdoc %s: %v", release.Version(), err)
+	}
+	logger.PrintSuccess("Updated %s to swagdoc %s", executable, release.Version())
+	return nil
+}
+
+// serveDocs serves an OpenAPI document with a documentation UI until the process is stopped
+func serveDocs(path string, port int, ui string) error {
+	if _, err := openapi.LoadSpec(path); err != nil {
+		logger.PrintError("%v", err)
+		return err
+	}
+
+	handler, err := openapi.ServeHandler(path, ui)
+	if err != nil {
+		logger.PrintError("%v", err)
+		return err
+	}
+
+	logger.PrintSuccess("Serving %s at http://localhost:%d", path, port)
+	logger.PrintInfo("The page reloads when the file changes, press Ctrl+C to stop")
+	if err := http.ListenAndServe(fmt.Sprintf(":%d", port), handler); err != nil {
+		logger.PrintError("Server error: %v", err)
+		return fmt.Errorf("server error: %v", err)
+	}
+	return nil
+}
+
+// lintDocs lints an OpenAPI document and prints the issues found to stdout
+func lintDocs(path string, rules []string, format string) error {
+	if format != "text" && format != "json" {
+		logger.PrintError("Unknown lint format %q, expected text or json", format)
+		return fmt.Errorf("unknown lint format %q, expected text or json", format)
+	}
+
+	severities := make(map[string]openapi.LintSeverity)
+	for _, rule := range rules {
+		name, value, ok := strings.Cut(rule, "=")
+		severity, err := openapi.ParseLintSeverity(value)
+		if !ok || err != nil {
+			logger.PrintError("Invalid rule %q, expected 'rule=severity' with error, warning, info, or off", rule)
+			return fmt.Errorf("invalid rule %q, expected 'rule=severity' with error, warning, info, or off", rule)
+		}
+		severities[name] = severity
+	}
+
+	spec, err := openapi.LoadSpec(path)
+	if err != nil {
+		logger.PrintError("%v", err)
+		return err
+	}
+
+	issues, err := openapi.LintSpec(spec, severities)
+	if err != nil {
+		logger.PrintError("%v", err)
+		return err
+	}
+
+	errorCount := 0
+	for _, issue := range issues {
+		if issue.Severity == openapi.LintError {
+			errorCount++
+		}
+	}
+
+	if format == "json" {
+		if issues == nil {
+			issues = []openapi.LintIssue{}
+		}
+		data, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, issue := range issues {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", issue.Severity, issue.Location, issue.Message, issue.Rule)
+		}
+		writer.Flush()
+		if len(issues) == 0 {
+			logger.PrintSuccess("No issues found in %s", path)
+		} else {
+			logger.PrintInfo("%d issues found in %s, %d of them errors", len(issues), path, errorCount)
+		}
+	}
+
+	if errorCount > 0 {
+		return &exitError{exitLintErrors, fmt.Errorf("%d lint errors found in %s", errorCount, path)}
+	}
+	return nil
+}
+
+// doctorMaxProblems is how many malformed transactions of a session file the doctor lists
+const doctorMaxProblems = 5
+
+// runDoctor diagnoses the data directory, the session files in it, and the proxy setup, and
+// fails when a problem was found
+func runDoctor(dataDir string, target string, port int) error {
+	logger.PrintHeader(" SwagDoc Doctor ")
+
+	problems := 0
+	problem := func(suggestion string, format string, args ...interface{}) {
+		problems++
+		logger.PrintError(format, args...)
+		logger.PrintInfo("Suggestion: %s", suggestion)
+	}
+
+	// The data directory must exist and be writable for the proxy to store transactions
+	info, err := os.Stat(dataDir)
+	switch {
+	case os.IsNotExist(err):
+		problem("Capture traffic with swagdoc proxy or swagdoc record first, or pass --data-dir where it was saved",
+			"Data directory %s does not exist", dataDir)
+	case err != nil:
+		problem("Check the permissions of the data directory", "Failed to access data directory %s: %v", dataDir, err)
+	case !info.IsDir():
+		problem("Pass the directory the transactions were saved to with --data-dir", "%s is not a directory", dataDir)
+	default:
+		if file, err := os.CreateTemp(dataDir, ".swagdoc-doctor-*"); err != nil {
+			problem("Fix the permissions of the data directory, or use another one with --data-dir",
+				"Data directory %s is not writable: %v", dataDir, err)
+		} else {
+			file.Close()
+			os.Remove(file.Name())
+			logger.PrintSuccess("Data directory %s is writable", dataDir)
+		}
+
+		checkSessionFiles(dataDir, problem)
+	}
+
+	// The target must answer for the proxy to capture anything
+	if target == "" {
+		logger.PrintInfo("Skipped the target check, pass --target to check that the API server is reachable")
+	} else if parsed, err := url.Parse(target); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		problem("Pass the full URL of the API server, e.g. http://localhost:3000", "Invalid target URL %q", target)
+	} else {
+		client := &http.Client{Timeout: 5 * time.Second}
+		if resp, err := client.Get(target); err != nil {
+			problem("Check the URL and that the API server is running and reachable from this machine",
+				"Target %s is not reachable: %v", target, err)
+		} else {
+			resp.Body.Close()
+			logger.PrintSuccess("Target %s is reachable (status %d)", target, resp.StatusCode)
+		}
+	}
+
+	// The proxy port must be free
+	if listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port)); err != nil {
+		problem("Stop the process using the port, or run the proxy on another one with --port",
+			"Port %d is not available: %v", port, err)
+	} else {
+		listener.Close()
+		logger.PrintSuccess("Port %d is available for the proxy", port)
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("doctor found %d problem(s)", problems)
+	}
+	logger.PrintSuccess("No problems found")
+	return nil
+}
+
+// checkSessionFiles reports the session files of the data directory that can't be read, their
+// malformed transactions, and data directories without any transactions
+func checkSessionFiles(dataDir string, problem func(suggestion string, format string, args ...interface{})) {
+	reports, err := proxy.InspectSessionFiles(dataDir)
+	if err != nil {
+		problem("Check the permissions of the data directory", "Failed to read data directory %s: %v", dataDir, err)
+		return
+	}
+
+	total := 0
+	for _, report := range reports {
+		if report.Err != nil {
+			problem("Fix or remove the file, it is skipped during generation", "Session file %s can't be read: %v", report.Path, report.Err)
+			continue
+		}
+		total += report.Transactions
+		if len(report.Malformed) == 0 {
+			continue
+		}
+
+		logger.PrintWarning("Session file %s has %d problem(s) with its transactions:", report.Path, len(report.Malformed))
+		for i, malformed := range report.Malformed {
+			if i == doctorMaxProblems {
+				logger.PrintWarning("  ... and %d more", len(report.Malformed)-doctorMaxProblems)
+				break
+			}
+			logger.PrintWarning("  %s", malformed)
+		}
+		logger.PrintInfo("Suggestion: Edit or remove the malformed transactions, or capture the traffic again")
+	}
+
+	switch {
+	case len(reports) == 0:
+		problem("Send requests through the proxy, e.g. swagdoc proxy --target http://api.example.com, then call http://localhost:8080",
+			"No session files in %s, generate would produce an empty document", dataDir)
+	case total == 0:
+		problem("Send requests through the proxy before generating", "Session files in %s contain no transactions, generate would produce an empty document", dataDir)
+	default:
+		logger.PrintSuccess("Found %d API transactions in %d session file(s)", total, len(reports))
+		if index, err := proxy.LoadIndex(dataDir); err == nil && index.Duplicates() > 0 {
+			logger.PrintInfo("%d of them repeat an earlier transaction with the same endpoint, status, and body structure", index.Duplicates())
+		}
+	}
+}
+
+// exportOutputOr returns the export output file, or the default file of the format
+func exportOutputOr(defaultOutput string) string {
+	if exportOutput != "" {
+		return exportOutput
+	}
+	return defaultOutput
+}
+
+// exportCollection generates the document from the captured transactions and writes it in an
+// API client's format
+func exportCollection(kind string, output string, write func(*openapi.OpenAPISpec, string) error) error {
+	// Print header
+	logger.PrintHeader(" SwagDoc Export ")
+	logger.PrintInfo("Exporting %s to %s", kind, output)
+	logger.PrintInfo("Reading API transaction data from %s", exportDataDir)
+
+	spec, err := buildSpec(exportDataDir, exportTitle, exportDescription, exportVersion, exportBasePath)
+	if err != nil {
+		return err
+	}
+
+	if err := write(spec, output); err != nil {
+		logger.PrintError("Failed to write %s: %v", kind, err)
+		return fmt.Errorf("failed to write %s: %v", kind, err)
+	}
+
+	logger.PrintSuccess("%s exported successfully: %s", kind, output)
+	return nil
+}
+
+// exportContractTests generates the document from the captured transactions and writes contract
+// tests replaying them in the language of --lang
+func exportContractTests() error {
+	var render func(openapi.ContractTestPlan) ([]byte, error)
+	output := exportOutputOr("contract_test.go")
+	switch exportTestsLang {
+	case "go":
+		render = func(plan openapi.ContractTestPlan) ([]byte, error) {
+			return openapi.RenderContractTestsGo(plan, exportTestsPkg)
+		}
+	case "json":
+		render = openapi.RenderContractTestPlan
+		output = exportOutputOr("contract-tests.json")
+	default:
+		logger.PrintError("Unknown test language %q, expected go or json", exportTestsLang)
+		return fmt.Errorf("unknown test language %q, expected go or json", exportTestsLang)
+	}
+
+	// Print header
+	logger.PrintHeader(" SwagDoc Export ")
+	logger.PrintInfo("Exporting contract tests to %s", output)
+	logger.PrintInfo("Reading API transaction data from %s", exportDataDir)
+
+	transactions, sessions, err := loadTransactions(exportDataDir)
+	if err != nil {
+		return err
+	}
+	spec, _, err := specFromTransactions(transactions, sessions, exportTitle, exportDescription, exportVersion, exportBasePath, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	if transactions, err = openapi.SelectTransactions(transactions, openapi.ResponseStrategy(generateStrategy)); err != nil {
+		logger.PrintError("Failed to select API transactions: %v", err)
+		return fmt.Errorf("failed to select API transactions: %v", err)
+	}
+	plan, err := openapi.NewContractTestPlan(spec, transactions)
+	if err != nil {
+		logger.PrintError("Failed to build contract tests: %v", err)
+		return fmt.Errorf("failed to build contract tests: %v", err)
+	}
+
+	if err := exportFile(func(*openapi.OpenAPISpec) ([]byte, error) { return render(plan) })(spec, output); err != nil {
+		logger.PrintError("Failed to write contract tests: %v", err)
+		return fmt.Errorf("failed to write contract tests: %v", err)
+	}
+
+	logger.PrintSuccess("%d contract tests exported successfully: %s", len(plan.Tests), output)
+	return nil
+}
+
+// publishDocs publishes a document file, or the document generated from the captured
+// transactions when path is empty, to the API catalog of --provider
+func publishDocs(path string, setVersion bool) error {
+	// Print header
+	logger.PrintHeader(" SwagDoc Publish ")
+
+	var spec *openapi.OpenAPISpec
+	var err error
+	if path != "" {
+		logger.PrintInfo("Publishing %s to %s", path, publishProvider)
+		if spec, err = openapi.LoadSpec(path); err != nil {
+			logger.PrintError("%v", err)
+			return err
+		}
+		if setVersion {
+			spec.Info.Version = publishVersion
+		}
+	} else {
+		logger.PrintInfo("Publishing to %s", publishProvider)
+		logger.PrintInfo("Reading API transaction data from %s", publishDataDir)
+		if spec, err = buildSpec(publishDataDir, publishTitle, publishDescription, publishVersion, publishBasePath); err != nil {
+			return err
+		}
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+	result, err := publish.Publish(context.Background(), client, publishProvider, spec, publish.Options{
+		Name:      publishName,
+		Owner:     publishOwner,
+		APIKey:    publishAPIKey,
+		Bump:      publishBump,
+		Private:   publishPrivate,
+		URL:       publishRegistry,
+		Branch:    publishBranch,
+		Output:    publishOutput,
+		Lifecycle: publishLifecycle,
+		System:    publishSystem,
+	})
+	if err != nil {
+		logger.PrintError("Failed to publish to %s: %v", publishProvider, err)
+		return fmt.Errorf("failed to publish to %s: %v", publishProvider, err)
+	}
+
+	logger.PrintSuccess("Published version %s to %s", result.Version, result.Location)
+	return nil
+}
+
+// exportFile returns a writer that renders a document into a single file, or to stdout for -
+func exportFile(render func(*openapi.OpenAPISpec) ([]byte, error)) func(*openapi.OpenAPISpec, string) error {
+	return func(spec *openapi.OpenAPISpec, output string) error {
+		data, err := render(spec)
+		if err != nil {
+			return err
+		}
+		if output == stdoutOutput {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(output, data, 0644)
+	}
+}
+
+// buildSpec generates the OpenAPI document for the transactions captured in the data directory,
+// applying the generate command's options
+func buildSpec(dataDir string, title string, description string, version string, basePath string) (*openapi.OpenAPISpec, error) {
+	transactions, sessions, err := loadTransactions(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	spec, _, err := specFromTransactions(transactions, sessions, title, description, version, basePath, nil, nil, nil)
+	return spec, err
+}
+
+// loadTransactions reads the transactions captured in the data directory, along with the start
+// times of its sessions, against which --stale-after tells stale endpoints, and validates the
+// response strategy that selects the ones documented
+func loadTransactions(dataDir string) ([]proxy.APITransaction, []time.Time, error) {
+	// Create storage to read API transactions
+	storage, err := proxy.NewFileStorage(dataDir)
+	if err != nil {
+		logger.PrintError("Failed to create storage: %v", err)
+		return nil, nil, fmt.Errorf("failed to create storage: %v", err)
+	}
+
+	// Stream the transactions, sampling every endpoint once the memory budget is used up
+	maxMemory, err := parseByteSize(generateMaxMemory)
+	if err != nil {
+		logger.PrintError("Invalid --max-memory: %v", err)
+		return nil, nil, fmt.Errorf("invalid --max-memory: %v", err)
+	}
+	if maxMemory > 0 {
+		debug.SetMemoryLimit(maxMemory)
+	}
+	collected := openapi.NewBoundedTransactions(maxMemory / transactionMemoryShare)
+	if err := storage.Each(func(tx proxy.APITransaction) error {
+		collected.Add(tx)
+		return nil
+	}); err != nil {
+		logger.PrintError("Failed to read API transactions: %v", err)
+		return nil, nil, fmt.Errorf("failed to read API transactions: %v", err)
+	}
+	transactions := collected.Transactions()
+	// The session files were just read, so listing them again only fails in a race worth ignoring
+	sessions, _ := proxy.SessionStarts(dataDir)
+
+	logger.PrintInfo("Found %d API transactions across all session files", collected.Seen())
+	if transactions, err = processTransactions(transactions); err != nil {
+		return nil, nil, err
+	}
+	if collected.Dropped() > 0 {
+		logger.PrintWarning("Reached the memory budget of --max-memory %s, documenting every endpoint from a random sample of its transactions: %d of %d transactions left out",
+			generateMaxMemory, collected.Dropped(), collected.Seen())
+	}
+	if generateCI && len(transactions) == 0 {
+		logger.PrintError("No API transactions found in %s", dataDir)
+		return nil, nil, &exitError{exitNoTransactions, fmt.Errorf("no API transactions found in %s", dataDir)}
+	}
+
+	// The generator chooses which transactions of every endpoint end up in the document, after
+	// the traffic statistics have counted all of them
+	strategy, err := openapi.ParseResponseStrategy(generateStrategy)
+	if err != nil {
+		logger.PrintError("Invalid response strategy: %v", err)
+		return nil, nil, fmt.Errorf("invalid response strategy: %v", err)
+	}
+
+	logger.PrintSuccess("Using %d API transactions, documented by the %s response strategy", len(transactions), strategy)
+	return transactions, sessions, nil
+}
+
+// transactionMemoryShare is the part of the --max-memory budget the captured transactions may use,
+// one in transactionMemoryShare bytes; their decoded bodies and the schemas built from them take
+// up the rest
+const transactionMemoryShare = 4
+
+// parseByteSize parses a size such as 512MB or 2GB, in powers of 1024, or a plain number of
+// bytes. An empty size is 0, no limit. Negative and non-finite sizes are rejected.
+func parseByteSize(size string) (int64, error) {
+	original := size
+	size = strings.ToUpper(strings.TrimSpace(size))
+	if size == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"KB", 1 << 10},
+		{"MB", 1 << 20},
+		{"GB", 1 << 30},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(size, unit.suffix) {
+			size = strings.TrimSpace(strings.TrimSuffix(size, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	// NaN, Inf, and sizes beyond int64 parse as floats but are no sizes
+	n, err := strconv.ParseFloat(size, 64)
+	if err != nil || math.IsNaN(n) || n < 0 || n*float64(multiplier) >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q, expected e.g. 512MB or 2GB", original)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// specFromTransactions generates the OpenAPI document for transactions, captured in sessions
+// started at the given times, applying the generate command's options. The prefixes are stripped
+// from the paths in addition to --strip-prefix, the analysis cache, if not nil, is reused and
+// updated, and progress, if not nil, is called with the progress of the generation.
+func specFromTransactions(transactions []proxy.APITransaction, sessions []time.Time, title string, description string, version string, basePath string, prefixes []string, cache *openapi.AnalysisCache, progress func(phase string, done, total int)) (*openapi.OpenAPISpec, openapi.GenerationStats, error) {
+
+	// Create OpenAPI generator with configuration
+	config := openapi.OpenAPIConfig{
+		Title:              title,
+		Description:        description,
+		Version:            version,
+		UsePathGroups:      generateUsePathGroups,
+		TagMappings:        make(map[string]string),
+		TagDescriptions:    make(map[string]string),
+		VersionPrefixes:    make(map[string]bool),
+		PathTemplates:      make(map[string]string),
+		StripPrefixes:      append(append([]string(nil), generateStripPrefix...), prefixes...),
+		InferConstraints:   generateConstraints,
+		RequiredThreshold:  generateRequired,
+		SplitMediaTypes:    generateSplitMedia,
+		CodeSamples:        generateCodeSamples,
+		ResponseStrategy:   openapi.ResponseStrategy(generateStrategy),
+		ResponseTimes:      generateResponseTimes,
+		CallFrequency:      generateCallFrequency,
+		MinCalls:           generateMinCalls,
+		ErrorRates:         generateErrorRates,
+		ErrorRateThreshold: generateErrorThreshold,
+		SessionStarts:      sessions,
+		StaleSessions:      generateStaleAfter,
+		DeprecateStale:     generateDeprecateStale,
+		Contact:            generateContact,
+		License:            generateLicense,
+		TermsOfService:     generateTerms,
+		ExternalDocs:       generateExternalDocs,
+		Progress:           progress,
+		InferServers:       generateInferServers,
+		IncludePaths:       generateIncludePaths,
+		ExcludePaths:       generateExcludePaths,
+		IncludeMethods:     generateIncludeMethod,
+		ExcludeMethods:     generateExcludeMethod,
+		IncludeHeaders:     generateIncludeHeader,
+		ExcludeHeaders:     generateExcludeHeader,
+		RequiredHeaders:    generateRequireHeader,
+		Workers:            generateWorkers,
+		MaxFieldSamples:    generateFieldSamples,
+		MaxEndpointSamples: generateEndpointSamples,
+		Cache:              cache,
+	}
+
+	// Process servers from command line, after the base path
+	if basePath != "" {
+		config.Servers = append(config.Servers, openapi.OpenAPIServer{
+			URL:         basePath,
+			Description: "API Server",
+		})
+	}
+	variables := make(map[string]openapi.OpenAPIServerVariable)
+	for _, variable := range generateServerVars {
+		name, values, ok := strings.Cut(variable, "=")
+		if !ok || name == "" || values == "" {
+			logger.PrintError("Invalid server variable %q, expected 'name=default|other|...'", variable)
+			return nil, openapi.GenerationStats{}, fmt.Errorf("invalid server variable %q, expected 'name=default|other|...'", variable)
+		}
+		enum := strings.Split(values, "|")
+		serverVariable := openapi.OpenAPIServerVariable{Default: enum[0]}
+		if len(enum) > 1 {
+			serverVariable.Enum = enum
+		}
+		variables[name] = serverVariable
+	}
+	for _, server := range generateServers {
+		url, description, _ := strings.Cut(server, "=")
+		config.Servers = append(config.Servers, openapi.OpenAPIServer{
+			URL:         url,
+			Description: description,
+			Variables:   variables,
+		})
+	}
+
+	// Process tag mappings from command line
+	for _, mapping := range generateTagMapping {
+		parts := strings.SplitN(mapping, ":", 2)
+		if len(parts) == 2 {
+			config.TagMappings[parts[0]] = parts[1]
+		}
+	}
+
+	// Process tag descriptions and groups from command line
+	for _, desc := range generateTagDesc {
+		parts := strings.SplitN(desc, ":", 2)
+		if len(parts) != 2 {
+			logger.PrintError("Invalid tag description %q, expected 'tag:description'", desc)
+			return nil, openapi.GenerationStats{}, fmt.Errorf("invalid tag description %q, expected 'tag:description'", desc)
+		}
+		config.TagDescriptions[parts[0]] = strings.TrimSpace(parts[1])
+	}
+	for _, group := range generateTagGroup {
+		parts := strings.SplitN(group, ":", 2)
+		if len(parts) != 2 {
+			logger.PrintError("Invalid tag group %q, expected 'group:tag1,tag2'", group)
+			return nil, openapi.GenerationStats{}, fmt.Errorf("invalid tag group %q, expected 'group:tag1,tag2'", group)
+		}
+		tagGroup := openapi.OpenAPITagGroup{Name: parts[0]}
+		for _, tag := range strings.Split(parts[1], ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tagGroup.Tags = append(tagGroup.Tags, tag)
+			}
+		}
+		config.TagGroups = append(config.TagGroups, tagGroup)
+	}
+
+	// Process status code descriptions from command line
+	config.StatusDescriptions = make(map[string]string)
+	for _, desc := range generateStatusDesc {
+		code, text, ok := strings.Cut(desc, "=")
+		if _, err := strconv.Atoi(code); !ok || err != nil || len(code) != 3 {
+			logger.PrintError("Invalid status description %q, expected 'code=description'", desc)
+			return nil, openapi.GenerationStats{}, fmt.Errorf("invalid status description %q, expected 'code=description'", desc)
+		}
+		config.StatusDescriptions[code] = strings.TrimSpace(text)
+	}
+
+	// Process version prefixes from command line
+	for _, prefix := range generateVersionPrefix {
+		config.VersionPrefixes[prefix] = true
+	}
+
+	// Process path template overrides from command line
+	for _, override := range generatePathTemplate {
+		parts := strings.SplitN(override, "=", 2)
+		if len(parts) != 2 {
+			logger.PrintError("Invalid path template %q, expected 'pattern=template'", override)
+			return nil, openapi.GenerationStats{}, fmt.Errorf("invalid path template %q, expected 'pattern=template'", override)
+		}
+		config.PathTemplates[parts[0]] = parts[1]
+	}
+
+	// Load the owners and teams of path prefixes
+	if generateOwners != "" {
+		owners, err := openapi.LoadOwners(generateOwners)
+		if err != nil {
+			logger.PrintError("Failed to load owners: %v", err)
+			return nil, openapi.GenerationStats{}, fmt.Errorf("failed to load owners: %v", err)
+		}
+		config.Owners = owners
+	}
+
+	// Load the human-written prose of operations
+	if generateDescriptions != "" {
+		docs, err := openapi.LoadOperationDocs(generateDescriptions)
+		if err != nil {
+			logger.PrintError("Failed to load descriptions: %v", err)
+			return nil, openapi.GenerationStats{}, fmt.Errorf("failed to load descriptions: %v", err)
+		}
+		config.OperationDocs = docs
+	}
+
+	// Create generator
+	generator := openapi.NewOpenAPIGenerator(config)
+	if err := addSpecPlugins(generator); err != nil {
+		return nil, openapi.GenerationStats{}, err
+	}
+
+	// Add all transactions
+	for _, tx := range transactions {
+		generator.AddTransaction(tx)
+	}
+
+	// Generate specification
+	spec, err := generator.GenerateSpec()
+	if err != nil {
+		logger.PrintError("Failed to generate specification: %v", err)
+		return nil, openapi.GenerationStats{}, fmt.Errorf("failed to generate specification: %v", err)
+	}
+	for _, endpoint := range openapi.UnusedOperationDocs(spec, config.OperationDocs) {
+		logger.PrintWarning("Description of %s matches no documented endpoint", endpoint)
+	}
+	for _, repair := range generator.Stats().Repairs {
+		logger.PrintWarning("%s", repair)
+	}
+	for _, endpoint := range generator.Stats().RareEndpoints {
+		logger.PrintInfo("Left out %s", endpoint)
+	}
+	for _, endpoint := range generator.Stats().FlakyEndpoints {
+		logger.PrintWarning("%s during capture", endpoint)
+	}
+
+	// Merge into the existing hand-edited document
+	if generateMergeInto != "" {
+		existing, err := openapi.LoadSpec(generateMergeInto)
+		if err != nil {
+			logger.PrintError("Failed to load document to merge into: %v", err)
+			return nil, openapi.GenerationStats{}, fmt.Errorf("failed to load document to merge into: %v", err)
+		}
+		spec = openapi.MergeSpecs(existing, spec)
+		logger.PrintInfo("Merged generated specification into %s", generateMergeInto)
+	}
+
+	// Apply overlays last so that their corrections always win
+	for _, overlay := range generateOverlays {
+		spec, err = openapi.ApplyOverlay(spec, overlay)
+		if err != nil {
+			logger.PrintError("Failed to apply overlay: %v", err)
+			return nil, openapi.GenerationStats{}, fmt.Errorf("failed to apply overlay: %v", err)
+		}
+		logger.PrintInfo("Applied overlay %s", overlay)
+	}
+
+	return spec, generator.Stats(), nil
+}
+
+// cleanupDataDir deletes the session files of the data directory after generation if
+// requested and confirmed, and the directory itself once it is empty
+func cleanupDataDir(dataDir string, cleanup bool) error {
+	if !cleanup {
+		return nil
+	}
+
+	// Never delete a directory that swagdoc didn't fill, e.g. a mistyped --data-dir
+	if err := proxy.CheckDataDir(dataDir); err != nil {
+		logger.PrintError("Refusing to clean up %s, it doesn't look like a swagdoc data directory: %v", dataDir, err)
+		return fmt.Errorf("refusing to clean up %s, it doesn't look like a swagdoc data directory: %v", dataDir, err)
+	}
+	files, err := proxy.SessionFiles(dataDir)
+	if err != nil {
+		logger.PrintError("Failed to list session files: %v", err)
+		return fmt.Errorf("failed to list session files: %v", err)
+	}
+
+	if !generateYes {
+		if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+			logger.PrintWarning("Not cleaning up %s without confirmation, pass --yes to clean up when not on a terminal", dataDir)
+			return nil
+		}
+		fmt.Fprintf(logger.Output(), "Delete %d session files in %s? [y/N] ", len(files), dataDir)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			logger.PrintInfo("Kept the data directory %s", dataDir)
+			return nil
+		}
+	}
+
+	logger.PrintInfo("Cleaning up data directory: %s", dataDir)
+	removed, err := proxy.RemoveSessionFiles(dataDir)
+	if err != nil {
+		logger.PrintError("Failed to clean up data directory: %v", err)
+		return fmt.Errorf("failed to clean up data directory: %v", err)
+	}
+	logger.PrintSuccess("Deleted %d session files", len(removed))
+	if _, err := os.Stat(dataDir); err == nil {
+		logger.PrintInfo("Kept %s for the files swagdoc didn't create", dataDir)
 	}
 
 	return nil
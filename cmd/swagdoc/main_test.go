@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		size     string
+		expected int64
+	}{
+		{size: "", expected: 0},
+		{size: "0", expected: 0},
+		{size: "1024", expected: 1024},
+		{size: "100B", expected: 100},
+		{size: "512KB", expected: 512 << 10},
+		{size: "512MB", expected: 512 << 20},
+		{size: " 2gb ", expected: 2 << 30},
+		{size: "1.5GB", expected: 3 << 29},
+		{size: "8 GB", expected: 8 << 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			size, err := parseByteSize(tt.size)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, size)
+		})
+	}
+}
+
+func TestParseByteSizeInvalid(t *testing.T) {
+	for _, size := range []string{
+		"NaN",
+		"nanMB",
+		"Inf",
+		"+Inf",
+		"-Inf",
+		"InfinityGB",
+		"-1",
+		"-512MB",
+		"1e30GB",
+		"9223372036854775807",
+		"lots",
+		"MB",
+		"2TB",
+	} {
+		t.Run(size, func(t *testing.T) {
+			_, err := parseByteSize(size)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/parnexcodes/swag-doc/pkg/logger"
+	"github.com/parnexcodes/swag-doc/pkg/openapi"
+	"github.com/parnexcodes/swag-doc/pkg/plugin"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// pluginsFor returns the plugins given with --plugin that support a hook, in the order given
+func pluginsFor(hook string) ([]plugin.Plugin, error) {
+	var plugins []plugin.Plugin
+	for _, name := range generatePlugins {
+		p, info, err := describePlugin(name)
+		if err != nil {
+			return nil, err
+		}
+		if info.Supports(hook) {
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins, nil
+}
+
+// describePlugin finds a plugin on PATH and asks it which hooks it supports
+func describePlugin(name string) (plugin.Plugin, plugin.Info, error) {
+	p, err := plugin.Find(name)
+	if err != nil {
+		logger.PrintError("%v", err)
+		return plugin.Plugin{}, plugin.Info{}, err
+	}
+	info, err := p.Describe(context.Background())
+	if err != nil {
+		logger.PrintError("Failed to load plugin %s: %v", name, err)
+		return plugin.Plugin{}, plugin.Info{}, fmt.Errorf("failed to load plugin %s: %v", name, err)
+	}
+	return p, info, nil
+}
+
+// processTransactions passes the captured transactions through the transaction plugins, e.g.
+// custom sanitizers, in the order they were given
+func processTransactions(transactions []proxy.APITransaction) ([]proxy.APITransaction, error) {
+	plugins, err := pluginsFor(plugin.HookTransactions)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range plugins {
+		before := len(transactions)
+		if transactions, err = p.ProcessTransactions(context.Background(), transactions); err != nil {
+			logger.PrintError("%v", err)
+			return nil, err
+		}
+		logger.PrintInfo("Plugin %s processed %d API transactions, %d left", p.Name, before, len(transactions))
+	}
+	return transactions, nil
+}
+
+// addSpecPlugins lets the spec plugins modify every document the generator completes, before it
+// is merged, overlaid, and written
+func addSpecPlugins(generator *openapi.OpenAPIGenerator) error {
+	plugins, err := pluginsFor(plugin.HookSpec)
+	if err != nil {
+		return err
+	}
+	for _, p := range plugins {
+		p := p
+		generator.OnSpecComplete(func(doc *openapi.OpenAPISpec) error {
+			processed, err := p.ProcessSpec(context.Background(), doc)
+			if err != nil {
+				return err
+			}
+			*doc = *processed
+			return nil
+		})
+	}
+	return nil
+}
+
+// exportPlugin exports the document with the export hook of a plugin, to the plugin's default
+// output file unless --output is given
+func exportPlugin(name string) error {
+	p, info, err := describePlugin(name)
+	if err != nil {
+		return err
+	}
+	if !info.Supports(plugin.HookExport) {
+		logger.PrintError("Plugin %s doesn't export documents, it supports: %s", name, strings.Join(info.Hooks, ", "))
+		return fmt.Errorf("plugin %s doesn't export documents", name)
+	}
+
+	output := info.Output
+	if output == "" {
+		output = stdoutOutput
+	}
+	return exportCollection(name+" export", exportOutputOr(output), exportFile(func(spec *openapi.OpenAPISpec) ([]byte, error) {
+		return p.Export(context.Background(), spec)
+	}))
+}
+
+// listPlugins prints the plugins found on PATH and the hooks they support
+func listPlugins() error {
+	plugins := plugin.Discover()
+	if len(plugins) == 0 {
+		logger.PrintInfo("No plugins found, plugins are %s<name> executables on PATH", plugin.Prefix)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tHOOKS\tDESCRIPTION\tPATH")
+	for _, p := range plugins {
+		info, err := p.Describe(context.Background())
+		if err != nil {
+			fmt.Fprintf(w, "%s\t-\t%v\t%s\n", p.Name, err, p.Path)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Name, strings.Join(info.Hooks, ","), info.Description, p.Path)
+	}
+	return w.Flush()
+}
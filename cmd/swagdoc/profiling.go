@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	rpprof "runtime/pprof"
+
+	"github.com/parnexcodes/swag-doc/pkg/logger"
+)
+
+// startPprofServer serves the runtime profiles under /debug/pprof/ on their own address, apart
+// from the proxied traffic, until the process exits
+func startPprofServer(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.PrintError("Failed to start the pprof server: %v", err)
+		return fmt.Errorf("failed to start the pprof server: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	logger.PrintInfo("Serving pprof profiles on http://%s/debug/pprof/", listener.Addr())
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			logger.PrintWarning("pprof server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// startProfiling starts writing a CPU profile to cpuPath, if set, and returns a function that
+// stops it and writes a heap profile to memPath, if set
+func startProfiling(cpuPath string, memPath string) (func(), error) {
+	var cpuFile *os.File
+	if cpuPath != "" {
+		var err error
+		if cpuFile, err = os.Create(cpuPath); err != nil {
+			logger.PrintError("Failed to create CPU profile: %v", err)
+			return nil, fmt.Errorf("failed to create CPU profile: %v", err)
+		}
+		if err := rpprof.StartCPUProfile(cpuFile); err != nil {
+			cpuFile.Close()
+			logger.PrintError("Failed to start CPU profile: %v", err)
+			return nil, fmt.Errorf("failed to start CPU profile: %v", err)
+		}
+	}
+
+	return func() {
+		if cpuFile != nil {
+			rpprof.StopCPUProfile()
+			cpuFile.Close()
+			logger.PrintInfo("Wrote CPU profile to %s", cpuPath)
+		}
+		if memPath != "" {
+			if err := writeHeapProfile(memPath); err != nil {
+				logger.PrintWarning("Failed to write memory profile: %v", err)
+				return
+			}
+			logger.PrintInfo("Wrote memory profile to %s", memPath)
+		}
+	}, nil
+}
+
+// writeHeapProfile writes the allocations of the process so far to a file
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Collect garbage first, so that the profile shows the live heap up to date
+	runtime.GC()
+	return rpprof.WriteHeapProfile(f)
+}
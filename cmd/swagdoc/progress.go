@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/logger"
+
+	"github.com/mattn/go-isatty"
+)
+
+// Phases of generation timed besides the generator's own
+const (
+	phaseLoad  = "load"
+	phaseWrite = "write"
+)
+
+// progressBarWidth is the number of characters of the progress bar
+const progressBarWidth = 30
+
+// progressReporter shows the progress of the generation phases and times them. The progress bar
+// is only drawn on terminals with text logs, so that log files and pipelines don't fill up with it.
+type progressReporter struct {
+	out         io.Writer
+	interactive bool
+	phase       string
+	started     time.Time
+	phases      []string
+	durations   map[string]time.Duration
+}
+
+func newProgressReporter() *progressReporter {
+	out := logger.Output()
+	file, ok := out.(*os.File)
+	return &progressReporter{
+		out:         out,
+		interactive: ok && logger.Format() == logger.FormatText && (isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())),
+		durations:   make(map[string]time.Duration),
+	}
+}
+
+// startPhase ends the current phase and starts timing the next one
+func (p *progressReporter) startPhase(phase string) {
+	p.endPhase()
+	p.phase = phase
+	p.started = time.Now()
+	if _, ok := p.durations[phase]; !ok {
+		p.phases = append(p.phases, phase)
+		p.durations[phase] = 0
+	}
+}
+
+// endPhase adds the time since the current phase started to its duration
+func (p *progressReporter) endPhase() {
+	if p.phase == "" {
+		return
+	}
+	p.durations[p.phase] += time.Since(p.started)
+	p.phase = ""
+}
+
+// update is the progress callback of the generator
+func (p *progressReporter) update(phase string, done, total int) {
+	if phase != p.phase {
+		p.startPhase(phase)
+	}
+	if !p.interactive || total == 0 {
+		return
+	}
+
+	filled := progressBarWidth * done / total
+	fmt.Fprintf(p.out, "\r%-16s [%s%s] %3d%% (%d/%d)", phase,
+		strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled), 100*done/total, done, total)
+	if done == total {
+		fmt.Fprintln(p.out)
+	}
+}
+
+// printTimings ends the current phase and prints how long every phase took
+func (p *progressReporter) printTimings() {
+	p.endPhase()
+	parts := make([]string, len(p.phases))
+	for i, phase := range p.phases {
+		parts[i] = fmt.Sprintf("%s %s", phase, p.durations[phase].Round(time.Millisecond))
+	}
+	logger.PrintInfo("Timings: %s", strings.Join(parts, ", "))
+}
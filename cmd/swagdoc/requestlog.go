@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/logger"
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// requestLogger logs proxied transactions with their templated paths, and counts them for the
+// summary printed when the proxy stops
+type requestLogger struct {
+	mutex    sync.Mutex
+	detector *parser.PathPatternDetector
+	paths    map[string]bool // concrete paths added to the detector
+	requests map[string]bool // "METHOD path" of the captured transactions
+	started  time.Time
+	captured int
+	skipped  int
+}
+
+func newRequestLogger() *requestLogger {
+	return &requestLogger{
+		detector: parser.NewPathPatternDetector(),
+		paths:    make(map[string]bool),
+		requests: make(map[string]bool),
+		started:  time.Now(),
+	}
+}
+
+// log logs a proxied transaction, and whether it was stored
+func (l *requestLogger) log(tx proxy.APITransaction, captured bool) {
+	l.mutex.Lock()
+	if captured {
+		l.captured++
+		l.requests[tx.Request.Method+" "+tx.Request.Path] = true
+	} else {
+		l.skipped++
+	}
+	// Patterns are analyzed again whenever a new path shows up, so that ids are templated as
+	// soon as a pattern emerges
+	if !l.paths[tx.Request.Path] {
+		l.paths[tx.Request.Path] = true
+		l.detector.AddPath(tx.Request.Path)
+		l.detector.AnalyzePatterns()
+	}
+	path := l.templatize(tx.Request.Path)
+	l.mutex.Unlock()
+
+	contentType := "unknown"
+	if ct := tx.Response.Headers.Get("Content-Type"); ct != "" {
+		contentType = ct
+	}
+	logger.PrintRequestLog(logger.RequestLog{
+		Method:      tx.Request.Method,
+		Path:        path,
+		StatusCode:  tx.Response.StatusCode,
+		ContentType: contentType,
+		Latency:     tx.Response.Timestamp.Sub(tx.Request.Timestamp),
+		Captured:    captured,
+	})
+}
+
+// templatize returns the templated path of a concrete path
+func (l *requestLogger) templatize(path string) string {
+	if templated := l.detector.TemplatizePath(path); templated != "" {
+		return templated
+	}
+	return path
+}
+
+// printSummary prints how many transactions and endpoints were captured, even with --quiet
+func (l *requestLogger) printSummary() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	endpoints := make(map[string]bool)
+	for request := range l.requests {
+		method, path, _ := strings.Cut(request, " ")
+		endpoints[method+" "+l.templatize(path)] = true
+	}
+	logger.PrintSummary("Captured %d API transactions of %d endpoints in %s, skipped %d",
+		l.captured, len(endpoints), time.Since(l.started).Round(time.Second), l.skipped)
+}
+
+// captureInterceptor stores every transaction while capturing reports true, or always when it
+// is nil, and logs every transaction
+func captureInterceptor(storage proxy.Storage, requests *requestLogger, capturing func() bool) proxy.APIInterceptor {
+	return func(tx proxy.APITransaction) {
+		captured := capturing == nil || capturing()
+		if captured {
+			if err := storage.Store(tx); err != nil {
+				logger.PrintError("Error storing API transaction: %v", err)
+				captured = false
+			}
+		}
+		requests.log(tx, captured)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/logger"
+	"github.com/parnexcodes/swag-doc/pkg/openapi"
+)
+
+// SDK generators of export sdk
+const (
+	sdkGeneratorAuto             = "auto"
+	sdkGeneratorOpenAPIGenerator = "openapi-generator"
+	sdkGeneratorBuiltin          = "builtin"
+)
+
+// openAPIGeneratorCommands are the executables of OpenAPI Generator, as installed by npm and by
+// Homebrew, in order of preference
+var openAPIGeneratorCommands = []string{"openapi-generator-cli", "openapi-generator"}
+
+// exportSDK generates the document from the captured transactions and a client SDK from it, with
+// the generator of --generator
+func exportSDK() error {
+	generator := exportSDKGenerator
+	command := findOpenAPIGenerator()
+	switch generator {
+	case sdkGeneratorAuto:
+		generator = sdkGeneratorOpenAPIGenerator
+		if command == "" && exportSDKLang == "go" {
+			generator = sdkGeneratorBuiltin
+		}
+	case sdkGeneratorOpenAPIGenerator, sdkGeneratorBuiltin:
+	default:
+		logger.PrintError("Unknown SDK generator %q, expected auto, openapi-generator, or builtin", generator)
+		return fmt.Errorf("unknown SDK generator %q, expected auto, openapi-generator, or builtin", generator)
+	}
+
+	switch {
+	case generator == sdkGeneratorBuiltin && exportSDKLang != "go":
+		logger.PrintError("The built-in generator only writes Go clients, use --generator openapi-generator for %s", exportSDKLang)
+		return fmt.Errorf("the built-in generator only writes Go clients, not %s", exportSDKLang)
+	case generator == sdkGeneratorBuiltin:
+		return exportCollection("Go client", exportOutputOr("sdk"), writeGoClient)
+	case command == "":
+		logger.PrintError("OpenAPI Generator is not installed: npm install -g @openapitools/openapi-generator-cli, or brew install openapi-generator")
+		return fmt.Errorf("OpenAPI Generator (%s) is not installed", strings.Join(openAPIGeneratorCommands, " or "))
+	case exportOutput == stdoutOutput:
+		return fmt.Errorf("SDKs are directories and can't be written to stdout")
+	}
+	return exportCollection(exportSDKLang+" SDK", exportOutputOr("sdk"), func(spec *openapi.OpenAPISpec, output string) error {
+		return runOpenAPIGenerator(command, spec, output)
+	})
+}
+
+// findOpenAPIGenerator returns the OpenAPI Generator executable on PATH, or "" if it isn't
+// installed
+func findOpenAPIGenerator() string {
+	for _, command := range openAPIGeneratorCommands {
+		if _, err := exec.LookPath(command); err == nil {
+			return command
+		}
+	}
+	return ""
+}
+
+// runOpenAPIGenerator writes the document to a temporary file and runs OpenAPI Generator on it,
+// generating the SDK of --lang into the output directory
+func runOpenAPIGenerator(command string, spec *openapi.OpenAPISpec, output string) error {
+	dir, err := os.MkdirTemp("", "swagdoc-sdk-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+	input := filepath.Join(dir, "openapi.json")
+	if err := os.WriteFile(input, data, 0644); err != nil {
+		return err
+	}
+
+	args := []string{"generate", "-i", input, "-g", exportSDKLang, "-o", output}
+	if exportSDKPackage != "" {
+		args = append(args, "--package-name", exportSDKPackage)
+	}
+	args = append(args, exportSDKArgs...)
+	logger.PrintInfo("Running %s %s", command, strings.Join(args, " "))
+
+	var out bytes.Buffer
+	cmd := exec.Command(command, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %v: %s", command, err, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
+
+// writeGoClient writes the built-in Go client to client.go in the output directory, or to the
+// output itself when it is a .go file or stdout
+func writeGoClient(spec *openapi.OpenAPISpec, output string) error {
+	pkg := exportSDKPackage
+	if pkg == "" {
+		pkg = "client"
+	}
+	if output != stdoutOutput && filepath.Ext(output) != ".go" {
+		output = filepath.Join(output, "client.go")
+	}
+	return exportFile(func(spec *openapi.OpenAPISpec) ([]byte, error) {
+		return openapi.RenderGoClient(spec, pkg)
+	})(spec, output)
+}
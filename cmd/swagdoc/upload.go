@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/logger"
+	"github.com/parnexcodes/swag-doc/pkg/openapi"
+	"github.com/parnexcodes/swag-doc/pkg/publish"
+)
+
+// uploadTimeout bounds every upload of a generated document
+const uploadTimeout = time.Minute
+
+// uploadDocs uploads a generated document to an s3:// or gs:// output
+func uploadDocs(destination string, data []byte, format string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+	if err := publish.Upload(ctx, http.DefaultClient, destination, data, openapi.ContentType(format)); err != nil {
+		logger.PrintError("Failed to upload specification: %v", err)
+		return fmt.Errorf("failed to upload specification: %v", err)
+	}
+	logger.PrintSuccess("Swagger documentation uploaded successfully: %s", destination)
+	return nil
+}
+
+// putDocs uploads a generated document to --output-url, if given, with the --output-header
+// headers
+func putDocs(data []byte, format string) error {
+	if generateOutputURL == "" {
+		return nil
+	}
+	header, err := outputHeaders()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+	if err := publish.Put(ctx, http.DefaultClient, generateOutputURL, data, openapi.ContentType(format), header); err != nil {
+		logger.PrintError("Failed to upload specification: %v", err)
+		return fmt.Errorf("failed to upload specification: %v", err)
+	}
+	logger.PrintSuccess("Swagger documentation uploaded successfully: %s", generateOutputURL)
+	return nil
+}
+
+// outputHeaders parses the --output-header flags given in format 'Name: value'
+func outputHeaders() (http.Header, error) {
+	header := http.Header{}
+	for _, h := range generateOutputHeaders {
+		name, value, ok := strings.Cut(h, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			logger.PrintError("Invalid output header %q, expected 'Name: value'", h)
+			return nil, fmt.Errorf("invalid output header %q, expected 'Name: value'", h)
+		}
+		header.Add(name, strings.TrimSpace(value))
+	}
+	return header, nil
+}
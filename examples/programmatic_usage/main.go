@@ -53,7 +53,10 @@ func runProxy(storage proxy.Storage) {
 		log.Fatalf("Failed to create proxy server: %v", err)
 	}
 
-	if err := server.Start(); err != nil {
+	// Serve until Ctrl+C, letting the requests in flight finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := server.Start(ctx); err != nil {
 		log.Fatalf("Proxy server error: %v", err)
 	}
 }
@@ -100,6 +103,35 @@ func generateDocs(storage proxy.Storage, outputFile string) {
 }
 */
 
+/*
+Example code for documenting live traffic without storing it first:
+
+func documentLiveTraffic() {
+	// The generator accepts transactions from several goroutines, so its AddTransaction
+	// can be the interceptor of a proxy directly
+	generator := openapi.NewOpenAPIGenerator(openapi.OpenAPIConfig{Title: "Example API"})
+	server, err := proxy.NewProxyServer(8080, "http://localhost:3000", generator.AddTransaction)
+	if err != nil {
+		log.Fatalf("Failed to create proxy server: %v", err)
+	}
+	go server.Start(context.Background())
+
+	// Generate a spec from the traffic so far every minute, while capturing continues
+	for range time.Tick(time.Minute) {
+		spec, err := generator.GenerateSpec()
+		if err != nil {
+			log.Printf("Failed to generate documentation: %v", err)
+			continue
+		}
+		fmt.Printf("Documented %d paths\n", spec.Paths.Len())
+	}
+}
+
+Transactions stored before can be streamed into a generator as well:
+
+	generator.AddTransactionsFrom(storage)
+*/
+
 // Example usage:
 // To start the proxy:
 //   go run main.go proxy
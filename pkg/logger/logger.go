@@ -1,14 +1,78 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// Level is the severity of a message. Messages below the configured level are not printed.
+type Level int
+
+// Levels from the least to the most severe
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+// levelNames are the names of the levels, as used by ParseLevel and in JSON messages
+var levelNames = map[Level]string{
+	LevelDebug:   "debug",
+	LevelInfo:    "info",
+	LevelWarning: "warning",
+	LevelError:   "error",
+}
+
+// String returns the name of the level
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel parses a level name: debug, info, warning (or warn), or error
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(name)
+	if name == "warn" {
+		name = "warning"
+	}
+	for l, levelName := range levelNames {
+		if levelName == name {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected debug, info, warning, or error", name)
+}
+
+// Formats messages are printed in
+const (
+	// FormatText prints colored, human-readable lines, banners, and headers
+	FormatText = "text"
+	// FormatJSON prints a JSON object per line for centralized logging
+	FormatJSON = "json"
 )
 
 var (
+	// output receives every message, see SetOutput
+	output io.Writer = os.Stdout
+
+	// level is the least severe level printed, see SetLevel
+	level = LevelInfo
+
+	// format is the format messages are printed in, see SetFormat
+	format = FormatText
+
+	// colorDisabled turns colors off regardless of the output, see SetColor
+	colorDisabled bool
+
 	// Predefined colors for different log levels
+	debugColor    = color.New(color.FgWhite)
 	infoColor     = color.New(color.FgCyan)
 	successColor  = color.New(color.FgGreen)
 	warningColor  = color.New(color.FgYellow)
@@ -27,33 +91,139 @@ var (
 	status5xxColor = color.New(color.FgMagenta, color.Bold)
 )
 
+func init() {
+	updateColor()
+}
+
+// SetOutput sets the writer every message is printed to, e.g. os.Stderr when stdout carries
+// command output. Colors are only used when the writer is a terminal.
+func SetOutput(w io.Writer) {
+	output = w
+	updateColor()
+}
+
+// SetColor turns colors off, e.g. for --no-color, or back on where the output supports them
+func SetColor(enabled bool) {
+	colorDisabled = !enabled
+	updateColor()
+}
+
+// Interactive reports whether messages are printed in color for a person at a terminal, with
+// the banner and highlights, rather than as plain lines for CI logs and files
+func Interactive() bool {
+	return !color.NoColor
+}
+
+// updateColor enables ANSI colors only when the output is a terminal and neither NO_COLOR
+// (https://no-color.org) nor a dumb terminal asks for plain output
+func updateColor() {
+	color.NoColor = colorDisabled || !isTerminal(output) || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+}
+
+// isTerminal reports whether a writer is a terminal
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd()))
+}
+
+// Output returns the writer messages are printed to
+func Output() io.Writer {
+	return output
+}
+
+// SetLevel sets the least severe level of the messages printed
+func SetLevel(l Level) {
+	level = l
+}
+
+// SetFormat sets the format messages are printed in, FormatText or FormatJSON
+func SetFormat(f string) error {
+	if f != FormatText && f != FormatJSON {
+		return fmt.Errorf("unknown log format %q, expected %s or %s", f, FormatText, FormatJSON)
+	}
+	format = f
+	return nil
+}
+
+// Format returns the format messages are printed in
+func Format() string {
+	return format
+}
+
+// PrintDebug prints a debug message, only shown with the debug level
+func PrintDebug(format string, args ...interface{}) {
+	printWithPrefix(debugColor, "DEBUG", LevelDebug, format, args...)
+}
+
 // PrintInfo prints an info message
 func PrintInfo(format string, args ...interface{}) {
-	printWithPrefix(infoColor, "INFO", format, args...)
+	printWithPrefix(infoColor, "INFO", LevelInfo, format, args...)
 }
 
-// PrintSuccess prints a success message
+// PrintSuccess prints a success message, at the info level
 func PrintSuccess(format string, args ...interface{}) {
-	printWithPrefix(successColor, "SUCCESS", format, args...)
+	printWithPrefix(successColor, "SUCCESS", LevelInfo, format, args...)
 }
 
 // PrintWarning prints a warning message
 func PrintWarning(format string, args ...interface{}) {
-	printWithPrefix(warningColor, "WARNING", format, args...)
+	printWithPrefix(warningColor, "WARNING", LevelWarning, format, args...)
 }
 
 // PrintError prints an error message
 func PrintError(format string, args ...interface{}) {
-	printWithPrefix(errorColor, "ERROR", format, args...)
+	printWithPrefix(errorColor, "ERROR", LevelError, format, args...)
 }
 
-// PrintCritical prints a critical error message
+// PrintCritical prints a critical error message, at the error level
 func PrintCritical(format string, args ...interface{}) {
-	printWithPrefix(criticalColor, "CRITICAL", format, args...)
+	printWithPrefix(criticalColor, "CRITICAL", LevelError, format, args...)
 }
 
-// PrintRequestLog prints a formatted API request/response log
-func PrintRequestLog(method, path string, statusCode int, contentType string) {
+// PrintHeader prints a highlighted section title. Headers are decoration for people reading
+// the text format and are left out of JSON logs.
+func PrintHeader(text string) {
+	if format != FormatText || level > LevelInfo {
+		return
+	}
+	if !Interactive() {
+		fmt.Fprintln(output, strings.TrimSpace(text))
+		return
+	}
+	fmt.Fprintln(output, HighlightHeader(text))
+}
+
+// RequestLog is a proxied request as it is logged
+type RequestLog struct {
+	Method      string
+	Path        string // templated, e.g. /users/{userId}
+	StatusCode  int
+	ContentType string
+	Latency     time.Duration
+	Captured    bool // false when the transaction was not stored, e.g. while capture is paused
+}
+
+// PrintRequestLog prints a formatted API request/response log, at the info level
+func PrintRequestLog(entry RequestLog) {
+	if level > LevelInfo {
+		return
+	}
+	capture := "captured"
+	if !entry.Captured {
+		capture = "skipped"
+	}
+	if format == FormatJSON {
+		printJSON(LevelInfo, "request", map[string]interface{}{
+			"method":       entry.Method,
+			"path":         entry.Path,
+			"status":       entry.StatusCode,
+			"content_type": entry.ContentType,
+			"latency_ms":   float64(entry.Latency.Microseconds()) / 1000,
+			"captured":     entry.Captured,
+		})
+		return
+	}
+
 	timestamp := time.Now().Format("15:04:05")
 	timeColor := color.New(color.FgWhite)
 	methodColor := color.New(color.FgBlue, color.Bold)
@@ -61,26 +231,58 @@ func PrintRequestLog(method, path string, statusCode int, contentType string) {
 	// Choose appropriate color for status code
 	var statusColorFunc *color.Color
 	switch {
-	case statusCode >= 200 && statusCode < 300:
+	case entry.StatusCode >= 200 && entry.StatusCode < 300:
 		statusColorFunc = status2xxColor
-	case statusCode >= 300 && statusCode < 400:
+	case entry.StatusCode >= 300 && entry.StatusCode < 400:
 		statusColorFunc = status3xxColor
-	case statusCode >= 400 && statusCode < 500:
+	case entry.StatusCode >= 400 && entry.StatusCode < 500:
 		statusColorFunc = status4xxColor
 	default:
 		statusColorFunc = status5xxColor
 	}
+	captureColor := successColor
+	if !entry.Captured {
+		captureColor = warningColor
+	}
 
 	// Format the log
-	fmt.Printf("[%s] ", timeColor.Sprint(timestamp))
-	methodColor.Printf("%-6s", method)
-	fmt.Printf(" %-40s → ", path)
-	statusColorFunc.Printf("%d", statusCode)
-	fmt.Printf(" (%s)\n", contentType)
+	fmt.Fprintf(output, "[%s] ", timeColor.Sprint(timestamp))
+	methodColor.Fprintf(output, "%-6s", entry.Method)
+	fmt.Fprintf(output, " %-40s → ", entry.Path)
+	statusColorFunc.Fprintf(output, "%d", entry.StatusCode)
+	fmt.Fprintf(output, " %6s ", entry.Latency.Round(time.Millisecond))
+	captureColor.Fprint(output, capture)
+	fmt.Fprintf(output, " (%s)\n", entry.ContentType)
+}
+
+// PrintSummary prints a success message at every level, e.g. the summary of a run that is
+// shown even when only errors are printed otherwise
+func PrintSummary(format string, args ...interface{}) {
+	printMessage(successColor, "SUMMARY", LevelInfo, format, args...)
 }
 
 // PrintStartupBanner prints a startup banner for the application
 func PrintStartupBanner(port int, target, dataDir string) {
+	if level > LevelInfo {
+		return
+	}
+	if format == FormatJSON {
+		printJSON(LevelInfo, "proxy server ready", map[string]interface{}{
+			"port":     port,
+			"target":   target,
+			"data_dir": dataDir,
+		})
+		return
+	}
+
+	// Plain output gets the details without the artwork
+	if !Interactive() {
+		fmt.Fprintf(output, "Proxy Server: http://localhost:%d -> %s\n", port, target)
+		fmt.Fprintf(output, "Data Directory: %s\n", dataDir)
+		fmt.Fprintln(output, "Ready to capture API traffic")
+		return
+	}
+
 	banner := `
    _____                    _____            
   / ____|                  |  __ \           
@@ -91,28 +293,59 @@ func PrintStartupBanner(port int, target, dataDir string) {
                        __/ |                  
                       |___/                   
 `
-	fmt.Println(color.New(color.FgCyan).Sprint(banner))
+	fmt.Fprintln(output, color.New(color.FgCyan).Sprint(banner))
 
-	fmt.Printf("%s %s\n",
+	fmt.Fprintf(output, "%s %s\n",
 		color.New(color.FgGreen, color.Bold).Sprint("✓ Proxy Server:"),
 		fmt.Sprintf("http://localhost:%d → %s", port, target))
 
-	fmt.Printf("%s %s\n",
+	fmt.Fprintf(output, "%s %s\n",
 		color.New(color.FgGreen, color.Bold).Sprint("✓ Data Directory:"),
 		dataDir)
 
-	highlightSuccess.Println(" READY TO CAPTURE API TRAFFIC ")
-	fmt.Println(color.New(color.FgWhite).Sprint("------------------------------------------"))
+	highlightSuccess.Fprintln(output, " READY TO CAPTURE API TRAFFIC ")
+	fmt.Fprintln(output, color.New(color.FgWhite).Sprint("------------------------------------------"))
 }
 
 // Helper function to print with a prefix
-func printWithPrefix(colorFunc *color.Color, prefix, format string, args ...interface{}) {
+func printWithPrefix(colorFunc *color.Color, prefix string, l Level, msgFormat string, args ...interface{}) {
+	if l < level {
+		return
+	}
+	printMessage(colorFunc, prefix, l, msgFormat, args...)
+}
+
+// printMessage prints a message regardless of the configured level
+func printMessage(colorFunc *color.Color, prefix string, l Level, msgFormat string, args ...interface{}) {
+	if format == FormatJSON {
+		printJSON(l, fmt.Sprintf(msgFormat, args...), nil)
+		return
+	}
+
 	timestamp := time.Now().Format("15:04:05")
 	timeColor := color.New(color.FgWhite)
 
-	fmt.Printf("[%s] ", timeColor.Sprint(timestamp))
-	colorFunc.Printf("[%s] ", prefix)
-	fmt.Printf(format+"\n", args...)
+	fmt.Fprintf(output, "[%s] ", timeColor.Sprint(timestamp))
+	colorFunc.Fprintf(output, "[%s] ", prefix)
+	fmt.Fprintf(output, msgFormat+"\n", args...)
+}
+
+// printJSON prints a message as a JSON object on one line, with the fields next to the time,
+// level, and message
+func printJSON(l Level, message string, fields map[string]interface{}) {
+	record := make(map[string]interface{}, len(fields)+3)
+	for key, value := range fields {
+		record[key] = value
+	}
+	record["time"] = time.Now().Format(time.RFC3339Nano)
+	record["level"] = l.String()
+	record["message"] = message
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	output.Write(append(data, '\n'))
 }
 
 // HighlightHeader returns a highlighted header string suitable for section titles
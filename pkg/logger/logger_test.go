@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONFormatAndLevel(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	if err := SetFormat(FormatJSON); err != nil {
+		t.Fatalf("Failed to set format: %v", err)
+	}
+	SetLevel(LevelWarning)
+	defer func() {
+		SetOutput(os.Stdout)
+		SetFormat(FormatText)
+		SetLevel(LevelInfo)
+	}()
+
+	PrintHeader(" Header ")
+	PrintInfo("skipped")
+	PrintWarning("disk %d%% full", 90)
+	PrintCritical("stopped")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d: %q", len(lines), buf.String())
+	}
+
+	expected := []struct{ level, message string }{
+		{"warning", "disk 90% full"},
+		{"error", "stopped"},
+	}
+	for i, line := range lines {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected a JSON object, got %q: %v", line, err)
+		}
+		if record["level"] != expected[i].level || record["message"] != expected[i].message {
+			t.Errorf("Expected %s message %q, got %v", expected[i].level, expected[i].message, record)
+		}
+		if _, ok := record["time"]; !ok {
+			t.Errorf("Expected a time in %v", record)
+		}
+	}
+}
+
+func TestPrintRequestLogJSON(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetFormat(FormatJSON)
+	defer func() {
+		SetOutput(os.Stdout)
+		SetFormat(FormatText)
+	}()
+
+	PrintRequestLog(RequestLog{
+		Method:      "GET",
+		Path:        "/users/{userId}",
+		StatusCode:  200,
+		ContentType: "application/json",
+		Latency:     1500 * time.Microsecond,
+		Captured:    true,
+	})
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a JSON object, got %q: %v", buf.String(), err)
+	}
+	if record["method"] != "GET" || record["path"] != "/users/{userId}" || record["status"] != float64(200) ||
+		record["content_type"] != "application/json" || record["latency_ms"] != 1.5 || record["captured"] != true {
+		t.Errorf("Unexpected request log %v", record)
+	}
+}
+
+func TestPrintSummaryAtEveryLevel(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetLevel(LevelError)
+	defer func() {
+		SetOutput(os.Stdout)
+		SetLevel(LevelInfo)
+	}()
+
+	PrintRequestLog(RequestLog{Method: "GET", Path: "/users", StatusCode: 200})
+	PrintSuccess("hidden")
+	PrintSummary("Captured %d transactions", 3)
+
+	if out := buf.String(); !strings.HasSuffix(out, "[SUMMARY] Captured 3 transactions\n") || strings.Contains(out, "hidden") || strings.Contains(out, "/users") {
+		t.Errorf("Expected only the summary, got %q", out)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]Level{"debug": LevelDebug, "INFO": LevelInfo, "warn": LevelWarning, "warning": LevelWarning, "error": LevelError}
+	for name, expected := range tests {
+		level, err := ParseLevel(name)
+		if err != nil || level != expected {
+			t.Errorf("ParseLevel(%q) = %v, %v; expected %v", name, level, err, expected)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+	if err := SetFormat("xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestPlainOutput(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stdout)
+
+	// Writers other than terminals get plain output
+	if Interactive() {
+		t.Fatal("Expected plain output for a buffer")
+	}
+
+	PrintHeader(" SwagDoc Documentation Generator ")
+	PrintWarning("careful")
+	PrintStartupBanner(8080, "http://api.example.com", "./data")
+
+	out := buf.String()
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("Expected no ANSI sequences, got %q", out)
+	}
+	for _, expected := range []string{
+		"SwagDoc Documentation Generator\n",
+		"[WARNING] careful\n",
+		"Proxy Server: http://localhost:8080 -> http://api.example.com\n",
+		"Data Directory: ./data\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected %q in %q", expected, out)
+		}
+	}
+}
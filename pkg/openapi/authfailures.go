@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// authFailureResponses maps the auth failure status codes to their shared response components
+var authFailureResponses = []struct {
+	statusCode int
+	name       string
+}{
+	{statusCode: http.StatusUnauthorized, name: "Unauthorized"},
+	{statusCode: http.StatusForbidden, name: "Forbidden"},
+}
+
+// authRequest builds the http.Request the auth detector analyzes from a captured request
+func authRequest(tx proxy.APITransaction) *http.Request {
+	header := make(http.Header)
+	for k, v := range tx.Request.Headers {
+		if len(v) > 0 {
+			header.Set(k, v[0])
+		}
+	}
+
+	reqURL, _ := url.Parse(tx.Request.Path)
+	if reqURL == nil {
+		reqURL = &url.URL{}
+	}
+	if tx.Request.QueryParams != nil {
+		reqURL.RawQuery = tx.Request.QueryParams.Encode()
+	}
+
+	return &http.Request{
+		Header: header,
+		URL:    reqURL,
+	}
+}
+
+// hasCredentials checks whether a captured request carried credentials of any auth scheme
+func hasCredentials(tx proxy.APITransaction) bool {
+	detector := parser.NewAuthDetector()
+	detector.AnalyzeTransaction(authRequest(tx), nil)
+	return len(detector.GetAuthSchemes()) > 0
+}
+
+// addAuthFailureResponses turns the 401/403 responses observed for secured endpoints into shared
+// Unauthorized/Forbidden response components referencing the Error schema, and adds them to every
+// secured operation so that all of them document how they fail without valid credentials
+func (g *OpenAPIGenerator) addAuthFailureResponses(doc *OpenAPISpec, secured map[string]bool, errorSchema string) {
+	endpointKeys := make([]string, 0, len(secured))
+	for endpointKey := range secured {
+		endpointKeys = append(endpointKeys, endpointKey)
+	}
+	sort.Strings(endpointKeys)
+
+	var operations []*openapi3.Operation
+	for _, endpointKey := range endpointKeys {
+		method, path, _ := strings.Cut(endpointKey, " ")
+		if pathItem := doc.Paths.Find(path); pathItem != nil {
+			if op := operationFor(pathItem, method); op != nil && op.Responses != nil {
+				operations = append(operations, op)
+			}
+		}
+	}
+
+	for _, failure := range authFailureResponses {
+		statusCode := strconv.Itoa(failure.statusCode)
+
+		// Only document failures that were actually observed
+		var observed *openapi3.Response
+		for _, op := range operations {
+			if response := op.Responses.Value(statusCode); response != nil && response.Value != nil {
+				observed = response.Value
+				break
+			}
+		}
+		if observed == nil {
+			continue
+		}
+
+		description := g.statusDescription(failure.statusCode)
+		response := &openapi3.Response{Description: &description, Headers: observed.Headers}
+		if errorSchema != "" {
+			response.Content = openapi3.Content{}
+			for mediaType := range observed.Content {
+				response.Content[mediaType] = errorMediaType(doc, errorSchema)
+			}
+			if len(response.Content) == 0 {
+				response.Content["application/json"] = errorMediaType(doc, errorSchema)
+			}
+		} else {
+			response.Content = observed.Content
+		}
+
+		if doc.Components.Responses == nil {
+			doc.Components.Responses = openapi3.ResponseBodies{}
+		}
+		doc.Components.Responses[failure.name] = &openapi3.ResponseRef{Value: response}
+
+		for _, op := range operations {
+			op.Responses.Set(statusCode, &openapi3.ResponseRef{
+				Ref:   "#/components/responses/" + failure.name,
+				Value: response,
+			})
+		}
+	}
+}
+
+// errorMediaType returns a media type whose schema references the shared Error schema
+func errorMediaType(doc *OpenAPISpec, errorSchema string) *openapi3.MediaType {
+	return &openapi3.MediaType{
+		Schema: &openapi3.SchemaRef{
+			Ref:   "#/components/schemas/" + errorSchema,
+			Value: doc.Components.Schemas[errorSchema].Value,
+		},
+	}
+}
@@ -0,0 +1,115 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecAuthFailureResponses(t *testing.T) {
+	authorized := func(tx proxy.APITransaction) proxy.APITransaction {
+		tx.Request.Headers.Set("Authorization", "Bearer __redacted__")
+		return tx
+	}
+
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(authorized(createTestTransaction("GET", "/users", nil, []byte(`[{"id":"__integer__"}]`), 200)))
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, []byte(`{"error":"missing token","code":"__string__"}`), 401))
+	generator.AddTransaction(authorized(createTestTransaction("DELETE", "/orders", nil, []byte(`{"error":"not allowed","code":"__string__"}`), 403)))
+	generator.AddTransaction(createTestTransaction("GET", "/health", nil, []byte(`{"status":"__string__"}`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+	require.NoError(t, ValidateSpec(spec))
+
+	require.Contains(t, spec.Components.Responses, "Unauthorized")
+	require.Contains(t, spec.Components.Responses, "Forbidden")
+	unauthorized := spec.Components.Responses["Unauthorized"].Value
+	assert.Equal(t, "Unauthorized", *unauthorized.Description)
+	assert.Equal(t, "#/components/schemas/Error", unauthorized.Content["application/json"].Schema.Ref)
+
+	// Every secured operation documents both failures
+	users := spec.Paths.Find("/users").Get
+	assert.Equal(t, "#/components/responses/Unauthorized", users.Responses.Value("401").Ref)
+	assert.Equal(t, "#/components/responses/Forbidden", users.Responses.Value("403").Ref)
+
+	orders := spec.Paths.Find("/orders").Delete
+	assert.Equal(t, "#/components/responses/Unauthorized", orders.Responses.Value("401").Ref)
+	assert.Equal(t, "#/components/responses/Forbidden", orders.Responses.Value("403").Ref)
+
+	// Endpoints called without credentials are left alone
+	health := spec.Paths.Find("/health").Get
+	assert.Nil(t, health.Responses.Value("401"))
+	assert.Nil(t, health.Responses.Value("403"))
+}
+
+func TestGenerateSpecAuthFailureResponsesFromCaptures(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"missing token","code":"unauthorized"}`)
+			return
+		}
+		fmt.Fprint(w, `[{"id":1}]`)
+	})
+	request := func(authorization string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, "/users", nil)
+		require.NoError(t, err)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return req
+	}
+
+	transactions := captureTestTransactions(t, handler, request("Bearer secret"), request(""))
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "Bearer __redacted__", transactions[0].Request.Headers.Get("Authorization"))
+
+	spec, _ := generateTestSpec(t, OpenAPIConfig{}, transactions...)
+	require.NoError(t, ValidateSpec(spec))
+	require.Contains(t, spec.Components.SecuritySchemes, "bearer")
+	require.Contains(t, spec.Components.Responses, "Unauthorized")
+	assert.Equal(t, "#/components/responses/Unauthorized", spec.Paths.Find("/users").Get.Responses.Value("401").Ref)
+}
+
+func TestGenerateSpecAuthFailureResponsesNotObserved(t *testing.T) {
+	tx := createTestTransaction("GET", "/users", nil, []byte(`[{"id":"__integer__"}]`), 200)
+	tx.Request.Headers.Set("X-API-Key", "__token__")
+
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(tx)
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	assert.Empty(t, spec.Components.Responses)
+	assert.Nil(t, spec.Paths.Find("/users").Get.Responses.Value("401"))
+}
+
+func TestHasCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		expected bool
+	}{
+		{name: "bearer token", header: "Authorization", value: "Bearer __redacted__", expected: true},
+		{name: "basic credentials", header: "Authorization", value: "Basic __redacted__", expected: true},
+		{name: "api key", header: "X-API-Key", value: "__token__", expected: true},
+		{name: "no credentials", header: "Accept", value: "application/json", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := createTestTransaction("GET", "/users", nil, nil, 200)
+			tx.Request.Headers.Set(tt.header, tt.value)
+			assert.Equal(t, tt.expected, hasCredentials(tx))
+		})
+	}
+}
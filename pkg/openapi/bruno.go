@@ -0,0 +1,152 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// brunoFileNamePattern matches the characters that cannot appear in Bruno file and folder names
+var brunoFileNamePattern = regexp.MustCompile(`[/\\:*?"<>|]+`)
+
+// brunoCollection is the bruno.json file that marks a directory as a Bruno collection
+type brunoCollection struct {
+	Version string   `json:"version"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Ignore  []string `json:"ignore"`
+}
+
+// RenderBruno converts a document to the files of a Bruno collection: bruno.json, a Default
+// environment holding the first server as baseUrl, and a folder per tag with a .bru file per
+// request. It returns the file contents by slash-separated path.
+func RenderBruno(spec *OpenAPISpec) (map[string][]byte, error) {
+	collection := brunoCollection{
+		Version: "1",
+		Name:    "API Documentation",
+		Type:    "collection",
+		Ignore:  []string{"node_modules", ".git"},
+	}
+	if spec.Info != nil && spec.Info.Title != "" {
+		collection.Name = spec.Info.Title
+	}
+	manifest, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{
+		"bruno.json":               manifest,
+		"environments/Default.bru": []byte(fmt.Sprintf("vars {\n  baseUrl: %s\n}\n", serverBaseURL(spec))),
+	}
+
+	tags := operationsByTag(spec)
+	for _, tag := range sortedTags(tags) {
+		folder := brunoFileName(tag)
+		used := make(map[string]bool)
+		for i, operation := range tags[tag] {
+			example := newExportRequest(spec, operation)
+
+			// Requests with the same summary need distinct files
+			name := brunoFileName(example.name)
+			for n := 2; used[name]; n++ {
+				name = fmt.Sprintf("%s (%d)", brunoFileName(example.name), n)
+			}
+			used[name] = true
+
+			files[path.Join(folder, name+".bru")] = []byte(brunoRequest(example, i+1))
+		}
+	}
+	return files, nil
+}
+
+// WriteBruno writes the files of a Bruno collection into a directory
+func WriteBruno(spec *OpenAPISpec, dir string) error {
+	files, err := RenderBruno(spec)
+	if err != nil {
+		return err
+	}
+	return writeFiles(dir, files)
+}
+
+// brunoRequest renders an example request as a .bru file
+func brunoRequest(example exportRequest, seq int) string {
+	var file strings.Builder
+
+	fmt.Fprintf(&file, "meta {\n  name: %s\n  type: http\n  seq: %d\n}\n\n", example.name, seq)
+
+	bodyMode := "none"
+	if example.body != "" {
+		bodyMode = "json"
+	}
+	url := "{{baseUrl}}" + example.templatedPath(func(name string) string { return ":" + name })
+	var enabled []string
+	for _, param := range example.query {
+		if param.required {
+			enabled = append(enabled, param.name+"="+param.value)
+		}
+	}
+	if len(enabled) > 0 {
+		url += "?" + strings.Join(enabled, "&")
+	}
+	fmt.Fprintf(&file, "%s {\n  url: %s\n  body: %s\n  auth: none\n}\n", strings.ToLower(example.method), url, bodyMode)
+
+	if len(example.query) > 0 {
+		var lines []string
+		for _, param := range example.query {
+			// Optional parameters are listed but disabled
+			prefix := ""
+			if !param.required {
+				prefix = "~"
+			}
+			lines = append(lines, prefix+param.name+": "+param.value)
+		}
+		file.WriteString(brunoBlock("params:query", strings.Join(lines, "\n")))
+	}
+
+	if len(example.pathParams) > 0 {
+		var lines []string
+		for _, param := range example.pathParams {
+			lines = append(lines, param.name+": "+param.value)
+		}
+		file.WriteString(brunoBlock("params:path", strings.Join(lines, "\n")))
+	}
+
+	if len(example.headers) > 0 {
+		var lines []string
+		for _, header := range example.headers {
+			lines = append(lines, header[0]+": "+credentialPlaceholder(header[1], postmanTemplate))
+		}
+		file.WriteString(brunoBlock("headers", strings.Join(lines, "\n")))
+	}
+
+	if example.body != "" {
+		file.WriteString(brunoBlock("body:json", example.body))
+	}
+	if example.description != "" {
+		file.WriteString(brunoBlock("docs", example.description))
+	}
+	return file.String()
+}
+
+// brunoBlock renders a named block of a .bru file with its content indented
+func brunoBlock(name, content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return fmt.Sprintf("\n%s {\n%s\n}\n", name, strings.Join(lines, "\n"))
+}
+
+// brunoFileName makes a request or tag name safe to use as a file or folder name
+func brunoFileName(name string) string {
+	name = strings.TrimSpace(brunoFileNamePattern.ReplaceAllString(name, "-"))
+	if name == "" || name == "." || name == ".." {
+		return "request"
+	}
+	return name
+}
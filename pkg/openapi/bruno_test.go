@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBruno(t *testing.T) {
+	spec, _ := generateTestSpec(t, shopTestConfig(), exportTestTransactions()...)
+	files, err := RenderBruno(spec)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"version":"1","name":"Shop API","type":"collection","ignore":["node_modules",".git"]}`, string(files["bruno.json"]))
+	assert.Equal(t, "vars {\n  baseUrl: https://api.example.com\n}\n", string(files["environments/Default.bru"]))
+
+	var users []string
+	for name := range files {
+		if filepath.Dir(name) == "Users" {
+			users = append(users, name)
+		}
+	}
+	require.Len(t, users, 2)
+
+	var get string
+	for _, name := range users {
+		if content := string(files[name]); strings.Contains(content, "\nget {\n") {
+			get = content
+		}
+	}
+	require.NotEmpty(t, get)
+	assert.Contains(t, get, "  url: {{baseUrl}}/users/:userId\n")
+	assert.Contains(t, get, "params:path {\n  userId: ")
+
+	var list string
+	for name, content := range files {
+		if filepath.Dir(name) == "Orders" {
+			list = string(content)
+		}
+	}
+	assert.Contains(t, list, "params:query {\n  ~page: ")
+}
+
+func TestWriteBruno(t *testing.T) {
+	spec, _ := generateTestSpec(t, shopTestConfig(), exportTestTransactions()...)
+	dir := t.TempDir()
+	require.NoError(t, WriteBruno(spec, dir))
+
+	for _, name := range []string{"bruno.json", "environments/Default.bru"} {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		assert.NoError(t, err, name)
+	}
+}
+
+func TestBrunoFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "Get user by userId", expected: "Get user by userId"},
+		{name: "GET /users/{userId}", expected: "GET -users-{userId}"},
+		{name: "  ", expected: "request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, brunoFileName(tt.name))
+		})
+	}
+}
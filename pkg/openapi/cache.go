@@ -0,0 +1,179 @@
+package openapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// analysisCacheVersion is bumped whenever the cached analysis changes, so that caches written by
+// other versions of swagdoc are discarded rather than misread
+const analysisCacheVersion = 1
+
+// AnalysisCache keeps the path patterns and merged schemas of a generation, so that the next
+// generation from the same data only re-analyzes what changed: the path patterns are reused
+// while the captured request paths stay the same, and the merged schemas of every endpoint while
+// no transactions are added to it
+type AnalysisCache struct {
+	Version   int                       `json:"version"`
+	PathsHash string                    `json:"pathsHash"` // fingerprint of the request paths and path templates
+	Paths     map[string]string         `json:"paths"`     // request path -> templated path
+	Endpoints map[string]CachedEndpoint `json:"endpoints"` // "METHOD /templated/path" -> merged schemas
+}
+
+// CachedEndpoint holds the merged schemas of an endpoint before required properties, constraints,
+// and timestamps are inferred from the samples, and the fingerprint of the transactions they were
+// merged from
+type CachedEndpoint struct {
+	Hash     string        `json:"hash"`
+	Request  parser.Schema `json:"request"`
+	Response parser.Schema `json:"response"`
+	Errors   parser.Schema `json:"errors"`
+}
+
+// NewAnalysisCache creates an empty analysis cache
+func NewAnalysisCache() *AnalysisCache {
+	return &AnalysisCache{
+		Version:   analysisCacheVersion,
+		Paths:     make(map[string]string),
+		Endpoints: make(map[string]CachedEndpoint),
+	}
+}
+
+// LoadAnalysisCache reads an analysis cache file. A missing file, or one written by another
+// version of swagdoc, yields an empty cache.
+func LoadAnalysisCache(path string) (*AnalysisCache, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewAnalysisCache(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cache AnalysisCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("invalid analysis cache %s: %v", path, err)
+	}
+	if cache.Version != analysisCacheVersion {
+		return NewAnalysisCache(), nil
+	}
+	if cache.Paths == nil {
+		cache.Paths = make(map[string]string)
+	}
+	if cache.Endpoints == nil {
+		cache.Endpoints = make(map[string]CachedEndpoint)
+	}
+	return &cache, nil
+}
+
+// Save writes the analysis cache to a file, creating its directory if needed
+func (c *AnalysisCache) Save(path string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// cachedTemplates returns the templated paths of the cache when they were detected from the same
+// request paths and path templates, or nil
+func (c *AnalysisCache) cachedTemplates(pathsHash string) map[string]string {
+	if c == nil || c.PathsHash != pathsHash {
+		return nil
+	}
+	return c.Paths
+}
+
+// reusableEndpoints returns the cached endpoints whose transactions are unchanged
+func (c *AnalysisCache) reusableEndpoints(hashes map[string]string) map[string]CachedEndpoint {
+	reusable := make(map[string]CachedEndpoint)
+	if c == nil {
+		return reusable
+	}
+	for key, hash := range hashes {
+		if cached, ok := c.Endpoints[key]; ok && cached.Hash == hash {
+			reusable[key] = cached
+		}
+	}
+	return reusable
+}
+
+// pathsHash fingerprints the request paths of the transactions in order, together with the
+// configured path templates, which are all that path pattern detection depends on
+func (g *OpenAPIGenerator) pathsHash(transactions []proxy.APITransaction) string {
+	hash := sha256.New()
+	for _, pattern := range sortedTemplatePatterns(g.config.PathTemplates) {
+		fmt.Fprintf(hash, "%s=%s\n", pattern, g.config.PathTemplates[pattern])
+	}
+	hash.Write([]byte{0})
+	for _, tx := range transactions {
+		fmt.Fprintf(hash, "%s\n", tx.Request.Path)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// samplingSettings describes the settings the merged schemas depend on besides the transactions
+func (g *OpenAPIGenerator) samplingSettings() string {
+	return fmt.Sprintf("fields=%d endpoints=%d", g.fieldSamples(), g.endpointSamples())
+}
+
+// endpointHashes fingerprints the transactions of every endpoint, keyed by "METHOD
+// /templated/path", from everything the merged schemas of the endpoint depend on: the
+// transactions and the sampling settings
+func endpointHashes(transactions []proxy.APITransaction, templatedPaths []string, settings string) map[string]string {
+	hashes := make(map[string]string)
+	sums := make(map[string][]byte)
+	for i, tx := range transactions {
+		key := tx.Request.Method + " " + templatedPaths[i]
+		hash := sha256.New()
+		if sum, ok := sums[key]; ok {
+			hash.Write(sum)
+		} else {
+			hash.Write([]byte(settings))
+		}
+		for _, field := range []string{
+			tx.Request.Path,
+			strconv.Itoa(tx.Response.StatusCode),
+			getContentType(tx.Request.Headers),
+			getContentType(tx.Response.Headers),
+			string(tx.Request.Body),
+			string(tx.Response.Body),
+		} {
+			fmt.Fprintf(hash, "%d:%s", len(field), field)
+		}
+		sums[key] = hash.Sum(nil)
+	}
+	for key, sum := range sums {
+		hashes[key] = hex.EncodeToString(sum)
+	}
+	return hashes
+}
+
+// updateCache replaces the contents of the analysis cache with the analysis of this generation
+func (g *OpenAPIGenerator) updateCache(pathsHash string, templates map[string]string, hashes map[string]string, merged []endpointSchemas) {
+	cache := g.config.Cache
+	if cache == nil {
+		return
+	}
+	cache.Version = analysisCacheVersion
+	cache.PathsHash = pathsHash
+	cache.Paths = templates
+	cache.Endpoints = make(map[string]CachedEndpoint, len(merged))
+	for _, endpoint := range merged {
+		key := endpoint.method + " " + endpoint.path
+		cached := endpoint.unrefined
+		cached.Hash = hashes[key]
+		cache.Endpoints[key] = cached
+	}
+}
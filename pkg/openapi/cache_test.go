@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecAnalysisCache(t *testing.T) {
+	transactions := []proxy.APITransaction{
+		createTestTransaction("GET", "/users/1", nil, []byte(`{"id":"__integer__","name":"__string__","createdAt":"2024-03-05T14:30:15Z"}`), 200),
+		createTestTransaction("GET", "/users/2", nil, []byte(`{"id":"__integer__","name":"__string__","email":"__string__"}`), 200),
+		createTestTransaction("GET", "/users/3", nil, []byte(`{"error":"__string__"}`), 404),
+		createTestTransaction("POST", "/users", []byte(`{"name":"__string__"}`), []byte(`{"id":"__integer__"}`), 201),
+	}
+
+	generate := func(transactions []proxy.APITransaction, cache *AnalysisCache) (string, GenerationStats) {
+		generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Users API", Version: "1.0.0", Cache: cache})
+		for _, tx := range transactions {
+			generator.AddTransaction(tx)
+		}
+		spec, err := generator.GenerateSpec()
+		require.NoError(t, err)
+		data, err := MarshalSpecFormat(spec, "json")
+		require.NoError(t, err)
+		return string(data), generator.Stats()
+	}
+
+	uncached, _ := generate(transactions, nil)
+
+	// The first run fills the cache
+	cache := NewAnalysisCache()
+	spec, stats := generate(transactions, cache)
+	assert.Equal(t, uncached, spec)
+	assert.Zero(t, stats.CachedEndpoints)
+	assert.Len(t, cache.Endpoints, 2)
+	assert.Equal(t, "/users/{userId}", cache.Paths["/users/1"])
+
+	path := filepath.Join(t.TempDir(), "cache", "analysis.json")
+	require.NoError(t, cache.Save(path))
+	cache, err := LoadAnalysisCache(path)
+	require.NoError(t, err)
+
+	// Nothing changed, so every endpoint is reused and the document stays the same
+	spec, stats = generate(transactions, cache)
+	assert.Equal(t, uncached, spec)
+	assert.Equal(t, 2, stats.CachedEndpoints)
+
+	// Only the endpoint with a new transaction is analyzed again
+	transactions = append(transactions, createTestTransaction("POST", "/users", []byte(`{"name":"__string__","admin":"__boolean__"}`), []byte(`{"id":"__integer__"}`), 201))
+	uncached, _ = generate(transactions, nil)
+	spec, stats = generate(transactions, cache)
+	assert.Equal(t, uncached, spec)
+	assert.Equal(t, 1, stats.CachedEndpoints)
+	assert.Contains(t, spec, "admin")
+}
+
+func TestEndpointHashesSamplingSettings(t *testing.T) {
+	transactions := []proxy.APITransaction{createTestTransaction("GET", "/users", nil, []byte(`[]`), 200)}
+	paths := []string{"/users"}
+
+	// Schemas merged with other sample caps are not reused
+	defaults := NewOpenAPIGenerator(OpenAPIConfig{})
+	capped := NewOpenAPIGenerator(OpenAPIConfig{MaxEndpointSamples: 10})
+	assert.Equal(t, endpointHashes(transactions, paths, defaults.samplingSettings()),
+		endpointHashes(transactions, paths, NewOpenAPIGenerator(OpenAPIConfig{MaxEndpointSamples: defaultEndpointSamples}).samplingSettings()))
+	assert.NotEqual(t, endpointHashes(transactions, paths, defaults.samplingSettings()),
+		endpointHashes(transactions, paths, capped.samplingSettings()))
+}
+
+func TestLoadAnalysisCache(t *testing.T) {
+	dir := t.TempDir()
+
+	// A missing cache is empty
+	cache, err := LoadAnalysisCache(filepath.Join(dir, "missing.json"))
+	require.NoError(t, err)
+	assert.Empty(t, cache.Endpoints)
+
+	// So is one written by another version
+	stale := filepath.Join(dir, "stale.json")
+	require.NoError(t, os.WriteFile(stale, []byte(`{"version":0,"endpoints":{"GET /users":{"hash":"abc"}}}`), 0644))
+	cache, err = LoadAnalysisCache(stale)
+	require.NoError(t, err)
+	assert.Empty(t, cache.Endpoints)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte("not json"), 0644))
+	_, err = LoadAnalysisCache(invalid)
+	assert.Error(t, err)
+}
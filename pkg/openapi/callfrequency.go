@@ -0,0 +1,131 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// callFrequencyExtension is the operation extension holding how often the operation was called
+const callFrequencyExtension = "x-call-frequency"
+
+// newPathDetector creates a path pattern detector with the configured template overrides
+func (g *OpenAPIGenerator) newPathDetector() (*parser.PathPatternDetector, error) {
+	detector := parser.NewPathPatternDetector()
+	for _, pattern := range sortedTemplatePatterns(g.config.PathTemplates) {
+		if err := detector.AddTemplateOverride(pattern, g.config.PathTemplates[pattern]); err != nil {
+			return nil, err
+		}
+	}
+	return detector, nil
+}
+
+// dropRareEndpoints leaves out the transactions of the endpoints captured fewer than MinCalls
+// times, typically typos and vulnerability scanners. Endpoints are the methods and templated
+// paths the request paths are documented by.
+func (g *OpenAPIGenerator) dropRareEndpoints(transactions []proxy.APITransaction) ([]proxy.APITransaction, error) {
+	if g.config.MinCalls <= 1 {
+		return transactions, nil
+	}
+	detector, err := g.newPathDetector()
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range transactions {
+		detector.AddPath(tx.Request.Path)
+	}
+	detector.AnalyzePatterns()
+
+	templates := make(map[string]string)
+	endpoints := make([]string, len(transactions))
+	calls := make(map[string]int)
+	for i, tx := range transactions {
+		template, ok := templates[tx.Request.Path]
+		if !ok {
+			if template = detector.TemplatizePath(tx.Request.Path); template == "" {
+				template = tx.Request.Path
+			}
+			templates[tx.Request.Path] = template
+		}
+		endpoints[i] = tx.Request.Method + " " + template
+		calls[endpoints[i]]++
+	}
+
+	kept := make([]proxy.APITransaction, 0, len(transactions))
+	for i, tx := range transactions {
+		if calls[endpoints[i]] >= g.config.MinCalls {
+			kept = append(kept, tx)
+		}
+	}
+	for endpoint, count := range calls {
+		if count < g.config.MinCalls {
+			times := "once"
+			if count > 1 {
+				times = fmt.Sprintf("%d times", count)
+			}
+			g.stats.RareEndpoints = append(g.stats.RareEndpoints, fmt.Sprintf("%s, captured %s", endpoint, times))
+		}
+	}
+	sort.Strings(g.stats.RareEndpoints)
+	return kept, nil
+}
+
+// requestCalls counts the transactions by method and request path. It runs before the response
+// strategy, so that every captured request counts.
+func requestCalls(transactions []proxy.APITransaction) map[string]int {
+	calls := make(map[string]int)
+	for _, tx := range transactions {
+		calls[tx.Request.Method+" "+tx.Request.Path]++
+	}
+	return calls
+}
+
+// addCallFrequencies sets x-call-frequency on operations, with the number of captured requests
+// they document and their share of all of them, and orders the declared tags by their calls,
+// busiest first. The request paths are matched against the documented paths, so that the
+// requests the response strategy left out count too.
+func addCallFrequencies(doc *OpenAPISpec, calls map[string]int) {
+	templates, prefixes := documentedPaths(doc)
+	byOperation := make(map[string]int)
+	total := 0
+	for key, count := range calls {
+		method, path, _ := strings.Cut(key, " ")
+		if template, ok := matchPathTemplate(path, templates, prefixes); ok && operationForKey(doc, method+" "+template) != nil {
+			byOperation[method+" "+template] += count
+			total += count
+		}
+	}
+
+	byTag := make(map[string]int)
+	for key, count := range byOperation {
+		op := operationForKey(doc, key)
+		setOperationExtension(op, callFrequencyExtension, map[string]interface{}{
+			"calls": count,
+			"share": math.Round(float64(count)/float64(total)*10000) / 10000,
+		})
+		for _, tag := range op.Tags {
+			byTag[tag] += count
+		}
+	}
+	sort.SliceStable(doc.Tags, func(i, j int) bool {
+		return byTag[doc.Tags[i].Name] > byTag[doc.Tags[j].Name]
+	})
+}
+
+// callCount returns the calls in the x-call-frequency of an operation, or 0 without one
+func callCount(op *openapi3.Operation) int {
+	frequency, _ := op.Extensions[callFrequencyExtension].(map[string]interface{})
+	switch calls := frequency["calls"].(type) {
+	case int:
+		return calls
+	case float64:
+		return int(calls)
+	}
+	return 0
+}
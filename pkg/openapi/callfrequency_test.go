@@ -0,0 +1,69 @@
+package openapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callFrequencyTestTransactions are 6 user requests, 3 order requests, and 1 health check
+func callFrequencyTestTransactions() []proxy.APITransaction {
+	var transactions []proxy.APITransaction
+	for i := 1; i <= 5; i++ {
+		transactions = append(transactions, createTestTransaction("GET", fmt.Sprintf("/users/%d", i), nil, []byte(`{"id":1,"name":"Ada"}`), 200))
+	}
+	transactions = append(transactions, createTestTransaction("POST", "/users", []byte(`{"name":"Ada"}`), []byte(`{"id":1}`), 201))
+	for i := 0; i < 3; i++ {
+		transactions = append(transactions, createTestTransaction("GET", "/orders", nil, []byte(`[{"id":1}]`), 200))
+	}
+	return append(transactions, createTestTransaction("GET", "/health", nil, []byte(`{"status":"ok"}`), 200))
+}
+
+func TestCallFrequency(t *testing.T) {
+	config := OpenAPIConfig{UsePathGroups: true, CallFrequency: true, ResponseStrategy: StrategyBestSuccess}
+	spec, stats := generateTestSpec(t, config, callFrequencyTestTransactions()...)
+	assert.Empty(t, stats.RareEndpoints)
+
+	// Every request counts, not just the one the response strategy documents
+	assert.Equal(t, map[string]interface{}{"calls": 5, "share": 0.5},
+		spec.Paths.Value("/users/{userId}").Get.Extensions[callFrequencyExtension])
+	assert.Equal(t, map[string]interface{}{"calls": 1, "share": 0.1},
+		spec.Paths.Value("/users").Post.Extensions[callFrequencyExtension])
+	assert.Equal(t, 3, callCount(spec.Paths.Value("/orders").Get))
+
+	// Tags are ordered by their calls, busiest first
+	var tags []string
+	for _, tag := range spec.Tags {
+		tags = append(tags, tag.Name)
+	}
+	assert.Equal(t, []string{"Users", "Orders", "Health"}, tags)
+
+	grouped := operationsByTag(spec)
+	assert.Equal(t, []string{"Users", "Orders", "Health"}, sortedTags(grouped))
+	require.Len(t, grouped["Users"], 2)
+	assert.Equal(t, "/users/{userId}", grouped["Users"][0].path)
+	assert.Equal(t, "/users", grouped["Users"][1].path)
+}
+
+func TestCallFrequencyDisabled(t *testing.T) {
+	spec, _ := generateTestSpec(t, markdownTestConfig(), markdownTestTransactions()...)
+	assert.NotContains(t, spec.Paths.Value("/orders").Get.Extensions, callFrequencyExtension)
+	assert.Equal(t, []string{"Orders", "Users"}, sortedTags(operationsByTag(spec)))
+}
+
+func TestMinCalls(t *testing.T) {
+	config := OpenAPIConfig{UsePathGroups: true, CallFrequency: true, MinCalls: 2, ResponseStrategy: StrategyBestSuccess}
+	spec, stats := generateTestSpec(t, config, callFrequencyTestTransactions()...)
+
+	assert.Nil(t, spec.Paths.Value("/health"))
+	assert.Nil(t, spec.Paths.Value("/users"))
+	assert.NotNil(t, spec.Paths.Value("/users/{userId}"))
+	assert.Equal(t, []string{"GET /health, captured once", "POST /users, captured once"}, stats.RareEndpoints)
+
+	// Shares are of the requests left
+	assert.Equal(t, 0.625, spec.Paths.Value("/users/{userId}").Get.Extensions[callFrequencyExtension].(map[string]interface{})["share"])
+}
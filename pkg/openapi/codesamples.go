@@ -0,0 +1,359 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// codeSample is an entry of the x-codeSamples extension rendered by Redoc and Stoplight
+type codeSample struct {
+	Lang   string `json:"lang"`
+	Label  string `json:"label"`
+	Source string `json:"source"`
+}
+
+// maxExampleDepth limits how deep example bodies are built from nested or recursive schemas
+const maxExampleDepth = 6
+
+// sampleRequest is the request an operation's code samples send
+type sampleRequest struct {
+	method  string
+	url     string
+	headers [][2]string // name, value in a stable order
+	body    interface{} // example JSON body, or nil
+}
+
+// addCodeSamples adds curl, JavaScript, Go, and Python snippets to every operation, calling
+// the first server with example path, query, header, and body values
+func addCodeSamples(doc *OpenAPISpec) {
+	baseURL := serverBaseURL(doc)
+	for _, path := range doc.Paths.InMatchingOrder() {
+		pathItem := doc.Paths.Value(path)
+		for _, method := range operationMethods {
+			op := operationFor(pathItem, method)
+			if op == nil {
+				continue
+			}
+
+			request := newSampleRequest(doc, baseURL, method, path, op)
+			setOperationExtension(op, "x-codeSamples", []codeSample{
+				{Lang: "Shell", Label: "curl", Source: curlSample(request)},
+				{Lang: "JavaScript", Label: "JavaScript", Source: javaScriptSample(request)},
+				{Lang: "Go", Label: "Go", Source: goSample(request)},
+				{Lang: "Python", Label: "Python", Source: pythonSample(request)},
+			})
+		}
+	}
+}
+
+// serverBaseURL returns the URL of the first server with its variables set to their defaults
+func serverBaseURL(doc *OpenAPISpec) string {
+	if len(doc.Servers) == 0 {
+		return ""
+	}
+	baseURL := strings.TrimRight(doc.Servers[0].URL, "/")
+	for name, variable := range doc.Servers[0].Variables {
+		baseURL = strings.ReplaceAll(baseURL, "{"+name+"}", variable.Default)
+	}
+	return baseURL
+}
+
+// newSampleRequest fills in the example values of an operation's parameters and body
+func newSampleRequest(doc *OpenAPISpec, baseURL, method, path string, op *openapi3.Operation) sampleRequest {
+	request := sampleRequest{method: method}
+
+	var query []string
+	for _, paramRef := range op.Parameters {
+		param := paramRef.Value
+		if param == nil {
+			continue
+		}
+		value := parameterExample(doc, param)
+		switch param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", value)
+		case "query":
+			if param.Required {
+				query = append(query, param.Name+"="+value)
+			}
+		case "header":
+			request.headers = append(request.headers, [2]string{param.Name, value})
+		}
+	}
+	sort.Strings(query)
+
+	request.url = baseURL + path
+	if len(query) > 0 {
+		request.url += "?" + strings.Join(query, "&")
+	}
+
+	request.headers = append(request.headers, securityHeaders(doc, op)...)
+
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		for _, mediaType := range sortedMediaTypes(op.RequestBody.Value.Content) {
+			if !isJSONContentType(mediaType) {
+				continue
+			}
+			media := op.RequestBody.Value.Content[mediaType]
+			request.body = media.Example
+			if request.body == nil {
+				request.body = schemaExample(doc, media.Schema, 0)
+			}
+			request.headers = append(request.headers, [2]string{"Content-Type", mediaType})
+			break
+		}
+	}
+
+	return request
+}
+
+// parameterExample returns the example value of a parameter as it appears in a URL or header
+func parameterExample(doc *OpenAPISpec, param *openapi3.Parameter) string {
+	value := param.Example
+	if value == nil {
+		value = schemaExample(doc, param.Schema, 0)
+	}
+	if value == nil {
+		return "{" + param.Name + "}"
+	}
+	return fmt.Sprint(value)
+}
+
+// securityHeaders returns placeholder credentials for the security schemes an operation needs
+func securityHeaders(doc *OpenAPISpec, op *openapi3.Operation) [][2]string {
+	requirements := doc.Security
+	if op.Security != nil {
+		requirements = *op.Security
+	}
+	if len(requirements) == 0 || doc.Components == nil {
+		return nil
+	}
+
+	var names []string
+	for name := range requirements[0] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var headers [][2]string
+	for _, name := range names {
+		ref := doc.Components.SecuritySchemes[name]
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		scheme := ref.Value
+		switch {
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+			headers = append(headers, [2]string{"Authorization", "Bearer $TOKEN"})
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+			headers = append(headers, [2]string{"Authorization", "Basic $CREDENTIALS"})
+		case scheme.Type == "apiKey" && scheme.In == "header":
+			headers = append(headers, [2]string{scheme.Name, "$API_KEY"})
+		}
+	}
+	return headers
+}
+
+// schemaExample builds an example value from a schema, preferring the examples recorded in it
+func schemaExample(doc *OpenAPISpec, ref *openapi3.SchemaRef, depth int) interface{} {
+	if ref == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	schema := ref.Value
+	if ref.Ref != "" && doc.Components != nil {
+		if component := doc.Components.Schemas[strings.TrimPrefix(ref.Ref, "#/components/schemas/")]; component != nil {
+			schema = component.Value
+		}
+	}
+	if schema == nil {
+		return nil
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+	if len(schema.OneOf) > 0 {
+		return schemaExample(doc, schema.OneOf[0], depth+1)
+	}
+
+	switch {
+	case schema.Type.Is("object"):
+		example := make(map[string]interface{})
+		for name, prop := range schema.Properties {
+			if prop != nil && prop.Value != nil && prop.Value.ReadOnly {
+				continue
+			}
+			if value := schemaExample(doc, prop, depth+1); value != nil {
+				example[name] = value
+			}
+		}
+		return example
+	case schema.Type.Is("array"):
+		if item := schemaExample(doc, schema.Items, depth+1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case schema.Type.Is("integer"), schema.Type.Is("number"):
+		return 0
+	case schema.Type.Is("boolean"):
+		return true
+	case schema.Type.Is("string"):
+		return "string"
+	}
+	return nil
+}
+
+// sortedMediaTypes returns the media types of a content map in sorted order
+func sortedMediaTypes(content openapi3.Content) []string {
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
+// indentedJSON formats an example body as indented JSON
+func indentedJSON(value interface{}, indent string) string {
+	data, err := json.MarshalIndent(value, indent, "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// shellQuote quotes a value for a POSIX shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// curlSample builds a curl command for a request
+func curlSample(request sampleRequest) string {
+	lines := []string{fmt.Sprintf("curl -X %s %s", request.method, shellQuote(request.url))}
+	for _, header := range request.headers {
+		// Keep $TOKEN-style placeholders expandable by the shell
+		lines = append(lines, fmt.Sprintf(`  -H "%s: %s"`, header[0], header[1]))
+	}
+	if request.body != nil {
+		lines = append(lines, "  -d "+shellQuote(indentedJSON(request.body, "")))
+	}
+	return strings.Join(lines, " \\\n")
+}
+
+// javaScriptSample builds a fetch call for a request
+func javaScriptSample(request sampleRequest) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "const response = await fetch(%s, {\n", strconv.Quote(request.url))
+	fmt.Fprintf(&builder, "  method: %s,\n", strconv.Quote(request.method))
+	if len(request.headers) > 0 {
+		builder.WriteString("  headers: {\n")
+		for i, header := range request.headers {
+			separator := ","
+			if i == len(request.headers)-1 {
+				separator = ""
+			}
+			fmt.Fprintf(&builder, "    %s: %s%s\n", strconv.Quote(header[0]), strconv.Quote(header[1]), separator)
+		}
+		builder.WriteString("  },\n")
+	}
+	if request.body != nil {
+		fmt.Fprintf(&builder, "  body: JSON.stringify(%s),\n", indentedJSON(request.body, "  "))
+	}
+	builder.WriteString("});\n")
+	builder.WriteString("console.log(response.status, await response.text());")
+	return builder.String()
+}
+
+// goSample builds a net/http program for a request
+func goSample(request sampleRequest) string {
+	imports := []string{`"fmt"`, `"io"`, `"net/http"`}
+	body := "nil"
+	if request.body != nil {
+		imports = append(imports, `"strings"`)
+		body = "strings.NewReader(`" + indentedJSON(request.body, "\t") + "`)"
+	}
+
+	var builder strings.Builder
+	builder.WriteString("package main\n\nimport (\n")
+	for _, name := range imports {
+		fmt.Fprintf(&builder, "\t%s\n", name)
+	}
+	builder.WriteString(")\n\nfunc main() {\n")
+	fmt.Fprintf(&builder, "\treq, err := http.NewRequest(%s, %s, %s)\n", strconv.Quote(request.method), strconv.Quote(request.url), body)
+	builder.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	for _, header := range request.headers {
+		fmt.Fprintf(&builder, "\treq.Header.Set(%s, %s)\n", strconv.Quote(header[0]), strconv.Quote(header[1]))
+	}
+	builder.WriteString("\n\tresp, err := http.DefaultClient.Do(req)\n")
+	builder.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	builder.WriteString("\tdefer resp.Body.Close()\n\n")
+	builder.WriteString("\tdata, _ := io.ReadAll(resp.Body)\n")
+	builder.WriteString("\tfmt.Println(resp.Status, string(data))\n}")
+	return builder.String()
+}
+
+// pythonSample builds a requests call for a request
+func pythonSample(request sampleRequest) string {
+	var builder strings.Builder
+	builder.WriteString("import requests\n\n")
+	fmt.Fprintf(&builder, "response = requests.request(\n    %s,\n    %s,\n", strconv.Quote(request.method), strconv.Quote(request.url))
+	if len(request.headers) > 0 {
+		var headers []string
+		for _, header := range request.headers {
+			headers = append(headers, strconv.Quote(header[0])+": "+strconv.Quote(header[1]))
+		}
+		fmt.Fprintf(&builder, "    headers={%s},\n", strings.Join(headers, ", "))
+	}
+	if request.body != nil {
+		fmt.Fprintf(&builder, "    json=%s,\n", pythonLiteral(request.body))
+	}
+	builder.WriteString(")\n")
+	builder.WriteString("print(response.status_code, response.text)")
+	return builder.String()
+}
+
+// pythonLiteral formats a JSON value as a Python literal
+func pythonLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case string:
+		return strconv.Quote(v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = strconv.Quote(key) + ": " + pythonLiteral(v[key])
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = pythonLiteral(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "None"
+	}
+	return string(data)
+}
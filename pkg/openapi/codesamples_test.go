@@ -0,0 +1,73 @@
+package openapi
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecCodeSamples(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:       "Test API",
+		Version:     "1.0.0",
+		Servers:     []OpenAPIServer{{URL: "https://api.example.com/"}},
+		CodeSamples: true,
+	})
+	generator.AddTransaction(createTestTransaction("POST", "/users", []byte(`{"name":"__string__","active":true}`), []byte(`{"id":"__integer__","name":"__string__"}`), 201))
+	generator.AddTransaction(createTestTransaction("GET", "/users/1", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/users/2", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	post := spec.Paths.Find("/users").Post
+	samples, ok := post.Extensions["x-codeSamples"].([]codeSample)
+	require.True(t, ok)
+	require.Len(t, samples, 4)
+
+	languages := make(map[string]string)
+	for _, sample := range samples {
+		languages[sample.Lang] = sample.Source
+	}
+
+	curl := languages["Shell"]
+	assert.Contains(t, curl, "curl -X POST 'https://api.example.com/users'")
+	assert.Contains(t, curl, `-H "Content-Type: application/json"`)
+	assert.Contains(t, curl, `"name": "string"`)
+
+	assert.Contains(t, languages["JavaScript"], `method: "POST"`)
+	assert.Contains(t, languages["Python"], `json={"active": True, "name": "string"}`)
+
+	// The Go snippet is a valid program
+	_, err = parser.ParseFile(token.NewFileSet(), "main.go", languages["Go"], 0)
+	assert.NoError(t, err)
+
+	// Path parameters are filled in with example values
+	get := spec.Paths.Find("/users/{userId}").Get
+	getSamples := get.Extensions["x-codeSamples"].([]codeSample)
+	assert.Contains(t, getSamples[0].Source, "curl -X GET 'https://api.example.com/users/")
+	assert.NotContains(t, getSamples[0].Source, "{userId}")
+	assert.NoError(t, ValidateSpec(spec))
+}
+
+func TestPythonLiteral(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{name: "null", value: nil, expected: "None"},
+		{name: "booleans", value: []interface{}{true, false}, expected: "[True, False]"},
+		{name: "number", value: 1.5, expected: "1.5"},
+		{name: "object", value: map[string]interface{}{"b": "x", "a": 0}, expected: `{"a": 0, "b": "x"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, pythonLiteral(tt.value))
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// pathParamPattern matches the parameters of a templated path, e.g. {userId}
+var pathParamPattern = regexp.MustCompile(`\{[^/{}]+\}`)
+
+// repairPathCollisions merges paths that differ only in the names of their parameters, e.g.
+// /users/{id} and /users/{uuid}, which OpenAPI considers the same path. Each group is merged into
+// the path with the most operations, or the first in sorted order on ties; path parameters of the
+// moved operations are renamed, and operations of the same method are merged. It returns a
+// description of every merge.
+func repairPathCollisions(doc *OpenAPISpec) []string {
+	groups := make(map[string][]string)
+	for _, path := range doc.Paths.InMatchingOrder() {
+		shape := pathParamPattern.ReplaceAllString(path, "{}")
+		groups[shape] = append(groups[shape], path)
+	}
+
+	var shapes []string
+	for shape, paths := range groups {
+		if len(paths) > 1 {
+			shapes = append(shapes, shape)
+		}
+	}
+	sort.Strings(shapes)
+
+	var repairs []string
+	for _, shape := range shapes {
+		paths := groups[shape]
+		sort.SliceStable(paths, func(i, j int) bool {
+			a, b := len(doc.Paths.Value(paths[i]).Operations()), len(doc.Paths.Value(paths[j]).Operations())
+			if a != b {
+				return a > b
+			}
+			return paths[i] < paths[j]
+		})
+
+		target := paths[0]
+		targetItem := doc.Paths.Value(target)
+		for _, path := range paths[1:] {
+			renames := pathParamRenames(path, target)
+			for _, method := range operationMethods {
+				op := operationFor(doc.Paths.Value(path), method)
+				if op == nil {
+					continue
+				}
+				for _, param := range op.Parameters {
+					if param.Value == nil || param.Value.In != "path" || renames[param.Value.Name] == "" {
+						continue
+					}
+					name := renames[param.Value.Name]
+					if param.Value.Description == "Path parameter: "+param.Value.Name {
+						param.Value.Description = "Path parameter: " + name
+					}
+					param.Value.Name = name
+				}
+				if existing := operationFor(targetItem, method); existing != nil {
+					mergeOperation(existing, op)
+				} else {
+					targetItem.SetOperation(method, op)
+				}
+			}
+			doc.Paths.Delete(path)
+			repairs = append(repairs, fmt.Sprintf("Merged %s into %s, which differ only in parameter names", path, target))
+		}
+	}
+	return repairs
+}
+
+// pathParamRenames maps the parameter names of a path to those of an equally shaped one
+func pathParamRenames(from, to string) map[string]string {
+	fromParams, toParams := pathParamPattern.FindAllString(from, -1), pathParamPattern.FindAllString(to, -1)
+	renames := make(map[string]string)
+	for i := range fromParams {
+		if i < len(toParams) {
+			renames[strings.Trim(fromParams[i], "{}")] = strings.Trim(toParams[i], "{}")
+		}
+	}
+	return renames
+}
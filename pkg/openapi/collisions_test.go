@@ -0,0 +1,49 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairPathCollisions(t *testing.T) {
+	operation := func(param string, statuses ...int) *openapi3.Operation {
+		op := openapi3.NewOperation()
+		op.AddParameter(&openapi3.Parameter{Name: param, In: "path", Required: true, Description: "Path parameter: " + param})
+		op.Responses = openapi3.NewResponses()
+		for _, status := range statuses {
+			op.AddResponse(status, openapi3.NewResponse().WithDescription("Observed response"))
+		}
+		return op
+	}
+
+	doc := &OpenAPISpec{OpenAPI: "3.0.3", Paths: openapi3.NewPaths()}
+	doc.AddOperation("/users/{id}", "GET", operation("id", 200))
+	doc.AddOperation("/users/{id}", "DELETE", operation("id", 204))
+	doc.AddOperation("/users/{uuid}", "GET", operation("uuid", 404))
+	doc.AddOperation("/users/{uuid}", "PATCH", operation("uuid", 200))
+	doc.AddOperation("/users/{id}/orders", "GET", operation("id", 200))
+
+	repairs := repairPathCollisions(doc)
+	assert.Equal(t, []string{"Merged /users/{uuid} into /users/{id}, which differ only in parameter names"}, repairs)
+
+	assert.Nil(t, doc.Paths.Value("/users/{uuid}"))
+	pathItem := doc.Paths.Value("/users/{id}")
+	require.NotNil(t, pathItem)
+
+	// Operations of the same method are merged
+	require.NotNil(t, pathItem.Get)
+	assert.NotNil(t, pathItem.Get.Responses.Value("200"))
+	assert.NotNil(t, pathItem.Get.Responses.Value("404"))
+	assert.Len(t, pathItem.Get.Parameters, 1)
+
+	// Moved operations use the parameter names of the path they moved to
+	require.NotNil(t, pathItem.Patch)
+	param := pathItem.Patch.Parameters.GetByInAndName("path", "id")
+	require.NotNil(t, param)
+	assert.Equal(t, "Path parameter: id", param.Description)
+
+	assert.Empty(t, repairPathCollisions(doc))
+}
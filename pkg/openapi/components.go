@@ -0,0 +1,477 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// resourceSchemas collects the inline schemas that describe one resource, e.g. /users and
+// /users/{userId}, in requests and in successful responses
+type resourceSchemas struct {
+	path      string
+	requests  []*openapi3.MediaType
+	responses []*openapi3.SchemaRef
+}
+
+// extractResourceComponents compares the request and response schemas of every resource and
+// moves resources seen in both directions into reusable component schemas. Properties only
+// ever sent by the server are marked readOnly and properties only ever sent by the client are
+// marked writeOnly, so that one component describes both directions.
+func extractResourceComponents(doc *OpenAPISpec) {
+	resources := make(map[string]*resourceSchemas)
+
+	paths := doc.Paths.InMatchingOrder()
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pathItem := doc.Paths.Value(path)
+		key := resourcePath(path)
+
+		for _, method := range operationMethods {
+			op := operationFor(pathItem, method)
+			if op == nil {
+				continue
+			}
+
+			if _, ok := resources[key]; !ok {
+				resources[key] = &resourceSchemas{path: key}
+			}
+			resource := resources[key]
+
+			if method == "POST" || method == "PUT" || method == "PATCH" {
+				if op.RequestBody != nil && op.RequestBody.Value != nil {
+					if media := op.RequestBody.Value.Content.Get("application/json"); isObjectMedia(media) {
+						resource.requests = append(resource.requests, media)
+					}
+				}
+			}
+
+			if op.Responses == nil {
+				continue
+			}
+			for status, response := range op.Responses.Map() {
+				if !strings.HasPrefix(status, "2") || response.Value == nil {
+					continue
+				}
+				if ref := resourceSchemaRef(response.Value.Content.Get("application/json")); ref != nil {
+					resource.responses = append(resource.responses, ref)
+				}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(resources))
+	for key := range resources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		resource := resources[key]
+		if len(resource.requests) == 0 || len(resource.responses) == 0 {
+			continue
+		}
+
+		component := combineResourceSchemas(resource)
+		if component == nil {
+			continue
+		}
+
+		name := componentName(resource.path, doc.Components.Schemas)
+		doc.Components.Schemas[name] = openapi3.NewSchemaRef("", component)
+		ref := "#/components/schemas/" + name
+
+		for _, media := range resource.requests {
+			media.Schema = openapi3.NewSchemaRef(ref, component)
+		}
+		for _, schemaRef := range resource.responses {
+			schemaRef.Ref = ref
+			schemaRef.Value = component
+		}
+	}
+}
+
+// combineResourceSchemas merges the request and response schemas of a resource into one
+// object schema with readOnly and writeOnly properties. It returns nil when the two sides
+// share no properties, as they then most likely describe different things (e.g. a login
+// request and a token response).
+func combineResourceSchemas(resource *resourceSchemas) *openapi3.Schema {
+	requestProps := make(map[string]*openapi3.SchemaRef)
+	requestRequired := make(map[string]bool)
+	for _, media := range resource.requests {
+		for name, prop := range media.Schema.Value.Properties {
+			if _, ok := requestProps[name]; !ok {
+				requestProps[name] = prop
+			}
+		}
+		for _, name := range media.Schema.Value.Required {
+			requestRequired[name] = true
+		}
+	}
+
+	responseProps := make(map[string]*openapi3.SchemaRef)
+	responseRequired := make(map[string]bool)
+	for _, schemaRef := range resource.responses {
+		for name, prop := range schemaRef.Value.Properties {
+			if _, ok := responseProps[name]; !ok {
+				responseProps[name] = prop
+			}
+		}
+		for _, name := range schemaRef.Value.Required {
+			responseRequired[name] = true
+		}
+	}
+
+	shared := false
+	for name := range requestProps {
+		if _, ok := responseProps[name]; ok {
+			shared = true
+			break
+		}
+	}
+	if !shared {
+		return nil
+	}
+
+	component := openapi3.NewObjectSchema()
+	for name, prop := range responseProps {
+		value := *prop.Value
+		if _, sentByClient := requestProps[name]; !sentByClient {
+			value.ReadOnly = true
+		}
+		component.Properties[name] = openapi3.NewSchemaRef("", &value)
+		if responseRequired[name] {
+			component.Required = append(component.Required, name)
+		}
+	}
+	for name, prop := range requestProps {
+		if _, ok := responseProps[name]; ok {
+			continue
+		}
+		value := *prop.Value
+		value.WriteOnly = true
+		component.Properties[name] = openapi3.NewSchemaRef("", &value)
+		if requestRequired[name] {
+			component.Required = append(component.Required, name)
+		}
+	}
+	sort.Strings(component.Required)
+
+	return component
+}
+
+// resourceSchemaRef returns the schema describing a single resource in a response: the body
+// itself for objects, or the item schema for arrays. Pagination envelopes are skipped.
+func resourceSchemaRef(media *openapi3.MediaType) *openapi3.SchemaRef {
+	if media == nil || media.Schema == nil || media.Schema.Value == nil {
+		return nil
+	}
+
+	schema := media.Schema.Value
+	if schema.Type.Is("array") {
+		if schema.Items != nil && schema.Items.Value != nil && schema.Items.Value.Type.Is("object") {
+			return schema.Items
+		}
+		return nil
+	}
+
+	if _, isEnvelope := schema.Extensions["x-pagination-envelope"]; isEnvelope {
+		return nil
+	}
+	if schema.Type.Is("object") && len(schema.Properties) > 0 {
+		return media.Schema
+	}
+	return nil
+}
+
+// isObjectMedia checks whether a media type carries an inline object schema with properties
+func isObjectMedia(media *openapi3.MediaType) bool {
+	return media != nil && media.Schema != nil && media.Schema.Value != nil &&
+		media.Schema.Value.Type.Is("object") && len(media.Schema.Value.Properties) > 0
+}
+
+// resourcePath strips trailing path parameters so that a collection and its items share
+// a resource, e.g. /users/{userId} becomes /users
+func resourcePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for len(segments) > 0 && isPathParam(segments[len(segments)-1]) {
+		segments = segments[:len(segments)-1]
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// componentName builds a schema name from the last segment of a resource path, e.g.
+// /users becomes User. Names already in use are qualified with earlier path segments.
+func componentName(path string, existing openapi3.Schemas) string {
+	var literals []string
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment != "" && !isPathParam(segment) {
+			literals = append(literals, segment)
+		}
+	}
+	if len(literals) == 0 {
+		literals = []string{"root"}
+	}
+
+	name := ""
+	for i := len(literals) - 1; i >= 0; i-- {
+		segment := literals[i]
+		if i == len(literals)-1 {
+			segment = parser.Singularize(segment)
+		}
+		name = parser.PascalCase(segment) + name
+		if _, taken := existing[name]; !taken {
+			return name
+		}
+	}
+
+	return uniqueSchemaName(name, existing)
+}
+
+// extractVariantComponents moves the inline oneOf variants of discriminated schemas into
+// component schemas, so that the discriminator mapping can reference them
+func extractVariantComponents(doc *OpenAPISpec) {
+	visited := make(map[*openapi3.Schema]bool)
+
+	var visit func(ref *openapi3.SchemaRef)
+	visit = func(ref *openapi3.SchemaRef) {
+		if ref == nil || ref.Value == nil || visited[ref.Value] {
+			return
+		}
+		schema := ref.Value
+		visited[schema] = true
+
+		for _, prop := range schema.Properties {
+			visit(prop)
+		}
+		visit(schema.Items)
+		visit(schema.AdditionalProperties.Schema)
+		for _, variant := range schema.OneOf {
+			visit(variant)
+		}
+
+		if schema.Discriminator == nil {
+			return
+		}
+		for _, variant := range schema.OneOf {
+			if variant.Ref != "" || variant.Value == nil {
+				continue
+			}
+
+			value, ok := discriminatorValue(variant.Value, schema.Discriminator.PropertyName)
+			if !ok {
+				continue
+			}
+			name := schema.Discriminator.Mapping[value]
+			if name == "" {
+				name = parser.PascalCase(value)
+			}
+			// Identical variants seen in other operations share one component
+			if existing, taken := doc.Components.Schemas[name]; !taken || !sameSchema(existing.Value, variant.Value) {
+				name = uniqueSchemaName(name, doc.Components.Schemas)
+				doc.Components.Schemas[name] = openapi3.NewSchemaRef("", variant.Value)
+			}
+			variant.Ref = "#/components/schemas/" + name
+			schema.Discriminator.Mapping[value] = variant.Ref
+		}
+	}
+
+	paths := doc.Paths.InMatchingOrder()
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathItem := doc.Paths.Value(path)
+		for _, method := range operationMethods {
+			op := operationFor(pathItem, method)
+			if op == nil {
+				continue
+			}
+
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				for _, media := range op.RequestBody.Value.Content {
+					visit(media.Schema)
+				}
+			}
+			if op.Responses == nil {
+				continue
+			}
+			for _, response := range op.Responses.Map() {
+				if response == nil || response.Value == nil {
+					continue
+				}
+				for _, media := range response.Value.Content {
+					visit(media.Schema)
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		visit(doc.Components.Schemas[name])
+	}
+}
+
+// discriminatorValue returns the single enum value of a variant's discriminator property
+func discriminatorValue(variant *openapi3.Schema, propertyName string) (string, bool) {
+	prop, ok := variant.Properties[propertyName]
+	if !ok || prop.Value == nil || len(prop.Value.Enum) != 1 {
+		return "", false
+	}
+	value, ok := prop.Value.Enum[0].(string)
+	return value, ok
+}
+
+// uniqueSchemaName appends a numeric suffix when a component schema name is already taken
+func uniqueSchemaName(name string, existing openapi3.Schemas) string {
+	candidate := name
+	for i := 2; ; i++ {
+		if _, taken := existing[candidate]; !taken {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+}
+
+// sameSchema checks whether two schemas serialize identically
+func sameSchema(a, b *openapi3.Schema) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aJSON, bJSON)
+}
+
+// minSharedProperties is how many properties an inline object needs before identical copies
+// of it are moved into a component, so that small objects like {id, name} pairs in unrelated
+// places aren't tied together
+const minSharedProperties = 3
+
+// sharedSchema collects the places an inline object schema appears, by structure
+type sharedSchema struct {
+	name string
+	refs []*openapi3.SchemaRef
+}
+
+// extractSharedComponents moves nested inline object schemas that appear more than once, e.g.
+// the same address object in orders and customers, into one component schema referenced from
+// every place, so that the spec describes each structure once
+func extractSharedComponents(doc *OpenAPISpec) {
+	shared := make(map[string]*sharedSchema)
+	var hashes []string
+	visited := make(map[*openapi3.Schema]bool)
+
+	var visit func(ref *openapi3.SchemaRef)
+	record := func(name string, ref *openapi3.SchemaRef) {
+		visit(ref)
+		if ref == nil || ref.Ref != "" || ref.Value == nil || !ref.Value.Type.Is("object") ||
+			len(ref.Value.Properties) < minSharedProperties {
+			return
+		}
+		data, err := json.Marshal(ref.Value)
+		if err != nil {
+			return
+		}
+		hash := string(data)
+		if shared[hash] == nil {
+			shared[hash] = &sharedSchema{name: name}
+			hashes = append(hashes, hash)
+		}
+		shared[hash].refs = append(shared[hash].refs, ref)
+	}
+	visit = func(ref *openapi3.SchemaRef) {
+		if ref == nil || ref.Ref != "" || ref.Value == nil || visited[ref.Value] {
+			return
+		}
+		schema := ref.Value
+		visited[schema] = true
+
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop := schema.Properties[name]
+			if prop != nil && prop.Value != nil && prop.Ref == "" && prop.Value.Type.Is("array") {
+				visited[prop.Value] = true
+				record(name, prop.Value.Items)
+				continue
+			}
+			record(name, prop)
+		}
+		visit(schema.Items)
+		visit(schema.AdditionalProperties.Schema)
+		for _, variant := range schema.OneOf {
+			visit(variant)
+		}
+	}
+
+	paths := doc.Paths.InMatchingOrder()
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathItem := doc.Paths.Value(path)
+		for _, method := range operationMethods {
+			op := operationFor(pathItem, method)
+			if op == nil {
+				continue
+			}
+
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				for _, mediaType := range sortedMediaTypes(op.RequestBody.Value.Content) {
+					visit(op.RequestBody.Value.Content[mediaType].Schema)
+				}
+			}
+			if op.Responses == nil {
+				continue
+			}
+			statuses := make([]string, 0, op.Responses.Len())
+			for status := range op.Responses.Map() {
+				statuses = append(statuses, status)
+			}
+			sort.Strings(statuses)
+			for _, status := range statuses {
+				response := op.Responses.Value(status)
+				if response == nil || response.Value == nil {
+					continue
+				}
+				for _, mediaType := range sortedMediaTypes(response.Value.Content) {
+					visit(response.Value.Content[mediaType].Schema)
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		visit(doc.Components.Schemas[name])
+	}
+
+	// Nested objects were recorded before the objects containing them, so inner components are
+	// referenced by the time an outer one is extracted
+	for _, hash := range hashes {
+		schema := shared[hash]
+		if len(schema.refs) < 2 {
+			continue
+		}
+		component := schema.refs[0].Value
+		name := uniqueSchemaName(parser.PascalCase(parser.Singularize(schema.name)), doc.Components.Schemas)
+		doc.Components.Schemas[name] = openapi3.NewSchemaRef("", component)
+		for _, ref := range schema.refs {
+			ref.Ref = "#/components/schemas/" + name
+			ref.Value = component
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractResourceComponents(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	generator.AddTransaction(createTestTransaction("POST", "/users",
+		[]byte(`{"name":"__string__","password":"__string__"}`),
+		[]byte(`{"id":"__integer__","name":"__string__","created_at":"__datetime__"}`), 201))
+	generator.AddTransaction(createTestTransaction("GET", "/users/42", nil,
+		[]byte(`{"id":"__integer__","name":"__string__","created_at":"__datetime__"}`), 200))
+	generator.AddTransaction(createTestTransaction("POST", "/login",
+		[]byte(`{"username":"__string__"}`),
+		[]byte(`{"token":"__string__"}`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	component := spec.Components.Schemas["User"]
+	require.NotNil(t, component)
+	props := component.Value.Properties
+	assert.True(t, props["id"].Value.ReadOnly)
+	assert.True(t, props["created_at"].Value.ReadOnly)
+	assert.True(t, props["password"].Value.WriteOnly)
+	assert.False(t, props["name"].Value.ReadOnly)
+	assert.False(t, props["name"].Value.WriteOnly)
+
+	post := spec.Paths.Find("/users").Post
+	require.NotNil(t, post)
+	assert.Equal(t, "#/components/schemas/User", post.RequestBody.Value.Content.Get("application/json").Schema.Ref)
+	assert.Equal(t, "#/components/schemas/User", post.Responses.Value("201").Value.Content.Get("application/json").Schema.Ref)
+
+	get := spec.Paths.Find("/users/{id}").Get
+	require.NotNil(t, get)
+	assert.Equal(t, "#/components/schemas/User", get.Responses.Value("200").Value.Content.Get("application/json").Schema.Ref)
+
+	// Requests and responses without common fields stay inline
+	assert.Len(t, spec.Components.Schemas, 1)
+	login := spec.Paths.Find("/login").Post
+	require.NotNil(t, login)
+	assert.Empty(t, login.RequestBody.Value.Content.Get("application/json").Schema.Ref)
+}
+
+func TestExtractVariantComponents(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	generator.AddTransaction(createTestTransaction("GET", "/payment-methods", nil,
+		[]byte(`[{"type":"card","last4":"__string__"},{"type":"bank_account","iban":"__string__"}]`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	require.Contains(t, spec.Components.Schemas, "Card")
+	require.Contains(t, spec.Components.Schemas, "BankAccount")
+
+	op := spec.Paths.Find("/payment-methods").Get
+	require.NotNil(t, op)
+	items := op.Responses.Value("200").Value.Content.Get("application/json").Schema.Value.Items.Value
+	require.NotNil(t, items.Discriminator)
+	assert.Equal(t, "type", items.Discriminator.PropertyName)
+	assert.Equal(t, "#/components/schemas/Card", items.Discriminator.Mapping["card"])
+	assert.Equal(t, "#/components/schemas/BankAccount", items.Discriminator.Mapping["bank_account"])
+	require.Len(t, items.OneOf, 2)
+	for _, variant := range items.OneOf {
+		assert.NotEmpty(t, variant.Ref)
+	}
+}
+
+func TestExtractSharedComponents(t *testing.T) {
+	address := `{"street":"__string__","city":"__string__","zip":"__string__"}`
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	generator.AddTransaction(createTestTransaction("GET", "/orders", nil,
+		[]byte(`{"total":"__integer__","shipping_address":`+address+`}`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/customers", nil,
+		[]byte(`{"name":"__string__","addresses":[`+address+`],"contact":{"email":"__string__","phone":"__string__"}}`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/suppliers", nil,
+		[]byte(`{"name":"__string__","contact":{"email":"__string__"}}`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	require.Contains(t, spec.Components.Schemas, "Address")
+	assert.Len(t, spec.Components.Schemas["Address"].Value.Properties, 3)
+
+	orders := spec.Paths.Find("/orders").Get.Responses.Value("200").Value.Content.Get("application/json").Schema.Value
+	assert.Equal(t, "#/components/schemas/Address", orders.Properties["shipping_address"].Ref)
+
+	customers := spec.Paths.Find("/customers").Get.Responses.Value("200").Value.Content.Get("application/json").Schema.Value
+	assert.Equal(t, "#/components/schemas/Address", customers.Properties["addresses"].Value.Items.Ref)
+
+	// Objects seen once, or with different properties, stay inline
+	assert.Empty(t, customers.Properties["contact"].Ref)
+	assert.Len(t, spec.Components.Schemas, 1)
+}
+
+func TestResourcePath(t *testing.T) {
+	assert.Equal(t, "/users", resourcePath("/users"))
+	assert.Equal(t, "/users", resourcePath("/users/{userId}"))
+	assert.Equal(t, "/users/{userId}/posts", resourcePath("/users/{userId}/posts/{postId}"))
+	assert.Equal(t, "/", resourcePath("/{id}"))
+}
+
+func TestComponentName(t *testing.T) {
+	existing := openapi3.Schemas{}
+	assert.Equal(t, "User", componentName("/users", existing))
+	assert.Equal(t, "UserProfile", componentName("/user-profiles", existing))
+
+	existing["Post"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema())
+	assert.Equal(t, "UsersPost", componentName("/users/{userId}/posts", existing))
+
+	existing["UsersPost"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema())
+	assert.Equal(t, "UsersPost2", componentName("/users/{userId}/posts", existing))
+}
@@ -0,0 +1,68 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// isJSONContentType checks if a media type carries JSON (or an unknown type assumed to be JSON)
+func isJSONContentType(mediaType string) bool {
+	return mediaType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// nonJSONBodySchema builds a schema for text, CSV, and binary bodies based on their content type.
+// It returns nil for JSON and form bodies, which are parsed as structured data instead.
+func nonJSONBodySchema(mediaType string, body []byte) *parser.Schema {
+	if len(body) == 0 {
+		return nil
+	}
+
+	switch {
+	case mediaType == "text/csv":
+		return &parser.Schema{
+			Type:        "string",
+			Description: describeCSV(body),
+		}
+	case proxy.IsBinaryContentType(mediaType):
+		return &parser.Schema{
+			Type:   "string",
+			Format: "binary",
+		}
+	case strings.HasPrefix(mediaType, "text/"):
+		return &parser.Schema{
+			Type: "string",
+		}
+	}
+
+	return nil
+}
+
+// describeCSV describes the rows of a sanitized CSV body, e.g. "CSV rows with columns: id (integer), name (string)"
+func describeCSV(body []byte) string {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil || len(header) == 0 {
+		return "CSV data"
+	}
+
+	// The sanitized row holds type placeholders for each column
+	row, _ := reader.Read()
+
+	columns := make([]string, len(header))
+	for i, name := range header {
+		columnType := "string"
+		if i < len(row) && strings.HasPrefix(row[i], "__") && strings.HasSuffix(row[i], "__") {
+			columnType = strings.Trim(row[i], "_")
+		}
+		columns[i] = fmt.Sprintf("%s (%s)", name, columnType)
+	}
+
+	return "CSV rows with columns: " + strings.Join(columns, ", ")
+}
@@ -0,0 +1,82 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonJSONBodySchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		mediaType   string
+		body        []byte
+		expectNil   bool
+		format      string
+		description string
+	}{
+		{name: "json", mediaType: "application/json", body: []byte(`{}`), expectNil: true},
+		{name: "empty body", mediaType: "text/plain", body: nil, expectNil: true},
+		{name: "plain text", mediaType: "text/plain", body: []byte("__string__")},
+		{name: "binary", mediaType: "application/pdf", body: []byte("__binary__"), format: "binary"},
+		{
+			name:        "csv",
+			mediaType:   "text/csv",
+			body:        []byte("id,name\n__integer__,__string__\n"),
+			description: "CSV rows with columns: id (integer), name (string)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := nonJSONBodySchema(tt.mediaType, tt.body)
+			if tt.expectNil {
+				assert.Nil(t, schema)
+				return
+			}
+
+			require.NotNil(t, schema)
+			assert.Equal(t, "string", schema.Type)
+			assert.Equal(t, tt.format, schema.Format)
+			assert.Equal(t, tt.description, schema.Description)
+		})
+	}
+}
+
+func TestGenerateSpecNonJSONResponses(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	generator.AddTransaction(proxy.APITransaction{
+		Request: proxy.RequestData{Method: "GET", Path: "/reports/export"},
+		Response: proxy.ResponseData{
+			StatusCode: 200,
+			Headers:    http.Header{"Content-Type": []string{"text/csv; charset=utf-8"}},
+			Body:       []byte("id,total\n__integer__,__number__\n"),
+		},
+	})
+	generator.AddTransaction(proxy.APITransaction{
+		Request: proxy.RequestData{Method: "GET", Path: "/files/logo"},
+		Response: proxy.ResponseData{
+			StatusCode: 200,
+			Headers:    http.Header{"Content-Type": []string{"image/png"}},
+			Body:       []byte("__binary__"),
+		},
+	})
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	csvResponse := spec.Paths.Find("/reports/export").Get.Responses.Value("200")
+	require.NotNil(t, csvResponse)
+	csvMedia := csvResponse.Value.Content.Get("text/csv")
+	require.NotNil(t, csvMedia)
+	assert.Equal(t, "CSV rows with columns: id (integer), total (number)", csvMedia.Schema.Value.Description)
+
+	pngResponse := spec.Paths.Find("/files/logo").Get.Responses.Value("200")
+	require.NotNil(t, pngResponse)
+	pngMedia := pngResponse.Value.Content.Get("image/png")
+	require.NotNil(t, pngMedia)
+	assert.Equal(t, "binary", pngMedia.Schema.Value.Format)
+}
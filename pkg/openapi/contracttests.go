@@ -0,0 +1,602 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ContractTestEnvPrefix prefixes the environment variables holding the values of redacted
+// request headers when contract tests run, e.g. SWAGDOC_AUTHORIZATION
+const ContractTestEnvPrefix = "SWAGDOC_"
+
+// ContractTestTargetEnv overrides the target of contract tests when they run
+const ContractTestTargetEnv = "SWAGDOC_CONTRACT_TARGET"
+
+// replayIgnoredHeaders are captured request headers that are not replayed, because the HTTP
+// client sets them or they only applied to the captured connection
+var replayIgnoredHeaders = map[string]bool{
+	"Accept-Encoding":   true,
+	"Connection":        true,
+	"Content-Length":    true,
+	"Host":              true,
+	"Keep-Alive":        true,
+	"Proxy-Connection":  true,
+	"Te":                true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+	"X-Forwarded-For":   true,
+	"X-Forwarded-Host":  true,
+	"X-Forwarded-Proto": true,
+}
+
+// ContractTestPlan is a set of captured requests to replay against a server, with the status
+// and response schema the documentation expects of each
+type ContractTestPlan struct {
+	Target  string                            `json:"target"` // scheme and host the requests are sent to
+	Tests   []ContractTest                    `json:"tests"`
+	Schemas map[string]map[string]interface{} `json:"$defs,omitempty"` // component schemas, as JSON Schema
+}
+
+// ContractTest is a captured request and what its response has to conform to. Redacted headers
+// are left out of Headers and read from the environment variables in Env when the test runs.
+type ContractTest struct {
+	Name      string                 `json:"name"`      // e.g. GET /users/{id} 200
+	Operation string                 `json:"operation"` // operationId of the documented operation
+	Method    string                 `json:"method"`
+	Path      string                 `json:"path"` // captured path with the query string
+	Headers   map[string]string      `json:"headers,omitempty"`
+	Env       map[string]string      `json:"env,omitempty"` // environment variable by header name
+	Body      string                 `json:"body,omitempty"`
+	Status    int                    `json:"status"`
+	Schema    map[string]interface{} `json:"schema,omitempty"` // JSON Schema of the response body
+}
+
+// NewContractTestPlan turns captured transactions into contract tests of a document: the first
+// transaction of every documented operation and status code is replayed, expecting that status
+// and a response conforming to the documented schema. Server errors are not contracts and
+// transactions of undocumented operations are left out.
+func NewContractTestPlan(doc *OpenAPISpec, transactions []proxy.APITransaction) (ContractTestPlan, error) {
+	plan := ContractTestPlan{Target: contractTarget(doc), Tests: []ContractTest{}}
+	ref := func(name string) string { return "#/$defs/" + name }
+
+	templates, prefixes := documentedPaths(doc)
+	seen := make(map[string]bool)
+	for _, tx := range transactions {
+		if tx.Response.StatusCode < 100 || tx.Response.StatusCode >= 500 {
+			continue
+		}
+		template, ok := matchPathTemplate(tx.Request.Path, templates, prefixes)
+		if !ok {
+			continue
+		}
+		op := operationFor(doc.Paths.Value(template), tx.Request.Method)
+		if op == nil {
+			continue
+		}
+		name := fmt.Sprintf("%s %s %d", tx.Request.Method, template, tx.Response.StatusCode)
+		if seen[name] {
+			continue
+		}
+		body, err := tx.Request.DecodedBody()
+		if err != nil || !utf8.Valid(body) {
+			continue
+		}
+		seen[name] = true
+
+		test := ContractTest{
+			Name:      name,
+			Operation: op.OperationID,
+			Method:    tx.Request.Method,
+			Path:      tx.Request.Path,
+			Body:      string(replayBody(body)),
+			Status:    tx.Response.StatusCode,
+		}
+		if query := replayQuery(tx.Request.QueryParams).Encode(); query != "" {
+			test.Path += "?" + query
+		}
+		test.Headers, test.Env = replayHeaders(tx.Request.Headers)
+
+		if schema := responseSchema(op, tx.Response); schema != nil {
+			if test.Schema, err = jsonSchemaMap(schema, ref); err != nil {
+				return ContractTestPlan{}, err
+			}
+		}
+		plan.Tests = append(plan.Tests, test)
+	}
+
+	if doc.Components != nil && len(plan.Tests) > 0 {
+		for name, schema := range doc.Components.Schemas {
+			if schema == nil || schema.Value == nil {
+				continue
+			}
+			converted, err := jsonSchemaMap(schema.Value, ref)
+			if err != nil {
+				return ContractTestPlan{}, err
+			}
+			if plan.Schemas == nil {
+				plan.Schemas = make(map[string]map[string]interface{})
+			}
+			plan.Schemas[name] = converted
+		}
+	}
+	return plan, nil
+}
+
+// RenderContractTestPlan renders a plan as a JSON test plan for runners in any language
+func RenderContractTestPlan(plan ContractTestPlan) ([]byte, error) {
+	return json.MarshalIndent(plan, "", "  ")
+}
+
+// RenderContractTestsGo renders a plan as a table-driven Go test file of a package. The file
+// only uses the standard library, so it can be dropped into any module and run with go test;
+// SWAGDOC_CONTRACT_TARGET points it at another server than the captured one.
+func RenderContractTestsGo(plan ContractTestPlan, pkg string) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("// Code generated by swagdoc export tests; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString(contractTestImports)
+	fmt.Fprintf(&b, "// defaultTarget is the server the requests were captured from, replaced by $%s\n", ContractTestTargetEnv)
+	fmt.Fprintf(&b, "const defaultTarget = %s\n\n", strconv.Quote(plan.Target))
+
+	b.WriteString("// contractTests are the captured requests, with the status and response schema their responses must have\n")
+	b.WriteString("var contractTests = []contractTest{\n")
+	for _, test := range plan.Tests {
+		b.WriteString("{\n")
+		fmt.Fprintf(&b, "name: %s,\n", strconv.Quote(test.Name))
+		fmt.Fprintf(&b, "method: %s,\n", strconv.Quote(test.Method))
+		fmt.Fprintf(&b, "path: %s,\n", strconv.Quote(test.Path))
+		if len(test.Headers) > 0 || len(test.Env) > 0 {
+			b.WriteString("headers: map[string]string{\n")
+			for _, name := range sortedKeys(test.Headers) {
+				fmt.Fprintf(&b, "%s: %s,\n", strconv.Quote(name), strconv.Quote(test.Headers[name]))
+			}
+			for _, name := range sortedKeys(test.Env) {
+				fmt.Fprintf(&b, "%s: os.Getenv(%s),\n", strconv.Quote(name), strconv.Quote(test.Env[name]))
+			}
+			b.WriteString("},\n")
+		}
+		if test.Body != "" {
+			fmt.Fprintf(&b, "body: %s,\n", strconv.Quote(test.Body))
+		}
+		fmt.Fprintf(&b, "status: %d,\n", test.Status)
+		if test.Schema != nil {
+			schema, err := json.Marshal(test.Schema)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&b, "schema: %s,\n", strconv.Quote(string(schema)))
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString("// schemaDefs are the component schemas the response schemas refer to as #/$defs/<name>\n")
+	b.WriteString("var schemaDefs = map[string]string{\n")
+	names := make([]string, 0, len(plan.Schemas))
+	for name := range plan.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema, err := json.Marshal(plan.Schemas[name])
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "%s: %s,\n", strconv.Quote(name), strconv.Quote(string(schema)))
+	}
+	b.WriteString("}\n")
+	b.WriteString(strings.ReplaceAll(contractTestSupport, "$TARGET_ENV", strconv.Quote(ContractTestTargetEnv)))
+
+	return format.Source([]byte(b.String()))
+}
+
+// contractTarget is the scheme and host of the first server, which the captured paths are
+// relative to
+func contractTarget(doc *OpenAPISpec) string {
+	u, err := url.Parse(serverBaseURL(doc))
+	if err != nil || u.Host == "" {
+		return "http://localhost:8080"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// documentedPaths returns the path templates of a document and the path prefixes of its servers,
+// which the generator may have stripped from the captured paths
+func documentedPaths(doc *OpenAPISpec) ([]string, []string) {
+	var templates []string
+	if doc.Paths != nil {
+		templates = doc.Paths.InMatchingOrder()
+		sort.Strings(templates)
+	}
+	var prefixes []string
+	for _, server := range doc.Servers {
+		baseURL := server.URL
+		for name, variable := range server.Variables {
+			baseURL = strings.ReplaceAll(baseURL, "{"+name+"}", variable.Default)
+		}
+		if u, err := url.Parse(baseURL); err == nil && strings.Trim(u.Path, "/") != "" {
+			prefixes = append(prefixes, "/"+strings.Trim(u.Path, "/"))
+		}
+	}
+	return templates, prefixes
+}
+
+// matchPathTemplate returns the template a captured path is documented by, preferring the one
+// with the most literal segments, e.g. /users/me over /users/{id}
+func matchPathTemplate(path string, templates []string, prefixes []string) (string, bool) {
+	candidates := []string{trimTrailingSlash(path)}
+	for _, prefix := range prefixes {
+		if stripped, ok := strings.CutPrefix(path, prefix); ok && (stripped == "" || strings.HasPrefix(stripped, "/")) {
+			candidates = append(candidates, trimTrailingSlash("/"+strings.TrimPrefix(stripped, "/")))
+		}
+	}
+
+	best, bestScore := "", -1
+	for _, candidate := range candidates {
+		segments := strings.Split(candidate, "/")
+		for _, template := range templates {
+			templateSegments := strings.Split(trimTrailingSlash(template), "/")
+			if len(templateSegments) != len(segments) {
+				continue
+			}
+			score := 0
+			for i, segment := range templateSegments {
+				switch {
+				case segment == segments[i]:
+					score++
+				case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") && segments[i] != "":
+				default:
+					score = -1
+				}
+				if score < 0 {
+					break
+				}
+			}
+			if score > bestScore {
+				best, bestScore = template, score
+			}
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// replayHeaders returns the captured headers to replay, and the environment variables holding
+// the values of the redacted ones
+func replayHeaders(captured http.Header) (map[string]string, map[string]string) {
+	var headers, env map[string]string
+	for name, values := range captured {
+		if replayIgnoredHeaders[name] || len(values) == 0 {
+			continue
+		}
+		value := strings.Join(values, ", ")
+		if strings.Contains(value, "__redacted__") {
+			if env == nil {
+				env = make(map[string]string)
+			}
+			env[name] = ContractTestEnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[name] = value
+	}
+	return headers, env
+}
+
+// replayQuery replaces the placeholders the capture left in query values with sample values of
+// their types, e.g. __integer__ with 0, as the code samples do
+func replayQuery(captured url.Values) url.Values {
+	query := make(url.Values, len(captured))
+	for name, values := range captured {
+		for _, value := range values {
+			query.Add(name, fmt.Sprint(convertPlaceholderString(value)))
+		}
+	}
+	return query
+}
+
+// replayBody replaces the placeholders the capture left in a JSON body with sample values of
+// their types. Other bodies are replayed as captured.
+func replayBody(body []byte) []byte {
+	if !bytes.Contains(body, []byte(`"__`)) {
+		return body
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return body
+	}
+	converted, err := json.Marshal(convertPlaceholders(value))
+	if err != nil {
+		return body
+	}
+	return converted
+}
+
+// responseSchema returns the documented JSON schema of a response, preferring the media type it
+// was captured with
+func responseSchema(op *openapi3.Operation, resp proxy.ResponseData) *openapi3.SchemaRef {
+	if op.Responses == nil {
+		return nil
+	}
+	response := op.Responses.Status(resp.StatusCode)
+	if response == nil {
+		response = op.Responses.Default()
+	}
+	if response == nil || response.Value == nil {
+		return nil
+	}
+
+	captured := strings.TrimSpace(strings.Split(resp.Headers.Get("Content-Type"), ";")[0])
+	if media := response.Value.Content[captured]; media != nil && isJSONContentType(captured) {
+		return media.Schema
+	}
+	for _, mediaType := range sortedMediaTypes(response.Value.Content) {
+		if isJSONContentType(mediaType) {
+			return response.Value.Content[mediaType].Schema
+		}
+	}
+	return nil
+}
+
+// jsonSchemaMap converts an OpenAPI schema to JSON Schema keywords, with component references
+// rewritten by ref
+func jsonSchemaMap(schema interface{}, ref func(name string) string) (map[string]interface{}, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var converted map[string]interface{}
+	if err := json.Unmarshal(data, &converted); err != nil {
+		return nil, err
+	}
+	convertSchema(converted, ref)
+	return converted, nil
+}
+
+// sortedKeys returns the keys of a string map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// contractTestImports are the imports of generated Go contract tests
+const contractTestImports = `import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+`
+
+// contractTestSupport is the test function of generated Go contract tests, with a validator of
+// the JSON Schema keywords generated documents use
+const contractTestSupport = `
+type contractTest struct {
+	name    string
+	method  string
+	path    string
+	headers map[string]string
+	body    string
+	status  int
+	schema  string
+}
+
+func TestContract(t *testing.T) {
+	target := strings.TrimRight(os.Getenv($TARGET_ENV), "/")
+	if target == "" {
+		target = defaultTarget
+	}
+	defs := make(map[string]interface{})
+	for name, schema := range schemaDefs {
+		defs[name] = decodeSchema(t, schema)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	for _, tc := range contractTests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var body io.Reader
+			if tc.body != "" {
+				body = strings.NewReader(tc.body)
+			}
+			req, err := http.NewRequest(tc.method, target+tc.path, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range tc.headers {
+				if value != "" {
+					req.Header.Set(name, value)
+				}
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("%s %s failed: %v", tc.method, tc.path, err)
+			}
+			defer resp.Body.Close()
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("failed to read the response: %v", err)
+			}
+			if resp.StatusCode != tc.status {
+				t.Errorf("expected status %d, got %d: %s", tc.status, resp.StatusCode, data)
+				return
+			}
+			if tc.schema == "" {
+				return
+			}
+
+			var value interface{}
+			if err := json.Unmarshal(data, &value); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			for _, problem := range checkSchema(decodeSchema(t, tc.schema), value, "body", defs) {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+func decodeSchema(t *testing.T, schema string) map[string]interface{} {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &decoded); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	return decoded
+}
+
+// checkSchema returns how a JSON value doesn't conform to a schema
+func checkSchema(schema map[string]interface{}, value interface{}, at string, defs map[string]interface{}) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown schema %s", at, ref)}
+		}
+		return checkSchema(def, value, at, defs)
+	}
+
+	var problems []string
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !hasType(types, value) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", at, strings.Join(types, " or "), jsonType(value))}
+	}
+	if values, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range values {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s: %v is not one of %v", at, value, values))
+		}
+	}
+	for _, sub := range schemaList(schema["allOf"]) {
+		problems = append(problems, checkSchema(sub, value, at, defs)...)
+	}
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		variants := schemaList(schema[keyword])
+		if len(variants) == 0 {
+			continue
+		}
+		matched := false
+		for _, sub := range variants {
+			if len(checkSchema(sub, value, at, defs)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			problems = append(problems, fmt.Sprintf("%s: matches none of the %s schemas", at, keyword))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schemaStrings(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required property %s", at, name))
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, property := range v {
+			if sub, ok := properties[name].(map[string]interface{}); ok {
+				problems = append(problems, checkSchema(sub, property, at+"."+name, defs)...)
+			} else if sub, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+				problems = append(problems, checkSchema(sub, property, at+"."+name, defs)...)
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				problems = append(problems, checkSchema(items, item, fmt.Sprintf("%s[%d]", at, i), defs)...)
+			}
+		}
+	}
+	return problems
+}
+
+func schemaTypes(value interface{}) []string {
+	if t, ok := value.(string); ok {
+		return []string{t}
+	}
+	return schemaStrings(value)
+}
+
+func schemaStrings(value interface{}) []string {
+	var result []string
+	list, _ := value.([]interface{})
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func schemaList(value interface{}) []map[string]interface{} {
+	var result []map[string]interface{}
+	list, _ := value.([]interface{})
+	for _, item := range list {
+		if sub, ok := item.(map[string]interface{}); ok {
+			result = append(result, sub)
+		}
+	}
+	return result
+}
+
+func hasType(types []string, value interface{}) bool {
+	actual := jsonType(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+`
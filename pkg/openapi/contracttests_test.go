@@ -0,0 +1,233 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contractTestTransactions are the captures of a small user API below /api
+func contractTestTransactions() []proxy.APITransaction {
+	create := createTestTransaction("POST", "/api/users", []byte(`{"name":"Ada"}`), []byte(`{"id":1,"name":"Ada"}`), 201)
+	create.Request.Headers.Set("Authorization", "__redacted__")
+	create.Request.Headers.Set("Content-Length", "14")
+	search := createTestTransaction("GET", "/api/users", nil, []byte(`[{"id":1,"name":"Ada"}]`), 200)
+	search.Request.QueryParams = map[string][]string{"name": {"Ada"}}
+	return []proxy.APITransaction{
+		create,
+		search,
+		createTestTransaction("GET", "/api/users/1", nil, []byte(`{"id":1,"name":"Ada"}`), 200),
+		createTestTransaction("GET", "/api/users/2", nil, []byte(`{"id":2,"name":"Grace"}`), 200),
+		createTestTransaction("GET", "/api/users/3", nil, []byte(`{"error":"not found"}`), 404),
+		createTestTransaction("GET", "/api/users/4", nil, []byte(`{"error":"boom"}`), 500),
+		createTestTransaction("GET", "/undocumented", nil, nil, 200),
+	}
+}
+
+func contractTestDoc(t *testing.T, transactions []proxy.APITransaction) *OpenAPISpec {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:         "Users API",
+		Version:       "1.0.0",
+		Servers:       []OpenAPIServer{{URL: "https://api.example.com"}},
+		StripPrefixes: []string{"/api"},
+	})
+	for _, tx := range transactions[:len(transactions)-1] {
+		generator.AddTransaction(tx)
+	}
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+	return spec
+}
+
+func TestNewContractTestPlan(t *testing.T) {
+	transactions := contractTestTransactions()
+	plan, err := NewContractTestPlan(contractTestDoc(t, transactions), transactions)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://api.example.com", plan.Target)
+	names := make([]string, len(plan.Tests))
+	for i, test := range plan.Tests {
+		names[i] = test.Name
+	}
+	// One test per operation and status, without server errors and undocumented paths
+	assert.Equal(t, []string{
+		"POST /users 201",
+		"GET /users 200",
+		"GET /users/{userId} 200",
+		"GET /users/{userId} 404",
+	}, names)
+
+	create := plan.Tests[0]
+	assert.Equal(t, "/api/users", create.Path)
+	assert.Equal(t, `{"name":"Ada"}`, create.Body)
+	assert.Equal(t, map[string]string{"Content-Type": "application/json"}, create.Headers)
+	assert.Equal(t, map[string]string{"Authorization": "SWAGDOC_AUTHORIZATION"}, create.Env)
+	assert.NotEmpty(t, create.Operation)
+	require.NotNil(t, create.Schema)
+
+	assert.Equal(t, "/api/users?name=Ada", plan.Tests[1].Path)
+	assert.Equal(t, "/api/users/1", plan.Tests[2].Path)
+
+	// Component references point into $defs
+	data, err := RenderContractTestPlan(plan)
+	require.NoError(t, err)
+	var decoded ContractTestPlan
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Len(t, decoded.Tests, len(plan.Tests))
+	for _, test := range plan.Tests {
+		if ref, ok := test.Schema["$ref"].(string); ok {
+			assert.Contains(t, plan.Schemas, strings.TrimPrefix(ref, "#/$defs/"))
+		}
+	}
+	assert.NotContains(t, string(data), "#/components/schemas/")
+}
+
+// usersTestHandler serves a users API that, like a real server, rejects query values and bodies
+// of the wrong types
+func usersTestHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users", func(w http.ResponseWriter, r *http.Request) {
+		for _, name := range []string{"limit", "page"} {
+			if _, err := strconv.Atoi(r.URL.Query().Get(name)); err != nil {
+				http.Error(w, `{"error":"invalid `+name+`"}`, http.StatusBadRequest)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"id":1,"email":"ada@example.com","name":"Ada"}]`)
+	})
+	mux.HandleFunc("POST /api/v1/users", func(w http.ResponseWriter, r *http.Request) {
+		var user struct {
+			Email string `json:"email"`
+			Name  string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&user); err != nil || user.Email == "" {
+			http.Error(w, `{"error":"invalid user"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":2,"email":%q,"name":%q}`, user.Email, user.Name)
+	})
+	return mux
+}
+
+func TestNewContractTestPlanFromCaptures(t *testing.T) {
+	list, err := http.NewRequest(http.MethodGet, "/api/v1/users?limit=10&page=2", nil)
+	require.NoError(t, err)
+	create, err := http.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(`{"email":"ada@example.com","name":"Ada"}`))
+	require.NoError(t, err)
+	create.Header.Set("Content-Type", "application/json")
+
+	handler := usersTestHandler()
+	transactions := captureTestTransactions(t, handler, list, create)
+	require.Len(t, transactions, 2)
+	doc, _ := generateTestSpec(t, OpenAPIConfig{Servers: []OpenAPIServer{{URL: "https://api.example.com"}}}, transactions...)
+	plan, err := NewContractTestPlan(doc, transactions)
+	require.NoError(t, err)
+	require.Len(t, plan.Tests, 2)
+
+	// The capture's placeholders are replayed as sample values of their types
+	assert.Equal(t, "/api/v1/users?limit=0&page=0", plan.Tests[0].Path)
+	assert.Equal(t, `{"email":"string","name":"string"}`, plan.Tests[1].Body)
+
+	// and the server accepts them
+	for _, test := range plan.Tests {
+		req := httptest.NewRequest(test.Method, test.Path, strings.NewReader(test.Body))
+		for name, value := range test.Headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, test.Status, rec.Code, test.Name)
+	}
+}
+
+func TestRenderContractTestsGo(t *testing.T) {
+	transactions := contractTestTransactions()
+	plan, err := NewContractTestPlan(contractTestDoc(t, transactions), transactions)
+	require.NoError(t, err)
+
+	source, err := RenderContractTestsGo(plan, "contract")
+	require.NoError(t, err)
+	file, err := parser.ParseFile(token.NewFileSet(), "contract_test.go", source, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "contract", file.Name.Name)
+	assert.Contains(t, string(source), `"Authorization": os.Getenv("SWAGDOC_AUTHORIZATION")`)
+	assert.Contains(t, string(source), `const defaultTarget = "https://api.example.com"`)
+}
+
+func TestRenderContractTestsGoRuns(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if testing.Short() || err != nil {
+		t.Skip("runs the generated tests with the go tool")
+	}
+
+	transactions := contractTestTransactions()
+	plan, err := NewContractTestPlan(contractTestDoc(t, transactions), transactions)
+	require.NoError(t, err)
+	source, err := RenderContractTestsGo(plan, "contract")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module contract\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "contract_test.go"), source, 0644))
+
+	run := func(handler http.HandlerFunc) (string, error) {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		cmd := exec.Command(goTool, "test", "-count=1", ".")
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), ContractTestTargetEnv+"="+server.URL, "SWAGDOC_AUTHORIZATION=Bearer token")
+		output, err := cmd.CombinedOutput()
+		return string(output), err
+	}
+
+	// A server honoring the contract passes
+	var authorization string
+	output, err := run(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST":
+			authorization = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":7,"name":"Ada"}`))
+		case r.URL.Path == "/api/users":
+			w.Write([]byte(`[{"id":1,"name":"Ada"},{"id":2,"name":"Grace"}]`))
+		case r.URL.Path == "/api/users/3":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+		default:
+			w.Write([]byte(`{"id":1,"name":"Ada"}`))
+		}
+	})
+	require.NoError(t, err, output)
+	assert.Equal(t, "Bearer token", authorization)
+
+	// A server breaking it fails with the differences
+	output, err = run(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusOK)
+		}
+		w.Write([]byte(`{"id":"seven"}`))
+	})
+	require.Error(t, err)
+	assert.Contains(t, output, "expected status 201, got 200")
+	assert.Contains(t, output, "body.id: expected integer, got string")
+	assert.Contains(t, output, "body: expected array, got object")
+}
@@ -0,0 +1,40 @@
+package openapi
+
+import (
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// applyDeprecation marks an operation whose responses signaled a deprecation as deprecated,
+// with the sunset date in x-sunset and the deprecation notice in the description
+func applyDeprecation(op *openapi3.Operation, info *parser.DeprecationInfo) {
+	if op == nil || info == nil {
+		return
+	}
+
+	op.Deprecated = true
+	if info.Sunset != "" {
+		setOperationExtension(op, "x-sunset", info.Sunset)
+	}
+	if info.Since != "" {
+		setOperationExtension(op, "x-deprecated-since", info.Since)
+	}
+	if info.Warning != "" {
+		op.Description += "\n\nDeprecated: " + info.Warning
+	}
+	if info.Link != "" && op.ExternalDocs == nil {
+		op.ExternalDocs = &openapi3.ExternalDocs{
+			Description: "Deprecation notice",
+			URL:         info.Link,
+		}
+	}
+}
+
+// setOperationExtension sets a vendor extension on an operation
+func setOperationExtension(op *openapi3.Operation, key string, value interface{}) {
+	if op.Extensions == nil {
+		op.Extensions = make(map[string]interface{})
+	}
+	op.Extensions[key] = value
+}
@@ -0,0 +1,33 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecDeprecation(t *testing.T) {
+	legacy := createTestTransaction("GET", "/v1/users", nil, []byte(`{"name":"__string__"}`), 200)
+	legacy.Response.Headers.Set("Deprecation", "true")
+	legacy.Response.Headers.Set("Warning", `299 - "Use /v2/users instead"`)
+	sunset := createTestTransaction("GET", "/v1/users", nil, []byte(`{"name":"__string__"}`), 200)
+	sunset.Response.Headers.Set("Sunset", "Wed, 11 Nov 2026 23:59:59 GMT")
+
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	generator.AddTransaction(legacy)
+	generator.AddTransaction(sunset)
+	generator.AddTransaction(createTestTransaction("GET", "/v2/users", nil, []byte(`{"name":"__string__"}`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	op := spec.Paths.Find("/v1/users").Get
+	assert.True(t, op.Deprecated)
+	assert.Equal(t, "2026-11-11T23:59:59Z", op.Extensions["x-sunset"])
+	assert.Contains(t, op.Description, "Use /v2/users instead")
+
+	current := spec.Paths.Find("/v2/users").Get
+	assert.False(t, current.Deprecated)
+	assert.Nil(t, current.Extensions["x-sunset"])
+}
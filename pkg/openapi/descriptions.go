@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIOperationDoc is human-written prose for an operation that replaces the generated
+// summary and description, and optionally its tag
+type OpenAPIOperationDoc struct {
+	Summary     string `yaml:"summary,omitempty" json:"summary,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Tag         string `yaml:"tag,omitempty" json:"tag,omitempty"`
+}
+
+// LoadOperationDocs reads a descriptions file (YAML or JSON) mapping "METHOD /templated/path"
+// to the prose of the operation, so that it can be versioned separately from the generated
+// document:
+//
+//	GET /users/{userId}:
+//	  summary: Get a user
+//	  description: Returns the profile of a user, including their roles.
+//	  tag: Identity
+func LoadOperationDocs(path string) (map[string]OpenAPIOperationDoc, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptions file %s: %v", path, err)
+	}
+
+	var file map[string]OpenAPIOperationDoc
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptions file %s: %v", path, err)
+	}
+
+	docs := make(map[string]OpenAPIOperationDoc, len(file))
+	for key, doc := range file {
+		endpoint, err := operationDocKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("descriptions file %s: %v", path, err)
+		}
+		docs[endpoint] = doc
+	}
+	return docs, nil
+}
+
+// operationDocKey normalizes a "METHOD /path" key: the method is upper-cased and trailing
+// slashes are removed from the path, as they are from captured paths
+func operationDocKey(key string) (string, error) {
+	method, path, ok := strings.Cut(strings.TrimSpace(key), " ")
+	path = strings.TrimSpace(path)
+	if !ok || !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("invalid endpoint %q, expected 'METHOD /path'", key)
+	}
+	method = strings.ToUpper(method)
+	if !containsMethod(operationMethods, method) {
+		return "", fmt.Errorf("invalid endpoint %q, unknown method %s", key, method)
+	}
+	return method + " " + trimTrailingSlash(path), nil
+}
+
+// annotateOperationDoc overrides the prose of an operation with the summary, description, and
+// tag annotations of a request, sent as X-SwagDoc-Summary, X-SwagDoc-Description, and
+// X-SwagDoc-Tag headers
+func annotateOperationDoc(operationDoc OpenAPIOperationDoc, annotations map[string]string) OpenAPIOperationDoc {
+	if summary := annotations["summary"]; summary != "" {
+		operationDoc.Summary = summary
+	}
+	if description := annotations["description"]; description != "" {
+		operationDoc.Description = description
+	}
+	if tag := annotations["tag"]; tag != "" {
+		operationDoc.Tag = tag
+	}
+	return operationDoc
+}
+
+// applyOperationDocs replaces the summary, description, and tag of the operations listed in the
+// descriptions
+func applyOperationDocs(doc *OpenAPISpec, docs map[string]OpenAPIOperationDoc) {
+	for key, operationDoc := range docs {
+		op := operationForKey(doc, key)
+		if op == nil {
+			continue
+		}
+		if operationDoc.Summary != "" {
+			op.Summary = operationDoc.Summary
+		}
+		if operationDoc.Description != "" {
+			op.Description = operationDoc.Description
+		}
+		if operationDoc.Tag != "" {
+			op.Tags = []string{operationDoc.Tag}
+		}
+	}
+}
+
+// UnusedOperationDocs returns the endpoints of a descriptions file that match no operation of
+// a document, e.g. because the endpoint was renamed or not captured
+func UnusedOperationDocs(spec *OpenAPISpec, docs map[string]OpenAPIOperationDoc) []string {
+	var unused []string
+	for key := range docs {
+		if operationForKey(spec, key) == nil {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// operationForKey returns the operation of a "METHOD /path" key, or nil
+func operationForKey(doc *OpenAPISpec, key string) *openapi3.Operation {
+	method, path, _ := strings.Cut(key, " ")
+	if doc.Paths == nil {
+		return nil
+	}
+	pathItem := doc.Paths.Value(path)
+	if pathItem == nil {
+		return nil
+	}
+	return operationFor(pathItem, method)
+}
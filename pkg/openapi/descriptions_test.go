@@ -0,0 +1,110 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecOperationDocs(t *testing.T) {
+	docs := map[string]OpenAPIOperationDoc{
+		"GET /users/{userId}": {Summary: "Get a user", Description: "Returns the profile of a user.", Tag: "Identity"},
+		"DELETE /users":       {Summary: "Delete all users"},
+	}
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:         "Test API",
+		Version:       "1.0.0",
+		OperationDocs: docs,
+	})
+	generator.AddTransaction(createTestTransaction("GET", "/users/123", nil, []byte(`{"id":"__integer__"}`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/users/456", nil, []byte(`{"id":"__integer__"}`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, []byte(`[{"id":"__integer__"}]`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	getUser := spec.Paths.Find("/users/{userId}").Get
+	assert.Equal(t, "Get a user", getUser.Summary)
+	assert.Equal(t, "Returns the profile of a user.", getUser.Description)
+	assert.Equal(t, []string{"Identity"}, getUser.Tags)
+	var tags []string
+	for _, tag := range spec.Tags {
+		tags = append(tags, tag.Name)
+	}
+	assert.Contains(t, tags, "Identity")
+
+	// Operations without an entry keep the generated prose
+	listUsers := spec.Paths.Find("/users").Get
+	assert.NotEmpty(t, listUsers.Summary)
+	assert.NotEqual(t, []string{"Identity"}, listUsers.Tags)
+
+	assert.Equal(t, []string{"DELETE /users"}, UnusedOperationDocs(spec, docs))
+}
+
+func TestGenerateSpecAnnotations(t *testing.T) {
+	annotated := func(tx proxy.APITransaction, annotations map[string]string) proxy.APITransaction {
+		tx.Request.Annotations = annotations
+		return tx
+	}
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:         "Test API",
+		Version:       "1.0.0",
+		OperationDocs: map[string]OpenAPIOperationDoc{"POST /users": {Summary: "Create a user from the descriptions file"}},
+	})
+	generator.AddTransaction(annotated(createTestTransaction("GET", "/users/123", nil, []byte(`{"id":"__integer__"}`), 200),
+		map[string]string{"summary": "Get a user", "tag": "Identity"}))
+	generator.AddTransaction(annotated(createTestTransaction("GET", "/users/456", nil, []byte(`{"id":"__integer__"}`), 200),
+		map[string]string{"description": "Returns the profile of a user."}))
+	generator.AddTransaction(annotated(createTestTransaction("POST", "/users", []byte(`{"name":"__string__"}`), []byte(`{"id":"__integer__"}`), 201),
+		map[string]string{"summary": "Create a user", "tag": "Identity"}))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	// Annotations of every request to an endpoint are combined
+	getUser := spec.Paths.Find("/users/{userId}").Get
+	assert.Equal(t, "Get a user", getUser.Summary)
+	assert.Equal(t, "Returns the profile of a user.", getUser.Description)
+	assert.Equal(t, []string{"Identity"}, getUser.Tags)
+
+	// The descriptions file wins over annotations
+	createUser := spec.Paths.Find("/users").Post
+	assert.Equal(t, "Create a user from the descriptions file", createUser.Summary)
+	assert.Equal(t, []string{"Identity"}, createUser.Tags)
+}
+
+func TestLoadOperationDocs(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "descriptions.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("get /users/{userId}/:\n  summary: Get a user\n  tag: Identity\n"), 0644))
+	docs, err := LoadOperationDocs(valid)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]OpenAPIOperationDoc{
+		"GET /users/{userId}": {Summary: "Get a user", Tag: "Identity"},
+	}, docs)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "missing path", content: "GET:\n  summary: Get a user\n"},
+		{name: "relative path", content: "GET users:\n  summary: Get a user\n"},
+		{name: "unknown method", content: "FETCH /users:\n  summary: Get a user\n"},
+		{name: "invalid yaml", content: "GET /users: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "invalid.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+			_, err := LoadOperationDocs(path)
+			assert.Error(t, err)
+		})
+	}
+}
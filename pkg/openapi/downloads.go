@@ -0,0 +1,152 @@
+package openapi
+
+import (
+	"mime"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// digitRuns matches the numbers in download file names, e.g. the dates and ids of invoice-2024-17.pdf
+var digitRuns = regexp.MustCompile(`[0-9]+`)
+
+// fileDownload holds the content types and file names of the file responses of one status code
+type fileDownload struct {
+	mediaTypes map[string]bool
+	filenames  []string
+}
+
+// downloadFilename returns the file name of an attachment response and whether the response is
+// a file download: a Content-Disposition attachment or a binary content type
+func downloadFilename(headers map[string][]string, mediaType string) (string, bool) {
+	disposition, params, err := mime.ParseMediaType(firstHeader(headers, "Content-Disposition"))
+	if err == nil && disposition == "attachment" {
+		return params["filename"], true
+	}
+	return "", proxy.IsBinaryContentType(mediaType)
+}
+
+// firstHeader returns the first value of a canonical header name
+func firstHeader(headers map[string][]string, name string) string {
+	if values := headers[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// firstFilename returns the first non-empty file name
+func firstFilename(filenames []string) string {
+	for _, name := range filenames {
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// filenamePattern generalizes the observed file names of a download into a pattern with *
+// wildcards, e.g. invoice-*-*.pdf for invoice-2024-17.pdf and invoice-2025-3.pdf
+func filenamePattern(filenames []string) string {
+	var patterns []string
+	for _, name := range filenames {
+		if name == "" {
+			continue
+		}
+		pattern := digitRuns.ReplaceAllString(name, "*")
+		if len(patterns) == 0 || patterns[0] != pattern {
+			patterns = append(patterns, pattern)
+		}
+	}
+	switch len(patterns) {
+	case 0:
+		return ""
+	case 1:
+		if len(filenames) == 1 {
+			return filenames[0]
+		}
+		return patterns[0]
+	}
+
+	// Keep the words every name has in common around a wildcard, e.g. report-*.csv
+	prefix, suffix := patterns[0], patterns[0]
+	for _, pattern := range patterns[1:] {
+		for !strings.HasPrefix(pattern, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+		for !strings.HasSuffix(pattern, suffix) {
+			suffix = suffix[1:]
+		}
+	}
+	prefix = prefix[:strings.LastIndexAny(prefix, filenameSeparators)+1]
+	if i := strings.IndexAny(suffix, filenameSeparators); i >= 0 {
+		suffix = suffix[i:]
+	} else {
+		suffix = ""
+	}
+	if len(prefix)+len(suffix) > len(patterns[0]) {
+		suffix = ""
+	}
+	return prefix + "*" + suffix
+}
+
+// filenameSeparators separate the words of file names
+const filenameSeparators = ".-_ "
+
+// addFileDownloads documents file download responses as binary content of every observed content
+// type, with the Content-Disposition header and the file names as an x-filename-pattern extension.
+// downloads maps "METHOD /templated/path" to the downloads of every status code.
+func addFileDownloads(doc *OpenAPISpec, downloads map[string]map[string]*fileDownload) {
+	for key, statuses := range downloads {
+		op := operationForKey(doc, key)
+		if op == nil || op.Responses == nil {
+			continue
+		}
+		for code, download := range statuses {
+			response := op.Responses.Value(code)
+			if response == nil || response.Value == nil {
+				continue
+			}
+
+			mediaTypes := make([]string, 0, len(download.mediaTypes))
+			for mediaType := range download.mediaTypes {
+				mediaTypes = append(mediaTypes, mediaType)
+			}
+			sort.Strings(mediaTypes)
+			if len(mediaTypes) == 0 {
+				mediaTypes = []string{"application/octet-stream"}
+			}
+
+			response.Value.Content = openapi3.Content{}
+			for _, mediaType := range mediaTypes {
+				schema := openapi3.NewStringSchema()
+				schema.Format = "binary"
+				response.Value.Content[mediaType] = openapi3.NewMediaType().WithSchema(schema)
+			}
+
+			pattern := filenamePattern(download.filenames)
+			if pattern == "" {
+				continue
+			}
+			if response.Value.Extensions == nil {
+				response.Value.Extensions = make(map[string]interface{})
+			}
+			response.Value.Extensions["x-filename-pattern"] = pattern
+			if response.Value.Headers == nil {
+				response.Value.Headers = openapi3.Headers{}
+			}
+			response.Value.Headers["Content-Disposition"] = &openapi3.HeaderRef{
+				Value: &openapi3.Header{
+					Parameter: openapi3.Parameter{
+						Description: "Downloads the content as a file named like " + pattern,
+						Schema:      openapi3.NewStringSchema().NewRef(),
+						Example:     `attachment; filename="` + firstFilename(download.filenames) + `"`,
+					},
+				},
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilenamePattern(t *testing.T) {
+	tests := []struct {
+		name      string
+		filenames []string
+		expected  string
+	}{
+		{name: "single file", filenames: []string{"report.csv"}, expected: "report.csv"},
+		{name: "numbered files", filenames: []string{"invoice-2024-17.pdf", "invoice-2025-3.pdf"}, expected: "invoice-*-*.pdf"},
+		{name: "named files", filenames: []string{"report-users.csv", "report-orders.csv"}, expected: "report-*.csv"},
+		{name: "different extensions", filenames: []string{"export.csv", "export.xlsx"}, expected: "export.*"},
+		{name: "no names", filenames: []string{"", ""}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, filenamePattern(tt.filenames))
+		})
+	}
+}
+
+func TestGenerateSpecFileDownloads(t *testing.T) {
+	first := createTestTransaction("GET", "/invoices/1/pdf", nil, nil, 200)
+	first.Response.Headers.Set("Content-Type", "application/pdf")
+	first.Response.Headers.Set("Content-Disposition", `attachment; filename="invoice-1.pdf"`)
+	second := createTestTransaction("GET", "/invoices/2/pdf", nil, nil, 200)
+	second.Response.Headers.Set("Content-Type", "application/pdf")
+	second.Response.Headers.Set("Content-Disposition", `attachment; filename="invoice-2.pdf"`)
+
+	// A CSV attachment is a download too
+	export := createTestTransaction("GET", "/exports/users", nil, []byte("id,name\n__integer__,__string__\n"), 200)
+	export.Response.Headers.Set("Content-Type", "text/csv")
+	export.Response.Headers.Set("Content-Disposition", "attachment; filename=users.csv")
+
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Files API", Version: "1.0.0"})
+	generator.AddTransaction(first)
+	generator.AddTransaction(second)
+	generator.AddTransaction(export)
+	generator.AddTransaction(createTestTransaction("GET", "/invoices", nil, []byte(`[]`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	response := spec.Paths.Find("/invoices/{invoiceId}/pdf").Get.Responses.Value("200").Value
+	require.Contains(t, response.Content, "application/pdf")
+	assert.Equal(t, "binary", response.Content["application/pdf"].Schema.Value.Format)
+	assert.Equal(t, "invoice-*.pdf", response.Extensions["x-filename-pattern"])
+	require.Contains(t, response.Headers, "Content-Disposition")
+	assert.Equal(t, `attachment; filename="invoice-1.pdf"`, response.Headers["Content-Disposition"].Value.Example)
+
+	response = spec.Paths.Find("/exports/users").Get.Responses.Value("200").Value
+	require.Contains(t, response.Content, "text/csv")
+	assert.Equal(t, "binary", response.Content["text/csv"].Schema.Value.Format)
+	assert.Equal(t, "users.csv", response.Extensions["x-filename-pattern"])
+
+	assert.NotContains(t, spec.Paths.Find("/invoices").Get.Responses.Value("200").Value.Extensions, "x-filename-pattern")
+	require.NoError(t, ValidateSpec(spec))
+}
@@ -0,0 +1,51 @@
+package openapi
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecBodyEncodings(t *testing.T) {
+	// An object stored as base64 text, which does not start with 'W' like a base64 array does
+	legacy := createTestTransaction("GET", "/users/1", nil, nil, 200)
+	legacy.Response.Body = []byte(base64.StdEncoding.EncodeToString([]byte(`{"id":"__integer__","name":"__string__"}`)))
+
+	encoded := createTestTransaction("POST", "/users", nil, nil, 201)
+	encoded.Request.Body = []byte(base64.StdEncoding.EncodeToString([]byte(`{"name":"__string__"}`)))
+	encoded.Request.BodyEncoding = proxy.BodyEncodingBase64
+	encoded.Response.Body = []byte(`{"id":"__integer__"}`)
+	encoded.Response.BodyEncoding = proxy.BodyEncodingSanitizedJSON
+
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(legacy)
+	generator.AddTransaction(encoded)
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	get := spec.Paths.Find("/users/{userId}")
+	require.NotNil(t, get)
+	schema := get.Get.Responses.Value("200").Value.Content.Get("application/json").Schema
+	require.NotNil(t, schema)
+	assert.Contains(t, resolveSchema(spec, schema).Properties, "name")
+
+	post := spec.Paths.Find("/users").Post
+	require.NotNil(t, post.RequestBody)
+	requestSchema := post.RequestBody.Value.Content.Get("application/json").Schema
+	assert.Contains(t, resolveSchema(spec, requestSchema).Properties, "name")
+}
+
+// resolveSchema follows a component reference
+func resolveSchema(spec *OpenAPISpec, ref *openapi3.SchemaRef) *openapi3.Schema {
+	if ref.Ref != "" {
+		return spec.Components.Schemas[strings.TrimPrefix(ref.Ref, "#/components/schemas/")].Value
+	}
+	return ref.Value
+}
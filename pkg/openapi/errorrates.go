@@ -0,0 +1,108 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// errorRateExtension is the operation extension holding how often the captured requests failed
+const errorRateExtension = "x-observed-error-rate"
+
+// defaultErrorRateThreshold is the error rate above which endpoints are reported as flaky
+const defaultErrorRateThreshold = 0.05
+
+// requestOutcomes counts the captured requests by method and request path
+type requestOutcomes struct {
+	requests     int
+	serverErrors int // 5xx responses, the failures an API owner is accountable for
+	clientErrors int // 4xx responses
+}
+
+// requestErrors returns the outcomes of transactions by method and request path. It runs before
+// the response strategy, so that failed requests count even when a success is documented.
+func requestErrors(transactions []proxy.APITransaction) map[string]*requestOutcomes {
+	outcomes := make(map[string]*requestOutcomes)
+	for _, tx := range transactions {
+		key := tx.Request.Method + " " + tx.Request.Path
+		outcome, ok := outcomes[key]
+		if !ok {
+			outcome = &requestOutcomes{}
+			outcomes[key] = outcome
+		}
+		outcome.requests++
+		switch {
+		case tx.Response.StatusCode >= 500:
+			outcome.serverErrors++
+		case tx.Response.StatusCode >= 400:
+			outcome.clientErrors++
+		}
+	}
+	return outcomes
+}
+
+// addErrorRates sets x-observed-error-rate on operations, with the number of captured requests,
+// their 5xx and 4xx responses, and the share of 5xx responses. Operations failing more often than
+// ErrorRateThreshold are reported in the generation stats, most failing first. The request paths
+// are matched against the documented paths, so that the requests the response strategy left out
+// count too.
+func (g *OpenAPIGenerator) addErrorRates(doc *OpenAPISpec, outcomes map[string]*requestOutcomes) {
+	templates, prefixes := documentedPaths(doc)
+	byOperation := make(map[string]*requestOutcomes)
+	for key, outcome := range outcomes {
+		method, path, _ := strings.Cut(key, " ")
+		template, ok := matchPathTemplate(path, templates, prefixes)
+		if !ok {
+			continue
+		}
+		operation, ok := byOperation[method+" "+template]
+		if !ok {
+			operation = &requestOutcomes{}
+			byOperation[method+" "+template] = operation
+		}
+		operation.requests += outcome.requests
+		operation.serverErrors += outcome.serverErrors
+		operation.clientErrors += outcome.clientErrors
+	}
+
+	threshold := g.config.ErrorRateThreshold
+	if threshold <= 0 {
+		threshold = defaultErrorRateThreshold
+	}
+	rates := make(map[string]float64)
+	for key, outcome := range byOperation {
+		op := operationForKey(doc, key)
+		if op == nil {
+			continue
+		}
+		rate := math.Round(float64(outcome.serverErrors)/float64(outcome.requests)*10000) / 10000
+		setOperationExtension(op, errorRateExtension, map[string]interface{}{
+			"requests":      outcome.requests,
+			"server_errors": outcome.serverErrors,
+			"client_errors": outcome.clientErrors,
+			"rate":          rate,
+		})
+		if rate > threshold {
+			rates[key] = rate
+		}
+	}
+
+	flaky := make([]string, 0, len(rates))
+	for key := range rates {
+		flaky = append(flaky, key)
+	}
+	sort.Slice(flaky, func(i, j int) bool {
+		if rates[flaky[i]] != rates[flaky[j]] {
+			return rates[flaky[i]] > rates[flaky[j]]
+		}
+		return flaky[i] < flaky[j]
+	})
+	for _, key := range flaky {
+		outcome := byOperation[key]
+		g.stats.FlakyEndpoints = append(g.stats.FlakyEndpoints, fmt.Sprintf("%s failed %d of %d requests (%.1f%%)",
+			key, outcome.serverErrors, outcome.requests, rates[key]*100))
+	}
+}
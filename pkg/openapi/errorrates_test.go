@@ -0,0 +1,82 @@
+package openapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorRates(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:            "Test API",
+		Version:          "1.0.0",
+		UsePathGroups:    true,
+		ErrorRates:       true,
+		ResponseStrategy: StrategyBestSuccess,
+	})
+	// 20 user lookups, of which 3 failed and 1 was not found
+	for i := 1; i <= 20; i++ {
+		status := 200
+		switch {
+		case i <= 3:
+			status = 503
+		case i == 4:
+			status = 404
+		}
+		generator.AddTransaction(createTestTransaction("GET", fmt.Sprintf("/users/%d", i), nil, []byte(`{"id":1,"name":"Ada"}`), status))
+	}
+	// 1 of 50 order listings failed, within the default threshold
+	for i := 1; i <= 50; i++ {
+		status := 200
+		if i == 1 {
+			status = 500
+		}
+		generator.AddTransaction(createTestTransaction("GET", "/orders", nil, []byte(`[{"id":1}]`), status))
+	}
+	// Every export failed
+	generator.AddTransaction(createTestTransaction("POST", "/exports", nil, []byte(`{"error":"timeout"}`), 504))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"requests":      20,
+		"server_errors": 3,
+		"client_errors": 1,
+		"rate":          0.15,
+	}, spec.Paths.Value("/users/{userId}").Get.Extensions[errorRateExtension])
+	orders := spec.Paths.Value("/orders").Get.Extensions[errorRateExtension].(map[string]interface{})
+	assert.Equal(t, 0.02, orders["rate"])
+
+	assert.Equal(t, []string{
+		"POST /exports failed 1 of 1 requests (100.0%)",
+		"GET /users/{userId} failed 3 of 20 requests (15.0%)",
+	}, generator.Stats().FlakyEndpoints)
+}
+
+func TestErrorRateThreshold(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:              "Test API",
+		Version:            "1.0.0",
+		ErrorRates:         true,
+		ErrorRateThreshold: 0.01,
+	})
+	generator.AddTransaction(createTestTransaction("GET", "/orders", nil, []byte(`[{"id":1}]`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/orders", nil, []byte(`[{"id":1}]`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/orders", nil, []byte(`{"error":"boom"}`), 500))
+
+	_, err := generator.GenerateSpec()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GET /orders failed 1 of 3 requests (33.3%)"}, generator.Stats().FlakyEndpoints)
+}
+
+func TestErrorRatesDisabled(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(createTestTransaction("GET", "/orders", nil, []byte(`{"error":"boom"}`), 500))
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+	assert.NotContains(t, spec.Paths.Value("/orders").Get.Extensions, errorRateExtension)
+	assert.Empty(t, generator.Stats().FlakyEndpoints)
+}
@@ -0,0 +1,107 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// errorSample is an error-shaped 4xx/5xx response body captured for an operation
+type errorSample struct {
+	path        string
+	method      string
+	statusCode  string
+	contentType string
+	body        interface{}
+}
+
+// newErrorSample decodes the response body of a failed transaction and reports whether it is
+// an RFC 7807 problem or a common error envelope
+func newErrorSample(path string, tx proxy.APITransaction) (errorSample, bool) {
+	if tx.Response.StatusCode < 400 || len(tx.Response.Body) == 0 {
+		return errorSample{}, false
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(tx.Response.Body, &body); err != nil {
+		return errorSample{}, false
+	}
+
+	contentType := getContentType(tx.Response.Headers)
+	if !parser.IsProblemJSON(contentType) && !parser.IsErrorEnvelope(body) {
+		return errorSample{}, false
+	}
+
+	return errorSample{
+		path:        path,
+		method:      tx.Request.Method,
+		statusCode:  fmt.Sprintf("%d", tx.Response.StatusCode),
+		contentType: contentType,
+		body:        body,
+	}, true
+}
+
+// extractErrorComponent merges the error bodies seen across all endpoints into a single Error
+// component schema and points the 4xx/5xx responses they came from at it. It returns the name
+// of the component, or "" when no error body could be parsed
+func (g *OpenAPIGenerator) extractErrorComponent(doc *OpenAPISpec, samples []errorSample) string {
+	var schemas []parser.Schema
+	var bodies []interface{}
+	for _, sample := range samples {
+		schema, err := g.parseJSONBody(sample.body)
+		if err != nil || schema == nil || schema.Type != "object" {
+			continue
+		}
+		schemas = append(schemas, *schema)
+		bodies = append(bodies, sample.body)
+	}
+	if len(schemas) == 0 {
+		return ""
+	}
+
+	merged := g.refineSchema(parser.MergeSchemaList(schemas), bodies)
+	if merged.Description == "" {
+		merged.Description = "Error response"
+	}
+
+	name := uniqueSchemaName("Error", doc.Components.Schemas)
+	component := toOpenAPISchema(merged)
+	doc.Components.Schemas[name] = &openapi3.SchemaRef{Value: component}
+
+	for _, sample := range samples {
+		pathItem := doc.Paths.Find(sample.path)
+		if pathItem == nil {
+			continue
+		}
+		op := operationFor(pathItem, sample.method)
+		if op == nil || op.Responses == nil {
+			continue
+		}
+		response := op.Responses.Value(sample.statusCode)
+		if response == nil || response.Value == nil {
+			continue
+		}
+
+		for _, media := range response.Value.Content {
+			if media.Schema != nil {
+				media.Schema = &openapi3.SchemaRef{
+					Ref:   "#/components/schemas/" + name,
+					Value: component,
+				}
+			}
+		}
+	}
+
+	return name
+}
+
+// isErrorStatus checks whether a response status code is a 4xx or 5xx code
+func isErrorStatus(statusCode string) bool {
+	code, err := strconv.Atoi(statusCode)
+	return err == nil && code >= 400
+}
@@ -0,0 +1,105 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// exportRequest is an operation filled in with example values, in the shape API client
+// collections need
+type exportRequest struct {
+	name        string
+	description string
+	method      string
+	path        string        // path with {name} parameters
+	pathParams  []exportParam // in the order they appear in the operation
+	query       []exportParam // sorted by name
+	headers     [][2]string   // name, value in a stable order
+	mediaType   string        // media type of the body, if any
+	body        string        // example JSON body, if any
+}
+
+// exportParam is a path or query parameter with its example value
+type exportParam struct {
+	name        string
+	value       string
+	description string
+	required    bool
+}
+
+// newExportRequest fills in the example values of an operation's parameters and JSON body
+func newExportRequest(doc *OpenAPISpec, operation taggedOperation) exportRequest {
+	op := operation.op
+	request := exportRequest{
+		name:        op.Summary,
+		description: op.Description,
+		method:      operation.method,
+		path:        operation.path,
+	}
+	if request.name == "" {
+		request.name = operation.method + " " + operation.path
+	}
+
+	for _, paramRef := range op.Parameters {
+		param := paramRef.Value
+		if param == nil {
+			continue
+		}
+		value := exportParam{
+			name:        param.Name,
+			value:       parameterExample(doc, param),
+			description: param.Description,
+			required:    param.Required,
+		}
+		switch param.In {
+		case "path":
+			request.pathParams = append(request.pathParams, value)
+		case "query":
+			request.query = append(request.query, value)
+		case "header":
+			request.headers = append(request.headers, [2]string{param.Name, value.value})
+		}
+	}
+	sort.Slice(request.query, func(i, j int) bool { return request.query[i].name < request.query[j].name })
+
+	request.headers = append(request.headers, securityHeaders(doc, op)...)
+
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		for _, mediaType := range sortedMediaTypes(op.RequestBody.Value.Content) {
+			if !isJSONContentType(mediaType) {
+				continue
+			}
+			media := op.RequestBody.Value.Content[mediaType]
+			body := media.Example
+			if body == nil {
+				body = schemaExample(doc, media.Schema, 0)
+			}
+			request.mediaType = mediaType
+			request.body = indentedJSON(body, "")
+			request.headers = append(request.headers, [2]string{"Content-Type", mediaType})
+			break
+		}
+	}
+
+	return request
+}
+
+// templatedPath replaces the {name} parameters of a path using a client's variable syntax
+func (r exportRequest) templatedPath(variable func(name string) string) string {
+	segments := strings.Split(r.path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = variable(strings.Trim(segment, "{}"))
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// credentialPlaceholder turns a $NAME credential placeholder into a client variable
+func credentialPlaceholder(value string, variable func(name string) string) string {
+	prefix, name, ok := strings.Cut(value, "$")
+	if !ok {
+		return value
+	}
+	return prefix + variable(strings.ToLower(name))
+}
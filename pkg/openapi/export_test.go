@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportTestTransactions have a JSON body, a path parameter, and an optional query parameter
+func exportTestTransactions() []proxy.APITransaction {
+	paged := createTestTransaction("GET", "/orders", nil, []byte(`[{"id":"__integer__"}]`), 200)
+	paged.Request.QueryParams = url.Values{"page": {"__integer__"}}
+	return []proxy.APITransaction{
+		createTestTransaction("POST", "/users", []byte(`{"name":"__string__"}`), []byte(`{"id":"__integer__","name":"__string__"}`), 201),
+		createTestTransaction("GET", "/users/1", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200),
+		createTestTransaction("GET", "/users/2", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200),
+		paged,
+		createTestTransaction("GET", "/orders", nil, []byte(`[{"id":"__integer__"}]`), 200),
+	}
+}
+
+func TestNewExportRequest(t *testing.T) {
+	spec, _ := generateTestSpec(t, shopTestConfig(), exportTestTransactions()...)
+	tags := operationsByTag(spec)
+
+	require.Len(t, tags["Users"], 2)
+	create := newExportRequest(spec, tags["Users"][0])
+	assert.Equal(t, "POST", create.method)
+	assert.Equal(t, "application/json", create.mediaType)
+	assert.JSONEq(t, `{"name":"string"}`, create.body)
+	assert.Contains(t, create.headers, [2]string{"Content-Type", "application/json"})
+
+	get := newExportRequest(spec, tags["Users"][1])
+	require.Len(t, get.pathParams, 1)
+	assert.Equal(t, "userId", get.pathParams[0].name)
+	assert.Equal(t, "/users/:userId", get.templatedPath(func(name string) string { return ":" + name }))
+
+	require.Len(t, tags["Orders"], 1)
+	list := newExportRequest(spec, tags["Orders"][0])
+	require.Len(t, list.query, 1)
+	assert.Equal(t, "page", list.query[0].name)
+	assert.False(t, list.query[0].required)
+}
+
+func TestCredentialPlaceholder(t *testing.T) {
+	assert.Equal(t, "Bearer {{token}}", credentialPlaceholder("Bearer $TOKEN", postmanTemplate))
+	assert.Equal(t, "{{ _.api_key }}", credentialPlaceholder("$API_KEY", insomniaTemplate))
+	assert.Equal(t, "plain", credentialPlaceholder("plain", postmanTemplate))
+}
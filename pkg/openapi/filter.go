@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// filterTransactions keeps the transactions whose request path matches one of the include path
+// patterns (if any) and none of the exclude path patterns, and whose method passes the include
+// and exclude method lists in the same way
+func (g *OpenAPIGenerator) filterTransactions(transactions []proxy.APITransaction) ([]proxy.APITransaction, error) {
+	include, err := compilePathPatterns(g.config.IncludePaths)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include path pattern: %v", err)
+	}
+	exclude, err := compilePathPatterns(g.config.ExcludePaths)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude path pattern: %v", err)
+	}
+	if len(include) == 0 && len(exclude) == 0 && len(g.config.IncludeMethods) == 0 && len(g.config.ExcludeMethods) == 0 {
+		return transactions, nil
+	}
+
+	var filtered []proxy.APITransaction
+	for _, tx := range transactions {
+		if len(include) > 0 && !matchesAnyPattern(include, tx.Request.Path) {
+			continue
+		}
+		if matchesAnyPattern(exclude, tx.Request.Path) {
+			continue
+		}
+		if len(g.config.IncludeMethods) > 0 && !containsMethod(g.config.IncludeMethods, tx.Request.Method) {
+			continue
+		}
+		if containsMethod(g.config.ExcludeMethods, tx.Request.Method) {
+			continue
+		}
+		filtered = append(filtered, tx)
+	}
+	return filtered, nil
+}
+
+// compilePathPatterns compiles regular expressions matched against request paths
+func compilePathPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// matchesAnyPattern checks whether a path matches one of the patterns
+func matchesAnyPattern(patterns []*regexp.Regexp, path string) bool {
+	for _, re := range patterns {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsMethod checks whether a method is in a list, ignoring case
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecFilters(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     OpenAPIConfig
+		operations []string
+	}{
+		{
+			name:       "no filters",
+			operations: []string{"DELETE /users/{userId}", "GET /debug/vars", "GET /internal/health", "GET /users", "OPTIONS /users"},
+		},
+		{
+			name:       "exclude paths",
+			config:     OpenAPIConfig{ExcludePaths: []string{"^/internal/", "^/debug/"}},
+			operations: []string{"DELETE /users/{userId}", "GET /users", "OPTIONS /users"},
+		},
+		{
+			name:       "include paths",
+			config:     OpenAPIConfig{IncludePaths: []string{"^/users"}},
+			operations: []string{"DELETE /users/{userId}", "GET /users", "OPTIONS /users"},
+		},
+		{
+			name:       "exclude wins over include",
+			config:     OpenAPIConfig{IncludePaths: []string{"^/(users|internal)"}, ExcludePaths: []string{"health$"}},
+			operations: []string{"DELETE /users/{userId}", "GET /users", "OPTIONS /users"},
+		},
+		{
+			name:       "methods",
+			config:     OpenAPIConfig{IncludeMethods: []string{"get", "OPTIONS"}, ExcludeMethods: []string{"options"}},
+			operations: []string{"GET /debug/vars", "GET /internal/health", "GET /users"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Title = "Test API"
+			config.Version = "1.0.0"
+			generator := NewOpenAPIGenerator(config)
+			generator.AddTransaction(createTestTransaction("GET", "/users", nil, []byte(`[{"id":"__integer__"}]`), 200))
+			generator.AddTransaction(createTestTransaction("OPTIONS", "/users", nil, nil, 204))
+			generator.AddTransaction(createTestTransaction("DELETE", "/users/123", nil, nil, 204))
+			generator.AddTransaction(createTestTransaction("DELETE", "/users/456", nil, nil, 204))
+			generator.AddTransaction(createTestTransaction("GET", "/internal/health", nil, []byte(`{"status":"__string__"}`), 200))
+			generator.AddTransaction(createTestTransaction("GET", "/debug/vars", nil, []byte(`{"goroutines":"__integer__"}`), 200))
+
+			spec, err := generator.GenerateSpec()
+			require.NoError(t, err)
+
+			var operations []string
+			for path, pathItem := range spec.Paths.Map() {
+				for method := range pathItem.Operations() {
+					operations = append(operations, method+" "+path)
+				}
+			}
+			sort.Strings(operations)
+			assert.Equal(t, tt.operations, operations)
+		})
+	}
+}
+
+func TestGenerateSpecInvalidFilter(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0", ExcludePaths: []string{"^/internal/("}})
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, []byte(`[{"id":"__integer__"}]`), 200))
+
+	_, err := generator.GenerateSpec()
+	assert.ErrorContains(t, err, "invalid exclude path pattern")
+}
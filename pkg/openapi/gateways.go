@@ -0,0 +1,337 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Integration types of the AWS API Gateway integration stubs
+const (
+	AWSIntegrationHTTPProxy = "http_proxy" // forwards every request to the backend
+	AWSIntegrationMock      = "mock"       // answers with the documented success status
+)
+
+// awsIntegrationExtension holds the integration of an operation in documents imported by AWS API
+// Gateway
+const awsIntegrationExtension = "x-amazon-apigateway-integration"
+
+// apimOperationIDLength is the longest operation name Azure API Management accepts
+const apimOperationIDLength = 80
+
+// nonAlphanumeric matches what AWS API Gateway model names can't contain
+var nonAlphanumeric = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// apimReservedCharacters matches what Azure API Management operation names can't contain
+var apimReservedCharacters = regexp.MustCompile(`[*#&+:<>?/\\%]+`)
+
+// AWSGatewayOptions configures the export for AWS API Gateway
+type AWSGatewayOptions struct {
+	Integration string // AWSIntegrationHTTPProxy (default) or AWSIntegrationMock
+	BackendURL  string // backend the http_proxy integrations forward to (default: the first server)
+}
+
+// RenderAWSAPIGateway converts a document to the OpenAPI 3.0 dialect AWS API Gateway imports as a
+// REST API. Every operation gets an x-amazon-apigateway-integration stub, forwarding to the
+// backend or mocking the documented success status, and the schema keywords and component
+// names API Gateway rejects are rewritten.
+func RenderAWSAPIGateway(spec *OpenAPISpec, opts AWSGatewayOptions) ([]byte, error) {
+	if opts.Integration == "" {
+		opts.Integration = AWSIntegrationHTTPProxy
+	}
+	if opts.Integration != AWSIntegrationHTTPProxy && opts.Integration != AWSIntegrationMock {
+		return nil, fmt.Errorf("unknown integration %q, expected %s or %s", opts.Integration, AWSIntegrationHTTPProxy, AWSIntegrationMock)
+	}
+	backend := strings.TrimRight(opts.BackendURL, "/")
+	if backend == "" {
+		backend = serverBaseURL(spec)
+	}
+	if opts.Integration == AWSIntegrationHTTPProxy && backend == "" {
+		return nil, fmt.Errorf("http_proxy integrations need a backend URL, the document has no servers")
+	}
+
+	doc, err := cloneSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	// Model names are alphanumeric
+	renameSchemas(doc, func(name string) string { return nonAlphanumeric.ReplaceAllString(name, "") })
+
+	// API Gateway rejects discriminators, read-only and write-only properties, and exclusive
+	// bounds, which become inclusive ones
+	eachSchema(doc, func(schema *openapi3.Schema) {
+		schema.Discriminator = nil
+		schema.ReadOnly = false
+		schema.WriteOnly = false
+		schema.ExclusiveMin = false
+		schema.ExclusiveMax = false
+	})
+
+	err = eachOperation(doc, func(method, path string, op *openapi3.Operation) error {
+		if op.Extensions == nil {
+			op.Extensions = make(map[string]interface{})
+		}
+		op.Extensions[awsIntegrationExtension] = awsIntegration(doc.Paths.Value(path), op, method, path, backend, opts.Integration)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// awsIntegration returns the integration stub of an operation
+func awsIntegration(pathItem *openapi3.PathItem, op *openapi3.Operation, method, path, backend, integration string) map[string]interface{} {
+	if integration == AWSIntegrationMock {
+		status := strconv.Itoa(successStatus(op))
+		return map[string]interface{}{
+			"type":                integration,
+			"passthroughBehavior": "when_no_templates",
+			"requestTemplates":    map[string]string{"application/json": `{"statusCode": ` + status + `}`},
+			"responses":           map[string]interface{}{"default": map[string]string{"statusCode": status}},
+		}
+	}
+
+	stub := map[string]interface{}{
+		"type":                integration,
+		"httpMethod":          method,
+		"uri":                 backend + path,
+		"connectionType":      "INTERNET",
+		"passthroughBehavior": "when_no_match",
+	}
+	parameters := make(map[string]string)
+	for _, params := range []openapi3.Parameters{pathItem.Parameters, op.Parameters} {
+		for _, param := range params {
+			if param.Value != nil && param.Value.In == "path" {
+				parameters["integration.request.path."+param.Value.Name] = "method.request.path." + param.Value.Name
+			}
+		}
+	}
+	if len(parameters) > 0 {
+		stub["requestParameters"] = parameters
+	}
+	return stub
+}
+
+// successStatus returns the lowest documented 2xx status of an operation, or 200
+func successStatus(op *openapi3.Operation) int {
+	best := 0
+	if op.Responses != nil {
+		for code := range op.Responses.Map() {
+			status, err := strconv.Atoi(code)
+			if err == nil && status >= 200 && status < 300 && (best == 0 || status < best) {
+				best = status
+			}
+		}
+	}
+	if best == 0 {
+		return 200
+	}
+	return best
+}
+
+// RenderAzureAPIM converts a document to the Swagger 2.0 dialect Azure API Management imports,
+// with operationIds shortened and stripped of the characters API Management doesn't accept in
+// operation names
+func RenderAzureAPIM(spec *OpenAPISpec) ([]byte, error) {
+	doc, err := cloneSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Components == nil {
+		doc.Components = &openapi3.Components{}
+	}
+
+	used := make(map[string]bool)
+	err = eachOperation(doc, func(method, path string, op *openapi3.Operation) error {
+		id := apimReservedCharacters.ReplaceAllString(op.OperationID, "-")
+		if id == "" {
+			id = strings.ToLower(method) + "-" + nonAlphanumeric.ReplaceAllString(path, "-")
+		}
+		op.OperationID = uniqueName(truncate(id, apimOperationIDLength), used, apimOperationIDLength)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Server URL variables become their defaults, since 2.0 has a single host and base path
+	for _, server := range doc.Servers {
+		for name, variable := range server.Variables {
+			server.URL = strings.ReplaceAll(server.URL, "{"+name+"}", variable.Default)
+		}
+		server.Variables = nil
+	}
+
+	doc2, err := openapi2conv.FromV3(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to Swagger 2.0: %v", err)
+	}
+	return json.MarshalIndent(doc2, "", "  ")
+}
+
+// cloneSpec returns a deep copy of a document with its references resolved, to be rewritten for
+// an export without touching the original
+func cloneSpec(spec *OpenAPISpec) (*OpenAPISpec, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy the document: %v", err)
+	}
+	return doc, nil
+}
+
+// renameSchemas renames the component schemas of a document, updating the references to them.
+// Names that would collide get a number appended.
+func renameSchemas(doc *OpenAPISpec, rename func(name string) string) {
+	if doc.Components == nil || len(doc.Components.Schemas) == 0 {
+		return
+	}
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	renamed := make(map[string]string)
+	used := make(map[string]bool)
+	schemas := make(openapi3.Schemas, len(names))
+	for _, name := range names {
+		newName := rename(name)
+		if newName == "" {
+			newName = "Schema"
+		}
+		newName = uniqueName(newName, used, 0)
+		renamed["#/components/schemas/"+name] = "#/components/schemas/" + newName
+		schemas[newName] = doc.Components.Schemas[name]
+	}
+	doc.Components.Schemas = schemas
+
+	eachSchemaRef(doc, func(ref *openapi3.SchemaRef) {
+		if target, ok := renamed[ref.Ref]; ok {
+			ref.Ref = target
+		}
+	})
+}
+
+// uniqueName returns name, or name with the lowest number appended that isn't used yet, and
+// marks it used. With a maximum length, the name is shortened to fit the number.
+func uniqueName(name string, used map[string]bool, maxLength int) string {
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		suffix := strconv.Itoa(i)
+		base := name
+		if maxLength > 0 {
+			base = truncate(name, maxLength-len(suffix))
+		}
+		candidate = base + suffix
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// truncate shortens a string to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// eachSchema calls fn once with every schema of a document: the components, and those of the
+// parameters, request bodies, and responses of every operation, with their subschemas
+func eachSchema(doc *OpenAPISpec, fn func(schema *openapi3.Schema)) {
+	visited := make(map[*openapi3.Schema]bool)
+	eachSchemaRef(doc, func(ref *openapi3.SchemaRef) {
+		if ref.Value != nil && !visited[ref.Value] {
+			visited[ref.Value] = true
+			fn(ref.Value)
+		}
+	})
+}
+
+// eachSchemaRef calls fn with every schema reference of a document, descending into each schema
+// once
+func eachSchemaRef(doc *OpenAPISpec, fn func(ref *openapi3.SchemaRef)) {
+	descended := make(map[*openapi3.Schema]bool)
+	var walk func(ref *openapi3.SchemaRef)
+	walk = func(ref *openapi3.SchemaRef) {
+		if ref == nil {
+			return
+		}
+		fn(ref)
+		schema := ref.Value
+		if schema == nil || descended[schema] {
+			return
+		}
+		descended[schema] = true
+
+		for _, name := range componentNames(schema.Properties) {
+			walk(schema.Properties[name])
+		}
+		walk(schema.Items)
+		walk(schema.AdditionalProperties.Schema)
+		walk(schema.Not)
+		for _, variants := range []openapi3.SchemaRefs{schema.AllOf, schema.AnyOf, schema.OneOf} {
+			for _, variant := range variants {
+				walk(variant)
+			}
+		}
+	}
+
+	if doc.Components != nil {
+		for _, name := range componentNames(doc.Components.Schemas) {
+			walk(doc.Components.Schemas[name])
+		}
+	}
+	eachOperation(doc, func(method, path string, op *openapi3.Operation) error {
+		for _, params := range []openapi3.Parameters{doc.Paths.Value(path).Parameters, op.Parameters} {
+			for _, param := range params {
+				if param.Value != nil {
+					walk(param.Value.Schema)
+				}
+			}
+		}
+		if op.RequestBody != nil && op.RequestBody.Value != nil {
+			for _, mediaType := range sortedMediaTypes(op.RequestBody.Value.Content) {
+				walk(op.RequestBody.Value.Content[mediaType].Schema)
+			}
+		}
+		if op.Responses != nil {
+			for _, response := range op.Responses.Map() {
+				if response.Value == nil {
+					continue
+				}
+				for _, mediaType := range sortedMediaTypes(response.Value.Content) {
+					walk(response.Value.Content[mediaType].Schema)
+				}
+				for _, header := range response.Value.Headers {
+					if header.Value != nil {
+						walk(header.Value.Schema)
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// componentNames returns the names of a schema map in sorted order
+func componentNames(schemas openapi3.Schemas) []string {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
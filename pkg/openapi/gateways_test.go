@@ -0,0 +1,128 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatewayTestConfig is the Shop API served under /v1
+func gatewayTestConfig() OpenAPIConfig {
+	config := shopTestConfig()
+	config.Servers = []OpenAPIServer{{URL: "https://api.example.com/v1"}}
+	return config
+}
+
+// gatewayTestTransactions document creating and reading users
+func gatewayTestTransactions() []proxy.APITransaction {
+	return []proxy.APITransaction{
+		createTestTransaction("POST", "/users", []byte(`{"name":"Ada"}`), []byte(`{"id":1,"name":"Ada"}`), 201),
+		createTestTransaction("GET", "/users/1", nil, []byte(`{"id":1,"name":"Ada"}`), 200),
+		createTestTransaction("GET", "/users/2", nil, []byte(`{"id":2,"name":"Grace"}`), 200),
+	}
+}
+
+// addGatewayTestSchemas adds a schema with keywords and names API Gateway rejects, returned by
+// GET /users/{userId}
+func addGatewayTestSchemas(spec *OpenAPISpec) {
+	spec.Components.Schemas["User_v1.Summary"] = &openapi3.SchemaRef{Value: &openapi3.Schema{
+		Type:          &openapi3.Types{"object"},
+		Discriminator: &openapi3.Discriminator{PropertyName: "kind"},
+		Properties: openapi3.Schemas{
+			"id":    {Value: &openapi3.Schema{Type: &openapi3.Types{"integer"}, ReadOnly: true}},
+			"total": {Value: &openapi3.Schema{Type: &openapi3.Types{"number"}, Min: openapi3.Float64Ptr(0), ExclusiveMin: true}},
+		},
+	}}
+	getUser := spec.Paths.Value("/users/{userId}").Get
+	getUser.Responses.Value("200").Value.Content["application/json"].Schema = openapi3.NewSchemaRef("#/components/schemas/User_v1.Summary", nil)
+}
+
+func TestRenderAWSAPIGateway(t *testing.T) {
+	spec, _ := generateTestSpec(t, gatewayTestConfig(), gatewayTestTransactions()...)
+	addGatewayTestSchemas(spec)
+	data, err := RenderAWSAPIGateway(spec, AWSGatewayOptions{})
+	require.NoError(t, err)
+
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	require.NoError(t, err)
+
+	// Operations forward to the backend of the first server
+	create := doc.Paths.Value("/users").Post.Extensions[awsIntegrationExtension].(map[string]interface{})
+	assert.Equal(t, "http_proxy", create["type"])
+	assert.Equal(t, "POST", create["httpMethod"])
+	assert.Equal(t, "https://api.example.com/v1/users", create["uri"])
+	assert.NotContains(t, create, "requestParameters")
+
+	get := doc.Paths.Value("/users/{userId}").Get.Extensions[awsIntegrationExtension].(map[string]interface{})
+	assert.Equal(t, "https://api.example.com/v1/users/{userId}", get["uri"])
+	assert.Equal(t, map[string]interface{}{"integration.request.path.userId": "method.request.path.userId"}, get["requestParameters"])
+
+	// Models are renamed and the keywords API Gateway rejects are left out
+	require.Contains(t, doc.Components.Schemas, "Userv1Summary")
+	assert.NotContains(t, doc.Components.Schemas, "User_v1.Summary")
+	assert.Contains(t, string(data), `"$ref": "#/components/schemas/Userv1Summary"`)
+	summary := doc.Components.Schemas["Userv1Summary"].Value
+	assert.Nil(t, summary.Discriminator)
+	assert.False(t, summary.Properties["id"].Value.ReadOnly)
+	assert.False(t, summary.Properties["total"].Value.ExclusiveMin)
+	assert.Equal(t, 0.0, *summary.Properties["total"].Value.Min)
+
+	// The original document is unchanged
+	assert.Contains(t, spec.Components.Schemas, "User_v1.Summary")
+	assert.NotContains(t, spec.Paths.Value("/users").Post.Extensions, awsIntegrationExtension)
+}
+
+func TestRenderAWSAPIGatewayMock(t *testing.T) {
+	spec, _ := generateTestSpec(t, gatewayTestConfig(), gatewayTestTransactions()...)
+	addGatewayTestSchemas(spec)
+	data, err := RenderAWSAPIGateway(spec, AWSGatewayOptions{Integration: AWSIntegrationMock})
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	create := doc["paths"].(map[string]interface{})["/users"].(map[string]interface{})["post"].(map[string]interface{})
+	integration := create[awsIntegrationExtension].(map[string]interface{})
+	assert.Equal(t, "mock", integration["type"])
+	assert.Equal(t, map[string]interface{}{"application/json": `{"statusCode": 201}`}, integration["requestTemplates"])
+	assert.Equal(t, map[string]interface{}{"default": map[string]interface{}{"statusCode": "201"}}, integration["responses"])
+
+	_, err = RenderAWSAPIGateway(spec, AWSGatewayOptions{Integration: "lambda"})
+	assert.Error(t, err)
+}
+
+func TestRenderAzureAPIM(t *testing.T) {
+	spec, _ := generateTestSpec(t, gatewayTestConfig(), gatewayTestTransactions()...)
+	addGatewayTestSchemas(spec)
+	long := strings.Repeat("listEverything", 10)
+	spec.Paths.Value("/users").Post.OperationID = long
+	spec.Paths.Value("/users/{userId}").Get.OperationID = long + ":byId"
+
+	data, err := RenderAzureAPIM(spec)
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "2.0", doc["swagger"])
+	assert.Equal(t, "api.example.com", doc["host"])
+	assert.Equal(t, "/v1", doc["basePath"])
+	assert.Equal(t, []interface{}{"https"}, doc["schemes"])
+	assert.Contains(t, doc["definitions"], "User_v1.Summary")
+
+	// Operation names are unique, at most 80 characters, and without reserved characters
+	paths := doc["paths"].(map[string]interface{})
+	create := paths["/users"].(map[string]interface{})["post"].(map[string]interface{})["operationId"].(string)
+	get := paths["/users/{userId}"].(map[string]interface{})["get"].(map[string]interface{})["operationId"].(string)
+	assert.Len(t, create, apimOperationIDLength)
+	assert.LessOrEqual(t, len(get), apimOperationIDLength)
+	assert.NotEqual(t, create, get)
+	assert.NotContains(t, get, ":")
+
+	// The original document is unchanged
+	assert.Equal(t, long, spec.Paths.Value("/users").Post.OperationID)
+}
@@ -1,23 +1,55 @@
 package openapi
 
 import (
-	"encoding/json"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/parnexcodes/swag-doc/pkg/proxy"
 )
 
 // Generator creates OpenAPI documentation from recorded API calls
 type Generator struct {
-	storage         proxy.Storage
-	title           string
-	description     string
-	version         string
-	basePath        string
-	tagMappings     map[string]string
-	usePathGroups   bool
-	versionPrefixes map[string]bool
+	storage           proxy.Storage
+	title             string
+	description       string
+	version           string
+	basePath          string
+	tagMappings       map[string]string
+	tagDescriptions   map[string]string
+	tagGroups         []OpenAPITagGroup
+	usePathGroups     bool
+	versionPrefixes   map[string]bool
+	pathTemplates     map[string]string
+	stripPrefixes     []string
+	inferConstraints  bool
+	requiredThreshold float64
+	splitMediaTypes   bool
+	codeSamples       bool
+	owners            []OpenAPIOwner
+	responseStrategy  ResponseStrategy
+	statusDescs       map[string]string
+	includePaths      []string
+	excludePaths      []string
+	includeMethods    []string
+	excludeMethods    []string
+	includeHeaders    []string
+	excludeHeaders    []string
+	requiredHeaders   []string
+	operationDocs     map[string]OpenAPIOperationDoc
+	strict            bool
+	mergeInto         string
+	overlays          []string
+	emitSchemas       string
+	split             bool
+	contact           OpenAPIContact
+	license           OpenAPILicense
+	termsOfService    string
+	externalDocs      OpenAPIExternalDocs
+	servers           []OpenAPIServer
+	inferServers      bool
+	hooks             generationHooks
 }
 
 // NewGenerator creates a new generator
@@ -29,8 +61,12 @@ func NewGenerator(storage proxy.Storage, title, description, version, basePath s
 		version:         version,
 		basePath:        basePath,
 		tagMappings:     make(map[string]string),
+		tagDescriptions: make(map[string]string),
 		usePathGroups:   true,
+		codeSamples:     true,
 		versionPrefixes: make(map[string]bool),
+		pathTemplates:   make(map[string]string),
+		statusDescs:     make(map[string]string),
 	}
 }
 
@@ -39,46 +75,247 @@ func (g *Generator) SetTagMapping(pathPrefix, tag string) {
 	g.tagMappings[pathPrefix] = tag
 }
 
+// SetTagDescription sets the description of a tag in the top-level tags list
+func (g *Generator) SetTagDescription(tag, description string) {
+	g.tagDescriptions[tag] = description
+}
+
+// AddTagGroup adds a named section of tags, emitted as x-tagGroups
+func (g *Generator) AddTagGroup(name string, tags ...string) {
+	g.tagGroups = append(g.tagGroups, OpenAPITagGroup{Name: name, Tags: tags})
+}
+
 // SetVersionPrefix adds a custom version prefix for path handling
 func (g *Generator) SetVersionPrefix(prefix string) {
 	g.versionPrefixes[prefix] = true
 }
 
+// SetPathTemplate forces paths matching a wildcard pattern (e.g. "/users/*") to use a template (e.g. "/users/{userId}")
+func (g *Generator) SetPathTemplate(pattern, template string) {
+	g.pathTemplates[pattern] = template
+}
+
+// AddStripPrefix adds a path prefix, e.g. /api/v2, that is removed from every path and added
+// to the server URLs instead
+func (g *Generator) AddStripPrefix(prefix string) {
+	g.stripPrefixes = append(g.stripPrefixes, prefix)
+}
+
+// AddIncludePath adds a regular expression for request paths to document; once one is added,
+// paths matching none are left out
+func (g *Generator) AddIncludePath(pattern string) {
+	g.includePaths = append(g.includePaths, pattern)
+}
+
+// AddExcludePath adds a regular expression for request paths to leave out, e.g. ^/internal/
+func (g *Generator) AddExcludePath(pattern string) {
+	g.excludePaths = append(g.excludePaths, pattern)
+}
+
+// AddIncludeMethod adds a method to document; once one is added, other methods are left out
+func (g *Generator) AddIncludeMethod(method string) {
+	g.includeMethods = append(g.includeMethods, method)
+}
+
+// AddExcludeMethod adds a method to leave out, e.g. OPTIONS
+func (g *Generator) AddExcludeMethod(method string) {
+	g.excludeMethods = append(g.excludeMethods, method)
+}
+
+// AddIncludeHeader adds a request header to document as a parameter even if it is common, e.g.
+// Accept-Language
+func (g *Generator) AddIncludeHeader(name string) {
+	g.includeHeaders = append(g.includeHeaders, name)
+}
+
+// AddExcludeHeader adds a request header never to document as a parameter, e.g. X-Request-Id
+func (g *Generator) AddExcludeHeader(name string) {
+	g.excludeHeaders = append(g.excludeHeaders, name)
+}
+
+// AddRequiredHeader adds a request header to document as a required parameter of every
+// operation, e.g. X-Org-Id
+func (g *Generator) AddRequiredHeader(name string) {
+	g.requiredHeaders = append(g.requiredHeaders, name)
+}
+
+// SetInferConstraints configures whether observed min/max, length, and pattern constraints are added to schemas
+func (g *Generator) SetInferConstraints(infer bool) {
+	g.inferConstraints = infer
+}
+
+// SetRequiredThreshold sets the fraction of samples (0-1] a property must appear in to be marked required
+func (g *Generator) SetRequiredThreshold(threshold float64) {
+	g.requiredThreshold = threshold
+}
+
+// SetSplitMediaTypes configures whether versioned vendor media types, e.g.
+// application/vnd.acme.v2+json, get their own schemas instead of one merged schema
+func (g *Generator) SetSplitMediaTypes(split bool) {
+	g.splitMediaTypes = split
+}
+
+// SetCodeSamples configures whether x-codeSamples snippets are added to every operation
+func (g *Generator) SetCodeSamples(enabled bool) {
+	g.codeSamples = enabled
+}
+
+// AddOwner assigns the operations under a path prefix to an owner and a team, emitted as the
+// x-owner and x-team extensions. Either may be empty.
+func (g *Generator) AddOwner(prefix, owner, team string) {
+	g.owners = append(g.owners, OpenAPIOwner{Prefix: prefix, Owner: owner, Team: team})
+}
+
+// SetResponseStrategy sets which captured transactions of every endpoint are documented
+func (g *Generator) SetResponseStrategy(strategy ResponseStrategy) {
+	g.responseStrategy = strategy
+}
+
+// SetStatusDescription sets the description of responses with a status code, replacing the
+// standard reason phrase
+func (g *Generator) SetStatusDescription(statusCode int, description string) {
+	g.statusDescs[strconv.Itoa(statusCode)] = description
+}
+
+// SetOperationDoc sets the human-written summary, description, and tag of the operation of a
+// method and templated path, e.g. GET /users/{userId}, replacing the generated ones
+func (g *Generator) SetOperationDoc(method, path string, doc OpenAPIOperationDoc) {
+	if g.operationDocs == nil {
+		g.operationDocs = make(map[string]OpenAPIOperationDoc)
+	}
+	g.operationDocs[strings.ToUpper(method)+" "+trimTrailingSlash(path)] = doc
+}
+
+// SetStrict configures whether Generate fails when the generated document is not a valid OpenAPI document
+func (g *Generator) SetStrict(strict bool) {
+	g.strict = strict
+}
+
+// SetMergeInto sets an existing OpenAPI document that the generated one is merged into,
+// preserving its hand-written descriptions, summaries, tags, and examples
+func (g *Generator) SetMergeInto(path string) {
+	g.mergeInto = path
+}
+
+// AddOverlay adds an OpenAPI Overlay or JSON merge patch file that is applied to every
+// generated document, in the order the files were added
+func (g *Generator) AddOverlay(path string) {
+	g.overlays = append(g.overlays, path)
+}
+
+// SetEmitSchemas sets a directory that every component schema is written to as a standalone
+// JSON Schema (draft 2020-12) file
+func (g *Generator) SetEmitSchemas(dir string) {
+	g.emitSchemas = dir
+}
+
+// SetSplit configures whether the document is written as a root file plus paths/ and
+// components/ files connected by relative $refs
+func (g *Generator) SetSplit(split bool) {
+	g.split = split
+}
+
+// SetContact sets the contact information of the API
+func (g *Generator) SetContact(name, email, url string) {
+	g.contact = OpenAPIContact{Name: name, Email: email, URL: url}
+}
+
+// SetLicense sets the license of the API
+func (g *Generator) SetLicense(name, url string) {
+	g.license = OpenAPILicense{Name: name, URL: url}
+}
+
+// SetTermsOfService sets the URL of the terms of service for the API
+func (g *Generator) SetTermsOfService(url string) {
+	g.termsOfService = url
+}
+
+// SetExternalDocs links the API to additional external documentation
+func (g *Generator) SetExternalDocs(description, url string) {
+	g.externalDocs = OpenAPIExternalDocs{Description: description, URL: url}
+}
+
+// AddServer adds a server after the base path. The URL may contain {name} placeholders, each
+// of which needs a variable.
+func (g *Generator) AddServer(url, description string, variables map[string]OpenAPIServerVariable) {
+	g.servers = append(g.servers, OpenAPIServer{URL: url, Description: description, Variables: variables})
+}
+
+// SetInferServers configures whether servers are added for the Host/X-Forwarded-Host headers
+// of captured requests
+func (g *Generator) SetInferServers(infer bool) {
+	g.inferServers = infer
+}
+
 // SetUsePathGroups configures whether to group APIs by path segments
 func (g *Generator) SetUsePathGroups(use bool) {
 	g.usePathGroups = use
 }
 
+// OnOperation registers a hook called with every generated operation
+func (g *Generator) OnOperation(hook OperationHook) {
+	g.hooks.operations = append(g.hooks.operations, hook)
+}
+
+// OnSchema registers a hook called with every generated schema
+func (g *Generator) OnSchema(hook SchemaHook) {
+	g.hooks.schemas = append(g.hooks.schemas, hook)
+}
+
+// OnSpecComplete registers a hook called with the complete document before it is written
+func (g *Generator) OnSpecComplete(hook SpecHook) {
+	g.hooks.specs = append(g.hooks.specs, hook)
+}
+
 // Generate creates an OpenAPI specification from the recorded API calls
 func (g *Generator) Generate(outputPath string) error {
-	// Get all transactions
-	transactions, err := g.storage.GetAll()
-	if err != nil {
-		return err
-	}
-
 	// Create config
 	config := OpenAPIConfig{
-		Title:           g.title,
-		Description:     g.description,
-		Version:         g.version,
-		TagMappings:     g.tagMappings,
-		UsePathGroups:   g.usePathGroups,
-		VersionPrefixes: g.versionPrefixes,
-		Servers: []OpenAPIServer{
+		Title:              g.title,
+		Description:        g.description,
+		Version:            g.version,
+		TagMappings:        g.tagMappings,
+		TagDescriptions:    g.tagDescriptions,
+		TagGroups:          g.tagGroups,
+		UsePathGroups:      g.usePathGroups,
+		VersionPrefixes:    g.versionPrefixes,
+		PathTemplates:      g.pathTemplates,
+		StripPrefixes:      g.stripPrefixes,
+		InferConstraints:   g.inferConstraints,
+		RequiredThreshold:  g.requiredThreshold,
+		SplitMediaTypes:    g.splitMediaTypes,
+		CodeSamples:        g.codeSamples,
+		Owners:             g.owners,
+		ResponseStrategy:   g.responseStrategy,
+		StatusDescriptions: g.statusDescs,
+		IncludePaths:       g.includePaths,
+		ExcludePaths:       g.excludePaths,
+		IncludeMethods:     g.includeMethods,
+		ExcludeMethods:     g.excludeMethods,
+		IncludeHeaders:     g.includeHeaders,
+		ExcludeHeaders:     g.excludeHeaders,
+		RequiredHeaders:    g.requiredHeaders,
+		OperationDocs:      g.operationDocs,
+		Contact:            g.contact,
+		License:            g.license,
+		TermsOfService:     g.termsOfService,
+		ExternalDocs:       g.externalDocs,
+		InferServers:       g.inferServers,
+		Servers: append([]OpenAPIServer{
 			{
 				URL:         g.basePath,
 				Description: "API Server",
 			},
-		},
+		}, g.servers...),
 	}
 
 	// Create generator
 	generator := NewOpenAPIGenerator(config)
+	generator.hooks = g.hooks
 
-	// Add transactions
-	for _, tx := range transactions {
-		generator.AddTransaction(tx)
+	// Stream the transactions from the storage
+	if err := generator.AddTransactionsFrom(g.storage); err != nil {
+		return err
 	}
 
 	// Generate spec
@@ -87,20 +324,55 @@ func (g *Generator) Generate(outputPath string) error {
 		return err
 	}
 
+	// Merge into the existing hand-edited document
+	if g.mergeInto != "" {
+		existing, err := LoadSpec(g.mergeInto)
+		if err != nil {
+			return err
+		}
+		spec = MergeSpecs(existing, spec)
+	}
+
+	// Apply overlays last so that their corrections always win
+	for _, overlay := range g.overlays {
+		if spec, err = ApplyOverlay(spec, overlay); err != nil {
+			return err
+		}
+	}
+
+	// Refuse to write an invalid document in strict mode
+	if g.strict {
+		if err := ValidateSpec(spec); err != nil {
+			return err
+		}
+	}
+
+	// Write the component schemas for use outside OpenAPI
+	if g.emitSchemas != "" {
+		if err := WriteJSONSchemas(spec, g.emitSchemas); err != nil {
+			return err
+		}
+	}
+
+	// Write the root document with its path and component files next to it
+	if g.split {
+		return WriteSplit(spec, outputPath, FormatForPath(outputPath))
+	}
+
 	// Create output directory if it doesn't exist
 	outputDir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return err
 	}
 
-	// Marshal to JSON
-	data, err := json.MarshalIndent(spec, "", "  ")
+	// Marshal to JSON, or YAML for .yaml/.yml output files
+	data, err := MarshalSpec(spec, outputPath)
 	if err != nil {
 		return err
 	}
 
-	// Write to file
-	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+	// Write to file, replacing any previous document only once the new one is complete
+	if err := WriteFileAtomic(outputPath, data); err != nil {
 		return err
 	}
 
@@ -0,0 +1,36 @@
+package openapi
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratorHeaderOptions(t *testing.T) {
+	storage, err := proxy.NewFileStorage(t.TempDir())
+	require.NoError(t, err)
+	tx := createTestTransaction("GET", "/users", nil, []byte(`[]`), 200)
+	tx.Request.Headers.Set("Accept-Language", "en")
+	tx.Request.Headers.Set("X-Request-Id", "__string__")
+	require.NoError(t, storage.Store(tx))
+
+	generator := NewGenerator(storage, "Test API", "", "1.0.0", "http://localhost")
+	generator.AddIncludeHeader("Accept-Language")
+	generator.AddExcludeHeader("X-Request-Id")
+	generator.AddRequiredHeader("X-Org-Id")
+	output := filepath.Join(t.TempDir(), "swagger.json")
+	require.NoError(t, generator.Generate(output))
+
+	spec, err := LoadSpec(output)
+	require.NoError(t, err)
+	users := spec.Paths.Find("/users").Get
+	assert.NotNil(t, users.Parameters.GetByInAndName("header", "Accept-Language"), "included common header")
+	assert.Nil(t, users.Parameters.GetByInAndName("header", "X-Request-Id"), "excluded header")
+	required := users.Parameters.GetByInAndName("header", "X-Org-Id")
+	require.NotNil(t, required)
+	assert.True(t, required.Required)
+}
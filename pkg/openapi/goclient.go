@@ -0,0 +1,439 @@
+package openapi
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+)
+
+// goClientSupport is the part of a generated Go client that doesn't depend on the document
+const goClientSupport = `
+// Client calls the API. Header is sent with every request, e.g. an Authorization header.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Header     http.Header
+}
+
+// NewClient creates a client of the API at baseURL, or at DefaultBaseURL if it is empty
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		Header:     http.Header{},
+	}
+}
+
+// Error is returned for responses without a 2xx status
+type Error struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// do sends a request, with body encoded as JSON unless it is an io.Reader, and decodes the JSON
+// response into result unless it is nil
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, contentType string, body, result interface{}) error {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	for name, values := range c.Header {
+		req.Header[name] = values
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if reader != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if result != nil {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &Error{StatusCode: resp.StatusCode, Body: data}
+	}
+	if result == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
+`
+
+// goClientParam is a query or header parameter of a generated client method
+type goClientParam struct {
+	field string
+	name  string
+	in    string
+}
+
+// goClientEmitter renders the models and methods of a document as Go
+type goClientEmitter struct {
+	doc    *OpenAPISpec
+	models map[string]string // Go type by component schema name
+	b      strings.Builder
+}
+
+// RenderGoClient renders a minimal client of a document as a Go file of a package: a struct per
+// component schema, and a Client method per operation taking the path parameters as arguments,
+// the query and header parameters as a struct, and the JSON request body, and returning the
+// decoded JSON response of the first documented success status. The file only uses the standard
+// library, so it can be dropped into any module.
+func RenderGoClient(spec *OpenAPISpec, pkg string) ([]byte, error) {
+	e := &goClientEmitter{doc: spec, models: make(map[string]string)}
+
+	var names []string
+	if spec.Components != nil {
+		for name := range spec.Components.Schemas {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	used := map[string]bool{"Client": true, "Error": true, "NewClient": true, "DefaultBaseURL": true}
+	for _, name := range names {
+		e.models[name] = uniqueName(goIdentifier(name, true), used, 0)
+	}
+
+	title := "the API"
+	if spec.Info != nil && spec.Info.Title != "" {
+		title = spec.Info.Title
+		if spec.Info.Version != "" {
+			title += " " + spec.Info.Version
+		}
+	}
+	e.b.WriteString("// Code generated by swagdoc export sdk; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&e.b, "// Package %s is a client of %s.\n", pkg, goComment(title))
+	fmt.Fprintf(&e.b, "package %s\n\n", pkg)
+	e.b.WriteString("import (\n\"bytes\"\n\"context\"\n\"encoding/json\"\n\"fmt\"\n\"io\"\n\"net/http\"\n\"net/url\"\n\"strings\"\n)\n\n")
+	e.b.WriteString("// DefaultBaseURL is the server the API was documented from\n")
+	fmt.Fprintf(&e.b, "const DefaultBaseURL = %s\n", strconv.Quote(serverBaseURL(spec)))
+	e.b.WriteString(goClientSupport)
+
+	for _, name := range names {
+		ref := spec.Components.Schemas[name]
+		if ref.Value == nil {
+			continue
+		}
+		e.b.WriteString("\n")
+		if description := ref.Value.Description; description != "" {
+			fmt.Fprintf(&e.b, "// %s %s\n", e.models[name], goComment(description))
+		}
+		if isObjectSchema(ref.Value) {
+			fmt.Fprintf(&e.b, "type %s %s\n", e.models[name], e.structType(ref.Value))
+		} else {
+			fmt.Fprintf(&e.b, "type %s %s\n", e.models[name], e.goType(&openapi3.SchemaRef{Value: ref.Value}, false))
+		}
+	}
+
+	methods := map[string]bool{"BaseURL": true, "HTTPClient": true, "Header": true, "do": true}
+	err := eachOperation(spec, func(method, path string, op *openapi3.Operation) error {
+		id := op.OperationID
+		if id == "" {
+			id = parser.GenerateOperationID(method, path)
+		}
+		e.method(uniqueName(goIdentifier(id, true), methods, 0), method, path, op, used)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := format.Source([]byte(e.b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the Go client: %v", err)
+	}
+	return source, nil
+}
+
+// method renders the Client method of an operation, with the struct of its parameters
+func (e *goClientEmitter) method(name, method, path string, op *openapi3.Operation, types map[string]bool) {
+	args := []string{"ctx context.Context"}
+	argNames := map[string]bool{"ctx": true, "c": true, "params": true, "body": true, "result": true, "err": true, "query": true, "header": true}
+	for _, pkg := range []string{"bytes", "context", "json", "fmt", "io", "http", "url", "strings"} {
+		argNames[pkg] = true
+	}
+
+	// Path parameters are arguments, in the order of the path
+	pathExpr := strconv.Quote(path)
+	for _, segment := range strings.Split(path, "/") {
+		if !isPathParam(segment) {
+			continue
+		}
+		name := strings.Trim(segment, "{}")
+		arg := uniqueName(goIdentifier(name, false), argNames, 0)
+		argType := "string"
+		if param := op.Parameters.GetByInAndName(openapi3.ParameterInPath, name); param != nil && param.Schema != nil && param.Schema.Value != nil && param.Schema.Value.Type.Is("integer") {
+			argType = "int64"
+		}
+		args = append(args, arg+" "+argType)
+		pathExpr = fmt.Sprintf("strings.Replace(%s, %s, url.PathEscape(fmt.Sprint(%s)), 1)", pathExpr, strconv.Quote(segment), arg)
+	}
+	var params []goClientParam
+	for _, ref := range op.Parameters {
+		if ref.Value != nil && (ref.Value.In == openapi3.ParameterInQuery || ref.Value.In == openapi3.ParameterInHeader) {
+			params = append(params, goClientParam{name: ref.Value.Name, in: ref.Value.In})
+		}
+	}
+	paramsType := ""
+	if len(params) > 0 {
+		paramsType = uniqueName(name+"Params", types, 0)
+		args = append(args, "params *"+paramsType)
+		fields := map[string]bool{}
+		for i := range params {
+			params[i].field = uniqueName(goIdentifier(params[i].name, true), fields, 0)
+		}
+	}
+
+	// The body is encoded as JSON, or sent as is for other media types
+	contentType, body := "", "nil"
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		if mediaTypes := sortedMediaTypes(op.RequestBody.Value.Content); len(mediaTypes) > 0 {
+			contentType, body = mediaTypes[0], "body"
+			for _, mediaType := range mediaTypes {
+				if isJSONContentType(mediaType) {
+					contentType = mediaType
+					break
+				}
+			}
+			if media := op.RequestBody.Value.Content[contentType]; isJSONContentType(contentType) && media.Schema != nil {
+				args = append(args, "body "+e.goType(media.Schema, true))
+			} else {
+				args = append(args, "body io.Reader")
+			}
+		}
+	}
+
+	result := e.resultType(op)
+
+	if paramsType != "" {
+		fmt.Fprintf(&e.b, "\n// %s are the query and header parameters of %s\n", paramsType, name)
+		fmt.Fprintf(&e.b, "type %s struct {\n", paramsType)
+		for _, param := range params {
+			fmt.Fprintf(&e.b, "%s string // %s %s\n", param.field, param.in, param.name)
+		}
+		e.b.WriteString("}\n")
+	}
+
+	fmt.Fprintf(&e.b, "\n// %s calls %s %s", name, method, path)
+	if op.Summary != "" {
+		fmt.Fprintf(&e.b, ": %s", goComment(op.Summary))
+	}
+	e.b.WriteString("\n")
+	if op.Deprecated {
+		e.b.WriteString("//\n// Deprecated: the operation is deprecated.\n")
+	}
+	if result != "" {
+		fmt.Fprintf(&e.b, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), result)
+	} else {
+		fmt.Fprintf(&e.b, "func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+	}
+
+	query, header := "nil", "nil"
+	if paramsType != "" {
+		e.b.WriteString("query, header := url.Values{}, http.Header{}\n")
+		e.b.WriteString("if params != nil {\n")
+		for _, param := range params {
+			fmt.Fprintf(&e.b, "if params.%s != \"\" {\n", param.field)
+			if param.in == openapi3.ParameterInQuery {
+				fmt.Fprintf(&e.b, "query.Set(%s, params.%s)\n", strconv.Quote(param.name), param.field)
+			} else {
+				fmt.Fprintf(&e.b, "header.Set(%s, params.%s)\n", strconv.Quote(param.name), param.field)
+			}
+			e.b.WriteString("}\n")
+		}
+		e.b.WriteString("}\n")
+		query, header = "query", "header"
+	}
+
+	call := fmt.Sprintf("c.do(ctx, %s, %s, %s, %s, %s, %s", strconv.Quote(method), pathExpr, query, header, strconv.Quote(contentType), body)
+	if result != "" {
+		fmt.Fprintf(&e.b, "var result %s\n", result)
+		fmt.Fprintf(&e.b, "err := %s, &result)\n", call)
+		e.b.WriteString("return result, err\n")
+	} else {
+		fmt.Fprintf(&e.b, "return %s, nil)\n", call)
+	}
+	e.b.WriteString("}\n")
+}
+
+// resultType returns the Go type of the JSON response of the first documented success status, or
+// "" without one
+func (e *goClientEmitter) resultType(op *openapi3.Operation) string {
+	if op.Responses == nil {
+		return ""
+	}
+	var statuses []string
+	for status := range op.Responses.Map() {
+		if len(status) == 3 && status[0] == '2' {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		resp := op.Responses.Value(status)
+		if resp == nil || resp.Value == nil {
+			continue
+		}
+		for _, mediaType := range sortedMediaTypes(resp.Value.Content) {
+			if media := resp.Value.Content[mediaType]; isJSONContentType(mediaType) && media.Schema != nil {
+				return e.goType(media.Schema, true)
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// goType returns the Go type of a schema. References to object schemas are pointers when
+// pointer is set, so that optional and recursive models can be nil.
+func (e *goClientEmitter) goType(ref *openapi3.SchemaRef, pointer bool) string {
+	if ref == nil {
+		return "json.RawMessage"
+	}
+	if name, ok := strings.CutPrefix(ref.Ref, "#/components/schemas/"); ok {
+		model, ok := e.models[name]
+		if !ok {
+			return "json.RawMessage"
+		}
+		if target := e.doc.Components.Schemas[name]; pointer && target != nil && target.Value != nil && isObjectSchema(target.Value) {
+			return "*" + model
+		}
+		return model
+	}
+	schema := ref.Value
+	if schema == nil || len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		return "json.RawMessage"
+	}
+	if len(schema.AllOf) == 1 {
+		return e.goType(schema.AllOf[0], pointer)
+	}
+
+	switch {
+	case schema.Type.Is("string"):
+		return "string"
+	case schema.Type.Is("integer"):
+		return "int64"
+	case schema.Type.Is("number"):
+		return "float64"
+	case schema.Type.Is("boolean"):
+		return "bool"
+	case schema.Type.Is("array"):
+		return "[]" + e.goType(schema.Items, false)
+	case isObjectSchema(schema):
+		return e.structType(schema)
+	case schema.Type.Is("object"):
+		if additional := schema.AdditionalProperties.Schema; additional != nil {
+			return "map[string]" + e.goType(additional, false)
+		}
+		return "map[string]interface{}"
+	}
+	return "json.RawMessage"
+}
+
+// structType returns a struct type with a field per property of an object schema, optional
+// properties being omitted when empty
+func (e *goClientEmitter) structType(schema *openapi3.Schema) string {
+	properties := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		properties = append(properties, name)
+	}
+	sort.Strings(properties)
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	fields := make(map[string]bool)
+	for _, name := range properties {
+		property := schema.Properties[name]
+		if property.Value != nil && property.Value.Description != "" {
+			fmt.Fprintf(&b, "// %s\n", goComment(property.Value.Description))
+		}
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&b, "%s %s `json:%s`\n", uniqueName(goIdentifier(name, true), fields, 0), e.goType(property, true), strconv.Quote(tag))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// isObjectSchema reports whether a schema is an object with properties, rendered as a struct
+func isObjectSchema(schema *openapi3.Schema) bool {
+	return len(schema.Properties) > 0 && (schema.Type == nil || schema.Type.Is("object"))
+}
+
+// goIdentifier turns a name into a Go identifier, e.g. user-id into UserId when exported or userId
+// otherwise
+func goIdentifier(name string, exported bool) string {
+	var b strings.Builder
+	for i, word := range parser.SplitWords(name) {
+		runes := []rune(word)
+		if i == 0 && !exported {
+			runes[0] = unicode.ToLower(runes[0])
+		} else {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		b.WriteString(string(runes))
+	}
+	id := b.String()
+	if id == "" || !unicode.IsLetter([]rune(id)[0]) {
+		if exported {
+			id = "X" + id
+		} else {
+			id = "x" + id
+		}
+	}
+	if token.IsKeyword(id) {
+		id += "_"
+	}
+	return id
+}
+
+// goComment flattens text into a single comment line
+func goComment(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,140 @@
+package openapi
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addListUsersOperation adds GET /users, with query and header parameters, to the gateway test
+// document
+func addListUsersOperation(spec *OpenAPISpec) {
+	list := openapi3.NewOperation()
+	list.OperationID = "listUsers"
+	list.Summary = "List users"
+	list.Parameters = openapi3.Parameters{
+		{Value: openapi3.NewQueryParameter("page").WithSchema(openapi3.NewIntegerSchema())},
+		{Value: openapi3.NewHeaderParameter("X-Tenant").WithSchema(openapi3.NewStringSchema())},
+	}
+	list.Responses = openapi3.NewResponses()
+	users := openapi3.NewArraySchema()
+	users.Items = openapi3.NewSchemaRef("#/components/schemas/User", nil)
+	list.Responses.Set("200", &openapi3.ResponseRef{Value: openapi3.NewResponse().WithJSONSchema(users)})
+	spec.Paths.Value("/users").Get = list
+}
+
+func TestRenderGoClient(t *testing.T) {
+	spec, _ := generateTestSpec(t, gatewayTestConfig(), gatewayTestTransactions()...)
+	addGatewayTestSchemas(spec)
+	addListUsersOperation(spec)
+	source, err := RenderGoClient(spec, "shop")
+	require.NoError(t, err)
+
+	file, err := parser.ParseFile(token.NewFileSet(), "client.go", source, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "shop", file.Name.Name)
+
+	// Models are structs with JSON tags, optional properties being omitted when empty
+	assert.Contains(t, string(source), "type User struct {\n\tId   int64  `json:\"id\"`")
+	assert.Contains(t, string(source), "Total float64 `json:\"total,omitempty\"`")
+	assert.Contains(t, string(source), `const DefaultBaseURL = "https://api.example.com/v1"`)
+
+	// Methods take the path parameters, a struct of the other parameters, and the body
+	assert.Contains(t, string(source), "func (c *Client) PostUsers(ctx context.Context, body *User) (*User, error)")
+	assert.Contains(t, string(source), "func (c *Client) GetUsersByUserId(ctx context.Context, userId int64) (*UserV1Summary, error)")
+	assert.Contains(t, string(source), "func (c *Client) ListUsers(ctx context.Context, params *ListUsersParams) ([]User, error)")
+	assert.Contains(t, string(source), "XTenant string // header X-Tenant")
+}
+
+func TestGoIdentifier(t *testing.T) {
+	assert.Equal(t, "UserId", goIdentifier("user-id", true))
+	assert.Equal(t, "userId", goIdentifier("user_id", false))
+	assert.Equal(t, "X2fa", goIdentifier("2fa", true))
+	assert.Equal(t, "type_", goIdentifier("type", false))
+	assert.Equal(t, "UserV1Summary", goIdentifier("User_v1.Summary", true))
+}
+
+func TestRenderGoClientBuilds(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if testing.Short() || err != nil {
+		t.Skip("builds the generated client with the go tool")
+	}
+
+	spec, _ := generateTestSpec(t, gatewayTestConfig(), gatewayTestTransactions()...)
+	addGatewayTestSchemas(spec)
+	addListUsersOperation(spec)
+	source, err := RenderGoClient(spec, "shop")
+	require.NoError(t, err)
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.String()+" "+r.Header.Get("X-Tenant")+" "+r.Header.Get("Authorization")+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":7,"name":"Ada"}`))
+		case r.URL.Path == "/users":
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 1, "name": "Ada"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module shop\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "client.go"), source, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "client_test.go"), []byte(`package shop
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	client := NewClient(os.Getenv("SHOP_URL"))
+	client.Header.Set("Authorization", "Bearer token")
+	ctx := context.Background()
+
+	created, err := client.PostUsers(ctx, &User{Name: "Ada"})
+	if err != nil || created.Id != 7 {
+		t.Fatalf("PostUsers: %+v, %v", created, err)
+	}
+	users, err := client.ListUsers(ctx, &ListUsersParams{Page: "2", XTenant: "acme"})
+	if err != nil || len(users) != 1 || users[0].Name != "Ada" {
+		t.Fatalf("ListUsers: %+v, %v", users, err)
+	}
+	var apiErr *Error
+	if _, err := client.GetUsersByUserId(ctx, 3); !errors.As(err, &apiErr) || apiErr.StatusCode != 404 {
+		t.Fatalf("GetUsersByUserId: %v", err)
+	}
+}
+`), 0644))
+
+	cmd := exec.Command(goTool, "test", "-count=1", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "SHOP_URL="+server.URL)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	assert.Equal(t, []string{
+		`POST /users  Bearer token {"id":0,"name":"Ada"}`,
+		"GET /users?page=2 acme Bearer token ",
+		"GET /users/3  Bearer token ",
+	}, requests)
+}
@@ -0,0 +1,159 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// OperationHook is called with every generated operation, e.g. to add organization-specific
+// extensions or rename operationIds. It may modify the operation in place.
+type OperationHook func(method, path string, operation *openapi3.Operation) error
+
+// SchemaHook is called with every generated schema: the component schemas under their names, and
+// the inline request and response body schemas with an empty name. It may modify the schema in
+// place.
+type SchemaHook func(name string, schema *openapi3.Schema) error
+
+// SpecHook is called with the complete document before it is returned. It may modify the
+// document in place.
+type SpecHook func(doc *OpenAPISpec) error
+
+// generationHooks holds the callbacks registered on a generator
+type generationHooks struct {
+	operations []OperationHook
+	schemas    []SchemaHook
+	specs      []SpecHook
+}
+
+// OnOperation registers a hook called with every operation of the generated documents. Hooks run
+// in the order they were registered, after the operations were named, tagged, and described, so
+// that tags they add are declared too.
+func (g *OpenAPIGenerator) OnOperation(hook OperationHook) {
+	g.generating.Lock()
+	defer g.generating.Unlock()
+	g.hooks.operations = append(g.hooks.operations, hook)
+}
+
+// OnSchema registers a hook called with every schema of the generated documents, after the
+// shared components were extracted and before the operation hooks run
+func (g *OpenAPIGenerator) OnSchema(hook SchemaHook) {
+	g.generating.Lock()
+	defer g.generating.Unlock()
+	g.hooks.schemas = append(g.hooks.schemas, hook)
+}
+
+// OnSpecComplete registers a hook called with every generated document once it is complete
+func (g *OpenAPIGenerator) OnSpecComplete(hook SpecHook) {
+	g.generating.Lock()
+	defer g.generating.Unlock()
+	g.hooks.specs = append(g.hooks.specs, hook)
+}
+
+// runSchemaHooks passes the component schemas, in name order, and then the inline body schemas
+// of the operations to the schema hooks
+func (h *generationHooks) runSchemaHooks(doc *OpenAPISpec) error {
+	if len(h.schemas) == 0 {
+		return nil
+	}
+
+	if doc.Components != nil {
+		names := make([]string, 0, len(doc.Components.Schemas))
+		for name := range doc.Components.Schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if ref := doc.Components.Schemas[name]; ref != nil && ref.Value != nil {
+				if err := h.callSchemaHooks(name, ref.Value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return eachOperation(doc, func(method, path string, op *openapi3.Operation) error {
+		var contents []openapi3.Content
+		if op.RequestBody != nil && op.RequestBody.Ref == "" && op.RequestBody.Value != nil {
+			contents = append(contents, op.RequestBody.Value.Content)
+		}
+		if op.Responses != nil {
+			statuses := make([]string, 0, op.Responses.Len())
+			for status := range op.Responses.Map() {
+				statuses = append(statuses, status)
+			}
+			sort.Strings(statuses)
+			for _, status := range statuses {
+				if resp := op.Responses.Value(status); resp != nil && resp.Ref == "" && resp.Value != nil {
+					contents = append(contents, resp.Value.Content)
+				}
+			}
+		}
+
+		for _, content := range contents {
+			for _, mediaType := range sortedMediaTypes(content) {
+				schema := content[mediaType].Schema
+				if schema == nil || schema.Ref != "" || schema.Value == nil {
+					continue
+				}
+				if err := h.callSchemaHooks("", schema.Value); err != nil {
+					return fmt.Errorf("%s %s: %w", method, path, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// callSchemaHooks passes one schema to every schema hook
+func (h *generationHooks) callSchemaHooks(name string, schema *openapi3.Schema) error {
+	for _, hook := range h.schemas {
+		if err := hook(name, schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runOperationHooks passes every operation, in path order, to the operation hooks
+func (h *generationHooks) runOperationHooks(doc *OpenAPISpec) error {
+	if len(h.operations) == 0 {
+		return nil
+	}
+	return eachOperation(doc, func(method, path string, op *openapi3.Operation) error {
+		for _, hook := range h.operations {
+			if err := hook(method, path, op); err != nil {
+				return fmt.Errorf("%s %s: %w", method, path, err)
+			}
+		}
+		return nil
+	})
+}
+
+// runSpecHooks passes the complete document to the spec hooks
+func (h *generationHooks) runSpecHooks(doc *OpenAPISpec) error {
+	for _, hook := range h.specs {
+		if err := hook(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eachOperation calls fn with every operation of a document, paths in sorted order
+func eachOperation(doc *OpenAPISpec, fn func(method, path string, op *openapi3.Operation) error) error {
+	paths := doc.Paths.InMatchingOrder()
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathItem := doc.Paths.Value(path)
+		for _, method := range operationMethods {
+			if op := operationFor(pathItem, method); op != nil {
+				if err := fn(method, path, op); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerationHooks(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, []byte(`[{"id":"__integer__","name":"__string__"}]`), 200))
+	generator.AddTransaction(createTestTransaction("POST", "/users", []byte(`{"name":"__string__"}`), []byte(`{"id":"__integer__","name":"__string__"}`), 201))
+	generator.AddTransaction(createTestTransaction("GET", "/status", nil, []byte(`{"healthy":"__boolean__"}`), 200))
+
+	var calls []string
+	generator.OnSchema(func(name string, schema *openapi3.Schema) error {
+		calls = append(calls, "schema")
+		if schema.Extensions == nil {
+			schema.Extensions = make(map[string]interface{})
+		}
+		schema.Extensions["x-reviewed"] = true
+		return nil
+	})
+	generator.OnOperation(func(method, path string, op *openapi3.Operation) error {
+		calls = append(calls, "operation "+method+" "+path)
+		op.OperationID = "acme_" + op.OperationID
+		op.Tags = append(op.Tags, "Acme")
+		return nil
+	})
+	generator.OnSpecComplete(func(doc *OpenAPISpec) error {
+		calls = append(calls, "spec")
+		doc.Info.Title = strings.ToUpper(doc.Info.Title)
+		return nil
+	})
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	assert.Equal(t, "TEST API", spec.Info.Title)
+	assert.Equal(t, "spec", calls[len(calls)-1])
+	assert.Contains(t, calls, "operation GET /users")
+	assert.Contains(t, calls, "operation POST /users")
+
+	get := spec.Paths.Find("/users").Get
+	assert.True(t, strings.HasPrefix(get.OperationID, "acme_"))
+
+	// Tags added by operation hooks are declared
+	assert.NotNil(t, spec.Tags.Get("Acme"))
+
+	// Inline body schemas are passed to the schema hooks too
+	status := spec.Paths.Find("/status").Get
+	schema := status.Responses.Status(200).Value.Content["application/json"].Schema
+	require.Empty(t, schema.Ref)
+	assert.Equal(t, true, schema.Value.Extensions["x-reviewed"])
+	for _, ref := range spec.Components.Schemas {
+		assert.Equal(t, true, ref.Value.Extensions["x-reviewed"])
+	}
+}
+
+func TestGenerationHookErrors(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, []byte(`{"id":"__integer__"}`), 200))
+
+	errMissingOwner := errors.New("missing owner")
+	generator.OnOperation(func(method, path string, op *openapi3.Operation) error {
+		return errMissingOwner
+	})
+
+	_, err := generator.GenerateSpec()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errMissingOwner)
+	assert.Contains(t, err.Error(), "GET /users")
+}
@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"strings"
+)
+
+// redocScriptURL is the Redoc bundle loaded by HTML output that doesn't inline one
+const redocScriptURL = "https://cdn.redoc.ly/redoc/v2.1.5/bundles/redoc.standalone.js"
+
+// RenderHTML renders a document as a standalone HTML page that displays it with Redoc. The
+// document is embedded in the page, so no server is needed to browse it. The Redoc bundle
+// (redoc.standalone.js) is inlined when given, which makes the page work offline, and is
+// loaded from the Redoc CDN otherwise.
+func RenderHTML(spec *OpenAPISpec, bundle []byte) ([]byte, error) {
+	// json.Marshal escapes <, >, and & so the document can't close the script element
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	title := "API Documentation"
+	if spec.Info != nil && spec.Info.Title != "" {
+		title = spec.Info.Title
+	}
+
+	var page bytes.Buffer
+	page.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	page.WriteString("  <meta charset=\"utf-8\">\n")
+	page.WriteString("  <meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	page.WriteString("  <title>" + html.EscapeString(title) + "</title>\n")
+	page.WriteString("  <style>body { margin: 0; padding: 0; }</style>\n")
+	page.WriteString("</head>\n<body>\n")
+	page.WriteString("  <div id=\"redoc\"></div>\n")
+	page.WriteString("  <script id=\"openapi-spec\" type=\"application/json\">")
+	page.Write(specJSON)
+	page.WriteString("</script>\n")
+
+	if len(bundle) > 0 {
+		page.WriteString("  <script>")
+		page.WriteString(strings.ReplaceAll(string(bundle), "</script", "<\\/script"))
+		page.WriteString("</script>\n")
+	} else {
+		page.WriteString("  <script src=\"" + redocScriptURL + "\"></script>\n")
+	}
+
+	page.WriteString("  <script>\n")
+	page.WriteString("    var spec = JSON.parse(document.getElementById(\"openapi-spec\").textContent);\n")
+	page.WriteString("    Redoc.init(spec, {}, document.getElementById(\"redoc\"));\n")
+	page.WriteString("  </script>\n")
+	page.WriteString("</body>\n</html>\n")
+
+	return page.Bytes(), nil
+}
@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTML(t *testing.T) {
+	spec := &OpenAPISpec{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       "Orders & <Billing>",
+			Description: "Closing tags like </script> must not end the embedded document",
+			Version:     "1.0.0",
+		},
+		Paths: openapi3.NewPaths(),
+	}
+
+	page, err := RenderHTML(spec, nil)
+	require.NoError(t, err)
+	html := string(page)
+
+	assert.Contains(t, html, "<title>Orders &amp; &lt;Billing&gt;</title>")
+	assert.Contains(t, html, `<script src="`+redocScriptURL+`"></script>`)
+	assert.Equal(t, 3, strings.Count(html, "</script>"))
+
+	// An inlined bundle replaces the CDN script
+	page, err = RenderHTML(spec, []byte(`var Redoc = {}; // "</script>"`))
+	require.NoError(t, err)
+	html = string(page)
+
+	assert.NotContains(t, html, redocScriptURL)
+	assert.Contains(t, html, `var Redoc = {}; // "<\/script>"`)
+	assert.Equal(t, 3, strings.Count(html, "</script>"))
+}
+
+func TestFormatForPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "swagger.json", expected: FormatJSON},
+		{path: "openapi.YAML", expected: FormatYAML},
+		{path: "openapi.yml", expected: FormatYAML},
+		{path: "docs/index.html", expected: FormatHTML},
+		{path: "docs/API.md", expected: FormatMarkdown},
+		{path: "spec", expected: FormatJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatForPath(tt.path))
+		})
+	}
+
+	_, err := MarshalSpecFormat(&OpenAPISpec{}, "xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Resource IDs of the Insomnia workspace and its base environment
+const (
+	insomniaWorkspaceID   = "wrk_swagdoc"
+	insomniaEnvironmentID = "env_swagdoc"
+)
+
+// insomniaExport is an Insomnia v4 export file
+type insomniaExport struct {
+	Type         string             `json:"_type"`
+	ExportFormat int                `json:"__export_format"`
+	ExportSource string             `json:"__export_source"`
+	Resources    []insomniaResource `json:"resources"`
+}
+
+// insomniaResource is a workspace, environment, folder (request_group), or request
+type insomniaResource struct {
+	ID             string                 `json:"_id"`
+	Type           string                 `json:"_type"`
+	ParentID       string                 `json:"parentId,omitempty"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description,omitempty"`
+	Scope          string                 `json:"scope,omitempty"`
+	Data           map[string]string      `json:"data,omitempty"`
+	Method         string                 `json:"method,omitempty"`
+	URL            string                 `json:"url,omitempty"`
+	Body           *insomniaBody          `json:"body,omitempty"`
+	Parameters     []insomniaParameter    `json:"parameters,omitempty"`
+	Headers        []insomniaParameter    `json:"headers,omitempty"`
+	Authentication map[string]interface{} `json:"authentication,omitempty"`
+	MetaSortKey    int                    `json:"metaSortKey,omitempty"`
+}
+
+// insomniaBody is a request body
+type insomniaBody struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// insomniaParameter is a query parameter or header, disabled when it is optional
+type insomniaParameter struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// RenderInsomnia converts a document to an Insomnia v4 export with a folder per tag. The first
+// server becomes the base_url variable of the base environment, and path parameters become
+// environment variables holding their example values.
+func RenderInsomnia(spec *OpenAPISpec) ([]byte, error) {
+	workspace := insomniaResource{
+		ID:    insomniaWorkspaceID,
+		Type:  "workspace",
+		Name:  "API Documentation",
+		Scope: "collection",
+	}
+	if spec.Info != nil {
+		workspace.Name = spec.Info.Title
+		workspace.Description = spec.Info.Description
+	}
+	environment := insomniaResource{
+		ID:       insomniaEnvironmentID,
+		Type:     "environment",
+		ParentID: insomniaWorkspaceID,
+		Name:     "Base Environment",
+		Data:     map[string]string{"base_url": serverBaseURL(spec)},
+	}
+
+	var resources []insomniaResource
+	tags := operationsByTag(spec)
+	requestCount := 0
+	for folderIndex, tag := range sortedTags(tags) {
+		folder := insomniaResource{
+			ID:          fmt.Sprintf("fld_swagdoc_%d", folderIndex+1),
+			Type:        "request_group",
+			ParentID:    insomniaWorkspaceID,
+			Name:        tag,
+			MetaSortKey: folderIndex + 1,
+		}
+		if t := spec.Tags.Get(tag); t != nil {
+			folder.Description = t.Description
+		}
+		resources = append(resources, folder)
+
+		for i, operation := range tags[tag] {
+			requestCount++
+			example := newExportRequest(spec, operation)
+			request := insomniaRequest(example)
+			request.ID = fmt.Sprintf("req_swagdoc_%d", requestCount)
+			request.ParentID = folder.ID
+			request.MetaSortKey = i + 1
+			resources = append(resources, request)
+
+			// Path parameters are environment variables, keeping the first example of each name
+			for _, param := range example.pathParams {
+				if _, ok := environment.Data[param.name]; !ok {
+					environment.Data[param.name] = param.value
+				}
+			}
+		}
+	}
+
+	export := insomniaExport{
+		Type:         "export",
+		ExportFormat: 4,
+		ExportSource: "swagdoc",
+		Resources:    append([]insomniaResource{workspace, environment}, resources...),
+	}
+	return json.MarshalIndent(export, "", "  ")
+}
+
+// insomniaRequest converts an example request to an Insomnia request
+func insomniaRequest(example exportRequest) insomniaResource {
+	request := insomniaResource{
+		Type:           "request",
+		Name:           example.name,
+		Description:    example.description,
+		Method:         example.method,
+		URL:            "{{ _.base_url }}" + example.templatedPath(insomniaTemplate),
+		Authentication: map[string]interface{}{},
+	}
+
+	for _, param := range example.query {
+		request.Parameters = append(request.Parameters, insomniaParameter{
+			Name:        param.name,
+			Value:       param.value,
+			Description: param.description,
+			Disabled:    !param.required,
+		})
+	}
+	for _, header := range example.headers {
+		request.Headers = append(request.Headers, insomniaParameter{
+			Name:  header[0],
+			Value: credentialPlaceholder(header[1], insomniaTemplate),
+		})
+	}
+	if example.body != "" {
+		request.Body = &insomniaBody{MimeType: example.mediaType, Text: example.body}
+	}
+	return request
+}
+
+// insomniaTemplate references an Insomnia environment variable
+func insomniaTemplate(name string) string {
+	return "{{ _." + name + " }}"
+}
@@ -0,0 +1,60 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderInsomnia(t *testing.T) {
+	spec, _ := generateTestSpec(t, shopTestConfig(), exportTestTransactions()...)
+	data, err := RenderInsomnia(spec)
+	require.NoError(t, err)
+
+	var export insomniaExport
+	require.NoError(t, json.Unmarshal(data, &export))
+	assert.Equal(t, "export", export.Type)
+	assert.Equal(t, 4, export.ExportFormat)
+
+	byType := make(map[string][]insomniaResource)
+	for _, resource := range export.Resources {
+		byType[resource.Type] = append(byType[resource.Type], resource)
+	}
+
+	require.Len(t, byType["workspace"], 1)
+	assert.Equal(t, "Shop API", byType["workspace"][0].Name)
+
+	require.Len(t, byType["environment"], 1)
+	environment := byType["environment"][0]
+	assert.Equal(t, insomniaWorkspaceID, environment.ParentID)
+	assert.Equal(t, "https://api.example.com", environment.Data["base_url"])
+	assert.NotEmpty(t, environment.Data["userId"])
+
+	require.Len(t, byType["request_group"], 2)
+	folders := make(map[string]string)
+	for _, folder := range byType["request_group"] {
+		folders[folder.ID] = folder.Name
+	}
+
+	require.Len(t, byType["request"], 3)
+	urls := make(map[string]insomniaResource)
+	for _, request := range byType["request"] {
+		urls[request.Method+" "+request.URL] = request
+	}
+
+	get, ok := urls["GET {{ _.base_url }}/users/{{ _.userId }}"]
+	require.True(t, ok)
+	assert.Equal(t, "Users", folders[get.ParentID])
+
+	create := urls["POST {{ _.base_url }}/users"]
+	require.NotNil(t, create.Body)
+	assert.Equal(t, "application/json", create.Body.MimeType)
+	assert.JSONEq(t, `{"name":"string"}`, create.Body.Text)
+
+	list := urls["GET {{ _.base_url }}/orders"]
+	require.Len(t, list.Parameters, 1)
+	assert.Equal(t, "page", list.Parameters[0].Name)
+	assert.True(t, list.Parameters[0].Disabled)
+}
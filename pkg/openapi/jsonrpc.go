@@ -0,0 +1,230 @@
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// rpcObservations tracks the JSON-RPC 2.0 calls sent to an endpoint
+type rpcObservations struct {
+	single  bool                         // a request carried one call
+	batch   bool                         // a request carried an array of calls
+	methods map[string]*rpcMethodSamples // method name -> samples
+}
+
+// rpcMethodSamples holds the decoded params and results of the calls of one JSON-RPC method
+type rpcMethodSamples struct {
+	params  []interface{}
+	results []interface{}
+}
+
+// newRPCObservations creates an empty JSON-RPC call tracker
+func newRPCObservations() *rpcObservations {
+	return &rpcObservations{methods: make(map[string]*rpcMethodSamples)}
+}
+
+// add records the calls of one transaction. The results of a batch are matched to its calls by
+// position, as the sanitized ids can't be compared.
+func (o *rpcObservations) add(calls []map[string]interface{}, batch bool, responses []map[string]interface{}) {
+	if batch {
+		o.batch = true
+	} else {
+		o.single = true
+	}
+	for i, call := range calls {
+		name, ok := call["method"].(string)
+		if !ok || name == "__string__" {
+			continue
+		}
+		samples := o.methods[name]
+		if samples == nil {
+			samples = &rpcMethodSamples{}
+			o.methods[name] = samples
+		}
+		if params, ok := call["params"]; ok {
+			samples.params = append(samples.params, params)
+		}
+		if len(responses) == len(calls) {
+			if result, ok := responses[i]["result"]; ok {
+				samples.results = append(samples.results, result)
+			}
+		}
+	}
+}
+
+// merge adds the observations of another request path of the same endpoint
+func (o *rpcObservations) merge(other *rpcObservations) {
+	o.single = o.single || other.single
+	o.batch = o.batch || other.batch
+	for name, samples := range other.methods {
+		if o.methods[name] == nil {
+			o.methods[name] = &rpcMethodSamples{}
+		}
+		o.methods[name].params = append(o.methods[name].params, samples.params...)
+		o.methods[name].results = append(o.methods[name].results, samples.results...)
+	}
+}
+
+// sortedMethods returns the observed method names in sorted order
+func (o *rpcObservations) sortedMethods() []string {
+	names := make([]string, 0, len(o.methods))
+	for name := range o.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decodeJSONRPC decodes a JSON-RPC 2.0 message or batch of messages, reporting whether it was
+// a batch. It returns false for other bodies.
+func decodeJSONRPC(body []byte) ([]map[string]interface{}, bool, bool) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, false, false
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v["jsonrpc"] != "2.0" {
+			return nil, false, false
+		}
+		return []map[string]interface{}{v}, false, true
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, false, false
+		}
+		messages := make([]map[string]interface{}, 0, len(v))
+		for _, item := range v {
+			message, ok := item.(map[string]interface{})
+			if !ok || message["jsonrpc"] != "2.0" {
+				return nil, false, false
+			}
+			messages = append(messages, message)
+		}
+		return messages, true, true
+	default:
+		return nil, false, false
+	}
+}
+
+// requestRPCCalls returns the JSON-RPC observations of transactions by method and request path.
+// It runs before the response strategy, which keeps only one call of most endpoints.
+func requestRPCCalls(transactions []proxy.APITransaction) map[string]*rpcObservations {
+	observations := make(map[string]*rpcObservations)
+	for _, tx := range transactions {
+		calls, batch, ok := decodeJSONRPC(tx.Request.Body)
+		if !ok || calls[0]["method"] == nil {
+			continue
+		}
+		responses, _, _ := decodeJSONRPC(tx.Response.Body)
+
+		key := tx.Request.Method + " " + tx.Request.Path
+		if observations[key] == nil {
+			observations[key] = newRPCObservations()
+		}
+		observations[key].add(calls, batch, responses)
+	}
+	return observations
+}
+
+// applyJSONRPC documents the request body of JSON-RPC endpoints as the JSON-RPC 2.0 envelope,
+// or an array of envelopes for batches, and lists every observed method with the schemas of
+// its params and result in an x-jsonrpc-methods extension
+func (g *OpenAPIGenerator) applyJSONRPC(doc *OpenAPISpec, observations map[string]*rpcObservations) {
+	for key, observed := range observations {
+		op := operationForKey(doc, key)
+		if op == nil {
+			continue
+		}
+
+		methods := observed.sortedMethods()
+		var params []interface{}
+		var documented []interface{}
+		for _, name := range methods {
+			samples := observed.methods[name]
+			params = append(params, samples.params...)
+
+			method := map[string]interface{}{"method": name}
+			if schema := g.sampleSchema(samples.params); schema != nil {
+				method["params"] = schema
+			}
+			if schema := g.sampleSchema(samples.results); schema != nil {
+				method["result"] = schema
+			}
+			documented = append(documented, method)
+		}
+		if len(documented) > 0 {
+			setOperationExtension(op, "x-jsonrpc-methods", documented)
+		}
+
+		envelope := rpcEnvelopeSchema(methods, g.sampleSchema(params))
+		var schema *openapi3.Schema
+		switch {
+		case observed.batch && observed.single:
+			schema = &openapi3.Schema{OneOf: openapi3.SchemaRefs{
+				envelope.NewRef(),
+				openapi3.NewArraySchema().WithItems(envelope).NewRef(),
+			}}
+		case observed.batch:
+			schema = openapi3.NewArraySchema().WithItems(envelope)
+			schema.Description = "Batch of JSON-RPC 2.0 calls"
+		default:
+			schema = envelope
+		}
+
+		op.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().
+				WithRequired(true).
+				WithJSONSchema(schema),
+		}
+	}
+}
+
+// rpcEnvelopeSchema builds the schema of a JSON-RPC 2.0 call of one of the given methods
+func rpcEnvelopeSchema(methods []string, params *openapi3.Schema) *openapi3.Schema {
+	version := openapi3.NewStringSchema().WithEnum("2.0")
+	version.Description = "JSON-RPC protocol version"
+
+	method := openapi3.NewStringSchema()
+	method.Description = "Name of the method to call, see x-jsonrpc-methods"
+	for _, name := range methods {
+		method.Enum = append(method.Enum, name)
+	}
+
+	id := &openapi3.Schema{OneOf: openapi3.SchemaRefs{
+		openapi3.NewStringSchema().NewRef(),
+		openapi3.NewIntegerSchema().NewRef(),
+	}}
+	id.Description = "Identifier echoed in the response; notifications, which get no response, omit it"
+
+	envelope := openapi3.NewObjectSchema().
+		WithProperty("jsonrpc", version).
+		WithProperty("method", method).
+		WithProperty("id", id)
+	if params != nil {
+		params.Description = "Parameters of the method, by name or by position"
+		envelope.WithProperty("params", params)
+	}
+	envelope.Required = []string{"jsonrpc", "method"}
+	envelope.Description = "JSON-RPC 2.0 call"
+	return envelope
+}
+
+// sampleSchema infers the schema of decoded JSON samples, or returns nil when there are none
+func (g *OpenAPIGenerator) sampleSchema(samples []interface{}) *openapi3.Schema {
+	var schemas []parser.Schema
+	for _, sample := range samples {
+		if schema, err := g.parseJSONBody(sample); err == nil && schema != nil {
+			schemas = append(schemas, *schema)
+		}
+	}
+	if len(schemas) == 0 {
+		return nil
+	}
+	return toOpenAPISchema(g.refineSchema(parser.MergeSchemaList(schemas), samples))
+}
@@ -0,0 +1,55 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecJSONRPC(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "RPC API", Version: "1.0.0", ResponseStrategy: StrategyBestSuccess})
+	generator.AddTransaction(createTestTransaction("POST", "/rpc",
+		[]byte(`{"jsonrpc":"2.0","method":"user.get","params":{"id":"__integer__"},"id":"__integer__"}`),
+		[]byte(`{"jsonrpc":"2.0","result":{"name":"__string__"},"id":"__integer__"}`), 200))
+	generator.AddTransaction(createTestTransaction("POST", "/rpc",
+		[]byte(`[{"jsonrpc":"2.0","method":"user.delete","params":["__integer__"],"id":"__integer__"},{"jsonrpc":"2.0","method":"audit.log","params":{"event":"__string__"}}]`),
+		[]byte(`[{"jsonrpc":"2.0","result":"__boolean__","id":"__integer__"}]`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	op := spec.Paths.Find("/rpc").Post
+	require.NotNil(t, op)
+
+	// Single calls and batches were both captured
+	body := op.RequestBody.Value.Content.Get("application/json").Schema.Value
+	require.Len(t, body.OneOf, 2)
+	envelope := body.OneOf[0].Value
+	assert.Equal(t, []string{"jsonrpc", "method"}, envelope.Required)
+	assert.Equal(t, []interface{}{"2.0"}, envelope.Properties["jsonrpc"].Value.Enum)
+	assert.Equal(t, []interface{}{"audit.log", "user.delete", "user.get"}, envelope.Properties["method"].Value.Enum)
+	assert.Len(t, envelope.Properties["id"].Value.OneOf, 2)
+	assert.True(t, body.OneOf[1].Value.Type.Is("array"))
+
+	methods, ok := op.Extensions["x-jsonrpc-methods"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, methods, 3)
+
+	get := methods[2].(map[string]interface{})
+	assert.Equal(t, "user.get", get["method"])
+	require.Contains(t, get, "result")
+	assert.Contains(t, get["result"].(*openapi3.Schema).Properties, "name")
+
+	// The results of a batch whose responses don't line up with its calls are left out
+	remove := methods[1].(map[string]interface{})
+	assert.Equal(t, "user.delete", remove["method"])
+	assert.True(t, remove["params"].(*openapi3.Schema).Type.Is("array"))
+	assert.NotContains(t, remove, "result")
+
+	require.NoError(t, ValidateSpec(spec))
+	data, err := MarshalSpecFormat(spec, "yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "x-jsonrpc-methods:")
+}
@@ -0,0 +1,142 @@
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// jsonSchemaDialect is the JSON Schema version of emitted schema files
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// openAPIOnlyKeywords are schema keywords of OpenAPI 3.0 that JSON Schema does not define
+var openAPIOnlyKeywords = []string{"discriminator", "xml", "externalDocs"}
+
+// RenderJSONSchemas converts every component schema of a document to a standalone JSON Schema
+// (draft 2020-12) file named after it, e.g. User.json. References between components point at
+// the sibling files. It returns the file contents by file name.
+func RenderJSONSchemas(spec *OpenAPISpec) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if spec.Components == nil {
+		return files, nil
+	}
+
+	names := make([]string, 0, len(spec.Components.Schemas))
+	for name := range spec.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ref := spec.Components.Schemas[name]
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+
+		// Round-trip through JSON to work on plain keywords instead of the typed schema
+		data, err := json.Marshal(ref.Value)
+		if err != nil {
+			return nil, err
+		}
+		var schema map[string]interface{}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return nil, err
+		}
+		convertToJSONSchema(schema)
+
+		schema["$schema"] = jsonSchemaDialect
+		schema["$id"] = name + ".json"
+		if _, ok := schema["title"]; !ok {
+			schema["title"] = name
+		}
+
+		if files[name+".json"], err = json.MarshalIndent(schema, "", "  "); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// WriteJSONSchemas writes the component schemas of a document into a directory as JSON Schema files
+func WriteJSONSchemas(spec *OpenAPISpec, dir string) error {
+	files, err := RenderJSONSchemas(spec)
+	if err != nil {
+		return err
+	}
+	return writeFiles(dir, files)
+}
+
+// convertToJSONSchema rewrites the OpenAPI 3.0 keywords of a schema and its subschemas in place,
+// with component references pointing at the sibling files
+func convertToJSONSchema(schema map[string]interface{}) {
+	convertSchema(schema, func(name string) string { return name + ".json" })
+}
+
+// convertSchema rewrites the OpenAPI 3.0 keywords of a schema and its subschemas in place, with
+// component references rewritten by ref
+func convertSchema(schema map[string]interface{}, ref func(name string) string) {
+	if target, ok := schema["$ref"].(string); ok {
+		schema["$ref"] = ref(strings.TrimPrefix(target, "#/components/schemas/"))
+	}
+
+	// nullable is expressed as a null type
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		switch t := schema["type"].(type) {
+		case string:
+			schema["type"] = []interface{}{t, "null"}
+		case []interface{}:
+			schema["type"] = append(t, "null")
+		}
+	}
+	delete(schema, "nullable")
+
+	// A single example becomes the examples array
+	if example, ok := schema["example"]; ok {
+		schema["examples"] = []interface{}{example}
+		delete(schema, "example")
+	}
+
+	// Exclusive bounds are numbers instead of flags on minimum and maximum
+	for _, bound := range []string{"Minimum", "Maximum"} {
+		exclusiveKey, key := "exclusive"+bound, strings.ToLower(bound)
+		if exclusive, ok := schema[exclusiveKey].(bool); ok {
+			delete(schema, exclusiveKey)
+			if value, ok := schema[key]; ok && exclusive {
+				schema[exclusiveKey] = value
+				delete(schema, key)
+			}
+		}
+	}
+
+	for _, keyword := range openAPIOnlyKeywords {
+		delete(schema, keyword)
+	}
+	for key := range schema {
+		if strings.HasPrefix(key, "x-") {
+			delete(schema, key)
+		}
+	}
+
+	// Recurse into subschemas
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		if sub, ok := schema[key].(map[string]interface{}); ok {
+			convertSchema(sub, ref)
+		}
+	}
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		for _, prop := range properties {
+			if sub, ok := prop.(map[string]interface{}); ok {
+				convertSchema(sub, ref)
+			}
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if variants, ok := schema[key].([]interface{}); ok {
+			for _, variant := range variants {
+				if sub, ok := variant.(map[string]interface{}); ok {
+					convertSchema(sub, ref)
+				}
+			}
+		}
+	}
+}
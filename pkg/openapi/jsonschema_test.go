@@ -0,0 +1,60 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertToJSONSchema(t *testing.T) {
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"x-internal": true,
+		"discriminator": {"propertyName": "kind"},
+		"properties": {
+			"owner": {"$ref": "#/components/schemas/User"},
+			"note": {"type": "string", "nullable": true, "example": "hello"},
+			"price": {"type": "number", "minimum": 0, "exclusiveMinimum": true},
+			"tags": {"type": "array", "items": {"type": "string", "nullable": true}}
+		}
+	}`), &schema))
+
+	convertToJSONSchema(schema)
+
+	expected := `{
+		"type": "object",
+		"properties": {
+			"owner": {"$ref": "User.json"},
+			"note": {"type": ["string", "null"], "examples": ["hello"]},
+			"price": {"type": "number", "exclusiveMinimum": 0},
+			"tags": {"type": "array", "items": {"type": ["string", "null"]}}
+		}
+	}`
+	actual, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(actual))
+}
+
+func TestWriteJSONSchemas(t *testing.T) {
+	spec, _ := generateTestSpec(t, shopTestConfig(), exportTestTransactions()...)
+	require.NotEmpty(t, spec.Components.Schemas)
+
+	dir := t.TempDir()
+	require.NoError(t, WriteJSONSchemas(spec, dir))
+
+	for name := range spec.Components.Schemas {
+		data, err := os.ReadFile(filepath.Join(dir, name+".json"))
+		require.NoError(t, err, name)
+
+		var schema map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &schema))
+		assert.Equal(t, jsonSchemaDialect, schema["$schema"])
+		assert.Equal(t, name+".json", schema["$id"])
+		assert.Equal(t, name, schema["title"])
+	}
+}
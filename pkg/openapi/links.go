@@ -0,0 +1,127 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// addLinks connects operations whose successful responses return the values other operations
+// need as path parameters, e.g. the id returned by POST /users is the userId of
+// GET /users/{userId}. Captured values are sanitized, so the relationships come from property
+// and parameter names rather than matching values.
+func addLinks(doc *OpenAPISpec) {
+	paths := doc.Paths.InMatchingOrder()
+	sort.Strings(paths)
+
+	for _, source := range paths {
+		sourceItem := doc.Paths.Value(source)
+		for _, method := range operationMethods {
+			op := operationFor(sourceItem, method)
+			if op == nil || op.Responses == nil {
+				continue
+			}
+
+			for status, responseRef := range op.Responses.Map() {
+				response := responseRef.Value
+				if !strings.HasPrefix(status, "2") || response == nil {
+					continue
+				}
+				properties := responseProperties(response)
+				if len(properties) == 0 {
+					continue
+				}
+
+				for _, target := range paths {
+					if target == source {
+						continue
+					}
+					parameters, description, ok := linkParameters(source, target, properties)
+					if !ok {
+						continue
+					}
+
+					targetItem := doc.Paths.Value(target)
+					for _, targetMethod := range operationMethods {
+						targetOp := operationFor(targetItem, targetMethod)
+						if targetOp == nil || targetOp.OperationID == "" {
+							continue
+						}
+						if response.Links == nil {
+							response.Links = openapi3.Links{}
+						}
+						response.Links[targetOp.OperationID] = &openapi3.LinkRef{
+							Value: &openapi3.Link{
+								OperationID: targetOp.OperationID,
+								Parameters:  parameters,
+								Description: fmt.Sprintf("Call `%s %s` with %s.", targetMethod, target, description),
+							},
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// responseProperties returns the top-level properties of a response's JSON object body
+func responseProperties(response *openapi3.Response) openapi3.Schemas {
+	for _, mediaType := range sortedMediaTypes(response.Content) {
+		media := response.Content[mediaType]
+		if isJSONContentType(mediaType) && isObjectMedia(media) {
+			return media.Schema.Value.Properties
+		}
+	}
+	return nil
+}
+
+// linkParameters resolves every path parameter of a target path from the source operation:
+// parameters shared with the source path come from its request, and the others from response
+// properties of the same name. The id of a created or fetched item fills the trailing
+// parameter of the item path under the source, e.g. /users -> /users/{userId}. At least one
+// parameter has to come from the response for the link to say anything.
+func linkParameters(source, target string, properties openapi3.Schemas) (map[string]interface{}, string, bool) {
+	sourceParams := make(map[string]bool)
+	for _, name := range pathParamNames(source) {
+		sourceParams[name] = true
+	}
+
+	targetParams := pathParamNames(target)
+	if len(targetParams) == 0 {
+		return nil, "", false
+	}
+
+	parameters := make(map[string]interface{})
+	var fromBody []string
+	for i, name := range targetParams {
+		switch {
+		case sourceParams[name]:
+			parameters[name] = "$request.path." + name
+		case properties[name] != nil:
+			parameters[name] = "$response.body#/" + name
+			fromBody = append(fromBody, fmt.Sprintf("`%s` from the response body", name))
+		case i == len(targetParams)-1 && target == source+"/{"+name+"}" && properties["id"] != nil:
+			parameters[name] = "$response.body#/id"
+			fromBody = append(fromBody, fmt.Sprintf("`%s` set to the `id` from the response body", name))
+		default:
+			return nil, "", false
+		}
+	}
+	if len(fromBody) == 0 {
+		return nil, "", false
+	}
+	return parameters, strings.Join(fromBody, " and "), true
+}
+
+// pathParamNames returns the names of the path parameters of a templated path, in order
+func pathParamNames(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if isPathParam(segment) {
+			names = append(names, strings.Trim(segment, "{}"))
+		}
+	}
+	return names
+}
@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecLinks(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(createTestTransaction("POST", "/users", []byte(`{"name":"__string__"}`), []byte(`{"id":"__integer__","name":"__string__"}`), 201))
+	generator.AddTransaction(createTestTransaction("GET", "/users/1", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/users/2", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200))
+	generator.AddTransaction(createTestTransaction("DELETE", "/users/3", nil, nil, 204))
+	generator.AddTransaction(createTestTransaction("GET", "/orders/7", nil, []byte(`{"id":"__integer__","userId":"__integer__"}`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/orders/8", nil, []byte(`{"id":"__integer__","userId":"__integer__"}`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+	require.NoError(t, ValidateSpec(spec))
+
+	get := spec.Paths.Find("/users/{userId}").Get
+	remove := spec.Paths.Find("/users/{userId}").Delete
+	require.NotNil(t, get)
+	require.NotNil(t, remove)
+
+	// The created id addresses the new user
+	created := spec.Paths.Find("/users").Post.Responses.Value("201").Value
+	require.Contains(t, created.Links, get.OperationID)
+	link := created.Links[get.OperationID].Value
+	assert.Equal(t, get.OperationID, link.OperationID)
+	assert.Equal(t, map[string]interface{}{"userId": "$response.body#/id"}, link.Parameters)
+	assert.Contains(t, created.Links, remove.OperationID)
+
+	// A property named like a path parameter links to that resource
+	order := spec.Paths.Find("/orders/{orderId}").Get.Responses.Value("200").Value
+	require.Contains(t, order.Links, get.OperationID)
+	assert.Equal(t, map[string]interface{}{"userId": "$response.body#/userId"}, order.Links[get.OperationID].Value.Parameters)
+
+	// Operations on the same item need nothing from the response
+	fetched := get.Responses.Value("200").Value
+	assert.NotContains(t, fetched.Links, remove.OperationID)
+}
+
+func TestLinkParameters(t *testing.T) {
+	properties := openapi3.Schemas{
+		"id":      openapi3.NewIntegerSchema().NewRef(),
+		"orderId": openapi3.NewIntegerSchema().NewRef(),
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		target   string
+		expected map[string]interface{}
+	}{
+		{
+			name:     "created item",
+			source:   "/users/{userId}/orders",
+			target:   "/users/{userId}/orders/{orderId}",
+			expected: map[string]interface{}{"userId": "$request.path.userId", "orderId": "$response.body#/orderId"},
+		},
+		{
+			name:     "id of nested item",
+			source:   "/users/{userId}/carts",
+			target:   "/users/{userId}/carts/{cartId}",
+			expected: map[string]interface{}{"userId": "$request.path.userId", "cartId": "$response.body#/id"},
+		},
+		{
+			name:   "unresolved parameter",
+			source: "/orders",
+			target: "/users/{userId}",
+		},
+		{
+			name:   "nothing from the response",
+			source: "/users/{userId}",
+			target: "/users/{userId}/settings",
+		},
+		{
+			name:   "no path parameters",
+			source: "/users",
+			target: "/health",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameters, _, ok := linkParameters(tt.source, tt.target, properties)
+			assert.Equal(t, tt.expected != nil, ok)
+			assert.Equal(t, tt.expected, parameters)
+		})
+	}
+}
@@ -0,0 +1,397 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// LintSeverity is how serious a lint issue is
+type LintSeverity string
+
+// Lint severities, from most to least serious. Rules set to off are not run
+const (
+	LintError   LintSeverity = "error"
+	LintWarning LintSeverity = "warning"
+	LintInfo    LintSeverity = "info"
+	LintOff     LintSeverity = "off"
+)
+
+// ParseLintSeverity parses a severity name
+func ParseLintSeverity(name string) (LintSeverity, error) {
+	switch severity := LintSeverity(strings.ToLower(name)); severity {
+	case LintError, LintWarning, LintInfo, LintOff:
+		return severity, nil
+	default:
+		return "", fmt.Errorf("unknown severity %q, expected error, warning, info, or off", name)
+	}
+}
+
+// LintRule is a check run against a document
+type LintRule struct {
+	Name        string
+	Description string
+	Severity    LintSeverity // default severity of the issues the rule reports
+	check       func(spec *OpenAPISpec) []LintIssue
+}
+
+// LintIssue is a problem found in a document
+type LintIssue struct {
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	Location string       `json:"location"` // e.g. "GET /users" or "components.schemas.User"
+	Message  string       `json:"message"`
+}
+
+// lintRules is the core ruleset
+var lintRules = []LintRule{
+	{
+		Name:        "operation-operation-id",
+		Description: "Every operation has an operationId, which code generators use as method names",
+		Severity:    LintError,
+		check:       lintOperationIDs,
+	},
+	{
+		Name:        "operation-description",
+		Description: "Every operation has a summary or description",
+		Severity:    LintWarning,
+		check:       lintOperationDescriptions,
+	},
+	{
+		Name:        "operation-tags",
+		Description: "Every operation has at least one tag, so that it is grouped with related operations",
+		Severity:    LintWarning,
+		check:       lintOperationTags,
+	},
+	{
+		Name:        "operation-id-casing",
+		Description: "All operationIds use the same casing, e.g. camelCase",
+		Severity:    LintWarning,
+		check:       lintOperationIDCasing,
+	},
+	{
+		Name:        "property-casing",
+		Description: "All schema properties use the same casing, e.g. camelCase or snake_case",
+		Severity:    LintWarning,
+		check:       lintPropertyCasing,
+	},
+	{
+		Name:        "unused-component",
+		Description: "Every component is referenced, directly or through other components, from the paths",
+		Severity:    LintWarning,
+		check:       lintUnusedComponents,
+	},
+}
+
+// LintRules returns the core ruleset with the default severities
+func LintRules() []LintRule {
+	return append([]LintRule(nil), lintRules...)
+}
+
+// LintSpec checks a document against the core ruleset. Severities override the default
+// severity of rules by name; rules set to off are skipped. Issues are sorted by location
+func LintSpec(spec *OpenAPISpec, severities map[string]LintSeverity) ([]LintIssue, error) {
+	known := make(map[string]bool)
+	for _, rule := range lintRules {
+		known[rule.Name] = true
+	}
+	for name := range severities {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown lint rule %q", name)
+		}
+	}
+
+	var issues []LintIssue
+	for _, rule := range lintRules {
+		severity := rule.Severity
+		if override, ok := severities[rule.Name]; ok {
+			severity = override
+		}
+		if severity == LintOff {
+			continue
+		}
+		for _, issue := range rule.check(spec) {
+			issue.Rule = rule.Name
+			issue.Severity = severity
+			issues = append(issues, issue)
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Location != issues[j].Location {
+			return issues[i].Location < issues[j].Location
+		}
+		return issues[i].Rule < issues[j].Rule
+	})
+	return issues, nil
+}
+
+// lintOperation is an operation with its location
+type lintOperation struct {
+	location string
+	op       *openapi3.Operation
+}
+
+// lintOperations returns the operations of a document sorted by path and method
+func lintOperations(spec *OpenAPISpec) []lintOperation {
+	if spec.Paths == nil {
+		return nil
+	}
+
+	var operations []lintOperation
+	paths := spec.Paths.InMatchingOrder()
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathItem := spec.Paths.Value(path)
+		for _, method := range operationMethods {
+			if op := operationFor(pathItem, method); op != nil {
+				operations = append(operations, lintOperation{location: method + " " + path, op: op})
+			}
+		}
+	}
+	return operations
+}
+
+func lintOperationIDs(spec *OpenAPISpec) []LintIssue {
+	var issues []LintIssue
+	for _, operation := range lintOperations(spec) {
+		if operation.op.OperationID == "" {
+			issues = append(issues, LintIssue{Location: operation.location, Message: "Operation has no operationId"})
+		}
+	}
+	return issues
+}
+
+func lintOperationDescriptions(spec *OpenAPISpec) []LintIssue {
+	var issues []LintIssue
+	for _, operation := range lintOperations(spec) {
+		if operation.op.Summary == "" && operation.op.Description == "" {
+			issues = append(issues, LintIssue{Location: operation.location, Message: "Operation has no summary or description"})
+		}
+	}
+	return issues
+}
+
+func lintOperationTags(spec *OpenAPISpec) []LintIssue {
+	var issues []LintIssue
+	for _, operation := range lintOperations(spec) {
+		if len(operation.op.Tags) == 0 {
+			issues = append(issues, LintIssue{Location: operation.location, Message: "Operation has no tags"})
+		}
+	}
+	return issues
+}
+
+func lintOperationIDCasing(spec *OpenAPISpec) []LintIssue {
+	var names []namedLocation
+	for _, operation := range lintOperations(spec) {
+		if operation.op.OperationID != "" {
+			names = append(names, namedLocation{name: operation.op.OperationID, location: operation.location})
+		}
+	}
+	return lintCasing(names, "operationId")
+}
+
+func lintPropertyCasing(spec *OpenAPISpec) []LintIssue {
+	doc, err := specToValue(spec)
+	if err != nil {
+		return nil
+	}
+
+	var names []namedLocation
+	var walk func(value interface{}, location string)
+	walk = func(value interface{}, location string) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if properties, ok := v["properties"].(map[string]interface{}); ok {
+				for name := range properties {
+					names = append(names, namedLocation{name: name, location: location + ".properties." + name})
+				}
+			}
+			for key, child := range v {
+				walk(child, location+"."+key)
+			}
+		case []interface{}:
+			for i, child := range v {
+				walk(child, fmt.Sprintf("%s[%d]", location, i))
+			}
+		}
+	}
+	for key, child := range doc {
+		walk(child, key)
+	}
+
+	sort.Slice(names, func(i, j int) bool { return names[i].location < names[j].location })
+	return lintCasing(names, "Property")
+}
+
+// namedLocation is a name with the location it is defined at
+type namedLocation struct {
+	name     string
+	location string
+}
+
+// lintCasing reports the names whose casing differs from the one most names use
+func lintCasing(names []namedLocation, kind string) []LintIssue {
+	counts := make(map[string]int)
+	for _, n := range names {
+		if style := nameCasing(n.name); style != "" {
+			counts[style]++
+		}
+	}
+
+	dominant := ""
+	for _, style := range []string{"camelCase", "snake_case", "PascalCase", "kebab-case"} {
+		if counts[style] > counts[dominant] {
+			dominant = style
+		}
+	}
+	if dominant == "" {
+		return nil
+	}
+
+	var issues []LintIssue
+	for _, n := range names {
+		if style := nameCasing(n.name); style != "" && style != dominant {
+			issues = append(issues, LintIssue{
+				Location: n.location,
+				Message:  fmt.Sprintf("%s %q uses %s while most use %s", kind, n.name, style, dominant),
+			})
+		}
+	}
+	return issues
+}
+
+// nameCasing classifies the casing of a name, or returns "" for single lowercase words, which
+// fit several conventions, and names that follow none
+func nameCasing(name string) string {
+	if name == "" {
+		return ""
+	}
+	hasUpper := strings.IndexFunc(name, unicode.IsUpper) >= 0
+	hasUnderscore := strings.Contains(name, "_")
+	hasDash := strings.Contains(name, "-")
+
+	switch {
+	case hasUnderscore && !hasDash && !hasUpper:
+		return "snake_case"
+	case hasDash && !hasUnderscore && !hasUpper:
+		return "kebab-case"
+	case hasUnderscore || hasDash:
+		return ""
+	case unicode.IsUpper([]rune(name)[0]):
+		return "PascalCase"
+	case hasUpper:
+		return "camelCase"
+	default:
+		return ""
+	}
+}
+
+func lintUnusedComponents(spec *OpenAPISpec) []LintIssue {
+	doc, err := specToValue(spec)
+	if err != nil {
+		return nil
+	}
+	components, _ := doc["components"].(map[string]interface{})
+	if len(components) == 0 {
+		return nil
+	}
+
+	// Follow the references from everything outside the components
+	used := make(map[string]bool)
+	var queue []string
+	for key, value := range doc {
+		if key != "components" {
+			queue = append(queue, collectRefs(value)...)
+		}
+	}
+	for len(queue) > 0 {
+		ref := queue[0]
+		queue = queue[1:]
+		if used[ref] {
+			continue
+		}
+		used[ref] = true
+
+		parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+		if !strings.HasPrefix(ref, "#/components/") || len(parts) != 2 {
+			continue
+		}
+		if entries, ok := components[parts[0]].(map[string]interface{}); ok {
+			queue = append(queue, collectRefs(entries[parts[1]])...)
+		}
+	}
+
+	var issues []LintIssue
+	componentTypes := make([]string, 0, len(components))
+	for componentType := range components {
+		componentTypes = append(componentTypes, componentType)
+	}
+	sort.Strings(componentTypes)
+	for _, componentType := range componentTypes {
+		// Security schemes are used through security requirements rather than references
+		if componentType == "securitySchemes" {
+			continue
+		}
+		entries, _ := components[componentType].(map[string]interface{})
+		names := make([]string, 0, len(entries))
+		for name := range entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !used["#/components/"+componentType+"/"+name] {
+				issues = append(issues, LintIssue{
+					Location: "components." + componentType + "." + name,
+					Message:  "Component is not referenced",
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// collectRefs returns the $ref values and discriminator mappings within a value
+func collectRefs(value interface{}) []string {
+	var refs []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				refs = append(refs, ref)
+				continue
+			}
+			refs = append(refs, collectRefs(child)...)
+		}
+		if mapping, ok := v["mapping"].(map[string]interface{}); ok {
+			for _, target := range mapping {
+				if ref, ok := target.(string); ok {
+					refs = append(refs, ref)
+				}
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			refs = append(refs, collectRefs(child)...)
+		}
+	}
+	return refs
+}
+
+// specToValue converts a document into plain maps and slices
+func specToValue(spec *OpenAPISpec) (map[string]interface{}, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,140 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lintTestSpec = `openapi: 3.0.3
+info:
+  title: Orders
+  version: 1.0.0
+paths:
+  /orders:
+    get:
+      operationId: listOrders
+      summary: List orders
+      tags: [Orders]
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Order"
+    post:
+      operationId: create_order
+      responses:
+        "201":
+          description: Created
+  /health:
+    get:
+      description: Health check
+      tags: [Health]
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        orderId: {type: string}
+        createdAt: {type: string}
+        total_price: {type: number}
+        customer:
+          $ref: "#/components/schemas/Customer"
+    Customer:
+      type: object
+      properties:
+        name: {type: string}
+    Legacy:
+      type: object
+      properties:
+        legacyId:
+          $ref: "#/components/schemas/LegacyId"
+    LegacyId:
+      type: string
+`
+
+func loadLintTestSpec(t *testing.T) *OpenAPISpec {
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(lintTestSpec), 0644))
+	spec, err := LoadSpec(path)
+	require.NoError(t, err)
+	return spec
+}
+
+func TestLintSpec(t *testing.T) {
+	issues, err := LintSpec(loadLintTestSpec(t), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []LintIssue{
+		{Rule: "operation-operation-id", Severity: LintError, Location: "GET /health", Message: "Operation has no operationId"},
+		{Rule: "operation-description", Severity: LintWarning, Location: "POST /orders", Message: "Operation has no summary or description"},
+		{Rule: "operation-id-casing", Severity: LintWarning, Location: "POST /orders", Message: `operationId "create_order" uses snake_case while most use camelCase`},
+		{Rule: "operation-tags", Severity: LintWarning, Location: "POST /orders", Message: "Operation has no tags"},
+		{Rule: "unused-component", Severity: LintWarning, Location: "components.schemas.Legacy", Message: "Component is not referenced"},
+		{Rule: "unused-component", Severity: LintWarning, Location: "components.schemas.LegacyId", Message: "Component is not referenced"},
+		{Rule: "property-casing", Severity: LintWarning, Location: "components.schemas.Order.properties.total_price", Message: `Property "total_price" uses snake_case while most use camelCase`},
+	}, issues)
+}
+
+func TestLintSpecSeverities(t *testing.T) {
+	issues, err := LintSpec(loadLintTestSpec(t), map[string]LintSeverity{
+		"operation-operation-id": LintOff,
+		"unused-component":       LintError,
+		"operation-description":  LintOff,
+		"operation-tags":         LintOff,
+		"operation-id-casing":    LintInfo,
+		"property-casing":        LintOff,
+	})
+	require.NoError(t, err)
+
+	var rules []string
+	for _, issue := range issues {
+		rules = append(rules, string(issue.Severity)+" "+issue.Rule)
+	}
+	assert.Equal(t, []string{"info operation-id-casing", "error unused-component", "error unused-component"}, rules)
+
+	_, err = LintSpec(loadLintTestSpec(t), map[string]LintSeverity{"no-such-rule": LintOff})
+	assert.Error(t, err)
+}
+
+func TestLintGeneratedSpec(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0", UsePathGroups: true})
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, []byte(`[{"id":"__integer__","firstName":"__string__"}]`), 200))
+	generator.AddTransaction(createTestTransaction("POST", "/users", []byte(`{"firstName":"__string__"}`), []byte(`{"id":"__integer__","firstName":"__string__"}`), 201))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	// Generated documents pass the core ruleset
+	issues, err := LintSpec(spec, nil)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestNameCasing(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "orderId", expected: "camelCase"},
+		{name: "order_id", expected: "snake_case"},
+		{name: "OrderId", expected: "PascalCase"},
+		{name: "order-id", expected: "kebab-case"},
+		{name: "order", expected: ""},
+		{name: "Order_Id", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, nameCasing(tt.name))
+		})
+	}
+}
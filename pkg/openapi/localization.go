@@ -0,0 +1,139 @@
+package openapi
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// acceptLanguageHeader is the request header that localized endpoints are documented with
+const acceptLanguageHeader = "Accept-Language"
+
+// localeObservations tracks the locales requested from an endpoint and whether its responses
+// vary by them
+type localeObservations struct {
+	locales map[string]bool
+	bodies  map[string][]byte // locale -> first response body of a request path
+	varies  bool
+}
+
+// newLocaleObservations creates an empty locale tracker
+func newLocaleObservations() *localeObservations {
+	return &localeObservations{locales: make(map[string]bool), bodies: make(map[string][]byte)}
+}
+
+// add records the locale of one transaction. Responses vary by locale when they say so with a
+// Content-Language or Vary: Accept-Language header, or when the same request path returned
+// different bodies for different locales.
+func (o *localeObservations) add(locale string, response proxy.ResponseData) {
+	o.locales[locale] = true
+	if variesByLanguage(response.Headers) {
+		o.varies = true
+	}
+	if _, ok := o.bodies[locale]; ok {
+		return
+	}
+	for _, body := range o.bodies {
+		if !bytes.Equal(body, response.Body) {
+			o.varies = true
+		}
+	}
+	o.bodies[locale] = response.Body
+}
+
+// merge adds the observations of another request path of the same endpoint
+func (o *localeObservations) merge(other *localeObservations) {
+	for locale := range other.locales {
+		o.locales[locale] = true
+	}
+	o.varies = o.varies || other.varies
+}
+
+// sortedLocales returns the observed locales in sorted order
+func (o *localeObservations) sortedLocales() []string {
+	locales := make([]string, 0, len(o.locales))
+	for locale := range o.locales {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// requestLocales returns the locale observations of transactions by method and request path. It
+// runs before the response strategy, which keeps only one locale of most endpoints.
+func requestLocales(transactions []proxy.APITransaction) map[string]*localeObservations {
+	observations := make(map[string]*localeObservations)
+	for _, tx := range transactions {
+		locale := preferredLocale(tx.Request.Headers.Get(acceptLanguageHeader))
+		if locale == "" {
+			continue
+		}
+		key := tx.Request.Method + " " + tx.Request.Path
+		if observations[key] == nil {
+			observations[key] = newLocaleObservations()
+		}
+		observations[key].add(locale, tx.Response)
+	}
+	return observations
+}
+
+// preferredLocale returns the first language tag of an Accept-Language value, e.g. de-CH for
+// "de-CH, de;q=0.9, en;q=0.8", or an empty string for none or the * wildcard
+func preferredLocale(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	tag, _, _ := strings.Cut(first, ";")
+	tag = strings.TrimSpace(tag)
+	if tag == "*" {
+		return ""
+	}
+	return tag
+}
+
+// variesByLanguage reports whether response headers declare a localized response
+func variesByLanguage(headers http.Header) bool {
+	if headers.Get("Content-Language") != "" {
+		return true
+	}
+	for _, value := range headers.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(name), acceptLanguageHeader) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addLocaleParameters documents Accept-Language as a parameter of the operations whose responses
+// vary by it, with the observed locales as its enum, unless the header is excluded
+func (g *OpenAPIGenerator) addLocaleParameters(doc *OpenAPISpec, observations map[string]*localeObservations) {
+	if headerListed(g.config.ExcludeHeaders, acceptLanguageHeader) {
+		return
+	}
+	for key, observed := range observations {
+		op := operationForKey(doc, key)
+		if op == nil || !observed.varies {
+			continue
+		}
+
+		locales := observed.sortedLocales()
+		schema := openapi3.NewStringSchema()
+		for _, locale := range locales {
+			schema.Enum = append(schema.Enum, locale)
+		}
+
+		param := op.Parameters.GetByInAndName("header", acceptLanguageHeader)
+		if param == nil {
+			param = &openapi3.Parameter{Name: acceptLanguageHeader, In: "header"}
+			op.Parameters = append(op.Parameters, &openapi3.ParameterRef{Value: param})
+		}
+		param.Description = "Locale of the response; responses are localized"
+		param.Schema = &openapi3.SchemaRef{Value: schema}
+		param.Example = locales[0]
+	}
+}
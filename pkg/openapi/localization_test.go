@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferredLocale(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"de-CH, de;q=0.9, en;q=0.8", "de-CH"},
+		{"fr", "fr"},
+		{"en;q=0.5", "en"},
+		{"*", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, preferredLocale(tt.value), tt.value)
+	}
+}
+
+func TestGenerateSpecLocales(t *testing.T) {
+	localized := func(tx proxy.APITransaction, locale string) proxy.APITransaction {
+		tx.Request.Headers.Set("Accept-Language", locale)
+		return tx
+	}
+
+	generator := NewOpenAPIGenerator(OpenAPIConfig{ResponseStrategy: StrategyBestSuccess})
+
+	// Different bodies of the same request path for different locales
+	generator.AddTransaction(localized(createTestTransaction("GET", "/products/1", nil, []byte(`{"name":"__string__"}`), 200), "en-US,en;q=0.9"))
+	generator.AddTransaction(localized(createTestTransaction("GET", "/products/1", nil, []byte(`{"name":"__string__","hinweis":"__string__"}`), 200), "de"))
+
+	// A Content-Language header
+	help := localized(createTestTransaction("GET", "/help", nil, []byte(`{"text":"__string__"}`), 200), "fr")
+	help.Response.Headers.Set("Content-Language", "fr")
+	generator.AddTransaction(help)
+
+	// The same body in every locale
+	generator.AddTransaction(localized(createTestTransaction("GET", "/users", nil, []byte(`[]`), 200), "en"))
+	generator.AddTransaction(localized(createTestTransaction("GET", "/users", nil, []byte(`[]`), 200), "de"))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	param := spec.Paths.Find("/products/{productId}").Get.Parameters.GetByInAndName("header", "Accept-Language")
+	require.NotNil(t, param)
+	assert.Equal(t, []interface{}{"de", "en-US"}, param.Schema.Value.Enum)
+	assert.False(t, param.Required)
+
+	param = spec.Paths.Find("/help").Get.Parameters.GetByInAndName("header", "Accept-Language")
+	require.NotNil(t, param)
+	assert.Equal(t, []interface{}{"fr"}, param.Schema.Value.Enum)
+
+	assert.Nil(t, spec.Paths.Find("/users").Get.Parameters.GetByInAndName("header", "Accept-Language"))
+}
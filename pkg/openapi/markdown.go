@@ -0,0 +1,402 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Markdown page names of directory output
+const (
+	markdownIndexPage   = "index.md"
+	markdownSchemasPage = "schemas.md"
+)
+
+// markdownAnchorPattern matches the characters GitHub drops from heading anchors
+var markdownAnchorPattern = regexp.MustCompile(`[^a-z0-9 _-]`)
+
+// markdownRenderer renders a document as Markdown, either as one page or as a page per tag
+type markdownRenderer struct {
+	spec       *OpenAPISpec
+	singlePage bool
+}
+
+// RenderMarkdown renders a document as Markdown pages: an index.md overview, a page per tag
+// with its endpoints, parameters, and bodies, and a schemas.md page with the component
+// schemas. It returns the page contents by file name.
+func RenderMarkdown(spec *OpenAPISpec) map[string][]byte {
+	r := markdownRenderer{spec: spec}
+	tags := operationsByTag(r.spec)
+
+	pages := map[string][]byte{
+		markdownIndexPage: []byte(r.index(tags)),
+	}
+	for _, tag := range sortedTags(tags) {
+		pages[markdownPageName(tag)] = []byte(r.tagSection(tag, tags[tag], "#"))
+	}
+	if schemas := r.schemasSection("#"); schemas != "" {
+		pages[markdownSchemasPage] = []byte(schemas)
+	}
+	return pages
+}
+
+// RenderMarkdownPage renders a document as a single Markdown page
+func RenderMarkdownPage(spec *OpenAPISpec) []byte {
+	r := markdownRenderer{spec: spec, singlePage: true}
+	tags := operationsByTag(r.spec)
+
+	var page strings.Builder
+	page.WriteString(r.index(tags))
+	for _, tag := range sortedTags(tags) {
+		page.WriteString("\n")
+		page.WriteString(r.tagSection(tag, tags[tag], "##"))
+	}
+	if schemas := r.schemasSection("##"); schemas != "" {
+		page.WriteString("\n")
+		page.WriteString(schemas)
+	}
+	return []byte(page.String())
+}
+
+// WriteMarkdown writes the Markdown pages of a document into a directory
+func WriteMarkdown(spec *OpenAPISpec, dir string) error {
+	return writeFiles(dir, RenderMarkdown(spec))
+}
+
+// index renders the overview: title, description, servers, and the list of tags
+func (r markdownRenderer) index(tags map[string][]taggedOperation) string {
+	var page strings.Builder
+
+	title := "API Documentation"
+	if r.spec.Info != nil && r.spec.Info.Title != "" {
+		title = r.spec.Info.Title
+	}
+	fmt.Fprintf(&page, "# %s\n\n", title)
+	if r.spec.Info != nil {
+		if r.spec.Info.Version != "" {
+			fmt.Fprintf(&page, "Version: `%s`\n\n", r.spec.Info.Version)
+		}
+		if r.spec.Info.Description != "" {
+			fmt.Fprintf(&page, "%s\n\n", r.spec.Info.Description)
+		}
+	}
+
+	if len(r.spec.Servers) > 0 {
+		page.WriteString("**Servers:**\n\n")
+		for _, server := range r.spec.Servers {
+			if server.Description != "" {
+				fmt.Fprintf(&page, "- `%s` - %s\n", server.URL, server.Description)
+			} else {
+				fmt.Fprintf(&page, "- `%s`\n", server.URL)
+			}
+		}
+		page.WriteString("\n")
+	}
+
+	page.WriteString("| Tag | Endpoints | Description |\n")
+	page.WriteString("|-----|-----------|-------------|\n")
+	for _, tag := range sortedTags(tags) {
+		fmt.Fprintf(&page, "| [%s](%s) | %d | %s |\n", tag, r.tagLink(tag), len(tags[tag]), markdownCell(r.tagDescription(tag)))
+	}
+
+	if r.hasSchemas() {
+		fmt.Fprintf(&page, "\nSee [Schemas](%s) for the shared schema definitions.\n", r.schemaPageLink(""))
+	}
+	return page.String()
+}
+
+// tagSection renders the endpoints of a tag: a summary table followed by a section per operation
+func (r markdownRenderer) tagSection(tag string, operations []taggedOperation, heading string) string {
+	var page strings.Builder
+
+	fmt.Fprintf(&page, "%s %s\n\n", heading, tag)
+	if description := r.tagDescription(tag); description != "" {
+		fmt.Fprintf(&page, "%s\n\n", description)
+	}
+
+	page.WriteString("| Method | Path | Summary |\n")
+	page.WriteString("|--------|------|---------|\n")
+	for _, operation := range operations {
+		fmt.Fprintf(&page, "| `%s` | `%s` | %s |\n", operation.method, operation.path, markdownCell(operation.op.Summary))
+	}
+
+	for _, operation := range operations {
+		page.WriteString("\n")
+		page.WriteString(r.operationSection(operation, heading+"#"))
+	}
+	return page.String()
+}
+
+// operationSection renders the parameters, request body, and responses of an operation
+func (r markdownRenderer) operationSection(operation taggedOperation, heading string) string {
+	var section strings.Builder
+	op := operation.op
+
+	fmt.Fprintf(&section, "%s %s %s\n\n", heading, operation.method, operation.path)
+	if op.Deprecated {
+		section.WriteString("> **Deprecated**\n\n")
+	}
+	if op.Summary != "" {
+		fmt.Fprintf(&section, "**%s**\n\n", op.Summary)
+	}
+	if op.Description != "" && op.Description != op.Summary {
+		fmt.Fprintf(&section, "%s\n\n", op.Description)
+	}
+
+	if len(op.Parameters) > 0 {
+		fmt.Fprintf(&section, "%s# Parameters\n\n", heading)
+		section.WriteString("| Name | In | Type | Required | Description |\n")
+		section.WriteString("|------|----|------|----------|-------------|\n")
+		for _, paramRef := range op.Parameters {
+			param := paramRef.Value
+			if param == nil {
+				continue
+			}
+			fmt.Fprintf(&section, "| `%s` | %s | %s | %s | %s |\n",
+				param.Name, param.In, r.schemaType(param.Schema), yesNo(param.Required), markdownCell(param.Description))
+		}
+		section.WriteString("\n")
+	}
+
+	if op.RequestBody != nil && op.RequestBody.Value != nil && len(op.RequestBody.Value.Content) > 0 {
+		fmt.Fprintf(&section, "%s# Request body\n\n", heading)
+		for _, mediaType := range sortedMediaTypes(op.RequestBody.Value.Content) {
+			section.WriteString(r.mediaTypeSection(mediaType, op.RequestBody.Value.Content[mediaType]))
+		}
+	}
+
+	if op.Responses != nil && op.Responses.Len() > 0 {
+		fmt.Fprintf(&section, "%s# Responses\n\n", heading)
+		section.WriteString("| Status | Description | Content |\n")
+		section.WriteString("|--------|-------------|---------|\n")
+
+		statuses := make([]string, 0, op.Responses.Len())
+		for status := range op.Responses.Map() {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+
+		var bodies strings.Builder
+		for _, status := range statuses {
+			response := op.Responses.Value(status).Value
+			if response == nil {
+				continue
+			}
+			description := ""
+			if response.Description != nil {
+				description = *response.Description
+			}
+			if description == "" && len(response.Content) == 0 {
+				// Skip the empty default response kin-openapi documents start with
+				continue
+			}
+
+			var content []string
+			for _, mediaType := range sortedMediaTypes(response.Content) {
+				content = append(content, fmt.Sprintf("`%s`: %s", mediaType, r.schemaType(response.Content[mediaType].Schema)))
+				if table := r.propertiesTable(response.Content[mediaType].Schema); table != "" {
+					fmt.Fprintf(&bodies, "**%s** `%s`\n\n%s\n", status, mediaType, table)
+				}
+			}
+			fmt.Fprintf(&section, "| %s | %s | %s |\n", status, markdownCell(description), strings.Join(content, "<br>"))
+		}
+		section.WriteString("\n")
+		section.WriteString(bodies.String())
+	}
+
+	return strings.TrimRight(section.String(), "\n") + "\n"
+}
+
+// mediaTypeSection renders the schema of a request body media type
+func (r markdownRenderer) mediaTypeSection(mediaType string, media *openapi3.MediaType) string {
+	section := fmt.Sprintf("`%s`: %s\n\n", mediaType, r.schemaType(media.Schema))
+	if table := r.propertiesTable(media.Schema); table != "" {
+		section += table + "\n"
+	}
+	return section
+}
+
+// schemasSection renders the component schemas with their properties
+func (r markdownRenderer) schemasSection(heading string) string {
+	if !r.hasSchemas() {
+		return ""
+	}
+
+	names := make([]string, 0, len(r.spec.Components.Schemas))
+	for name := range r.spec.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var page strings.Builder
+	fmt.Fprintf(&page, "%s Schemas\n", heading)
+	for _, name := range names {
+		ref := r.spec.Components.Schemas[name]
+		fmt.Fprintf(&page, "\n%s# %s\n\n", heading, name)
+		if ref.Value != nil && ref.Value.Description != "" {
+			fmt.Fprintf(&page, "%s\n\n", ref.Value.Description)
+		}
+		if table := r.propertiesTable(&openapi3.SchemaRef{Value: ref.Value}); table != "" {
+			page.WriteString(table)
+		} else {
+			fmt.Fprintf(&page, "Type: %s\n", r.schemaType(&openapi3.SchemaRef{Value: ref.Value}))
+		}
+	}
+	return page.String()
+}
+
+// propertiesTable renders the properties of an inline object schema, or of the items of an
+// inline array. Referenced schemas are described on the schemas page instead.
+func (r markdownRenderer) propertiesTable(ref *openapi3.SchemaRef) string {
+	if ref == nil || ref.Ref != "" || ref.Value == nil {
+		return ""
+	}
+	schema := ref.Value
+	if schema.Type.Is("array") && schema.Items != nil && schema.Items.Ref == "" && schema.Items.Value != nil {
+		schema = schema.Items.Value
+	}
+	if len(schema.Properties) == 0 {
+		return ""
+	}
+
+	required := make(map[string]bool)
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var table strings.Builder
+	table.WriteString("| Property | Type | Required | Description |\n")
+	table.WriteString("|----------|------|----------|-------------|\n")
+	for _, name := range names {
+		prop := schema.Properties[name]
+		description := ""
+		if prop.Value != nil {
+			description = prop.Value.Description
+			if prop.Value.ReadOnly {
+				description = strings.TrimSpace("Read-only. " + description)
+			}
+			if prop.Value.WriteOnly {
+				description = strings.TrimSpace("Write-only. " + description)
+			}
+		}
+		fmt.Fprintf(&table, "| `%s` | %s | %s | %s |\n", name, r.schemaType(prop), yesNo(required[name]), markdownCell(description))
+	}
+	return table.String()
+}
+
+// schemaType describes the type of a schema, linking to referenced component schemas
+func (r markdownRenderer) schemaType(ref *openapi3.SchemaRef) string {
+	if ref == nil {
+		return ""
+	}
+	if ref.Ref != "" {
+		name := strings.TrimPrefix(ref.Ref, "#/components/schemas/")
+		return fmt.Sprintf("[%s](%s)", name, r.schemaPageLink(name))
+	}
+
+	schema := ref.Value
+	if schema == nil {
+		return ""
+	}
+	switch {
+	case len(schema.OneOf) > 0:
+		var variants []string
+		for _, variant := range schema.OneOf {
+			variants = append(variants, r.schemaType(variant))
+		}
+		return "one of " + strings.Join(variants, ", ")
+	case schema.Type.Is("array"):
+		return "array of " + r.schemaType(schema.Items)
+	case schema.Type.Is("object") && schema.AdditionalProperties.Schema != nil:
+		return "map of " + r.schemaType(schema.AdditionalProperties.Schema)
+	}
+
+	if schema.Type == nil || len(*schema.Type) == 0 {
+		return "any"
+	}
+	typeName := strings.Join(*schema.Type, " | ")
+	if schema.Format != "" {
+		typeName += " (" + schema.Format + ")"
+	}
+	if len(schema.Enum) > 0 {
+		var values []string
+		for _, value := range schema.Enum {
+			values = append(values, fmt.Sprintf("`%v`", value))
+		}
+		typeName += ": " + strings.Join(values, ", ")
+	}
+	return typeName
+}
+
+// tagDescription returns the description of a tag from the top-level tags list
+func (r markdownRenderer) tagDescription(name string) string {
+	if tag := r.spec.Tags.Get(name); tag != nil {
+		return tag.Description
+	}
+	return ""
+}
+
+// hasSchemas checks whether the document has component schemas
+func (r markdownRenderer) hasSchemas() bool {
+	return r.spec.Components != nil && len(r.spec.Components.Schemas) > 0
+}
+
+// tagLink links to the section of a tag
+func (r markdownRenderer) tagLink(tag string) string {
+	if r.singlePage {
+		return "#" + markdownAnchor(tag)
+	}
+	return markdownPageName(tag)
+}
+
+// schemaPageLink links to the definition of a component schema, or to the schemas section
+func (r markdownRenderer) schemaPageLink(name string) string {
+	anchor := "schemas"
+	if name != "" {
+		anchor = markdownAnchor(name)
+	}
+	if r.singlePage {
+		return "#" + anchor
+	}
+	if name == "" {
+		return markdownSchemasPage
+	}
+	return markdownSchemasPage + "#" + anchor
+}
+
+// markdownPageName returns the file name of a tag's page, e.g. "User Accounts" -> user-accounts.md
+func markdownPageName(tag string) string {
+	name := markdownAnchor(tag)
+	if name == "" || name == "index" || name == "schemas" {
+		name = "tag-" + name
+	}
+	return name + ".md"
+}
+
+// markdownAnchor returns the anchor GitHub generates for a heading
+func markdownAnchor(heading string) string {
+	anchor := markdownAnchorPattern.ReplaceAllString(strings.ToLower(heading), "")
+	return strings.ReplaceAll(anchor, " ", "-")
+}
+
+// markdownCell escapes a value for a table cell
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.ReplaceAll(strings.TrimSpace(value), "\n", "<br>")
+}
+
+// yesNo formats a flag for a table cell
+func yesNo(value bool) string {
+	if value {
+		return "Yes"
+	}
+	return "No"
+}
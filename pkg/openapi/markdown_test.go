@@ -0,0 +1,104 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// markdownTestConfig is the Shop API with a described tag
+func markdownTestConfig() OpenAPIConfig {
+	config := shopTestConfig()
+	config.TagDescriptions = map[string]string{"Users": "Customer accounts"}
+	return config
+}
+
+// markdownTestTransactions document users with a request body and orders with an inline one
+func markdownTestTransactions() []proxy.APITransaction {
+	return []proxy.APITransaction{
+		createTestTransaction("POST", "/users", []byte(`{"name":"__string__","active":true}`), []byte(`{"id":"__integer__","name":"__string__"}`), 201),
+		createTestTransaction("GET", "/users/1", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200),
+		createTestTransaction("GET", "/users/2", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200),
+		createTestTransaction("GET", "/orders", nil, []byte(`[{"id":"__integer__","total":"__number__"}]`), 200),
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	spec, _ := generateTestSpec(t, markdownTestConfig(), markdownTestTransactions()...)
+	pages := RenderMarkdown(spec)
+
+	require.Contains(t, pages, markdownIndexPage)
+	index := string(pages[markdownIndexPage])
+	assert.Contains(t, index, "# Shop API")
+	assert.Contains(t, index, "- `https://api.example.com`")
+	assert.Contains(t, index, "| [Users](users.md) | 2 | Customer accounts |")
+
+	require.Contains(t, pages, "users.md")
+	users := string(pages["users.md"])
+	assert.Contains(t, users, "# Users\n\nCustomer accounts")
+	assert.Contains(t, users, "| `POST` | `/users` |")
+	assert.Contains(t, users, "## GET /users/{userId}")
+	assert.Contains(t, users, "| `userId` | path |")
+	assert.Contains(t, users, "### Request body\n\n`application/json`: [User](schemas.md#user)")
+	assert.Contains(t, users, "| 201 | Created | `application/json`: [User](schemas.md#user) |")
+	assert.NotContains(t, users, "| default |")
+
+	// Referenced schemas are defined on the schemas page
+	require.Contains(t, pages, markdownSchemasPage)
+	schemas := string(pages[markdownSchemasPage])
+	assert.Contains(t, schemas, "## User\n")
+	assert.Contains(t, schemas, "| `name` | string |")
+
+	// Inline bodies get their property tables next to the operation
+	orders := string(pages["orders.md"])
+	assert.Contains(t, orders, "| `id` | integer (int64) | Yes |")
+}
+
+func TestRenderMarkdownPage(t *testing.T) {
+	spec, _ := generateTestSpec(t, markdownTestConfig(), markdownTestTransactions()...)
+	page := string(RenderMarkdownPage(spec))
+
+	assert.Contains(t, page, "# Shop API")
+	assert.Contains(t, page, "| [Users](#users) |")
+	assert.Contains(t, page, "## Users")
+	assert.Contains(t, page, "### POST /users")
+	assert.NotContains(t, page, "users.md")
+
+	data, err := MarshalSpecFormat(spec, FormatMarkdown)
+	require.NoError(t, err)
+	assert.Equal(t, page, string(data))
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	spec, _ := generateTestSpec(t, markdownTestConfig(), markdownTestTransactions()...)
+	dir := filepath.Join(t.TempDir(), "docs")
+	require.NoError(t, WriteMarkdown(spec, dir))
+
+	for _, name := range []string{markdownIndexPage, "users.md", "orders.md"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.NoError(t, err, name)
+	}
+}
+
+func TestMarkdownPageName(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{tag: "Users", expected: "users.md"},
+		{tag: "User Accounts", expected: "user-accounts.md"},
+		{tag: "Billing & Invoices", expected: "billing--invoices.md"},
+		{tag: "Index", expected: "tag-index.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			assert.Equal(t, tt.expected, markdownPageName(tt.tag))
+		})
+	}
+}
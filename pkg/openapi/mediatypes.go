@@ -0,0 +1,111 @@
+package openapi
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// versionedMediaTypePattern matches vendor media types that carry an API version, e.g.
+// application/vnd.acme.v2+json or application/vnd.github-v3+json
+var versionedMediaTypePattern = regexp.MustCompile(`^application/vnd\.[A-Za-z0-9._-]+?[.-]v(\d+(?:\.\d+)*)\+json$`)
+
+// mediaTypeVersion returns the API version of a versioned vendor media type, or an empty string
+func mediaTypeVersion(mediaType string) string {
+	if match := versionedMediaTypePattern.FindStringSubmatch(strings.ToLower(mediaType)); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// responseMediaType returns the media type a response is documented under. APIs versioned
+// through the Accept header often answer with plain application/json, so a versioned media
+// type the client asked for is used for JSON responses.
+func responseMediaType(tx proxy.APITransaction) string {
+	contentType := getContentType(tx.Response.Headers)
+	if mediaTypeVersion(contentType) != "" {
+		return contentType
+	}
+	if accepted := acceptedVersionedMediaType(tx.Request.Headers.Get("Accept")); accepted != "" && isJSONContentType(contentType) {
+		return accepted
+	}
+	return contentType
+}
+
+// acceptedVersionedMediaType returns the first versioned vendor media type of an Accept header
+func acceptedVersionedMediaType(accept string) string {
+	for _, entry := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(entry, ";", 2)[0])
+		if mediaTypeVersion(mediaType) != "" {
+			return mediaType
+		}
+	}
+	return ""
+}
+
+// mediaSampleKey is the key of the body samples of one media type of an endpoint
+func mediaSampleKey(bodyKey, mediaType string) string {
+	return bodyKey + "|" + mediaType
+}
+
+// addMediaType documents a media type seen on a later transaction as another content entry
+func addMediaType(content openapi3.Content, mediaType string, schema *parser.Schema) {
+	if schema == nil || mediaType == "" || content == nil || content[mediaType] != nil {
+		return
+	}
+	content[mediaType] = &openapi3.MediaType{
+		Schema: &openapi3.SchemaRef{Value: toOpenAPISchema(*schema)},
+	}
+}
+
+// missingMediaType reports whether a body of a media type would add content to an operation: a
+// response with a status code not documented yet, or a media type its response, or its request
+// body when statusCode is empty, doesn't have yet
+func missingMediaType(op *openapi3.Operation, statusCode string, mediaType string) bool {
+	if op == nil {
+		return false
+	}
+
+	var content openapi3.Content
+	if statusCode == "" {
+		if op.RequestBody == nil || op.RequestBody.Value == nil {
+			return false
+		}
+		content = op.RequestBody.Value.Content
+	} else {
+		response := op.Responses.Value(statusCode)
+		if response == nil {
+			return true
+		}
+		if response.Value == nil {
+			return false
+		}
+		content = response.Value.Content
+	}
+	return mediaType != "" && content != nil && content[mediaType] == nil
+}
+
+// splitMediaTypeSchema infers the schema of one versioned media type from its own body
+// samples when SplitMediaTypes is enabled, so that the versions of a resource aren't merged
+// into one schema
+func (g *OpenAPIGenerator) splitMediaTypeSchema(mediaSamples *sampleReservoirs, bodyKey, mediaType string) (parser.Schema, bool) {
+	if !g.config.SplitMediaTypes {
+		return parser.Schema{}, false
+	}
+
+	samples := mediaSamples.values(mediaSampleKey(bodyKey, mediaType))
+	var schemas []parser.Schema
+	for _, sample := range samples {
+		if schema, err := g.parseJSONBody(sample); err == nil && schema != nil {
+			schemas = append(schemas, *schema)
+		}
+	}
+	if len(schemas) == 0 {
+		return parser.Schema{}, false
+	}
+	return g.refineSchema(parser.MergeSchemaList(schemas), samples), true
+}
@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMediaTypeVersion(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		expected  string
+	}{
+		{mediaType: "application/vnd.acme.v2+json", expected: "2"},
+		{mediaType: "application/vnd.github-v3+json", expected: "3"},
+		{mediaType: "application/vnd.acme.user.v1.1+json", expected: "1.1"},
+		{mediaType: "application/vnd.api+json", expected: ""},
+		{mediaType: "application/json", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			assert.Equal(t, tt.expected, mediaTypeVersion(tt.mediaType))
+		})
+	}
+}
+
+func TestGenerateSpecVersionedMediaTypes(t *testing.T) {
+	v1 := "application/vnd.acme.v1+json"
+	v2 := "application/vnd.acme.v2+json"
+
+	// v1 is negotiated through Accept with a plain JSON response, v2 through Content-Type
+	acceptV1 := createTestTransaction("GET", "/users", nil, []byte(`{"name":"__string__"}`), 200)
+	acceptV1.Request.Headers.Set("Accept", v1)
+	contentV2 := createTestTransaction("GET", "/users", nil, []byte(`{"firstName":"__string__","lastName":"__string__"}`), 200)
+	contentV2.Response.Headers.Set("Content-Type", v2)
+	transactions := []proxy.APITransaction{acceptV1, contentV2}
+
+	for _, split := range []bool{false, true} {
+		generator := NewOpenAPIGenerator(OpenAPIConfig{SplitMediaTypes: split})
+		for _, tx := range transactions {
+			generator.AddTransaction(tx)
+		}
+
+		spec, err := generator.GenerateSpec()
+		require.NoError(t, err)
+
+		content := spec.Paths.Find("/users").Get.Responses.Value("200").Value.Content
+		require.NotNil(t, content.Get(v1))
+		require.NotNil(t, content.Get(v2))
+		assert.Nil(t, content.Get("application/json"))
+
+		v1Schema := content.Get(v1).Schema.Value
+		v2Schema := content.Get(v2).Schema.Value
+		if !split {
+			// Without splitting, every version documents the merged schema
+			assert.Len(t, v1Schema.Properties, 3)
+			assert.Len(t, v2Schema.Properties, 3)
+			continue
+		}
+
+		assert.Contains(t, v1Schema.Properties, "name")
+		assert.NotContains(t, v1Schema.Properties, "firstName")
+		assert.Contains(t, v2Schema.Properties, "firstName")
+		assert.NotContains(t, v2Schema.Properties, "name")
+	}
+}
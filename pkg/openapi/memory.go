@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// defaultEndpointSamples is how many decoded bodies are kept per endpoint and body kind for
+// required property, constraint, and mixed type inference, and how many distinct schemas are
+// merged per endpoint, unless configured otherwise. Beyond it a uniform random sample is kept,
+// so that huge captures don't hold every body in memory.
+const defaultEndpointSamples = 1000
+
+// defaultFieldSamples is how many values of every field type inference looks at, unless
+// configured otherwise
+const defaultFieldSamples = 10
+
+// endpointSamples returns how many bodies and schemas are kept per endpoint
+func (g *OpenAPIGenerator) endpointSamples() int {
+	if g.config.MaxEndpointSamples > 0 {
+		return g.config.MaxEndpointSamples
+	}
+	return defaultEndpointSamples
+}
+
+// fieldSamples returns how many values of every field type inference looks at
+func (g *OpenAPIGenerator) fieldSamples() int {
+	if g.config.MaxFieldSamples > 0 {
+		return g.config.MaxFieldSamples
+	}
+	return defaultFieldSamples
+}
+
+// sampleReservoirs keeps a bounded random sample of decoded bodies per key
+type sampleReservoirs struct {
+	size       int
+	reservoirs map[string]*parser.Reservoir
+}
+
+// newSampleReservoirs creates empty reservoirs of at most size bodies per key
+func newSampleReservoirs(size int) *sampleReservoirs {
+	return &sampleReservoirs{size: size, reservoirs: make(map[string]*parser.Reservoir)}
+}
+
+// add offers a decoded body to the reservoir of a key
+func (s *sampleReservoirs) add(key string, value interface{}) {
+	reservoir, exists := s.reservoirs[key]
+	if !exists {
+		reservoir = parser.NewReservoir(s.size)
+		s.reservoirs[key] = reservoir
+	}
+	reservoir.Add(value)
+}
+
+// values returns the decoded bodies kept for a key
+func (s *sampleReservoirs) values(key string) []interface{} {
+	return s.reservoirs[key].Values()
+}
+
+// errorSamples keeps a bounded random sample of the error responses of every operation and
+// status code, in the order they were first seen
+type errorSamples struct {
+	keys       []string
+	reservoirs *sampleReservoirs
+}
+
+// newErrorSamples creates an empty collection of at most size error samples per operation and
+// status code
+func newErrorSamples(size int) *errorSamples {
+	return &errorSamples{reservoirs: newSampleReservoirs(size)}
+}
+
+// add keeps an error sample
+func (e *errorSamples) add(sample errorSample) {
+	key := sample.method + " " + sample.path + " " + sample.statusCode
+	if _, exists := e.reservoirs.reservoirs[key]; !exists {
+		e.keys = append(e.keys, key)
+	}
+	e.reservoirs.add(key, sample)
+}
+
+// all returns the kept error samples
+func (e *errorSamples) all() []errorSample {
+	var samples []errorSample
+	for _, key := range e.keys {
+		for _, value := range e.reservoirs.values(key) {
+			samples = append(samples, value.(errorSample))
+		}
+	}
+	return samples
+}
+
+// TransactionSize approximates the memory a transaction takes up: its bodies, headers, path,
+// and query parameters
+func TransactionSize(tx proxy.APITransaction) int64 {
+	size := int64(len(tx.Request.Method) + len(tx.Request.Path) + len(tx.Request.Body) + len(tx.Response.Body))
+	for _, headers := range []map[string][]string{tx.Request.Headers, tx.Response.Headers, tx.Request.QueryParams} {
+		for name, values := range headers {
+			size += int64(len(name))
+			for _, value := range values {
+				size += int64(len(value))
+			}
+		}
+	}
+	return size
+}
+
+// BoundedTransactions collects transactions within a memory budget. Until the budget is used up
+// every transaction is kept; after that every endpoint keeps a uniform random sample of its
+// transactions, replacing kept ones rather than growing, so that memory stays bounded while
+// every endpoint remains documented. Transactions of endpoints not seen before are always kept.
+type BoundedTransactions struct {
+	budget    int64
+	size      int64
+	seq       int
+	kept      []keptTransaction
+	endpoints map[string]*endpointSamples
+	dropped   int
+}
+
+// keptTransaction is a transaction with its position in the stream, to restore capture order
+type keptTransaction struct {
+	seq int
+	tx  proxy.APITransaction
+}
+
+// endpointSamples are the kept transactions of an endpoint, sampled by a reservoir that grows
+// until the budget is used up
+type endpointSamples struct {
+	slots     []int // indexes into kept
+	reservoir *parser.Reservoir
+}
+
+// NewBoundedTransactions creates a collector for a budget in bytes, or without a limit if the
+// budget is 0 or less
+func NewBoundedTransactions(budget int64) *BoundedTransactions {
+	return &BoundedTransactions{
+		budget:    budget,
+		endpoints: make(map[string]*endpointSamples),
+	}
+}
+
+// Add offers a transaction to the collector
+func (b *BoundedTransactions) Add(tx proxy.APITransaction) {
+	b.seq++
+	key := samplingKey(tx)
+	endpoint, exists := b.endpoints[key]
+	if !exists {
+		endpoint = &endpointSamples{reservoir: parser.NewReservoir(0)}
+		b.endpoints[key] = endpoint
+	}
+
+	// Over budget, the endpoint stops growing, and the transaction replaces a kept one of its
+	// endpoint with the probability that keeps the sample uniform
+	size := TransactionSize(tx)
+	if b.budget > 0 && b.size+size > b.budget && len(endpoint.slots) > 0 {
+		endpoint.reservoir.Freeze()
+	}
+	switch i := endpoint.reservoir.Offer(); {
+	case i == len(endpoint.slots):
+		endpoint.slots = append(endpoint.slots, len(b.kept))
+		b.kept = append(b.kept, keptTransaction{seq: b.seq, tx: tx})
+		b.size += size
+	case i >= 0:
+		b.dropped++
+		slot := endpoint.slots[i]
+		b.size += size - TransactionSize(b.kept[slot].tx)
+		b.kept[slot] = keptTransaction{seq: b.seq, tx: tx}
+	default:
+		b.dropped++
+	}
+}
+
+// Transactions returns the kept transactions in the order they were added
+func (b *BoundedTransactions) Transactions() []proxy.APITransaction {
+	kept := append([]keptTransaction(nil), b.kept...)
+	sort.Slice(kept, func(i, j int) bool { return kept[i].seq < kept[j].seq })
+	transactions := make([]proxy.APITransaction, len(kept))
+	for i, k := range kept {
+		transactions[i] = k.tx
+	}
+	return transactions
+}
+
+// Seen returns how many transactions were added
+func (b *BoundedTransactions) Seen() int {
+	return b.seq
+}
+
+// Dropped returns how many transactions were left out to stay within the budget
+func (b *BoundedTransactions) Dropped() int {
+	return b.dropped
+}
+
+// samplingKey approximates the endpoint of a transaction before path patterns are known: path
+// segments with digits, which are mostly identifiers, are collapsed
+func samplingKey(tx proxy.APITransaction) string {
+	segments := strings.Split(tx.Request.Path, "/")
+	for i, segment := range segments {
+		if strings.IndexFunc(segment, unicode.IsDigit) >= 0 {
+			segments[i] = "*"
+		}
+	}
+	return tx.Request.Method + " " + strings.Join(segments, "/")
+}
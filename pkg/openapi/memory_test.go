@@ -0,0 +1,87 @@
+package openapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoundedTransactions(t *testing.T) {
+	body := []byte(`{"id":"__integer__","name":"__string__"}`)
+	size := TransactionSize(createTestTransaction("GET", "/users/1", nil, body, 200))
+
+	// Without a budget everything is kept
+	unbounded := NewBoundedTransactions(0)
+	for i := 0; i < 50; i++ {
+		unbounded.Add(createTestTransaction("GET", fmt.Sprintf("/users/%d", i), nil, body, 200))
+	}
+	assert.Len(t, unbounded.Transactions(), 50)
+	assert.Zero(t, unbounded.Dropped())
+
+	// With one, endpoints are sampled once it is used up, but new endpoints are still kept
+	bounded := NewBoundedTransactions(10 * size)
+	for i := 0; i < 100; i++ {
+		bounded.Add(createTestTransaction("GET", fmt.Sprintf("/users/%d", i), nil, body, 200))
+	}
+	bounded.Add(createTestTransaction("GET", "/health", nil, nil, 200))
+	transactions := bounded.Transactions()
+	assert.Len(t, transactions, 11)
+	assert.Equal(t, 101, bounded.Seen())
+	assert.Equal(t, 90, bounded.Dropped())
+	assert.Equal(t, "/health", transactions[len(transactions)-1].Request.Path)
+
+	// The kept transactions are spread over the capture and stay in capture order
+	var paths []string
+	for _, tx := range transactions {
+		paths = append(paths, tx.Request.Path)
+	}
+	assert.NotEqual(t, "/users/9", paths[9], "expected later transactions to replace early ones")
+	assert.IsNonDecreasing(t, userIDs(t, transactions[:10]))
+}
+
+// userIDs returns the numeric IDs of /users/{id} requests
+func userIDs(t *testing.T, transactions []proxy.APITransaction) []int {
+	var ids []int
+	for _, tx := range transactions {
+		var id int
+		_, err := fmt.Sscanf(tx.Request.Path, "/users/%d", &id)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestGenerateSpecBoundedBodySamples(t *testing.T) {
+	// Far more bodies than are kept still document every field seen in the sample
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Users API", Version: "1.0.0"})
+	for i := 0; i < 3*defaultEndpointSamples; i++ {
+		generator.AddTransaction(createTestTransaction("GET", fmt.Sprintf("/users/%d", i), nil,
+			[]byte(`{"id":"__integer__","name":"__string__"}`), 200))
+	}
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	response := spec.Paths.Find("/users/{userId}").Get.Responses.Value("200")
+	require.NotNil(t, response)
+	schema := response.Value.Content.Get("application/json").Schema.Value
+	assert.ElementsMatch(t, []string{"id", "name"}, schema.Required)
+}
+
+func TestGenerateSpecMaxEndpointSamples(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{MaxEndpointSamples: 2, MaxFieldSamples: 1})
+	for i := 0; i < 20; i++ {
+		generator.AddTransaction(createTestTransaction("GET", "/users", nil,
+			[]byte(fmt.Sprintf(`{"id":"__integer__","field%d":"__string__"}`, i)), 200))
+	}
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	// Two of the twenty distinct bodies are merged, each with id and one other field
+	schema := spec.Paths.Find("/users").Get.Responses.Value("200").Value.Content.Get("application/json").Schema.Value
+	assert.Len(t, schema.Properties, 3)
+	assert.Contains(t, schema.Properties, "id")
+}
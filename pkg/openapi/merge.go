@@ -0,0 +1,292 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// LoadSpec reads an existing OpenAPI document in JSON or YAML
+func LoadSpec(path string) (*OpenAPISpec, error) {
+	loader := openapi3.NewLoader()
+	spec, err := loader.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI document %s: %v", path, err)
+	}
+	return spec, nil
+}
+
+// MergeSpecs merges a freshly generated document into an existing, hand-edited one. Schemas,
+// parameters, responses, and newly observed endpoints are taken from the generated document,
+// while the descriptions, summaries, tags, and examples written by hand are preserved, as is
+// everything the generated document doesn't know about. The existing document is updated in
+// place and returned.
+func MergeSpecs(existing, generated *OpenAPISpec) *OpenAPISpec {
+	if existing.Paths == nil {
+		existing.Paths = openapi3.NewPaths()
+	}
+
+	paths := generated.Paths.InMatchingOrder()
+	sort.Strings(paths)
+	for _, path := range paths {
+		generatedItem := generated.Paths.Value(path)
+		existingItem := existing.Paths.Value(path)
+		if existingItem == nil {
+			existing.Paths.Set(path, generatedItem)
+			continue
+		}
+
+		for _, method := range operationMethods {
+			generatedOp := operationFor(generatedItem, method)
+			if generatedOp == nil {
+				continue
+			}
+			if existingOp := operationFor(existingItem, method); existingOp != nil {
+				mergeOperation(existingOp, generatedOp)
+			} else {
+				existingItem.SetOperation(method, generatedOp)
+			}
+		}
+	}
+
+	mergeComponents(existing, generated)
+	mergeTags(existing, generated)
+
+	if len(existing.Servers) == 0 {
+		existing.Servers = generated.Servers
+	}
+	if len(existing.Security) == 0 {
+		existing.Security = generated.Security
+	}
+
+	return existing
+}
+
+// mergeOperation updates an existing operation with what was observed, keeping its
+// hand-written documentation
+func mergeOperation(existing, generated *openapi3.Operation) {
+	if existing.Summary == "" {
+		existing.Summary = generated.Summary
+	}
+	if existing.Description == "" {
+		existing.Description = generated.Description
+	}
+	if existing.OperationID == "" {
+		existing.OperationID = generated.OperationID
+	}
+	if len(existing.Tags) == 0 {
+		existing.Tags = generated.Tags
+	}
+	if existing.Security == nil {
+		existing.Security = generated.Security
+	}
+	mergeExtensions(&existing.Extensions, generated.Extensions)
+
+	existing.Parameters = mergeParameters(existing.Parameters, generated.Parameters)
+
+	switch {
+	case generated.RequestBody == nil || generated.RequestBody.Value == nil:
+	case existing.RequestBody == nil:
+		existing.RequestBody = generated.RequestBody
+	case existing.RequestBody.Ref == "" && existing.RequestBody.Value != nil:
+		mergeContent(&existing.RequestBody.Value.Content, generated.RequestBody.Value.Content)
+	}
+
+	if generated.Responses == nil {
+		return
+	}
+	if existing.Responses == nil {
+		existing.Responses = generated.Responses
+		return
+	}
+	for status, generatedResponse := range generated.Responses.Map() {
+		existingResponse := existing.Responses.Value(status)
+		if existingResponse == nil || existingResponse.Value == nil {
+			// Skip the placeholder default response when the existing operation has its own
+			if status == "default" && existing.Responses.Len() > 0 {
+				continue
+			}
+			existing.Responses.Set(status, generatedResponse)
+			continue
+		}
+		if existingResponse.Ref != "" || generatedResponse.Value == nil {
+			continue
+		}
+		mergeResponse(existingResponse.Value, generatedResponse.Value)
+	}
+}
+
+// mergeParameters updates parameters that appear in both lists with the observed schema and
+// adds newly observed ones. Hand-written parameters, descriptions, and examples are kept.
+func mergeParameters(existing, generated openapi3.Parameters) openapi3.Parameters {
+	for _, generatedParam := range generated {
+		if generatedParam.Value == nil {
+			continue
+		}
+
+		var match *openapi3.ParameterRef
+		for _, param := range existing {
+			if param.Value != nil && param.Value.In == generatedParam.Value.In && param.Value.Name == generatedParam.Value.Name {
+				match = param
+				break
+			}
+		}
+
+		switch {
+		case match == nil:
+			existing = append(existing, generatedParam)
+		case match.Ref == "":
+			if match.Value.Description == "" {
+				match.Value.Description = generatedParam.Value.Description
+			}
+			if match.Value.Example == nil && len(match.Value.Examples) == 0 {
+				match.Value.Example = generatedParam.Value.Example
+			}
+			match.Value.Required = match.Value.Required || generatedParam.Value.Required
+			match.Value.Schema = mergeSchemaRef(match.Value.Schema, generatedParam.Value.Schema)
+		}
+	}
+	return existing
+}
+
+// mergeResponse updates an existing response with the observed headers and content
+func mergeResponse(existing, generated *openapi3.Response) {
+	if existing.Description == nil || *existing.Description == "" {
+		existing.Description = generated.Description
+	}
+	for name, header := range generated.Headers {
+		if existing.Headers == nil {
+			existing.Headers = openapi3.Headers{}
+		}
+		if _, ok := existing.Headers[name]; !ok {
+			existing.Headers[name] = header
+		}
+	}
+	mergeContent(&existing.Content, generated.Content)
+	mergeExtensions(&existing.Extensions, generated.Extensions)
+}
+
+// mergeContent updates the schemas of existing media types, keeping their examples, and
+// adds newly observed media types
+func mergeContent(existing *openapi3.Content, generated openapi3.Content) {
+	for mediaType, generatedMedia := range generated {
+		if *existing == nil {
+			*existing = openapi3.Content{}
+		}
+		existingMedia := (*existing)[mediaType]
+		if existingMedia == nil {
+			(*existing)[mediaType] = generatedMedia
+			continue
+		}
+
+		existingMedia.Schema = mergeSchemaRef(existingMedia.Schema, generatedMedia.Schema)
+		if existingMedia.Example == nil && len(existingMedia.Examples) == 0 {
+			existingMedia.Example = generatedMedia.Example
+			existingMedia.Examples = generatedMedia.Examples
+		}
+	}
+}
+
+// mergeSchemaRef takes the generated schema, carrying over the documentation of the existing
+// one. Component references are kept as they are; components are merged separately.
+func mergeSchemaRef(existing, generated *openapi3.SchemaRef) *openapi3.SchemaRef {
+	if generated == nil {
+		return existing
+	}
+	if existing != nil && existing.Ref != "" {
+		// Hand-written references are kept, e.g. to a renamed component
+		return existing
+	}
+	if existing == nil || existing.Value == nil || generated.Ref != "" || generated.Value == nil {
+		return generated
+	}
+
+	preserveSchemaDocs(generated.Value, existing.Value)
+	return generated
+}
+
+// preserveSchemaDocs copies the descriptions, titles, and examples of an existing schema and
+// its properties onto the generated schema that replaces it
+func preserveSchemaDocs(generated, existing *openapi3.Schema) {
+	if existing.Title != "" {
+		generated.Title = existing.Title
+	}
+	if existing.Description != "" {
+		generated.Description = existing.Description
+	}
+	if existing.Example != nil {
+		generated.Example = existing.Example
+	}
+	if existing.Deprecated {
+		generated.Deprecated = true
+	}
+	for key, value := range existing.Extensions {
+		if generated.Extensions == nil {
+			generated.Extensions = make(map[string]interface{})
+		}
+		generated.Extensions[key] = value
+	}
+
+	for name, prop := range generated.Properties {
+		existingProp := existing.Properties[name]
+		if prop == nil || prop.Ref != "" || prop.Value == nil || existingProp == nil || existingProp.Value == nil {
+			continue
+		}
+		preserveSchemaDocs(prop.Value, existingProp.Value)
+	}
+
+	if generated.Items != nil && generated.Items.Ref == "" && generated.Items.Value != nil &&
+		existing.Items != nil && existing.Items.Value != nil {
+		preserveSchemaDocs(generated.Items.Value, existing.Items.Value)
+	}
+}
+
+// mergeComponents updates component schemas with the generated ones and adds new components,
+// keeping hand-written components the generated document doesn't have
+func mergeComponents(existing, generated *OpenAPISpec) {
+	if generated.Components == nil {
+		return
+	}
+	if existing.Components == nil {
+		existing.Components = generated.Components
+		return
+	}
+
+	if existing.Components.Schemas == nil {
+		existing.Components.Schemas = openapi3.Schemas{}
+	}
+	for name, schema := range generated.Components.Schemas {
+		existing.Components.Schemas[name] = mergeSchemaRef(existing.Components.Schemas[name], schema)
+	}
+
+	if existing.Components.SecuritySchemes == nil {
+		existing.Components.SecuritySchemes = openapi3.SecuritySchemes{}
+	}
+	for name, scheme := range generated.Components.SecuritySchemes {
+		if _, ok := existing.Components.SecuritySchemes[name]; !ok {
+			existing.Components.SecuritySchemes[name] = scheme
+		}
+	}
+}
+
+// mergeTags adds newly used tags, keeping the descriptions of existing ones
+func mergeTags(existing, generated *OpenAPISpec) {
+	for _, tag := range generated.Tags {
+		if existing.Tags.Get(tag.Name) == nil {
+			existing.Tags = append(existing.Tags, tag)
+		}
+	}
+}
+
+// mergeExtensions adds extensions that aren't already set
+func mergeExtensions(existing *map[string]interface{}, generated map[string]interface{}) {
+	for key, value := range generated {
+		if *existing == nil {
+			*existing = make(map[string]interface{})
+		}
+		if _, ok := (*existing)[key]; !ok {
+			(*existing)[key] = value
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const existingSpec = `openapi: 3.0.3
+info:
+  title: Curated API
+  version: 2.0.0
+tags:
+  - name: users
+    description: Everything about users
+paths:
+  /users/{userId}:
+    get:
+      summary: Fetch a user
+      description: Hand-written description
+      operationId: fetchUser
+      tags: [users]
+      parameters:
+        - name: userId
+          in: path
+          required: true
+          description: The user's ID
+          schema:
+            type: string
+      responses:
+        "200":
+          description: The user
+          content:
+            application/json:
+              example: {"id": 7, "name": "Ada", "email": "ada@example.com"}
+              schema:
+                type: object
+                description: A user
+                properties:
+                  name:
+                    type: string
+                    description: Full name
+  /legacy:
+    get:
+      responses:
+        "200":
+          description: Not observed anymore
+`
+
+func TestMergeSpecs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(existingSpec), 0644))
+
+	existing, err := LoadSpec(path)
+	require.NoError(t, err)
+
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Generated", Version: "1.0.0"})
+	generator.AddTransaction(createTestTransaction("GET", "/users/1", nil, []byte(`{"id":"__integer__","name":"__string__","email":"__string__"}`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/users/2", nil, []byte(`{"id":"__integer__","name":"__string__","email":"__string__"}`), 200))
+	generator.AddTransaction(createTestTransaction("POST", "/orders", []byte(`{"sku":"__string__"}`), []byte(`{"id":"__integer__"}`), 201))
+	generated, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	merged := MergeSpecs(existing, generated)
+
+	// Hand-written documentation is preserved
+	assert.Equal(t, "Curated API", merged.Info.Title)
+	assert.Equal(t, "Everything about users", merged.Tags.Get("users").Description)
+
+	op := merged.Paths.Value("/users/{userId}").Get
+	require.NotNil(t, op)
+	assert.Equal(t, "Fetch a user", op.Summary)
+	assert.Equal(t, "Hand-written description", op.Description)
+	assert.Equal(t, "fetchUser", op.OperationID)
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, "The user's ID", op.Parameters[0].Value.Description)
+
+	media := op.Responses.Value("200").Value.Content.Get("application/json")
+	assert.Equal(t, "The user", *op.Responses.Value("200").Value.Description)
+	assert.Equal(t, map[string]interface{}{"id": 7.0, "name": "Ada", "email": "ada@example.com"}, media.Example)
+
+	// Schemas are updated from the observed traffic
+	schema := media.Schema.Value
+	assert.Equal(t, "A user", schema.Description)
+	assert.Contains(t, schema.Properties, "email")
+	assert.Equal(t, "Full name", schema.Properties["name"].Value.Description)
+
+	// Newly observed endpoints are added and unobserved ones are kept
+	assert.NotNil(t, merged.Paths.Value("/orders").Post)
+	assert.NotNil(t, merged.Paths.Value("/legacy").Get)
+
+	assert.NoError(t, ValidateSpec(merged))
+}
+
+func TestMarshalSpecYAML(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, []byte(`[{"id":"__integer__"}]`), 200))
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	data, err := MarshalSpec(spec, "openapi.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "openapi: 3.0.3")
+
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	loaded, err := LoadSpec(path)
+	require.NoError(t, err)
+	assert.NotNil(t, loaded.Paths.Value("/users"))
+
+	data, err = MarshalSpec(spec, "openapi.json")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"openapi": "3.0.3"`)
+}
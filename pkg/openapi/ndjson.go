@@ -0,0 +1,89 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ndjsonStream holds the items of the newline-delimited JSON responses of one status code
+type ndjsonStream struct {
+	mediaType string
+	items     []interface{}
+}
+
+// decodeNDJSON decodes the values of a newline-delimited JSON body: one with an NDJSON media
+// type, or one that isn't a single JSON value but has a JSON value on each of several lines
+func decodeNDJSON(mediaType string, body []byte) ([]interface{}, bool) {
+	if !proxy.IsNDJSONContentType(mediaType) && (json.Valid(body) || bytes.Count(bytes.TrimSpace(body), []byte("\n")) == 0) {
+		return nil, false
+	}
+
+	var items []interface{}
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var item interface{}
+		if err := json.Unmarshal(line, &item); err != nil {
+			return nil, false
+		}
+		items = append(items, item)
+	}
+	return items, len(items) > 0
+}
+
+// addNDJSONResponses documents newline-delimited JSON responses as an array of their merged
+// items, marked with x-stream: ndjson since the items arrive one per line rather than in a JSON
+// array. streams maps "METHOD /templated/path" to the streams of every status code.
+func (g *OpenAPIGenerator) addNDJSONResponses(doc *OpenAPISpec, streams map[string]map[string]*ndjsonStream) {
+	for key, statuses := range streams {
+		op := operationForKey(doc, key)
+		if op == nil || op.Responses == nil {
+			continue
+		}
+
+		codes := make([]string, 0, len(statuses))
+		for code := range statuses {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			response := op.Responses.Value(code)
+			if response == nil || response.Value == nil {
+				continue
+			}
+			stream := statuses[code]
+
+			var schemas []parser.Schema
+			for _, item := range stream.items {
+				if schema, err := g.parseJSONBody(item); err == nil && schema != nil {
+					schemas = append(schemas, *schema)
+				}
+			}
+			if len(schemas) == 0 {
+				continue
+			}
+			items := g.refineSchema(parser.MergeSchemaList(schemas), stream.items)
+
+			schema := openapi3.NewArraySchema().WithItems(toOpenAPISchema(items))
+			schema.Description = "Stream of newline-delimited JSON values, one item per line"
+			schema.Extensions = map[string]interface{}{"x-stream": "ndjson"}
+
+			mediaType := stream.mediaType
+			if mediaType == "" {
+				mediaType = "application/x-ndjson"
+			}
+			if response.Value.Content == nil {
+				response.Value.Content = openapi3.Content{}
+			}
+			response.Value.Content[mediaType] = openapi3.NewMediaType().WithSchema(schema)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeNDJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		body      string
+		items     int
+	}{
+		{name: "ndjson media type", mediaType: "application/x-ndjson", body: "{\"id\":1}\n", items: 1},
+		{name: "untyped lines", mediaType: "application/json", body: "{\"id\":1}\n{\"id\":2}\n", items: 2},
+		{name: "single json value", mediaType: "application/json", body: "{\n  \"id\": 1\n}", items: 0},
+		{name: "invalid line", mediaType: "application/x-ndjson", body: "{\"id\":1}\nnot json\n", items: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, ok := decodeNDJSON(tt.mediaType, []byte(tt.body))
+			assert.Equal(t, tt.items > 0, ok)
+			assert.Len(t, items, tt.items)
+		})
+	}
+}
+
+func TestGenerateSpecNDJSON(t *testing.T) {
+	tx := createTestTransaction("GET", "/events", nil,
+		[]byte("{\"id\":\"__integer__\",\"type\":\"created\"}\n{\"id\":\"__integer__\",\"type\":\"created\",\"note\":\"__string__\"}\n"), 200)
+	tx.Response.Headers.Set("Content-Type", "application/x-ndjson")
+
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Events API", Version: "1.0.0"})
+	generator.AddTransaction(tx)
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	response := spec.Paths.Find("/events").Get.Responses.Value("200")
+	require.NotNil(t, response)
+	mediaType := response.Value.Content.Get("application/x-ndjson")
+	require.NotNil(t, mediaType)
+
+	schema := mediaType.Schema.Value
+	assert.True(t, schema.Type.Is("array"))
+	assert.Equal(t, "ndjson", schema.Extensions["x-stream"])
+	items := schema.Items.Value
+	assert.Contains(t, items.Properties, "id")
+	assert.Contains(t, items.Properties, "note")
+	assert.Equal(t, []string{"id", "type"}, items.Required)
+
+	require.NoError(t, ValidateSpec(spec))
+}
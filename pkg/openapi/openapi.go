@@ -1,15 +1,16 @@
 package openapi
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"net/url"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/parnexcodes/swag-doc/pkg/parser"
 	"github.com/parnexcodes/swag-doc/pkg/proxy"
@@ -20,28 +21,98 @@ import (
 // OpenAPISpec is an alias for openapi3.T to make it clearer
 type OpenAPISpec = openapi3.T
 
-// OpenAPIGenerator generates OpenAPI specs from API transactions
+// OpenAPIGenerator generates OpenAPI specs from API transactions. Transactions may be added from
+// several goroutines at once, also while a spec is generated from the ones added before.
 type OpenAPIGenerator struct {
-	config       OpenAPIConfig
+	config  OpenAPIConfig
+	schemas map[string]*openapi3.Schema
+
+	mutex        sync.Mutex // guards transactions
 	transactions []proxy.APITransaction
-	schemas      map[string]*openapi3.Schema
+
+	generating sync.Mutex // held while a spec is generated, guards stats and hooks
+	stats      GenerationStats
+	hooks      generationHooks
 }
 
 // OpenAPIConfig holds configuration for the generator
 type OpenAPIConfig struct {
-	Title           string
-	Description     string
-	Version         string
-	Servers         []OpenAPIServer
-	TagMappings     map[string]string // Maps path prefixes to custom tags
-	UsePathGroups   bool              // Whether to group APIs by path segments
-	VersionPrefixes map[string]bool   // Custom version prefixes to detect
+	Title              string
+	Description        string
+	Version            string
+	Servers            []OpenAPIServer
+	InferServers       bool // Whether to add servers for the Host/X-Forwarded-Host of captured requests
+	Contact            OpenAPIContact
+	License            OpenAPILicense
+	TermsOfService     string // URL of the terms of service
+	ExternalDocs       OpenAPIExternalDocs
+	TagMappings        map[string]string // Maps path prefixes to custom tags
+	TagDescriptions    map[string]string // Maps tags to their descriptions
+	TagGroups          []OpenAPITagGroup // Sections of tags emitted as x-tagGroups
+	UsePathGroups      bool              // Whether to group APIs by path segments
+	VersionPrefixes    map[string]bool   // Custom version prefixes to detect
+	PathTemplates      map[string]string // Maps wildcard path patterns to forced templates
+	StripPrefixes      []string          // Path prefixes moved from the paths into the server URLs
+	InferConstraints   bool              // Whether to add observed min/max, length, and pattern constraints
+	RequiredThreshold  float64           // Fraction of samples a property must appear in to be required (default 1)
+	SplitMediaTypes    bool              // Whether versioned vendor media types get their own schemas instead of one merged schema
+	CodeSamples        bool              // Whether to add x-codeSamples snippets (curl, JavaScript, Go, Python) to operations
+	ResponseTimes      bool              // Whether to add x-response-time latency percentiles of the captured requests to operations
+	CallFrequency      bool              // Whether to add x-call-frequency to operations and order tags and operations by their calls
+	MinCalls           int               // Endpoints captured fewer times are left out as noise, e.g. typos and scanners (default: all kept)
+	ErrorRates         bool              // Whether to add x-observed-error-rate to operations and report the ones failing often
+	ErrorRateThreshold float64           // Share of 5xx responses above which an operation is reported as flaky (default: 0.05)
+	SessionStarts      []time.Time       // Start times of the capture sessions the transactions come from
+	StaleSessions      int               // Operations not called in this many most recent sessions are reported as stale (default: none)
+	DeprecateStale     bool              // Whether to mark stale operations deprecated
+	Owners             []OpenAPIOwner    // Owners and teams of path prefixes, emitted as x-owner/x-team
+	ResponseStrategy   ResponseStrategy  // Which transactions of an endpoint are documented (default: all of them)
+	StatusDescriptions map[string]string // Maps status codes, e.g. "429", to response descriptions
+	IncludePaths       []string          // Regular expressions, only request paths matching one are documented
+	ExcludePaths       []string          // Regular expressions, request paths matching one are left out
+	IncludeMethods     []string          // Only these methods are documented
+	ExcludeMethods     []string          // These methods are left out
+	IncludeHeaders     []string          // Request headers documented as parameters even if they are common, e.g. Accept-Language
+	ExcludeHeaders     []string          // Request headers never documented as parameters, e.g. X-Request-Id
+	RequiredHeaders    []string          // Request headers documented as required parameters of every operation, e.g. X-Org-Id
+	Workers            int               // Goroutines decoding bodies and merging schemas (default: the number of CPUs)
+	MaxFieldSamples    int               // Values of every field type inference looks at (default 10)
+	MaxEndpointSamples int               // Bodies kept for inference and distinct schemas merged per endpoint (default 1000)
+	Cache              *AnalysisCache    // Analysis of the last generation to reuse, updated with this one's, or nil
+
+	// OperationDocs maps "METHOD /templated/path" to human-written summaries, descriptions,
+	// and tags that replace the generated ones
+	OperationDocs map[string]OpenAPIOperationDoc
+
+	// Progress, if set, is called with the number of transactions of a phase (PhasePatternAnalysis
+	// or PhaseSchemaMerge) done so far, so that long generations can show their progress
+	Progress func(phase string, done, total int)
 }
 
 // OpenAPIServer represents an API server in the OpenAPI spec
 type OpenAPIServer struct {
-	URL         string
+	URL         string // may contain {name} placeholders, e.g. https://{env}.api.example.com
+	Description string
+	Variables   map[string]OpenAPIServerVariable
+}
+
+// OpenAPIContact is the contact information for the API in the OpenAPI spec
+type OpenAPIContact struct {
+	Name  string
+	Email string
+	URL   string
+}
+
+// OpenAPILicense is the license of the API in the OpenAPI spec
+type OpenAPILicense struct {
+	Name string
+	URL  string
+}
+
+// OpenAPIExternalDocs links to additional documentation of the API
+type OpenAPIExternalDocs struct {
 	Description string
+	URL         string
 }
 
 // NewOpenAPIGenerator creates a new OpenAPI generator
@@ -62,11 +133,31 @@ func NewOpenAPIGenerator(config OpenAPIConfig) *OpenAPIGenerator {
 	}
 }
 
-// AddTransaction adds an API transaction to be analyzed
+// AddTransaction adds an API transaction to be analyzed. It has the signature of a
+// proxy.APIInterceptor, so a proxy can feed the generator directly.
 func (g *OpenAPIGenerator) AddTransaction(tx proxy.APITransaction) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
 	g.transactions = append(g.transactions, tx)
 }
 
+// AddTransactionsFrom adds every transaction of a storage, streamed one at a time from storages
+// that support it rather than loaded all at once
+func (g *OpenAPIGenerator) AddTransactionsFrom(storage proxy.Storage) error {
+	return proxy.EachTransaction(storage, func(tx proxy.APITransaction) error {
+		g.AddTransaction(tx)
+		return nil
+	})
+}
+
+// capturedTransactions returns the transactions added so far
+func (g *OpenAPIGenerator) capturedTransactions() []proxy.APITransaction {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	// Later appends must not write into the returned slice
+	return g.transactions[:len(g.transactions):len(g.transactions)]
+}
+
 // LoadTransactionsFromFile loads API transactions from a file
 func (g *OpenAPIGenerator) LoadTransactionsFromFile(filename string) error {
 	data, err := ioutil.ReadFile(filename)
@@ -79,6 +170,8 @@ func (g *OpenAPIGenerator) LoadTransactionsFromFile(filename string) error {
 		return err
 	}
 
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
 	g.transactions = append(g.transactions, transactions...)
 	return nil
 }
@@ -104,30 +197,26 @@ func (g *OpenAPIGenerator) LoadTransactionsFromDirectory(dirPath string) error {
 	return nil
 }
 
-// GenerateSpec generates an OpenAPI spec from the collected transactions
+// GenerateSpec generates an OpenAPI spec from the transactions added so far. Calls are
+// serialized, and transactions added during a call are left for the next one.
 func (g *OpenAPIGenerator) GenerateSpec() (*OpenAPISpec, error) {
+	g.generating.Lock()
+	defer g.generating.Unlock()
 	return g.generateAPI()
 }
 
-// Helper function to decode Base64 response body if needed
-func maybeDecodeBase64(data []byte) ([]byte, error) {
-	// Check if data is base64 encoded
-	if len(data) > 0 && data[0] == 'W' {
-		// Try to decode base64
-		decoded, err := base64.StdEncoding.DecodeString(string(data))
-		if err == nil {
-			// If decoding worked and result looks like JSON, return it
-			if len(decoded) > 0 && (decoded[0] == '{' || decoded[0] == '[') {
-				return decoded, nil
-			}
-		}
-	}
-	// Return original data if not base64 or decode failed
-	return data, nil
+// Stats returns what the last GenerateSpec call observed beyond the document, for the quality
+// report
+func (g *OpenAPIGenerator) Stats() GenerationStats {
+	g.generating.Lock()
+	defer g.generating.Unlock()
+	return g.stats
 }
 
 // generateAPI generates an OpenAPI document from the transactions
 func (g *OpenAPIGenerator) generateAPI() (*OpenAPISpec, error) {
+	g.stats = GenerationStats{Samples: make(map[string]int)}
+
 	// Create a new OpenAPI document
 	doc := &OpenAPISpec{
 		OpenAPI: "3.0.3",
@@ -138,63 +227,81 @@ func (g *OpenAPIGenerator) generateAPI() (*OpenAPISpec, error) {
 		},
 		Paths: openapi3.NewPaths(),
 	}
+	g.addInfoDetails(doc)
+
+	// Strip the configured prefixes and trailing slashes from the paths
+	transactions, prefixes := g.normalizedTransactions()
 
-	// Add servers if configured
-	for _, server := range g.config.Servers {
-		doc.Servers = append(doc.Servers, &openapi3.Server{
-			URL:         server.URL,
-			Description: server.Description,
-		})
+	// Leave out the paths and methods that are filtered away
+	transactions, err := g.filterTransactions(transactions)
+	if err != nil {
+		return nil, err
+	}
+	if transactions, err = g.dropRareEndpoints(transactions); err != nil {
+		return nil, err
+	}
+
+	// Remember the scenarios and locales of every transaction, then keep the ones the response
+	// strategy selects
+	scenariosByRequest := requestScenarios(transactions)
+	localesByRequest := requestLocales(transactions)
+	conventionsByRequest := requestHeaderConventions(transactions)
+	rpcByRequest := requestRPCCalls(transactions)
+	var latenciesByRequest map[string][]time.Duration
+	if g.config.ResponseTimes {
+		latenciesByRequest = requestLatencies(transactions)
+	}
+	var callsByRequest map[string]int
+	if g.config.CallFrequency {
+		callsByRequest = requestCalls(transactions)
+	}
+	var errorsByRequest map[string]*requestOutcomes
+	if g.config.ErrorRates {
+		errorsByRequest = requestErrors(transactions)
+	}
+	var lastSeenByRequest map[string]time.Time
+	if g.config.StaleSessions > 0 {
+		lastSeenByRequest = requestLastSeen(transactions)
+	}
+	transactions, err = SelectTransactions(transactions, g.config.ResponseStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the configured and observed servers
+	if err := g.addServers(doc, transactions, prefixes); err != nil {
+		return nil, err
 	}
 
 	// Create parser components
-	pathDetector := parser.NewPathPatternDetector()
+	pathDetector, err := g.newPathDetector()
+	if err != nil {
+		return nil, err
+	}
 	authDetector := parser.NewAuthDetector()
 	schemaMerger := parser.NewSchemaMerger()
-	typeInferrer := parser.NewTypeInferrer(10) // Collect up to 10 samples per field
+	schemaMerger.SetMaxSchemas(g.endpointSamples())
+	typeInferrer := parser.NewTypeInferrer(g.fieldSamples())
 
-	// Status code descriptions for responses
-	statusCodeDescriptions := map[string]string{
-		"200": "OK",
-		"201": "Created",
-		"202": "Accepted",
-		"204": "No Content",
-		"400": "Bad Request",
-		"401": "Unauthorized",
-		"403": "Forbidden",
-		"404": "Not Found",
-		"500": "Internal Server Error",
-	}
+	// Decode the JSON bodies once, in parallel
+	decoded := g.decodeBodies(transactions)
 
 	// First pass: analyze paths and auth
-	for _, tx := range g.transactions {
+	for i, tx := range transactions {
+		g.reportProgress(PhasePatternAnalysis, i, len(transactions))
+
 		// Add path for pattern detection
 		pathDetector.AddPath(tx.Request.Path)
 
 		// Create http.Request with headers for auth detection
-		reqHeader := make(http.Header)
-		for k, v := range tx.Request.Headers {
-			if len(v) > 0 {
-				reqHeader.Set(k, v[0])
-			}
-		}
-
-		// Create URL for request
-		reqURL, _ := url.Parse(tx.Request.Path)
-		if tx.Request.QueryParams != nil {
-			reqURL.RawQuery = tx.Request.QueryParams.Encode()
-		}
-
-		authReq := &http.Request{
-			Header: reqHeader,
-			URL:    reqURL,
-		}
+		authReq := authRequest(tx)
 
 		// Create http.Response with headers for auth detection
 		respHeader := make(http.Header)
 		for k, v := range tx.Response.Headers {
-			if len(v) > 0 {
-				respHeader.Set(k, v[0])
+			// Keep every value so multiple Set-Cookie headers are all analyzed
+			for _, value := range v {
+				respHeader.Add(k, value)
 			}
 		}
 
@@ -205,48 +312,188 @@ func (g *OpenAPIGenerator) generateAPI() (*OpenAPISpec, error) {
 		authDetector.AnalyzeTransaction(authReq, authResp)
 
 		// Collect samples for type inference
-		if tx.Request.Body != nil {
-			bodyObj := make(map[string]interface{})
-			if err := json.Unmarshal(tx.Request.Body, &bodyObj); err == nil {
-				typeInferrer.AddSample("request:"+tx.Request.Method+":"+tx.Request.Path, bodyObj)
+		if bodyObj, ok := decoded[i].request.(map[string]interface{}); ok {
+			typeInferrer.AddSample("request:"+tx.Request.Method+":"+tx.Request.Path, bodyObj)
+		}
+
+		switch body := decoded[i].response.(type) {
+		case map[string]interface{}:
+			typeInferrer.AddSample("response:"+tx.Request.Method+":"+tx.Request.Path, body)
+		case []interface{}:
+			// For arrays, add each item as a separate sample for better type inference
+			for _, item := range body {
+				typeInferrer.AddSample("response:"+tx.Request.Method+":"+tx.Request.Path+":item", item)
 			}
 		}
+	}
 
-		if tx.Response.Body != nil {
-			// Decode the response body from base64 if needed
-			decodedBody, err := maybeDecodeBase64(tx.Response.Body)
-			if err == nil {
-				// Try as object first
-				bodyObj := make(map[string]interface{})
-				if err := json.Unmarshal(decodedBody, &bodyObj); err == nil {
-					typeInferrer.AddSample("response:"+tx.Request.Method+":"+tx.Request.Path, bodyObj)
-				} else {
-					// Try as array
-					var bodyArr []interface{}
-					if err := json.Unmarshal(decodedBody, &bodyArr); err == nil {
-						if len(bodyArr) > 0 {
-							// For arrays, add each item as a separate sample for better type inference
-							for _, item := range bodyArr {
-								typeInferrer.AddSample("response:"+tx.Request.Method+":"+tx.Request.Path+":item", item)
-							}
-						}
-					}
-				}
+	// Analyze path patterns, unless the analysis cache has the patterns of the same request paths
+	pathsHash := g.pathsHash(transactions)
+	templates := g.config.Cache.cachedTemplates(pathsHash)
+	if templates == nil {
+		pathDetector.AnalyzePatterns()
+		templates = make(map[string]string)
+		for _, tx := range transactions {
+			if _, exists := templates[tx.Request.Path]; exists {
+				continue
 			}
+			templatedPath := pathDetector.TemplatizePath(tx.Request.Path)
+			if templatedPath == "" {
+				templatedPath = tx.Request.Path
+			}
+			templates[tx.Request.Path] = templatedPath
 		}
 	}
+	g.reportProgress(PhasePatternAnalysis, len(transactions), len(transactions))
 
-	// Analyze path patterns
-	pathDetector.AnalyzePatterns()
+	// Reuse the merged schemas of the endpoints that got no new transactions since the analysis
+	// cache was written
+	templatedPaths := make([]string, len(transactions))
+	for i, tx := range transactions {
+		templatedPaths[i] = templates[tx.Request.Path]
+	}
+	hashes := endpointHashes(transactions, templatedPaths, g.samplingSettings())
+	reused := g.config.Cache.reusableEndpoints(hashes)
+	g.stats.CachedEndpoints = len(reused)
 
 	// Second pass: generate paths and schemas
-	endpoints := make(map[string]map[string]bool) // path -> method -> bool
+	endpoints := make(map[string]map[string]bool)            // path -> method -> bool
+	queryStats := make(map[string]*queryParamStats)          // method + path -> query parameter observations
+	listSamples := make(map[string]proxy.ResponseData)       // method + path -> first successful GET response
+	bodySamples := newSampleReservoirs(g.endpointSamples())  // path + method -> decoded bodies
+	mediaSamples := newSampleReservoirs(g.endpointSamples()) // path + method + versioned media type -> decoded bodies
+	errorSamples := newErrorSamples(g.endpointSamples())     // decoded 4xx/5xx response bodies
+	deprecations := make(map[string]*parser.DeprecationInfo) // method + path -> deprecation signals
+	secured := make(map[string]bool)                         // method + path -> requests carried credentials
+	annotations := make(map[string]OpenAPIOperationDoc)      // method + path -> X-SwagDoc-* annotations
+	scenarios := make(map[string][]string)                   // method + path -> scenario names
+	locales := make(map[string]*localeObservations)          // method + path -> Accept-Language observations
+	conventions := make(map[string]*headerConventions)       // method + path -> idempotency and rate limit headers
+	rpcCalls := make(map[string]*rpcObservations)            // method + path -> JSON-RPC calls
+	requestPaths := make(map[string]bool)                    // method + request path -> observations combined
+	streams := make(map[string]map[string]*ndjsonStream)     // method + path -> status code -> NDJSON response items
+	downloads := make(map[string]map[string]*fileDownload)   // method + path -> status code -> file responses
+
+	for i, tx := range transactions {
+		g.reportProgress(PhaseSchemaMerge, i, len(transactions))
+
+		// Track query parameters across every request to the endpoint
+		templatedPath := templatedPaths[i]
+		endpointKey := tx.Request.Method + " " + templatedPath
+		_, cached := reused[endpointKey]
+		g.stats.Samples[endpointKey]++
+		if len(tx.Request.Annotations) > 0 {
+			annotations[endpointKey] = annotateOperationDoc(annotations[endpointKey], tx.Request.Annotations)
+		}
+
+		// Combine what was observed for every request path of the endpoint, once per path
+		if requestKey := tx.Request.Method + " " + tx.Request.Path; !requestPaths[requestKey] {
+			requestPaths[requestKey] = true
+			if names := scenariosByRequest[requestKey]; len(names) > 0 {
+				scenarios[endpointKey] = appendScenarios(scenarios[endpointKey], names...)
+			}
+			if observed := localesByRequest[requestKey]; observed != nil {
+				if locales[endpointKey] == nil {
+					locales[endpointKey] = newLocaleObservations()
+				}
+				locales[endpointKey].merge(observed)
+			}
+			if observed := conventionsByRequest[requestKey]; observed != nil {
+				if conventions[endpointKey] == nil {
+					conventions[endpointKey] = newHeaderConventions()
+				}
+				conventions[endpointKey].merge(observed)
+			}
+			if observed := rpcByRequest[requestKey]; observed != nil {
+				if rpcCalls[endpointKey] == nil {
+					rpcCalls[endpointKey] = newRPCObservations()
+				}
+				rpcCalls[endpointKey].merge(observed)
+			}
+		}
+
+		if _, exists := queryStats[endpointKey]; !exists {
+			queryStats[endpointKey] = newQueryParamStats()
+		}
+		queryStats[endpointKey].add(tx.Request.QueryParams)
+
+		// Error responses are merged separately so they don't pollute the success schema
+		responseKey := templatedPath + ":response"
+		if tx.Response.StatusCode >= 400 {
+			responseKey = templatedPath + ":error"
+			if sample, ok := newErrorSample(templatedPath, tx); ok {
+				errorSamples.add(sample)
+			}
+		}
+
+		// Keep decoded bodies for required-property and constraint inference, and per
+		// versioned media type for --split-media-types
+		requestContentType := getContentType(tx.Request.Headers)
+		responseContentType := responseMediaType(tx)
+		for key, body := range map[string]interface{}{
+			templatedPath + ":" + tx.Request.Method: decoded[i].request,
+			responseKey + ":" + tx.Request.Method:   decoded[i].response,
+		} {
+			if body != nil {
+				bodySamples.add(key, body)
+
+				mediaType := requestContentType
+				if strings.HasPrefix(key, responseKey+":") {
+					mediaType = responseContentType
+				}
+				if mediaTypeVersion(mediaType) != "" {
+					mediaSamples.add(mediaSampleKey(key, mediaType), body)
+				}
+			}
+		}
+
+		// Keep the items of newline-delimited JSON responses, which aren't a single JSON value
+		if items, ok := decodeNDJSON(responseContentType, tx.Response.Body); ok {
+			statusCode := fmt.Sprintf("%d", tx.Response.StatusCode)
+			if streams[endpointKey] == nil {
+				streams[endpointKey] = make(map[string]*ndjsonStream)
+			}
+			if streams[endpointKey][statusCode] == nil {
+				streams[endpointKey][statusCode] = &ndjsonStream{mediaType: responseContentType}
+			}
+			streams[endpointKey][statusCode].items = append(streams[endpointKey][statusCode].items, items...)
+		}
 
-	for _, tx := range g.transactions {
-		// Get the templated path
-		templatedPath := pathDetector.TemplatizePath(tx.Request.Path)
-		if templatedPath == "" {
-			templatedPath = tx.Request.Path
+		// Keep the content types and file names of file downloads
+		if filename, ok := downloadFilename(tx.Response.Headers, getContentType(tx.Response.Headers)); ok {
+			statusCode := fmt.Sprintf("%d", tx.Response.StatusCode)
+			if downloads[endpointKey] == nil {
+				downloads[endpointKey] = make(map[string]*fileDownload)
+			}
+			download := downloads[endpointKey][statusCode]
+			if download == nil {
+				download = &fileDownload{mediaTypes: make(map[string]bool)}
+				downloads[endpointKey][statusCode] = download
+			}
+			if mediaType := getContentType(tx.Response.Headers); mediaType != "" {
+				download.mediaTypes[mediaType] = true
+			}
+			download.filenames = append(download.filenames, filename)
+		}
+
+		// Remember endpoints that are called with credentials
+		if hasCredentials(tx) {
+			secured[endpointKey] = true
+		}
+
+		// Collect Deprecation, Sunset, and Warning: 299 signals of any response
+		if info := parser.DetectDeprecation(tx.Response.Headers); info != nil {
+			if existing, ok := deprecations[endpointKey]; ok {
+				existing.Merge(info)
+			} else {
+				deprecations[endpointKey] = info
+			}
+		}
+
+		// Keep a successful GET response per endpoint for pagination detection
+		if _, exists := listSamples[endpointKey]; !exists && tx.Request.Method == "GET" &&
+			tx.Response.StatusCode >= 200 && tx.Response.StatusCode < 300 {
+			listSamples[endpointKey] = tx.Response
 		}
 
 		// Check if we've already processed this endpoint
@@ -256,25 +503,52 @@ func (g *OpenAPIGenerator) generateAPI() (*OpenAPISpec, error) {
 
 		// Skip if we've already processed this method
 		if endpoints[templatedPath][tx.Request.Method] {
-			// Add to schema merger for later merging
-			if tx.Request.Body != nil {
-				bodyObj := make(map[string]interface{})
-				if err := json.Unmarshal(tx.Request.Body, &bodyObj); err == nil {
-					if requestSchema, err := g.parseJSONBody(bodyObj); err == nil && requestSchema != nil {
-						schemaMerger.AddSchema(templatedPath, tx.Request.Method, *requestSchema)
+			statusCode := fmt.Sprintf("%d", tx.Response.StatusCode)
+			var op *openapi3.Operation
+			if pathItem := doc.Paths.Find(templatedPath); pathItem != nil {
+				op = operationFor(pathItem, tx.Request.Method)
+			}
+
+			// Add to schema merger for later merging. The schemas of cached endpoints are merged
+			// already, so their bodies are only parsed for status codes and media types not
+			// documented yet.
+			var requestSchema *parser.Schema
+			if tx.Request.Body != nil && (!cached || missingMediaType(op, "", requestContentType)) {
+				if bodyObj, ok := decoded[i].request.(map[string]interface{}); ok {
+					if schema, err := g.parseJSONBody(bodyObj); err == nil && schema != nil {
+						requestSchema = schema
+						if !cached {
+							schemaMerger.AddSchema(templatedPath, tx.Request.Method, *schema)
+						}
 					}
 				}
 			}
 
-			if tx.Response.Body != nil {
-				bodyObj := make(map[string]interface{})
-				if err := json.Unmarshal(tx.Response.Body, &bodyObj); err == nil {
-					if responseSchema, err := g.parseJSONBody(bodyObj); err == nil && responseSchema != nil {
-						schemaMerger.AddSchema(templatedPath+":response", tx.Request.Method, *responseSchema)
+			var responseSchema *parser.Schema
+			if tx.Response.Body != nil && (!cached || missingMediaType(op, statusCode, responseContentType)) {
+				if bodyObj, ok := decoded[i].response.(map[string]interface{}); ok {
+					if schema, err := g.parseJSONBody(bodyObj); err == nil && schema != nil {
+						responseSchema = schema
+						if !cached {
+							schemaMerger.AddSchema(responseKey, tx.Request.Method, *schema)
+						}
 					}
 				}
 			}
 
+			// Document status codes and media types that the first transaction didn't show
+			if op != nil {
+				if response := op.Responses.Value(statusCode); response == nil {
+					description := g.statusDescription(tx.Response.StatusCode)
+					op.Responses.Set(statusCode, newResponse(description, responseContentType, responseSchema))
+				} else if response.Value != nil {
+					addMediaType(response.Value.Content, responseContentType, responseSchema)
+				}
+				if op.RequestBody != nil && op.RequestBody.Value != nil {
+					addMediaType(op.RequestBody.Value.Content, requestContentType, requestSchema)
+				}
+			}
+
 			continue
 		}
 
@@ -324,33 +598,10 @@ func (g *OpenAPIGenerator) generateAPI() (*OpenAPISpec, error) {
 			})
 		}
 
-		// Add query parameters if available
-		if tx.Request.QueryParams != nil {
-			for name, values := range tx.Request.QueryParams {
-				var example interface{}
-				if len(values) > 0 {
-					example = values[0]
-				}
-
-				op.Parameters = append(op.Parameters, &openapi3.ParameterRef{
-					Value: &openapi3.Parameter{
-						Name:        name,
-						In:          "query",
-						Required:    false,
-						Description: "",
-						Schema: &openapi3.SchemaRef{
-							Value: openapi3.NewStringSchema(),
-						},
-						Example: example,
-					},
-				})
-			}
-		}
-
 		// Add headers (excluding common headers)
 		for name, values := range tx.Request.Headers {
 			// Skip common headers and auth headers (handled separately)
-			if isCommonHeader(name) || isAuthHeader(name) {
+			if !g.isHeaderParam(name) {
 				continue
 			}
 
@@ -373,11 +624,33 @@ func (g *OpenAPIGenerator) generateAPI() (*OpenAPISpec, error) {
 			})
 		}
 
+		g.addRequiredHeaders(op)
+
+		// Add cookies that aren't used for session auth as cookie parameters
+		for _, cookie := range requestCookies(tx.Request.Headers) {
+			if parser.IsSessionCookie(cookie.Name) {
+				continue
+			}
+
+			op.Parameters = append(op.Parameters, &openapi3.ParameterRef{
+				Value: &openapi3.Parameter{
+					Name:        cookie.Name,
+					In:          "cookie",
+					Required:    false,
+					Description: "",
+					Schema: &openapi3.SchemaRef{
+						Value: openapi3.NewStringSchema(),
+					},
+				},
+			})
+		}
+
 		// Parse request body
 		var requestSchema *parser.Schema
 		if tx.Request.Body != nil {
-			bodyObj := make(map[string]interface{})
-			if err := json.Unmarshal(tx.Request.Body, &bodyObj); err == nil {
+			if schema := nonJSONBodySchema(getContentType(tx.Request.Headers), tx.Request.Body); schema != nil {
+				requestSchema = schema
+			} else if bodyObj, ok := decoded[i].request.(map[string]interface{}); ok {
 				requestSchema, _ = g.parseJSONBody(bodyObj)
 			}
 		}
@@ -390,17 +663,16 @@ func (g *OpenAPIGenerator) generateAPI() (*OpenAPISpec, error) {
 
 			// Apply type inference to improve schema quality
 			samples := []interface{}{}
-			if tx.Request.Body != nil {
-				bodyObj := make(map[string]interface{})
-				if err := json.Unmarshal(tx.Request.Body, &bodyObj); err == nil {
-					samples = append(samples, bodyObj)
-				}
+			if bodyObj, ok := decoded[i].request.(map[string]interface{}); ok {
+				samples = append(samples, bodyObj)
 			}
 
-			*requestSchema = parser.ApplyTypeInference(*requestSchema, samples, 10)
+			*requestSchema = parser.ApplyTypeInference(*requestSchema, samples, g.fieldSamples())
 
 			// Add to schema merger for future refinement
-			schemaMerger.AddSchema(templatedPath, tx.Request.Method, *requestSchema)
+			if !cached {
+				schemaMerger.AddSchema(templatedPath, tx.Request.Method, *requestSchema)
+			}
 
 			op.RequestBody = &openapi3.RequestBodyRef{
 				Value: &openapi3.RequestBody{
@@ -418,105 +690,47 @@ func (g *OpenAPIGenerator) generateAPI() (*OpenAPISpec, error) {
 
 		// Parse response
 		var responseSchema *parser.Schema
-		if tx.Response.Body != nil {
-			// Decode the response body from base64 if needed
-			decodedBody, err := maybeDecodeBase64(tx.Response.Body)
-			if err == nil {
-				// Try as object first
-				bodyObj := make(map[string]interface{})
-				if err := json.Unmarshal(decodedBody, &bodyObj); err == nil {
-					responseSchema, _ = g.parseJSONBody(bodyObj)
+		if schema := nonJSONBodySchema(getContentType(tx.Response.Headers), tx.Response.Body); schema != nil {
+			responseSchema = schema
+		} else {
+			switch body := decoded[i].response.(type) {
+			case map[string]interface{}:
+				responseSchema, _ = g.parseJSONBody(body)
+			case []interface{}:
+				if len(body) > 0 {
+					// Create array schema from all items
+					responseSchema, _ = g.parseJSONBody(body)
+					if responseSchema != nil && responseSchema.Items == nil {
+						responseSchema = nil
+					}
 				} else {
-					// Try as array
-					var bodyArr []interface{}
-					if err := json.Unmarshal(decodedBody, &bodyArr); err == nil {
-						if len(bodyArr) > 0 {
-							// Create array schema with the first item as a sample
-							itemSchema, _ := g.parseJSONBody(bodyArr[0])
-							if itemSchema != nil {
-								// For array items, use the first item as an example
-								// This ensures we have realistic example data
-								responseSchema = &parser.Schema{
-									Type:    "array",
-									Items:   itemSchema,
-									Example: bodyArr, // Use the actual array as an example
-								}
-							}
-						} else {
-							// Empty array - create a default array schema
-							responseSchema = &parser.Schema{
-								Type:  "array",
-								Items: &parser.Schema{Type: "string"},
-							}
-						}
+					// Empty array - create a default array schema
+					responseSchema = &parser.Schema{
+						Type:  "array",
+						Items: &parser.Schema{Type: "string"},
 					}
 				}
 			}
 		}
 
-		if responseSchema != nil {
-			statusCode := fmt.Sprintf("%d", tx.Response.StatusCode)
-			contentType := getContentType(tx.Response.Headers)
-			if contentType == "" {
-				contentType = "application/json"
-			}
+		statusCode := fmt.Sprintf("%d", tx.Response.StatusCode)
+		description := g.statusDescription(tx.Response.StatusCode)
 
+		if responseSchema != nil {
 			// Apply type inference to improve schema quality
 			samples := []interface{}{}
-			if tx.Response.Body != nil {
-				// Try as object
-				bodyObj := make(map[string]interface{})
-				if err := json.Unmarshal(tx.Response.Body, &bodyObj); err == nil {
-					samples = append(samples, bodyObj)
-				} else {
-					// Try as array of objects
-					var bodyArr []interface{}
-					if err := json.Unmarshal(tx.Response.Body, &bodyArr); err == nil {
-						// For arrays, preserve the whole array as an example
-						if responseSchema.Type == "array" && responseSchema.Items != nil {
-							// Keep the original response as an example
-							responseSchema.Example = bodyArr
-						}
-					}
-				}
+			if bodyObj, ok := decoded[i].response.(map[string]interface{}); ok {
+				samples = append(samples, bodyObj)
 			}
 
-			*responseSchema = parser.ApplyTypeInference(*responseSchema, samples, 10)
+			*responseSchema = parser.ApplyTypeInference(*responseSchema, samples, g.fieldSamples())
 
 			// Add to schema merger for future refinement
-			schemaMerger.AddSchema(templatedPath+":response", tx.Request.Method, *responseSchema)
-
-			description := "Response"
-			if desc, ok := statusCodeDescriptions[statusCode]; ok {
-				description = desc
-			}
-
-			op.Responses.Set(statusCode, &openapi3.ResponseRef{
-				Value: &openapi3.Response{
-					Description: &description,
-					Content: openapi3.Content{
-						contentType: &openapi3.MediaType{
-							Schema: &openapi3.SchemaRef{
-								Value: toOpenAPISchema(*responseSchema),
-							},
-						},
-					},
-				},
-			})
-		} else {
-			// Add a default response
-			statusCode := fmt.Sprintf("%d", tx.Response.StatusCode)
-			description := "Response"
-			if desc, ok := statusCodeDescriptions[statusCode]; ok {
-				description = desc
+			if !cached {
+				schemaMerger.AddSchema(responseKey, tx.Request.Method, *responseSchema)
 			}
-
-			op.Responses.Set(statusCode, &openapi3.ResponseRef{
-				Value: &openapi3.Response{
-					Description: &description,
-				},
-			})
 		}
+		op.Responses.Set(statusCode, newResponse(description, responseContentType, responseSchema))
 
 		// Assign the operation to the path item based on the method
 		switch tx.Request.Method {
@@ -537,57 +751,76 @@ func (g *OpenAPIGenerator) generateAPI() (*OpenAPISpec, error) {
 		}
 	}
 
-	// Apply schema merging to improve schema quality
+	// Add query parameters observed across all requests to each endpoint
 	for path, methods := range endpoints {
+		pathItem := doc.Paths.Find(path)
+		if pathItem == nil {
+			continue
+		}
+
 		for method := range methods {
-			// Merge request schemas
-			mergedSchema := schemaMerger.MergeSchemas(path, method)
-
-			// Update request body schema if it exists
-			pathItem := doc.Paths.Find(path)
-			if pathItem != nil {
-				var op *openapi3.Operation
-				switch method {
-				case "GET":
-					op = pathItem.Get
-				case "POST":
-					op = pathItem.Post
-				case "PUT":
-					op = pathItem.Put
-				case "DELETE":
-					op = pathItem.Delete
-				case "PATCH":
-					op = pathItem.Patch
-				case "HEAD":
-					op = pathItem.Head
-				case "OPTIONS":
-					op = pathItem.Options
-				}
+			op := operationFor(pathItem, method)
+			stats := queryStats[method+" "+path]
+			if op == nil || stats == nil {
+				continue
+			}
 
-				if op != nil && op.RequestBody != nil && op.RequestBody.Value != nil {
-					for mediaType, content := range op.RequestBody.Value.Content {
-						if content.Schema != nil && content.Schema.Value != nil {
-							// Update with merged schema
-							content.Schema.Value = toOpenAPISchema(mergedSchema)
-							op.RequestBody.Value.Content[mediaType] = content
-						}
+			for _, param := range stats.parameters() {
+				op.Parameters = append(op.Parameters, &openapi3.ParameterRef{
+					Value: param,
+				})
+			}
+		}
+	}
+
+	// Apply schema merging to improve schema quality, merging the operations in parallel, and
+	// keep the merged schemas for the next generation
+	allMerged := g.mergeEndpointSchemas(endpoints, schemaMerger, reused, bodySamples)
+	g.updateCache(pathsHash, templates, hashes, allMerged)
+	for _, merged := range allMerged {
+		path, method := merged.path, merged.method
+		pathItem := doc.Paths.Find(path)
+		if pathItem == nil {
+			continue
+		}
+		op := operationFor(pathItem, method)
+
+		// Update request body schema if it exists
+		if op != nil && op.RequestBody != nil && op.RequestBody.Value != nil {
+			for mediaType, content := range op.RequestBody.Value.Content {
+				if content.Schema != nil && content.Schema.Value != nil {
+					// Update with merged schema, or the schema of this media type's version
+					schema := merged.request
+					if versioned, ok := g.splitMediaTypeSchema(mediaSamples, path+":"+method, mediaType); ok {
+						schema = versioned
 					}
+					content.Schema.Value = toOpenAPISchema(schema)
+					op.RequestBody.Value.Content[mediaType] = content
 				}
+			}
+		}
 
-				// Merge response schemas
-				responseSchema := schemaMerger.MergeSchemas(path+":response", method)
-
-				if op != nil && op.Responses != nil {
-					responseMap := op.Responses.Map()
-					for _, response := range responseMap {
-						if response != nil && response.Value != nil {
-							for mediaType, content := range response.Value.Content {
-								if content.Schema != nil && content.Schema.Value != nil {
-									// Update with merged schema
-									content.Schema.Value = toOpenAPISchema(responseSchema)
-									response.Value.Content[mediaType] = content
-								}
+		// Update response schemas, keeping error responses apart from successful ones
+		if op != nil && op.Responses != nil {
+			for statusCode, response := range op.Responses.Map() {
+				schema, samplesKey := merged.response, path+":response:"+method
+				if isErrorStatus(statusCode) {
+					schema, samplesKey = merged.errors, path+":error:"+method
+				}
+				if schema.Type == "" && len(schema.OneOf) == 0 {
+					continue
+				}
+
+				if response != nil && response.Value != nil {
+					for mediaType, content := range response.Value.Content {
+						if content.Schema != nil && content.Schema.Value != nil {
+							// Update with merged schema, or the schema of this media type's version
+							versionedSchema := schema
+							if versioned, ok := g.splitMediaTypeSchema(mediaSamples, samplesKey, mediaType); ok {
+								versionedSchema = versioned
 							}
+							content.Schema.Value = toOpenAPISchema(versionedSchema)
+							response.Value.Content[mediaType] = content
 						}
 					}
 				}
@@ -595,11 +828,97 @@ func (g *OpenAPIGenerator) generateAPI() (*OpenAPISpec, error) {
 		}
 	}
 
+	g.reportProgress(PhaseSchemaMerge, len(transactions), len(transactions))
+
+	// Report the fields whose types the samples disagree on
+	g.collectMixedTypeFields(endpoints, bodySamples)
+
+	// Document pagination on list endpoints
+	for endpointKey, resp := range listSamples {
+		path := strings.TrimPrefix(endpointKey, "GET ")
+		pathItem := doc.Paths.Find(path)
+		if pathItem == nil {
+			continue
+		}
+
+		var body interface{}
+		_ = json.Unmarshal(resp.Body, &body)
+
+		info := parser.DetectPagination(queryStats[endpointKey].names(), resp.Headers, body)
+		applyPagination(pathItem.Get, info, fmt.Sprintf("%d", resp.StatusCode))
+	}
+
+	// Merge paths that differ only in parameter names, then name and describe every operation
+	g.stats.Repairs = repairPathCollisions(doc)
+	g.stats.Repairs = append(g.stats.Repairs, assignOperationMetadata(doc)...)
+
+	// Replace the generated prose with the human-written one: annotations sent by test clients,
+	// then the descriptions file
+	applyOperationDocs(doc, annotations)
+	applyOperationDocs(doc, g.config.OperationDocs)
+
+	// Document the locales of localized operations
+	g.addLocaleParameters(doc, locales)
+
+	// Document idempotency keys, rate limit headers, and rate limited responses
+	g.applyHeaderConventions(doc, conventions)
+
+	// Document the envelope and methods of JSON-RPC endpoints
+	g.applyJSONRPC(doc, rpcCalls)
+
+	// Document newline-delimited JSON streams
+	g.addNDJSONResponses(doc, streams)
+
+	// Document file downloads as binary content
+	addFileDownloads(doc, downloads)
+
+	// List the captured flows every operation took part in
+	addScenarios(doc, scenarios)
+
+	// Summarize the latencies of every operation as a performance baseline
+	addResponseTimes(doc, latenciesByRequest)
+
+	// Summarize how often every operation failed during the capture
+	g.addErrorRates(doc, errorsByRequest)
+
+	// Flag the operations that stopped being called in recent sessions
+	g.markStaleEndpoints(doc, lastSeenByRequest)
+
+	// Mark operations whose responses signaled a deprecation
+	for endpointKey, info := range deprecations {
+		method, path, _ := strings.Cut(endpointKey, " ")
+		if pathItem := doc.Paths.Find(path); pathItem != nil {
+			applyDeprecation(operationFor(pathItem, method), info)
+		}
+	}
+
+	// Connect operations whose responses return the path parameters of others
+	addLinks(doc)
+
+	// Route questions about operations to the teams that own them
+	addOwners(doc, g.config.Owners)
+
 	// Add security schemes
 	doc.Components = &openapi3.Components{
+		Schemas:         openapi3.Schemas{},
 		SecuritySchemes: openapi3.SecuritySchemes{},
 	}
 
+	// Share one Error schema between all error responses
+	errorSchema := g.extractErrorComponent(doc, errorSamples.all())
+
+	// Document the auth failures of secured endpoints as shared responses
+	g.addAuthFailureResponses(doc, secured, errorSchema)
+
+	// Share resource schemas between requests and responses
+	extractResourceComponents(doc)
+
+	// Move polymorphic variants into components referenced by their discriminators
+	extractVariantComponents(doc)
+
+	// Describe nested objects repeated across operations once
+	extractSharedComponents(doc)
+
 	// Apply auth schemes
 	authSchemes := authDetector.GetAuthSchemes()
 	for _, scheme := range authSchemes {
@@ -633,30 +952,29 @@ func (g *OpenAPIGenerator) generateAPI() (*OpenAPISpec, error) {
 		}
 	}
 
-	// Generate tags for the document
-	tagSet := make(map[string]bool)
+	// Add ready-to-copy request snippets
+	if g.config.CodeSamples {
+		addCodeSamples(doc)
+	}
 
-	// Collect all unique tags from operations
-	for _, path := range doc.Paths.Map() {
-		for _, op := range []*openapi3.Operation{
-			path.Get, path.Post, path.Put,
-			path.Delete, path.Options, path.Head,
-			path.Patch, path.Trace,
-		} {
-			if op != nil && len(op.Tags) > 0 {
-				for _, tag := range op.Tags {
-					tagSet[tag] = true
-				}
-			}
-		}
+	// Apply the user's conventions
+	if err := g.hooks.runSchemaHooks(doc); err != nil {
+		return nil, fmt.Errorf("schema hook failed: %w", err)
+	}
+	if err := g.hooks.runOperationHooks(doc); err != nil {
+		return nil, fmt.Errorf("operation hook failed: %w", err)
+	}
+
+	// Declare every tag used by an operation
+	g.addTags(doc)
+
+	// Weigh operations by how often they were called, busiest tags first
+	if g.config.CallFrequency {
+		addCallFrequencies(doc, callsByRequest)
 	}
 
-	// Create tag objects for the specification
-	for tag := range tagSet {
-		doc.Tags = append(doc.Tags, &openapi3.Tag{
-			Name:        tag,
-			Description: fmt.Sprintf("Operations related to %s", tag),
-		})
+	if err := g.hooks.runSpecHooks(doc); err != nil {
+		return nil, fmt.Errorf("spec hook failed: %w", err)
 	}
 
 	return doc, nil
@@ -678,7 +996,6 @@ func (g *OpenAPIGenerator) parseJSONBody(body interface{}) (*parser.Schema, erro
 		schema = parser.Schema{
 			Type:       "object",
 			Properties: make(map[string]parser.Schema),
-			Example:    processedBody, // Store the actual object as an example
 		}
 
 		for key, val := range v {
@@ -687,22 +1004,46 @@ func (g *OpenAPIGenerator) parseJSONBody(body interface{}) (*parser.Schema, erro
 				schema.Required = append(schema.Required, key)
 			}
 		}
+
+		// Objects keyed by data (IDs, locales, dates) are maps rather than records
+		schema = parser.CollapseMapSchema(schema)
 	case []interface{}:
 		schema = parser.Schema{
-			Type:    "array",
-			Example: processedBody, // Store the actual array as an example
+			Type: "array",
 		}
 
-		if len(v) > 0 {
-			if itemSchema, err := g.parseJSONBody(v[0]); err == nil && itemSchema != nil {
-				schema.Items = itemSchema
+		// Merge the schemas of all items so that polymorphic items become a oneOf
+		var itemSchemas []parser.Schema
+		for _, item := range v {
+			if itemSchema, err := g.parseJSONBody(item); err == nil && itemSchema != nil {
+				itemSchemas = append(itemSchemas, *itemSchema)
 			}
 		}
+		if len(itemSchemas) > 0 {
+			merged := parser.MergeSchemaList(itemSchemas)
+			schema.Items = &merged
+		}
 	case string:
+		if v == "__file__" {
+			// File part of a multipart/form-data body
+			schema = parser.Schema{
+				Type:   "string",
+				Format: "binary",
+			}
+			break
+		}
+
 		schema = parser.Schema{
 			Type:    "string",
 			Example: v, // Store the actual string as an example
 		}
+	case int64:
+		// Integer placeholders are converted to int64 samples
+		schema = parser.Schema{
+			Type:    "integer",
+			Format:  "int64",
+			Example: v,
+		}
 	case float64:
 		if v == float64(int(v)) {
 			schema = parser.Schema{
@@ -762,6 +1103,10 @@ func convertPlaceholders(value interface{}) interface{} {
 			return "unknown"
 		case "__redacted__":
 			return "redacted"
+		case "__date__":
+			return "2024-01-01"
+		case "__datetime__":
+			return "2024-01-01T00:00:00Z"
 		default:
 			return v
 		}
@@ -788,6 +1133,8 @@ func isCommonHeader(name string) bool {
 		"Referer":           true,
 		"User-Agent":        true,
 		"X-Forwarded-For":   true,
+		"X-Forwarded-Host":  true,
+		"X-Forwarded-Port":  true,
 		"X-Forwarded-Proto": true,
 	}
 
@@ -810,11 +1157,93 @@ func isAuthHeader(name string) bool {
 	return authHeaders[name]
 }
 
+// Helper function to parse the cookies sent with a request
+func requestCookies(headers http.Header) []*http.Cookie {
+	if headers == nil {
+		return nil
+	}
+
+	req := &http.Request{Header: headers}
+	return req.Cookies()
+}
+
+// addInfoDetails adds the configured contact, license, terms of service, and external docs,
+// which many publishing pipelines require
+func (g *OpenAPIGenerator) addInfoDetails(doc *OpenAPISpec) {
+	if contact := g.config.Contact; contact != (OpenAPIContact{}) {
+		doc.Info.Contact = &openapi3.Contact{
+			Name:  contact.Name,
+			Email: contact.Email,
+			URL:   contact.URL,
+		}
+	}
+	if license := g.config.License; license != (OpenAPILicense{}) {
+		doc.Info.License = &openapi3.License{
+			Name: license.Name,
+			URL:  license.URL,
+		}
+	}
+	doc.Info.TermsOfService = g.config.TermsOfService
+	if docs := g.config.ExternalDocs; docs.URL != "" {
+		doc.ExternalDocs = &openapi3.ExternalDocs{
+			Description: docs.Description,
+			URL:         docs.URL,
+		}
+	}
+}
+
+// refineSchema adds what can only be learned from all body samples of an endpoint to its
+// merged schema: required properties, optional value constraints, and timestamp formats
+func (g *OpenAPIGenerator) refineSchema(schema parser.Schema, samples []interface{}) parser.Schema {
+	schema = parser.InferRequired(schema, samples, g.config.RequiredThreshold)
+	if g.config.InferConstraints {
+		schema = parser.InferConstraints(schema, samples)
+	}
+	return parser.AnnotateTimestamps(schema, samples)
+}
+
+// newResponse creates a response with a body schema, if one was captured
+func newResponse(description, contentType string, schema *parser.Schema) *openapi3.ResponseRef {
+	response := &openapi3.Response{Description: &description}
+	if schema != nil {
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		response.Content = openapi3.Content{
+			contentType: &openapi3.MediaType{
+				Schema: &openapi3.SchemaRef{Value: toOpenAPISchema(*schema)},
+			},
+		}
+	}
+	return &openapi3.ResponseRef{Value: response}
+}
+
 // Helper function to convert a parser.Schema to an openapi3.Schema
 func toOpenAPISchema(schema parser.Schema) *openapi3.Schema {
 	// First, process any examples to convert placeholders
 	schema = processSchemaExamples(schema)
 
+	// Polymorphic schemas become a oneOf of their variants
+	if len(schema.OneOf) > 0 {
+		result := openapi3.NewSchema()
+		result.Description = schema.Description
+		for _, variant := range schema.OneOf {
+			result.OneOf = append(result.OneOf, &openapi3.SchemaRef{
+				Value: toOpenAPISchema(variant),
+			})
+		}
+		if schema.Discriminator != nil {
+			result.Discriminator = &openapi3.Discriminator{
+				PropertyName: schema.Discriminator.PropertyName,
+				Mapping:      openapi3.StringMap{},
+			}
+			for value, name := range schema.Discriminator.Mapping {
+				result.Discriminator.Mapping[value] = name
+			}
+		}
+		return result
+	}
+
 	var result *openapi3.Schema
 
 	switch schema.Type {
@@ -842,6 +1271,7 @@ func toOpenAPISchema(schema parser.Schema) *openapi3.Schema {
 
 	// Copy example values
 	result.Example = schema.Example
+	result.Description = schema.Description
 	result.Nullable = schema.Nullable
 
 	// Convert enum values
@@ -849,6 +1279,26 @@ func toOpenAPISchema(schema parser.Schema) *openapi3.Schema {
 		result.Enum = schema.Enum
 	}
 
+	// Copy observed value constraints
+	result.Min = schema.Minimum
+	result.Max = schema.Maximum
+	if schema.MinLength != nil {
+		result.MinLength = uint64(*schema.MinLength)
+	}
+	if schema.MaxLength != nil {
+		maxLength := uint64(*schema.MaxLength)
+		result.MaxLength = &maxLength
+	}
+	result.Pattern = schema.Pattern
+
+	// Copy vendor extensions such as x-format
+	for key, value := range schema.Extensions {
+		if result.Extensions == nil {
+			result.Extensions = make(map[string]interface{})
+		}
+		result.Extensions[key] = value
+	}
+
 	// Convert properties for objects
 	if schema.Type == "object" && schema.Properties != nil {
 		for name, propSchema := range schema.Properties {
@@ -863,6 +1313,15 @@ func toOpenAPISchema(schema parser.Schema) *openapi3.Schema {
 		}
 	}
 
+	// Convert the value schema of maps
+	if schema.Type == "object" && schema.AdditionalProperties != nil {
+		result.AdditionalProperties = openapi3.AdditionalProperties{
+			Schema: &openapi3.SchemaRef{
+				Value: toOpenAPISchema(*schema.AdditionalProperties),
+			},
+		}
+	}
+
 	// Convert items for arrays
 	if schema.Type == "array" && schema.Items != nil {
 		result.Items = &openapi3.SchemaRef{
@@ -933,6 +1392,12 @@ func convertPlaceholderString(value string) interface{} {
 		return "unknown"
 	case "__redacted__":
 		return "redacted"
+	case "__date__":
+		return "2024-01-01"
+	case "__datetime__":
+		return "2024-01-01T00:00:00Z"
+	case "__file__":
+		return "binary"
 	default:
 		return value
 	}
@@ -953,6 +1418,34 @@ func getContentType(headers http.Header) string {
 	return strings.TrimSpace(contentType)
 }
 
+// sortedTemplatePatterns orders path template patterns so the most specific (most literal
+// segments) are matched first, falling back to alphabetical order for stable output
+func sortedTemplatePatterns(templates map[string]string) []string {
+	patterns := make([]string, 0, len(templates))
+	for pattern := range templates {
+		patterns = append(patterns, pattern)
+	}
+
+	literals := func(pattern string) int {
+		count := 0
+		for _, segment := range strings.Split(strings.Trim(pattern, "/"), "/") {
+			if segment != "*" {
+				count++
+			}
+		}
+		return count
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		li, lj := literals(patterns[i]), literals(patterns[j])
+		if li != lj {
+			return li > lj
+		}
+		return patterns[i] < patterns[j]
+	})
+	return patterns
+}
+
 // extractTagFromPath extracts a tag name from the API path
 func (g *OpenAPIGenerator) extractTagFromPath(path string) string {
 	// Remove leading slash and get first segment
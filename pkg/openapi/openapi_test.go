@@ -1,7 +1,12 @@
 package openapi
 
 import (
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
 	"testing"
 
 	"github.com/parnexcodes/swag-doc/pkg/parser"
@@ -70,6 +75,49 @@ func TestAddTransaction(t *testing.T) {
 	assert.Equal(t, tx, generator.transactions[0])
 }
 
+func TestAddTransactionConcurrently(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+
+	// Transactions arrive from several interceptors while specs are generated
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				generator.AddTransaction(createTestTransaction("GET", fmt.Sprintf("/users/%d", i*25+j), nil,
+					[]byte(`{"id":"__integer__"}`), 200))
+			}
+		}(i)
+	}
+	for i := 0; i < 3; i++ {
+		_, err := generator.GenerateSpec()
+		require.NoError(t, err)
+	}
+	wg.Wait()
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+	assert.NotNil(t, spec.Paths.Find("/users/{userId}"))
+	assert.Equal(t, 200, generator.Stats().Samples["GET /users/{userId}"])
+}
+
+func TestAddTransactionsFrom(t *testing.T) {
+	storage, err := proxy.NewFileStorage(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, storage.Store(createTestTransaction("GET", "/users", nil, []byte(`[]`), 200)))
+	require.NoError(t, storage.Store(createTestTransaction("POST", "/users", []byte(`{"name":"__string__"}`), nil, 201)))
+
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	require.NoError(t, generator.AddTransactionsFrom(storage))
+	assert.Len(t, generator.transactions, 2)
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+	assert.NotNil(t, spec.Paths.Find("/users").Get)
+	assert.NotNil(t, spec.Paths.Find("/users").Post)
+}
+
 func TestGenerateSpec(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -171,6 +219,303 @@ func TestGenerateSpec(t *testing.T) {
 	}
 }
 
+func TestGenerateSpecInfoDetails(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:          "Test API",
+		Version:        "1.0.0",
+		Contact:        OpenAPIContact{Name: "API Team", Email: "api@example.com"},
+		License:        OpenAPILicense{Name: "Apache 2.0", URL: "https://www.apache.org/licenses/LICENSE-2.0"},
+		TermsOfService: "https://example.com/terms",
+		ExternalDocs:   OpenAPIExternalDocs{Description: "Guides", URL: "https://docs.example.com"},
+	})
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, nil, 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	require.NotNil(t, spec.Info.Contact)
+	assert.Equal(t, "API Team", spec.Info.Contact.Name)
+	assert.Equal(t, "api@example.com", spec.Info.Contact.Email)
+	require.NotNil(t, spec.Info.License)
+	assert.Equal(t, "Apache 2.0", spec.Info.License.Name)
+	assert.Equal(t, "https://example.com/terms", spec.Info.TermsOfService)
+	require.NotNil(t, spec.ExternalDocs)
+	assert.Equal(t, "https://docs.example.com", spec.ExternalDocs.URL)
+	assert.NoError(t, ValidateSpec(spec))
+
+	// Nothing is added when the details aren't configured
+	spec, err = NewOpenAPIGenerator(OpenAPIConfig{}).GenerateSpec()
+	require.NoError(t, err)
+	assert.Nil(t, spec.Info.Contact)
+	assert.Nil(t, spec.Info.License)
+	assert.Nil(t, spec.ExternalDocs)
+}
+
+func TestGenerateSpecCookies(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	generator.AddTransaction(proxy.APITransaction{
+		Request: proxy.RequestData{
+			Method: "GET",
+			Path:   "/profile",
+			Headers: http.Header{
+				"Cookie": []string{"session_id=__redacted__; theme=__redacted__"},
+			},
+		},
+		Response: proxy.ResponseData{
+			StatusCode: 200,
+		},
+	})
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	scheme := spec.Components.SecuritySchemes["apiKey_session_id"]
+	require.NotNil(t, scheme)
+	assert.Equal(t, "apiKey", scheme.Value.Type)
+	assert.Equal(t, "cookie", scheme.Value.In)
+
+	op := spec.Paths.Find("/profile").Get
+	require.NotNil(t, op)
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, "theme", op.Parameters[0].Value.Name)
+	assert.Equal(t, "cookie", op.Parameters[0].Value.In)
+}
+
+func TestGenerateSpecMultipart(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	generator.AddTransaction(proxy.APITransaction{
+		Request: proxy.RequestData{
+			Method: "POST",
+			Path:   "/uploads",
+			Headers: http.Header{
+				"Content-Type": []string{"multipart/form-data; boundary=xyz"},
+			},
+			Body: []byte(`{"title":"__string__","photo":"__file__"}`),
+		},
+		Response: proxy.ResponseData{
+			StatusCode: 201,
+		},
+	})
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	op := spec.Paths.Find("/uploads").Post
+	require.NotNil(t, op)
+	require.NotNil(t, op.RequestBody)
+
+	media := op.RequestBody.Value.Content.Get("multipart/form-data")
+	require.NotNil(t, media)
+	photo := media.Schema.Value.Properties["photo"]
+	require.NotNil(t, photo)
+	assert.Equal(t, "binary", photo.Value.Format)
+	assert.True(t, photo.Value.Type.Is("string"))
+}
+
+func TestGenerateSpecPathTemplates(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		PathTemplates: map[string]string{
+			"/files/*":      "/files/{fileName}",
+			"/files/shared": "/files/shared",
+		},
+	})
+	generator.AddTransaction(createTestTransaction("GET", "/files/report.pdf", nil, nil, 200))
+	generator.AddTransaction(createTestTransaction("GET", "/files/shared", nil, nil, 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	assert.NotNil(t, spec.Paths.Find("/files/{fileName}"))
+	assert.NotNil(t, spec.Paths.Value("/files/shared"))
+
+	invalid := NewOpenAPIGenerator(OpenAPIConfig{
+		PathTemplates: map[string]string{"/files/*": "/files"},
+	})
+	_, err = invalid.GenerateSpec()
+	assert.Error(t, err)
+}
+
+func TestGenerateSpecPagination(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	tx := createTestTransaction("GET", "/orders", nil, []byte(`{"data":[{"id":"__integer__"}],"total":"__integer__"}`), 200)
+	tx.Request.QueryParams = url.Values{"page": {"__integer__"}, "per_page": {"__integer__"}}
+	tx.Response.Headers.Set("Link", `<https://api.example.com/orders?page=2>; rel="next"`)
+	generator.AddTransaction(tx)
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	op := spec.Paths.Find("/orders").Get
+	require.NotNil(t, op)
+
+	pagination, ok := op.Extensions["x-pagination"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "page", pagination["style"])
+	assert.Equal(t, "page", pagination["pageParam"])
+	assert.Equal(t, "per_page", pagination["sizeParam"])
+	assert.Equal(t, "data", pagination["itemsField"])
+	assert.Equal(t, "total", pagination["totalField"])
+	assert.Equal(t, true, pagination["linkHeader"])
+
+	for _, param := range op.Parameters {
+		assert.NotEmpty(t, param.Value.Description)
+	}
+
+	response := op.Responses.Value("200")
+	require.NotNil(t, response)
+	assert.NotNil(t, response.Value.Headers["Link"])
+
+	schema := response.Value.Content.Get("application/json").Schema.Value
+	assert.Equal(t, true, schema.Extensions["x-pagination-envelope"])
+	assert.NotEmpty(t, schema.Properties["total"].Value.Description)
+}
+
+func TestGenerateSpecMaps(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	generator.AddTransaction(createTestTransaction("GET", "/products/1", nil,
+		[]byte(`{"id":"__integer__","names":{"en":"__string__","fr":"__string__"}}`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	op := spec.Paths.Find("/products/{productId}").Get
+	require.NotNil(t, op)
+	schema := op.Responses.Value("200").Value.Content.Get("application/json").Schema.Value
+
+	names := schema.Properties["names"]
+	require.NotNil(t, names)
+	assert.Empty(t, names.Value.Properties)
+	require.NotNil(t, names.Value.AdditionalProperties.Schema)
+	assert.True(t, names.Value.AdditionalProperties.Schema.Value.Type.Is("string"))
+}
+
+func TestGenerateSpecConstraints(t *testing.T) {
+	transactions := []proxy.APITransaction{
+		createTestTransaction("GET", "/inventory", nil, []byte(`{"sku":"SKU-0001","stock":4}`), 200),
+		createTestTransaction("GET", "/inventory", nil, []byte(`{"sku":"SKU-0420","stock":120}`), 200),
+		createTestTransaction("GET", "/inventory", nil, []byte(`{"sku":"ABC-0099","stock":17}`), 200),
+	}
+
+	for _, infer := range []bool{false, true} {
+		generator := NewOpenAPIGenerator(OpenAPIConfig{InferConstraints: infer})
+		for _, tx := range transactions {
+			generator.AddTransaction(tx)
+		}
+
+		spec, err := generator.GenerateSpec()
+		require.NoError(t, err)
+
+		schema := spec.Paths.Find("/inventory").Get.Responses.Value("200").Value.Content.Get("application/json").Schema.Value
+		stock := schema.Properties["stock"].Value
+		sku := schema.Properties["sku"].Value
+
+		if !infer {
+			assert.Nil(t, stock.Min)
+			assert.Empty(t, sku.Pattern)
+			continue
+		}
+
+		require.NotNil(t, stock.Min)
+		require.NotNil(t, stock.Max)
+		assert.Equal(t, 4.0, *stock.Min)
+		assert.Equal(t, 120.0, *stock.Max)
+		assert.Equal(t, uint64(8), sku.MinLength)
+		require.NotNil(t, sku.MaxLength)
+		assert.Equal(t, uint64(8), *sku.MaxLength)
+		assert.Equal(t, `^[A-Z]{3}-\d{4}$`, sku.Pattern)
+	}
+}
+
+func TestGenerateSpecTimestamps(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	generator.AddTransaction(createTestTransaction("GET", "/events", nil, []byte(`{"name":"__string__","created_at":"__integer__","attempts":"__integer__"}`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	schema := spec.Paths.Find("/events").Get.Responses.Value("200").Value.Content.Get("application/json").Schema.Value
+
+	createdAt := schema.Properties["created_at"].Value
+	assert.Equal(t, "int64", createdAt.Format)
+	assert.Equal(t, "unix-timestamp", createdAt.Extensions["x-format"])
+
+	attempts := schema.Properties["attempts"].Value
+	assert.Nil(t, attempts.Extensions["x-format"])
+}
+
+func TestGenerateSpecErrorComponent(t *testing.T) {
+	problem := createTestTransaction("POST", "/orders", []byte(`{"sku":"__string__"}`),
+		[]byte(`{"type":"about:blank","title":"__string__","status":"__integer__","detail":"__string__"}`), 422)
+	problem.Response.Headers.Set("Content-Type", "application/problem+json")
+
+	transactions := []proxy.APITransaction{
+		createTestTransaction("GET", "/users/1", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200),
+		createTestTransaction("GET", "/users/2", nil, []byte(`{"message":"__string__","code":"__string__"}`), 404),
+		createTestTransaction("GET", "/products/1", nil, []byte(`{"message":"__string__","code":"__string__","status":"__integer__"}`), 404),
+		problem,
+	}
+
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	for _, tx := range transactions {
+		generator.AddTransaction(tx)
+	}
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	errorSchema := spec.Components.Schemas["Error"]
+	require.NotNil(t, errorSchema)
+	assert.Contains(t, errorSchema.Value.Properties, "message")
+	assert.Contains(t, errorSchema.Value.Properties, "title")
+
+	users := spec.Paths.Find("/users/{userId}").Get
+	require.NotNil(t, users.Responses.Value("404"))
+	assert.Equal(t, "#/components/schemas/Error", users.Responses.Value("404").Value.Content.Get("application/json").Schema.Ref)
+
+	// The error body doesn't leak into the successful response
+	success := users.Responses.Value("200").Value.Content.Get("application/json").Schema
+	assert.Empty(t, success.Ref)
+	assert.NotContains(t, success.Value.Properties, "message")
+
+	orders := spec.Paths.Find("/orders").Post.Responses.Value("422").Value.Content.Get("application/problem+json")
+	require.NotNil(t, orders)
+	assert.Equal(t, "#/components/schemas/Error", orders.Schema.Ref)
+}
+
+func TestGenerateSpecRequiredProperties(t *testing.T) {
+	transactions := []proxy.APITransaction{
+		createTestTransaction("GET", "/profile", nil, []byte(`{"id":"__integer__","name":"__string__","bio":"__string__"}`), 200),
+		createTestTransaction("GET", "/profile", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200),
+		createTestTransaction("GET", "/profile", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200),
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		required  []string
+	}{
+		{name: "present in every sample", threshold: 0, required: []string{"id", "name"}},
+		{name: "present in a third of samples", threshold: 0.3, required: []string{"bio", "id", "name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewOpenAPIGenerator(OpenAPIConfig{RequiredThreshold: tt.threshold})
+			for _, tx := range transactions {
+				generator.AddTransaction(tx)
+			}
+
+			spec, err := generator.GenerateSpec()
+			require.NoError(t, err)
+
+			schema := spec.Paths.Find("/profile").Get.Responses.Value("200").Value.Content.Get("application/json").Schema.Value
+			assert.Equal(t, tt.required, schema.Required)
+			assert.Contains(t, schema.Properties, "bio")
+			assert.Nil(t, schema.Example)
+		})
+	}
+}
+
 func TestParseJSONBody(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -227,10 +572,6 @@ func TestParseJSONBody(t *testing.T) {
 					},
 				},
 				Required: []string{"name", "age"},
-				Example: map[string]interface{}{
-					"name": "John",
-					"age":  float64(30),
-				},
 			},
 		},
 		{
@@ -242,7 +583,6 @@ func TestParseJSONBody(t *testing.T) {
 					Type:    "string",
 					Example: "one",
 				},
-				Example: []interface{}{"one", "two"},
 			},
 		},
 	}
@@ -425,3 +765,93 @@ func createTestTransaction(method, path string, reqBody, respBody []byte, status
 		},
 	}
 }
+
+// captureTestTransactions serves requests with handler behind the proxy's capture middleware and
+// returns the transactions it captured, sanitized as the proxy stores them. The requests are
+// sent to the test server whatever their host.
+func captureTestTransactions(t *testing.T, handler http.Handler, requests ...*http.Request) []proxy.APITransaction {
+	t.Helper()
+	var mu sync.Mutex
+	var transactions []proxy.APITransaction
+	server := httptest.NewServer(proxy.CaptureMiddleware(handler, nil, func(tx proxy.APITransaction) {
+		mu.Lock()
+		defer mu.Unlock()
+		transactions = append(transactions, tx)
+	}))
+	defer server.Close()
+
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	for _, req := range requests {
+		req.URL.Scheme, req.URL.Host, req.Host = target.Scheme, target.Host, target.Host
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return transactions
+}
+
+// generateTestSpec generates the document of transactions with a configuration, titled Test API
+// 1.0.0 unless it sets a title or version, and returns it with the statistics of the generation
+func generateTestSpec(t *testing.T, config OpenAPIConfig, transactions ...proxy.APITransaction) (*OpenAPISpec, GenerationStats) {
+	t.Helper()
+	if config.Title == "" {
+		config.Title = "Test API"
+	}
+	if config.Version == "" {
+		config.Version = "1.0.0"
+	}
+	generator := NewOpenAPIGenerator(config)
+	for _, tx := range transactions {
+		generator.AddTransaction(tx)
+	}
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+	return spec, generator.Stats()
+}
+
+// shopTestConfig configures the Shop API served at https://api.example.com, which the renderers
+// and exporters are tested with
+func shopTestConfig() OpenAPIConfig {
+	return OpenAPIConfig{
+		Title:         "Shop API",
+		Servers:       []OpenAPIServer{{URL: "https://api.example.com"}},
+		UsePathGroups: true,
+	}
+}
+
+// benchmarkTransactions returns the transactions of a small CRUD API over n users
+func benchmarkTransactions(n int) []proxy.APITransaction {
+	var transactions []proxy.APITransaction
+	for i := 0; i < n; i++ {
+		user := fmt.Sprintf(`{"id":%d,"name":"User %d","email":"user%d@example.com","created_at":"2024-01-02T10:00:00Z","address":{"street":"Main St","city":"Berlin","zip":"10115"}}`, i, i, i)
+		transactions = append(transactions,
+			createTestTransaction("GET", fmt.Sprintf("/users/%d", i), nil, []byte(user), 200),
+			createTestTransaction("POST", "/users", []byte(`{"name":"User","email":"user@example.com"}`), []byte(user), 201),
+			createTestTransaction("GET", fmt.Sprintf("/users/%d/orders", i), nil,
+				[]byte(fmt.Sprintf(`[{"id":%d,"total":12.5,"status":"paid","shipping":{"street":"Main St","city":"Berlin","zip":"10115"}}]`, i)), 200),
+			createTestTransaction("DELETE", fmt.Sprintf("/users/%d", i), nil, []byte(`{"error":"not found","code":404}`), 404),
+		)
+	}
+	return transactions
+}
+
+func BenchmarkGenerateSpec(b *testing.B) {
+	transactions := benchmarkTransactions(250)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		generator := NewOpenAPIGenerator(OpenAPIConfig{})
+		for _, tx := range transactions {
+			generator.AddTransaction(tx)
+		}
+		if _, err := generator.GenerateSpec(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
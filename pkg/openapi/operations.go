@@ -0,0 +1,157 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// operationMethods lists HTTP methods in the order operations are visited
+var operationMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "TRACE"}
+
+// assignOperationMetadata fills in operationId, summary, and description for every operation.
+// Paths are visited in sorted order so that generated IDs (and collision suffixes) are stable.
+// It returns a description of every operationId that was renamed to stay unique.
+func assignOperationMetadata(doc *OpenAPISpec) []string {
+	paths := doc.Paths.InMatchingOrder()
+	sort.Strings(paths)
+
+	var repairs []string
+	usedIDs := make(map[string]string) // operationId -> "METHOD /path" of the operation using it
+	for _, path := range paths {
+		pathItem := doc.Paths.Value(path)
+		for _, method := range operationMethods {
+			op := operationFor(pathItem, method)
+			if op == nil {
+				continue
+			}
+
+			if op.OperationID == "" {
+				op.OperationID = parser.GenerateOperationID(method, path)
+			}
+			endpoint := method + " " + path
+			if id := uniqueOperationID(op.OperationID, usedIDs, endpoint); id != op.OperationID {
+				repairs = append(repairs, fmt.Sprintf("Renamed operationId %s of %s to %s, as %s already uses it",
+					op.OperationID, endpoint, id, usedIDs[op.OperationID]))
+				op.OperationID = id
+			}
+
+			if op.Summary == "" {
+				op.Summary = generateSummary(method, path)
+			}
+			if op.Description == "" {
+				op.Description = generateDescription(method, path)
+			}
+		}
+	}
+	return repairs
+}
+
+// operationFor returns the operation of a path item for a method, or nil for methods
+// the path item has no slot for
+func operationFor(pathItem *openapi3.PathItem, method string) *openapi3.Operation {
+	for _, known := range operationMethods {
+		if known == method {
+			return pathItem.GetOperation(method)
+		}
+	}
+	return nil
+}
+
+// uniqueOperationID appends a numeric suffix when an operationId has already been used, and
+// records the endpoint using the returned one
+func uniqueOperationID(id string, used map[string]string, endpoint string) string {
+	candidate := id
+	for i := 2; used[candidate] != ""; i++ {
+		candidate = fmt.Sprintf("%s%d", id, i)
+	}
+	used[candidate] = endpoint
+	return candidate
+}
+
+// generateSummary builds a short human-readable summary such as "List users" or "Get user by id"
+func generateSummary(method, path string) string {
+	resource, param := describePath(path)
+
+	switch strings.ToUpper(method) {
+	case "GET":
+		if param == "" {
+			return "List " + resource
+		}
+		return fmt.Sprintf("Get %s by %s", parser.Singularize(resource), param)
+	case "POST":
+		if param == "" {
+			return "Create " + parser.Singularize(resource)
+		}
+		return fmt.Sprintf("Create %s by %s", parser.Singularize(resource), param)
+	case "PUT", "PATCH":
+		if param == "" {
+			return "Update " + resource
+		}
+		return fmt.Sprintf("Update %s by %s", parser.Singularize(resource), param)
+	case "DELETE":
+		if param == "" {
+			return "Delete " + resource
+		}
+		return fmt.Sprintf("Delete %s by %s", parser.Singularize(resource), param)
+	default:
+		return fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+	}
+}
+
+// generateDescription builds a one-sentence description for an operation
+func generateDescription(method, path string) string {
+	resource, param := describePath(path)
+
+	switch strings.ToUpper(method) {
+	case "GET":
+		if param == "" {
+			return fmt.Sprintf("Returns a list of %s.", resource)
+		}
+		return fmt.Sprintf("Returns a single %s identified by %s.", parser.Singularize(resource), param)
+	case "POST":
+		return fmt.Sprintf("Creates a new %s.", parser.Singularize(resource))
+	case "PUT":
+		return fmt.Sprintf("Replaces an existing %s.", parser.Singularize(resource))
+	case "PATCH":
+		return fmt.Sprintf("Partially updates an existing %s.", parser.Singularize(resource))
+	case "DELETE":
+		return fmt.Sprintf("Deletes an existing %s.", parser.Singularize(resource))
+	default:
+		return fmt.Sprintf("Observed %s request to %s.", strings.ToUpper(method), path)
+	}
+}
+
+// describePath returns the last literal resource segment of a path (as lowercase words)
+// and the name of the path parameter that follows it, if any
+func describePath(path string) (string, string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	resource := ""
+	param := ""
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		if isPathParam(segment) {
+			param = strings.Trim(segment, "{}")
+			continue
+		}
+		resource = strings.ToLower(strings.Join(parser.SplitWords(segment), " "))
+		param = ""
+	}
+
+	if resource == "" {
+		resource = "root"
+	}
+	return resource, param
+}
+
+// isPathParam checks if a path segment is a template parameter like {id}
+func isPathParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
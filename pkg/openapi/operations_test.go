@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		expected string
+	}{
+		{name: "list", method: "GET", path: "/users", expected: "List users"},
+		{name: "get by id", method: "GET", path: "/users/{id}", expected: "Get user by id"},
+		{name: "create", method: "POST", path: "/categories", expected: "Create category"},
+		{name: "update", method: "PUT", path: "/addresses/{addressId}", expected: "Update address by addressId"},
+		{name: "delete nested", method: "DELETE", path: "/users/{userId}/posts/{postId}", expected: "Delete post by postId"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, generateSummary(tt.method, tt.path))
+		})
+	}
+}
+
+func TestAssignOperationMetadataUniqueness(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	generator.AddTransaction(createTestTransaction("GET", "/user-list", nil, nil, 200))
+	generator.AddTransaction(createTestTransaction("GET", "/user_list", nil, nil, 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	first := spec.Paths.Find("/user-list").Get
+	second := spec.Paths.Find("/user_list").Get
+	require.NotNil(t, first)
+	require.NotNil(t, second)
+
+	assert.Equal(t, "getUserList", first.OperationID)
+	assert.Equal(t, "getUserList2", second.OperationID)
+	assert.Equal(t, "List user list", first.Summary)
+	assert.NotEmpty(t, first.Description)
+
+	// The rename is reported
+	assert.Equal(t, []string{"Renamed operationId getUserList of GET /user_list to getUserList2, as GET /user-list already uses it"},
+		generator.Stats().Repairs)
+}
@@ -0,0 +1,128 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats of a generated document
+const (
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatHTML     = "html"     // standalone Redoc page with the document embedded
+	FormatMarkdown = "markdown" // Markdown pages, one per tag
+)
+
+// FormatForPath returns the output format implied by a file extension: YAML for .yaml and
+// .yml, HTML for .html and .htm, Markdown for .md, and JSON otherwise
+func FormatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".html", ".htm":
+		return FormatHTML
+	case ".md", ".markdown":
+		return FormatMarkdown
+	default:
+		return FormatJSON
+	}
+}
+
+// ContentType returns the media type of an output format, e.g. for uploads
+func ContentType(format string) string {
+	switch format {
+	case FormatYAML:
+		return "application/yaml"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// MarshalSpec serializes a document in the format implied by the output path's extension
+func MarshalSpec(spec *OpenAPISpec, path string) ([]byte, error) {
+	return MarshalSpecFormat(spec, FormatForPath(path))
+}
+
+// MarshalSpecFormat serializes a document as indented JSON, YAML, a standalone HTML page, or
+// a single Markdown page
+func MarshalSpecFormat(spec *OpenAPISpec, format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(spec, "", "  ")
+	case FormatYAML:
+		return yaml.Marshal(spec)
+	case FormatHTML:
+		return RenderHTML(spec, nil)
+	case FormatMarkdown:
+		return RenderMarkdownPage(spec), nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected json, yaml, html, or markdown", format)
+	}
+}
+
+// writeFiles writes files given by slash-separated paths relative to a directory, creating
+// the directories they are in
+func writeFiles(dir string, files map[string][]byte) error {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// WriteFileAtomic writes a file through a temporary file in the same directory that is renamed
+// over the target, so that a failed write never leaves a truncated document behind
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// BackupFile copies an existing file next to itself with a timestamp, e.g.
+// swagger.json.20060102-150405.bak, and returns the backup path. Nothing is copied, and an
+// empty path returned, when the file doesn't exist.
+func BackupFile(path string, now time.Time) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	backup := fmt.Sprintf("%s.%s.bak", path, now.Format("20060102-150405"))
+	if err := WriteFileAtomic(backup, data); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
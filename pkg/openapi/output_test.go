@@ -0,0 +1,60 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "swagger.json")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+
+	require.NoError(t, WriteFileAtomic(path, []byte("new")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// A missing directory fails without creating anything
+	assert.Error(t, WriteFileAtomic(filepath.Join(dir, "missing", "swagger.json"), []byte("new")))
+}
+
+func TestBackupFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "swagger.json")
+	now := time.Date(2024, 3, 5, 14, 30, 15, 0, time.UTC)
+
+	backup, err := BackupFile(path, now)
+	require.NoError(t, err)
+	assert.Empty(t, backup, "nothing to back up")
+
+	require.NoError(t, os.WriteFile(path, []byte("curated"), 0644))
+	backup, err = BackupFile(path, now)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "swagger.json.20240305-143015.bak"), backup)
+
+	data, err := os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, "curated", string(data))
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, "application/json", ContentType(FormatJSON))
+	assert.Equal(t, "application/yaml", ContentType(FormatYAML))
+	assert.Equal(t, "text/html; charset=utf-8", ContentType(FormatHTML))
+	assert.Equal(t, "text/markdown; charset=utf-8", ContentType(FormatMarkdown))
+}
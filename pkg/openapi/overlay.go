@@ -0,0 +1,319 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// overlayAction is an action of an OpenAPI Overlay (https://spec.openapis.org/overlay/v1.0.0),
+// which updates or removes the nodes selected by a JSONPath target
+type overlayAction struct {
+	Target string
+	Update interface{}
+	Remove bool
+}
+
+// ApplyOverlay applies a file of corrections to a generated document, so that they survive
+// every regeneration. The file is either an OpenAPI Overlay with update/remove actions, or a
+// JSON merge patch (RFC 7386) of the document, in JSON or YAML.
+func ApplyOverlay(spec *OpenAPISpec, path string) (*OpenAPISpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overlay %s: %v", path, err)
+	}
+
+	var patch interface{}
+	if err := yaml.Unmarshal(data, &patch); err != nil {
+		return nil, fmt.Errorf("failed to parse overlay %s: %v", path, err)
+	}
+	patch = normalizeYAML(patch)
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("overlay %s must be an object", path)
+	}
+
+	// Work on the generic JSON form of the document
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(specJSON, &doc); err != nil {
+		return nil, err
+	}
+
+	if _, isOverlay := patchObj["overlay"]; isOverlay {
+		actions, _ := patchObj["actions"].([]interface{})
+		for i, raw := range actions {
+			fields, _ := raw.(map[string]interface{})
+			action := overlayAction{Update: fields["update"]}
+			action.Target, _ = fields["target"].(string)
+			action.Remove, _ = fields["remove"].(bool)
+
+			if err := applyOverlayAction(doc, action); err != nil {
+				return nil, fmt.Errorf("overlay %s action %d: %v", path, i+1, err)
+			}
+		}
+	} else {
+		doc = applyMergePatch(doc, patch)
+	}
+
+	patchedJSON, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := openapi3.NewLoader().LoadFromData(patchedJSON)
+	if err != nil {
+		return nil, fmt.Errorf("document is invalid after applying overlay %s: %v", path, err)
+	}
+	return patched, nil
+}
+
+// normalizeYAML converts the maps decoded from YAML with non-string keys, such as unquoted
+// status codes, into the map[string]interface{} form used by JSON
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			result[fmt.Sprint(key)] = normalizeYAML(child)
+		}
+		return result
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = normalizeYAML(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = normalizeYAML(child)
+		}
+	}
+	return value
+}
+
+// applyMergePatch applies a JSON merge patch: objects are merged recursively, null removes a
+// member, and any other value replaces the target
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = applyMergePatch(targetObj[key], value)
+	}
+	return targetObj
+}
+
+// overlayRemoved marks array elements removed by an action until the array is compacted
+type overlayRemoved struct{}
+
+// overlayNode is a node selected by a JSONPath target, addressed through its parent
+type overlayNode struct {
+	parent interface{} // map[string]interface{} or []interface{}; nil for the document root
+	key    string
+	index  int
+}
+
+// applyOverlayAction removes or updates every node selected by the action's target
+func applyOverlayAction(doc interface{}, action overlayAction) error {
+	segments, err := parseJSONPath(action.Target)
+	if err != nil {
+		return err
+	}
+
+	nodes := selectNodes(doc, segments)
+	for _, node := range nodes {
+		switch {
+		case action.Remove:
+			if node.parent == nil {
+				return fmt.Errorf("cannot remove the document root")
+			}
+			node.set(overlayRemoved{})
+		case action.Update != nil:
+			node.set(mergeOverlayUpdate(node.get(doc), action.Update))
+		}
+	}
+
+	if action.Remove {
+		compactRemoved(doc)
+	}
+	return nil
+}
+
+// mergeOverlayUpdate merges an update into a node: objects are merged recursively, arrays are
+// appended to, and other values are replaced
+func mergeOverlayUpdate(target, update interface{}) interface{} {
+	switch target := target.(type) {
+	case map[string]interface{}:
+		updateObj, ok := update.(map[string]interface{})
+		if !ok {
+			return update
+		}
+		for key, value := range updateObj {
+			target[key] = mergeOverlayUpdate(target[key], value)
+		}
+		return target
+	case []interface{}:
+		if items, ok := update.([]interface{}); ok {
+			return append(target, items...)
+		}
+		return append(target, update)
+	}
+	return update
+}
+
+// get returns the value of the node
+func (n overlayNode) get(root interface{}) interface{} {
+	switch parent := n.parent.(type) {
+	case map[string]interface{}:
+		return parent[n.key]
+	case []interface{}:
+		return parent[n.index]
+	}
+	return root
+}
+
+// set replaces the value of the node. The root is updated in place by merging.
+func (n overlayNode) set(value interface{}) {
+	switch parent := n.parent.(type) {
+	case map[string]interface{}:
+		parent[n.key] = value
+	case []interface{}:
+		parent[n.index] = value
+	}
+}
+
+// compactRemoved drops the members and array elements marked as removed
+func compactRemoved(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if _, removed := child.(overlayRemoved); removed {
+				delete(v, key)
+				continue
+			}
+			v[key] = compactRemoved(child)
+		}
+	case []interface{}:
+		kept := v[:0]
+		for _, child := range v {
+			if _, removed := child.(overlayRemoved); !removed {
+				kept = append(kept, compactRemoved(child))
+			}
+		}
+		return kept
+	}
+	return value
+}
+
+// jsonPathSegment is one step of a JSONPath: a member name, an array index, or a wildcard
+type jsonPathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseJSONPath parses the subset of JSONPath used by overlays: $, .name, ['name'], [n],
+// and the .* / [*] wildcards, e.g. $.paths['/users'].get or $.paths.*.*.tags
+func parseJSONPath(expr string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("JSONPath target %q must start with $", expr)
+	}
+
+	var segments []jsonPathSegment
+	rest := expr[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".*"):
+			segments = append(segments, jsonPathSegment{wildcard: true})
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("JSONPath target %q has an empty member name", expr)
+			}
+			segments = append(segments, jsonPathSegment{key: name})
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath target %q has an unclosed bracket", expr)
+			}
+			// Quoted names may contain ], e.g. ['/items/{id}']
+			if quote := rest[1]; quote == '\'' || quote == '"' {
+				closing := strings.Index(rest[2:], string(quote)+"]")
+				if closing < 0 {
+					return nil, fmt.Errorf("JSONPath target %q has an unclosed quote", expr)
+				}
+				segments = append(segments, jsonPathSegment{key: rest[2 : closing+2]})
+				rest = rest[closing+4:]
+				continue
+			}
+
+			selector := rest[1:end]
+			rest = rest[end+1:]
+			if selector == "*" {
+				segments = append(segments, jsonPathSegment{wildcard: true})
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return nil, fmt.Errorf("JSONPath target %q: unsupported selector [%s]", expr, selector)
+			}
+			segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+		default:
+			return nil, fmt.Errorf("JSONPath target %q: unexpected %q", expr, rest)
+		}
+	}
+	return segments, nil
+}
+
+// selectNodes returns the nodes of a document selected by a parsed JSONPath
+func selectNodes(doc interface{}, segments []jsonPathSegment) []overlayNode {
+	nodes := []overlayNode{{}}
+	for _, segment := range segments {
+		var next []overlayNode
+		for _, node := range nodes {
+			switch value := node.get(doc).(type) {
+			case map[string]interface{}:
+				if segment.wildcard {
+					for key := range value {
+						next = append(next, overlayNode{parent: value, key: key})
+					}
+				} else if _, ok := value[segment.key]; ok && !segment.isIndex {
+					next = append(next, overlayNode{parent: value, key: segment.key})
+				}
+			case []interface{}:
+				if segment.wildcard {
+					for i := range value {
+						next = append(next, overlayNode{parent: value, index: i})
+					}
+				} else if segment.isIndex && segment.index >= 0 && segment.index < len(value) {
+					next = append(next, overlayNode{parent: value, index: segment.index})
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
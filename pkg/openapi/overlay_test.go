@@ -0,0 +1,108 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// overlayTestTransactions document a public and an internal API for overlays to correct
+func overlayTestTransactions() []proxy.APITransaction {
+	return []proxy.APITransaction{
+		createTestTransaction("GET", "/users", nil, []byte(`[{"id":"__integer__"}]`), 200),
+		createTestTransaction("POST", "/users", []byte(`{"name":"__string__"}`), []byte(`{"id":"__integer__"}`), 201),
+		createTestTransaction("GET", "/internal/health", nil, []byte(`{"ok":"__boolean__"}`), 200),
+	}
+}
+
+func writeOverlay(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "overrides.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestApplyOverlayActions(t *testing.T) {
+	path := writeOverlay(t, `overlay: 1.0.0
+info:
+  title: Corrections
+  version: 1.0.0
+actions:
+  - target: $.info
+    update:
+      description: Curated description
+  - target: $.paths['/users'].get
+    update:
+      summary: List all users
+      tags: [people]
+  - target: $.paths['/users'].*.responses
+    update:
+      500:
+        description: Server error
+  - target: $.paths['/internal/health']
+    remove: true
+`)
+
+	spec, _ := generateTestSpec(t, OpenAPIConfig{}, overlayTestTransactions()...)
+	spec, err := ApplyOverlay(spec, path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Curated description", spec.Info.Description)
+
+	get := spec.Paths.Value("/users").Get
+	assert.Equal(t, "List all users", get.Summary)
+	assert.Contains(t, get.Tags, "people")
+	assert.NotNil(t, get.Responses.Value("500"))
+	assert.NotNil(t, spec.Paths.Value("/users").Post.Responses.Value("500"))
+
+	assert.Nil(t, spec.Paths.Value("/internal/health"))
+	assert.NoError(t, ValidateSpec(spec))
+}
+
+func TestApplyOverlayMergePatch(t *testing.T) {
+	path := writeOverlay(t, `info:
+  title: Patched API
+paths:
+  /internal/health: null
+`)
+
+	spec, _ := generateTestSpec(t, OpenAPIConfig{}, overlayTestTransactions()...)
+	spec, err := ApplyOverlay(spec, path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Patched API", spec.Info.Title)
+	assert.Nil(t, spec.Paths.Value("/internal/health"))
+	assert.NotNil(t, spec.Paths.Value("/users"))
+}
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected []jsonPathSegment
+		wantErr  bool
+	}{
+		{name: "root", expr: "$", expected: nil},
+		{name: "members", expr: "$.info.title", expected: []jsonPathSegment{{key: "info"}, {key: "title"}}},
+		{name: "quoted path", expr: "$.paths['/users/{id}'].get", expected: []jsonPathSegment{{key: "paths"}, {key: "/users/{id}"}, {key: "get"}}},
+		{name: "wildcards and index", expr: "$.paths.*[*].tags[0]", expected: []jsonPathSegment{{key: "paths"}, {wildcard: true}, {wildcard: true}, {key: "tags"}, {index: 0, isIndex: true}}},
+		{name: "filter expression", expr: "$.paths[?(@.get)]", wantErr: true},
+		{name: "missing root", expr: "paths", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments, err := parseJSONPath(tt.expr)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, segments)
+		})
+	}
+}
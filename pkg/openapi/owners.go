@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIOwner assigns the operations under a path prefix to an owner and a team, emitted as
+// the x-owner and x-team extensions for catalogs such as Backstage
+type OpenAPIOwner struct {
+	Prefix string `yaml:"prefix" json:"prefix"`
+	Owner  string `yaml:"owner,omitempty" json:"owner,omitempty"`
+	Team   string `yaml:"team,omitempty" json:"team,omitempty"`
+}
+
+// ownersFile is the layout of an ownership mapping file
+type ownersFile struct {
+	Owners []OpenAPIOwner `yaml:"owners"`
+}
+
+// LoadOwners reads an ownership mapping file (YAML or JSON) of the form
+//
+//	owners:
+//	  - prefix: /users
+//	    owner: alice@example.com
+//	    team: identity
+func LoadOwners(path string) ([]OpenAPIOwner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read owners file %s: %v", path, err)
+	}
+
+	var file ownersFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse owners file %s: %v", path, err)
+	}
+	for i, owner := range file.Owners {
+		if !strings.HasPrefix(owner.Prefix, "/") {
+			return nil, fmt.Errorf("owners file %s entry %d: prefix %q must start with /", path, i+1, owner.Prefix)
+		}
+		if owner.Owner == "" && owner.Team == "" {
+			return nil, fmt.Errorf("owners file %s entry %d: needs an owner or a team", path, i+1)
+		}
+	}
+	return file.Owners, nil
+}
+
+// addOwners sets x-owner and x-team on every operation from the longest matching path prefix
+func addOwners(doc *OpenAPISpec, owners []OpenAPIOwner) {
+	if len(owners) == 0 {
+		return
+	}
+
+	// Longest prefixes first, so that /users/admin wins over /users
+	sorted := make([]OpenAPIOwner, len(owners))
+	copy(sorted, owners)
+	for i := range sorted {
+		sorted[i].Prefix = trimTrailingSlash(sorted[i].Prefix)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].Prefix) > len(sorted[j].Prefix) })
+
+	for _, path := range doc.Paths.InMatchingOrder() {
+		owner, ok := ownerForPath(path, sorted)
+		if !ok {
+			continue
+		}
+		pathItem := doc.Paths.Value(path)
+		for _, method := range operationMethods {
+			op := operationFor(pathItem, method)
+			if op == nil {
+				continue
+			}
+			if owner.Owner != "" {
+				setOperationExtension(op, "x-owner", owner.Owner)
+			}
+			if owner.Team != "" {
+				setOperationExtension(op, "x-team", owner.Team)
+			}
+		}
+	}
+}
+
+// ownerForPath returns the first owner whose prefix matches the path on a segment boundary
+func ownerForPath(path string, owners []OpenAPIOwner) (OpenAPIOwner, bool) {
+	for _, owner := range owners {
+		if owner.Prefix == "/" || path == owner.Prefix || strings.HasPrefix(path, owner.Prefix+"/") {
+			return owner, true
+		}
+	}
+	return OpenAPIOwner{}, false
+}
@@ -0,0 +1,68 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecOwners(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:   "Test API",
+		Version: "1.0.0",
+		Owners: []OpenAPIOwner{
+			{Prefix: "/users", Owner: "alice@example.com", Team: "identity"},
+			{Prefix: "/users/admin/", Team: "platform"},
+		},
+	})
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, []byte(`[{"id":"__integer__"}]`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/users/admin", nil, []byte(`{"enabled":"__boolean__"}`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/usersettings", nil, []byte(`{"theme":"__string__"}`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	users := spec.Paths.Find("/users").Get
+	assert.Equal(t, "alice@example.com", users.Extensions["x-owner"])
+	assert.Equal(t, "identity", users.Extensions["x-team"])
+
+	// The longest prefix wins
+	admin := spec.Paths.Find("/users/admin").Get
+	assert.Equal(t, "platform", admin.Extensions["x-team"])
+	assert.NotContains(t, admin.Extensions, "x-owner")
+
+	// Prefixes match whole segments only
+	settings := spec.Paths.Find("/usersettings").Get
+	assert.NotContains(t, settings.Extensions, "x-team")
+}
+
+func TestLoadOwners(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "owners.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("owners:\n  - prefix: /users\n    owner: alice@example.com\n    team: identity\n"), 0644))
+	owners, err := LoadOwners(valid)
+	require.NoError(t, err)
+	assert.Equal(t, []OpenAPIOwner{{Prefix: "/users", Owner: "alice@example.com", Team: "identity"}}, owners)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "relative prefix", content: "owners:\n  - prefix: users\n    team: identity\n"},
+		{name: "no owner or team", content: "owners:\n  - prefix: /users\n"},
+		{name: "invalid yaml", content: "owners: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "invalid.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+			_, err := LoadOwners(path)
+			assert.Error(t, err)
+		})
+	}
+}
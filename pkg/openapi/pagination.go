@@ -0,0 +1,140 @@
+package openapi
+
+import (
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// applyPagination documents a detected pagination style on a list operation: it adds an
+// x-pagination extension, describes the pagination query parameters, documents the Link
+// header, and marks the response envelope schema of the given status code.
+func applyPagination(op *openapi3.Operation, info *parser.PaginationInfo, statusCode string) {
+	if op == nil || info == nil {
+		return
+	}
+
+	if op.Extensions == nil {
+		op.Extensions = make(map[string]interface{})
+	}
+	op.Extensions["x-pagination"] = paginationExtension(info)
+
+	// Describe the pagination query parameters consistently
+	descriptions := map[string]string{
+		info.PageParam:   "Page number to return",
+		info.SizeParam:   "Maximum number of items to return per page",
+		info.OffsetParam: "Number of items to skip before the first returned item",
+		info.CursorParam: "Opaque cursor from a previous response pointing at the next page",
+	}
+	for _, paramRef := range op.Parameters {
+		if paramRef == nil || paramRef.Value == nil || paramRef.Value.In != "query" {
+			continue
+		}
+		if desc, ok := descriptions[paramRef.Value.Name]; ok && paramRef.Value.Name != "" && paramRef.Value.Description == "" {
+			paramRef.Value.Description = desc
+		}
+	}
+
+	if op.Responses == nil {
+		return
+	}
+	response := op.Responses.Value(statusCode)
+	if response == nil || response.Value == nil {
+		return
+	}
+
+	if info.LinkHeader {
+		if response.Value.Headers == nil {
+			response.Value.Headers = openapi3.Headers{}
+		}
+		response.Value.Headers["Link"] = &openapi3.HeaderRef{
+			Value: &openapi3.Header{
+				Parameter: openapi3.Parameter{
+					Description: `Links to adjacent pages (RFC 8288), e.g. rel="next"`,
+					Schema:      openapi3.NewStringSchema().NewRef(),
+				},
+			},
+		}
+	}
+
+	if info.ItemsField == "" {
+		return
+	}
+	for _, mediaType := range response.Value.Content {
+		if mediaType == nil || mediaType.Schema == nil || mediaType.Schema.Value == nil {
+			continue
+		}
+		markPaginationEnvelope(mediaType.Schema.Value, info)
+	}
+}
+
+// markPaginationEnvelope flags a response schema as a pagination envelope and describes
+// its items, total, and next fields
+func markPaginationEnvelope(schema *openapi3.Schema, info *parser.PaginationInfo) {
+	if schema.Extensions == nil {
+		schema.Extensions = make(map[string]interface{})
+	}
+	schema.Extensions["x-pagination-envelope"] = true
+	if schema.Description == "" {
+		schema.Description = "Paginated list of results"
+	}
+
+	describeProperty(schema, info.ItemsField, "Items on the current page")
+
+	meta := schema
+	if info.EnvelopeObject != "" {
+		ref, ok := schema.Properties[info.EnvelopeObject]
+		if !ok || ref == nil || ref.Value == nil {
+			return
+		}
+		meta = ref.Value
+		describeProperty(schema, info.EnvelopeObject, "Pagination metadata")
+	}
+	describeProperty(meta, info.TotalField, "Total number of items (or pages) available")
+	describeProperty(meta, info.NextField, "Cursor or link for the next page; absent on the last page")
+}
+
+// describeProperty sets the description of an object property when it exists and has none
+func describeProperty(schema *openapi3.Schema, name, description string) {
+	if name == "" {
+		return
+	}
+	ref, ok := schema.Properties[name]
+	if !ok || ref == nil || ref.Value == nil || ref.Value.Description != "" {
+		return
+	}
+	ref.Value.Description = description
+}
+
+// paginationExtension converts detected pagination details into the x-pagination value
+func paginationExtension(info *parser.PaginationInfo) map[string]interface{} {
+	ext := map[string]interface{}{
+		"style": info.Style,
+	}
+
+	setIfPresent := func(key, value string) {
+		if value != "" {
+			ext[key] = value
+		}
+	}
+	setIfPresent("pageParam", info.PageParam)
+	setIfPresent("sizeParam", info.SizeParam)
+	setIfPresent("offsetParam", info.OffsetParam)
+	setIfPresent("cursorParam", info.CursorParam)
+	setIfPresent("itemsField", info.ItemsField)
+	setIfPresent("totalField", envelopeFieldPath(info, info.TotalField))
+	setIfPresent("nextField", envelopeFieldPath(info, info.NextField))
+	if info.LinkHeader {
+		ext["linkHeader"] = true
+	}
+
+	return ext
+}
+
+// envelopeFieldPath qualifies a metadata field with its nested envelope object, e.g. meta.total
+func envelopeFieldPath(info *parser.PaginationInfo, field string) string {
+	if field == "" || info.EnvelopeObject == "" {
+		return field
+	}
+	return info.EnvelopeObject + "." + field
+}
@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"encoding/json"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// workers returns the number of goroutines generation shards its work across
+func (g *OpenAPIGenerator) workers() int {
+	if g.config.Workers > 0 {
+		return g.config.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// parallelFor calls fn for every index below n on a pool of workers. Callers write the result of
+// an index to its own slot, so that the results don't depend on the order the workers finish in.
+func parallelFor(n, workers int, fn func(i int)) {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// decodedBodies holds the request and response bodies of a transaction decoded as JSON, or nil
+// for bodies that are empty or not JSON
+type decodedBodies struct {
+	request  interface{}
+	response interface{}
+}
+
+// decodeBodies decodes the JSON bodies of all transactions in parallel, once, for both passes
+func (g *OpenAPIGenerator) decodeBodies(transactions []proxy.APITransaction) []decodedBodies {
+	decoded := make([]decodedBodies, len(transactions))
+	parallelFor(len(transactions), g.workers(), func(i int) {
+		decoded[i] = decodedBodies{
+			request:  decodeJSONBody(transactions[i].Request.Body),
+			response: decodeJSONBody(transactions[i].Response.Body),
+		}
+	})
+	return decoded
+}
+
+// decodeJSONBody decodes a JSON body, or returns nil when it is empty or not JSON
+func decodeJSONBody(body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil
+	}
+	return value
+}
+
+// endpointSchemas are the merged request, response, and error response schemas of an operation
+type endpointSchemas struct {
+	path, method string
+	request      parser.Schema
+	response     parser.Schema
+	errors       parser.Schema
+	unrefined    CachedEndpoint // the merged schemas before refinement, for the analysis cache
+}
+
+// mergeEndpointSchemas merges the schemas collected for every operation, sharded by operation
+// across workers, or takes them from the analysis cache for the reused operations. The results
+// are sorted by path and method, so that they are applied in the same order on every run.
+func (g *OpenAPIGenerator) mergeEndpointSchemas(endpoints map[string]map[string]bool, schemaMerger *parser.SchemaMerger, reused map[string]CachedEndpoint, bodySamples *sampleReservoirs) []endpointSchemas {
+	var merged []endpointSchemas
+	for path, methods := range endpoints {
+		for method := range methods {
+			merged = append(merged, endpointSchemas{path: path, method: method})
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].path != merged[j].path {
+			return merged[i].path < merged[j].path
+		}
+		return merged[i].method < merged[j].method
+	})
+
+	parallelFor(len(merged), g.workers(), func(i int) {
+		path, method := merged[i].path, merged[i].method
+		unrefined, ok := reused[method+" "+path]
+		if !ok {
+			unrefined = CachedEndpoint{
+				Request:  schemaMerger.MergeSchemas(path, method),
+				Response: schemaMerger.MergeSchemas(path+":response", method),
+				Errors:   schemaMerger.MergeSchemas(path+":error", method),
+			}
+		}
+		merged[i].unrefined = unrefined
+		merged[i].request = g.refineSchema(unrefined.Request, bodySamples.values(path+":"+method))
+		merged[i].response = g.refineSchema(unrefined.Response, bodySamples.values(path+":response:"+method))
+		merged[i].errors = g.refineSchema(unrefined.Errors, bodySamples.values(path+":error:"+method))
+	})
+	return merged
+}
@@ -0,0 +1,48 @@
+package openapi
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelFor(t *testing.T) {
+	for _, workers := range []int{0, 1, 4, 64} {
+		visits := make([]int32, 50)
+		parallelFor(len(visits), workers, func(i int) {
+			atomic.AddInt32(&visits[i], 1)
+		})
+		for i, count := range visits {
+			assert.Equal(t, int32(1), count, "index %d with %d workers", i, workers)
+		}
+	}
+}
+
+func TestGenerateSpecWorkersDeterministic(t *testing.T) {
+	generate := func(workers int) []byte {
+		generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Shop API", Version: "1.0.0", Workers: workers})
+		for i := 0; i < 20; i++ {
+			generator.AddTransaction(createTestTransaction("GET", fmt.Sprintf("/products/%d", i), nil,
+				[]byte(fmt.Sprintf(`{"id":"__integer__","name":"__string__","tag%d":"__string__"}`, i%3)), 200))
+			generator.AddTransaction(createTestTransaction("POST", fmt.Sprintf("/carts/%d/items", i),
+				[]byte(`{"productId":"__integer__","quantity":"__integer__"}`),
+				[]byte(`{"error":"__string__"}`), 400))
+			generator.AddTransaction(createTestTransaction("GET", fmt.Sprintf("/resource%d", i%5), nil,
+				[]byte(`[{"id":"__integer__"}]`), 200))
+		}
+		spec, err := generator.GenerateSpec()
+		require.NoError(t, err)
+		data, err := MarshalSpecFormat(spec, "json")
+		require.NoError(t, err)
+		return data
+	}
+
+	// Sharding the work across workers yields the same spec as generating serially
+	serial := generate(1)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, string(serial), string(generate(8)))
+	}
+}
@@ -0,0 +1,200 @@
+package openapi
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// queryParamStats tracks the query parameters sent to one endpoint across all captured requests
+type queryParamStats struct {
+	requests     int
+	observations map[string][][]string // parameter name -> values seen in each request
+}
+
+// newQueryParamStats creates an empty query parameter tracker
+func newQueryParamStats() *queryParamStats {
+	return &queryParamStats{
+		observations: make(map[string][][]string),
+	}
+}
+
+// add records the query parameters of one request
+func (s *queryParamStats) add(params url.Values) {
+	s.requests++
+	for name, values := range params {
+		s.observations[name] = append(s.observations[name], values)
+	}
+}
+
+// parameters builds the query parameters for the endpoint in name order. A parameter is
+// required when it was present in every captured request.
+func (s *queryParamStats) parameters() []*openapi3.Parameter {
+	names := s.names()
+	params := make([]*openapi3.Parameter, 0, len(names))
+	for _, name := range names {
+		param := queryParameter(name, s.observations[name])
+		param.Required = len(s.observations[name]) == s.requests
+		params = append(params, param)
+	}
+	return params
+}
+
+// names returns the observed query parameter names in sorted order
+func (s *queryParamStats) names() []string {
+	names := make([]string, 0, len(s.observations))
+	for name := range s.observations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// queryParameter builds a query parameter whose schema is inferred from the observed
+// (sanitized) values of each request. Repeated parameters become exploded arrays and
+// comma-separated values become non-exploded arrays.
+func queryParameter(name string, observations [][]string) *openapi3.Parameter {
+	param := &openapi3.Parameter{
+		Name:     name,
+		In:       "query",
+		Required: false,
+	}
+
+	var items []string
+	isArray := false
+	explode := true
+
+	for _, values := range observations {
+		switch {
+		case len(values) > 1:
+			// ?tag=a&tag=b
+			isArray = true
+			items = append(items, values...)
+		case len(values) == 1 && strings.Contains(values[0], ","):
+			// ?ids=1,2,3
+			if !isArray {
+				explode = false
+			}
+			isArray = true
+			items = append(items, strings.Split(values[0], ",")...)
+		default:
+			items = append(items, values...)
+		}
+	}
+
+	itemSchema := inferQueryValueSchema(items)
+
+	if isArray {
+		schema := openapi3.NewArraySchema()
+		schema.Items = &openapi3.SchemaRef{Value: itemSchema}
+		param.Schema = &openapi3.SchemaRef{Value: schema}
+		param.Style = openapi3.SerializationForm
+		param.Explode = &explode
+		return param
+	}
+
+	param.Schema = &openapi3.SchemaRef{Value: itemSchema}
+	if len(items) > 0 {
+		param.Example = convertPlaceholderString(items[0])
+	}
+	return param
+}
+
+// inferQueryValueSchema runs sanitized query values through the type inferrer
+func inferQueryValueSchema(values []string) *openapi3.Schema {
+	inferrer := parser.NewTypeInferrer(defaultFieldSamples)
+	for _, value := range values {
+		inferrer.AddSample("query", queryValueSample(value))
+	}
+
+	inferred := inferrer.InferSchema("query")
+	if inferred == nil {
+		return openapi3.NewStringSchema()
+	}
+
+	switch inferred.Type {
+	case "integer":
+		return openapi3.NewInt64Schema()
+	case "number":
+		return openapi3.NewFloat64Schema()
+	case "boolean":
+		return openapi3.NewBoolSchema()
+	}
+
+	schema := openapi3.NewStringSchema()
+	switch inferred.Format {
+	case "date":
+		schema.Format = "date"
+	case "datetime", "date-time":
+		schema.Format = "date-time"
+	}
+	return schema
+}
+
+// queryValueSample converts a sanitized query placeholder into a typed sample value
+// that the type inferrer understands (numbers are float64, as decoded from JSON)
+func queryValueSample(value string) interface{} {
+	switch value {
+	case "__integer__":
+		return float64(1)
+	case "__number__":
+		return 1.5
+	case "__boolean__":
+		return true
+	default:
+		return convertPlaceholderString(value)
+	}
+}
+
+// isHeaderParam reports whether a request header is documented as a parameter. Headers listed in
+// RequiredHeaders always are, then ExcludeHeaders wins over IncludeHeaders, and other headers are
+// documented unless they are common or carry credentials.
+func (g *OpenAPIGenerator) isHeaderParam(name string) bool {
+	switch {
+	case headerListed(g.config.RequiredHeaders, name):
+		return true
+	case headerListed(g.config.ExcludeHeaders, name):
+		return false
+	case headerListed(g.config.IncludeHeaders, name):
+		return true
+	default:
+		return !isCommonHeader(name) && !isAuthHeader(name)
+	}
+}
+
+// addRequiredHeaders marks the RequiredHeaders parameters of an operation required, adding the
+// ones its request didn't send
+func (g *OpenAPIGenerator) addRequiredHeaders(op *openapi3.Operation) {
+	for _, name := range g.config.RequiredHeaders {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if param := op.Parameters.GetByInAndName("header", name); param != nil {
+			param.Required = true
+			continue
+		}
+		op.Parameters = append(op.Parameters, &openapi3.ParameterRef{
+			Value: &openapi3.Parameter{
+				Name:     name,
+				In:       "header",
+				Required: true,
+				Schema: &openapi3.SchemaRef{
+					Value: openapi3.NewStringSchema(),
+				},
+			},
+		})
+	}
+}
+
+// headerListed reports whether a list of header names contains a header, ignoring case
+func headerListed(names []string, name string) bool {
+	for _, listed := range names {
+		if strings.EqualFold(strings.TrimSpace(listed), name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryParameter(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []string
+		schemaType string
+		itemType   string
+		format     string
+		explode    *bool
+	}{
+		{name: "integer", values: []string{"__integer__"}, schemaType: "integer", format: "int64"},
+		{name: "boolean", values: []string{"__boolean__"}, schemaType: "boolean"},
+		{name: "date", values: []string{"__date__"}, schemaType: "string", format: "date"},
+		{name: "string", values: []string{"__string__"}, schemaType: "string"},
+		{name: "repeated", values: []string{"__string__", "__string__"}, schemaType: "array", itemType: "string", explode: openapi3.BoolPtr(true)},
+		{name: "comma separated", values: []string{"__integer__,__integer__"}, schemaType: "array", itemType: "integer", explode: openapi3.BoolPtr(false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param := queryParameter("q", [][]string{tt.values})
+			require.NotNil(t, param.Schema)
+
+			schema := param.Schema.Value
+			assert.True(t, schema.Type.Is(tt.schemaType))
+			assert.Equal(t, tt.format, schema.Format)
+
+			if tt.itemType != "" {
+				require.NotNil(t, schema.Items)
+				assert.True(t, schema.Items.Value.Type.Is(tt.itemType))
+				assert.Equal(t, openapi3.SerializationForm, param.Style)
+				assert.Equal(t, tt.explode, param.Explode)
+			}
+		})
+	}
+}
+
+func TestQueryParamStatsRequired(t *testing.T) {
+	stats := newQueryParamStats()
+	stats.add(url.Values{"page": {"__integer__"}, "q": {"__string__"}})
+	stats.add(url.Values{"page": {"__integer__"}})
+	stats.add(url.Values{"page": {"__integer__"}, "sort": {"__string__"}})
+
+	params := stats.parameters()
+	require.Len(t, params, 3)
+
+	assert.Equal(t, "page", params[0].Name)
+	assert.True(t, params[0].Required)
+	assert.Equal(t, "q", params[1].Name)
+	assert.False(t, params[1].Required)
+	assert.Equal(t, "sort", params[2].Name)
+	assert.False(t, params[2].Required)
+}
+
+func TestHeaderParameters(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		IncludeHeaders:  []string{"accept-language", "X-Request-Id"},
+		ExcludeHeaders:  []string{"x-request-id"},
+		RequiredHeaders: []string{"x-org-id"},
+	})
+	tx := createTestTransaction("GET", "/users", nil, []byte(`[]`), 200)
+	tx.Request.Headers.Set("Accept-Language", "en")
+	tx.Request.Headers.Set("X-Request-Id", "__string__")
+	tx.Request.Headers.Set("X-Org-Id", "__string__")
+	tx.Request.Headers.Set("X-Trace", "__string__")
+	generator.AddTransaction(tx)
+	generator.AddTransaction(createTestTransaction("GET", "/orders", nil, []byte(`[]`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	users := spec.Paths.Find("/users").Get
+	assert.NotNil(t, users.Parameters.GetByInAndName("header", "Accept-Language"), "included common header")
+	assert.NotNil(t, users.Parameters.GetByInAndName("header", "X-Trace"), "other header")
+	assert.Nil(t, users.Parameters.GetByInAndName("header", "X-Request-Id"), "excluded header")
+	assert.Nil(t, users.Parameters.GetByInAndName("header", "Content-Type"), "common header")
+
+	// Required headers are required on every operation, even those whose requests lacked them
+	for _, op := range []*openapi3.Operation{users, spec.Paths.Find("/orders").Get} {
+		param := op.Parameters.GetByInAndName("header", "X-Org-Id")
+		require.NotNil(t, param)
+		assert.True(t, param.Required)
+	}
+	assert.Len(t, users.Parameters, 3)
+}
@@ -0,0 +1,245 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// postmanSchemaURL identifies the Postman collection format version
+const postmanSchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// postmanCollection is a Postman v2.1 collection
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanVariable `json:"variable,omitempty"`
+}
+
+// postmanInfo describes a collection
+type postmanInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// postmanItem is a folder, when it has items, or a request
+type postmanItem struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Item        []postmanItem     `json:"item,omitempty"`
+	Request     *postmanRequest   `json:"request,omitempty"`
+	Response    []postmanResponse `json:"response,omitempty"`
+}
+
+// postmanRequest is a request of a collection
+type postmanRequest struct {
+	Method      string          `json:"method"`
+	Description string          `json:"description,omitempty"`
+	Header      []postmanHeader `json:"header"`
+	Body        *postmanBody    `json:"body,omitempty"`
+	URL         postmanURL      `json:"url"`
+}
+
+// postmanHeader is a request or response header
+type postmanHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// postmanBody is a raw request body
+type postmanBody struct {
+	Mode    string                 `json:"mode"`
+	Raw     string                 `json:"raw"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// postmanURL is a request URL split into the parts Postman edits separately
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Host     []string          `json:"host"`
+	Path     []string          `json:"path"`
+	Query    []postmanQuery    `json:"query,omitempty"`
+	Variable []postmanVariable `json:"variable,omitempty"`
+}
+
+// postmanQuery is a query parameter, disabled when it is optional
+type postmanQuery struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// postmanVariable is a collection or path variable
+type postmanVariable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+// postmanResponse is an example response saved with a request
+type postmanResponse struct {
+	Name            string          `json:"name"`
+	OriginalRequest postmanRequest  `json:"originalRequest"`
+	Status          string          `json:"status"`
+	Code            int             `json:"code"`
+	Language        string          `json:"_postman_previewlanguage,omitempty"`
+	Header          []postmanHeader `json:"header"`
+	Body            string          `json:"body"`
+}
+
+// RenderPostman converts a document to a Postman v2.1 collection with a folder per tag. Path
+// parameters become :name variables, the first server becomes the {{baseUrl}} variable, and
+// every documented response is saved as an example.
+func RenderPostman(spec *OpenAPISpec) ([]byte, error) {
+	collection := postmanCollection{
+		Info: postmanInfo{Schema: postmanSchemaURL},
+		Item: []postmanItem{},
+		Variable: []postmanVariable{
+			{Key: "baseUrl", Value: serverBaseURL(spec)},
+		},
+	}
+	if spec.Info != nil {
+		collection.Info.Name = spec.Info.Title
+		collection.Info.Description = spec.Info.Description
+	}
+
+	tags := operationsByTag(spec)
+	for _, tag := range sortedTags(tags) {
+		folder := postmanItem{Name: tag}
+		if t := spec.Tags.Get(tag); t != nil {
+			folder.Description = t.Description
+		}
+		for _, operation := range tags[tag] {
+			folder.Item = append(folder.Item, postmanOperation(spec, operation))
+		}
+		collection.Item = append(collection.Item, folder)
+	}
+
+	return json.MarshalIndent(collection, "", "  ")
+}
+
+// postmanOperation converts an operation to a request with its example responses
+func postmanOperation(doc *OpenAPISpec, operation taggedOperation) postmanItem {
+	example := newExportRequest(doc, operation)
+	request := postmanRequest{
+		Method:      example.method,
+		Description: example.description,
+		Header:      []postmanHeader{},
+		URL:         postmanRequestURL(example),
+	}
+
+	for _, header := range example.headers {
+		request.Header = append(request.Header, postmanHeader{Key: header[0], Value: credentialPlaceholder(header[1], postmanTemplate)})
+	}
+	if example.body != "" {
+		request.Body = &postmanBody{
+			Mode:    "raw",
+			Raw:     example.body,
+			Options: map[string]interface{}{"raw": map[string]string{"language": "json"}},
+		}
+	}
+
+	return postmanItem{
+		Name:     example.name,
+		Request:  &request,
+		Response: postmanResponses(doc, operation.op, request),
+	}
+}
+
+// postmanRequestURL builds the URL of a request on top of the {{baseUrl}} variable
+func postmanRequestURL(example exportRequest) postmanURL {
+	url := postmanURL{Host: []string{"{{baseUrl}}"}}
+
+	// Postman marks path variables with a colon instead of braces
+	path := example.templatedPath(func(name string) string { return ":" + name })
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment != "" {
+			url.Path = append(url.Path, segment)
+		}
+	}
+	for _, param := range example.pathParams {
+		url.Variable = append(url.Variable, postmanVariable{Key: param.name, Value: param.value, Description: param.description})
+	}
+
+	var enabled []string
+	for _, param := range example.query {
+		url.Query = append(url.Query, postmanQuery{
+			Key:         param.name,
+			Value:       param.value,
+			Description: param.description,
+			Disabled:    !param.required,
+		})
+		if param.required {
+			enabled = append(enabled, param.name+"="+param.value)
+		}
+	}
+
+	url.Raw = "{{baseUrl}}/" + strings.Join(url.Path, "/")
+	if len(enabled) > 0 {
+		url.Raw += "?" + strings.Join(enabled, "&")
+	}
+	return url
+}
+
+// postmanResponses saves the documented responses of an operation as examples, using the
+// first JSON media type of each
+func postmanResponses(doc *OpenAPISpec, op *openapi3.Operation, request postmanRequest) []postmanResponse {
+	if op.Responses == nil {
+		return nil
+	}
+
+	var statuses []string
+	for status := range op.Responses.Map() {
+		if _, err := strconv.Atoi(status); err == nil {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Strings(statuses)
+
+	var responses []postmanResponse
+	for _, status := range statuses {
+		response := op.Responses.Value(status).Value
+		if response == nil {
+			continue
+		}
+		code, _ := strconv.Atoi(status)
+		example := postmanResponse{
+			Name:            status + " " + http.StatusText(code),
+			OriginalRequest: request,
+			Status:          http.StatusText(code),
+			Code:            code,
+			Header:          []postmanHeader{},
+		}
+		if response.Description != nil && *response.Description != "" {
+			example.Name = status + " " + *response.Description
+		}
+
+		for _, mediaType := range sortedMediaTypes(response.Content) {
+			if !isJSONContentType(mediaType) {
+				continue
+			}
+			media := response.Content[mediaType]
+			body := media.Example
+			if body == nil {
+				body = schemaExample(doc, media.Schema, 0)
+			}
+			example.Header = append(example.Header, postmanHeader{Key: "Content-Type", Value: mediaType})
+			example.Language = "json"
+			example.Body = indentedJSON(body, "")
+			break
+		}
+		responses = append(responses, example)
+	}
+	return responses
+}
+
+// postmanTemplate references a Postman variable
+func postmanTemplate(name string) string {
+	return "{{" + name + "}}"
+}
@@ -0,0 +1,61 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPostman(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:           "Shop API",
+		Version:         "1.0.0",
+		Servers:         []OpenAPIServer{{URL: "https://api.example.com/"}},
+		TagDescriptions: map[string]string{"Users": "Customer accounts"},
+		UsePathGroups:   true,
+	})
+	generator.AddTransaction(createTestTransaction("POST", "/users", []byte(`{"name":"__string__"}`), []byte(`{"id":"__integer__","name":"__string__"}`), 201))
+	generator.AddTransaction(createTestTransaction("GET", "/users/1", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/users/2", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	data, err := RenderPostman(spec)
+	require.NoError(t, err)
+
+	var collection postmanCollection
+	require.NoError(t, json.Unmarshal(data, &collection))
+
+	assert.Equal(t, "Shop API", collection.Info.Name)
+	assert.Equal(t, postmanSchemaURL, collection.Info.Schema)
+	assert.Equal(t, []postmanVariable{{Key: "baseUrl", Value: "https://api.example.com"}}, collection.Variable)
+
+	require.Len(t, collection.Item, 1)
+	folder := collection.Item[0]
+	assert.Equal(t, "Users", folder.Name)
+	assert.Equal(t, "Customer accounts", folder.Description)
+	require.Len(t, folder.Item, 2)
+
+	// Requests keep the path order of the document
+	create := folder.Item[0]
+	require.NotNil(t, create.Request)
+	assert.Equal(t, "POST", create.Request.Method)
+	assert.Equal(t, "{{baseUrl}}/users", create.Request.URL.Raw)
+	require.NotNil(t, create.Request.Body)
+	assert.JSONEq(t, `{"name":"string"}`, create.Request.Body.Raw)
+	require.Len(t, create.Response, 1)
+	assert.Equal(t, 201, create.Response[0].Code)
+	assert.Equal(t, "Created", create.Response[0].Status)
+	assert.Equal(t, "json", create.Response[0].Language)
+
+	// Path parameters become templated variables
+	get := folder.Item[1]
+	assert.Equal(t, "{{baseUrl}}/users/:userId", get.Request.URL.Raw)
+	assert.Equal(t, []string{"users", ":userId"}, get.Request.URL.Path)
+	require.Len(t, get.Request.URL.Variable, 1)
+	assert.Equal(t, "userId", get.Request.URL.Variable[0].Key)
+	assert.NotEmpty(t, get.Request.URL.Variable[0].Value)
+}
@@ -0,0 +1,111 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// normalizedTransactions returns the transactions with their paths normalized: the configured
+// prefixes are stripped and trailing slashes are removed, so that /api/v2/users/ and
+// /api/v2/users become one /users entry. Their bodies are decoded as well. It also returns the prefixes that were stripped,
+// which belong in the server URLs instead.
+func (g *OpenAPIGenerator) normalizedTransactions() ([]proxy.APITransaction, []string) {
+	prefixes := normalizePrefixes(g.config.StripPrefixes)
+
+	stripped := make(map[string]bool)
+	captured := g.capturedTransactions()
+	transactions := make([]proxy.APITransaction, len(captured))
+	for i, tx := range captured {
+		path, prefix := stripPathPrefix(tx.Request.Path, prefixes)
+		if prefix != "" {
+			stripped[prefix] = true
+		}
+		tx.Request.Path = trimTrailingSlash(path)
+		decodeBodies(&tx)
+		transactions[i] = tx
+	}
+
+	var used []string
+	for _, prefix := range prefixes {
+		if stripped[prefix] {
+			used = append(used, prefix)
+		}
+	}
+	sort.Strings(used)
+	return transactions, used
+}
+
+// decodeBodies decodes the request and response bodies of a transaction according to their
+// encodings, so that the generator works on plain bytes. Bodies that cannot be decoded are dropped.
+func decodeBodies(tx *proxy.APITransaction) {
+	if body, err := tx.Request.DecodedBody(); err == nil {
+		tx.Request.Body = body
+	} else {
+		tx.Request.Body = nil
+	}
+	if body, err := tx.Response.DecodedBody(); err == nil {
+		tx.Response.Body = body
+	} else {
+		tx.Response.Body = nil
+	}
+	tx.Request.BodyEncoding = proxy.BodyEncodingRaw
+	tx.Response.BodyEncoding = proxy.BodyEncodingRaw
+}
+
+// normalizePrefixes gives every prefix a leading and no trailing slash, and orders them from
+// the longest to the shortest so that /api/v2 is stripped before /api
+func normalizePrefixes(prefixes []string) []string {
+	var result []string
+	for _, prefix := range prefixes {
+		prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+		if prefix != "" {
+			result = append(result, "/"+prefix)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i]) > len(result[j])
+	})
+	return result
+}
+
+// stripPathPrefix removes the first prefix that matches whole segments of a path, e.g. /api
+// matches /api/users but not /apis. It returns the remaining path and the prefix removed.
+func stripPathPrefix(path string, prefixes []string) (string, string) {
+	for _, prefix := range prefixes {
+		if path == prefix {
+			return "/", prefix
+		}
+		if strings.HasPrefix(path, prefix+"/") {
+			return path[len(prefix):], prefix
+		}
+	}
+	return path, ""
+}
+
+// trimTrailingSlash removes trailing slashes from a path, keeping the root path
+func trimTrailingSlash(path string) string {
+	if trimmed := strings.TrimRight(path, "/"); trimmed != "" {
+		return trimmed
+	}
+	return "/"
+}
+
+// prefixedServers moves the stripped prefixes into the server URLs. With several prefixes,
+// every server is listed once per prefix.
+func prefixedServers(servers []OpenAPIServer, prefixes []string) []OpenAPIServer {
+	if len(prefixes) == 0 {
+		return servers
+	}
+
+	var result []OpenAPIServer
+	for _, server := range servers {
+		for _, prefix := range prefixes {
+			prefixed := server
+			prefixed.URL = strings.TrimRight(server.URL, "/") + prefix
+			result = append(result, prefixed)
+		}
+	}
+	return result
+}
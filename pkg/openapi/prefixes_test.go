@@ -0,0 +1,57 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripPathPrefix(t *testing.T) {
+	prefixes := normalizePrefixes([]string{"api", "/api/v2/"})
+
+	tests := []struct {
+		path     string
+		expected string
+		prefix   string
+	}{
+		{path: "/api/v2/users", expected: "/users", prefix: "/api/v2"},
+		{path: "/api/users", expected: "/users", prefix: "/api"},
+		{path: "/api/v2", expected: "/", prefix: "/api/v2"},
+		{path: "/apis/users", expected: "/apis/users", prefix: ""},
+		{path: "/users", expected: "/users", prefix: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, prefix := stripPathPrefix(tt.path, prefixes)
+			assert.Equal(t, tt.expected, path)
+			assert.Equal(t, tt.prefix, prefix)
+		})
+	}
+}
+
+func TestGenerateSpecStripPrefix(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:         "Test API",
+		Version:       "1.0.0",
+		Servers:       []OpenAPIServer{{URL: "https://example.com/", Description: "Production"}},
+		StripPrefixes: []string{"/api/v2"},
+	})
+	generator.AddTransaction(createTestTransaction("GET", "/api/v2/users", nil, nil, 200))
+	generator.AddTransaction(createTestTransaction("POST", "/api/v2/users/", nil, nil, 201))
+	generator.AddTransaction(createTestTransaction("GET", "/health/", nil, nil, 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"/users", "/health"}, spec.Paths.InMatchingOrder())
+	users := spec.Paths.Value("/users")
+	require.NotNil(t, users)
+	assert.NotNil(t, users.Get)
+	assert.NotNil(t, users.Post)
+
+	require.Len(t, spec.Servers, 1)
+	assert.Equal(t, "https://example.com/api/v2", spec.Servers[0].URL)
+	assert.Equal(t, "Production", spec.Servers[0].Description)
+}
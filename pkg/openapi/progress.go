@@ -0,0 +1,22 @@
+package openapi
+
+// Phases of generation reported to the progress callback
+const (
+	PhasePatternAnalysis = "pattern analysis" // paths, auth, and samples are collected and path templates detected
+	PhaseSchemaMerge     = "schema merge"     // operations are built and the schemas of their samples merged
+)
+
+// reportProgress calls the progress callback with the transactions of a phase done so far, at
+// the start and end of the phase and at most once per percent in between
+func (g *OpenAPIGenerator) reportProgress(phase string, done, total int) {
+	if g.config.Progress == nil {
+		return
+	}
+	step := (total + 99) / 100
+	if step < 1 {
+		step = 1
+	}
+	if done == 0 || done == total || done%step == 0 {
+		g.config.Progress(phase, done, total)
+	}
+}
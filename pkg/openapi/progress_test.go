@@ -0,0 +1,45 @@
+package openapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecProgress(t *testing.T) {
+	type report struct {
+		phase       string
+		done, total int
+	}
+	var reports []report
+
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:   "Test API",
+		Version: "1.0.0",
+		Progress: func(phase string, done, total int) {
+			reports = append(reports, report{phase, done, total})
+		},
+	})
+	for i := 0; i < 250; i++ {
+		generator.AddTransaction(createTestTransaction("GET", fmt.Sprintf("/users/%d", i), nil, []byte(`{"id":"__integer__"}`), 200))
+	}
+
+	_, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	require.NotEmpty(t, reports)
+	assert.Equal(t, report{PhasePatternAnalysis, 0, 250}, reports[0])
+	assert.Equal(t, report{PhaseSchemaMerge, 250, 250}, reports[len(reports)-1])
+
+	// Progress is reported at most once per percent, in order
+	assert.LessOrEqual(t, len(reports), 2*(100+2))
+	for i := 1; i < len(reports); i++ {
+		if reports[i].phase == reports[i-1].phase {
+			assert.GreaterOrEqual(t, reports[i].done, reports[i-1].done)
+		}
+	}
+	assert.Contains(t, reports, report{PhasePatternAnalysis, 250, 250})
+	assert.Contains(t, reports, report{PhaseSchemaMerge, 0, 250})
+}
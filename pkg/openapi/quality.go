@@ -0,0 +1,256 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// GenerationStats is what the generator observed in the captured traffic that the document
+// doesn't record, collected by GenerateSpec for the quality report
+type GenerationStats struct {
+	Samples         map[string]int // "METHOD /templated/path" -> transactions documenting the operation
+	MixedTypeFields []string       // e.g. "POST /users request: address.zip", fields seen with several JSON types
+	Repairs         []string       // path collisions merged and operationIds renamed to keep the document valid
+	CachedEndpoints int            // endpoints whose merged schemas were reused from the analysis cache
+	RareEndpoints   []string       // e.g. "GET /wp-login.php, captured once", endpoints left out for being captured fewer than MinCalls times
+	FlakyEndpoints  []string       // e.g. "GET /users/{userId} failed 3 of 20 requests (15.0%)", most failing first
+	StaleEndpoints  []string       // e.g. "GET /reports, last seen 2026-01-02 10:00", operations not called in the StaleSessions most recent sessions
+}
+
+// QualityReport summarizes how complete a generated document is and where more traffic would
+// improve it
+type QualityReport struct {
+	Operations             int      `json:"operations"`
+	Schemas                int      `json:"schemas"`
+	OperationsWithExamples float64  `json:"operationsWithExamplesPercent"`
+	OperationsWithSecurity float64  `json:"operationsWithSecurityPercent"`
+	MixedTypeFields        []string `json:"mixedTypeFields"`
+	SingleSampleEndpoints  []string `json:"singleSampleEndpoints"`
+	Repairs                []string `json:"repairs"`
+	StaleEndpoints         []string `json:"staleEndpoints"`
+}
+
+// NewQualityReport builds the quality report of a document from the stats of its generation.
+// Operations the stats don't know about, e.g. those of a document merged into, are counted but
+// never reported as documented from a single sample.
+func NewQualityReport(spec *OpenAPISpec, stats GenerationStats) QualityReport {
+	report := QualityReport{
+		MixedTypeFields:       append([]string{}, stats.MixedTypeFields...),
+		SingleSampleEndpoints: []string{},
+		Repairs:               append([]string{}, stats.Repairs...),
+		StaleEndpoints:        append([]string{}, stats.StaleEndpoints...),
+	}
+	if spec.Components != nil {
+		report.Schemas = len(spec.Components.Schemas)
+	}
+
+	withExamples, withSecurity := 0, 0
+	if spec.Paths != nil {
+		paths := spec.Paths.InMatchingOrder()
+		sort.Strings(paths)
+		for _, path := range paths {
+			pathItem := spec.Paths.Value(path)
+			for _, method := range operationMethods {
+				op := operationFor(pathItem, method)
+				if op == nil {
+					continue
+				}
+				report.Operations++
+				if operationHasExamples(spec, op) {
+					withExamples++
+				}
+				if operationHasSecurity(spec, op) {
+					withSecurity++
+				}
+				if stats.Samples[method+" "+path] == 1 {
+					report.SingleSampleEndpoints = append(report.SingleSampleEndpoints, method+" "+path)
+				}
+			}
+		}
+	}
+
+	if report.Operations > 0 {
+		report.OperationsWithExamples = percentage(withExamples, report.Operations)
+		report.OperationsWithSecurity = percentage(withSecurity, report.Operations)
+	}
+	return report
+}
+
+// WriteQualityReport writes a quality report as indented JSON
+func WriteQualityReport(report QualityReport, path string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// percentage is part of total in percent, rounded to one decimal
+func percentage(part, total int) float64 {
+	return float64(part*1000/total) / 10
+}
+
+// operationHasExamples reports whether any parameter, request body, or response of an
+// operation shows an example value
+func operationHasExamples(spec *OpenAPISpec, op *openapi3.Operation) bool {
+	for _, param := range op.Parameters {
+		if param.Value != nil && (param.Value.Example != nil || len(param.Value.Examples) > 0) {
+			return true
+		}
+	}
+
+	var contents []openapi3.Content
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		contents = append(contents, op.RequestBody.Value.Content)
+	}
+	if op.Responses != nil {
+		for _, response := range op.Responses.Map() {
+			if response.Value != nil {
+				contents = append(contents, response.Value.Content)
+			}
+		}
+	}
+	for _, content := range contents {
+		for _, media := range content {
+			if media.Example != nil || len(media.Examples) > 0 {
+				return true
+			}
+			if media.Schema != nil && schemaHasExample(spec, media.Schema) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// schemaHasExample reports whether a schema, its array items, or its properties have an
+// example. References to component schemas are followed one level deep.
+func schemaHasExample(spec *OpenAPISpec, ref *openapi3.SchemaRef) bool {
+	schema := ref.Value
+	if schema == nil && ref.Ref != "" && spec.Components != nil {
+		if component := spec.Components.Schemas[strings.TrimPrefix(ref.Ref, "#/components/schemas/")]; component != nil {
+			schema = component.Value
+		}
+	}
+	if schema == nil {
+		return false
+	}
+	if schema.Example != nil {
+		return true
+	}
+	if schema.Items != nil && schema.Items.Value != nil && schema.Items.Value.Example != nil {
+		return true
+	}
+	for _, property := range schema.Properties {
+		if property.Value != nil && property.Value.Example != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// operationHasSecurity reports whether an operation requires credentials, through its own
+// security requirements or those of the document
+func operationHasSecurity(spec *OpenAPISpec, op *openapi3.Operation) bool {
+	requirements := spec.Security
+	if op.Security != nil {
+		requirements = *op.Security
+	}
+	for _, requirement := range requirements {
+		if len(requirement) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// mixedTypeFields returns the fields of decoded JSON bodies that were seen with different JSON
+// types, e.g. a number in one sample and a string in another, which no single schema type
+// describes. Nulls don't count as a type. The body itself is reported as "(body)".
+func mixedTypeFields(samples []interface{}) []string {
+	kinds := make(map[string]map[string]bool)
+	for _, sample := range samples {
+		collectJSONKinds(sample, "", kinds)
+	}
+
+	var fields []string
+	for field, seen := range kinds {
+		if len(seen) > 1 {
+			if field == "" {
+				field = "(body)"
+			}
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// collectJSONKinds records the JSON type of a decoded value and its nested fields by path,
+// e.g. "address.zip" or "items[].id"
+func collectJSONKinds(value interface{}, path string, kinds map[string]map[string]bool) {
+	var kind string
+	switch v := value.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		kind = "object"
+		for name, field := range v {
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			collectJSONKinds(field, fieldPath, kinds)
+		}
+	case []interface{}:
+		kind = "array"
+		for _, item := range v {
+			collectJSONKinds(item, path+"[]", kinds)
+		}
+	case string:
+		kind = "string"
+	case float64, json.Number:
+		kind = "number"
+	case bool:
+		kind = "boolean"
+	default:
+		kind = fmt.Sprintf("%T", v)
+	}
+
+	if kinds[path] == nil {
+		kinds[path] = make(map[string]bool)
+	}
+	kinds[path][kind] = true
+}
+
+// collectMixedTypeFields records the mixed-type fields of the request, response, and error
+// bodies of every endpoint in the generation stats
+func (g *OpenAPIGenerator) collectMixedTypeFields(endpoints map[string]map[string]bool, bodySamples *sampleReservoirs) {
+	paths := make([]string, 0, len(endpoints))
+	for path := range endpoints {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		for _, method := range operationMethods {
+			if !endpoints[path][method] {
+				continue
+			}
+			for _, body := range []struct{ name, key string }{
+				{"request", path + ":" + method},
+				{"response", path + ":response:" + method},
+				{"error", path + ":error:" + method},
+			} {
+				for _, field := range mixedTypeFields(bodySamples.values(body.key)) {
+					g.stats.MixedTypeFields = append(g.stats.MixedTypeFields, fmt.Sprintf("%s %s %s: %s", method, path, body.name, field))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMixedTypeFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  []string
+		expected []string
+	}{
+		{
+			name:     "consistent types",
+			samples:  []string{`{"id":1,"name":"a"}`, `{"id":2,"name":"b"}`},
+			expected: nil,
+		},
+		{
+			name:     "nulls are not a type",
+			samples:  []string{`{"id":1,"note":null}`, `{"id":2,"note":"b"}`},
+			expected: nil,
+		},
+		{
+			name:     "number and string",
+			samples:  []string{`{"zip":12345}`, `{"zip":"12345-6789"}`},
+			expected: []string{"zip"},
+		},
+		{
+			name:     "nested fields and array items",
+			samples:  []string{`{"address":{"zip":1},"tags":[{"id":1}]}`, `{"address":{"zip":"1"},"tags":[{"id":true}]}`},
+			expected: []string{"address.zip", "tags[].id"},
+		},
+		{
+			name:     "body itself",
+			samples:  []string{`{"id":1}`, `[{"id":1}]`},
+			expected: []string{"(body)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var samples []interface{}
+			for _, sample := range tt.samples {
+				var decoded interface{}
+				require.NoError(t, json.Unmarshal([]byte(sample), &decoded))
+				samples = append(samples, decoded)
+			}
+			assert.Equal(t, tt.expected, mixedTypeFields(samples))
+		})
+	}
+}
+
+func TestGenerationStats(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(createTestTransaction("GET", "/users/1", nil, []byte(`{"id":1,"zip":12345}`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/users/2", nil, []byte(`{"id":2,"zip":"12345-6789"}`), 200))
+	generator.AddTransaction(createTestTransaction("POST", "/users", []byte(`{"name":"a"}`), []byte(`{"id":3}`), 201))
+
+	_, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	stats := generator.Stats()
+	assert.Equal(t, map[string]int{"GET /users/{userId}": 2, "POST /users": 1}, stats.Samples)
+	assert.Equal(t, []string{"GET /users/{userId} response: zip"}, stats.MixedTypeFields)
+}
+
+func TestNewQualityReport(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(createTestTransaction("GET", "/users/1", nil, []byte(`{"id":1,"name":"a"}`), 200))
+	generator.AddTransaction(createTestTransaction("GET", "/users/2", nil, []byte(`{"id":2,"name":"b"}`), 200))
+	generator.AddTransaction(createTestTransaction("POST", "/users", []byte(`{"name":"a"}`), []byte(`{"id":3,"name":"a"}`), 201))
+	generator.AddTransaction(createTestTransaction("DELETE", "/users/3", nil, nil, 204))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	// Secure one of the three operations
+	spec.Paths.Find("/users").Post.Security = &openapi3.SecurityRequirements{{"bearer": {}}}
+
+	report := NewQualityReport(spec, generator.Stats())
+	assert.Equal(t, 3, report.Operations)
+	assert.Equal(t, len(spec.Components.Schemas), report.Schemas)
+	assert.Equal(t, 66.6, report.OperationsWithExamples, "every operation but the DELETE has a body with examples")
+	assert.Equal(t, 33.3, report.OperationsWithSecurity)
+	assert.Empty(t, report.MixedTypeFields)
+	assert.Equal(t, []string{"POST /users", "DELETE /users/{userId}"}, report.SingleSampleEndpoints)
+
+	// Document-wide requirements apply to every operation without its own
+	spec.Security = openapi3.SecurityRequirements{{"bearer": {}}}
+	spec.Paths.Find("/users/{userId}").Get.Security = &openapi3.SecurityRequirements{}
+	assert.Equal(t, 66.6, NewQualityReport(spec, generator.Stats()).OperationsWithSecurity)
+}
+
+func TestWriteQualityReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	report := QualityReport{Operations: 2, OperationsWithExamples: 50, MixedTypeFields: []string{}, SingleSampleEndpoints: []string{"GET /health"}}
+	require.NoError(t, WriteQualityReport(report, path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, float64(2), decoded["operations"])
+	assert.Equal(t, float64(50), decoded["operationsWithExamplesPercent"])
+	assert.Equal(t, []interface{}{"GET /health"}, decoded["singleSampleEndpoints"])
+}
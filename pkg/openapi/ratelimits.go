@@ -0,0 +1,180 @@
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// idempotencyKeyHeader is the request header that makes retrying a request safe
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// rateLimitHeaders maps the canonical names of the well-known rate limit response headers to
+// their conventional spelling and description
+var rateLimitHeaders = map[string]struct {
+	name        string
+	description string
+}{
+	"X-Ratelimit-Limit":     {"X-RateLimit-Limit", "Maximum number of requests allowed in the current rate limit window"},
+	"X-Ratelimit-Remaining": {"X-RateLimit-Remaining", "Number of requests left in the current rate limit window"},
+	"X-Ratelimit-Reset":     {"X-RateLimit-Reset", "Time at which the current rate limit window resets"},
+	"X-Ratelimit-Used":      {"X-RateLimit-Used", "Number of requests made in the current rate limit window"},
+	"Ratelimit-Limit":       {"RateLimit-Limit", "Maximum number of requests allowed in the current rate limit window"},
+	"Ratelimit-Remaining":   {"RateLimit-Remaining", "Number of requests left in the current rate limit window"},
+	"Ratelimit-Reset":       {"RateLimit-Reset", "Seconds until the current rate limit window resets"},
+	"Ratelimit-Policy":      {"RateLimit-Policy", "Quota policies of the rate limit"},
+	"Ratelimit":             {"RateLimit", "Current state of the rate limit"},
+	"Retry-After":           {"Retry-After", "Seconds to wait, or the HTTP date to wait for, before retrying the request"},
+}
+
+// headerConventions tracks the idempotency and rate limit headers of an endpoint's traffic
+type headerConventions struct {
+	idempotencyKey bool
+	responses      map[string]http.Header // status code -> rate limit headers with a sample value
+}
+
+// newHeaderConventions creates an empty header convention tracker
+func newHeaderConventions() *headerConventions {
+	return &headerConventions{responses: make(map[string]http.Header)}
+}
+
+// add records the idempotency and rate limit headers of one transaction
+func (c *headerConventions) add(tx proxy.APITransaction) {
+	if tx.Request.Headers.Get(idempotencyKeyHeader) != "" {
+		c.idempotencyKey = true
+	}
+
+	status := strconv.Itoa(tx.Response.StatusCode)
+	for name, values := range tx.Response.Headers {
+		canonical := http.CanonicalHeaderKey(name)
+		if !isRateLimitHeader(canonical) || len(values) == 0 {
+			continue
+		}
+		if c.responses[status] == nil {
+			c.responses[status] = make(http.Header)
+		}
+		if c.responses[status].Get(canonical) == "" {
+			c.responses[status].Set(canonical, values[0])
+		}
+	}
+	if tx.Response.StatusCode == http.StatusTooManyRequests && c.responses[status] == nil {
+		c.responses[status] = make(http.Header)
+	}
+}
+
+// merge adds the observations of another request path of the same endpoint
+func (c *headerConventions) merge(other *headerConventions) {
+	c.idempotencyKey = c.idempotencyKey || other.idempotencyKey
+	for status, headers := range other.responses {
+		if c.responses[status] == nil {
+			c.responses[status] = make(http.Header)
+		}
+		for name, values := range headers {
+			if c.responses[status].Get(name) == "" {
+				c.responses[status][name] = values
+			}
+		}
+	}
+}
+
+// isRateLimitHeader reports whether a canonical response header name is a rate limit header
+func isRateLimitHeader(name string) bool {
+	_, known := rateLimitHeaders[name]
+	return known || strings.HasPrefix(name, "X-Ratelimit-") || strings.HasPrefix(name, "Ratelimit-")
+}
+
+// requestHeaderConventions returns the header conventions of transactions by method and request
+// path. It runs before the response strategy, which may leave out the 429 responses.
+func requestHeaderConventions(transactions []proxy.APITransaction) map[string]*headerConventions {
+	conventions := make(map[string]*headerConventions)
+	for _, tx := range transactions {
+		key := tx.Request.Method + " " + tx.Request.Path
+		if conventions[key] == nil {
+			conventions[key] = newHeaderConventions()
+		}
+		conventions[key].add(tx)
+	}
+	return conventions
+}
+
+// applyHeaderConventions documents the Idempotency-Key parameter and the rate limit response
+// headers of operations consistently, and adds a 429 response to the operations that were rate
+// limited even when the response strategy left it out
+func (g *OpenAPIGenerator) applyHeaderConventions(doc *OpenAPISpec, conventions map[string]*headerConventions) {
+	for key, observed := range conventions {
+		op := operationForKey(doc, key)
+		if op == nil {
+			continue
+		}
+
+		if observed.idempotencyKey && !headerListed(g.config.ExcludeHeaders, idempotencyKeyHeader) {
+			param := op.Parameters.GetByInAndName("header", idempotencyKeyHeader)
+			if param == nil {
+				param = &openapi3.Parameter{Name: idempotencyKeyHeader, In: "header", Schema: openapi3.NewStringSchema().NewRef()}
+				op.Parameters = append(op.Parameters, &openapi3.ParameterRef{Value: param})
+			}
+			param.Description = "Unique key of the request; retrying it with the same key returns the original response instead of repeating the operation"
+			param.Example = nil
+		}
+
+		for status, headers := range observed.responses {
+			if op.Responses == nil {
+				op.Responses = openapi3.NewResponses()
+			}
+			response := op.Responses.Value(status)
+			if response == nil && status == strconv.Itoa(http.StatusTooManyRequests) {
+				response = &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription(g.statusDescription(http.StatusTooManyRequests))}
+				op.Responses.Set(status, response)
+			}
+			if response == nil || response.Ref != "" || response.Value == nil {
+				continue
+			}
+			addRateLimitHeaders(response.Value, headers)
+		}
+	}
+}
+
+// addRateLimitHeaders documents rate limit headers on a response, typed by their sample values
+func addRateLimitHeaders(response *openapi3.Response, headers http.Header) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, canonical := range names {
+		name, description := canonical, "Rate limit information"
+		if known, ok := rateLimitHeaders[canonical]; ok {
+			name, description = known.name, known.description
+		} else if rest, ok := strings.CutPrefix(canonical, "X-Ratelimit-"); ok {
+			name = "X-RateLimit-" + rest
+		} else if rest, ok := strings.CutPrefix(canonical, "Ratelimit-"); ok {
+			name = "RateLimit-" + rest
+		}
+
+		schema := openapi3.NewStringSchema()
+		if _, err := strconv.ParseInt(headers.Get(canonical), 10, 64); err == nil {
+			schema = openapi3.NewIntegerSchema()
+		}
+
+		if response.Headers == nil {
+			response.Headers = openapi3.Headers{}
+		}
+		if _, ok := response.Headers[name]; ok {
+			continue
+		}
+		response.Headers[name] = &openapi3.HeaderRef{
+			Value: &openapi3.Header{
+				Parameter: openapi3.Parameter{
+					Description: description,
+					Schema:      schema.NewRef(),
+				},
+			},
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecHeaderConventions(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{ResponseStrategy: StrategyBestSuccess})
+
+	created := createTestTransaction("POST", "/payments", []byte(`{"amount":"__integer__"}`), []byte(`{"id":"__integer__"}`), 201)
+	created.Request.Headers.Set("Idempotency-Key", "3f2a9c")
+	created.Response.Headers.Set("X-RateLimit-Limit", "100")
+	created.Response.Headers.Set("X-RateLimit-Remaining", "99")
+	created.Response.Headers.Set("X-RateLimit-Reset", "1717171717")
+	generator.AddTransaction(created)
+
+	// The response strategy leaves out the 429 response, which is documented anyway
+	limited := createTestTransaction("POST", "/payments", []byte(`{"amount":"__integer__"}`), nil, 429)
+	limited.Response.Headers.Set("Retry-After", "30")
+	generator.AddTransaction(limited)
+
+	generator.AddTransaction(createTestTransaction("GET", "/payments", nil, []byte(`[]`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	op := spec.Paths.Find("/payments").Post
+	param := op.Parameters.GetByInAndName("header", "Idempotency-Key")
+	require.NotNil(t, param)
+	assert.Contains(t, param.Description, "retrying it with the same key")
+	assert.Nil(t, param.Example)
+
+	created201 := op.Responses.Value("201")
+	require.NotNil(t, created201)
+	assert.Len(t, created201.Value.Headers, 3)
+	require.Contains(t, created201.Value.Headers, "X-RateLimit-Remaining")
+	remaining := created201.Value.Headers["X-RateLimit-Remaining"].Value
+	assert.Equal(t, "Number of requests left in the current rate limit window", remaining.Description)
+	assert.True(t, remaining.Schema.Value.Type.Is("integer"))
+
+	tooMany := op.Responses.Value("429")
+	require.NotNil(t, tooMany)
+	assert.Equal(t, "Too Many Requests", *tooMany.Value.Description)
+	require.Contains(t, tooMany.Value.Headers, "Retry-After")
+
+	list := spec.Paths.Find("/payments").Get
+	assert.Nil(t, list.Parameters.GetByInAndName("header", "Idempotency-Key"))
+	assert.Nil(t, list.Responses.Value("429"))
+	assert.Empty(t, list.Responses.Value("200").Value.Headers)
+}
@@ -0,0 +1,73 @@
+package openapi
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// responseTimeExtension is the operation extension holding the latency percentiles of its traffic
+const responseTimeExtension = "x-response-time"
+
+// requestLatencies returns the latencies of transactions by method and request path, from the
+// request being received to the response being captured. It runs before the response strategy,
+// so that every captured request counts. Transactions without timestamps are left out.
+func requestLatencies(transactions []proxy.APITransaction) map[string][]time.Duration {
+	latencies := make(map[string][]time.Duration)
+	for _, tx := range transactions {
+		if tx.Request.Timestamp.IsZero() || tx.Response.Timestamp.IsZero() {
+			continue
+		}
+		latency := tx.Response.Timestamp.Sub(tx.Request.Timestamp)
+		if latency < 0 {
+			continue
+		}
+		key := tx.Request.Method + " " + tx.Request.Path
+		latencies[key] = append(latencies[key], latency)
+	}
+	return latencies
+}
+
+// addResponseTimes sets x-response-time on operations, with the p50, p95, and p99 latencies of
+// their captured requests in milliseconds and the number of requests they are computed from. The
+// request paths are matched against the documented paths, so that the requests the response
+// strategy left out count too.
+func addResponseTimes(doc *OpenAPISpec, latencies map[string][]time.Duration) {
+	templates, prefixes := documentedPaths(doc)
+	byOperation := make(map[string][]time.Duration)
+	for key, samples := range latencies {
+		method, path, _ := strings.Cut(key, " ")
+		if template, ok := matchPathTemplate(path, templates, prefixes); ok {
+			byOperation[method+" "+template] = append(byOperation[method+" "+template], samples...)
+		}
+	}
+
+	for key, samples := range byOperation {
+		op := operationForKey(doc, key)
+		if op == nil {
+			continue
+		}
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		setOperationExtension(op, responseTimeExtension, map[string]interface{}{
+			"p50":     percentileMillis(samples, 50),
+			"p95":     percentileMillis(samples, 95),
+			"p99":     percentileMillis(samples, 99),
+			"unit":    "ms",
+			"samples": len(samples),
+		})
+	}
+}
+
+// percentileMillis returns the nearest-rank percentile of sorted latencies in milliseconds,
+// rounded to a tenth
+func percentileMillis(sorted []time.Duration, percentile float64) float64 {
+	rank := int(math.Ceil(percentile/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	millis := float64(sorted[rank]) / float64(time.Millisecond)
+	return math.Round(millis*10) / 10
+}
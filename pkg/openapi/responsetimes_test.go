@@ -0,0 +1,73 @@
+package openapi
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// timedTransaction returns a transaction whose response was captured after latency
+func timedTransaction(method, path string, latency time.Duration) proxy.APITransaction {
+	tx := createTestTransaction(method, path, nil, []byte(`{"id":1,"name":"Ada"}`), 200)
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	tx.Request.Timestamp = start
+	tx.Response.Timestamp = start.Add(latency)
+	return tx
+}
+
+func TestResponseTimes(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:            "Test API",
+		Version:          "1.0.0",
+		UsePathGroups:    true,
+		ResponseTimes:    true,
+		ResponseStrategy: StrategyBestSuccess,
+	})
+	// 100 requests of 1ms to 100ms over many user IDs, of which the strategy documents one
+	for i := 1; i <= 100; i++ {
+		generator.AddTransaction(timedTransaction("GET", fmt.Sprintf("/users/%d", i), time.Duration(i)*time.Millisecond))
+	}
+	generator.AddTransaction(timedTransaction("POST", "/users", 1500*time.Microsecond))
+	untimed := createTestTransaction("GET", "/health", nil, []byte(`{"status":"ok"}`), 200)
+	generator.AddTransaction(untimed)
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	get := spec.Paths.Value("/users/{userId}").Get
+	require.NotNil(t, get)
+	assert.Equal(t, map[string]interface{}{
+		"p50":     50.0,
+		"p95":     95.0,
+		"p99":     99.0,
+		"unit":    "ms",
+		"samples": 100,
+	}, get.Extensions[responseTimeExtension])
+
+	post := spec.Paths.Value("/users").Post.Extensions[responseTimeExtension].(map[string]interface{})
+	assert.Equal(t, 1.5, post["p99"])
+	assert.Equal(t, 1, post["samples"])
+
+	// Operations without timestamps have no baseline
+	assert.NotContains(t, spec.Paths.Value("/health").Get.Extensions, responseTimeExtension)
+}
+
+func TestResponseTimesDisabled(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(timedTransaction("GET", "/users", 10*time.Millisecond))
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+	assert.NotContains(t, spec.Paths.Value("/users").Get.Extensions, responseTimeExtension)
+}
+
+func TestPercentileMillis(t *testing.T) {
+	samples := []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond, 40 * time.Millisecond}
+	assert.Equal(t, 2.0, percentileMillis(samples, 50))
+	assert.Equal(t, 40.0, percentileMillis(samples, 95))
+	assert.Equal(t, 1.0, percentileMillis(samples[:1], 0))
+	assert.Equal(t, 0.3, percentileMillis([]time.Duration{340 * time.Microsecond}, 99))
+}
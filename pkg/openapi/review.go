@@ -0,0 +1,192 @@
+package openapi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// reviewHelp explains the answers of the review prompt
+const reviewHelp = "Enter or y keeps an endpoint, n removes it, t renames its tag, s edits its summary, a keeps all remaining"
+
+// ReviewSpec walks through the operations of a document on a terminal, grouped by tag, and lets
+// the user remove them, rename their tag, or edit their summary before the document is written.
+// Prompts are written to out and answers read line by line from in; the end of the input keeps
+// the remaining operations
+func ReviewSpec(spec *OpenAPISpec, in io.Reader, out io.Writer) error {
+	var operations []taggedOperation
+	byTag := operationsByTag(spec)
+	for _, tag := range sortedTags(byTag) {
+		operations = append(operations, byTag[tag]...)
+	}
+	if len(operations) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(out, "Reviewing %d endpoints. %s.\n", len(operations), reviewHelp)
+	scanner := bufio.NewScanner(in)
+	prompt := func(question string) (string, bool) {
+		fmt.Fprint(out, question)
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return "", false
+		}
+		return strings.TrimSpace(scanner.Text()), true
+	}
+
+	removed := make(map[string]bool)
+operations:
+	for i, operation := range operations {
+		for {
+			fmt.Fprintf(out, "\n[%d/%d] %s %s  (%s)\n", i+1, len(operations), operation.method, operation.path, strings.Join(operation.op.Tags, ", "))
+			if operation.op.Summary != "" {
+				fmt.Fprintf(out, "  %s\n", operation.op.Summary)
+			}
+
+			answer, ok := prompt("  Keep? [Y/n/t/s/a]: ")
+			if !ok {
+				break operations
+			}
+			switch strings.ToLower(answer) {
+			case "", "y", "yes":
+				continue operations
+			case "n", "no":
+				removed[operation.method+" "+operation.path] = true
+				continue operations
+			case "a":
+				break operations
+			case "t":
+				if tag, ok := prompt("  New tag: "); ok && tag != "" {
+					operation.op.Tags = []string{tag}
+				}
+			case "s":
+				if summary, ok := prompt("  New summary: "); ok && summary != "" {
+					operation.op.Summary = summary
+				}
+			default:
+				fmt.Fprintf(out, "  %s\n", reviewHelp)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read review answers: %v", err)
+	}
+
+	removeOperations(spec, removed)
+	syncTags(spec)
+	return nil
+}
+
+// removeOperations removes operations given as "METHOD path", the paths left without
+// operations, and the links pointing at removed operations
+func removeOperations(spec *OpenAPISpec, removed map[string]bool) {
+	if len(removed) == 0 {
+		return
+	}
+
+	removedIDs := make(map[string]bool)
+	for _, path := range spec.Paths.InMatchingOrder() {
+		pathItem := spec.Paths.Value(path)
+		for _, method := range operationMethods {
+			op := operationFor(pathItem, method)
+			if op == nil || !removed[method+" "+path] {
+				continue
+			}
+			if op.OperationID != "" {
+				removedIDs[op.OperationID] = true
+			}
+			pathItem.SetOperation(method, nil)
+		}
+		if len(pathItem.Operations()) == 0 {
+			spec.Paths.Delete(path)
+		}
+	}
+
+	for _, pathItem := range spec.Paths.Map() {
+		for _, op := range pathItem.Operations() {
+			if op.Responses == nil {
+				continue
+			}
+			for _, response := range op.Responses.Map() {
+				if response.Value == nil {
+					continue
+				}
+				for name, link := range response.Value.Links {
+					if link.Value != nil && removedIDs[link.Value.OperationID] {
+						delete(response.Value.Links, name)
+					}
+				}
+			}
+		}
+	}
+}
+
+// syncTags updates the top-level tags and x-tagGroups after operations were removed or retagged:
+// tags no operation uses anymore are dropped, and new tags are declared and listed in the group
+// of ungrouped tags
+func syncTags(spec *OpenAPISpec) {
+	used := make(map[string]bool)
+	for _, pathItem := range spec.Paths.Map() {
+		for _, op := range pathItem.Operations() {
+			for _, tag := range op.Tags {
+				used[tag] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	declared := make(map[string]bool)
+	var tags openapi3.Tags
+	for _, tag := range spec.Tags {
+		if used[tag.Name] {
+			tags = append(tags, tag)
+			declared[tag.Name] = true
+		}
+	}
+	var added []string
+	for _, name := range names {
+		if !declared[name] {
+			tags = append(tags, &openapi3.Tag{Name: name, Description: fmt.Sprintf("Operations related to %s", name)})
+			added = append(added, name)
+		}
+	}
+	spec.Tags = tags
+
+	// Only groups built by the generator are updated, not ones read from an existing document
+	groups, ok := spec.Extensions["x-tagGroups"].([]map[string]interface{})
+	if !ok {
+		return
+	}
+	var synced []map[string]interface{}
+	for _, group := range groups {
+		var groupTags []string
+		configured, ok := group["tags"].([]string)
+		if !ok {
+			return
+		}
+		for _, tag := range configured {
+			if used[tag] {
+				groupTags = append(groupTags, tag)
+			}
+		}
+		if group["name"] == otherTagsGroup {
+			groupTags = append(groupTags, added...)
+			added = nil
+		}
+		if len(groupTags) > 0 {
+			synced = append(synced, map[string]interface{}{"name": group["name"], "tags": groupTags})
+		}
+	}
+	if len(added) > 0 {
+		synced = append(synced, map[string]interface{}{"name": otherTagsGroup, "tags": added})
+	}
+	spec.Extensions["x-tagGroups"] = synced
+}
@@ -0,0 +1,73 @@
+package openapi
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reviewTestConfig groups the tags of the review test document
+func reviewTestConfig() OpenAPIConfig {
+	return OpenAPIConfig{
+		UsePathGroups: true,
+		TagGroups:     []OpenAPITagGroup{{Name: "Shop", Tags: []string{"Orders", "Users"}}},
+	}
+}
+
+// reviewTestTransactions document users and orders for a review to curate
+func reviewTestTransactions() []proxy.APITransaction {
+	return []proxy.APITransaction{
+		createTestTransaction("POST", "/users", []byte(`{"name":"__string__"}`), []byte(`{"id":"__integer__","name":"__string__"}`), 201),
+		createTestTransaction("GET", "/users/123", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200),
+		createTestTransaction("GET", "/users/456", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200),
+		createTestTransaction("GET", "/orders", nil, []byte(`[{"total":"__number__"}]`), 200),
+	}
+}
+
+func TestReviewSpec(t *testing.T) {
+	spec, _ := generateTestSpec(t, reviewTestConfig(), reviewTestTransactions()...)
+	require.NotEmpty(t, spec.Paths.Find("/users").Post.Responses.Value("201").Value.Links)
+
+	// Orders come first: retag GET /orders, then remove GET /users/{userId} and keep the rest
+	answers := strings.Join([]string{"t", "Billing", "s", "List all orders", "", "y", "n", "?", "a"}, "\n")
+	var out bytes.Buffer
+	require.NoError(t, ReviewSpec(spec, strings.NewReader(answers), &out))
+
+	assert.Contains(t, out.String(), "[1/3] GET /orders  (Orders)")
+	assert.Contains(t, out.String(), "[3/3] GET /users/{userId}  (Users)")
+
+	orders := spec.Paths.Find("/orders").Get
+	assert.Equal(t, []string{"Billing"}, orders.Tags)
+	assert.Equal(t, "List all orders", orders.Summary)
+
+	// The removed operation's path and the links to it are gone
+	assert.Nil(t, spec.Paths.Find("/users/{userId}"))
+	assert.Empty(t, spec.Paths.Find("/users").Post.Responses.Value("201").Value.Links)
+
+	var tags []string
+	for _, tag := range spec.Tags {
+		tags = append(tags, tag.Name)
+	}
+	assert.Equal(t, []string{"Users", "Billing"}, tags)
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "Shop", "tags": []string{"Users"}},
+		{"name": "Other", "tags": []string{"Billing"}},
+	}, spec.Extensions["x-tagGroups"])
+}
+
+func TestReviewSpecEndOfInput(t *testing.T) {
+	spec, _ := generateTestSpec(t, reviewTestConfig(), reviewTestTransactions()...)
+
+	// Running out of answers keeps the remaining operations
+	var out bytes.Buffer
+	require.NoError(t, ReviewSpec(spec, strings.NewReader("n\n"), &out))
+
+	assert.Nil(t, spec.Paths.Find("/orders"))
+	assert.NotNil(t, spec.Paths.Find("/users").Post)
+	assert.NotNil(t, spec.Paths.Find("/users/{userId}").Get)
+}
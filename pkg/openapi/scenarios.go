@@ -0,0 +1,47 @@
+package openapi
+
+import (
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// requestScenarios returns the scenarios of transactions by method and request path, in the order
+// they were first seen. It runs before the response strategy, which may drop transactions of a
+// scenario.
+func requestScenarios(transactions []proxy.APITransaction) map[string][]string {
+	scenarios := make(map[string][]string)
+	for _, tx := range transactions {
+		name := tx.Request.Annotations[proxy.ScenarioAnnotation]
+		if name == "" {
+			continue
+		}
+		key := tx.Request.Method + " " + tx.Request.Path
+		scenarios[key] = appendScenarios(scenarios[key], name)
+	}
+	return scenarios
+}
+
+// appendScenarios appends the names that scenarios doesn't list yet
+func appendScenarios(scenarios []string, names ...string) []string {
+	for _, name := range names {
+		found := false
+		for _, scenario := range scenarios {
+			if scenario == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			scenarios = append(scenarios, name)
+		}
+	}
+	return scenarios
+}
+
+// addScenarios sets x-scenarios on operations, listing the captured flows they took part in
+func addScenarios(doc *OpenAPISpec, scenarios map[string][]string) {
+	for key, names := range scenarios {
+		if op := operationForKey(doc, key); op != nil {
+			setOperationExtension(op, "x-scenarios", names)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecScenarios(t *testing.T) {
+	inScenario := func(tx proxy.APITransaction, scenario string) proxy.APITransaction {
+		tx.Request.Annotations = map[string]string{proxy.ScenarioAnnotation: scenario}
+		return tx
+	}
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:            "Test API",
+		Version:          "1.0.0",
+		ResponseStrategy: StrategyBestSuccess,
+	})
+	generator.AddTransaction(inScenario(createTestTransaction("POST", "/users", []byte(`{"name":"__string__"}`), []byte(`{"id":"__integer__"}`), 201), "signup"))
+	generator.AddTransaction(inScenario(createTestTransaction("GET", "/users/123", nil, []byte(`{"id":"__integer__"}`), 200), "signup"))
+	generator.AddTransaction(inScenario(createTestTransaction("GET", "/users/456", nil, []byte(`{"id":"__integer__"}`), 200), "checkout"))
+	generator.AddTransaction(inScenario(createTestTransaction("GET", "/users/123", nil, []byte(`{"error":"__string__"}`), 404), "account recovery"))
+	generator.AddTransaction(createTestTransaction("GET", "/orders", nil, []byte(`[]`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	// Scenarios of transactions the response strategy leaves out are kept
+	assert.Equal(t, []string{"signup", "account recovery", "checkout"}, spec.Paths.Find("/users/{userId}").Get.Extensions["x-scenarios"])
+	assert.Equal(t, []string{"signup"}, spec.Paths.Find("/users").Post.Extensions["x-scenarios"])
+	assert.NotContains(t, spec.Paths.Find("/orders").Get.Extensions, "x-scenarios")
+}
@@ -0,0 +1,118 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Documentation UIs the serve handler can render a document with
+const (
+	UIRedoc   = "redoc"
+	UISwagger = "swagger-ui"
+)
+
+// UIs lists the documentation UIs in the order they are offered to users
+var UIs = []string{UIRedoc, UISwagger}
+
+// Swagger UI assets loaded by served pages
+const (
+	swaggerUIScriptURL = "https://unpkg.com/swagger-ui-dist@5.17.14/swagger-ui-bundle.js"
+	swaggerUIStyleURL  = "https://unpkg.com/swagger-ui-dist@5.17.14/swagger-ui.css"
+)
+
+// Paths of the serve handler besides the page itself
+const (
+	serveSpecPath    = "/openapi.json"
+	serveVersionPath = "/version"
+)
+
+// ServeHandler returns a handler that serves the OpenAPI document (JSON or YAML) at path with
+// Redoc or Swagger UI. The document is read again on every request, and the page reloads itself
+// when the file changes, so regenerated output can be reviewed without restarting the server.
+func ServeHandler(path string, ui string) (http.Handler, error) {
+	if ui != UIRedoc && ui != UISwagger {
+		return nil, fmt.Errorf("unknown UI %q, expected one of %v", ui, UIs)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(renderServePage(ui))
+	})
+	mux.HandleFunc(serveSpecPath, func(w http.ResponseWriter, r *http.Request) {
+		spec, err := LoadSpec(path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data, err := json.Marshal(spec)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(data)
+	})
+	mux.HandleFunc(serveVersionPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprint(w, fileVersion(path))
+	})
+	return mux, nil
+}
+
+// fileVersion identifies the current content of a file by its modification time and size
+func fileVersion(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "missing"
+	}
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+}
+
+// renderServePage renders the page that loads the served document into a UI and reloads when
+// the version of the file changes
+func renderServePage(ui string) []byte {
+	var page bytes.Buffer
+	page.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	page.WriteString("  <meta charset=\"utf-8\">\n")
+	page.WriteString("  <meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	page.WriteString("  <title>API Documentation</title>\n")
+	page.WriteString("  <style>body { margin: 0; padding: 0; }</style>\n")
+	if ui == UISwagger {
+		page.WriteString("  <link rel=\"stylesheet\" href=\"" + swaggerUIStyleURL + "\">\n")
+	}
+	page.WriteString("</head>\n<body>\n")
+	page.WriteString("  <div id=\"ui\"></div>\n")
+
+	if ui == UISwagger {
+		page.WriteString("  <script src=\"" + swaggerUIScriptURL + "\"></script>\n")
+		page.WriteString("  <script>\n")
+		page.WriteString("    SwaggerUIBundle({ url: \"" + serveSpecPath + "\", dom_id: \"#ui\" });\n")
+	} else {
+		page.WriteString("  <script src=\"" + redocScriptURL + "\"></script>\n")
+		page.WriteString("  <script>\n")
+		page.WriteString("    Redoc.init(\"" + serveSpecPath + "\", {}, document.getElementById(\"ui\"));\n")
+	}
+
+	// Poll the version of the file and reload once it changes
+	page.WriteString("    var version = null;\n")
+	page.WriteString("    setInterval(function () {\n")
+	page.WriteString("      fetch(\"" + serveVersionPath + "\").then(function (r) { return r.text(); }).then(function (v) {\n")
+	page.WriteString("        if (version !== null && v !== version) { location.reload(); }\n")
+	page.WriteString("        version = v;\n")
+	page.WriteString("      }).catch(function () {});\n")
+	page.WriteString("    }, 1000);\n")
+	page.WriteString("  </script>\n")
+	page.WriteString("</body>\n</html>\n")
+
+	return page.Bytes()
+}
@@ -0,0 +1,61 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("openapi: 3.0.3\ninfo:\n  title: Orders\n  version: 1.0.0\npaths: {}\n"), 0644))
+
+	get := func(handler http.Handler, target string) (int, string) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", target, nil))
+		body, _ := io.ReadAll(recorder.Result().Body)
+		return recorder.Code, string(body)
+	}
+
+	handler, err := ServeHandler(path, UIRedoc)
+	require.NoError(t, err)
+
+	code, page := get(handler, "/")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, page, redocScriptURL)
+	assert.Contains(t, page, `Redoc.init("/openapi.json"`)
+
+	// YAML documents are served as JSON
+	code, body := get(handler, "/openapi.json")
+	require.Equal(t, http.StatusOK, code)
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(body), &spec))
+	assert.Equal(t, "Orders", spec["info"].(map[string]interface{})["title"])
+
+	// The version changes with the file, which makes the page reload
+	_, before := get(handler, "/version")
+	require.NoError(t, os.WriteFile(path, []byte("openapi: 3.0.3\ninfo:\n  title: Orders v2\n  version: 2.0.0\npaths: {}\n"), 0644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	_, after := get(handler, "/version")
+	assert.NotEqual(t, before, after)
+
+	code, _ = get(handler, "/missing")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	handler, err = ServeHandler(path, UISwagger)
+	require.NoError(t, err)
+	_, page = get(handler, "/")
+	assert.Contains(t, page, swaggerUIScriptURL)
+	assert.Contains(t, page, swaggerUIStyleURL)
+
+	_, err = ServeHandler(path, "rapidoc")
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package openapi
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// OpenAPIServerVariable is a substitution for a {name} placeholder in a server URL
+type OpenAPIServerVariable struct {
+	Default     string
+	Enum        []string
+	Description string
+}
+
+// serverVariablePattern matches the {name} placeholders of templated server URLs
+var serverVariablePattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// addServers adds the configured servers and, if enabled, the hosts seen in the captured
+// traffic, followed by the prefixes stripped from the paths. Every placeholder of a templated
+// server URL must have a variable.
+func (g *OpenAPIGenerator) addServers(doc *OpenAPISpec, transactions []proxy.APITransaction, prefixes []string) error {
+	servers := g.config.Servers
+	if g.config.InferServers {
+		servers = append(servers, observedServers(transactions, servers)...)
+	}
+	servers = prefixedServers(servers, prefixes)
+
+	for _, server := range servers {
+		result := &openapi3.Server{
+			URL:         server.URL,
+			Description: server.Description,
+		}
+
+		for _, match := range serverVariablePattern.FindAllStringSubmatch(server.URL, -1) {
+			name := match[1]
+			variable, ok := server.Variables[name]
+			if !ok {
+				return fmt.Errorf("server %s has no variable for {%s}", server.URL, name)
+			}
+			if result.Variables == nil {
+				result.Variables = make(map[string]*openapi3.ServerVariable)
+			}
+			result.Variables[name] = &openapi3.ServerVariable{
+				Default:     variable.Default,
+				Enum:        variable.Enum,
+				Description: variable.Description,
+			}
+		}
+
+		doc.Servers = append(doc.Servers, result)
+	}
+	return nil
+}
+
+// observedServers returns a server for every host the captured requests were sent to, from the
+// X-Forwarded-Host or Host headers, skipping hosts that are already configured
+func observedServers(transactions []proxy.APITransaction, configured []OpenAPIServer) []OpenAPIServer {
+	known := make(map[string]bool)
+	for _, server := range configured {
+		known[strings.TrimSuffix(server.URL, "/")] = true
+	}
+
+	urls := make(map[string]bool)
+	for _, tx := range transactions {
+		headers := tx.Request.Headers
+		host := firstHeaderValue(headers.Get("X-Forwarded-Host"))
+		if host == "" {
+			host = headers.Get("Host")
+		}
+		if host == "" || strings.HasPrefix(host, "__") {
+			continue
+		}
+
+		scheme := firstHeaderValue(headers.Get("X-Forwarded-Proto"))
+		if scheme != "http" && scheme != "https" {
+			scheme = "https"
+			if isLocalHost(host) {
+				scheme = "http"
+			}
+		}
+
+		url := scheme + "://" + host
+		if !known[url] {
+			urls[url] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(urls))
+	for url := range urls {
+		sorted = append(sorted, url)
+	}
+	sort.Strings(sorted)
+
+	var servers []OpenAPIServer
+	for _, url := range sorted {
+		servers = append(servers, OpenAPIServer{URL: url, Description: "Observed host"})
+	}
+	return servers
+}
+
+// firstHeaderValue returns the first entry of a comma-separated header such as
+// X-Forwarded-Host, which lists every proxy hop
+func firstHeaderValue(value string) string {
+	return strings.TrimSpace(strings.Split(value, ",")[0])
+}
+
+// isLocalHost checks whether a host is the local machine, which is usually served over http
+func isLocalHost(host string) bool {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
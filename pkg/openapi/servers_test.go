@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecServerVariables(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:   "Test API",
+		Version: "1.0.0",
+		Servers: []OpenAPIServer{
+			{URL: "http://localhost:8080", Description: "Local"},
+			{
+				URL:         "https://{env}.api.example.com",
+				Description: "Hosted",
+				Variables: map[string]OpenAPIServerVariable{
+					"env": {Default: "prod", Enum: []string{"prod", "staging"}},
+				},
+			},
+		},
+	})
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, nil, 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	require.Len(t, spec.Servers, 2)
+	assert.Nil(t, spec.Servers[0].Variables)
+	env := spec.Servers[1].Variables["env"]
+	require.NotNil(t, env)
+	assert.Equal(t, "prod", env.Default)
+	assert.Equal(t, []string{"prod", "staging"}, env.Enum)
+	assert.NoError(t, ValidateSpec(spec))
+
+	// A placeholder without a variable is an error
+	missing := NewOpenAPIGenerator(OpenAPIConfig{
+		Servers: []OpenAPIServer{{URL: "https://{region}.api.example.com"}},
+	})
+	_, err = missing.GenerateSpec()
+	assert.Error(t, err)
+}
+
+func TestGenerateSpecInferServers(t *testing.T) {
+	forwarded := createTestTransaction("GET", "/users", nil, nil, 200)
+	forwarded.Request.Headers.Set("Host", "localhost:8080")
+	forwarded.Request.Headers.Set("X-Forwarded-Host", "api.example.com, gateway.internal")
+	forwarded.Request.Headers.Set("X-Forwarded-Proto", "https")
+
+	local := createTestTransaction("GET", "/orders", nil, nil, 200)
+	local.Request.Headers.Set("Host", "localhost:9000")
+
+	configured := createTestTransaction("GET", "/items", nil, nil, 200)
+	configured.Request.Headers.Set("Host", "staging.example.com")
+	configured.Request.Headers.Set("X-Forwarded-Proto", "https")
+
+	for _, infer := range []bool{false, true} {
+		generator := NewOpenAPIGenerator(OpenAPIConfig{
+			InferServers: infer,
+			Servers:      []OpenAPIServer{{URL: "https://staging.example.com/", Description: "Staging"}},
+		})
+		for _, tx := range []proxy.APITransaction{forwarded, local, configured} {
+			generator.AddTransaction(tx)
+		}
+
+		spec, err := generator.GenerateSpec()
+		require.NoError(t, err)
+
+		var urls []string
+		for _, server := range spec.Servers {
+			urls = append(urls, server.URL)
+		}
+		if !infer {
+			assert.Equal(t, []string{"https://staging.example.com/"}, urls)
+			continue
+		}
+		assert.Equal(t, []string{"https://staging.example.com/", "http://localhost:9000", "https://api.example.com"}, urls)
+
+		// Routing headers are not documented as parameters
+		for _, param := range spec.Paths.Find("/users").Get.Parameters {
+			assert.NotEqual(t, "X-Forwarded-Host", param.Value.Name)
+		}
+	}
+}
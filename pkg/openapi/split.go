@@ -0,0 +1,161 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// splitFileNamePattern matches the characters of a path that are replaced in its file name
+var splitFileNamePattern = regexp.MustCompile(`[^A-Za-z0-9._{}-]+`)
+
+// inlineComponentTypes are component types kept in the root document when it is split
+var inlineComponentTypes = map[string]bool{"securitySchemes": true}
+
+// RenderSplit splits a document into a root file plus a file per path under paths/ and a file
+// per component under components/<type>/, connected by relative $refs. Files are JSON or YAML
+// depending on the format. It returns the file contents by slash-separated path, with the root
+// document under rootName.
+func RenderSplit(spec *OpenAPISpec, rootName, format string) (map[string][]byte, error) {
+	if format != FormatJSON && format != FormatYAML {
+		return nil, fmt.Errorf("split output needs json or yaml, not %q", format)
+	}
+	ext := "." + format
+
+	// Work on plain values so that references can be rewritten per file
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	documents := make(map[string]interface{})
+
+	if components, ok := root["components"].(map[string]interface{}); ok {
+		for componentType, entries := range components {
+			entries, ok := entries.(map[string]interface{})
+			if !ok || inlineComponentTypes[componentType] {
+				continue
+			}
+			for name, component := range entries {
+				file := "components/" + componentType + "/" + name + ext
+				documents[file] = component
+				entries[name] = map[string]interface{}{"$ref": "./" + file}
+			}
+		}
+	}
+
+	if paths, ok := root["paths"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(paths))
+		for name := range paths {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		used := make(map[string]bool)
+		for _, name := range names {
+			file := splitPathFileName(name)
+			for n := 2; used[file]; n++ {
+				file = fmt.Sprintf("%s_%d", splitPathFileName(name), n)
+			}
+			used[file] = true
+
+			file = "paths/" + file + ext
+			documents[file] = paths[name]
+			paths[name] = map[string]interface{}{"$ref": "./" + file}
+		}
+	}
+
+	files := make(map[string][]byte)
+	documents[rootName] = root
+	for file, document := range documents {
+		rewriteComponentRefs(document, path.Dir(file), ext)
+		if files[file], err = marshalFormat(document, format); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// WriteSplit writes a split document with its root at outputPath and the path and component
+// files next to it
+func WriteSplit(spec *OpenAPISpec, outputPath, format string) error {
+	files, err := RenderSplit(spec, filepath.Base(outputPath), format)
+	if err != nil {
+		return err
+	}
+	return writeFiles(filepath.Dir(outputPath), files)
+}
+
+// splitPathFileName returns the file name of a path, e.g. /users/{userId} -> users_{userId}
+func splitPathFileName(p string) string {
+	name := strings.Trim(splitFileNamePattern.ReplaceAllString(p, "_"), "_")
+	if name == "" {
+		return "root"
+	}
+	return name
+}
+
+// rewriteComponentRefs rewrites the #/components/... references in a value into paths relative
+// to the directory of the file it is written to
+func rewriteComponentRefs(value interface{}, dir, ext string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				v[key] = relativeComponentRef(ref, dir, ext)
+				continue
+			}
+			rewriteComponentRefs(child, dir, ext)
+		}
+		// Discriminator mappings hold references as plain values
+		if mapping, ok := v["mapping"].(map[string]interface{}); ok {
+			for key, target := range mapping {
+				if ref, ok := target.(string); ok {
+					mapping[key] = relativeComponentRef(ref, dir, ext)
+				}
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			rewriteComponentRefs(child, dir, ext)
+		}
+	}
+}
+
+// relativeComponentRef turns a #/components/<type>/<name> reference into the relative path of
+// the component's file, leaving other references alone
+func relativeComponentRef(ref, dir, ext string) string {
+	parts := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+	if !strings.HasPrefix(ref, "#/components/") || len(parts) != 3 || inlineComponentTypes[parts[1]] {
+		return ref
+	}
+
+	target := strings.Join(parts, "/") + ext
+	relative, err := filepath.Rel(filepath.FromSlash(dir), filepath.FromSlash(target))
+	if err != nil {
+		return ref
+	}
+	relative = filepath.ToSlash(relative)
+	if !strings.HasPrefix(relative, "../") {
+		relative = "./" + relative
+	}
+	return relative
+}
+
+// marshalFormat serializes a plain value as indented JSON or YAML
+func marshalFormat(value interface{}, format string) ([]byte, error) {
+	if format == FormatYAML {
+		return yaml.Marshal(value)
+	}
+	return json.MarshalIndent(value, "", "  ")
+}
@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSplit(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			spec, _ := generateTestSpec(t, shopTestConfig(), exportTestTransactions()...)
+			dir := t.TempDir()
+			root := filepath.Join(dir, "openapi."+format)
+			require.NoError(t, WriteSplit(spec, root, format))
+
+			// The split files resolve back into a valid document
+			loader := openapi3.NewLoader()
+			loader.IsExternalRefsAllowed = true
+			loaded, err := loader.LoadFromFile(root)
+			require.NoError(t, err)
+			require.NoError(t, loaded.Validate(context.Background()))
+
+			item := loaded.Paths.Value("/users/{userId}")
+			require.NotNil(t, item)
+			assert.Equal(t, "./paths/users_{userId}."+format, item.Ref)
+			require.NotNil(t, item.Get)
+
+			response := item.Get.Responses.Value("200").Value
+			schema := response.Content.Get("application/json").Schema
+			assert.Equal(t, "../components/schemas/User."+format, schema.Ref)
+			assert.Contains(t, schema.Value.Properties, "name")
+		})
+	}
+}
+
+func TestRenderSplitFiles(t *testing.T) {
+	spec, _ := generateTestSpec(t, shopTestConfig(), exportTestTransactions()...)
+	files, err := RenderSplit(spec, "openapi.yaml", FormatYAML)
+	require.NoError(t, err)
+
+	assert.Contains(t, files, "openapi.yaml")
+	assert.Contains(t, files, "paths/users.yaml")
+	assert.Contains(t, files, "paths/orders.yaml")
+	assert.Contains(t, files, "components/schemas/User.yaml")
+
+	_, err = RenderSplit(spec, "index.html", FormatHTML)
+	assert.Error(t, err)
+}
+
+func TestRelativeComponentRef(t *testing.T) {
+	tests := []struct {
+		ref      string
+		dir      string
+		expected string
+	}{
+		{ref: "#/components/schemas/User", dir: ".", expected: "./components/schemas/User.yaml"},
+		{ref: "#/components/schemas/User", dir: "paths", expected: "../components/schemas/User.yaml"},
+		{ref: "#/components/schemas/User", dir: "components/schemas", expected: "./User.yaml"},
+		{ref: "#/components/schemas/User", dir: "components/responses", expected: "../schemas/User.yaml"},
+		{ref: "#/components/securitySchemes/bearerAuth", dir: "paths", expected: "#/components/securitySchemes/bearerAuth"},
+		{ref: "https://example.com/schema.json", dir: "paths", expected: "https://example.com/schema.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref+" from "+tt.dir, func(t *testing.T) {
+			assert.Equal(t, tt.expected, relativeComponentRef(tt.ref, tt.dir, ".yaml"))
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// lastSeenExtension is the operation extension holding when a stale operation was last called
+const lastSeenExtension = "x-last-seen"
+
+// requestLastSeen returns when every method and request path was last requested. It runs before
+// the response strategy, so that the latest request counts even when an earlier one is
+// documented. Transactions without timestamps are left out.
+func requestLastSeen(transactions []proxy.APITransaction) map[string]time.Time {
+	lastSeen := make(map[string]time.Time)
+	for _, tx := range transactions {
+		if tx.Request.Timestamp.IsZero() {
+			continue
+		}
+		key := tx.Request.Method + " " + tx.Request.Path
+		if tx.Request.Timestamp.After(lastSeen[key]) {
+			lastSeen[key] = tx.Request.Timestamp
+		}
+	}
+	return lastSeen
+}
+
+// markStaleEndpoints reports the operations not called since the start of the StaleSessions most
+// recent capture sessions, possibly removed routes, and sets x-last-seen on them. They are marked
+// deprecated too with DeprecateStale. Nothing is stale until more sessions than StaleSessions
+// were captured, nor are operations whose requests have no timestamps.
+func (g *OpenAPIGenerator) markStaleEndpoints(doc *OpenAPISpec, lastSeen map[string]time.Time) {
+	sessions := len(g.config.SessionStarts)
+	if g.config.StaleSessions <= 0 || sessions <= g.config.StaleSessions {
+		return
+	}
+	starts := append([]time.Time(nil), g.config.SessionStarts...)
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	cutoff := starts[sessions-g.config.StaleSessions]
+
+	templates, prefixes := documentedPaths(doc)
+	byOperation := make(map[string]time.Time)
+	for key, seen := range lastSeen {
+		method, path, _ := strings.Cut(key, " ")
+		template, ok := matchPathTemplate(path, templates, prefixes)
+		if ok && seen.After(byOperation[method+" "+template]) {
+			byOperation[method+" "+template] = seen
+		}
+	}
+
+	var stale []string
+	for key, seen := range byOperation {
+		op := operationForKey(doc, key)
+		if op == nil || !seen.Before(cutoff) {
+			continue
+		}
+		setOperationExtension(op, lastSeenExtension, seen.UTC().Format(time.RFC3339))
+		if g.config.DeprecateStale {
+			op.Deprecated = true
+		}
+		stale = append(stale, key)
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		if !byOperation[stale[i]].Equal(byOperation[stale[j]]) {
+			return byOperation[stale[i]].Before(byOperation[stale[j]])
+		}
+		return stale[i] < stale[j]
+	})
+	for _, key := range stale {
+		g.stats.StaleEndpoints = append(g.stats.StaleEndpoints, fmt.Sprintf("%s, last seen %s", key, byOperation[key].Format("2006-01-02 15:04")))
+	}
+}
@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// staleTestWeek is the length of every session of the stale endpoint tests, the first starting at
+// staleTestStart
+const staleTestWeek = 7 * 24 * time.Hour
+
+var staleTestStart = time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+// staleTestSessions are the starts of the three sessions, out of order
+func staleTestSessions() []time.Time {
+	return []time.Time{staleTestStart.Add(2 * staleTestWeek), staleTestStart, staleTestStart.Add(staleTestWeek)}
+}
+
+// staleTestTransactions were captured in the three sessions: /reports was only called in the
+// first, /users in the first two, and /orders in all of them
+func staleTestTransactions() []proxy.APITransaction {
+	at := func(tx proxy.APITransaction, timestamp time.Time) proxy.APITransaction {
+		tx.Request.Timestamp = timestamp
+		return tx
+	}
+	transactions := []proxy.APITransaction{
+		at(createTestTransaction("GET", "/reports/7", nil, []byte(`{"id":7}`), 200), staleTestStart.Add(time.Hour)),
+		at(createTestTransaction("GET", "/users/1", nil, []byte(`{"id":1}`), 200), staleTestStart.Add(time.Hour)),
+		at(createTestTransaction("GET", "/users/2", nil, []byte(`{"id":2}`), 200), staleTestStart.Add(staleTestWeek+time.Hour)),
+	}
+	for session := 0; session < 3; session++ {
+		transactions = append(transactions, at(createTestTransaction("GET", "/orders", nil, []byte(`[{"id":1}]`), 200), staleTestStart.Add(time.Duration(session)*staleTestWeek+time.Hour)))
+	}
+	// Requests without timestamps, e.g. imported ones, are never stale
+	return append(transactions, createTestTransaction("GET", "/health", nil, []byte(`{"status":"ok"}`), 200))
+}
+
+func TestStaleEndpoints(t *testing.T) {
+	config := OpenAPIConfig{UsePathGroups: true, SessionStarts: staleTestSessions(), StaleSessions: 1}
+	spec, stats := generateTestSpec(t, config, staleTestTransactions()...)
+
+	assert.Equal(t, []string{
+		"GET /reports/{reportId}, last seen 2026-01-05 10:00",
+		"GET /users/{userId}, last seen 2026-01-12 10:00",
+	}, stats.StaleEndpoints)
+	users := spec.Paths.Value("/users/{userId}").Get
+	assert.Equal(t, "2026-01-12T10:00:00Z", users.Extensions[lastSeenExtension])
+	assert.False(t, users.Deprecated)
+	assert.NotContains(t, spec.Paths.Value("/orders").Get.Extensions, lastSeenExtension)
+	assert.NotContains(t, spec.Paths.Value("/health").Get.Extensions, lastSeenExtension)
+
+	assert.Equal(t, stats.StaleEndpoints, NewQualityReport(spec, stats).StaleEndpoints)
+}
+
+func TestStaleEndpointsDeprecated(t *testing.T) {
+	config := OpenAPIConfig{UsePathGroups: true, SessionStarts: staleTestSessions(), StaleSessions: 2, DeprecateStale: true}
+	spec, stats := generateTestSpec(t, config, staleTestTransactions()...)
+
+	assert.Equal(t, []string{"GET /reports/{reportId}, last seen 2026-01-05 10:00"}, stats.StaleEndpoints)
+	assert.True(t, spec.Paths.Value("/reports/{reportId}").Get.Deprecated)
+	assert.False(t, spec.Paths.Value("/users/{userId}").Get.Deprecated)
+}
+
+func TestStaleEndpointsTooFewSessions(t *testing.T) {
+	config := OpenAPIConfig{UsePathGroups: true, SessionStarts: staleTestSessions(), StaleSessions: 3, DeprecateStale: true}
+	spec, stats := generateTestSpec(t, config, staleTestTransactions()...)
+
+	assert.Empty(t, stats.StaleEndpoints)
+	assert.False(t, spec.Paths.Value("/reports/{reportId}").Get.Deprecated)
+}
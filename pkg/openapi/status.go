@@ -0,0 +1,18 @@
+package openapi
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// statusDescription returns the description of a response status code: the configured one,
+// otherwise the standard reason phrase, e.g. "Too Many Requests" for 429
+func (g *OpenAPIGenerator) statusDescription(statusCode int) string {
+	if description, ok := g.config.StatusDescriptions[strconv.Itoa(statusCode)]; ok {
+		return description
+	}
+	if text := http.StatusText(statusCode); text != "" {
+		return text
+	}
+	return "Response"
+}
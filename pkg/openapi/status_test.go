@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusDescription(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		StatusDescriptions: map[string]string{"429": "Rate limit exceeded"},
+	})
+
+	tests := []struct {
+		statusCode int
+		expected   string
+	}{
+		{statusCode: 200, expected: "OK"},
+		{statusCode: 418, expected: "I'm a teapot"},
+		{statusCode: 422, expected: "Unprocessable Entity"},
+		{statusCode: 429, expected: "Rate limit exceeded"},
+		{statusCode: 503, expected: "Service Unavailable"},
+		{statusCode: 599, expected: "Response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, generator.statusDescription(tt.statusCode))
+		})
+	}
+}
+
+func TestGenerateSpecStatusDescriptions(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:              "Test API",
+		Version:            "1.0.0",
+		StatusDescriptions: map[string]string{"429": "Rate limit exceeded"},
+	})
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, []byte(`{"error":"__string__"}`), 429))
+	generator.AddTransaction(createTestTransaction("POST", "/users", nil, []byte(`{"error":"__string__"}`), 409))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	get := spec.Paths.Find("/users").Get
+	require.NotNil(t, get.Responses.Value("429"))
+	assert.Equal(t, "Rate limit exceeded", *get.Responses.Value("429").Value.Description)
+
+	post := spec.Paths.Find("/users").Post
+	require.NotNil(t, post.Responses.Value("409"))
+	assert.Equal(t, "Conflict", *post.Responses.Value("409").Value.Description)
+}
@@ -0,0 +1,161 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// ResponseStrategy selects which captured transactions of an endpoint are documented
+type ResponseStrategy string
+
+// Response selection strategies. Endpoints are grouped by method and request path.
+const (
+	// StrategyBestSuccess keeps one transaction per endpoint, preferring 2xx over 3xx over
+	// 4xx over 5xx responses and the lowest status code within a class
+	StrategyBestSuccess ResponseStrategy = "best-success"
+	// StrategyAllStatuses keeps every transaction, documenting all observed status codes
+	StrategyAllStatuses ResponseStrategy = "all-statuses"
+	// StrategyLatest keeps the most recently captured transaction per endpoint
+	StrategyLatest ResponseStrategy = "latest"
+	// StrategyMostSamples keeps the transactions with the status code seen most often per
+	// endpoint, preferring the lower status code on ties
+	StrategyMostSamples ResponseStrategy = "most-samples"
+)
+
+// ResponseStrategies lists the supported response selection strategies
+var ResponseStrategies = []ResponseStrategy{StrategyBestSuccess, StrategyAllStatuses, StrategyLatest, StrategyMostSamples}
+
+// ParseResponseStrategy validates the name of a response selection strategy
+func ParseResponseStrategy(name string) (ResponseStrategy, error) {
+	names := make([]string, len(ResponseStrategies))
+	for i, strategy := range ResponseStrategies {
+		if string(strategy) == name {
+			return strategy, nil
+		}
+		names[i] = string(strategy)
+	}
+	return "", fmt.Errorf("unknown response strategy %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// SelectTransactions applies a response selection strategy to captured transactions. An
+// empty strategy keeps every transaction. Endpoints keep the order of their first transaction,
+// and the X-SwagDoc-* annotations of all their transactions.
+func SelectTransactions(transactions []proxy.APITransaction, strategy ResponseStrategy) ([]proxy.APITransaction, error) {
+	if strategy == "" || strategy == StrategyAllStatuses {
+		return transactions, nil
+	}
+	if _, err := ParseResponseStrategy(string(strategy)); err != nil {
+		return nil, err
+	}
+
+	// Group transactions by endpoint (method + path)
+	var keys []string
+	endpoints := make(map[string][]proxy.APITransaction)
+	for _, tx := range transactions {
+		key := tx.Request.Method + ":" + tx.Request.Path
+		if _, ok := endpoints[key]; !ok {
+			keys = append(keys, key)
+		}
+		endpoints[key] = append(endpoints[key], tx)
+	}
+
+	var selected []proxy.APITransaction
+	for _, key := range keys {
+		txs := endpoints[key]
+		var kept []proxy.APITransaction
+		switch strategy {
+		case StrategyBestSuccess:
+			kept = []proxy.APITransaction{selectBestTransaction(txs)}
+		case StrategyLatest:
+			kept = []proxy.APITransaction{selectLatestTransaction(txs)}
+		case StrategyMostSamples:
+			kept = selectMostSampledTransactions(txs)
+		}
+
+		// Annotations may have been sent with a transaction that wasn't selected
+		if annotations := mergeAnnotations(txs); annotations != nil {
+			for i := range kept {
+				kept[i].Request.Annotations = annotations
+			}
+		}
+		selected = append(selected, kept...)
+	}
+	return selected, nil
+}
+
+// mergeAnnotations merges the annotations of transactions, later ones overriding earlier ones,
+// or returns nil when none has any
+func mergeAnnotations(transactions []proxy.APITransaction) map[string]string {
+	var merged map[string]string
+	for _, tx := range transactions {
+		for name, value := range tx.Request.Annotations {
+			if merged == nil {
+				merged = make(map[string]string)
+			}
+			merged[name] = value
+		}
+	}
+	return merged
+}
+
+// selectBestTransaction selects the transaction with the most successful response
+func selectBestTransaction(transactions []proxy.APITransaction) proxy.APITransaction {
+	best := transactions[0]
+	for _, tx := range transactions[1:] {
+		priority, bestPriority := statusPriority(tx.Response.StatusCode), statusPriority(best.Response.StatusCode)
+		if priority < bestPriority || (priority == bestPriority && tx.Response.StatusCode < best.Response.StatusCode) {
+			best = tx
+		}
+	}
+	return best
+}
+
+// statusPriority ranks status codes: 2xx > 3xx > 4xx > everything else
+func statusPriority(statusCode int) int {
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		return 0
+	case statusCode >= 300 && statusCode < 400:
+		return 1
+	case statusCode >= 400 && statusCode < 500:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// selectLatestTransaction selects the transaction whose response was captured last
+func selectLatestTransaction(transactions []proxy.APITransaction) proxy.APITransaction {
+	latest := transactions[0]
+	for _, tx := range transactions[1:] {
+		if !tx.Response.Timestamp.Before(latest.Response.Timestamp) {
+			latest = tx
+		}
+	}
+	return latest
+}
+
+// selectMostSampledTransactions selects the transactions with the most common status code
+func selectMostSampledTransactions(transactions []proxy.APITransaction) []proxy.APITransaction {
+	counts := make(map[int]int)
+	for _, tx := range transactions {
+		counts[tx.Response.StatusCode]++
+	}
+
+	status := transactions[0].Response.StatusCode
+	for code, count := range counts {
+		if count > counts[status] || (count == counts[status] && code < status) {
+			status = code
+		}
+	}
+
+	var selected []proxy.APITransaction
+	for _, tx := range transactions {
+		if tx.Response.StatusCode == status {
+			selected = append(selected, tx)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectTransactions(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newTx := func(method, path string, status int, minute int) proxy.APITransaction {
+		tx := createTestTransaction(method, path, nil, []byte(`{}`), status)
+		tx.Response.Timestamp = start.Add(time.Duration(minute) * time.Minute)
+		return tx
+	}
+	transactions := []proxy.APITransaction{
+		newTx("GET", "/users", 500, 0),
+		newTx("GET", "/users", 404, 1),
+		newTx("GET", "/users", 404, 2),
+		newTx("POST", "/users", 422, 3),
+		newTx("GET", "/users", 201, 4),
+		newTx("GET", "/users", 200, 5),
+		newTx("POST", "/users", 201, 6),
+		newTx("GET", "/users", 500, 7),
+	}
+
+	tests := []struct {
+		strategy ResponseStrategy
+		expected []int // status codes of the selected transactions, in order
+	}{
+		{strategy: "", expected: []int{500, 404, 404, 422, 201, 200, 201, 500}},
+		{strategy: StrategyAllStatuses, expected: []int{500, 404, 404, 422, 201, 200, 201, 500}},
+		{strategy: StrategyBestSuccess, expected: []int{200, 201}},
+		{strategy: StrategyLatest, expected: []int{500, 201}},
+		{strategy: StrategyMostSamples, expected: []int{404, 404, 201}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			selected, err := SelectTransactions(transactions, tt.strategy)
+			require.NoError(t, err)
+
+			statuses := make([]int, len(selected))
+			for i, tx := range selected {
+				statuses[i] = tx.Response.StatusCode
+			}
+			assert.Equal(t, tt.expected, statuses)
+		})
+	}
+
+	_, err := SelectTransactions(transactions, "newest")
+	assert.Error(t, err)
+}
+
+func TestSelectTransactionsKeepsAnnotations(t *testing.T) {
+	failed := createTestTransaction("GET", "/users", nil, []byte(`{}`), 500)
+	failed.Request.Annotations = map[string]string{"summary": "List users"}
+	succeeded := createTestTransaction("GET", "/users", nil, []byte(`{}`), 200)
+
+	selected, err := SelectTransactions([]proxy.APITransaction{failed, succeeded}, StrategyBestSuccess)
+	require.NoError(t, err)
+	require.Len(t, selected, 1)
+	assert.Equal(t, 200, selected[0].Response.StatusCode)
+	assert.Equal(t, map[string]string{"summary": "List users"}, selected[0].Request.Annotations)
+}
+
+func TestParseResponseStrategy(t *testing.T) {
+	strategy, err := ParseResponseStrategy("most-samples")
+	require.NoError(t, err)
+	assert.Equal(t, StrategyMostSamples, strategy)
+
+	_, err = ParseResponseStrategy("best")
+	assert.EqualError(t, err, `unknown response strategy "best", expected one of best-success, all-statuses, latest, most-samples`)
+}
+
+func TestGenerateSpecResponseStrategy(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:            "Test API",
+		Version:          "1.0.0",
+		ResponseStrategy: StrategyBestSuccess,
+	})
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, []byte(`{"error":"__string__"}`), 500))
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, []byte(`[{"id":"__integer__"}]`), 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	responses := spec.Paths.Find("/users").Get.Responses
+	assert.NotNil(t, responses.Value("200"))
+	assert.Nil(t, responses.Value("500"))
+}
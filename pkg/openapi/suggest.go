@@ -0,0 +1,106 @@
+package openapi
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// Kinds of filter suggestions, named after the generate flags that apply them
+const (
+	FilterExcludePath   = "exclude-path"
+	FilterExcludeMethod = "exclude-method"
+)
+
+// maxSuggestionExamples is how many matching paths a suggestion lists
+const maxSuggestionExamples = 3
+
+// FilterSuggestion is an exclude rule for traffic that rarely belongs in API documentation
+type FilterSuggestion struct {
+	Kind     string   // FilterExcludePath or FilterExcludeMethod
+	Value    string   // path regular expression or method
+	Reason   string   // e.g. "health checks"
+	Matches  int      // captured transactions the rule leaves out
+	Examples []string // a few distinct matching paths
+}
+
+// pathFilterRules are the path patterns suggested for exclusion, matched against request paths
+var pathFilterRules = []struct {
+	pattern string
+	reason  string
+}{
+	{`(^|/)(health|healthz|healthcheck|livez|readyz|ping)(/|$)`, "health checks"},
+	{`(^|/)(metrics|prometheus|actuator)(/|$)|^/debug/`, "metrics and debug endpoints"},
+	{`\.(css|js|map|png|jpe?g|gif|svg|ico|webp|woff2?|ttf|eot)$|^/robots\.txt$`, "static assets"},
+}
+
+// FilterSuggester collects the filter suggestions of captured transactions one transaction at a
+// time, so that huge captures can be streamed through it. It keeps a few example paths per rule
+// rather than the transactions.
+type FilterSuggester struct {
+	patterns    []*regexp.Regexp // of pathFilterRules
+	suggestions []FilterSuggestion
+}
+
+// NewFilterSuggester creates a suggester with the path rules followed by the CORS preflight rule
+func NewFilterSuggester() *FilterSuggester {
+	suggester := &FilterSuggester{}
+	for _, rule := range pathFilterRules {
+		suggester.patterns = append(suggester.patterns, regexp.MustCompile(rule.pattern))
+		suggester.suggestions = append(suggester.suggestions, FilterSuggestion{Kind: FilterExcludePath, Value: rule.pattern, Reason: rule.reason})
+	}
+	suggester.suggestions = append(suggester.suggestions, FilterSuggestion{Kind: FilterExcludeMethod, Value: "OPTIONS", Reason: "CORS preflight requests"})
+	return suggester
+}
+
+// Add counts a transaction towards the rules it matches
+func (s *FilterSuggester) Add(tx proxy.APITransaction) {
+	for i, re := range s.patterns {
+		if re.MatchString(tx.Request.Path) {
+			s.suggestions[i].match(tx.Request.Path)
+		}
+	}
+	if strings.EqualFold(tx.Request.Method, "OPTIONS") {
+		s.suggestions[len(s.patterns)].match(tx.Request.Path)
+	}
+}
+
+// Suggestions returns the rules that matched at least one transaction
+func (s *FilterSuggester) Suggestions() []FilterSuggestion {
+	var suggestions []FilterSuggestion
+	for _, suggestion := range s.suggestions {
+		if suggestion.Matches > 0 {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	return suggestions
+}
+
+// match counts a matching transaction, keeping the first distinct paths in sorted order as
+// examples
+func (f *FilterSuggestion) match(path string) {
+	f.Matches++
+	i := sort.SearchStrings(f.Examples, path)
+	if i == maxSuggestionExamples || i < len(f.Examples) && f.Examples[i] == path {
+		return
+	}
+	f.Examples = append(f.Examples, "")
+	copy(f.Examples[i+1:], f.Examples[i:])
+	f.Examples[i] = path
+	if len(f.Examples) > maxSuggestionExamples {
+		f.Examples = f.Examples[:maxSuggestionExamples]
+	}
+}
+
+// SuggestFilters analyzes captured transactions for traffic that rarely belongs in API
+// documentation: health checks, metrics, static assets, and CORS preflight OPTIONS requests.
+// Only rules that match at least one transaction are suggested.
+func SuggestFilters(transactions []proxy.APITransaction) []FilterSuggestion {
+	suggester := NewFilterSuggester()
+	for _, tx := range transactions {
+		suggester.Add(tx)
+	}
+	return suggester.Suggestions()
+}
@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestFilters(t *testing.T) {
+	var transactions []proxy.APITransaction
+	for _, request := range []struct{ method, path string }{
+		{"GET", "/users"},
+		{"GET", "/users/1"},
+		{"GET", "/healthz"},
+		{"GET", "/healthz"},
+		{"GET", "/api/health/ready"},
+		{"GET", "/metrics"},
+		{"GET", "/static/app.js"},
+		{"GET", "/favicon.ico"},
+		{"OPTIONS", "/users"},
+		{"GET", "/reports/healthy-customers"},
+	} {
+		transactions = append(transactions, createTestTransaction(request.method, request.path, nil, nil, 200))
+	}
+
+	suggestions := SuggestFilters(transactions)
+	require.Len(t, suggestions, 4)
+
+	assert.Equal(t, FilterExcludePath, suggestions[0].Kind)
+	assert.Equal(t, "health checks", suggestions[0].Reason)
+	assert.Equal(t, 3, suggestions[0].Matches)
+	assert.Equal(t, []string{"/api/health/ready", "/healthz"}, suggestions[0].Examples)
+
+	assert.Equal(t, "metrics and debug endpoints", suggestions[1].Reason)
+	assert.Equal(t, 1, suggestions[1].Matches)
+
+	assert.Equal(t, "static assets", suggestions[2].Reason)
+	assert.Equal(t, []string{"/favicon.ico", "/static/app.js"}, suggestions[2].Examples)
+
+	assert.Equal(t, FilterExcludeMethod, suggestions[3].Kind)
+	assert.Equal(t, "OPTIONS", suggestions[3].Value)
+	assert.Equal(t, 1, suggestions[3].Matches)
+
+	// The suggested patterns are valid --exclude-path values that keep the API paths
+	for _, suggestion := range suggestions[:3] {
+		re, err := regexp.Compile(suggestion.Value)
+		require.NoError(t, err)
+		assert.False(t, re.MatchString("/users/1"))
+		assert.False(t, re.MatchString("/reports/healthy-customers"))
+	}
+}
+
+func TestSuggestFiltersNothingToSuggest(t *testing.T) {
+	transactions := []proxy.APITransaction{
+		createTestTransaction("GET", "/users", nil, nil, 200),
+		createTestTransaction("POST", "/users", nil, nil, 201),
+	}
+	assert.Empty(t, SuggestFilters(transactions))
+}
+
+func TestFilterSuggesterExamples(t *testing.T) {
+	// Streamed transactions keep only the first distinct paths in sorted order
+	suggester := NewFilterSuggester()
+	for _, path := range []string{"/svc-d/health", "/svc-b/health", "/svc-d/health", "/svc-e/health", "/svc-a/health", "/svc-c/health"} {
+		suggester.Add(createTestTransaction("GET", path, nil, nil, 200))
+	}
+
+	suggestions := suggester.Suggestions()
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, 6, suggestions[0].Matches)
+	assert.Equal(t, []string{"/svc-a/health", "/svc-b/health", "/svc-c/health"}, suggestions[0].Examples)
+}
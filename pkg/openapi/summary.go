@@ -0,0 +1,37 @@
+package openapi
+
+import "fmt"
+
+// SpecSummary is an overview of a generated document, e.g. to preview the effect of filters
+type SpecSummary struct {
+	Endpoints  int      // documented paths
+	Operations int      // operations of all paths
+	Schemas    int      // component schemas
+	Warnings   []string // validation problems and lint issues of warning or error severity
+}
+
+// SummarizeSpec counts the endpoints, operations, and schemas of a document and collects the
+// problems that validation and the default lint rules report for it
+func SummarizeSpec(spec *OpenAPISpec) SpecSummary {
+	var summary SpecSummary
+	if spec.Paths != nil {
+		summary.Endpoints = spec.Paths.Len()
+		for _, item := range spec.Paths.Map() {
+			summary.Operations += len(item.Operations())
+		}
+	}
+	if spec.Components != nil {
+		summary.Schemas = len(spec.Components.Schemas)
+	}
+
+	if err := ValidateSpec(spec); err != nil {
+		summary.Warnings = append(summary.Warnings, err.Error())
+	}
+	issues, _ := LintSpec(spec, nil)
+	for _, issue := range issues {
+		if issue.Severity == LintError || issue.Severity == LintWarning {
+			summary.Warnings = append(summary.Warnings, fmt.Sprintf("%s: %s (%s)", issue.Location, issue.Message, issue.Rule))
+		}
+	}
+	return summary
+}
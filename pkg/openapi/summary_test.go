@@ -0,0 +1,45 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeSpec(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(createTestTransaction("GET", "/users/1", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200))
+	generator.AddTransaction(createTestTransaction("PUT", "/users/1", []byte(`{"name":"__string__"}`), []byte(`{"id":"__integer__","name":"__string__"}`), 200))
+	generator.AddTransaction(createTestTransaction("POST", "/users", []byte(`{"name":"__string__"}`), []byte(`{"id":"__integer__","name":"__string__"}`), 201))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	summary := SummarizeSpec(spec)
+	assert.Equal(t, 2, summary.Endpoints)
+	assert.Equal(t, 3, summary.Operations)
+	assert.Equal(t, len(spec.Components.Schemas), summary.Schemas)
+
+	// Issues of info severity are not warnings
+	issues, err := LintSpec(spec, nil)
+	require.NoError(t, err)
+	expected := 0
+	for _, issue := range issues {
+		if issue.Severity != LintInfo {
+			expected++
+		}
+	}
+	assert.Len(t, summary.Warnings, expected)
+}
+
+func TestSummarizeSpecEmpty(t *testing.T) {
+	spec, err := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"}).GenerateSpec()
+	require.NoError(t, err)
+
+	summary := SummarizeSpec(spec)
+	assert.Zero(t, summary.Endpoints)
+	assert.Zero(t, summary.Operations)
+	require.NotEmpty(t, summary.Warnings)
+	assert.Contains(t, summary.Warnings[0], "no paths were documented")
+}
@@ -0,0 +1,154 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// OpenAPITagGroup is a named section of tags, emitted as x-tagGroups for renderers like Redoc
+type OpenAPITagGroup struct {
+	Name string
+	Tags []string
+}
+
+// otherTagsGroup collects tags that no configured group lists, since renderers hide them otherwise
+const otherTagsGroup = "Other"
+
+// untaggedTag groups operations without tags in exported documentation
+const untaggedTag = "default"
+
+// taggedOperation is an operation with the path and method it is served at
+type taggedOperation struct {
+	path   string
+	method string
+	op     *openapi3.Operation
+}
+
+// addTags declares every tag used by an operation in the top-level tags list, in sorted order,
+// with its configured description, and adds the configured tag groups
+func (g *OpenAPIGenerator) addTags(doc *OpenAPISpec) {
+	used := make(map[string]bool)
+	for _, pathItem := range doc.Paths.Map() {
+		for _, method := range operationMethods {
+			if op := operationFor(pathItem, method); op != nil {
+				for _, tag := range op.Tags {
+					used[tag] = true
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		description, ok := g.config.TagDescriptions[name]
+		if !ok {
+			description = fmt.Sprintf("Operations related to %s", name)
+		}
+		doc.Tags = append(doc.Tags, &openapi3.Tag{
+			Name:        name,
+			Description: description,
+		})
+	}
+
+	if groups := tagGroups(g.config.TagGroups, names); len(groups) > 0 {
+		if doc.Extensions == nil {
+			doc.Extensions = make(map[string]interface{})
+		}
+		doc.Extensions["x-tagGroups"] = groups
+	}
+}
+
+// tagGroups builds the x-tagGroups value from the configured groups, keeping only declared tags
+// and putting ungrouped tags into an extra group
+func tagGroups(configured []OpenAPITagGroup, declared []string) []map[string]interface{} {
+	if len(configured) == 0 {
+		return nil
+	}
+
+	isDeclared := make(map[string]bool)
+	for _, name := range declared {
+		isDeclared[name] = true
+	}
+
+	var groups []map[string]interface{}
+	grouped := make(map[string]bool)
+	for _, group := range configured {
+		tags := []string{}
+		for _, tag := range group.Tags {
+			if isDeclared[tag] && !grouped[tag] {
+				tags = append(tags, tag)
+				grouped[tag] = true
+			}
+		}
+		if len(tags) > 0 {
+			groups = append(groups, map[string]interface{}{"name": group.Name, "tags": tags})
+		}
+	}
+
+	var other []string
+	for _, name := range declared {
+		if !grouped[name] {
+			other = append(other, name)
+		}
+	}
+	if len(other) > 0 {
+		groups = append(groups, map[string]interface{}{"name": otherTagsGroup, "tags": other})
+	}
+
+	return groups
+}
+
+// operationsByTag groups the operations of a document by their first tag, in path order, or by
+// their x-call-frequency, busiest first, when they have one
+func operationsByTag(doc *OpenAPISpec) map[string][]taggedOperation {
+	tags := make(map[string][]taggedOperation)
+	if doc.Paths == nil {
+		return tags
+	}
+
+	paths := doc.Paths.InMatchingOrder()
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathItem := doc.Paths.Value(path)
+		for _, method := range operationMethods {
+			op := operationFor(pathItem, method)
+			if op == nil {
+				continue
+			}
+			tag := untaggedTag
+			if len(op.Tags) > 0 {
+				tag = op.Tags[0]
+			}
+			tags[tag] = append(tags[tag], taggedOperation{path: path, method: method, op: op})
+		}
+	}
+	for _, operations := range tags {
+		sort.SliceStable(operations, func(i, j int) bool {
+			return callCount(operations[i].op) > callCount(operations[j].op)
+		})
+	}
+	return tags
+}
+
+// sortedTags returns the tags of grouped operations in sorted order, or by the calls of their
+// operations, busiest first, when they have an x-call-frequency
+func sortedTags(tags map[string][]taggedOperation) []string {
+	names := make([]string, 0, len(tags))
+	calls := make(map[string]int, len(tags))
+	for name, operations := range tags {
+		names = append(names, name)
+		for _, operation := range operations {
+			calls[name] += callCount(operation.op)
+		}
+	}
+	sort.Strings(names)
+	sort.SliceStable(names, func(i, j int) bool { return calls[names[i]] > calls[names[j]] })
+	return names
+}
@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSpecTags(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		TagDescriptions: map[string]string{"Users": "Manage user accounts"},
+		TagGroups: []OpenAPITagGroup{
+			{Name: "Accounts", Tags: []string{"Users", "Auth", "Unused"}},
+		},
+	})
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, nil, 200))
+	generator.AddTransaction(createTestTransaction("POST", "/auth/login", nil, nil, 200))
+	generator.AddTransaction(createTestTransaction("GET", "/orders", nil, nil, 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+
+	require.Len(t, spec.Tags, 3)
+	assert.Equal(t, "Auth", spec.Tags[0].Name)
+	assert.Equal(t, "Orders", spec.Tags[1].Name)
+	assert.Equal(t, "Users", spec.Tags[2].Name)
+	assert.Equal(t, "Manage user accounts", spec.Tags.Get("Users").Description)
+	assert.Equal(t, "Operations related to Orders", spec.Tags.Get("Orders").Description)
+
+	// Every operation tag is declared
+	for _, pathItem := range spec.Paths.Map() {
+		for _, op := range pathItem.Operations() {
+			for _, tag := range op.Tags {
+				assert.NotNil(t, spec.Tags.Get(tag), tag)
+			}
+		}
+	}
+
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "Accounts", "tags": []string{"Users", "Auth"}},
+		{"name": "Other", "tags": []string{"Orders"}},
+	}, spec.Extensions["x-tagGroups"])
+}
+
+func TestGenerateSpecWithoutTagGroups(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{})
+	generator.AddTransaction(createTestTransaction("GET", "/users", nil, nil, 200))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+	assert.NotContains(t, spec.Extensions, "x-tagGroups")
+}
@@ -0,0 +1,19 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ValidateSpec checks a generated document against the OpenAPI specification, so that broken
+// output is caught at generation time rather than by downstream tools
+func ValidateSpec(spec *OpenAPISpec) error {
+	if spec.Paths == nil || spec.Paths.Len() == 0 {
+		return errors.New("invalid OpenAPI document: no paths were documented")
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		return fmt.Errorf("invalid OpenAPI document: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSpec(t *testing.T) {
+	generator := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"})
+	generator.AddTransaction(createTestTransaction("GET", "/users/1", nil, []byte(`{"id":"__integer__","name":"__string__"}`), 200))
+	generator.AddTransaction(createTestTransaction("POST", "/users", []byte(`{"name":"__string__"}`), []byte(`{"id":"__integer__","name":"__string__"}`), 201))
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+	assert.NoError(t, ValidateSpec(spec))
+
+	// A path parameter missing from the operation is invalid
+	spec.Paths.Find("/users/{userId}").Get.Parameters = openapi3.Parameters{}
+	assert.Error(t, ValidateSpec(spec))
+
+	empty, err := NewOpenAPIGenerator(OpenAPIConfig{Title: "Test API", Version: "1.0.0"}).GenerateSpec()
+	require.NoError(t, err)
+	assert.Error(t, ValidateSpec(empty))
+}
@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// versionSegmentPattern matches path segments naming an API version, e.g. v1 or v2.1
+var versionSegmentPattern = regexp.MustCompile(`^[vV]\d+(\.\d+)*$`)
+
+// maxVersionSegment is how deep in a path a version segment is looked for, e.g. /api/rest/v1
+const maxVersionSegment = 3
+
+// APIVersion is the captured traffic of one API version
+type APIVersion struct {
+	Name         string   // version segment, e.g. v1
+	Prefixes     []string // path prefixes ending in the version segment, e.g. /api/v1
+	Transactions []proxy.APITransaction
+}
+
+// SplitByVersion groups transactions by the version segment of their paths, e.g. /v1/users and
+// /api/v2/users, so that every version can be documented on its own with its prefixes folded into
+// the server URLs. Transactions of unversioned paths, such as /health, are shared by every
+// version. Versions are ordered by number; nil is returned when no path has a version segment.
+func SplitByVersion(transactions []proxy.APITransaction) []APIVersion {
+	byName := make(map[string]*APIVersion)
+	var shared []proxy.APITransaction
+	for _, tx := range transactions {
+		name, prefix := versionPrefix(tx.Request.Path)
+		if name == "" {
+			shared = append(shared, tx)
+			continue
+		}
+		version, ok := byName[name]
+		if !ok {
+			version = &APIVersion{Name: name}
+			byName[name] = version
+		}
+		if !containsString(version.Prefixes, prefix) {
+			version.Prefixes = append(version.Prefixes, prefix)
+		}
+		version.Transactions = append(version.Transactions, tx)
+	}
+
+	versions := make([]APIVersion, 0, len(byName))
+	for _, version := range byName {
+		sort.Strings(version.Prefixes)
+		version.Transactions = append(version.Transactions, shared...)
+		versions = append(versions, *version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versionLess(versions[i].Name, versions[j].Name)
+	})
+	if len(versions) == 0 {
+		return nil
+	}
+	return versions
+}
+
+// versionPrefix returns the lower-cased version segment of a path and the prefix ending in it,
+// e.g. v2 and /api/v2 for /api/v2/users, or empty strings for unversioned paths
+func versionPrefix(path string) (string, string) {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, segment := range segments {
+		if i >= maxVersionSegment {
+			break
+		}
+		if versionSegmentPattern.MatchString(segment) {
+			return strings.ToLower(segment), "/" + strings.Join(segments[:i+1], "/")
+		}
+	}
+	return "", ""
+}
+
+// versionLess orders version names by their numbers, so that v2 comes before v10
+func versionLess(a, b string) bool {
+	aParts := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bParts := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, _ := strconv.Atoi(aParts[i])
+		bNum, _ := strconv.Atoi(bParts[i])
+		if aNum != bNum {
+			return aNum < bNum
+		}
+	}
+	return len(aParts) < len(bParts)
+}
+
+// containsString reports whether a list contains a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
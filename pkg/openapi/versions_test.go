@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitByVersion(t *testing.T) {
+	transactions := []proxy.APITransaction{
+		createTestTransaction("GET", "/v1/users", nil, []byte(`[{"id":"__integer__"}]`), 200),
+		createTestTransaction("GET", "/api/v2/users", nil, []byte(`[{"id":"__string__"}]`), 200),
+		createTestTransaction("GET", "/v10/users", nil, []byte(`[{"id":"__string__"}]`), 200),
+		createTestTransaction("GET", "/V2/orders", nil, []byte(`[{"id":"__string__"}]`), 200),
+		createTestTransaction("GET", "/health", nil, []byte(`{"ok":"__boolean__"}`), 200),
+	}
+
+	versions := SplitByVersion(transactions)
+	require.Len(t, versions, 3)
+
+	var names []string
+	for _, version := range versions {
+		names = append(names, version.Name)
+	}
+	assert.Equal(t, []string{"v1", "v2", "v10"}, names)
+
+	assert.Equal(t, []string{"/v1"}, versions[0].Prefixes)
+	assert.Equal(t, []string{"/V2", "/api/v2"}, versions[1].Prefixes)
+
+	// Unversioned paths are shared by every version
+	var paths []string
+	for _, tx := range versions[1].Transactions {
+		paths = append(paths, tx.Request.Path)
+	}
+	assert.Equal(t, []string{"/api/v2/users", "/V2/orders", "/health"}, paths)
+
+	assert.Nil(t, SplitByVersion(transactions[4:5]))
+}
+
+func TestGenerateSpecVersionPrefix(t *testing.T) {
+	versions := SplitByVersion([]proxy.APITransaction{
+		createTestTransaction("GET", "/api/v2/users", nil, []byte(`[{"id":"__string__"}]`), 200),
+	})
+	require.Len(t, versions, 1)
+
+	generator := NewOpenAPIGenerator(OpenAPIConfig{
+		Title:         "Test API",
+		Version:       "1.0.0",
+		Servers:       []OpenAPIServer{{URL: "https://api.example.com"}},
+		StripPrefixes: versions[0].Prefixes,
+	})
+	for _, tx := range versions[0].Transactions {
+		generator.AddTransaction(tx)
+	}
+
+	spec, err := generator.GenerateSpec()
+	require.NoError(t, err)
+	assert.NotNil(t, spec.Paths.Find("/users"))
+	assert.Equal(t, "https://api.example.com/api/v2", spec.Servers[0].URL)
+}
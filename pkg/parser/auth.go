@@ -33,8 +33,10 @@ func NewAuthDetector() *AuthDetector {
 func (d *AuthDetector) AnalyzeTransaction(req *http.Request, resp *http.Response) {
 	d.analyzeHeaders(req.Header)
 	d.analyzeQueryParams(req.URL.Query())
+	d.analyzeCookies(req.Cookies())
 	if resp != nil {
 		d.analyzeResponseHeaders(resp.Header)
+		d.analyzeSetCookies(resp.Cookies())
 	}
 }
 
@@ -85,6 +87,64 @@ func (d *AuthDetector) analyzeQueryParams(params map[string][]string) {
 	}
 }
 
+// analyzeCookies analyzes request cookies for session authentication
+func (d *AuthDetector) analyzeCookies(cookies []*http.Cookie) {
+	for _, cookie := range cookies {
+		if IsSessionCookie(cookie.Name) {
+			d.addAuthScheme(AuthScheme{
+				Type:        "apiKey",
+				Description: fmt.Sprintf("Session authentication via %s cookie", cookie.Name),
+				In:          "cookie",
+				Name:        cookie.Name,
+			})
+		}
+	}
+}
+
+// analyzeSetCookies analyzes response cookies for session cookies issued by the server
+func (d *AuthDetector) analyzeSetCookies(cookies []*http.Cookie) {
+	for _, cookie := range cookies {
+		// An HttpOnly cookie with a session-like name is almost always a login session
+		if cookie.HttpOnly && IsSessionCookie(cookie.Name) {
+			d.addAuthScheme(AuthScheme{
+				Type:        "apiKey",
+				Description: fmt.Sprintf("Session authentication via %s cookie", cookie.Name),
+				In:          "cookie",
+				Name:        cookie.Name,
+			})
+		}
+	}
+}
+
+// IsSessionCookie checks if a cookie name looks like it carries a session or auth token
+func IsSessionCookie(name string) bool {
+	lower := strings.ToLower(name)
+
+	// CSRF tokens accompany sessions but don't authenticate on their own
+	if strings.Contains(lower, "csrf") || strings.Contains(lower, "xsrf") {
+		return false
+	}
+
+	// Well-known framework session cookies
+	knownCookies := []string{
+		"jsessionid", "phpsessid", "asp.net_sessionid", "connect.sid",
+		"laravel_session", "_session_id", "sessionid", "sid",
+	}
+	if contains(knownCookies, lower) {
+		return true
+	}
+
+	// Generic auth-related name fragments
+	fragments := []string{"session", "sess", "auth", "token", "jwt"}
+	for _, fragment := range fragments {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // analyzeResponseHeaders analyzes response headers for authentication information
 func (d *AuthDetector) analyzeResponseHeaders(headers http.Header) {
 	// Check WWW-Authenticate header
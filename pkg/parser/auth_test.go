@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSessionCookie(t *testing.T) {
+	tests := []struct {
+		name     string
+		cookie   string
+		expected bool
+	}{
+		{name: "java session", cookie: "JSESSIONID", expected: true},
+		{name: "express session", cookie: "connect.sid", expected: true},
+		{name: "custom session", cookie: "app_session", expected: true},
+		{name: "auth token", cookie: "auth_token", expected: true},
+		{name: "csrf token", cookie: "csrftoken", expected: false},
+		{name: "preference", cookie: "theme", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsSessionCookie(tt.cookie))
+		})
+	}
+}
+
+func TestAnalyzeTransactionCookies(t *testing.T) {
+	detector := NewAuthDetector()
+
+	req := &http.Request{
+		Header: http.Header{"Cookie": []string{"session_id=__redacted__; theme=__redacted__"}},
+		URL:    &url.URL{Path: "/profile"},
+	}
+	resp := &http.Response{
+		Header: http.Header{"Set-Cookie": []string{"refresh_token=__redacted__; Path=/; HttpOnly"}},
+	}
+
+	detector.AnalyzeTransaction(req, resp)
+
+	schemes := detector.GetAuthSchemes()
+	assert.Len(t, schemes, 2)
+	for _, scheme := range schemes {
+		assert.Equal(t, "apiKey", scheme.Type)
+		assert.Equal(t, "cookie", scheme.In)
+		assert.Contains(t, []string{"session_id", "refresh_token"}, scheme.Name)
+	}
+}
@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// minConstraintSamples is how many literal values a field needs before constraints are inferred
+const minConstraintSamples = 3
+
+// InferConstraints walks a schema alongside the body samples it was built from and adds the
+// observed value constraints: minimum/maximum for numbers, minLength/maxLength for strings,
+// and a pattern for obviously patterned strings such as SKU codes. Sanitized placeholders
+// are not literal values and are ignored.
+func InferConstraints(schema Schema, samples []interface{}) Schema {
+	return walkSchemaSamples(schema, samples, func(_ string, schema *Schema, samples []interface{}) {
+		switch schema.Type {
+		case "integer", "number":
+			if min, max, _, ok := numericRange(samples); ok {
+				schema.Minimum = &min
+				schema.Maximum = &max
+			}
+		case "string":
+			inferStringConstraints(schema, samples)
+		}
+	})
+}
+
+// numericRange returns the minimum and maximum of the literal numbers among the samples,
+// and whether all of them are integers. It reports false when there are too few numbers.
+func numericRange(samples []interface{}) (float64, float64, bool, bool) {
+	var min, max float64
+	allIntegers := true
+	count := 0
+
+	for _, sample := range samples {
+		num, ok := sample.(float64)
+		if !ok {
+			continue
+		}
+		if count == 0 || num < min {
+			min = num
+		}
+		if count == 0 || num > max {
+			max = num
+		}
+		allIntegers = allIntegers && num == float64(int64(num))
+		count++
+	}
+
+	return min, max, allIntegers, count >= minConstraintSamples
+}
+
+// inferStringConstraints sets the length bounds of a string schema and, for values that
+// share a shape, a pattern
+func inferStringConstraints(schema *Schema, samples []interface{}) {
+	var values []string
+	for _, sample := range samples {
+		if str, ok := sample.(string); ok && !isPlaceholder(str) {
+			values = append(values, str)
+		}
+	}
+	if len(values) < minConstraintSamples {
+		return
+	}
+
+	minLength, maxLength := len(values[0]), len(values[0])
+	for _, value := range values[1:] {
+		if len(value) < minLength {
+			minLength = len(value)
+		}
+		if len(value) > maxLength {
+			maxLength = len(value)
+		}
+	}
+	schema.MinLength = &minLength
+	schema.MaxLength = &maxLength
+
+	// Well-known formats and enums already describe the values better than a pattern
+	if schema.Format == "" && len(schema.Enum) == 0 {
+		schema.Pattern = inferPattern(values)
+	}
+}
+
+// isPlaceholder checks if a value is a sanitization placeholder such as __string__
+func isPlaceholder(value string) bool {
+	return len(value) > 4 && strings.HasPrefix(value, "__") && strings.HasSuffix(value, "__")
+}
+
+// shapeRun is a run of characters of one class within a value, e.g. three upper-case letters
+type shapeRun struct {
+	class   string // regex character class, or a quoted literal for punctuation
+	literal bool
+	min     int
+	max     int
+}
+
+// inferPattern derives a regex from values that share a shape, e.g. SKU-1234 and ABC-0042
+// give ^[A-Z]{3}-\d{4}$. It returns an empty string when the values have no common shape
+// or the shape is too plain (a single word or number) to be worth a pattern.
+func inferPattern(values []string) string {
+	var shape []shapeRun
+	distinct := make(map[string]bool)
+
+	for i, value := range values {
+		distinct[value] = true
+		runs := valueShape(value)
+		if runs == nil {
+			return ""
+		}
+
+		if i == 0 {
+			shape = runs
+			continue
+		}
+		if len(runs) != len(shape) {
+			return ""
+		}
+		for j, run := range runs {
+			if run.class != shape[j].class {
+				return ""
+			}
+			if run.min < shape[j].min {
+				shape[j].min = run.min
+			}
+			if run.max > shape[j].max {
+				shape[j].max = run.max
+			}
+		}
+	}
+
+	// Only shapes mixing words with digits or punctuation are obviously patterned
+	patterned := false
+	for _, run := range shape {
+		patterned = patterned || run.literal || run.class == `\d`
+	}
+	if len(shape) < 2 || len(distinct) < 2 || !patterned {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("^")
+	for _, run := range shape {
+		builder.WriteString(run.class)
+		switch {
+		case run.min == run.max && run.min == 1:
+		case run.min == run.max:
+			builder.WriteString(fmt.Sprintf("{%d}", run.min))
+		default:
+			builder.WriteString(fmt.Sprintf("{%d,%d}", run.min, run.max))
+		}
+	}
+	builder.WriteString("$")
+	return builder.String()
+}
+
+// valueShape splits a value into runs of upper-case letters, lower-case letters, digits,
+// and literal punctuation. Values with whitespace or other characters are free text and
+// have no shape.
+func valueShape(value string) []shapeRun {
+	var runs []shapeRun
+	for _, r := range value {
+		run := shapeRun{min: 1, max: 1}
+		switch {
+		case r >= 'A' && r <= 'Z':
+			run.class = "[A-Z]"
+		case r >= 'a' && r <= 'z':
+			run.class = "[a-z]"
+		case r >= '0' && r <= '9':
+			run.class = `\d`
+		case strings.ContainsRune("-_./:#", r):
+			run.class = regexp.QuoteMeta(string(r))
+			run.literal = true
+		default:
+			return nil
+		}
+
+		if last := len(runs) - 1; last >= 0 && !run.literal && runs[last].class == run.class {
+			runs[last].min++
+			runs[last].max++
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected string
+	}{
+		{name: "sku codes", values: []string{"SKU-1234", "ABC-0042", "XYZ-9999"}, expected: `^[A-Z]{3}-\d{4}$`},
+		{name: "varying run lengths", values: []string{"ord_12", "ord_345", "ord_6"}, expected: `^[a-z]{3}_\d{1,3}$`},
+		{name: "plain words", values: []string{"alpha", "beta", "gamma"}, expected: ""},
+		{name: "capitalized names", values: []string{"John", "Alice", "Bob"}, expected: ""},
+		{name: "free text", values: []string{"hello world 1", "foo bar 2", "baz qux 3"}, expected: ""},
+		{name: "different shapes", values: []string{"SKU-1234", "1234-SKU", "ABC-0042"}, expected: ""},
+		{name: "identical values", values: []string{"A-1", "A-1", "A-1"}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, inferPattern(tt.values))
+		})
+	}
+}
+
+func TestInferConstraints(t *testing.T) {
+	schema := Schema{
+		Type: "object",
+		Properties: map[string]Schema{
+			"price": {Type: "number"},
+			"sku":   {Type: "string"},
+			"email": {Type: "string", Format: "email"},
+			"count": {Type: "integer"},
+		},
+	}
+	samples := []interface{}{
+		map[string]interface{}{"price": 9.5, "sku": "SKU-0001", "email": "a@example.com", "count": "__integer__"},
+		map[string]interface{}{"price": 20.0, "sku": "SKU-0002", "email": "bob@example.com", "count": "__integer__"},
+		map[string]interface{}{"price": 3.25, "sku": "ABC-1000", "email": "c@example.org", "count": "__integer__"},
+	}
+
+	result := InferConstraints(schema, samples)
+
+	price := result.Properties["price"]
+	require.NotNil(t, price.Minimum)
+	require.NotNil(t, price.Maximum)
+	assert.Equal(t, 3.25, *price.Minimum)
+	assert.Equal(t, 20.0, *price.Maximum)
+
+	sku := result.Properties["sku"]
+	require.NotNil(t, sku.MinLength)
+	assert.Equal(t, 8, *sku.MinLength)
+	assert.Equal(t, 8, *sku.MaxLength)
+	assert.Equal(t, `^[A-Z]{3}-\d{4}$`, sku.Pattern)
+
+	email := result.Properties["email"]
+	require.NotNil(t, email.MaxLength)
+	assert.Equal(t, 15, *email.MaxLength)
+	assert.Empty(t, email.Pattern)
+
+	// Placeholders are not literal values
+	count := result.Properties["count"]
+	assert.Nil(t, count.Minimum)
+	assert.Nil(t, count.Maximum)
+
+	// The input schema is not modified
+	assert.Nil(t, schema.Properties["price"].Minimum)
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeprecationInfo describes the deprecation signals of a response: the Deprecation header
+// (RFC 9745), the Sunset header (RFC 8594), a rel="deprecation" link, and Warning: 299
+type DeprecationInfo struct {
+	Since   string // RFC 3339 time the resource was deprecated, if the Deprecation header had a date
+	Sunset  string // RFC 3339 time the resource stops responding
+	Link    string // URL of the documentation of the deprecation
+	Warning string // text of a "299 Miscellaneous persistent warning" Warning header
+}
+
+// DetectDeprecation detects the deprecation signals of a response from its headers. It
+// returns nil when the response doesn't signal a deprecation.
+func DetectDeprecation(headers http.Header) *DeprecationInfo {
+	info := &DeprecationInfo{}
+	deprecated := false
+
+	if value := strings.TrimSpace(headers.Get("Deprecation")); value != "" && !strings.EqualFold(value, "false") {
+		deprecated = true
+		info.Since = parseDeprecationDate(value)
+	}
+
+	if value := strings.TrimSpace(headers.Get("Sunset")); value != "" {
+		deprecated = true
+		if sunset, err := http.ParseTime(value); err == nil {
+			info.Sunset = sunset.UTC().Format(time.RFC3339)
+		} else {
+			info.Sunset = value
+		}
+	}
+
+	for _, warning := range headers.Values("Warning") {
+		if text, ok := persistentWarning(warning); ok {
+			deprecated = true
+			info.Warning = text
+			break
+		}
+	}
+
+	// A deprecation link only documents a deprecation signaled by the other headers
+	if deprecated {
+		info.Link = linkWithRel(headers.Values("Link"), "deprecation")
+		return info
+	}
+	return nil
+}
+
+// Merge fills in the signals of another response that this one didn't carry
+func (d *DeprecationInfo) Merge(other *DeprecationInfo) {
+	if other == nil {
+		return
+	}
+	if d.Since == "" {
+		d.Since = other.Since
+	}
+	if d.Sunset == "" {
+		d.Sunset = other.Sunset
+	}
+	if d.Link == "" {
+		d.Link = other.Link
+	}
+	if d.Warning == "" {
+		d.Warning = other.Warning
+	}
+}
+
+// parseDeprecationDate reads the date of a Deprecation header, either a structured field date
+// like @1688169599 (RFC 9745) or an HTTP date from earlier drafts. It returns an empty string
+// for "true" and other values without a date.
+func parseDeprecationDate(value string) string {
+	if strings.HasPrefix(value, "@") {
+		if seconds, err := strconv.ParseInt(value[1:], 10, 64); err == nil {
+			return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+		}
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date.UTC().Format(time.RFC3339)
+	}
+	return ""
+}
+
+// persistentWarning returns the text of a Warning header with code 299, which servers use to
+// announce deprecations, e.g. 299 - "Deprecated API"
+func persistentWarning(value string) (string, bool) {
+	if !strings.HasPrefix(strings.TrimSpace(value), "299 ") {
+		return "", false
+	}
+
+	start := strings.Index(value, `"`)
+	if start < 0 {
+		return "", true
+	}
+	end := strings.Index(value[start+1:], `"`)
+	if end < 0 {
+		return value[start+1:], true
+	}
+	return value[start+1 : start+1+end], true
+}
+
+// linkWithRel returns the target of the first Link header entry with a relation type
+func linkWithRel(values []string, rel string) string {
+	for _, value := range values {
+		for _, entry := range strings.Split(value, ",") {
+			parts := strings.Split(entry, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				name, paramValue, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if strings.EqualFold(name, "rel") && strings.EqualFold(strings.Trim(paramValue, `"`), rel) {
+					return strings.Trim(target, "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectDeprecation(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  http.Header
+		expected *DeprecationInfo
+	}{
+		{
+			name:     "no signals",
+			headers:  http.Header{"Content-Type": {"application/json"}},
+			expected: nil,
+		},
+		{
+			name:     "deprecation false",
+			headers:  http.Header{"Deprecation": {"false"}},
+			expected: nil,
+		},
+		{
+			name:     "deprecation true",
+			headers:  http.Header{"Deprecation": {"true"}},
+			expected: &DeprecationInfo{},
+		},
+		{
+			name: "structured date with sunset and link",
+			headers: http.Header{
+				"Deprecation": {"@1688169599"},
+				"Sunset":      {"Wed, 11 Nov 2026 23:59:59 GMT"},
+				"Link":        {`<https://example.com/docs/v2>; rel="successor-version", <https://example.com/deprecation>; rel="deprecation"`},
+			},
+			expected: &DeprecationInfo{
+				Since:  "2023-06-30T23:59:59Z",
+				Sunset: "2026-11-11T23:59:59Z",
+				Link:   "https://example.com/deprecation",
+			},
+		},
+		{
+			name:     "sunset only",
+			headers:  http.Header{"Sunset": {"Wed, 11 Nov 2026 23:59:59 GMT"}},
+			expected: &DeprecationInfo{Sunset: "2026-11-11T23:59:59Z"},
+		},
+		{
+			name:     "persistent warning",
+			headers:  http.Header{"Warning": {`199 - "Stale"`, `299 - "Deprecated API, use /v2/users"`}},
+			expected: &DeprecationInfo{Warning: "Deprecated API, use /v2/users"},
+		},
+		{
+			name:     "link without other signals",
+			headers:  http.Header{"Link": {`<https://example.com/deprecation>; rel="deprecation"`}},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectDeprecation(tt.headers))
+		})
+	}
+}
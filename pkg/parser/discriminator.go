@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// mergeVariants merges object schemas that carry a literal discriminator value (e.g. "type")
+// whose values correlate with different property sets into a oneOf with a discriminator.
+// It returns false when the schemas don't look polymorphic.
+func mergeVariants(schemas []Schema) (Schema, bool) {
+	var flat []Schema
+	for _, schema := range schemas {
+		flat = append(flat, schemaVariants(schema)...)
+	}
+
+	field, groups := detectDiscriminator(flat)
+	if field == "" {
+		return Schema{}, false
+	}
+
+	values := make([]string, 0, len(groups))
+	for value := range groups {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	result := Schema{
+		Discriminator: &Discriminator{
+			PropertyName: field,
+			Mapping:      make(map[string]string),
+		},
+	}
+	for _, value := range values {
+		variant := mergeFlattened(groups[value])
+
+		// Copy the properties so that the input schemas are left untouched
+		properties := make(map[string]Schema, len(variant.Properties))
+		for name, prop := range variant.Properties {
+			properties[name] = prop
+		}
+		prop := properties[field]
+		prop.Enum = []interface{}{value}
+		prop.Example = value
+		properties[field] = prop
+		variant.Properties = properties
+		variant.Required = mergeRequired(variant.Required, []string{field})
+		variant.Example = nil
+
+		result.OneOf = append(result.OneOf, variant)
+		result.Discriminator.Mapping[value] = PascalCase(value)
+	}
+
+	return result, true
+}
+
+// detectDiscriminator finds a property that every object schema has with a literal value,
+// where different values come with different property sets. It returns the property name and
+// the schemas grouped by value, or an empty name if there is no such property.
+func detectDiscriminator(schemas []Schema) (string, map[string][]Schema) {
+	if len(schemas) < 2 {
+		return "", nil
+	}
+	for _, schema := range schemas {
+		if schema.Type != "object" {
+			return "", nil
+		}
+	}
+
+	for _, field := range proxy.DiscriminatorFields {
+		groups := make(map[string][]Schema)
+		for _, schema := range schemas {
+			value, ok := literalValue(schema.Properties[field])
+			if !ok {
+				groups = nil
+				break
+			}
+			groups[value] = append(groups[value], schema)
+		}
+		if len(groups) < 2 {
+			continue
+		}
+
+		// The variants must differ in shape; a field like "type" on otherwise identical
+		// objects is just an enum
+		shapes := make(map[string]bool)
+		for _, group := range groups {
+			shapes[propertySignature(group)] = true
+		}
+		if len(shapes) > 1 {
+			return field, groups
+		}
+	}
+
+	return "", nil
+}
+
+// literalValue returns the literal string example of a property, ignoring the generic
+// examples produced from sanitization placeholders
+func literalValue(prop Schema) (string, bool) {
+	value, ok := prop.Example.(string)
+	if !ok || prop.Type != "string" || value == "" || strings.HasPrefix(value, "__") {
+		return "", false
+	}
+	switch value {
+	case "string", "unknown", "redacted":
+		return "", false
+	}
+	return value, true
+}
+
+// propertySignature describes the union of property names of a group of object schemas
+func propertySignature(schemas []Schema) string {
+	names := make(map[string]bool)
+	for _, schema := range schemas {
+		for name := range schema.Properties {
+			names[name] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// schemaVariants returns the oneOf variants of a polymorphic schema, or the schema itself
+func schemaVariants(schema Schema) []Schema {
+	if schema.Discriminator != nil && len(schema.OneOf) > 0 {
+		return schema.OneOf
+	}
+	return []Schema{schema}
+}
+
+// mergeFlattened merges a list of non-polymorphic schemas into one
+func mergeFlattened(schemas []Schema) Schema {
+	result := schemas[0]
+	for _, schema := range schemas[1:] {
+		result = mergeSchema(result, schema)
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func objectSchema(props map[string]Schema) Schema {
+	return Schema{Type: "object", Properties: props}
+}
+
+func TestMergeSchemaListDiscriminator(t *testing.T) {
+	card := objectSchema(map[string]Schema{
+		"type":  {Type: "string", Example: "card"},
+		"last4": {Type: "string", Example: "string"},
+	})
+	bank := objectSchema(map[string]Schema{
+		"type":   {Type: "string", Example: "bank_account"},
+		"iban":   {Type: "string", Example: "string"},
+		"holder": {Type: "string", Example: "string"},
+	})
+
+	merged := MergeSchemaList([]Schema{card, bank, card})
+	require.NotNil(t, merged.Discriminator)
+	assert.Equal(t, "type", merged.Discriminator.PropertyName)
+	assert.Equal(t, map[string]string{"bank_account": "BankAccount", "card": "Card"}, merged.Discriminator.Mapping)
+	require.Len(t, merged.OneOf, 2)
+	assert.Contains(t, merged.OneOf[0].Properties, "iban")
+	assert.Equal(t, []interface{}{"bank_account"}, merged.OneOf[0].Properties["type"].Enum)
+	assert.Contains(t, merged.OneOf[1].Properties, "last4")
+	assert.Contains(t, merged.OneOf[1].Required, "type")
+
+	// Merging another variant into an existing oneOf extends it
+	wallet := objectSchema(map[string]Schema{
+		"type":     {Type: "string", Example: "wallet"},
+		"provider": {Type: "string", Example: "string"},
+	})
+	extended := mergeSchema(merged, wallet)
+	require.NotNil(t, extended.Discriminator)
+	assert.Len(t, extended.OneOf, 3)
+
+	// The input schemas are not modified
+	assert.Empty(t, card.Properties["type"].Enum)
+}
+
+func TestMergeSchemaListWithoutDiscriminator(t *testing.T) {
+	tests := []struct {
+		name    string
+		schemas []Schema
+	}{
+		{
+			name: "same shape",
+			schemas: []Schema{
+				objectSchema(map[string]Schema{"type": {Type: "string", Example: "admin"}, "name": {Type: "string"}}),
+				objectSchema(map[string]Schema{"type": {Type: "string", Example: "member"}, "name": {Type: "string"}}),
+			},
+		},
+		{
+			name: "placeholder values",
+			schemas: []Schema{
+				objectSchema(map[string]Schema{"type": {Type: "string", Example: "string"}, "a": {Type: "string"}}),
+				objectSchema(map[string]Schema{"type": {Type: "string", Example: "string"}, "b": {Type: "string"}}),
+			},
+		},
+		{
+			name: "missing discriminator",
+			schemas: []Schema{
+				objectSchema(map[string]Schema{"type": {Type: "string", Example: "card"}, "a": {Type: "string"}}),
+				objectSchema(map[string]Schema{"b": {Type: "string"}}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := MergeSchemaList(tt.schemas)
+			assert.Nil(t, merged.Discriminator)
+			assert.Empty(t, merged.OneOf)
+			assert.Equal(t, "object", merged.Type)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"mime"
+	"strings"
+)
+
+// ProblemJSONContentType is the RFC 7807 media type for error responses
+const ProblemJSONContentType = "application/problem+json"
+
+// errorMessageFields and errorCodeFields make up the common {"message", "code"} envelope
+var (
+	errorMessageFields = []string{"message", "error_message", "errorMessage", "detail", "error_description"}
+	errorCodeFields    = []string{"code", "error_code", "errorCode", "status", "statusCode", "status_code", "error"}
+)
+
+// IsProblemJSON checks whether a content type is application/problem+json
+func IsProblemJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && strings.EqualFold(mediaType, ProblemJSONContentType)
+}
+
+// IsErrorEnvelope checks whether a decoded response body has the shape of a common error
+// envelope: {"error": {...}}, {"errors": [...]}, {"message": ..., "code": ...}, or the RFC 7807
+// problem details fields
+func IsErrorEnvelope(body interface{}) bool {
+	obj, ok := body.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	switch obj["error"].(type) {
+	case map[string]interface{}, string:
+		return true
+	}
+	if errors, ok := obj["errors"].([]interface{}); ok && len(errors) > 0 {
+		return true
+	}
+
+	// RFC 7807 problem details served without the problem+json media type
+	if _, ok := obj["title"]; ok {
+		if hasAnyKey(obj, []string{"type", "status", "detail"}) {
+			return true
+		}
+	}
+
+	return hasAnyKey(obj, errorMessageFields) && hasAnyKey(obj, errorCodeFields)
+}
+
+// hasAnyKey checks whether an object has at least one of the keys
+func hasAnyKey(obj map[string]interface{}, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := obj[key]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsErrorEnvelope(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     interface{}
+		expected bool
+	}{
+		{name: "nested error object", body: map[string]interface{}{"error": map[string]interface{}{"code": "__string__"}}, expected: true},
+		{name: "error string", body: map[string]interface{}{"error": "__string__"}, expected: true},
+		{name: "errors list", body: map[string]interface{}{"errors": []interface{}{map[string]interface{}{"field": "email"}}}, expected: true},
+		{name: "message and code", body: map[string]interface{}{"message": "__string__", "code": "__integer__"}, expected: true},
+		{name: "problem details", body: map[string]interface{}{"type": "about:blank", "title": "__string__", "status": "__integer__"}, expected: true},
+		{name: "message only", body: map[string]interface{}{"message": "__string__"}, expected: false},
+		{name: "resource", body: map[string]interface{}{"id": "__integer__", "name": "__string__"}, expected: false},
+		{name: "array", body: []interface{}{"__string__"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsErrorEnvelope(tt.body))
+		})
+	}
+}
+
+func TestIsProblemJSON(t *testing.T) {
+	assert.True(t, IsProblemJSON("application/problem+json"))
+	assert.True(t, IsProblemJSON("application/problem+json; charset=utf-8"))
+	assert.False(t, IsProblemJSON("application/json"))
+	assert.False(t, IsProblemJSON(""))
+}
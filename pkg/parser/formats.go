@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode"
+)
+
+// stringFormatOrder lists the typePatterns from the most to the least specific, so that
+// values matching several patterns (a JWT is also base64-ish, an IP is also hostname-ish)
+// get the most precise format
+var stringFormatOrder = []string{
+	"uuid",
+	"jwt",
+	"email",
+	"uri",
+	"datetime",
+	"date",
+	"time",
+	"ipv4",
+	"ipv6",
+	"phone",
+	"hostname",
+	"currency-code",
+	"country-code",
+	"byte",
+}
+
+// stringFormatValidators double-check values whose pattern alone is too loose
+var stringFormatValidators = map[string]func(string) bool{
+	"hostname":      isLikelyHostname,
+	"country-code":  func(value string) bool { return countryCodes[value] },
+	"currency-code": func(value string) bool { return currencyCodes[value] },
+	"byte":          isLikelyBase64,
+}
+
+// fileExtensions are dotted suffixes that make a value a file name rather than a hostname
+var fileExtensions = map[string]bool{
+	"json": true, "xml": true, "yaml": true, "yml": true, "txt": true, "csv": true,
+	"html": true, "htm": true, "css": true, "js": true, "md": true, "pdf": true,
+	"png": true, "jpg": true, "jpeg": true, "gif": true, "svg": true, "webp": true,
+	"zip": true, "gz": true, "tar": true, "mp3": true, "mp4": true, "doc": true,
+	"docx": true, "xls": true, "xlsx": true, "exe": true, "bin": true, "log": true,
+}
+
+// isLikelyHostname rejects dotted values that are file names, e.g. avatar.png
+func isLikelyHostname(value string) bool {
+	extension := strings.ToLower(value[strings.LastIndex(value, ".")+1:])
+	return !fileExtensions[extension]
+}
+
+// minBase64Length is the shortest value considered base64-encoded binary data
+const minBase64Length = 16
+
+// isLikelyBase64 checks that a value decodes as base64 and mixes character classes the way
+// encoded bytes do, so plain words and hex strings are not mistaken for binary data
+func isLikelyBase64(value string) bool {
+	if len(value) < minBase64Length {
+		return false
+	}
+	if _, err := base64.StdEncoding.DecodeString(value); err != nil {
+		return false
+	}
+
+	var upper, lower, other bool
+	for _, r := range value {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		default:
+			other = true
+		}
+	}
+	return upper && lower && other
+}
+
+// countryCodes holds the ISO 3166-1 alpha-2 country codes
+var countryCodes = codeSet(`
+	AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ
+	BR BS BT BV BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM
+	DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS
+	GT GU GW GY HK HM HN HR HT HU ID IE IL IM IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN
+	KP KR KW KY KZ LA LB LC LI LK LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ
+	MR MS MT MU MV MW MX MY MZ NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM
+	PN PR PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV
+	SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG UM US UY UZ VA VC VE VG VI
+	VN VU WF WS YE YT ZA ZM ZW
+`)
+
+// currencyCodes holds the active ISO 4217 currency codes
+var currencyCodes = codeSet(`
+	AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL BSD BTN
+	BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD
+	FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY
+	KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP
+	MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR
+	RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT
+	TND TOP TRY TTD TWD TZS UAH UGX USD UYU UZS VES VND VUV WST XAF XCD XOF XPF YER ZAR ZMW
+	ZWL
+`)
+
+// codeSet builds a lookup set from a whitespace-separated list of codes
+func codeSet(codes string) map[string]bool {
+	set := make(map[string]bool)
+	for _, code := range strings.Fields(codes) {
+		set[code] = true
+	}
+	return set
+}
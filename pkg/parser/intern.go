@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// SchemaInterner deduplicates structurally identical schemas. Intern returns one canonical
+// schema for all schemas of the same structure, sharing its property maps and nested schemas,
+// so that the many identical schemas parsed from the bodies of an endpoint, and the identical
+// nested objects within them, take up the memory of one. Interned schemas are shared and must
+// not be modified in place.
+type SchemaInterner struct {
+	schemas map[string]Schema // structural hash -> canonical schema
+}
+
+// NewSchemaInterner creates an empty schema interner
+func NewSchemaInterner() *SchemaInterner {
+	return &SchemaInterner{schemas: make(map[string]Schema)}
+}
+
+// Intern returns the canonical schema structurally identical to schema, and its structural hash
+func (in *SchemaInterner) Intern(schema Schema) (Schema, string) {
+	hash := sha256.New()
+	shallow := schema
+	shallow.Properties = nil
+	shallow.Items = nil
+	shallow.AdditionalProperties = nil
+	shallow.Not = nil
+	shallow.AllOf = nil
+	shallow.OneOf = nil
+	shallow.AnyOf = nil
+	data, _ := json.Marshal(struct {
+		Schema     Schema
+		Extensions map[string]interface{}
+	}{shallow, schema.Extensions})
+	hash.Write(data)
+
+	// Intern the nested schemas first, so that they are shared too, and hash the node from
+	// their hashes
+	if schema.Properties != nil {
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		properties := make(map[string]Schema, len(schema.Properties))
+		for _, name := range names {
+			prop, propHash := in.Intern(schema.Properties[name])
+			properties[name] = prop
+			fmt.Fprintf(hash, "\x00properties\x00%s\x00%s", name, propHash)
+		}
+		schema.Properties = properties
+	}
+	schema.Items = in.internRef(hash, "items", schema.Items)
+	schema.AdditionalProperties = in.internRef(hash, "additionalProperties", schema.AdditionalProperties)
+	schema.Not = in.internRef(hash, "not", schema.Not)
+	schema.AllOf = in.internList(hash, "allOf", schema.AllOf)
+	schema.OneOf = in.internList(hash, "oneOf", schema.OneOf)
+	schema.AnyOf = in.internList(hash, "anyOf", schema.AnyOf)
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if canonical, ok := in.schemas[sum]; ok {
+		return canonical, sum
+	}
+	in.schemas[sum] = schema
+	return schema, sum
+}
+
+// Len returns the number of distinct schemas interned
+func (in *SchemaInterner) Len() int {
+	return len(in.schemas)
+}
+
+// internRef interns a nested schema and adds its hash to the hash of its parent
+func (in *SchemaInterner) internRef(hash io.Writer, name string, schema *Schema) *Schema {
+	if schema == nil {
+		return nil
+	}
+	interned, nestedHash := in.Intern(*schema)
+	fmt.Fprintf(hash, "\x00%s\x00%s", name, nestedHash)
+	return &interned
+}
+
+// internList interns a list of nested schemas and adds their hashes to the hash of their parent
+func (in *SchemaInterner) internList(hash io.Writer, name string, schemas []Schema) []Schema {
+	if schemas == nil {
+		return nil
+	}
+	interned := make([]Schema, len(schemas))
+	for i, schema := range schemas {
+		var nestedHash string
+		interned[i], nestedHash = in.Intern(schema)
+		fmt.Fprintf(hash, "\x00%s\x00%s", name, nestedHash)
+	}
+	return interned
+}
+
+// SchemaHash returns a hash of the structure of a schema: two schemas have the same hash when
+// they have the same types, formats, properties, constraints, and examples, however their maps
+// are ordered
+func SchemaHash(schema Schema) string {
+	_, hash := NewSchemaInterner().Intern(schema)
+	return hash
+}
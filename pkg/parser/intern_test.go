@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaHash(t *testing.T) {
+	user := func() Schema {
+		return Schema{
+			Type: "object",
+			Properties: map[string]Schema{
+				"id":   {Type: "integer"},
+				"name": {Type: "string"},
+				"tags": {Type: "array", Items: &Schema{Type: "string"}},
+			},
+		}
+	}
+
+	assert.Equal(t, SchemaHash(user()), SchemaHash(user()))
+
+	changed := user()
+	changed.Properties["tags"] = Schema{Type: "array", Items: &Schema{Type: "integer"}}
+	assert.NotEqual(t, SchemaHash(user()), SchemaHash(changed))
+
+	renamed := user()
+	renamed.Properties["title"] = renamed.Properties["name"]
+	delete(renamed.Properties, "name")
+	assert.NotEqual(t, SchemaHash(user()), SchemaHash(renamed))
+
+	// A property holding a schema differs from an item holding the same schema
+	assert.NotEqual(t,
+		SchemaHash(Schema{Type: "array", Items: &Schema{Type: "string"}}),
+		SchemaHash(Schema{Type: "array", AdditionalProperties: &Schema{Type: "string"}}))
+}
+
+func TestSchemaInterner(t *testing.T) {
+	address := func() Schema {
+		return Schema{
+			Type: "object",
+			Properties: map[string]Schema{
+				"street": {Type: "string"},
+				"city":   {Type: "string"},
+			},
+		}
+	}
+	order := Schema{
+		Type: "object",
+		Properties: map[string]Schema{
+			"billing":  address(),
+			"shipping": address(),
+		},
+	}
+
+	interner := NewSchemaInterner()
+	interned, hash := interner.Intern(order)
+	assert.Equal(t, SchemaHash(order), hash)
+	assert.Equal(t, order, interned)
+
+	// The order, one address, and one string schema
+	assert.Equal(t, 3, interner.Len())
+
+	again, againHash := interner.Intern(order)
+	assert.Equal(t, hash, againHash)
+	assert.Equal(t, interned, again)
+	assert.Equal(t, 3, interner.Len())
+}
+
+func TestSchemaMergerSkipsIdenticalSchemas(t *testing.T) {
+	merger := NewSchemaMerger()
+	for i := 0; i < 3; i++ {
+		merger.AddSchema("/users", "GET", Schema{
+			Type:       "object",
+			Properties: map[string]Schema{"id": {Type: "integer"}},
+		})
+	}
+	merger.AddSchema("/users", "GET", Schema{
+		Type:       "object",
+		Properties: map[string]Schema{"id": {Type: "integer"}, "email": {Type: "string"}},
+	})
+	merger.AddSchema("/orders", "GET", Schema{
+		Type:       "object",
+		Properties: map[string]Schema{"id": {Type: "integer"}},
+	})
+
+	assert.Len(t, merger.schemas["/users:GET"], 2)
+	assert.Len(t, merger.schemas["/orders:GET"], 1)
+
+	merged := merger.MergeSchemas("/users", "GET")
+	assert.Contains(t, merged.Properties, "id")
+	assert.Contains(t, merged.Properties, "email")
+}
@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+)
+
+// dataKeyPatterns match object keys that are data (locales, dates, currency codes) rather
+// than field names
+var dataKeyPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^[a-z]{2}([-_][A-Za-z]{2})?$`), // locale: en, fr, en-US, pt_BR
+	regexp.MustCompile(`^\d{4}-\d{2}(-\d{2})?$`),       // date: 2024-03, 2024-03-01
+	regexp.MustCompile(`^[A-Z]{3}$`),                   // currency: USD, EUR
+}
+
+// minMapKeys is how many distinct keys objects must have across samples before differing
+// key sets are treated as a map rather than optional fields
+const minMapKeys = 4
+
+// CollapseMapSchema turns an object schema whose keys are all data (IDs, locales, dates,
+// currency codes) and whose values share one shape into a map schema with
+// additionalProperties, e.g. {"en": "...", "fr": "..."}. Other schemas are returned unchanged.
+func CollapseMapSchema(schema Schema) Schema {
+	if schema.Type != "object" || len(schema.Properties) < 2 || schema.AdditionalProperties != nil {
+		return schema
+	}
+
+	for key := range schema.Properties {
+		if !isDataKey(key) {
+			return schema
+		}
+	}
+
+	if result, ok := toMapSchema(schema, schema.Properties); ok {
+		return result
+	}
+	return schema
+}
+
+// mergeMapSchemas merges two object schemas into a map schema when either is already a map,
+// or when their key sets are disjoint while all values share one shape. It returns false when
+// the objects look like records with optional fields instead.
+func mergeMapSchemas(a, b Schema) (Schema, bool) {
+	values := make(map[string]Schema)
+	addValues := func(schema Schema, prefix string) {
+		for key, prop := range schema.Properties {
+			values[prefix+key] = prop
+		}
+		if schema.AdditionalProperties != nil {
+			values[prefix+"*"] = *schema.AdditionalProperties
+		}
+	}
+
+	if a.AdditionalProperties == nil && b.AdditionalProperties == nil {
+		keys := make(map[string]bool)
+		for key := range a.Properties {
+			keys[key] = true
+		}
+		for key := range b.Properties {
+			if keys[key] {
+				// Shared keys mean a record schema
+				return Schema{}, false
+			}
+			keys[key] = true
+		}
+		if len(a.Properties) == 0 || len(b.Properties) == 0 || len(keys) < minMapKeys {
+			return Schema{}, false
+		}
+	}
+
+	addValues(a, "a:")
+	addValues(b, "b:")
+
+	result, ok := toMapSchema(a, values)
+	if !ok {
+		return Schema{}, false
+	}
+	result.Nullable = a.Nullable || b.Nullable
+	return result, true
+}
+
+// toMapSchema builds a map schema from the values of an object, provided they share one shape
+func toMapSchema(schema Schema, values map[string]Schema) (Schema, bool) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	samples := make([]Schema, 0, len(keys))
+	for _, key := range keys {
+		value := values[key]
+		if len(samples) > 0 && !sameShape(samples[0], value) {
+			return Schema{}, false
+		}
+		samples = append(samples, value)
+	}
+
+	valueSchema := MergeSchemaList(samples)
+	return Schema{
+		Type:                 "object",
+		Description:          schema.Description,
+		AdditionalProperties: &valueSchema,
+		Example:              schema.Example,
+		Nullable:             schema.Nullable,
+	}, true
+}
+
+// isDataKey checks whether an object key looks like data rather than a field name
+func isDataKey(key string) bool {
+	if _, _, ok := identifierKind(key); ok {
+		return true
+	}
+	for _, pattern := range dataKeyPatterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameShape checks whether two schemas have the same type and, for objects and arrays,
+// the same property names and item types
+func sameShape(a, b Schema) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case "object":
+		return propertySignature([]Schema{a}) == propertySignature([]Schema{b})
+	case "array":
+		if a.Items == nil || b.Items == nil {
+			return a.Items == b.Items
+		}
+		return a.Items.Type == b.Items.Type
+	}
+	return true
+}
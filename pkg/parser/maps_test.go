@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollapseMapSchema(t *testing.T) {
+	tests := []struct {
+		name      string
+		props     map[string]Schema
+		collapsed bool
+	}{
+		{
+			name:      "locales",
+			props:     map[string]Schema{"en": {Type: "string"}, "fr": {Type: "string"}, "pt_BR": {Type: "string"}},
+			collapsed: true,
+		},
+		{
+			name:      "numeric ids",
+			props:     map[string]Schema{"101": {Type: "integer"}, "102": {Type: "integer"}},
+			collapsed: true,
+		},
+		{
+			name: "dates with object values",
+			props: map[string]Schema{
+				"2024-03-01": objectSchema(map[string]Schema{"count": {Type: "integer"}}),
+				"2024-03-02": objectSchema(map[string]Schema{"count": {Type: "integer"}}),
+			},
+			collapsed: true,
+		},
+		{
+			name:      "field names",
+			props:     map[string]Schema{"name": {Type: "string"}, "email": {Type: "string"}},
+			collapsed: false,
+		},
+		{
+			name:      "mixed value types",
+			props:     map[string]Schema{"en": {Type: "string"}, "fr": {Type: "integer"}},
+			collapsed: false,
+		},
+		{
+			name:      "single key",
+			props:     map[string]Schema{"en": {Type: "string"}},
+			collapsed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CollapseMapSchema(objectSchema(tt.props))
+			if !tt.collapsed {
+				assert.Nil(t, result.AdditionalProperties)
+				assert.Len(t, result.Properties, len(tt.props))
+				return
+			}
+			require.NotNil(t, result.AdditionalProperties)
+			assert.Empty(t, result.Properties)
+			assert.Equal(t, "object", result.Type)
+		})
+	}
+}
+
+func TestMergeMapSchemas(t *testing.T) {
+	a := objectSchema(map[string]Schema{"alpha": {Type: "integer"}, "beta": {Type: "integer"}})
+	b := objectSchema(map[string]Schema{"gamma": {Type: "integer"}, "delta": {Type: "integer"}})
+
+	merged := MergeSchemaList([]Schema{a, b})
+	require.NotNil(t, merged.AdditionalProperties)
+	assert.Equal(t, "integer", merged.AdditionalProperties.Type)
+
+	// A map stays a map when merged with further samples
+	c := objectSchema(map[string]Schema{"epsilon": {Type: "integer"}})
+	merged = mergeSchema(merged, c)
+	require.NotNil(t, merged.AdditionalProperties)
+	assert.Empty(t, merged.Properties)
+
+	// Overlapping keys describe a record with optional fields
+	d := objectSchema(map[string]Schema{"alpha": {Type: "integer"}, "gamma": {Type: "integer"}, "delta": {Type: "integer"}})
+	record := MergeSchemaList([]Schema{a, d})
+	assert.Nil(t, record.AdditionalProperties)
+	assert.Len(t, record.Properties, 4)
+
+	// Too few keys to tell a map from optional fields
+	e := objectSchema(map[string]Schema{"gamma": {Type: "integer"}})
+	record = MergeSchemaList([]Schema{objectSchema(map[string]Schema{"alpha": {Type: "integer"}}), e})
+	assert.Nil(t, record.AdditionalProperties)
+}
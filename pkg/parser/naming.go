@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SplitWords splits an identifier on separators and camel-case boundaries,
+// e.g. "user-profiles" and "userProfiles" both become ["user", "profiles"]
+func SplitWords(s string) []string {
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+
+	return words
+}
+
+// GenerateOperationID builds a camel-case operationId from a method and templated path,
+// e.g. GET /users/{id} becomes getUsersById
+func GenerateOperationID(method, path string) string {
+	var builder strings.Builder
+	builder.WriteString(strings.ToLower(method))
+
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			builder.WriteString("By")
+		}
+		builder.WriteString(PascalCase(segment))
+	}
+
+	return builder.String()
+}
+
+// Singularize applies simple English rules to turn a plural resource name into a singular one
+func Singularize(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"):
+		return word
+	case strings.HasSuffix(word, "s") && len(word) > 1:
+		return word[:len(word)-1]
+	default:
+		return word
+	}
+}
+
+// PascalCase joins the words of an identifier in PascalCase, e.g. "bank_account" and
+// "user-profiles" become "BankAccount" and "UserProfiles"
+func PascalCase(s string) string {
+	var builder strings.Builder
+	for _, word := range SplitWords(s) {
+		builder.WriteString(upperFirst(strings.ToLower(word)))
+	}
+	return builder.String()
+}
+
+// upperFirst upper-cases the first letter of a word
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,29 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitWords(t *testing.T) {
+	assert.Equal(t, []string{"user", "profiles"}, SplitWords("user-profiles"))
+	assert.Equal(t, []string{"user", "Profiles"}, SplitWords("userProfiles"))
+	assert.Equal(t, []string{"bulk", "import"}, SplitWords("bulk_import"))
+	assert.Empty(t, SplitWords("{}"))
+}
+
+func TestSingularize(t *testing.T) {
+	tests := map[string]string{
+		"users":      "user",
+		"categories": "category",
+		"addresses":  "address",
+		"boxes":      "box",
+		"status":     "status",
+		"data":       "data",
+	}
+
+	for plural, singular := range tests {
+		assert.Equal(t, singular, Singularize(plural), plural)
+	}
+}
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Pagination styles
+const (
+	PaginationPage   = "page"   // ?page=2&per_page=20
+	PaginationOffset = "offset" // ?offset=40&limit=20
+	PaginationCursor = "cursor" // ?cursor=abc or a next-cursor field in the body
+	PaginationLink   = "link"   // Link: <...>; rel="next" or a next URL in the body
+)
+
+// PaginationInfo describes how an endpoint paginates its results
+type PaginationInfo struct {
+	Style          string
+	PageParam      string
+	SizeParam      string
+	OffsetParam    string
+	CursorParam    string
+	LinkHeader     bool   // Whether a Link header with rel="next" was observed
+	EnvelopeObject string // Nested object holding pagination metadata (e.g. "meta"), if any
+	ItemsField     string // Envelope field holding the page of items
+	TotalField     string // Envelope field holding the total count
+	NextField      string // Envelope field holding the next cursor/link
+}
+
+var (
+	pageParams   = []string{"page", "page_number", "pagenumber", "p"}
+	sizeParams   = []string{"limit", "per_page", "perpage", "page_size", "pagesize", "size", "max_results", "maxresults"}
+	offsetParams = []string{"offset", "skip", "start"}
+	cursorParams = []string{"cursor", "after", "before", "page_token", "pagetoken", "next_token", "nexttoken", "starting_after", "continuation"}
+
+	itemsFields = []string{"data", "items", "results", "records", "entries", "content", "list", "rows"}
+	totalFields = []string{"total", "total_count", "totalcount", "total_items", "totalitems", "total_results", "totalresults", "total_pages", "totalpages", "count"}
+	nextFields  = []string{"next", "next_cursor", "nextcursor", "next_page", "nextpage", "next_page_token", "nextpagetoken", "next_url", "nexturl", "cursor", "has_more", "hasmore"}
+	metaObjects = []string{"meta", "pagination", "paging", "page_info", "pageinfo", "links", "_links"}
+)
+
+// DetectPagination inspects the query parameter names sent to an endpoint along with a
+// response's headers and decoded body, and describes the pagination style if one is found.
+// It returns nil for endpoints that don't look paginated.
+func DetectPagination(queryParams []string, headers http.Header, body interface{}) *PaginationInfo {
+	info := &PaginationInfo{}
+
+	for _, name := range queryParams {
+		lower := strings.ToLower(name)
+		switch {
+		case info.PageParam == "" && contains(pageParams, lower):
+			info.PageParam = name
+		case info.SizeParam == "" && contains(sizeParams, lower):
+			info.SizeParam = name
+		case info.OffsetParam == "" && contains(offsetParams, lower):
+			info.OffsetParam = name
+		case info.CursorParam == "" && contains(cursorParams, lower):
+			info.CursorParam = name
+		}
+	}
+
+	if headers != nil {
+		for _, link := range headers.Values("Link") {
+			if strings.Contains(link, `rel="next"`) || strings.Contains(link, "rel=next") {
+				info.LinkHeader = true
+			}
+		}
+	}
+
+	if obj, ok := body.(map[string]interface{}); ok {
+		info.detectEnvelope(obj)
+	}
+
+	hasEnvelope := info.ItemsField != "" && (info.TotalField != "" || info.NextField != "")
+
+	switch {
+	case info.CursorParam != "" || strings.Contains(strings.ToLower(info.NextField), "cursor") ||
+		strings.Contains(strings.ToLower(info.NextField), "token"):
+		info.Style = PaginationCursor
+	case info.OffsetParam != "":
+		info.Style = PaginationOffset
+	case info.PageParam != "":
+		info.Style = PaginationPage
+	case info.LinkHeader || hasEnvelope && info.NextField != "":
+		info.Style = PaginationLink
+	case hasEnvelope:
+		// Only a total count is available; assume classic page numbering
+		info.Style = PaginationPage
+	case info.SizeParam != "":
+		// A bare limit is not pagination on its own
+		return nil
+	default:
+		return nil
+	}
+
+	return info
+}
+
+// detectEnvelope looks for an items array plus total/next metadata in a response object,
+// either at the top level or inside a nested metadata object
+func (p *PaginationInfo) detectEnvelope(obj map[string]interface{}) {
+	for key, value := range obj {
+		if _, isArray := value.([]interface{}); isArray && contains(itemsFields, strings.ToLower(key)) {
+			p.ItemsField = key
+			break
+		}
+	}
+	if p.ItemsField == "" {
+		return
+	}
+
+	p.TotalField, p.NextField = findPaginationFields(obj)
+	if p.TotalField != "" || p.NextField != "" {
+		return
+	}
+
+	for key, value := range obj {
+		nested, ok := value.(map[string]interface{})
+		if !ok || !contains(metaObjects, strings.ToLower(key)) {
+			continue
+		}
+
+		p.TotalField, p.NextField = findPaginationFields(nested)
+		if p.TotalField != "" || p.NextField != "" {
+			p.EnvelopeObject = key
+			return
+		}
+	}
+}
+
+// findPaginationFields returns the total and next fields of an object, if present
+func findPaginationFields(obj map[string]interface{}) (string, string) {
+	total, next := "", ""
+	for _, candidate := range totalFields {
+		for key := range obj {
+			if total == "" && strings.ToLower(key) == candidate {
+				total = key
+			}
+		}
+	}
+	for _, candidate := range nextFields {
+		for key := range obj {
+			if next == "" && strings.ToLower(key) == candidate {
+				next = key
+			}
+		}
+	}
+	return total, next
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectPagination(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   []string
+		headers  http.Header
+		body     interface{}
+		expected *PaginationInfo
+	}{
+		{
+			name:   "page and size params",
+			params: []string{"page", "per_page", "sort"},
+			expected: &PaginationInfo{
+				Style:     PaginationPage,
+				PageParam: "page",
+				SizeParam: "per_page",
+			},
+		},
+		{
+			name:   "offset and limit params",
+			params: []string{"limit", "offset"},
+			expected: &PaginationInfo{
+				Style:       PaginationOffset,
+				SizeParam:   "limit",
+				OffsetParam: "offset",
+			},
+		},
+		{
+			name:   "cursor param with envelope",
+			params: []string{"cursor"},
+			body: map[string]interface{}{
+				"data":        []interface{}{},
+				"next_cursor": "__string__",
+			},
+			expected: &PaginationInfo{
+				Style:       PaginationCursor,
+				CursorParam: "cursor",
+				ItemsField:  "data",
+				NextField:   "next_cursor",
+			},
+		},
+		{
+			name: "link header",
+			headers: http.Header{
+				"Link": []string{`<https://api.example.com/items?page=2>; rel="next"`},
+			},
+			expected: &PaginationInfo{
+				Style:      PaginationLink,
+				LinkHeader: true,
+			},
+		},
+		{
+			name: "nested metadata envelope",
+			body: map[string]interface{}{
+				"items": []interface{}{},
+				"meta": map[string]interface{}{
+					"total": "__integer__",
+				},
+			},
+			expected: &PaginationInfo{
+				Style:          PaginationPage,
+				EnvelopeObject: "meta",
+				ItemsField:     "items",
+				TotalField:     "total",
+			},
+		},
+		{
+			name:     "bare limit",
+			params:   []string{"limit"},
+			expected: nil,
+		},
+		{
+			name:     "plain object",
+			body:     map[string]interface{}{"id": "__integer__", "total": "__integer__"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := DetectPagination(tt.params, tt.headers, tt.body)
+			if tt.expected == nil {
+				assert.Nil(t, info)
+				return
+			}
+			require.NotNil(t, info)
+			assert.Equal(t, tt.expected, info)
+		})
+	}
+}
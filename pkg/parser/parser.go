@@ -32,24 +32,39 @@ type Components struct {
 
 // Schema represents an OpenAPI schema
 type Schema struct {
-	Type         string            `json:"type,omitempty"`
-	Format       string            `json:"format,omitempty"`
-	Properties   map[string]Schema `json:"properties,omitempty"`
-	Items        *Schema           `json:"items,omitempty"`
-	Required     []string          `json:"required,omitempty"`
-	Enum         []interface{}     `json:"enum,omitempty"`
-	Example      interface{}       `json:"example,omitempty"`
-	AllOf        []Schema          `json:"allOf,omitempty"`
-	OneOf        []Schema          `json:"oneOf,omitempty"`
-	AnyOf        []Schema          `json:"anyOf,omitempty"`
-	Not          *Schema           `json:"not,omitempty"`
-	Default      interface{}       `json:"default,omitempty"`
-	Nullable     bool              `json:"nullable,omitempty"`
-	ReadOnly     bool              `json:"readOnly,omitempty"`
-	WriteOnly    bool              `json:"writeOnly,omitempty"`
-	XML          map[string]string `json:"xml,omitempty"`
-	ExternalDocs map[string]string `json:"externalDocs,omitempty"`
-	Deprecated   bool              `json:"deprecated,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Properties           map[string]Schema      `json:"properties,omitempty"`
+	Items                *Schema                `json:"items,omitempty"`
+	AdditionalProperties *Schema                `json:"additionalProperties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Example              interface{}            `json:"example,omitempty"`
+	AllOf                []Schema               `json:"allOf,omitempty"`
+	OneOf                []Schema               `json:"oneOf,omitempty"`
+	AnyOf                []Schema               `json:"anyOf,omitempty"`
+	Not                  *Schema                `json:"not,omitempty"`
+	Discriminator        *Discriminator         `json:"discriminator,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	Nullable             bool                   `json:"nullable,omitempty"`
+	ReadOnly             bool                   `json:"readOnly,omitempty"`
+	WriteOnly            bool                   `json:"writeOnly,omitempty"`
+	XML                  map[string]string      `json:"xml,omitempty"`
+	ExternalDocs         map[string]string      `json:"externalDocs,omitempty"`
+	Deprecated           bool                   `json:"deprecated,omitempty"`
+	Extensions           map[string]interface{} `json:"-"` // x- vendor extensions
+}
+
+// Discriminator identifies the variant of a oneOf schema by the value of a property
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"` // property value -> variant name
 }
 
 // Operation represents an OpenAPI operation
@@ -148,7 +163,7 @@ func (d *APIDefinition) AddTransaction(transaction proxy.APITransaction) {
 	// Create or update operation
 	operation := d.Paths[path][method]
 	if operation.OperationID == "" {
-		operation.OperationID = generateOperationID(method, path)
+		operation.OperationID = GenerateOperationID(method, path)
 	}
 
 	// Set tags based on path segments
@@ -250,29 +265,6 @@ func (d *APIDefinition) ToOpenAPI() map[string]interface{} {
 	}
 }
 
-// Generate a valid operation ID from method and path
-func generateOperationID(method, path string) string {
-	// Remove leading and trailing slashes
-	path = strings.Trim(path, "/")
-
-	// Replace slashes with underscores
-	path = strings.ReplaceAll(path, "/", "_")
-
-	// Replace non-alphanumeric characters with underscores
-	re := regexp.MustCompile(`[^a-zA-Z0-9_]`)
-	path = re.ReplaceAllString(path, "_")
-
-	// Convert to camelCase
-	segments := strings.Split(path, "_")
-	for i := 1; i < len(segments); i++ {
-		if len(segments[i]) > 0 {
-			segments[i] = strings.ToUpper(segments[i][:1]) + segments[i][1:]
-		}
-	}
-
-	return method + strings.Join(segments, "")
-}
-
 // Generate tags based on the first path segment
 func generateTags(path string) []string {
 	parts := strings.Split(strings.Trim(path, "/"), "/")
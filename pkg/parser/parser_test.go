@@ -59,25 +59,37 @@ func TestGenerateOperationID(t *testing.T) {
 			name:     "simple path",
 			method:   "get",
 			path:     "/users",
-			expected: "getusers",
+			expected: "getUsers",
 		},
 		{
 			name:     "path with parameters",
 			method:   "get",
 			path:     "/users/{id}",
-			expected: "getusersId",
+			expected: "getUsersById",
 		},
 		{
 			name:     "nested path",
 			method:   "post",
 			path:     "/api/v1/users/create",
-			expected: "postapiV1UsersCreate",
+			expected: "postApiV1UsersCreate",
+		},
+		{
+			name:     "nested parameters",
+			method:   "DELETE",
+			path:     "/users/{userId}/posts/{postId}",
+			expected: "deleteUsersByUserIdPostsByPostId",
+		},
+		{
+			name:     "separators",
+			method:   "POST",
+			path:     "/user-profiles/bulk_import",
+			expected: "postUserProfilesBulkImport",
 		},
 		{
 			name:     "path with special characters",
 			method:   "put",
 			path:     "/users/!@#$%^&*()",
-			expected: "putusers",
+			expected: "putUsers",
 		},
 		{
 			name:     "empty path",
@@ -89,7 +101,7 @@ func TestGenerateOperationID(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := generateOperationID(tt.method, tt.path)
+			result := GenerateOperationID(tt.method, tt.path)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -7,22 +7,222 @@ import (
 	"strings"
 )
 
+// Patterns of path segment values, compiled once as path detection runs them on every
+// observed path
+var (
+	uuidPattern  = regexp.MustCompile(`^[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}$`)
+	datePattern  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	slugPattern  = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	paramPattern = regexp.MustCompile(`{([^}]+)}`)
+)
+
+// identifierFormat describes an encoding commonly used for resource IDs in paths
+type identifierFormat struct {
+	name        string // fallback parameter name
+	description string
+	pattern     *regexp.Regexp
+	check       func(string) bool // optional extra check beyond the pattern
+}
+
+// identifierFormats lists ID encodings beyond numeric IDs and UUIDs, most specific first
+var identifierFormats = []identifierFormat{
+	{
+		name:        "objectId",
+		description: "MongoDB ObjectID",
+		pattern:     regexp.MustCompile(`^[a-fA-F0-9]{24}$`),
+	},
+	{
+		name:        "ulid",
+		description: "ULID identifier",
+		pattern:     regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}$`),
+	},
+	{
+		name:        "ksuid",
+		description: "KSUID identifier",
+		pattern:     regexp.MustCompile(`^[0-9A-Za-z]{27}$`),
+	},
+	{
+		name:        "shortId",
+		description: "Base62 identifier",
+		pattern:     regexp.MustCompile(`^[0-9A-Za-z]{8,22}$`),
+		check:       isLikelyBase62ID,
+	},
+}
+
+// isLikelyBase62ID checks that a base62 candidate mixes digits with upper and lower case
+// letters, which distinguishes generated IDs from ordinary words and slugs
+func isLikelyBase62ID(value string) bool {
+	var hasDigit, hasUpper, hasLower bool
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r >= 'A' && r <= 'Z':
+			hasUpper = true
+		case r >= 'a' && r <= 'z':
+			hasLower = true
+		}
+	}
+	return hasDigit && hasUpper && hasLower
+}
+
+// matchIdentifierFormat returns the first identifier format matching a value
+func matchIdentifierFormat(value string) (identifierFormat, bool) {
+	for _, format := range identifierFormats {
+		if format.pattern.MatchString(value) && (format.check == nil || format.check(value)) {
+			return format, true
+		}
+	}
+	return identifierFormat{}, false
+}
+
+// isIdentifierName checks if an inferred parameter name denotes a resource identifier
+func isIdentifierName(name string) bool {
+	if name == "id" || name == "uuid" {
+		return true
+	}
+	for _, format := range identifierFormats {
+		if format.name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // PathPatternDetector detects path patterns from observed URLs
 type PathPatternDetector struct {
-	pathObservations map[string][]string // base path -> observed paths
+	pathObservations map[string][]string // base path -> distinct observed paths, in first-seen order
+	observed         map[string]bool     // observed paths
 	patterns         map[string]string   // detected pattern -> parameter description
+	index            *segmentTrie        // detected patterns by segment
+	overrides        []templateOverride  // user-defined templates, checked before detection
+	templates        map[string]string   // path -> templated path, until the patterns change
+}
+
+// segmentTrie indexes the detected patterns by their segments, so that templating a path walks
+// its segments once instead of looking up every prefix of it
+type segmentTrie struct {
+	children map[string]*segmentTrie
+	pattern  bool // a pattern ends at this node
+}
+
+// newSegmentTrie creates an empty segment trie
+func newSegmentTrie() *segmentTrie {
+	return &segmentTrie{children: make(map[string]*segmentTrie)}
+}
+
+// insert adds the pattern made of segments to the trie
+func (t *segmentTrie) insert(segments []string) {
+	node := t
+	for _, segment := range segments {
+		child, ok := node.children[segment]
+		if !ok {
+			child = newSegmentTrie()
+			node.children[segment] = child
+		}
+		node = child
+	}
+	node.pattern = true
+}
+
+// child returns the node below t for a segment, or nil
+func (t *segmentTrie) child(segment string) *segmentTrie {
+	if t == nil {
+		return nil
+	}
+	return t.children[segment]
+}
+
+// templateOverride forces paths matching a wildcard pattern onto a fixed template
+type templateOverride struct {
+	pattern  []string // segments, "*" matches any single segment
+	template string
 }
 
 // NewPathPatternDetector creates a new path pattern detector
 func NewPathPatternDetector() *PathPatternDetector {
 	return &PathPatternDetector{
 		pathObservations: make(map[string][]string),
+		observed:         make(map[string]bool),
 		patterns:         make(map[string]string),
+		index:            newSegmentTrie(),
+		templates:        make(map[string]string),
+	}
+}
+
+// addPattern registers a detected pattern
+func (d *PathPatternDetector) addPattern(pattern, description string) {
+	if _, exists := d.patterns[pattern]; !exists {
+		d.index.insert(strings.Split(strings.Trim(pattern, "/"), "/"))
+		d.templates = make(map[string]string)
+	}
+	d.patterns[pattern] = description
+}
+
+// AddTemplateOverride forces paths matching pattern (where "*" matches a single segment) to
+// use the given template, e.g. "/users/*/orders/*" -> "/users/{userId}/orders/{orderId}".
+// Overrides are checked in the order they were added, before any detected pattern.
+func (d *PathPatternDetector) AddTemplateOverride(pattern, template string) error {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+
+	if len(patternSegments) != len(templateSegments) {
+		return fmt.Errorf("path template %q has %d segments but pattern %q has %d",
+			template, len(templateSegments), pattern, len(patternSegments))
+	}
+
+	for i, segment := range patternSegments {
+		if segment == "*" {
+			if !isTemplateParam(templateSegments[i]) {
+				return fmt.Errorf("path template %q must use a {parameter} where pattern %q has a wildcard", template, pattern)
+			}
+		} else if segment != templateSegments[i] {
+			return fmt.Errorf("path template %q does not match literal segment %q of pattern %q", template, segment, pattern)
+		}
+	}
+
+	d.overrides = append(d.overrides, templateOverride{
+		pattern:  patternSegments,
+		template: "/" + strings.Join(templateSegments, "/"),
+	})
+	d.templates = make(map[string]string)
+	return nil
+}
+
+// matchOverride returns the user-defined template for a path, if any
+func (d *PathPatternDetector) matchOverride(segments []string) (string, bool) {
+	for _, override := range d.overrides {
+		if len(override.pattern) != len(segments) {
+			continue
+		}
+
+		matched := true
+		for i, segment := range override.pattern {
+			if segment != "*" && segment != segments[i] {
+				matched = false
+				break
+			}
+			if segment == "*" && segments[i] == "" {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return override.template, true
+		}
 	}
+	return "", false
 }
 
-// AddPath adds a path to the detector for analysis
+// AddPath adds a path to the detector for analysis. Paths seen before add nothing to the
+// analysis and are only kept once.
 func (d *PathPatternDetector) AddPath(path string) {
+	if d.observed[path] {
+		return
+	}
+	d.observed[path] = true
+
 	// Split the path into segments
 	segments := strings.Split(strings.Trim(path, "/"), "/")
 	if len(segments) == 0 {
@@ -103,48 +303,79 @@ func (d *PathPatternDetector) detectPatternInGroup(paths []string) {
 
 		// Determine parameter type
 		paramName, paramDesc := d.inferParameterType(values)
+		if isIdentifierName(paramName) {
+			// Identifiers are named after the resource they identify
+			paramName = ContextualParamName(refSegments, i, paramName)
+		}
 
 		// Replace varying segment with parameter
 		templateSegments[i] = fmt.Sprintf("{%s}", paramName)
 
 		// Create the pattern
 		pattern := "/" + strings.Join(templateSegments, "/")
-		d.patterns[pattern] = paramDesc
+		d.addPattern(pattern, paramDesc)
 	}
 }
 
-// detectCommonRESTPatterns detects common REST patterns
+// detectCommonRESTPatterns detects common REST patterns such as /users/123 and
+// /users/123/posts/456, registering a pattern for every identifier in the path
 func (d *PathPatternDetector) detectCommonRESTPatterns(basePath string, paths []string) {
-	// Detect paths like /users/123, /products/456, etc.
-	reIDPath := regexp.MustCompile(`^/` + basePath + `/(\d+)(/.*)?$`)
-
 	for _, path := range paths {
-		matches := reIDPath.FindStringSubmatch(path)
-		if len(matches) > 1 {
-			// Found an ID pattern
-			pattern := fmt.Sprintf("/%s/{id}", basePath)
-			d.patterns[pattern] = "Resource ID"
-
-			// If there's more after the ID, process it recursively
-			if len(matches) > 2 && matches[2] != "" {
-				subPath := matches[2]
-				if len(subPath) > 0 {
-					// Process sub-resources e.g., /users/{id}/posts
-					d.AddPath(fmt.Sprintf("/%s/id%s", basePath, subPath))
-				}
-			}
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		if len(segments) < 2 || segments[0] != basePath {
+			continue
 		}
+
+		d.walkIdentifiers(segments, func(template []string, index int, description string) bool {
+			// Register the pattern up to and including this identifier,
+			// e.g. /users/{userId} and then /users/{userId}/posts/{postId}
+			d.addPattern("/"+strings.Join(template[:index+1], "/"), description)
+			return true
+		})
 	}
+}
 
-	// Look for UUID patterns
-	reUUID := regexp.MustCompile(`^/` + basePath + `/([a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12})(/.*)?$`)
-	for _, path := range paths {
-		matches := reUUID.FindStringSubmatch(path)
-		if len(matches) > 1 {
-			pattern := fmt.Sprintf("/%s/{uuid}", basePath)
-			d.patterns[pattern] = "Resource UUID"
+// walkIdentifiers walks the segments of a path and, for every segment that looks like a
+// resource identifier, replaces it in a template copy with a contextual parameter and calls
+// visit with the template, the segment index, and the identifier description. If visit
+// returns false the segment is left as a literal. The final template is returned.
+func (d *PathPatternDetector) walkIdentifiers(segments []string, visit func(template []string, index int, description string) bool) []string {
+	template := make([]string, len(segments))
+	copy(template, segments)
+
+	// The first segment is always the resource collection
+	for i := 1; i < len(template); i++ {
+		kind, description, ok := identifierKind(template[i])
+		if !ok {
+			continue
+		}
+
+		literal := template[i]
+		template[i] = "{" + ContextualParamName(template, i, kind) + "}"
+		if !visit(template, i, description) {
+			template[i] = literal
 		}
 	}
+
+	return template
+}
+
+// identifierKind reports whether a path segment looks like a resource identifier,
+// returning the fallback parameter name and a description of the identifier
+func identifierKind(segment string) (string, string, bool) {
+	if _, err := strconv.Atoi(segment); err == nil {
+		return "id", "Resource ID", true
+	}
+
+	if uuidPattern.MatchString(segment) {
+		return "uuid", "Resource UUID", true
+	}
+
+	if format, ok := matchIdentifierFormat(segment); ok {
+		return format.name, format.description, true
+	}
+
+	return "", "", false
 }
 
 // inferParameterType tries to infer the type of parameter from observed values
@@ -169,7 +400,6 @@ func (d *PathPatternDetector) inferParameterType(values []string) (string, strin
 	}
 
 	// Check for UUIDs
-	uuidPattern := regexp.MustCompile(`^[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}$`)
 	allUUIDs := true
 	for v := range uniqueValues {
 		if !uuidPattern.MatchString(v) {
@@ -182,8 +412,22 @@ func (d *PathPatternDetector) inferParameterType(values []string) (string, strin
 		return "uuid", "UUID identifier"
 	}
 
+	// Check for other identifier encodings (ObjectID, ULID, KSUID, base62)
+	for _, format := range identifierFormats {
+		allMatch := len(uniqueValues) > 0
+		for v := range uniqueValues {
+			if !format.pattern.MatchString(v) || (format.check != nil && !format.check(v)) {
+				allMatch = false
+				break
+			}
+		}
+
+		if allMatch {
+			return format.name, format.description
+		}
+	}
+
 	// Check for dates
-	datePattern := regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
 	allDates := true
 	for v := range uniqueValues {
 		if !datePattern.MatchString(v) {
@@ -197,7 +441,6 @@ func (d *PathPatternDetector) inferParameterType(values []string) (string, strin
 	}
 
 	// Check for slugs
-	slugPattern := regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
 	allSlugs := true
 	for v := range uniqueValues {
 		if !slugPattern.MatchString(v) {
@@ -219,50 +462,104 @@ func (d *PathPatternDetector) GetPatterns() map[string]string {
 	return d.patterns
 }
 
-// TemplatizePath converts a concrete path to a templated path if it matches a pattern
+// TemplatizePath converts a concrete path to a templated path if it matches a pattern.
+// Every identifier segment is templated, so nested resources such as /users/1/posts/2
+// become /users/{userId}/posts/{postId}. Templated paths are remembered until the patterns
+// change, as the same paths are usually templated many times.
 func (d *PathPatternDetector) TemplatizePath(path string) string {
+	if template, ok := d.templates[path]; ok {
+		return template
+	}
+	template := d.templatizePath(path)
+	d.templates[path] = template
+	return template
+}
+
+// templatizePath converts a concrete path to a templated path if it matches a pattern
+func (d *PathPatternDetector) templatizePath(path string) string {
 	// Check if this path exactly matches a pattern
 	if _, exists := d.patterns[path]; exists {
 		return path
 	}
 
-	// Try to match with existing patterns
 	segments := strings.Split(strings.Trim(path, "/"), "/")
-	if len(segments) == 0 {
+
+	// User-defined templates take precedence over heuristics
+	if template, ok := d.matchOverride(segments); ok {
+		return template
+	}
+
+	if len(segments) < 2 {
 		return path
 	}
 
-	basePath := segments[0]
+	// Only replace identifiers whose pattern (up to that segment) was detected. The template
+	// is final up to the previous identifier, so the trie is walked on from there.
+	templated := false
+	node, walked := d.index, 0
+	template := d.walkIdentifiers(segments, func(template []string, index int, _ string) bool {
+		for ; walked < index; walked++ {
+			node = node.child(template[walked])
+		}
+		next := node.child(template[index])
+		if next == nil || !next.pattern {
+			return false
+		}
+		node, walked = next, index+1
+		templated = true
+		return true
+	})
+
+	// If no pattern matches, return the original path
+	if !templated {
+		return path
+	}
+
+	return "/" + strings.Join(template, "/")
+}
 
-	// Try common patterns first
-	if len(segments) >= 2 {
-		// Check for numeric IDs
-		if _, err := strconv.Atoi(segments[1]); err == nil {
-			pattern := fmt.Sprintf("/%s/{id}", basePath)
-			if _, exists := d.patterns[pattern]; exists {
-				// Replace the numeric segment with {id}
-				segments[1] = "{id}"
-				// If there are more segments, check if they also match patterns
-				if len(segments) > 2 {
-					// TODO: Handle deeper patterns recursively
+// ContextualParamName names the parameter at segments[index] after the resource segment
+// preceding it, e.g. "users" gives "userId". The fallback is used when there is no literal
+// resource segment before the parameter. Names already used earlier in the template (for
+// example /users/{userId}/users/{userId}) are deduplicated with a numeric suffix.
+func ContextualParamName(segments []string, index int, fallback string) string {
+	name := fallback
+	if index > 0 && index <= len(segments) && !isTemplateParam(segments[index-1]) {
+		words := SplitWords(segments[index-1])
+		if len(words) > 0 {
+			words[len(words)-1] = Singularize(words[len(words)-1])
+
+			var builder strings.Builder
+			for i, word := range words {
+				word = strings.ToLower(word)
+				if i > 0 {
+					word = upperFirst(word)
 				}
-				return "/" + strings.Join(segments, "/")
+				builder.WriteString(word)
 			}
+			builder.WriteString("Id")
+			name = builder.String()
 		}
+	}
 
-		// Check for UUIDs
-		uuidPattern := regexp.MustCompile(`^[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}$`)
-		if uuidPattern.MatchString(segments[1]) {
-			pattern := fmt.Sprintf("/%s/{uuid}", basePath)
-			if _, exists := d.patterns[pattern]; exists {
-				segments[1] = "{uuid}"
-				return "/" + strings.Join(segments, "/")
-			}
+	// Deduplicate against parameters already present earlier in the template
+	used := make(map[string]bool)
+	for i := 0; i < index && i < len(segments); i++ {
+		if isTemplateParam(segments[i]) {
+			used[strings.Trim(segments[i], "{}")] = true
 		}
 	}
 
-	// If no pattern matches, return the original path
-	return path
+	candidate := name
+	for n := 2; used[candidate]; n++ {
+		candidate = fmt.Sprintf("%s%d", name, n)
+	}
+	return candidate
+}
+
+// isTemplateParam checks if a path segment is a template parameter like {id}
+func isTemplateParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
 }
 
 // GetPathParameters extracts parameters from a path given its template
@@ -270,7 +567,6 @@ func GetPathParameters(path, template string) map[string]string {
 	params := make(map[string]string)
 
 	// Extract parameter names from the template
-	paramPattern := regexp.MustCompile(`{([^}]+)}`)
 	matches := paramPattern.FindAllStringSubmatch(template, -1)
 
 	if len(matches) == 0 {
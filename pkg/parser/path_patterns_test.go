@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -70,6 +71,28 @@ func TestAddPath(t *testing.T) {
 	}
 }
 
+func TestAddPathSkipsDuplicates(t *testing.T) {
+	detector := NewPathPatternDetector()
+	for i := 0; i < 3; i++ {
+		detector.AddPath("/users/1")
+		detector.AddPath("/users/2")
+	}
+	assert.Equal(t, map[string][]string{"users": {"/users/1", "/users/2"}}, detector.pathObservations)
+}
+
+func TestTemplatizePathAfterPatternChanges(t *testing.T) {
+	detector := NewPathPatternDetector()
+	assert.Equal(t, "/users/1/posts/2", detector.TemplatizePath("/users/1/posts/2"))
+
+	// Remembered templates don't outlive the patterns they were made with
+	detector.AddPath("/users/1/posts/2")
+	detector.AnalyzePatterns()
+	assert.Equal(t, "/users/{userId}/posts/{postId}", detector.TemplatizePath("/users/1/posts/2"))
+
+	assert.NoError(t, detector.AddTemplateOverride("/users/*/posts/*", "/users/{id}/posts/{postId}"))
+	assert.Equal(t, "/users/{id}/posts/{postId}", detector.TemplatizePath("/users/1/posts/2"))
+}
+
 func TestAnalyzePatterns(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -98,8 +121,8 @@ func TestAnalyzePatterns(t *testing.T) {
 			paths:         []string{"/users/123", "/users/456"},
 			expectedCount: 1,
 			validateResult: func(t *testing.T, patterns map[string]string) {
-				assert.Contains(t, patterns, "/users/{id}")
-				assert.Equal(t, "Resource ID", patterns["/users/{id}"])
+				assert.Contains(t, patterns, "/users/{userId}")
+				assert.Equal(t, "Resource ID", patterns["/users/{userId}"])
 			},
 		},
 		{
@@ -107,8 +130,8 @@ func TestAnalyzePatterns(t *testing.T) {
 			paths:         []string{"/users/123e4567-e89b-12d3-a456-426614174000", "/users/123e4567-e89b-12d3-a456-426614174001"},
 			expectedCount: 1,
 			validateResult: func(t *testing.T, patterns map[string]string) {
-				assert.Contains(t, patterns, "/users/{uuid}")
-				assert.Equal(t, "Resource UUID", patterns["/users/{uuid}"])
+				assert.Contains(t, patterns, "/users/{userId}")
+				assert.Equal(t, "Resource UUID", patterns["/users/{userId}"])
 			},
 		},
 		{
@@ -132,11 +155,10 @@ func TestAnalyzePatterns(t *testing.T) {
 		{
 			name:          "multiple varying segments",
 			paths:         []string{"/users/123/posts/456", "/users/789/posts/101"},
-			expectedCount: 3, // Actual implementation creates 3 patterns
+			expectedCount: 4, // Two group patterns plus one REST pattern per nesting level
 			validateResult: func(t *testing.T, patterns map[string]string) {
-				assert.Contains(t, patterns, "/users/{id}")
-				// The actual implementation's behavior differs from expected
-				// It should detect the nested pattern differently
+				assert.Contains(t, patterns, "/users/{userId}")
+				assert.Contains(t, patterns, "/users/{userId}/posts/{postId}")
 			},
 		},
 		{
@@ -144,7 +166,7 @@ func TestAnalyzePatterns(t *testing.T) {
 			paths:         []string{"/users", "/users/123/posts"},
 			expectedCount: 1,
 			validateResult: func(t *testing.T, patterns map[string]string) {
-				assert.Contains(t, patterns, "/users/{id}")
+				assert.Contains(t, patterns, "/users/{userId}")
 			},
 		},
 		{
@@ -153,7 +175,7 @@ func TestAnalyzePatterns(t *testing.T) {
 			expectedCount: 2, // Implementation identifies both patterns
 			validateResult: func(t *testing.T, patterns map[string]string) {
 				// The actual implementation detects more patterns
-				assert.Contains(t, patterns, "/users/{id}")
+				assert.Contains(t, patterns, "/users/{userId}")
 				assert.Contains(t, patterns, "/users/{slug}")
 			},
 		},
@@ -259,6 +281,30 @@ func TestInferParameterType(t *testing.T) {
 			expectedName: "uuid",
 			expectedDesc: "UUID identifier",
 		},
+		{
+			name:         "ObjectID values",
+			values:       []string{"507f1f77bcf86cd799439011", "507f191e810c19729de860ea"},
+			expectedName: "objectId",
+			expectedDesc: "MongoDB ObjectID",
+		},
+		{
+			name:         "ULID values",
+			values:       []string{"01ARZ3NDEKTSV4RRFFQ69G5FAV", "01BX5ZZKBKACTAV9WEVGEMMVRZ"},
+			expectedName: "ulid",
+			expectedDesc: "ULID identifier",
+		},
+		{
+			name:         "KSUID values",
+			values:       []string{"0ujtsYcgvSTl8PAuAdqWYSMnLOv", "0ujzPyRiIAffKhBux4PvQdDqMHY"},
+			expectedName: "ksuid",
+			expectedDesc: "KSUID identifier",
+		},
+		{
+			name:         "base62 values",
+			values:       []string{"aZ3kP9xQ", "Qm7Tz2Lp"},
+			expectedName: "shortId",
+			expectedDesc: "Base62 identifier",
+		},
 		{
 			name:         "date values",
 			values:       []string{"2023-01-15", "2023-02-20"},
@@ -319,13 +365,43 @@ func TestTemplatizePath(t *testing.T) {
 			name:           "numeric ID pattern",
 			setupPaths:     []string{"/users/123", "/users/456"},
 			testPath:       "/users/789",
-			expectedResult: "/users/{id}",
+			expectedResult: "/users/{userId}",
 		},
 		{
 			name:           "UUID pattern",
 			setupPaths:     []string{"/users/123e4567-e89b-12d3-a456-426614174000", "/users/123e4567-e89b-12d3-a456-426614174001"},
 			testPath:       "/users/123e4567-e89b-12d3-a456-426614174002",
-			expectedResult: "/users/{uuid}",
+			expectedResult: "/users/{userId}",
+		},
+		{
+			name:           "nested resources",
+			setupPaths:     []string{"/users/1/posts/2", "/users/3/posts/4/comments/5"},
+			testPath:       "/users/7/posts/8/comments/9",
+			expectedResult: "/users/{userId}/posts/{postId}/comments/{commentId}",
+		},
+		{
+			name:           "nested resource with trailing action",
+			setupPaths:     []string{"/orders/1/items/2/refund"},
+			testPath:       "/orders/1/items/2/refund",
+			expectedResult: "/orders/{orderId}/items/{itemId}/refund",
+		},
+		{
+			name:           "mixed identifier kinds",
+			setupPaths:     []string{"/users/123e4567-e89b-12d3-a456-426614174000/posts/42"},
+			testPath:       "/users/123e4567-e89b-12d3-a456-426614174000/posts/42",
+			expectedResult: "/users/{userId}/posts/{postId}",
+		},
+		{
+			name:           "ObjectID and ULID identifiers",
+			setupPaths:     []string{"/stores/507f1f77bcf86cd799439011/orders/01ARZ3NDEKTSV4RRFFQ69G5FAV"},
+			testPath:       "/stores/507f191e810c19729de860ea/orders/01BX5ZZKBKACTAV9WEVGEMMVRZ",
+			expectedResult: "/stores/{storeId}/orders/{orderId}",
+		},
+		{
+			name:           "plain words are not identifiers",
+			setupPaths:     []string{"/users/profile", "/users/settings"},
+			testPath:       "/users/settings",
+			expectedResult: "/users/settings",
 		},
 		{
 			name:           "no match",
@@ -453,8 +529,8 @@ func TestDetectCommonRESTPatterns(t *testing.T) {
 			paths:         []string{"/users/123", "/users/456"},
 			expectedCount: 1,
 			validateResult: func(t *testing.T, patterns map[string]string) {
-				assert.Contains(t, patterns, "/users/{id}")
-				assert.Equal(t, "Resource ID", patterns["/users/{id}"])
+				assert.Contains(t, patterns, "/users/{userId}")
+				assert.Equal(t, "Resource ID", patterns["/users/{userId}"])
 			},
 		},
 		{
@@ -463,17 +539,18 @@ func TestDetectCommonRESTPatterns(t *testing.T) {
 			paths:         []string{"/users/123e4567-e89b-12d3-a456-426614174000", "/users/123e4567-e89b-12d3-a456-426614174001"},
 			expectedCount: 1,
 			validateResult: func(t *testing.T, patterns map[string]string) {
-				assert.Contains(t, patterns, "/users/{uuid}")
-				assert.Equal(t, "Resource UUID", patterns["/users/{uuid}"])
+				assert.Contains(t, patterns, "/users/{userId}")
+				assert.Equal(t, "Resource UUID", patterns["/users/{userId}"])
 			},
 		},
 		{
 			name:          "nested resources",
 			basePath:      "users",
 			paths:         []string{"/users/123/posts/456"},
-			expectedCount: 1,
+			expectedCount: 2,
 			validateResult: func(t *testing.T, patterns map[string]string) {
-				assert.Contains(t, patterns, "/users/{id}")
+				assert.Contains(t, patterns, "/users/{userId}")
+				assert.Contains(t, patterns, "/users/{userId}/posts/{postId}")
 			},
 		},
 		{
@@ -489,10 +566,9 @@ func TestDetectCommonRESTPatterns(t *testing.T) {
 			name:          "mixed patterns",
 			basePath:      "resources",
 			paths:         []string{"/resources/123", "/resources/123e4567-e89b-12d3-a456-426614174000"},
-			expectedCount: 2,
+			expectedCount: 1, // Numeric and UUID identifiers share the contextual name
 			validateResult: func(t *testing.T, patterns map[string]string) {
-				assert.Contains(t, patterns, "/resources/{id}")
-				assert.Contains(t, patterns, "/resources/{uuid}")
+				assert.Contains(t, patterns, "/resources/{resourceId}")
 			},
 		},
 	}
@@ -558,8 +634,7 @@ func TestComplexScenarios(t *testing.T) {
 				patterns := detector.GetPatterns()
 
 				// Check for ID patterns
-				assert.Contains(t, patterns, "/users/{id}")
-				assert.Contains(t, patterns, "/users/{uuid}")
+				assert.Contains(t, patterns, "/users/{userId}")
 				assert.Contains(t, patterns, "/users/{slug}")
 
 				// The event pattern might not be detected as expected
@@ -590,3 +665,68 @@ func TestComplexScenarios(t *testing.T) {
 		})
 	}
 }
+
+func TestContextualParamName(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []string
+		index    int
+		fallback string
+		expected string
+	}{
+		{name: "plural resource", segments: []string{"users", "123"}, index: 1, fallback: "id", expected: "userId"},
+		{name: "ies plural", segments: []string{"categories", "7"}, index: 1, fallback: "id", expected: "categoryId"},
+		{name: "multi-word resource", segments: []string{"user-profiles", "7"}, index: 1, fallback: "id", expected: "userProfileId"},
+		{name: "nested resource", segments: []string{"users", "{userId}", "posts", "9"}, index: 3, fallback: "id", expected: "postId"},
+		{name: "no preceding resource", segments: []string{"123"}, index: 0, fallback: "id", expected: "id"},
+		{name: "preceded by parameter", segments: []string{"files", "{fileId}", "abc"}, index: 2, fallback: "id", expected: "id"},
+		{name: "duplicate name", segments: []string{"users", "{userId}", "users", "9"}, index: 3, fallback: "id", expected: "userId2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ContextualParamName(tt.segments, tt.index, tt.fallback))
+		})
+	}
+}
+
+func TestTemplateOverrides(t *testing.T) {
+	detector := NewPathPatternDetector()
+	err := detector.AddTemplateOverride("/users/*/orders/*", "/users/{userId}/orders/{orderId}")
+	assert.NoError(t, err)
+
+	// Overrides apply even without enough samples to detect a pattern
+	assert.Equal(t, "/users/{userId}/orders/{orderId}", detector.TemplatizePath("/users/me/orders/latest"))
+	assert.Equal(t, "/users/me/orders", detector.TemplatizePath("/users/me/orders"))
+
+	// Invalid overrides are rejected
+	assert.Error(t, detector.AddTemplateOverride("/users/*", "/users/{userId}/orders"))
+	assert.Error(t, detector.AddTemplateOverride("/users/*", "/users/me"))
+	assert.Error(t, detector.AddTemplateOverride("/users/*", "/accounts/{userId}"))
+}
+
+func BenchmarkPathPatterns(b *testing.B) {
+	// Many distinct paths, each requested several times, as in a long capture
+	var paths []string
+	for i := 0; i < 20000; i++ {
+		paths = append(paths,
+			fmt.Sprintf("/users/%d", i),
+			fmt.Sprintf("/users/%d/orders/%d", i%500, i),
+			fmt.Sprintf("/products/%08x-0000-4000-8000-%012x", i, i),
+			"/users",
+			"/products")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		detector := NewPathPatternDetector()
+		for _, path := range paths {
+			detector.AddPath(path)
+		}
+		detector.AnalyzePatterns()
+		for _, path := range paths {
+			detector.TemplatizePath(path)
+		}
+	}
+}
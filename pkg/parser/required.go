@@ -0,0 +1,50 @@
+package parser
+
+import "sort"
+
+// DefaultRequiredThreshold requires a property to be present in every observed object
+const DefaultRequiredThreshold = 1.0
+
+// InferRequired recomputes the required properties of every object in a schema from how
+// often each property was present in the samples observed at that position. A property is
+// required when it appears in at least the threshold fraction (0-1] of the object samples.
+// Objects without samples keep their required list.
+func InferRequired(schema Schema, samples []interface{}, threshold float64) Schema {
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultRequiredThreshold
+	}
+
+	return walkSchemaSamples(schema, samples, func(_ string, schema *Schema, samples []interface{}) {
+		if schema.Type != "object" || len(schema.Properties) == 0 {
+			return
+		}
+
+		objects := 0
+		presence := make(map[string]int)
+		for _, sample := range samples {
+			obj, ok := sample.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			objects++
+			for name := range schema.Properties {
+				if _, exists := obj[name]; exists {
+					presence[name]++
+				}
+			}
+		}
+		if objects == 0 {
+			return
+		}
+
+		var required []string
+		for name := range schema.Properties {
+			// Allow for floating point error, e.g. 0.8 of 5 samples
+			if float64(presence[name])/float64(objects) >= threshold-1e-9 {
+				required = append(required, name)
+			}
+		}
+		sort.Strings(required)
+		schema.Required = required
+	})
+}
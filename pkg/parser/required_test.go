@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferRequired(t *testing.T) {
+	schema := Schema{
+		Type: "object",
+		Properties: map[string]Schema{
+			"id":       {Type: "integer"},
+			"name":     {Type: "string"},
+			"nickname": {Type: "string"},
+			"address": objectSchema(map[string]Schema{
+				"city": {Type: "string"},
+				"zip":  {Type: "string"},
+			}),
+		},
+		Required: []string{"id", "name", "nickname", "address"},
+	}
+	samples := []interface{}{
+		map[string]interface{}{"id": 1.0, "name": "a", "nickname": "x", "address": map[string]interface{}{"city": "c", "zip": "z"}},
+		map[string]interface{}{"id": 2.0, "name": "b", "address": map[string]interface{}{"city": "c"}},
+		map[string]interface{}{"id": 3.0, "name": "c", "address": map[string]interface{}{"city": "c", "zip": "z"}},
+		map[string]interface{}{"id": 4.0},
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		required  []string
+		nested    []string
+	}{
+		{name: "default", threshold: 0, required: []string{"id"}, nested: []string{"city"}},
+		{name: "all samples", threshold: 1, required: []string{"id"}, nested: []string{"city"}},
+		{name: "most samples", threshold: 0.75, required: []string{"address", "id", "name"}, nested: []string{"city"}},
+		{name: "some samples", threshold: 0.5, required: []string{"address", "id", "name"}, nested: []string{"city", "zip"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := InferRequired(schema, samples, tt.threshold)
+			assert.Equal(t, tt.required, result.Required)
+			assert.Equal(t, tt.nested, result.Properties["address"].Required)
+		})
+	}
+
+	// Without samples the required list is kept
+	unchanged := InferRequired(schema, nil, 1)
+	assert.Equal(t, schema.Required, unchanged.Required)
+}
+
+func TestInferRequiredArrayItemsAndVariants(t *testing.T) {
+	merged := MergeSchemaList([]Schema{
+		objectSchema(map[string]Schema{"type": {Type: "string", Example: "card"}, "last4": {Type: "string"}, "brand": {Type: "string"}}),
+		objectSchema(map[string]Schema{"type": {Type: "string", Example: "bank"}, "iban": {Type: "string"}}),
+	})
+	schema := Schema{Type: "array", Items: &merged}
+
+	samples := []interface{}{
+		[]interface{}{
+			map[string]interface{}{"type": "card", "last4": "1", "brand": "x"},
+			map[string]interface{}{"type": "card", "last4": "2"},
+			map[string]interface{}{"type": "bank", "iban": "i"},
+		},
+	}
+
+	result := InferRequired(schema, samples, 1)
+	require.NotNil(t, result.Items)
+	require.Len(t, result.Items.OneOf, 2)
+	assert.Equal(t, []string{"iban", "type"}, result.Items.OneOf[0].Required)
+	assert.Equal(t, []string{"last4", "type"}, result.Items.OneOf[1].Required)
+}
@@ -0,0 +1,73 @@
+package parser
+
+// Reservoir keeps a uniform random sample of at most a fixed number of values from a stream of
+// any length (reservoir sampling), so that memory stays bounded however many values are added
+// while late values are as likely to be kept as early ones. The random choices are seeded, so the
+// same stream always yields the same sample.
+type Reservoir struct {
+	size   int
+	seen   int
+	values []interface{}
+	state  uint64
+}
+
+// NewReservoir creates a reservoir of at most size values, or of all values if size is 0 or less
+func NewReservoir(size int) *Reservoir {
+	return &Reservoir{size: size, state: 0x9e3779b97f4a7c15}
+}
+
+// Add offers a value to the reservoir
+func (r *Reservoir) Add(value interface{}) {
+	switch i := r.Offer(); {
+	case i == len(r.values):
+		r.values = append(r.values, value)
+	case i >= 0:
+		r.values[i] = value
+	}
+}
+
+// Offer counts a value offered to the reservoir and returns the slot it is kept in: the next
+// free one while the reservoir fills up, the one it replaces once it is full, or -1 when it is
+// not kept. Add keeps the values itself; callers that keep them elsewhere call Offer directly.
+func (r *Reservoir) Offer() int {
+	r.seen++
+	if r.size <= 0 || r.seen <= r.size {
+		return r.seen - 1
+	}
+	if i := r.next() % uint64(r.seen); i < uint64(r.size) {
+		return int(i)
+	}
+	return -1
+}
+
+// Freeze stops an unbounded reservoir from growing: it keeps the values offered so far, and
+// from then on a uniform random sample of that many values
+func (r *Reservoir) Freeze() {
+	if r.size <= 0 {
+		r.size = r.seen
+	}
+}
+
+// Values returns the kept values. A nil reservoir has none.
+func (r *Reservoir) Values() []interface{} {
+	if r == nil {
+		return nil
+	}
+	return r.values
+}
+
+// Seen returns how many values were offered to the reservoir
+func (r *Reservoir) Seen() int {
+	if r == nil {
+		return 0
+	}
+	return r.seen
+}
+
+// next returns the next pseudo-random number (xorshift64*)
+func (r *Reservoir) next() uint64 {
+	r.state ^= r.state >> 12
+	r.state ^= r.state << 25
+	r.state ^= r.state >> 27
+	return r.state * 0x2545f4914f6cdd1d
+}
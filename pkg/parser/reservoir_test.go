@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservoir(t *testing.T) {
+	// Below its size a reservoir keeps every value in order
+	small := NewReservoir(5)
+	for i := 0; i < 3; i++ {
+		small.Add(i)
+	}
+	assert.Equal(t, []interface{}{0, 1, 2}, small.Values())
+
+	// Beyond it, the kept values are spread over the whole stream
+	reservoir := NewReservoir(100)
+	for i := 0; i < 10000; i++ {
+		reservoir.Add(i)
+	}
+	assert.Len(t, reservoir.Values(), 100)
+	assert.Equal(t, 10000, reservoir.Seen())
+	late := 0
+	for _, value := range reservoir.Values() {
+		if value.(int) >= 5000 {
+			late++
+		}
+	}
+	assert.InDelta(t, 50, late, 20)
+
+	// The sample is the same for the same stream
+	again := NewReservoir(100)
+	for i := 0; i < 10000; i++ {
+		again.Add(i)
+	}
+	assert.Equal(t, reservoir.Values(), again.Values())
+
+	// An unbounded reservoir keeps everything
+	unbounded := NewReservoir(0)
+	for i := 0; i < 1000; i++ {
+		unbounded.Add(i)
+	}
+	assert.Len(t, unbounded.Values(), 1000)
+	assert.Nil(t, (*Reservoir)(nil).Values())
+}
+
+func TestReservoirFreeze(t *testing.T) {
+	reservoir := NewReservoir(0)
+	for i := 0; i < 50; i++ {
+		reservoir.Add(i)
+	}
+	reservoir.Freeze()
+	for i := 50; i < 10000; i++ {
+		reservoir.Add(i)
+	}
+	assert.Len(t, reservoir.Values(), 50)
+	assert.Equal(t, 10000, reservoir.Seen())
+	late := 0
+	for _, value := range reservoir.Values() {
+		if value.(int) >= 5000 {
+			late++
+		}
+	}
+	assert.InDelta(t, 25, late, 12)
+
+	// A bounded reservoir keeps its size
+	bounded := NewReservoir(10)
+	bounded.Add(0)
+	bounded.Freeze()
+	for i := 1; i < 100; i++ {
+		bounded.Add(i)
+	}
+	assert.Len(t, bounded.Values(), 10)
+}
+
+func TestReservoirOffer(t *testing.T) {
+	// Offer picks the slots Add keeps values in, for callers that store the values themselves
+	added := NewReservoir(10)
+	offered := NewReservoir(10)
+	var kept []interface{}
+	for i := 0; i < 1000; i++ {
+		added.Add(i)
+		switch slot := offered.Offer(); {
+		case slot == len(kept):
+			kept = append(kept, i)
+		case slot >= 0:
+			kept[slot] = i
+		}
+	}
+	assert.Equal(t, added.Values(), kept)
+	assert.Equal(t, 1000, offered.Seen())
+}
@@ -0,0 +1,87 @@
+package parser
+
+// schemaVisitor is called for a schema position with the property name it is stored under
+// (empty for bodies, array items, and map values) and the samples observed there
+type schemaVisitor func(name string, schema *Schema, samples []interface{})
+
+// walkSchemaSamples visits a schema and every nested schema (properties, map values, array
+// items, and oneOf variants) together with the sample values observed at that position.
+// visit is called on copies, children first, and the updated schema is returned.
+func walkSchemaSamples(schema Schema, samples []interface{}, visit schemaVisitor) Schema {
+	return walkNamedSchemaSamples("", schema, samples, visit)
+}
+
+// walkNamedSchemaSamples is walkSchemaSamples for a schema stored under a property name
+func walkNamedSchemaSamples(name string, schema Schema, samples []interface{}, visit schemaVisitor) Schema {
+	if len(schema.OneOf) > 0 {
+		variants := make([]Schema, len(schema.OneOf))
+		for i, variant := range schema.OneOf {
+			variants[i] = walkNamedSchemaSamples(name, variant, variantSamples(schema, variant, samples), visit)
+		}
+		schema.OneOf = variants
+	}
+
+	if schema.Properties != nil {
+		properties := make(map[string]Schema, len(schema.Properties))
+		for propName, prop := range schema.Properties {
+			var values []interface{}
+			for _, sample := range samples {
+				if obj, ok := sample.(map[string]interface{}); ok {
+					if value, exists := obj[propName]; exists {
+						values = append(values, value)
+					}
+				}
+			}
+			properties[propName] = walkNamedSchemaSamples(propName, prop, values, visit)
+		}
+		schema.Properties = properties
+	}
+
+	if schema.AdditionalProperties != nil {
+		var values []interface{}
+		for _, sample := range samples {
+			if obj, ok := sample.(map[string]interface{}); ok {
+				for _, value := range obj {
+					values = append(values, value)
+				}
+			}
+		}
+		walked := walkSchemaSamples(*schema.AdditionalProperties, values, visit)
+		schema.AdditionalProperties = &walked
+	}
+
+	if schema.Items != nil {
+		var items []interface{}
+		for _, sample := range samples {
+			if arr, ok := sample.([]interface{}); ok {
+				items = append(items, arr...)
+			}
+		}
+		walked := walkSchemaSamples(*schema.Items, items, visit)
+		schema.Items = &walked
+	}
+
+	visit(name, &schema, samples)
+	return schema
+}
+
+// variantSamples returns the samples belonging to one variant of a discriminated oneOf
+func variantSamples(schema, variant Schema, samples []interface{}) []interface{} {
+	if schema.Discriminator == nil {
+		return samples
+	}
+
+	field := schema.Discriminator.PropertyName
+	enum := variant.Properties[field].Enum
+	if len(enum) != 1 {
+		return samples
+	}
+
+	var matching []interface{}
+	for _, sample := range samples {
+		if obj, ok := sample.(map[string]interface{}); ok && obj[field] == enum[0] {
+			matching = append(matching, sample)
+		}
+	}
+	return matching
+}
@@ -6,20 +6,59 @@ import (
 
 // SchemaMerger merges multiple schemas into a single comprehensive schema
 type SchemaMerger struct {
-	schemas map[string][]Schema // path+method -> schemas
+	schemas    map[string][]Schema        // path+method -> schemas
+	hashes     map[string]map[string]bool // path+method -> structural hashes of the schemas added
+	interner   *SchemaInterner
+	maxSchemas int                   // distinct schemas kept per path+method, 0 for all
+	sampled    map[string]*Reservoir // path+method -> slots of the kept schemas, when capped
 }
 
 // NewSchemaMerger creates a new schema merger
 func NewSchemaMerger() *SchemaMerger {
 	return &SchemaMerger{
-		schemas: make(map[string][]Schema),
+		schemas:  make(map[string][]Schema),
+		hashes:   make(map[string]map[string]bool),
+		interner: NewSchemaInterner(),
+		sampled:  make(map[string]*Reservoir),
 	}
 }
 
-// AddSchema adds a schema to the merger for the given path and method
+// SetMaxSchemas caps the distinct schemas kept per path and method, keeping a uniform random
+// sample of them beyond the cap, so that endpoints with very varied bodies merge quickly. A
+// cap of 0 or less keeps all of them.
+func (m *SchemaMerger) SetMaxSchemas(max int) {
+	m.maxSchemas = max
+}
+
+// AddSchema adds a schema to the merger for the given path and method. Schemas are interned, and
+// a schema identical to one added before for the same path and method is only kept once, as
+// merging it again wouldn't change the result.
 func (m *SchemaMerger) AddSchema(path, method string, schema Schema) {
 	key := path + ":" + method
-	m.schemas[key] = append(m.schemas[key], schema)
+	schema, hash := m.interner.Intern(schema)
+	if m.hashes[key] == nil {
+		m.hashes[key] = make(map[string]bool)
+	}
+	if m.hashes[key][hash] {
+		return
+	}
+	m.hashes[key][hash] = true
+	if m.maxSchemas <= 0 {
+		m.schemas[key] = append(m.schemas[key], schema)
+		return
+	}
+
+	reservoir, ok := m.sampled[key]
+	if !ok {
+		reservoir = NewReservoir(m.maxSchemas)
+		m.sampled[key] = reservoir
+	}
+	switch i := reservoir.Offer(); {
+	case i == len(m.schemas[key]):
+		m.schemas[key] = append(m.schemas[key], schema)
+	case i >= 0:
+		m.schemas[key][i] = schema
+	}
 }
 
 // MergeSchemas merges all schemas for the given path and method
@@ -30,10 +69,20 @@ func (m *SchemaMerger) MergeSchemas(path, method string) Schema {
 		return Schema{}
 	}
 
+	return MergeSchemaList(schemas)
+}
+
+// MergeSchemaList merges a non-empty list of schemas into one. Polymorphic objects that
+// share a discriminator property are merged into a oneOf rather than a superset object.
+func MergeSchemaList(schemas []Schema) Schema {
 	if len(schemas) == 1 {
 		return schemas[0]
 	}
 
+	if merged, ok := mergeVariants(schemas); ok {
+		return merged
+	}
+
 	// Use the first schema as a base
 	result := schemas[0]
 
@@ -47,6 +96,14 @@ func (m *SchemaMerger) MergeSchemas(path, method string) Schema {
 
 // mergeSchema merges two schemas into one
 func mergeSchema(a, b Schema) Schema {
+	// Polymorphic objects become (or extend) a oneOf instead of a merged superset
+	if merged, ok := mergeVariants([]Schema{a, b}); ok {
+		return merged
+	}
+	if a.Discriminator != nil || b.Discriminator != nil {
+		return mergeFlattened(append(schemaVariants(a), schemaVariants(b)...))
+	}
+
 	// Start with a copy of the first schema
 	result := a
 
@@ -58,6 +115,11 @@ func mergeSchema(a, b Schema) Schema {
 	// Handle different schema types
 	switch a.Type {
 	case "object":
+		if b.Type == "object" {
+			if merged, ok := mergeMapSchemas(a, b); ok {
+				return merged
+			}
+		}
 		result.Properties = mergeObjectProperties(a.Properties, b.Properties)
 		result.Required = mergeRequired(a.Required, b.Required)
 	case "array":
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"sort"
 	"testing"
 
@@ -754,3 +755,42 @@ func TestAreSchemasEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestSchemaMergerMaxSchemas(t *testing.T) {
+	merger := NewSchemaMerger()
+	merger.SetMaxSchemas(3)
+	for i := 0; i < 50; i++ {
+		merger.AddSchema("/users", "GET", Schema{
+			Type:       "object",
+			Properties: map[string]Schema{fmt.Sprintf("field%d", i): {Type: "string"}},
+		})
+	}
+
+	// Only a sample of the distinct schemas is merged
+	assert.Len(t, merger.schemas["/users:GET"], 3)
+	assert.Len(t, merger.MergeSchemas("/users", "GET").Properties, 3)
+}
+
+func BenchmarkMergeSchemas(b *testing.B) {
+	// Bodies of one endpoint that differ in which optional fields they carry
+	bodies := make([][]byte, 200)
+	for i := range bodies {
+		body := fmt.Sprintf(`{"id":%d,"name":"User","email":"user@example.com","roles":["admin"],"address":{"city":"Berlin","zip":"10115"}`, i)
+		if i%3 == 0 {
+			body += `,"phone":"+49301234567"`
+		}
+		if i%5 == 0 {
+			body += `,"manager":{"id":1,"name":"Boss"}`
+		}
+		bodies[i] = []byte(body + "}")
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		merger := NewSchemaMerger()
+		for _, body := range bodies {
+			merger.AddSchema("/users/{id}", "GET", parseJSONSchema(body))
+		}
+		merger.MergeSchemas("/users/{id}", "GET")
+	}
+}
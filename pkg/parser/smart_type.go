@@ -10,7 +10,7 @@ import (
 // TypeInferrer analyzes values to determine their types more intelligently
 type TypeInferrer struct {
 	typePatterns map[string]*regexp.Regexp
-	samples      map[string][]interface{}
+	samples      map[string]*Reservoir
 	maxSamples   int
 }
 
@@ -21,20 +21,26 @@ func NewTypeInferrer(maxSamples int) *TypeInferrer {
 	}
 
 	t := &TypeInferrer{
-		samples:    make(map[string][]interface{}),
+		samples:    make(map[string]*Reservoir),
 		maxSamples: maxSamples,
 	}
 
 	// Initialize patterns for special types
 	t.typePatterns = map[string]*regexp.Regexp{
-		"uuid":     regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`),
-		"email":    regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`),
-		"uri":      regexp.MustCompile(`^(https?|ftp)://[^\s/$.?#].[^\s]*$`),
-		"date":     regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`),
-		"time":     regexp.MustCompile(`^\d{2}:\d{2}(:\d{2})?(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`),
-		"datetime": regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}(:\d{2})?(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`),
-		"ipv4":     regexp.MustCompile(`^(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)$`),
-		"ipv6":     regexp.MustCompile(`^(([0-9a-fA-F]{1,4}:){7,7}[0-9a-fA-F]{1,4}|([0-9a-fA-F]{1,4}:){1,7}:|([0-9a-fA-F]{1,4}:){1,6}:[0-9a-fA-F]{1,4}|([0-9a-fA-F]{1,4}:){1,5}(:[0-9a-fA-F]{1,4}){1,2}|([0-9a-fA-F]{1,4}:){1,4}(:[0-9a-fA-F]{1,4}){1,3}|([0-9a-fA-F]{1,4}:){1,3}(:[0-9a-fA-F]{1,4}){1,4}|([0-9a-fA-F]{1,4}:){1,2}(:[0-9a-fA-F]{1,4}){1,5}|[0-9a-fA-F]{1,4}:((:[0-9a-fA-F]{1,4}){1,6})|:((:[0-9a-fA-F]{1,4}){1,7}|:)|fe80:(:[0-9a-fA-F]{0,4}){0,4}%[0-9a-zA-Z]{1,}|::(ffff(:0{1,4}){0,1}:){0,1}((25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])\.){3,3}(25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])|([0-9a-fA-F]{1,4}:){1,4}:((25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])\.){3,3}(25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9]))$`),
+		"uuid":          regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`),
+		"email":         regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`),
+		"uri":           regexp.MustCompile(`^(https?|ftp)://[^\s/$.?#].[^\s]*$`),
+		"date":          regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`),
+		"time":          regexp.MustCompile(`^\d{2}:\d{2}(:\d{2})?(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`),
+		"datetime":      regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}(:\d{2})?(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`),
+		"ipv4":          regexp.MustCompile(`^(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)$`),
+		"ipv6":          regexp.MustCompile(`^(([0-9a-fA-F]{1,4}:){7,7}[0-9a-fA-F]{1,4}|([0-9a-fA-F]{1,4}:){1,7}:|([0-9a-fA-F]{1,4}:){1,6}:[0-9a-fA-F]{1,4}|([0-9a-fA-F]{1,4}:){1,5}(:[0-9a-fA-F]{1,4}){1,2}|([0-9a-fA-F]{1,4}:){1,4}(:[0-9a-fA-F]{1,4}){1,3}|([0-9a-fA-F]{1,4}:){1,3}(:[0-9a-fA-F]{1,4}){1,4}|([0-9a-fA-F]{1,4}:){1,2}(:[0-9a-fA-F]{1,4}){1,5}|[0-9a-fA-F]{1,4}:((:[0-9a-fA-F]{1,4}){1,6})|:((:[0-9a-fA-F]{1,4}){1,7}|:)|fe80:(:[0-9a-fA-F]{0,4}){0,4}%[0-9a-zA-Z]{1,}|::(ffff(:0{1,4}){0,1}:){0,1}((25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])\.){3,3}(25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])|([0-9a-fA-F]{1,4}:){1,4}:((25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])\.){3,3}(25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9]))$`),
+		"jwt":           regexp.MustCompile(`^eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*$`),
+		"phone":         regexp.MustCompile(`^\+[1-9]\d{6,14}$`), // E.164
+		"hostname":      regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,63}$`),
+		"country-code":  regexp.MustCompile(`^[A-Z]{2}$`), // ISO 3166-1 alpha-2
+		"currency-code": regexp.MustCompile(`^[A-Z]{3}$`), // ISO 4217
+		"byte":          regexp.MustCompile(`^([A-Za-z0-9+/]{4})+([A-Za-z0-9+/]{2}==|[A-Za-z0-9+/]{3}=)?$`),
 	}
 
 	return t
@@ -47,23 +53,24 @@ func (t *TypeInferrer) AddSample(path string, value interface{}) {
 		return
 	}
 
-	// Limit the number of samples we collect
-	if samples, exists := t.samples[path]; exists && len(samples) >= t.maxSamples {
-		return
+	// Limit the number of samples we collect to a random sample of every field's values
+	reservoir, exists := t.samples[path]
+	if !exists {
+		reservoir = NewReservoir(t.maxSamples)
+		t.samples[path] = reservoir
 	}
-
-	t.samples[path] = append(t.samples[path], value)
+	reservoir.Add(value)
 }
 
 // ResetSamples clears all collected samples
 func (t *TypeInferrer) ResetSamples() {
-	t.samples = make(map[string][]interface{})
+	t.samples = make(map[string]*Reservoir)
 }
 
 // InferSchema generates a refined schema based on collected samples
 func (t *TypeInferrer) InferSchema(path string) *Schema {
-	samples, exists := t.samples[path]
-	if !exists || len(samples) == 0 {
+	samples := t.samples[path].Values()
+	if len(samples) == 0 {
 		return nil
 	}
 
@@ -167,11 +174,16 @@ func (t *TypeInferrer) inferType(value interface{}) *Schema {
 
 // inferStringFormat tries to determine if a string matches a known format
 func (t *TypeInferrer) inferStringFormat(value string) string {
-	// Try all patterns
-	for format, pattern := range t.typePatterns {
-		if pattern.MatchString(value) {
-			return format
+	// Try the patterns from the most to the least specific
+	for _, format := range stringFormatOrder {
+		pattern, ok := t.typePatterns[format]
+		if !ok || !pattern.MatchString(value) {
+			continue
 		}
+		if validate, ok := stringFormatValidators[format]; ok && !validate(value) {
+			continue
+		}
+		return format
 	}
 
 	// Additional checks for formats without simple regex
@@ -197,7 +209,7 @@ func (t *TypeInferrer) inferStringFormat(value string) string {
 
 // handleMixedTypes deals with cases where samples have different types
 func (t *TypeInferrer) handleMixedTypes(path string) *Schema {
-	samples := t.samples[path]
+	samples := t.samples[path].Values()
 
 	// Try to find a common type that works for all
 	nullCount := 0
@@ -304,7 +316,7 @@ func (t *TypeInferrer) inferObjectProperties(path string) map[string]Schema {
 	propCounts := make(map[string]int)
 
 	// Find all possible properties across all object samples
-	for _, sample := range t.samples[path] {
+	for _, sample := range t.samples[path].Values() {
 		if obj, ok := sample.(map[string]interface{}); ok {
 			for propName, propValue := range obj {
 				propCounts[propName]++
@@ -323,7 +335,7 @@ func (t *TypeInferrer) inferObjectProperties(path string) map[string]Schema {
 
 	// Count total object samples
 	objectCount := 0
-	for _, sample := range t.samples[path] {
+	for _, sample := range t.samples[path].Values() {
 		if _, ok := sample.(map[string]interface{}); ok {
 			objectCount++
 		}
@@ -352,7 +364,7 @@ func (t *TypeInferrer) inferArrayItems(path string) *Schema {
 	// Collect all array items
 	var allItems []interface{}
 
-	for _, sample := range t.samples[path] {
+	for _, sample := range t.samples[path].Values() {
 		if arr, ok := sample.([]interface{}); ok {
 			for _, item := range arr {
 				// Add each item as a sample
@@ -433,29 +445,7 @@ func (t *TypeInferrer) enhanceStringSchema(schema *Schema, samples []interface{}
 // enhanceNumberSchema adds min, max, etc. for number schemas
 func (t *TypeInferrer) enhanceNumberSchema(schema *Schema, samples []interface{}) *Schema {
 	// If we have enough numeric samples, try to determine ranges
-	if len(samples) >= 3 {
-		var min, max float64
-		var allIntegers = true
-
-		// Initialize with first value
-		if num, ok := samples[0].(float64); ok {
-			min, max = num, num
-			allIntegers = allIntegers && (num == float64(int64(num)))
-		}
-
-		// Find min/max and check if all values are integers
-		for _, s := range samples[1:] {
-			if num, ok := s.(float64); ok {
-				if num < min {
-					min = num
-				}
-				if num > max {
-					max = num
-				}
-				allIntegers = allIntegers && (num == float64(int64(num)))
-			}
-		}
-
+	if min, max, allIntegers, ok := numericRange(samples); ok {
 		// Set format based on range and pattern
 		if allIntegers {
 			schema.Type = "integer"
@@ -551,6 +541,11 @@ func ApplyTypeInference(schema Schema, samples []interface{}, maxSamples int) Sc
 		result.Enum = improved.Enum
 	}
 
+	// Maps and polymorphic schemas are already more precise than a property list
+	if result.AdditionalProperties != nil || len(result.OneOf) > 0 {
+		return result
+	}
+
 	// For objects, merge properties
 	if result.Type == "object" && improved.Type == "object" {
 		if result.Properties == nil {
@@ -607,8 +602,8 @@ func mergeSchemaWithImprovement(original, improved Schema) Schema {
 		result.Example = improved.Example
 	}
 
-	// Merge object properties
-	if result.Type == "object" && improved.Type == "object" {
+	// Merge object properties, unless the original is a map or polymorphic
+	if result.Type == "object" && improved.Type == "object" && result.AdditionalProperties == nil && len(result.OneOf) == 0 {
 		if result.Properties == nil {
 			result.Properties = improved.Properties
 		} else if improved.Properties != nil {
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"strings"
+)
+
+// Unix timestamp formats reported through the x-format extension
+const (
+	UnixTimestampFormat   = "unix-timestamp"    // seconds since the epoch
+	UnixTimestampMsFormat = "unix-timestamp-ms" // milliseconds since the epoch
+)
+
+// Plausible epoch ranges: 2000-01-01 to 2100-01-01
+const (
+	minEpochSeconds = 946684800
+	maxEpochSeconds = 4102444800
+)
+
+// timestampNameSuffixes mark integer fields that hold times, e.g. created_at, expiryTime
+var timestampNameSuffixes = []string{"_at", "_time", "_ts", "_on", "at", "time", "timestamp", "epoch"}
+
+// AnnotateTimestamps marks integer fields that hold Unix timestamps with format int64 and an
+// x-format extension of unix-timestamp or unix-timestamp-ms. Fields are recognized by name
+// (created_at, updatedAt, expiry_time) or, when there are enough literal samples, by all
+// values falling in the plausible epoch range in seconds or milliseconds.
+func AnnotateTimestamps(schema Schema, samples []interface{}) Schema {
+	return walkSchemaSamples(schema, samples, func(name string, schema *Schema, samples []interface{}) {
+		if schema.Type != "integer" || name == "" {
+			return
+		}
+
+		format := ""
+		if unit, ok := epochUnit(samples); ok && !isIDName(name) {
+			format = unit
+		} else if isTimestampName(name) {
+			format = UnixTimestampFormat
+			if words := SplitWords(name); isMillisWord(words[len(words)-1]) {
+				format = UnixTimestampMsFormat
+			}
+		}
+		if format == "" {
+			return
+		}
+
+		schema.Format = "int64"
+		setExtension(schema, "x-format", format)
+		if schema.Description == "" {
+			if format == UnixTimestampMsFormat {
+				schema.Description = "Unix timestamp in milliseconds"
+			} else {
+				schema.Description = "Unix timestamp in seconds"
+			}
+		}
+	})
+}
+
+// isTimestampName checks whether a field name denotes a point in time
+func isTimestampName(name string) bool {
+	words := SplitWords(name)
+	if len(words) == 0 {
+		return false
+	}
+
+	// Ignore unit suffixes, e.g. created_at_ms
+	if isMillisWord(words[len(words)-1]) && len(words) > 1 {
+		words = words[:len(words)-1]
+	}
+
+	joined := strings.ToLower(strings.Join(words, "_"))
+	for _, suffix := range timestampNameSuffixes {
+		if joined == suffix || strings.HasSuffix(joined, "_"+strings.TrimPrefix(suffix, "_")) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMillisWord checks whether a name segment is a milliseconds unit suffix
+func isMillisWord(word string) bool {
+	word = strings.ToLower(word)
+	return word == "ms" || word == "millis"
+}
+
+// isIDName checks whether a field name denotes an identifier, whose values can fall in the
+// epoch range by coincidence
+func isIDName(name string) bool {
+	words := SplitWords(name)
+	return len(words) > 0 && strings.EqualFold(words[len(words)-1], "id")
+}
+
+// epochUnit reports whether all literal samples are plausible epoch values, in seconds or in
+// milliseconds. It needs at least minConstraintSamples literal values.
+func epochUnit(samples []interface{}) (string, bool) {
+	min, max, allIntegers, ok := numericRange(samples)
+	if !ok || !allIntegers {
+		return "", false
+	}
+
+	switch {
+	case min >= minEpochSeconds && max <= maxEpochSeconds:
+		return UnixTimestampFormat, true
+	case min >= minEpochSeconds*1000 && max <= maxEpochSeconds*1000:
+		return UnixTimestampMsFormat, true
+	}
+	return "", false
+}
+
+// setExtension sets a vendor extension on a schema without modifying extension maps shared
+// with copies of the schema
+func setExtension(schema *Schema, key string, value interface{}) {
+	extensions := make(map[string]interface{}, len(schema.Extensions)+1)
+	for k, v := range schema.Extensions {
+		extensions[k] = v
+	}
+	extensions[key] = value
+	schema.Extensions = extensions
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTimestampName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "created_at", expected: true},
+		{name: "updatedAt", expected: true},
+		{name: "expiry_time", expected: true},
+		{name: "last_seen_ts", expected: true},
+		{name: "timestamp", expected: true},
+		{name: "sent_at_ms", expected: true},
+		{name: "createdAtMs", expected: true},
+		{name: "count", expected: false},
+		{name: "format", expected: false},
+		{name: "ms", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isTimestampName(tt.name))
+		})
+	}
+}
+
+func TestAnnotateTimestamps(t *testing.T) {
+	schema := Schema{
+		Type: "object",
+		Properties: map[string]Schema{
+			"created_at":   {Type: "integer"},
+			"sent_at_ms":   {Type: "integer"},
+			"expires":      {Type: "integer"},
+			"last_login":   {Type: "integer"},
+			"user_id":      {Type: "integer"},
+			"count":        {Type: "integer"},
+			"published_at": {Type: "string", Format: "date-time"},
+		},
+	}
+	samples := []interface{}{
+		map[string]interface{}{"created_at": "__integer__", "expires": 1700000000.0, "last_login": 1700000000123.0, "user_id": 1700000001.0, "count": 3.0},
+		map[string]interface{}{"created_at": "__integer__", "expires": 1710000000.0, "last_login": 1710000000456.0, "user_id": 1700000002.0, "count": 7.0},
+		map[string]interface{}{"created_at": "__integer__", "expires": 1720000000.0, "last_login": 1720000000789.0, "user_id": 1700000003.0, "count": 12.0},
+	}
+
+	result := AnnotateTimestamps(schema, samples)
+
+	tests := []struct {
+		property string
+		format   interface{}
+	}{
+		{property: "created_at", format: UnixTimestampFormat},
+		{property: "sent_at_ms", format: UnixTimestampMsFormat},
+		{property: "expires", format: UnixTimestampFormat},
+		{property: "last_login", format: UnixTimestampMsFormat},
+		{property: "user_id", format: nil},
+		{property: "count", format: nil},
+		{property: "published_at", format: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.property, func(t *testing.T) {
+			prop := result.Properties[tt.property]
+			assert.Equal(t, tt.format, prop.Extensions["x-format"])
+			if tt.format != nil {
+				assert.Equal(t, "int64", prop.Format)
+				assert.NotEmpty(t, prop.Description)
+			}
+		})
+	}
+
+	// The input schema is left untouched
+	assert.Nil(t, schema.Properties["created_at"].Extensions)
+}
@@ -0,0 +1,216 @@
+// Package plugin runs external swagdoc plugins: executables named swagdoc-plugin-<name> on PATH,
+// written in any language, that process the captured transactions or the generated document, or
+// export the document in formats of their own.
+//
+// A plugin is run once per hook with the hook as its only argument. It reads a Request as JSON
+// from stdin and writes a Response as JSON to stdout; what it writes to stderr is shown when it
+// fails. The hooks are:
+//
+//   - describe: returns the plugin's Info, which lists the hooks it supports
+//   - transactions: receives the captured transactions and returns them modified, e.g. with
+//     custom sanitization applied or some of them left out
+//   - spec: receives the generated document and returns it modified
+//   - export: receives the generated document and returns the content of an output file
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Prefix is the prefix of the names of plugin executables
+const Prefix = "swagdoc-plugin-"
+
+// ProtocolVersion is sent with every request, and bumped whenever the protocol changes in a way
+// plugins have to know about
+const ProtocolVersion = 1
+
+// Hooks a plugin can support
+const (
+	HookDescribe     = "describe"
+	HookTransactions = "transactions"
+	HookSpec         = "spec"
+	HookExport       = "export"
+)
+
+// Plugin is a plugin executable
+type Plugin struct {
+	Name string // name without the prefix, e.g. pii for swagdoc-plugin-pii
+	Path string // path of the executable
+}
+
+// Info describes a plugin, in response to the describe hook
+type Info struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Hooks       []string `json:"hooks"`            // supported hooks besides describe
+	Output      string   `json:"output,omitempty"` // default output file of the export hook
+}
+
+// Request is what a plugin reads from stdin
+type Request struct {
+	Version      int                    `json:"version"`
+	Hook         string                 `json:"hook"`
+	Transactions []proxy.APITransaction `json:"transactions,omitempty"` // transactions hook
+	Spec         *openapi3.T            `json:"spec,omitempty"`         // spec and export hooks
+}
+
+// Response is what a plugin writes to stdout. Fields left out, or null, leave what they hold
+// unchanged, so a plugin may return an empty object, while an empty list of transactions leaves
+// all of them out.
+type Response struct {
+	Info         *Info                  `json:"info,omitempty"`   // describe hook
+	Transactions []proxy.APITransaction `json:"transactions"`     // transactions hook
+	Spec         *openapi3.T            `json:"spec,omitempty"`   // spec hook
+	Output       string                 `json:"output,omitempty"` // export hook
+	Error        string                 `json:"error,omitempty"`  // fails the hook with this message
+}
+
+// Supports checks whether the plugin supports a hook
+func (i Info) Supports(hook string) bool {
+	for _, supported := range i.Hooks {
+		if supported == hook {
+			return true
+		}
+	}
+	return false
+}
+
+// Discover finds the plugin executables on PATH, sorted by name. Like the shell, the first of
+// several executables of the same name wins.
+func Discover() []Plugin {
+	seen := make(map[string]bool)
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || seen[name] || !isExecutable(filepath.Join(dir, entry.Name())) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: filepath.Join(dir, entry.Name())})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// Find returns the plugin of a name, e.g. pii for swagdoc-plugin-pii, from PATH
+func Find(name string) (Plugin, error) {
+	path, err := exec.LookPath(Prefix + name)
+	if err != nil {
+		return Plugin{}, fmt.Errorf("plugin %s not found: no %s%s executable on PATH", name, Prefix, name)
+	}
+	return Plugin{Name: name, Path: path}, nil
+}
+
+// pluginName returns the plugin name of an executable's file name
+func pluginName(file string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		file = strings.TrimSuffix(strings.ToLower(file), ".exe")
+	}
+	name := strings.TrimPrefix(file, Prefix)
+	return name, name != file && name != ""
+}
+
+// isExecutable checks whether a path is a file that can be executed
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
+
+// Describe asks the plugin which hooks it supports
+func (p Plugin) Describe(ctx context.Context) (Info, error) {
+	resp, err := p.call(ctx, Request{Hook: HookDescribe})
+	if err != nil {
+		return Info{}, err
+	}
+	if resp.Info == nil {
+		return Info{}, fmt.Errorf("plugin %s returned no info", p.Name)
+	}
+	return *resp.Info, nil
+}
+
+// ProcessTransactions passes the transactions to the plugin and returns the ones it returned
+func (p Plugin) ProcessTransactions(ctx context.Context, transactions []proxy.APITransaction) ([]proxy.APITransaction, error) {
+	resp, err := p.call(ctx, Request{Hook: HookTransactions, Transactions: transactions})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Transactions == nil {
+		return transactions, nil
+	}
+	return resp.Transactions, nil
+}
+
+// ProcessSpec passes the document to the plugin and returns the document it returned
+func (p Plugin) ProcessSpec(ctx context.Context, spec *openapi3.T) (*openapi3.T, error) {
+	resp, err := p.call(ctx, Request{Hook: HookSpec, Spec: spec})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Spec == nil {
+		return spec, nil
+	}
+	return resp.Spec, nil
+}
+
+// Export passes the document to the plugin and returns the content of the output file it
+// rendered
+func (p Plugin) Export(ctx context.Context, spec *openapi3.T) ([]byte, error) {
+	resp, err := p.call(ctx, Request{Hook: HookExport, Spec: spec})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(resp.Output), nil
+}
+
+// call runs the plugin for a hook, writing the request to its stdin and reading the response
+// from its stdout
+func (p Plugin) call(ctx context.Context, req Request) (Response, error) {
+	req.Version = ProtocolVersion
+	input, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to encode the request to plugin %s: %v", p.Name, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path, req.Hook)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return Response{}, fmt.Errorf("plugin %s failed on %s: %v: %s", p.Name, req.Hook, err, message)
+		}
+		return Response{}, fmt.Errorf("plugin %s failed on %s: %v", p.Name, req.Hook, err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return Response{}, fmt.Errorf("plugin %s returned an invalid response to %s: %v", p.Name, req.Hook, err)
+	}
+	if resp.Error != "" {
+		return Response{}, fmt.Errorf("plugin %s failed on %s: %s", p.Name, req.Hook, resp.Error)
+	}
+	return resp, nil
+}
@@ -0,0 +1,202 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// TestMain makes the test binary act as a plugin when SWAGDOC_TEST_PLUGIN is set, so that the
+// tests can run it as a subprocess
+func TestMain(m *testing.M) {
+	if behavior := os.Getenv("SWAGDOC_TEST_PLUGIN"); behavior != "" {
+		os.Exit(runTestPlugin(behavior))
+	}
+	os.Exit(m.Run())
+}
+
+// runTestPlugin answers one request the way a plugin would
+func runTestPlugin(behavior string) int {
+	var req Request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(os.Args) != 2 || os.Args[1] != req.Hook || req.Version != ProtocolVersion {
+		fmt.Fprintf(os.Stderr, "unexpected invocation %v of version %d\n", os.Args[1:], req.Version)
+		return 1
+	}
+
+	var resp Response
+	switch behavior {
+	case "crash":
+		fmt.Fprintln(os.Stderr, "out of coffee")
+		return 3
+	case "refuse":
+		resp.Error = "refusing to " + req.Hook
+	case "noop":
+	default:
+		switch req.Hook {
+		case HookDescribe:
+			resp.Info = &Info{Name: "test", Hooks: []string{HookTransactions, HookSpec, HookExport}, Output: "api.txt"}
+		case HookTransactions:
+			resp.Transactions = []proxy.APITransaction{}
+			for _, tx := range req.Transactions {
+				if tx.Request.Path == "/internal" {
+					continue
+				}
+				tx.Request.Headers.Set("X-Sanitized", "true")
+				resp.Transactions = append(resp.Transactions, tx)
+			}
+		case HookSpec:
+			resp.Spec = req.Spec
+			resp.Spec.Info.Title = strings.ToUpper(resp.Spec.Info.Title)
+		case HookExport:
+			resp.Output = "API " + req.Spec.Info.Title
+		}
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// testPlugin returns the test binary as a plugin with a behavior
+func testPlugin(t *testing.T, behavior string) Plugin {
+	t.Setenv("SWAGDOC_TEST_PLUGIN", behavior)
+	return Plugin{Name: "test", Path: os.Args[0]}
+}
+
+func testSpec() *openapi3.T {
+	return &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "Orders", Version: "1.0.0"},
+		Paths:   openapi3.NewPaths(),
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	info, err := testPlugin(t, "default").Describe(context.Background())
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	if !info.Supports(HookSpec) || info.Supports("unknown") {
+		t.Errorf("Unexpected hooks %v", info.Hooks)
+	}
+	if info.Output != "api.txt" {
+		t.Errorf("Expected output api.txt, got %q", info.Output)
+	}
+
+	if _, err := testPlugin(t, "noop").Describe(context.Background()); err == nil {
+		t.Error("Expected an error for a plugin returning no info")
+	}
+}
+
+func TestProcessTransactions(t *testing.T) {
+	transactions := []proxy.APITransaction{
+		{Request: proxy.RequestData{Method: "GET", Path: "/orders", Headers: map[string][]string{}}},
+		{Request: proxy.RequestData{Method: "GET", Path: "/internal", Headers: map[string][]string{}}},
+	}
+
+	processed, err := testPlugin(t, "default").ProcessTransactions(context.Background(), transactions)
+	if err != nil {
+		t.Fatalf("ProcessTransactions failed: %v", err)
+	}
+	if len(processed) != 1 || processed[0].Request.Path != "/orders" {
+		t.Fatalf("Expected only /orders to be kept, got %+v", processed)
+	}
+	if processed[0].Request.Headers.Get("X-Sanitized") != "true" {
+		t.Errorf("Expected the plugin's modifications, got headers %v", processed[0].Request.Headers)
+	}
+
+	// A response without transactions leaves them unchanged
+	unchanged, err := testPlugin(t, "noop").ProcessTransactions(context.Background(), transactions)
+	if err != nil {
+		t.Fatalf("ProcessTransactions failed: %v", err)
+	}
+	if len(unchanged) != 2 {
+		t.Errorf("Expected the transactions unchanged, got %d", len(unchanged))
+	}
+}
+
+func TestProcessSpecAndExport(t *testing.T) {
+	spec, err := testPlugin(t, "default").ProcessSpec(context.Background(), testSpec())
+	if err != nil {
+		t.Fatalf("ProcessSpec failed: %v", err)
+	}
+	if spec.Info.Title != "ORDERS" {
+		t.Errorf("Expected the title modified by the plugin, got %q", spec.Info.Title)
+	}
+
+	output, err := testPlugin(t, "default").Export(context.Background(), testSpec())
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if string(output) != "API Orders" {
+		t.Errorf("Expected the rendered output, got %q", output)
+	}
+}
+
+func TestPluginErrors(t *testing.T) {
+	_, err := testPlugin(t, "crash").ProcessSpec(context.Background(), testSpec())
+	if err == nil || !strings.Contains(err.Error(), "out of coffee") {
+		t.Errorf("Expected the plugin's stderr in the error, got %v", err)
+	}
+
+	_, err = testPlugin(t, "refuse").ProcessSpec(context.Background(), testSpec())
+	if err == nil || !strings.Contains(err.Error(), "refusing to spec") {
+		t.Errorf("Expected the plugin's error message, got %v", err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executable bits don't apply on Windows")
+	}
+
+	first, second := t.TempDir(), t.TempDir()
+	for _, file := range []struct {
+		dir  string
+		name string
+		mode os.FileMode
+	}{
+		{first, "swagdoc-plugin-pii", 0755},
+		{first, "swagdoc-plugin-notes", 0644}, // not executable
+		{first, "swagdoc-other", 0755},
+		{second, "swagdoc-plugin-pii", 0755}, // shadowed by the first
+		{second, "swagdoc-plugin-asciidoc", 0755},
+	} {
+		if err := os.WriteFile(filepath.Join(file.dir, file.name), []byte("#!/bin/sh\n"), file.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+
+	plugins := Discover()
+	if len(plugins) != 2 {
+		t.Fatalf("Expected 2 plugins, got %+v", plugins)
+	}
+	if plugins[0].Name != "asciidoc" || plugins[1].Name != "pii" {
+		t.Errorf("Expected asciidoc and pii sorted by name, got %+v", plugins)
+	}
+	if plugins[1].Path != filepath.Join(first, "swagdoc-plugin-pii") {
+		t.Errorf("Expected the first pii on PATH, got %s", plugins[1].Path)
+	}
+
+	found, err := Find("asciidoc")
+	if err != nil || found.Path != filepath.Join(second, "swagdoc-plugin-asciidoc") {
+		t.Errorf("Expected to find asciidoc, got %+v, %v", found, err)
+	}
+	if _, err := Find("missing"); err == nil {
+		t.Error("Expected an error for a missing plugin")
+	}
+}
@@ -0,0 +1,109 @@
+package proxy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sessionFilePattern matches the names of the session files NewFileStorage creates
+var sessionFilePattern = regexp.MustCompile(`^session-\d{8}-\d{6}\.json$`)
+
+// SessionFiles returns the session files swagdoc created in a data directory, sorted by name
+// and so by the time they were started. Other files are left out, even JSON ones.
+func SessionFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && sessionFilePattern.MatchString(entry.Name()) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// SessionStarts returns the times the sessions of a data directory were started, oldest first,
+// as recorded in the names of their session files in local time
+func SessionStarts(dir string) ([]time.Time, error) {
+	files, err := SessionFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	starts := make([]time.Time, 0, len(files))
+	for _, file := range files {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), "session-"), ".json")
+		start, err := time.ParseInLocation("20060102-150405", stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		starts = append(starts, start)
+	}
+	return starts, nil
+}
+
+// CheckDataDir returns an error unless a directory looks like a swagdoc data directory: it
+// holds at least one session file and no subdirectories, which a project or home directory
+// passed by mistake would
+func CheckDataDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	sessions := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			return fmt.Errorf("%s contains the directory %s, which swagdoc never creates", dir, entry.Name())
+		}
+		if entry.Type().IsRegular() && sessionFilePattern.MatchString(entry.Name()) {
+			sessions++
+		}
+	}
+	if sessions == 0 {
+		return fmt.Errorf("%s contains no session files", dir)
+	}
+	return nil
+}
+
+// RemoveSessionFiles deletes the session files of a data directory with their index files, and
+// the directory itself once nothing else is left in it. It refuses directories that
+// CheckDataDir rejects, and returns the session files deleted.
+func RemoveSessionFiles(dir string) ([]string, error) {
+	if err := CheckDataDir(dir); err != nil {
+		return nil, err
+	}
+
+	files, err := SessionFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return removed, err
+		}
+		removed = append(removed, file)
+		if err := os.Remove(indexFile(file)); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+	}
+
+	// Files that aren't ours keep the directory
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+		if err := os.Remove(dir); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
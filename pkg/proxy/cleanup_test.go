@@ -0,0 +1,128 @@
+package proxy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("[]"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+}
+
+func TestSessionFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, "session-20240305-143015.json", "session-20240301-090000.json", "swagger.json", "session-notes.json")
+
+	files, err := SessionFiles(dir)
+	if err != nil {
+		t.Fatalf("Failed to list session files: %v", err)
+	}
+	expected := []string{
+		filepath.Join(dir, "session-20240301-090000.json"),
+		filepath.Join(dir, "session-20240305-143015.json"),
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, files)
+	}
+	for i := range expected {
+		if files[i] != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], files[i])
+		}
+	}
+}
+
+func TestSessionStarts(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, "session-20240305-143015.json", "session-20240301-090000.json", "session-20241399-000000.json", "swagger.json")
+
+	starts, err := SessionStarts(dir)
+	if err != nil {
+		t.Fatalf("Failed to list session starts: %v", err)
+	}
+	expected := []time.Time{
+		time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local),
+		time.Date(2024, 3, 5, 14, 30, 15, 0, time.Local),
+	}
+	if len(starts) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, starts)
+	}
+	for i := range expected {
+		if !starts[i].Equal(expected[i]) {
+			t.Errorf("Expected %v, got %v", expected[i], starts[i])
+		}
+	}
+}
+
+func TestCheckDataDir(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFiles(t, dataDir, "session-20240305-143015.json")
+	if err := CheckDataDir(dataDir); err != nil {
+		t.Errorf("Expected a data directory, got %v", err)
+	}
+
+	emptyDir := t.TempDir()
+	writeTestFiles(t, emptyDir, "main.go", "go.json")
+	if err := CheckDataDir(emptyDir); err == nil {
+		t.Error("Expected a directory without session files to be rejected")
+	}
+
+	projectDir := t.TempDir()
+	writeTestFiles(t, projectDir, "session-20240305-143015.json")
+	if err := os.Mkdir(filepath.Join(projectDir, "src"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := CheckDataDir(projectDir); err == nil {
+		t.Error("Expected a directory with subdirectories to be rejected")
+	}
+
+	if err := CheckDataDir(filepath.Join(dataDir, "missing")); err == nil {
+		t.Error("Expected a missing directory to be rejected")
+	}
+}
+
+func TestRemoveSessionFiles(t *testing.T) {
+	// Only session files are deleted, and the directory is kept for the other files
+	dir := t.TempDir()
+	writeTestFiles(t, dir, "session-20240305-143015.json", "session-20240301-090000.json", "notes.json")
+
+	removed, err := RemoveSessionFiles(dir)
+	if err != nil {
+		t.Fatalf("Failed to remove session files: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("Expected 2 removed files, got %v", removed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.json")); err != nil {
+		t.Errorf("Expected notes.json to be kept: %v", err)
+	}
+
+	// A directory left empty is removed
+	dataDir := filepath.Join(t.TempDir(), "swagdoc-data")
+	if err := os.Mkdir(dataDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writeTestFiles(t, dataDir, "session-20240305-143015.json")
+	if _, err := RemoveSessionFiles(dataDir); err != nil {
+		t.Fatalf("Failed to remove session files: %v", err)
+	}
+	if _, err := os.Stat(dataDir); !os.IsNotExist(err) {
+		t.Errorf("Expected the empty data directory to be removed, got %v", err)
+	}
+
+	// Directories that don't look like data directories are left alone
+	projectDir := t.TempDir()
+	writeTestFiles(t, projectDir, "README.md")
+	if _, err := RemoveSessionFiles(projectDir); err == nil {
+		t.Error("Expected a directory without session files to be refused")
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "README.md")); err != nil {
+		t.Errorf("Expected README.md to be kept: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// BodyEncoding records how a captured body is stored, so that it can be decoded without guessing
+type BodyEncoding string
+
+const (
+	// BodyEncodingRaw is a body stored as captured, e.g. the placeholder of a text or binary body
+	BodyEncodingRaw BodyEncoding = "raw"
+	// BodyEncodingBase64 is a body stored as base64 text
+	BodyEncodingBase64 BodyEncoding = "base64"
+	// BodyEncodingSanitizedJSON is a JSON body with its values replaced by type placeholders
+	BodyEncodingSanitizedJSON BodyEncoding = "sanitized-json"
+)
+
+// sanitizedBodyEncoding returns the encoding of a body produced by sanitizeBody
+func sanitizedBodyEncoding(body []byte) BodyEncoding {
+	if len(body) > 0 && json.Valid(body) {
+		return BodyEncodingSanitizedJSON
+	}
+	return BodyEncodingRaw
+}
+
+// DecodedBody returns the request body decoded according to its encoding
+func (r RequestData) DecodedBody() ([]byte, error) {
+	return DecodeBody(r.Body, r.BodyEncoding)
+}
+
+// DecodedBody returns the response body decoded according to its encoding
+func (r ResponseData) DecodedBody() ([]byte, error) {
+	return DecodeBody(r.Body, r.BodyEncoding)
+}
+
+// DecodeBody decodes a stored body. Bodies captured before encodings were recorded have none;
+// they are decoded from base64 only when they are not JSON themselves and decode to JSON.
+func DecodeBody(body []byte, encoding BodyEncoding) ([]byte, error) {
+	switch encoding {
+	case BodyEncodingRaw, BodyEncodingSanitizedJSON:
+		return body, nil
+	case BodyEncodingBase64:
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(body)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 body: %v", err)
+		}
+		return decoded, nil
+	case "":
+		trimmed := bytes.TrimSpace(body)
+		if len(trimmed) == 0 || json.Valid(trimmed) {
+			return body, nil
+		}
+		if decoded, err := base64.StdEncoding.DecodeString(string(trimmed)); err == nil && json.Valid(decoded) {
+			return decoded, nil
+		}
+		return body, nil
+	default:
+		return nil, fmt.Errorf("unknown body encoding %q", encoding)
+	}
+}
@@ -0,0 +1,67 @@
+package proxy
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestDecodeBody(t *testing.T) {
+	object := `{"id":"__integer__"}`
+	array := `[{"id":"__integer__"}]`
+
+	tests := []struct {
+		name     string
+		body     string
+		encoding BodyEncoding
+		expected string
+		wantErr  bool
+	}{
+		{name: "sanitized json", body: object, encoding: BodyEncodingSanitizedJSON, expected: object},
+		{name: "raw placeholder", body: "__string__", encoding: BodyEncodingRaw, expected: "__string__"},
+		{name: "base64", body: base64.StdEncoding.EncodeToString([]byte("hello")), encoding: BodyEncodingBase64, expected: "hello"},
+		{name: "invalid base64", body: "not base64!", encoding: BodyEncodingBase64, wantErr: true},
+		{name: "unknown encoding", body: object, encoding: "gzip", wantErr: true},
+		{name: "legacy json", body: object, expected: object},
+		{name: "legacy base64 object", body: base64.StdEncoding.EncodeToString([]byte(object)), expected: object},
+		{name: "legacy base64 array", body: base64.StdEncoding.EncodeToString([]byte(array)), expected: array},
+		{name: "legacy base64 of text", body: base64.StdEncoding.EncodeToString([]byte("hello")), expected: base64.StdEncoding.EncodeToString([]byte("hello"))},
+		{name: "legacy placeholder", body: "__binary__", expected: "__binary__"},
+		{name: "empty", body: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeBody([]byte(tt.body), tt.encoding)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %q", string(decoded))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error decoding body: %v", err)
+			}
+			if string(decoded) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, string(decoded))
+			}
+		})
+	}
+}
+
+func TestSanitizedBodyEncoding(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected BodyEncoding
+	}{
+		{body: `{"id":"__integer__"}`, expected: BodyEncodingSanitizedJSON},
+		{body: "__string__", expected: BodyEncodingRaw},
+		{body: "id,name\n__integer__,__string__\n", expected: BodyEncodingRaw},
+		{body: "", expected: BodyEncodingRaw},
+	}
+
+	for _, tt := range tests {
+		if encoding := sanitizedBodyEncoding([]byte(tt.body)); encoding != tt.expected {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.body, encoding)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package proxy
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// indexVersion is bumped whenever the index format changes, so that index files written by
+// other versions of swagdoc are rebuilt rather than misread
+const indexVersion = 2
+
+// indexSuffix replaces the .json extension of a session file in the name of its index file,
+// which storage reads skip as they only read JSON files
+const indexSuffix = ".index"
+
+// IndexEntry records a stored transaction of a session file
+type IndexEntry struct {
+	File     string `json:"-"`        // session file
+	Endpoint string `json:"endpoint"` // "METHOD /path"
+	Hash     string `json:"hash"`     // fingerprint of everything documented about the transaction
+}
+
+// sessionIndex is the content of the index file of a session file
+type sessionIndex struct {
+	Version int          `json:"version"`
+	Size    int64        `json:"size"`    // size of the session file when it was indexed
+	ModTime time.Time    `json:"modTime"` // modification time of the session file when it was indexed
+	Entries []IndexEntry `json:"entries"`
+}
+
+// StorageIndex records the endpoints and fingerprints of the transactions of the session files
+// of a data directory, so that doctor can count duplicates without parsing the session files.
+// Reading transactions always goes through the session files themselves.
+type StorageIndex struct {
+	Entries []IndexEntry // in the order of the session files and of the transactions in them
+}
+
+// TransactionHash fingerprints what documentation is generated from: the endpoint, the status,
+// the content types, and the sanitized bodies, whose values are already replaced with type
+// placeholders. Transactions with the same hash document the same thing.
+func TransactionHash(transaction APITransaction) string {
+	hash := sha256.New()
+	for _, field := range []string{
+		transaction.Request.Method,
+		transaction.Request.Path,
+		fmt.Sprint(transaction.Response.StatusCode),
+		transaction.Request.Headers.Get("Content-Type"),
+		transaction.Response.Headers.Get("Content-Type"),
+		string(transaction.Request.Body),
+		string(transaction.Response.Body),
+	} {
+		fmt.Fprintf(hash, "%d:%s", len(field), field)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// transactionEndpoint returns the "METHOD /path" key of a transaction in the index
+func transactionEndpoint(transaction APITransaction) string {
+	return transaction.Request.Method + " " + transaction.Request.Path
+}
+
+// indexEntry returns the index entry of a transaction of a session file
+func indexEntry(sessionFile string, transaction APITransaction) IndexEntry {
+	return IndexEntry{
+		File:     sessionFile,
+		Endpoint: transactionEndpoint(transaction),
+		Hash:     TransactionHash(transaction),
+	}
+}
+
+// indexFile returns the name of the index file of a session file
+func indexFile(sessionFile string) string {
+	return strings.TrimSuffix(sessionFile, ".json") + indexSuffix
+}
+
+// LoadIndex returns the index of the session files of a data directory. Index files that are
+// missing or older than their session file, e.g. after it was edited by hand, are rebuilt from
+// the session file and written again when the directory is writable.
+func LoadIndex(dir string) (*StorageIndex, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &StorageIndex{}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		entries, err := sessionEntries(filepath.Join(dir, file.Name()))
+		if err != nil {
+			continue
+		}
+		index.Entries = append(index.Entries, entries...)
+	}
+	return index, nil
+}
+
+// sessionEntries returns the index entries of a session file, from its index file while that
+// is up to date
+func sessionEntries(sessionFile string) ([]IndexEntry, error) {
+	info, err := os.Stat(sessionFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var index sessionIndex
+	if data, err := os.ReadFile(indexFile(sessionFile)); err == nil && json.Unmarshal(data, &index) == nil &&
+		index.Version == indexVersion && index.Size == info.Size() && index.ModTime.Equal(info.ModTime()) {
+		for i := range index.Entries {
+			index.Entries[i].File = sessionFile
+		}
+		return index.Entries, nil
+	}
+
+	entries, err := scanSessionFile(sessionFile)
+	if err != nil {
+		return nil, err
+	}
+	// The index only saves work later, so a read-only directory is fine
+	_ = writeIndex(sessionFile, entries)
+	return entries, nil
+}
+
+// scanSessionFile indexes a session file by decoding it. Like Each, it stops at the first
+// invalid transaction.
+func scanSessionFile(sessionFile string) ([]IndexEntry, error) {
+	f, err := os.Open(sessionFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(reader)
+
+	var entries []IndexEntry
+	add := func() bool {
+		var transaction APITransaction
+		if err := decoder.Decode(&transaction); err != nil {
+			return false
+		}
+		entries = append(entries, indexEntry(sessionFile, transaction))
+		return true
+	}
+
+	// For backward compatibility - handle single transaction files
+	if first[0] != '[' {
+		add()
+		return entries, nil
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil
+	}
+	for decoder.More() && add() {
+	}
+	return entries, nil
+}
+
+// writeIndex writes the index file of a session file, recording the session file's current size
+// and modification time so that later edits are noticed
+func writeIndex(sessionFile string, entries []IndexEntry) error {
+	info, err := os.Stat(sessionFile)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(sessionIndex{
+		Version: indexVersion,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Entries: entries,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(indexFile(sessionFile), data, 0644)
+}
+
+// Duplicates returns how many transactions document the same as one stored before them
+func (idx *StorageIndex) Duplicates() int {
+	seen := make(map[string]bool)
+	duplicates := 0
+	for _, entry := range idx.Entries {
+		if seen[entry.Hash] {
+			duplicates++
+		}
+		seen[entry.Hash] = true
+	}
+	return duplicates
+}
@@ -0,0 +1,118 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func indexTestTransaction(method, path string, status int, body string) APITransaction {
+	return APITransaction{
+		Request: RequestData{Method: method, Path: path, Headers: http.Header{}},
+		Response: ResponseData{
+			StatusCode: status,
+			Headers:    http.Header{"Content-Type": []string{"application/json"}},
+			Body:       []byte(body),
+		},
+	}
+}
+
+func TestFileStorageIndex(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("NewFileStorage failed: %v", err)
+	}
+
+	transactions := []APITransaction{
+		indexTestTransaction("GET", "/users", 200, `[{"id":"__integer__"}]`),
+		indexTestTransaction("GET", "/users/1", 200, `{"id":"__integer__"}`),
+		indexTestTransaction("GET", "/users", 200, `[{"id":"__integer__"}]`),
+	}
+	if err := storage.Store(transactions[0]); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if err := storage.StoreAll(transactions[1:]); err != nil {
+		t.Fatalf("StoreAll failed: %v", err)
+	}
+
+	// The session file is laid out as before the index existed
+	data, err := os.ReadFile(storage.sessionFile)
+	if err != nil {
+		t.Fatalf("Failed to read session file: %v", err)
+	}
+	expected, _ := json.MarshalIndent(transactions, "", "  ")
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Expected the session file to match json.MarshalIndent, got:\n%s", data)
+	}
+	if _, err := os.Stat(indexFile(storage.sessionFile)); err != nil {
+		t.Errorf("Expected an index file next to the session file: %v", err)
+	}
+
+	index, err := LoadIndex(dir)
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+	if len(index.Entries) != 3 {
+		t.Fatalf("Expected 3 index entries, got %d", len(index.Entries))
+	}
+	var endpoints []string
+	for _, entry := range index.Entries {
+		if entry.File != storage.sessionFile {
+			t.Errorf("Expected entries of %s, got one of %s", storage.sessionFile, entry.File)
+		}
+		endpoints = append(endpoints, entry.Endpoint)
+	}
+	if !reflect.DeepEqual(endpoints, []string{"GET /users", "GET /users/1", "GET /users"}) {
+		t.Errorf("Unexpected endpoints %v", endpoints)
+	}
+	if index.Duplicates() != 1 {
+		t.Errorf("Expected 1 duplicate, got %d", index.Duplicates())
+	}
+
+	// Clearing the storage removes the index files too
+	if err := storage.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected an empty data directory, got %d files", len(files))
+	}
+}
+
+func TestLoadIndexRebuildsStaleIndexes(t *testing.T) {
+	dir := t.TempDir()
+	sessionFile := filepath.Join(dir, "session-20240301-090000.json")
+	write := func(transactions ...APITransaction) {
+		data, _ := json.MarshalIndent(transactions, "", "  ")
+		if err := os.WriteFile(sessionFile, data, 0644); err != nil {
+			t.Fatalf("Failed to write session file: %v", err)
+		}
+	}
+
+	// Session files written by older versions have no index yet
+	write(indexTestTransaction("GET", "/users", 200, `[]`))
+	index, err := LoadIndex(dir)
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+	if len(index.Entries) != 1 {
+		t.Fatalf("Expected 1 index entry, got %d", len(index.Entries))
+	}
+	if _, err := os.Stat(indexFile(sessionFile)); err != nil {
+		t.Errorf("Expected the index to be written: %v", err)
+	}
+
+	// Edits of the session file invalidate its index
+	write(indexTestTransaction("GET", "/users", 200, `[]`), indexTestTransaction("DELETE", "/users/1", 204, ``))
+	index, err = LoadIndex(dir)
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+	if len(index.Entries) != 2 || index.Entries[1].Endpoint != "DELETE /users/1" {
+		t.Errorf("Expected the rebuilt index to record DELETE /users/1, got %+v", index.Entries)
+	}
+}
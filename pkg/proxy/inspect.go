@@ -0,0 +1,89 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SessionFileReport describes a session file of a data directory
+type SessionFileReport struct {
+	Path         string
+	Transactions int
+	Err          error    // why the file could not be read, in which case it is skipped during generation
+	Malformed    []string // problems of individual transactions, e.g. "transaction 3: no request method"
+}
+
+// InspectSessionFiles reads every session file of a data directory the way GetAll does, and
+// reports the files that can't be read and the transactions that can't be documented, which
+// GetAll silently skips
+func InspectSessionFiles(dir string) ([]SessionFileReport, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []SessionFileReport
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		report := SessionFileReport{Path: filepath.Join(dir, file.Name())}
+		data, err := os.ReadFile(report.Path)
+		if err != nil {
+			report.Err = err
+			reports = append(reports, report)
+			continue
+		}
+
+		var transactions []APITransaction
+		if len(data) > 0 && data[0] == '[' {
+			err = json.Unmarshal(data, &transactions)
+		} else {
+			var transaction APITransaction
+			err = json.Unmarshal(data, &transaction)
+			transactions = []APITransaction{transaction}
+		}
+		if err != nil {
+			report.Err = fmt.Errorf("invalid JSON: %v", err)
+			reports = append(reports, report)
+			continue
+		}
+
+		report.Transactions = len(transactions)
+		for i, tx := range transactions {
+			for _, problem := range transactionProblems(tx) {
+				report.Malformed = append(report.Malformed, fmt.Sprintf("transaction %d: %s", i+1, problem))
+			}
+		}
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Path < reports[j].Path })
+	return reports, nil
+}
+
+// transactionProblems returns what keeps a transaction from being documented correctly
+func transactionProblems(tx APITransaction) []string {
+	var problems []string
+	if tx.Request.Method == "" {
+		problems = append(problems, "no request method")
+	}
+	if !strings.HasPrefix(tx.Request.Path, "/") {
+		problems = append(problems, fmt.Sprintf("request path %q does not start with /", tx.Request.Path))
+	}
+	if tx.Response.StatusCode < 100 || tx.Response.StatusCode > 599 {
+		problems = append(problems, fmt.Sprintf("invalid response status %d", tx.Response.StatusCode))
+	}
+	if _, err := tx.Request.DecodedBody(); err != nil {
+		problems = append(problems, fmt.Sprintf("request body: %v", err))
+	}
+	if _, err := tx.Response.DecodedBody(); err != nil {
+		problems = append(problems, fmt.Sprintf("response body: %v", err))
+	}
+	return problems
+}
@@ -0,0 +1,76 @@
+package proxy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInspectSessionFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	storage, err := NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	if err := storage.Store(APITransaction{
+		Request:  RequestData{Method: "GET", Path: "/users"},
+		Response: ResponseData{StatusCode: 200},
+	}); err != nil {
+		t.Fatalf("Failed to store transaction: %v", err)
+	}
+	if err := storage.Store(APITransaction{
+		Request:  RequestData{Path: "users"},
+		Response: ResponseData{Body: []byte("not base64"), BodyEncoding: BodyEncodingBase64},
+	}); err != nil {
+		t.Fatalf("Failed to store transaction: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("[{"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	reports, err := InspectSessionFiles(dir)
+	if err != nil {
+		t.Fatalf("Failed to inspect session files: %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 session files, got %d", len(reports))
+	}
+
+	broken := reports[0]
+	if filepath.Base(broken.Path) != "broken.json" || broken.Err == nil {
+		t.Errorf("Expected broken.json to be reported as unreadable, got %+v", broken)
+	}
+
+	session := reports[1]
+	if session.Err != nil {
+		t.Fatalf("Expected the session file to be readable, got %v", session.Err)
+	}
+	if session.Transactions != 2 {
+		t.Errorf("Expected 2 transactions, got %d", session.Transactions)
+	}
+
+	expected := []string{
+		"transaction 2: no request method",
+		`transaction 2: request path "users" does not start with /`,
+		"transaction 2: invalid response status 0",
+		"transaction 2: response body: invalid base64 body",
+	}
+	if len(session.Malformed) != len(expected) {
+		t.Fatalf("Expected %d problems, got %v", len(expected), session.Malformed)
+	}
+	for i, problem := range expected {
+		if !strings.HasPrefix(session.Malformed[i], problem) {
+			t.Errorf("Expected problem %q, got %q", problem, session.Malformed[i])
+		}
+	}
+
+	if _, err := InspectSessionFiles(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
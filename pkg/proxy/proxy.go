@@ -2,13 +2,21 @@ package proxy
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"mime"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/parnexcodes/swag-doc/pkg/logger"
@@ -16,20 +24,38 @@ import (
 
 // RequestData stores information about an HTTP request
 type RequestData struct {
-	Method      string
-	Path        string
-	QueryParams url.Values
-	Headers     http.Header
-	Body        []byte
-	Timestamp   time.Time
+	Method       string
+	Path         string
+	QueryParams  url.Values
+	Headers      http.Header
+	Body         []byte
+	BodyEncoding BodyEncoding `json:",omitempty"`
+	Timestamp    time.Time
+
+	// Annotations sent by the client as X-SwagDoc-* headers, by lower-cased name, e.g. summary
+	Annotations map[string]string `json:",omitempty"`
 }
 
+// AnnotationHeaderPrefix prefixes the headers that annotate a request for the documentation,
+// e.g. X-SwagDoc-Summary. They are stripped before the request is forwarded.
+const AnnotationHeaderPrefix = "X-Swagdoc-"
+
+// ScenarioAnnotation names the annotation that groups transactions under a scenario. An
+// X-SwagDoc-Scenario header starts a scenario that the following requests belong to as well,
+// and an empty one ends it.
+const ScenarioAnnotation = "scenario"
+
+// ScenarioPath is the control endpoint of the proxy that starts a scenario with PUT or POST and
+// a name query parameter, and ends it with DELETE. Its requests are not forwarded or captured.
+const ScenarioPath = "/__swagdoc/scenario"
+
 // ResponseData stores information about an HTTP response
 type ResponseData struct {
-	StatusCode int
-	Headers    http.Header
-	Body       []byte
-	Timestamp  time.Time
+	StatusCode   int
+	Headers      http.Header
+	Body         []byte
+	BodyEncoding BodyEncoding `json:",omitempty"`
+	Timestamp    time.Time
 }
 
 // APITransaction represents a complete API transaction (request + response)
@@ -47,8 +73,22 @@ type ProxyServer struct {
 	targetURL   *url.URL
 	proxy       *httputil.ReverseProxy
 	interceptor APIInterceptor
+	redact      map[string]bool // canonical names of headers redacted besides the built-in ones
+
+	mutex    sync.Mutex
+	server   *http.Server
+	listener net.Listener
+	shutdown bool
+	scenario string // scenario of the captured transactions, if any
+
+	ready     chan struct{} // closed once Start listens, fails, or the server is shut down
+	readyOnce sync.Once
 }
 
+// ShutdownTimeout is how long a proxy server whose Start context is canceled waits for the
+// requests in flight before it stops
+const ShutdownTimeout = 10 * time.Second
+
 // NewProxyServer creates a new proxy server
 func NewProxyServer(port int, target string, interceptor APIInterceptor) (*ProxyServer, error) {
 	targetURL, err := url.Parse(target)
@@ -63,29 +103,197 @@ func NewProxyServer(port int, target string, interceptor APIInterceptor) (*Proxy
 		targetURL:   targetURL,
 		proxy:       proxy,
 		interceptor: interceptor,
+		ready:       make(chan struct{}),
 	}, nil
 }
 
-// Start starts the proxy server
-func (p *ProxyServer) Start() error {
+// SetRedactHeaders redacts the values of more request and response headers, e.g. a custom
+// X-Tenant-Token, in addition to the built-in credential headers
+func (p *ProxyServer) SetRedactHeaders(names []string) {
+	p.redact = make(map[string]bool)
+	for _, name := range names {
+		p.redact[http.CanonicalHeaderKey(strings.TrimSpace(name))] = true
+	}
+}
+
+// SetScenario groups the transactions captured from now on under a named scenario, or under
+// none when name is empty
+func (p *ProxyServer) SetScenario(name string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.scenario = name
+}
+
+// Scenario returns the name of the current scenario, or an empty string when there is none
+func (p *ProxyServer) Scenario() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.scenario
+}
+
+// tagScenario starts or ends the current scenario from the scenario annotation of a request, and
+// tags the request with the current one
+func (p *ProxyServer) tagScenario(reqData *RequestData) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if name, ok := reqData.Annotations[ScenarioAnnotation]; ok {
+		p.scenario = name
+	}
+	if p.scenario == "" {
+		delete(reqData.Annotations, ScenarioAnnotation)
+		return
+	}
+	if reqData.Annotations == nil {
+		reqData.Annotations = make(map[string]string)
+	}
+	reqData.Annotations[ScenarioAnnotation] = p.scenario
+}
+
+// serveScenario handles the scenario control endpoint
+func (p *ProxyServer) serveScenario(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		name := strings.TrimSpace(r.URL.Query().Get("name"))
+		if name == "" {
+			http.Error(w, "missing scenario name", http.StatusBadRequest)
+			return
+		}
+		p.SetScenario(name)
+		logger.PrintDebug("Started scenario %s", name)
+	case http.MethodDelete:
+		p.SetScenario("")
+		logger.PrintDebug("Ended scenario")
+	default:
+		w.Header().Set("Allow", "PUT, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Start listens on the port of the proxy server and serves until the server is shut down, by
+// Shutdown or by canceling ctx, which waits up to ShutdownTimeout for the requests in flight.
+// It returns nil once the server is shut down.
+func (p *ProxyServer) Start(ctx context.Context) error {
 	// Create a custom handler that wraps the proxy
+	capture := captureHandler(p.proxy, p.redact, p.tagScenario, p.interceptor)
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ScenarioPath {
+			p.serveScenario(w, r)
+			return
+		}
+		capture.ServeHTTP(w, r)
+	})
+
+	// Start the server unless it was already shut down
+	defer p.markReady()
+	addr := fmt.Sprintf(":%d", p.port)
+	p.mutex.Lock()
+	if p.shutdown {
+		p.mutex.Unlock()
+		return nil
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		p.mutex.Unlock()
+		return err
+	}
+	p.listener = listener
+	p.server = &http.Server{Handler: handler}
+	server := p.server
+	p.mutex.Unlock()
+	p.markReady()
+
+	// Shut down gracefully when the context is canceled
+	stopped := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(stopped)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		if err := p.Shutdown(shutdownCtx); err != nil {
+			logger.PrintWarning("Failed to stop the proxy server gracefully: %v", err)
+		}
+	})
+
+	logger.PrintDebug("Starting proxy server on %s", listener.Addr())
+	err = server.Serve(listener)
+	if !stop() {
+		<-stopped
+	}
+	if err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Addr waits until Start listens and returns the address the proxy server listens on, e.g. with
+// the port chosen for port 0. It returns nil when Start failed or the server was shut down first.
+func (p *ProxyServer) Addr() net.Addr {
+	<-p.ready
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.listener == nil {
+		return nil
+	}
+	return p.listener.Addr()
+}
+
+// markReady unblocks Addr
+func (p *ProxyServer) markReady() {
+	p.readyOnce.Do(func() { close(p.ready) })
+}
+
+// Shutdown stops the proxy server, waiting until the transactions in flight have been passed to
+// the interceptor. Start returns nil once the server is shut down
+func (p *ProxyServer) Shutdown(ctx context.Context) error {
+	p.mutex.Lock()
+	p.shutdown = true
+	server := p.server
+	p.mutex.Unlock()
+
+	if server == nil {
+		p.markReady()
+		return nil
+	}
+	// Not holding the mutex, which the requests in flight may need to finish
+	return server.Shutdown(ctx)
+}
+
+// CaptureMiddleware wraps an HTTP handler so that every request it serves is captured with its
+// response, sanitized and redacted like the proxy captures them, and passed to interceptor. It
+// documents a Go server from the inside, without a proxy in front of it.
+func CaptureMiddleware(next http.Handler, redactHeaders []string, interceptor APIInterceptor) http.Handler {
+	redact := make(map[string]bool)
+	for _, name := range redactHeaders {
+		redact[http.CanonicalHeaderKey(strings.TrimSpace(name))] = true
+	}
+	return captureHandler(next, redact, nil, interceptor)
+}
+
+// captureHandler serves requests with next, and passes every request and its response to
+// interceptor. tag, if not nil, can amend the captured request before it is served.
+func captureHandler(next http.Handler, redact map[string]bool, tag func(*RequestData), interceptor APIInterceptor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Capture the request
-		reqData, err := captureRequest(r)
+		reqData, err := captureRequest(r, redact)
 		if err != nil {
-			log.Printf("Error capturing request: %v", err)
+			logger.PrintError("Error capturing request: %v", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
+		if tag != nil {
+			tag(&reqData)
+		}
 
 		// Create a custom response writer to capture the response
 		rw := newResponseWriter(w)
 
-		// Forward the request to the target server
-		p.proxy.ServeHTTP(rw, r)
+		// Serve the request, e.g. forward it to the target server
+		next.ServeHTTP(rw, r)
 
 		// Capture the response
-		respData := captureResponse(rw)
+		respData := captureResponse(rw, redact)
 
 		// Create a complete transaction and pass it to the interceptor
 		transaction := APITransaction{
@@ -93,23 +301,15 @@ func (p *ProxyServer) Start() error {
 			Response: respData,
 		}
 
-		// Log the captured request to the console
-		logTransaction(transaction)
-
 		// Pass the transaction to the interceptor
-		if p.interceptor != nil {
-			p.interceptor(transaction)
+		if interceptor != nil {
+			interceptor(transaction)
 		}
 	})
-
-	// Start the server
-	addr := fmt.Sprintf(":%d", p.port)
-	log.Printf("Starting proxy server on %s", addr)
-	return http.ListenAndServe(addr, handler)
 }
 
 // captureRequest captures data from an HTTP request
-func captureRequest(r *http.Request) (RequestData, error) {
+func captureRequest(r *http.Request, redact map[string]bool) (RequestData, error) {
 	// Read the request body
 	var bodyBytes []byte
 	if r.Body != nil {
@@ -119,25 +319,57 @@ func captureRequest(r *http.Request) (RequestData, error) {
 	}
 
 	// Sanitize the body to remove actual data values
-	sanitizedBody, err := sanitizeJSON(bodyBytes)
+	sanitizedBody, err := sanitizeBody(r.Header.Get("Content-Type"), bodyBytes)
 	if err != nil {
 		// If there's an error sanitizing, still capture the request but with empty body
 		sanitizedBody = []byte{}
 	}
 
+	// Take out the annotations so that neither the target nor the documentation sees them
+	annotations := extractAnnotations(r.Header)
+
+	// Go moves the Host header out of r.Header; keep it so servers can be inferred
+	headers := sanitizeHeaders(r.Header, redact)
+	if r.Host != "" {
+		headers.Set("Host", r.Host)
+	}
+
 	// Create a RequestData object with sanitized body
 	reqData := RequestData{
-		Method:      r.Method,
-		Path:        r.URL.Path,
-		QueryParams: sanitizeQueryParams(r.URL.Query()),
-		Headers:     sanitizeHeaders(r.Header),
-		Body:        sanitizedBody,
-		Timestamp:   time.Now(),
+		Method:       r.Method,
+		Path:         r.URL.Path,
+		QueryParams:  sanitizeQueryParams(r.URL.Query()),
+		Headers:      headers,
+		Body:         sanitizedBody,
+		BodyEncoding: sanitizedBodyEncoding(sanitizedBody),
+		Timestamp:    time.Now(),
+		Annotations:  annotations,
 	}
 
 	return reqData, nil
 }
 
+// extractAnnotations removes the X-SwagDoc-* headers from request headers and returns their
+// values by lower-cased name, or nil when there are none
+func extractAnnotations(headers http.Header) map[string]string {
+	var annotations map[string]string
+	for key, values := range headers {
+		name, ok := strings.CutPrefix(http.CanonicalHeaderKey(key), AnnotationHeaderPrefix)
+		if !ok {
+			continue
+		}
+		delete(headers, key)
+		if name == "" || len(values) == 0 {
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+	return annotations
+}
+
 // responseWriter is a custom ResponseWriter that captures the response
 type responseWriter struct {
 	http.ResponseWriter
@@ -169,20 +401,136 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 }
 
 // captureResponse captures data from the response
-func captureResponse(rw *responseWriter) ResponseData {
+func captureResponse(rw *responseWriter, redact map[string]bool) ResponseData {
 	// Sanitize the body to remove actual data values
-	sanitizedBody, err := sanitizeJSON(rw.body.Bytes())
+	sanitizedBody, err := sanitizeBody(rw.ResponseWriter.Header().Get("Content-Type"), rw.body.Bytes())
 	if err != nil {
 		// If there's an error sanitizing, still capture the response but with empty body
 		sanitizedBody = []byte{}
 	}
 
 	return ResponseData{
-		StatusCode: rw.statusCode,
-		Headers:    sanitizeHeaders(rw.ResponseWriter.Header()),
-		Body:       sanitizedBody,
-		Timestamp:  time.Now(),
+		StatusCode:   rw.statusCode,
+		Headers:      sanitizeHeaders(rw.ResponseWriter.Header(), redact),
+		Body:         sanitizedBody,
+		BodyEncoding: sanitizedBodyEncoding(sanitizedBody),
+		Timestamp:    time.Now(),
+	}
+}
+
+// sanitizeBody sanitizes a request or response body according to its content type
+func sanitizeBody(contentType string, data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	if boundary, ok := multipartBoundary(contentType); ok {
+		return sanitizeMultipart(data, boundary)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "text/csv":
+		return sanitizeCSV(data)
+	case IsNDJSONContentType(mediaType):
+		return sanitizeNDJSON(data)
+	case IsBinaryContentType(mediaType):
+		return []byte("__binary__"), nil
+	case strings.HasPrefix(mediaType, "text/"):
+		return []byte("__string__"), nil
 	}
+
+	sanitized, err := sanitizeJSON(data)
+	if err != nil && bytes.Count(bytes.TrimSpace(data), []byte("\n")) > 0 {
+		// Newline-delimited JSON sent without its media type
+		if lines, ndjsonErr := sanitizeNDJSON(data); ndjsonErr == nil {
+			return lines, nil
+		}
+	}
+	return sanitized, err
+}
+
+// IsNDJSONContentType reports whether a media type is a stream of newline-delimited JSON values
+func IsNDJSONContentType(mediaType string) bool {
+	switch mediaType {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return true
+	}
+	return false
+}
+
+// maxNDJSONLines is how many lines of a newline-delimited JSON stream are kept
+const maxNDJSONLines = 20
+
+// sanitizeNDJSON sanitizes every JSON value of a newline-delimited JSON stream, keeping the
+// first maxNDJSONLines of them
+func sanitizeNDJSON(data []byte) ([]byte, error) {
+	var lines [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		sanitized, err := sanitizeJSON(line)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, sanitized)
+		if len(lines) == maxNDJSONLines {
+			break
+		}
+	}
+	return append(bytes.Join(lines, []byte("\n")), '\n'), nil
+}
+
+// IsBinaryContentType checks if a media type carries binary (non-textual) content
+func IsBinaryContentType(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "font/"):
+		return true
+	}
+
+	binaryTypes := map[string]bool{
+		"application/octet-stream": true,
+		"application/pdf":          true,
+		"application/zip":          true,
+		"application/gzip":         true,
+		"application/x-tar":        true,
+		"application/vnd.ms-excel": true,
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       true,
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
+	}
+	return binaryTypes[mediaType]
+}
+
+// sanitizeCSV keeps the header row of a CSV body and replaces the first data row with type placeholders
+func sanitizeCSV(data []byte) ([]byte, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	records := [][]string{header}
+	if row, err := reader.Read(); err == nil {
+		placeholders := make([]string, len(row))
+		for i, value := range row {
+			placeholders[i] = sanitizeString(value)
+		}
+		records = append(records, placeholders)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 // sanitizeJSON preserves the structure of JSON data but replaces values with type placeholders
@@ -196,10 +544,164 @@ func sanitizeJSON(data []byte) ([]byte, error) {
 		return nil, err
 	}
 
+	if sanitized, ok := sanitizeJSONRPC(obj); ok {
+		return json.Marshal(sanitized)
+	}
 	sanitized := sanitizeValue(obj)
 	return json.Marshal(sanitized)
 }
 
+// rpcMethodPattern matches JSON-RPC method names that are safe to keep verbatim, e.g. user.get
+var rpcMethodPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.:/-]{0,127}$`)
+
+// sanitizeJSONRPC sanitizes a JSON-RPC 2.0 request or response, or a batch of them, keeping
+// the protocol version and the method names, and every entry of a batch so that requests and
+// responses still line up. It returns false for other values.
+func sanitizeJSONRPC(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v["jsonrpc"] != "2.0" {
+			return nil, false
+		}
+		result := sanitizeValue(v).(map[string]interface{})
+		result["jsonrpc"] = "2.0"
+		if method, ok := v["method"].(string); ok && rpcMethodPattern.MatchString(method) {
+			result["method"] = method
+		}
+		return result, true
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, false
+		}
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			sanitized, ok := sanitizeJSONRPC(item)
+			if !ok {
+				return nil, false
+			}
+			result[i] = sanitized
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+// multipartBoundary returns the boundary of a multipart/form-data content type
+func multipartBoundary(contentType string) (string, bool) {
+	if contentType == "" {
+		return "", false
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return "", false
+	}
+
+	return params["boundary"], true
+}
+
+// sanitizeMultipart converts a multipart/form-data body into a JSON object of field names
+// mapped to type placeholders. File parts are recorded with the __file__ placeholder and
+// fields sent multiple times become arrays.
+func sanitizeMultipart(data []byte, boundary string) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	fields := make(map[string]interface{})
+	reader := multipart.NewReader(bytes.NewReader(data), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := part.FormName()
+		if name == "" {
+			part.Close()
+			continue
+		}
+
+		var placeholder interface{} = "__file__"
+		if part.FileName() == "" {
+			value, err := io.ReadAll(part)
+			if err != nil {
+				part.Close()
+				return nil, err
+			}
+			placeholder = sanitizeString(string(value))
+		}
+		part.Close()
+
+		if existing, ok := fields[name]; ok {
+			// Repeated fields are arrays; one sample is enough to infer the item type
+			if _, isArray := existing.([]interface{}); !isArray {
+				fields[name] = []interface{}{existing}
+			}
+			continue
+		}
+		fields[name] = placeholder
+	}
+
+	return json.Marshal(fields)
+}
+
+// sanitizeString replaces a textual value (form field, query parameter) with a placeholder
+// describing the type it most likely represents
+func sanitizeString(value string) string {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return "__integer__"
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return "__number__"
+	}
+	if value == "true" || value == "false" {
+		return "__boolean__"
+	}
+	if _, err := time.Parse("2006-01-02", value); err == nil {
+		return "__date__"
+	}
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return "__datetime__"
+	}
+	return "__string__"
+}
+
+// sanitizeQueryValue replaces a query parameter value with a placeholder, keeping the
+// shape of comma-separated lists (e.g. "1,2,3" becomes "__integer__,__integer__,__integer__")
+func sanitizeQueryValue(value string) string {
+	if !strings.Contains(value, ",") {
+		return sanitizeString(value)
+	}
+
+	items := strings.Split(value, ",")
+	for i, item := range items {
+		items[i] = sanitizeString(item)
+	}
+	return strings.Join(items, ",")
+}
+
+// DiscriminatorFields lists object fields whose literal values are kept during sanitization,
+// as they identify the variant of a polymorphic object rather than carry user data
+var DiscriminatorFields = []string{"type", "kind", "object", "__typename", "@type", "event_type", "eventType"}
+
+// discriminatorValuePattern matches enum-like values that are safe to keep verbatim
+var discriminatorValuePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.:-]{0,63}$`)
+
+// discriminatorValue returns the literal discriminator value of an object, if it has one
+func discriminatorValue(obj map[string]interface{}) (string, bool) {
+	for _, field := range DiscriminatorFields {
+		if value, ok := obj[field].(string); ok && discriminatorValuePattern.MatchString(value) {
+			return field + "=" + value, true
+		}
+	}
+	return "", false
+}
+
 // sanitizeValue replaces actual values with type placeholders
 func sanitizeValue(value interface{}) interface{} {
 	if value == nil {
@@ -210,6 +712,10 @@ func sanitizeValue(value interface{}) interface{} {
 	case map[string]interface{}:
 		result := make(map[string]interface{})
 		for key, val := range v {
+			if str, ok := val.(string); ok && isDiscriminatorField(key) && discriminatorValuePattern.MatchString(str) {
+				result[key] = str
+				continue
+			}
 			result[key] = sanitizeValue(val)
 		}
 		return result
@@ -217,8 +723,25 @@ func sanitizeValue(value interface{}) interface{} {
 		if len(v) == 0 {
 			return []interface{}{}
 		}
-		// For arrays, we only need one sample to infer schema
-		return []interface{}{sanitizeValue(v[0])}
+		// For arrays, we only need one sample to infer schema, plus one per
+		// variant when the items are polymorphic objects
+		result := []interface{}{sanitizeValue(v[0])}
+		seen := make(map[string]bool)
+		for i, item := range v {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			variant, ok := discriminatorValue(obj)
+			if !ok || seen[variant] {
+				continue
+			}
+			seen[variant] = true
+			if i > 0 {
+				result = append(result, sanitizeValue(obj))
+			}
+		}
+		return result
 	case string:
 		return "__string__"
 	case float64:
@@ -233,8 +756,18 @@ func sanitizeValue(value interface{}) interface{} {
 	}
 }
 
-// sanitizeHeaders removes sensitive values from headers
-func sanitizeHeaders(headers http.Header) http.Header {
+// isDiscriminatorField checks if an object field is one of the known discriminator fields
+func isDiscriminatorField(name string) bool {
+	for _, field := range DiscriminatorFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeHeaders removes sensitive values from headers, and those of the headers in redact
+func sanitizeHeaders(headers http.Header, redact map[string]bool) http.Header {
 	sanitized := make(http.Header)
 	sensitiveHeaders := map[string]bool{
 		"Authorization":       true,
@@ -257,15 +790,93 @@ func sanitizeHeaders(headers http.Header) http.Header {
 	}
 
 	for key, values := range headers {
-		if sensitiveHeaders[key] {
+		switch {
+		case key == "Cookie":
+			// Keep cookie names so session auth and cookie parameters can be documented
+			sanitized[key] = sanitizeCookieHeader(values)
+		case key == "Set-Cookie":
+			sanitized[key] = sanitizeSetCookieHeader(values)
+		case key == "Authorization":
+			// Keep the auth scheme so Bearer and Basic auth can be documented
+			sanitized[key] = sanitizeAuthorizationHeader(values)
+		case sensitiveHeaders[key] || redact[key]:
 			sanitized[key] = []string{"__redacted__"}
-		} else {
+		default:
 			sanitized[key] = values
 		}
 	}
 	return sanitized
 }
 
+// sanitizeAuthorizationHeader keeps the auth scheme of Authorization headers, e.g. Bearer, but
+// redacts their credentials
+func sanitizeAuthorizationHeader(values []string) []string {
+	sanitized := make([]string, 0, len(values))
+	for _, value := range values {
+		scheme, credentials, ok := strings.Cut(strings.TrimSpace(value), " ")
+		if !ok || strings.TrimSpace(credentials) == "" || !isAuthScheme(scheme) {
+			sanitized = append(sanitized, "__redacted__")
+			continue
+		}
+		sanitized = append(sanitized, scheme+" __redacted__")
+	}
+	return sanitized
+}
+
+// isAuthScheme checks whether a name is a valid auth scheme token, so that no part of the
+// credentials is mistaken for one
+func isAuthScheme(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// sanitizeCookieHeader keeps cookie names from a Cookie header but redacts their values
+func sanitizeCookieHeader(values []string) []string {
+	var pairs []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ";") {
+			name := strings.TrimSpace(strings.SplitN(part, "=", 2)[0])
+			if name == "" {
+				continue
+			}
+			pairs = append(pairs, name+"=__redacted__")
+		}
+	}
+
+	if len(pairs) == 0 {
+		return []string{"__redacted__"}
+	}
+	return []string{strings.Join(pairs, "; ")}
+}
+
+// sanitizeSetCookieHeader keeps cookie names and attributes from Set-Cookie headers but redacts their values
+func sanitizeSetCookieHeader(values []string) []string {
+	sanitized := make([]string, 0, len(values))
+	for _, value := range values {
+		parts := strings.Split(value, ";")
+		name := strings.TrimSpace(strings.SplitN(parts[0], "=", 2)[0])
+		if name == "" {
+			sanitized = append(sanitized, "__redacted__")
+			continue
+		}
+
+		// Attributes such as Path, HttpOnly and Secure carry no secrets
+		parts[0] = name + "=__redacted__"
+		for i := 1; i < len(parts); i++ {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		sanitized = append(sanitized, strings.Join(parts, "; "))
+	}
+	return sanitized
+}
+
 // sanitizeQueryParams removes sensitive values from query parameters
 func sanitizeQueryParams(params url.Values) url.Values {
 	sanitized := make(url.Values)
@@ -290,23 +901,11 @@ func sanitizeQueryParams(params url.Values) url.Values {
 			sanitized[key] = []string{"__redacted__"}
 		} else {
 			newValues := make([]string, len(values))
-			for i := range values {
-				newValues[i] = "__string__"
+			for i, value := range values {
+				newValues[i] = sanitizeQueryValue(value)
 			}
 			sanitized[key] = newValues
 		}
 	}
 	return sanitized
 }
-
-// logTransaction logs the captured transaction details to the console
-func logTransaction(tx APITransaction) {
-	// Get content type
-	contentType := "unknown"
-	if ct, ok := tx.Response.Headers["Content-Type"]; ok && len(ct) > 0 {
-		contentType = ct[0]
-	}
-
-	// Use our new logger to print the request log
-	logger.PrintRequestLog(tx.Request.Method, tx.Request.Path, tx.Response.StatusCode, contentType)
-}
@@ -0,0 +1,186 @@
+package proxy
+
+import (
+	"errors"
+	"sync"
+)
+
+// DefaultQueueSize is how many captured transactions may wait to be written before the proxy
+// slows down to the speed of the disk
+const DefaultQueueSize = 1024
+
+// ErrStorageClosed is returned when a transaction is stored after the storage was closed
+var ErrStorageClosed = errors.New("storage is closed")
+
+// ErrQueueFull is reported when a lossy storage drops transactions because its queue is full
+var ErrQueueFull = errors.New("queue is full, dropping transactions until it has room again")
+
+// batchStorage is implemented by storages that write several transactions at once faster than
+// one by one
+type batchStorage interface {
+	StoreAll(transactions []APITransaction) error
+}
+
+// AsyncStorage writes transactions to another storage on a background goroutine, so that proxied
+// requests don't wait for the disk. The queue is bounded: when it is full, Store blocks until
+// there is room again rather than dropping transactions or growing without limit. Close writes
+// the queued transactions before returning.
+type AsyncStorage struct {
+	storage Storage
+	queue   chan APITransaction
+	onError func(error)
+	done    chan struct{}
+	lossy   bool // drop transactions when the queue is full instead of waiting
+
+	sending sync.RWMutex // held by Store while queueing, and by Close to close the queue
+	closed  bool
+
+	mutex    sync.Mutex
+	written  *sync.Cond
+	pending  int  // transactions queued or being written
+	dropping bool // whether transactions were dropped since the last write
+}
+
+// NewAsyncStorage starts writing to storage in the background, with room for size queued
+// transactions. onError, if not nil, is called with the errors of writes, which happen after
+// Store returned.
+func NewAsyncStorage(storage Storage, size int, onError func(error)) *AsyncStorage {
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+	a := &AsyncStorage{
+		storage: storage,
+		queue:   make(chan APITransaction, size),
+		onError: onError,
+		done:    make(chan struct{}),
+	}
+	a.written = sync.NewCond(&a.mutex)
+	go a.write()
+	return a
+}
+
+// NewLossyAsyncStorage is like NewAsyncStorage, but Store drops transactions rather than
+// waiting when the queue is full, for copies such as sinks that must never slow down capture.
+// The first transaction dropped after every write is reported to onError as ErrQueueFull.
+func NewLossyAsyncStorage(storage Storage, size int, onError func(error)) *AsyncStorage {
+	a := NewAsyncStorage(storage, size, onError)
+	a.lossy = true
+	return a
+}
+
+// Store queues a transaction to be written
+func (a *AsyncStorage) Store(transaction APITransaction) error {
+	a.sending.RLock()
+	defer a.sending.RUnlock()
+	if a.closed {
+		return ErrStorageClosed
+	}
+
+	a.mutex.Lock()
+	a.pending++
+	a.mutex.Unlock()
+
+	if !a.lossy {
+		a.queue <- transaction
+		return nil
+	}
+	select {
+	case a.queue <- transaction:
+	default:
+		a.mutex.Lock()
+		a.pending--
+		report := !a.dropping
+		a.dropping = true
+		a.written.Broadcast()
+		a.mutex.Unlock()
+		if report && a.onError != nil {
+			a.onError(ErrQueueFull)
+		}
+	}
+	return nil
+}
+
+// GetAll returns all stored API transactions, including the queued ones
+func (a *AsyncStorage) GetAll() ([]APITransaction, error) {
+	a.Flush()
+	return a.storage.GetAll()
+}
+
+// Each streams all stored API transactions to fn, including the queued ones
+func (a *AsyncStorage) Each(fn func(APITransaction) error) error {
+	a.Flush()
+	return EachTransaction(a.storage, fn)
+}
+
+// Clear removes all stored API transactions, after the queued ones were written
+func (a *AsyncStorage) Clear() error {
+	a.Flush()
+	return a.storage.Clear()
+}
+
+// Flush waits until the transactions queued so far are written
+func (a *AsyncStorage) Flush() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for a.pending > 0 {
+		a.written.Wait()
+	}
+}
+
+// Close stops accepting transactions and waits until the queued ones are written
+func (a *AsyncStorage) Close() error {
+	a.sending.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.sending.Unlock()
+
+	<-a.done
+	return nil
+}
+
+// write writes the queued transactions until the queue is closed, taking everything queued at
+// once so that storages supporting it write a batch per disk write
+func (a *AsyncStorage) write() {
+	defer close(a.done)
+	for transaction := range a.queue {
+		batch := []APITransaction{transaction}
+	drain:
+		for len(batch) < cap(a.queue) {
+			select {
+			case next, ok := <-a.queue:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+
+		if err := storeBatch(a.storage, batch); err != nil && a.onError != nil {
+			a.onError(err)
+		}
+
+		a.mutex.Lock()
+		a.pending -= len(batch)
+		a.dropping = false
+		a.written.Broadcast()
+		a.mutex.Unlock()
+	}
+}
+
+// storeBatch writes a batch of transactions to a storage, at once if the storage supports it
+func storeBatch(storage Storage, batch []APITransaction) error {
+	if batcher, ok := storage.(batchStorage); ok {
+		return batcher.StoreAll(batch)
+	}
+	var errs []error
+	for _, transaction := range batch {
+		if err := storage.Store(transaction); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,228 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStorage records the transactions it stores, and how many writes it took
+type memoryStorage struct {
+	mutex        sync.Mutex
+	transactions []APITransaction
+	writes       int
+	started      chan struct{} // if set, receives every write as it starts
+	release      chan struct{} // if set, every write waits for it
+}
+
+func (m *memoryStorage) Store(transaction APITransaction) error {
+	return m.StoreAll([]APITransaction{transaction})
+}
+
+func (m *memoryStorage) StoreAll(transactions []APITransaction) error {
+	if m.started != nil {
+		m.started <- struct{}{}
+	}
+	if m.release != nil {
+		<-m.release
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.transactions = append(m.transactions, transactions...)
+	m.writes++
+	return nil
+}
+
+func (m *memoryStorage) GetAll() ([]APITransaction, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]APITransaction(nil), m.transactions...), nil
+}
+
+func (m *memoryStorage) Clear() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.transactions = nil
+	return nil
+}
+
+func TestAsyncStorageFlushesOnClose(t *testing.T) {
+	backend := &memoryStorage{}
+	storage := NewAsyncStorage(backend, 16, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := storage.Store(APITransaction{Request: RequestData{Path: fmt.Sprintf("/users/%d", i*50+j)}}); err != nil {
+					t.Errorf("Failed to store: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := storage.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	if len(backend.transactions) != 500 {
+		t.Errorf("Expected all 500 transactions to be written on close, got %d", len(backend.transactions))
+	}
+	if err := storage.Store(APITransaction{}); !errors.Is(err, ErrStorageClosed) {
+		t.Errorf("Expected ErrStorageClosed after close, got %v", err)
+	}
+	if err := storage.Close(); err != nil {
+		t.Errorf("Expected closing twice to succeed, got %v", err)
+	}
+}
+
+func TestAsyncStorageEach(t *testing.T) {
+	backend := &memoryStorage{}
+	storage := NewAsyncStorage(backend, 16, nil)
+	defer storage.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := storage.Store(APITransaction{Request: RequestData{Path: fmt.Sprintf("/users/%d", i)}}); err != nil {
+			t.Fatalf("Store failed: %v", err)
+		}
+	}
+
+	// The queued transactions are written before they are iterated
+	var paths []string
+	if err := storage.Each(func(tx APITransaction) error {
+		paths = append(paths, tx.Request.Path)
+		return nil
+	}); err != nil {
+		t.Fatalf("Each failed: %v", err)
+	}
+	if len(paths) != 5 || paths[0] != "/users/0" || paths[4] != "/users/4" {
+		t.Errorf("Expected /users/0 to /users/4 in order, got %v", paths)
+	}
+
+	// Errors of fn stop the iteration
+	stop := errors.New("stop")
+	count := 0
+	err := EachTransaction(storage, func(APITransaction) error {
+		count++
+		return stop
+	})
+	if err != stop || count != 1 {
+		t.Errorf("Expected the iteration to stop at the first error, got %v after %d transactions", err, count)
+	}
+}
+
+func TestAsyncStorageBackpressure(t *testing.T) {
+	backend := &memoryStorage{started: make(chan struct{}, 10), release: make(chan struct{})}
+	storage := NewAsyncStorage(backend, 2, nil)
+
+	// One transaction is being written and two are queued, so the fourth has to wait
+	for i := 0; i < 3; i++ {
+		if err := storage.Store(APITransaction{}); err != nil {
+			t.Fatalf("Failed to store: %v", err)
+		}
+		if i == 0 {
+			<-backend.started
+		}
+	}
+	stored := make(chan struct{})
+	go func() {
+		storage.Store(APITransaction{})
+		close(stored)
+	}()
+	select {
+	case <-stored:
+		t.Fatal("Expected Store to block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Once the disk catches up, the queued transactions are written in one batch
+	close(backend.release)
+	<-stored
+	all, err := storage.GetAll()
+	if err != nil || len(all) != 4 {
+		t.Fatalf("Expected 4 transactions, got %d and %v", len(all), err)
+	}
+	if backend.writes > 3 {
+		t.Errorf("Expected the queued transactions to be batched, got %d writes", backend.writes)
+	}
+	storage.Close()
+}
+
+func TestLossyAsyncStorage(t *testing.T) {
+	backend := &memoryStorage{started: make(chan struct{}, 10), release: make(chan struct{})}
+	var mutex sync.Mutex
+	var reported []error
+	storage := NewLossyAsyncStorage(backend, 2, func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		reported = append(reported, err)
+	})
+
+	// One transaction is being written and two are queued, so the rest are dropped at once
+	stored := make(chan struct{})
+	go func() {
+		defer close(stored)
+		for i := 0; i < 6; i++ {
+			if err := storage.Store(APITransaction{}); err != nil {
+				t.Errorf("Failed to store: %v", err)
+			}
+			if i == 0 {
+				<-backend.started
+			}
+		}
+	}()
+	select {
+	case <-stored:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Store not to block while the queue is full")
+	}
+
+	close(backend.release)
+	storage.Close()
+	if len(backend.transactions) != 3 {
+		t.Errorf("Expected 3 transactions to be written, got %d", len(backend.transactions))
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], ErrQueueFull) {
+		t.Errorf("Expected the drops to be reported once, got %v", reported)
+	}
+}
+
+func TestAsyncStorageErrors(t *testing.T) {
+	var mutex sync.Mutex
+	var reported []error
+	storage := NewAsyncStorage(failingStorage{}, 4, func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		reported = append(reported, err)
+	})
+	if err := storage.Store(APITransaction{}); err != nil {
+		t.Fatalf("Expected write errors to be reported later, got %v", err)
+	}
+	storage.Close()
+	if len(reported) != 1 {
+		t.Errorf("Expected 1 reported error, got %v", reported)
+	}
+}
+
+// failingStorage fails every write, one transaction at a time
+type failingStorage struct{}
+
+func (failingStorage) Store(APITransaction) error        { return errors.New("disk full") }
+func (failingStorage) GetAll() ([]APITransaction, error) { return nil, nil }
+func (failingStorage) Clear() error                      { return nil }
+
+func BenchmarkAsyncStorageStore(b *testing.B) {
+	storage := NewAsyncStorage(&memoryStorage{}, DefaultQueueSize, nil)
+	transaction := APITransaction{Request: RequestData{Method: "GET", Path: "/users"}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := storage.Store(transaction); err != nil {
+			b.Fatal(err)
+		}
+	}
+	storage.Close()
+}
@@ -1,14 +1,16 @@
 package proxy
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/logger"
 )
 
 // Storage interface for storing API transactions
@@ -18,12 +20,39 @@ type Storage interface {
 	Clear() error
 }
 
-// FileStorage stores API transactions as JSON files
+// Iterator is implemented by storages that can pass their transactions on one at a time,
+// without loading all of them into memory first
+type Iterator interface {
+	Each(fn func(APITransaction) error) error
+}
+
+// EachTransaction passes every transaction of a storage to fn, streaming them from storages
+// that implement Iterator. An error returned by fn stops the iteration and is returned.
+func EachTransaction(storage Storage, fn func(APITransaction) error) error {
+	if iterator, ok := storage.(Iterator); ok {
+		return iterator.Each(fn)
+	}
+
+	transactions, err := storage.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, transaction := range transactions {
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FileStorage stores API transactions as JSON files, each next to an index of the endpoints and
+// fingerprints of its transactions
 type FileStorage struct {
-	baseDir      string
-	sessionFile  string
-	transactions []APITransaction
-	mutex        sync.Mutex
+	baseDir     string
+	sessionFile string
+	data        []byte       // content of the session file
+	entries     []IndexEntry // index of the session file
+	mutex       sync.Mutex
 }
 
 // NewFileStorage creates a new file storage
@@ -37,67 +66,127 @@ func NewFileStorage(baseDir string) (*FileStorage, error) {
 	sessionFile := filepath.Join(baseDir, fmt.Sprintf("session-%s.json", time.Now().Format("20060102-150405")))
 
 	return &FileStorage{
-		baseDir:      baseDir,
-		sessionFile:  sessionFile,
-		transactions: []APITransaction{},
+		baseDir:     baseDir,
+		sessionFile: sessionFile,
 	}, nil
 }
 
 // Store saves an API transaction to storage
 func (s *FileStorage) Store(transaction APITransaction) error {
+	return s.StoreAll([]APITransaction{transaction})
+}
+
+// StoreAll saves several API transactions to storage with a single write of the session file
+func (s *FileStorage) StoreAll(transactions []APITransaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// Add transaction to in-memory list
-	s.transactions = append(s.transactions, transaction)
+	// Append the transactions to the encoded session, laid out like json.MarshalIndent would lay
+	// out all of them, so that the transactions stored before aren't encoded again
+	data := s.data
+	if len(data) == 0 {
+		data = []byte("[\n  ")
+	} else {
+		data = append(data[:len(data)-len("\n]")], ",\n  "...)
+	}
+	entries := s.entries
+	for i, transaction := range transactions {
+		encoded, err := json.MarshalIndent(transaction, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			data = append(data, ",\n  "...)
+		}
+		entries = append(entries, indexEntry(s.sessionFile, transaction))
+		data = append(data, encoded...)
+	}
+	data = append(data, "\n]"...)
 
-	// Write all transactions to the session file
-	data, err := json.MarshalIndent(s.transactions, "", "  ")
-	if err != nil {
+	// Write to file
+	if err := os.WriteFile(s.sessionFile, data, 0644); err != nil {
 		return err
 	}
+	s.data, s.entries = data, entries
 
-	// Write to file
-	return os.WriteFile(s.sessionFile, data, 0644)
+	// The index only saves work later, and is rebuilt from the session file when missing
+	_ = writeIndex(s.sessionFile, entries)
+	return nil
 }
 
 // GetAll returns all stored API transactions
 func (s *FileStorage) GetAll() ([]APITransaction, error) {
-	// First, load all transactions from session files
+	var allTransactions []APITransaction
+	err := s.Each(func(transaction APITransaction) error {
+		allTransactions = append(allTransactions, transaction)
+		return nil
+	})
+	return allTransactions, err
+}
+
+// Each streams the stored API transactions to fn one at a time, decoding the session files
+// incrementally so that huge captures never have to be held in memory at once. Unreadable files
+// are skipped, as are the rest of a session file from its first invalid transaction on. An
+// error returned by fn stops the iteration and is returned.
+func (s *FileStorage) Each(fn func(APITransaction) error) error {
 	files, err := os.ReadDir(s.baseDir)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var allTransactions []APITransaction
-
 	for _, file := range files {
 		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
 			continue
 		}
-
-		filePath := filepath.Join(s.baseDir, file.Name())
-		data, err := os.ReadFile(filePath)
-		if err != nil {
-			continue
+		if err := eachInFile(filepath.Join(s.baseDir, file.Name()), fn); err != nil {
+			return err
 		}
+	}
+	return nil
+}
+
+// eachInFile streams the transactions of a session file, which holds an array of transactions,
+// or a single transaction in files written by older versions
+func eachInFile(path string, fn func(APITransaction) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil
+	}
+	decoder := json.NewDecoder(reader)
 
-		// Check if it's a session file (containing an array)
-		if len(data) > 0 && data[0] == '[' {
-			var transactions []APITransaction
-			if err := json.Unmarshal(data, &transactions); err == nil {
-				allTransactions = append(allTransactions, transactions...)
-			}
-		} else {
-			// For backward compatibility - handle single transaction files
-			var transaction APITransaction
-			if err := json.Unmarshal(data, &transaction); err == nil {
-				allTransactions = append(allTransactions, transaction)
-			}
+	// For backward compatibility - handle single transaction files
+	if first[0] != '[' {
+		var transaction APITransaction
+		if err := decoder.Decode(&transaction); err != nil {
+			return nil
 		}
+		return fn(transaction)
 	}
 
-	return allTransactions, nil
+	if _, err := decoder.Token(); err != nil {
+		return nil
+	}
+	for decoder.More() {
+		var transaction APITransaction
+		if err := decoder.Decode(&transaction); err != nil {
+			return nil
+		}
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Clear removes all stored API transactions
@@ -106,7 +195,7 @@ func (s *FileStorage) Clear() error {
 	defer s.mutex.Unlock()
 
 	// Reset in-memory transactions
-	s.transactions = []APITransaction{}
+	s.data, s.entries = nil, nil
 
 	// Remove all files from the directory
 	files, err := os.ReadDir(s.baseDir)
@@ -115,7 +204,7 @@ func (s *FileStorage) Clear() error {
 	}
 
 	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") && !strings.HasSuffix(file.Name(), indexSuffix) {
 			continue
 		}
 
@@ -132,7 +221,7 @@ func (s *FileStorage) Clear() error {
 func TransactionInterceptor(storage Storage) func(APITransaction) {
 	return func(transaction APITransaction) {
 		if err := storage.Store(transaction); err != nil {
-			log.Printf("Error storing API transaction: %v", err)
+			logger.PrintError("Error storing API transaction: %v", err)
 		}
 	}
 }
@@ -0,0 +1,62 @@
+package proxy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStorageEach(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"session-20240301-090000.json": `[{"Request":{"Method":"GET","Path":"/users"}},{"Request":{"Method":"POST","Path":"/users"}}]`,
+		"session-20240302-090000.json": `[{"Request":{"Method":"GET","Path":"/orders"}},{"Request":`, // truncated by a crash
+		"single.json":                  `{"Request":{"Method":"DELETE","Path":"/users/1"}}`,
+		"notes.txt":                    `not a session file`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	storage, err := NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	// The transactions before the truncation are still read
+	var paths []string
+	if err := storage.Each(func(tx APITransaction) error {
+		paths = append(paths, tx.Request.Method+" "+tx.Request.Path)
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to read transactions: %v", err)
+	}
+	expected := []string{"GET /users", "POST /users", "GET /orders", "DELETE /users/1"}
+	if len(paths) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], paths[i])
+		}
+	}
+
+	// An error stops the iteration
+	stop := errors.New("stop")
+	count := 0
+	err = storage.Each(func(tx APITransaction) error {
+		count++
+		return stop
+	})
+	if err != stop || count != 1 {
+		t.Errorf("Expected the iteration to stop after 1 transaction, got %d and %v", count, err)
+	}
+
+	all, err := storage.GetAll()
+	if err != nil || len(all) != len(expected) {
+		t.Errorf("Expected %d transactions, got %d and %v", len(expected), len(all), err)
+	}
+}
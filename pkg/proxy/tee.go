@@ -0,0 +1,47 @@
+package proxy
+
+import "errors"
+
+// TeeStorage stores transactions in a storage and copies them to others, e.g. sinks publishing
+// them to a central pipeline. Transactions are read from and cleared in the first storage only.
+// Copies that may be slow or unreachable belong behind a NewLossyAsyncStorage queue, so that
+// they never hold up the primary storage.
+type TeeStorage struct {
+	primary Storage
+	copies  []Storage
+}
+
+// NewTeeStorage creates a storage storing transactions in primary and copying them to copies
+func NewTeeStorage(primary Storage, copies ...Storage) *TeeStorage {
+	return &TeeStorage{primary: primary, copies: copies}
+}
+
+// Store stores a transaction in every storage
+func (t *TeeStorage) Store(transaction APITransaction) error {
+	return t.StoreAll([]APITransaction{transaction})
+}
+
+// StoreAll stores several transactions in every storage, at once in those that support it. A
+// failing storage doesn't keep the transactions from the others.
+func (t *TeeStorage) StoreAll(transactions []APITransaction) error {
+	errs := []error{storeBatch(t.primary, transactions)}
+	for _, storage := range t.copies {
+		errs = append(errs, storeBatch(storage, transactions))
+	}
+	return errors.Join(errs...)
+}
+
+// GetAll returns the transactions of the primary storage
+func (t *TeeStorage) GetAll() ([]APITransaction, error) {
+	return t.primary.GetAll()
+}
+
+// Each streams the transactions of the primary storage to fn
+func (t *TeeStorage) Each(fn func(APITransaction) error) error {
+	return EachTransaction(t.primary, fn)
+}
+
+// Clear removes the transactions of the primary storage
+func (t *TeeStorage) Clear() error {
+	return t.primary.Clear()
+}
@@ -0,0 +1,72 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTeeStorage(t *testing.T) {
+	primary, copy := &memoryStorage{}, &memoryStorage{}
+	storage := NewTeeStorage(primary, copy, failingStorage{})
+
+	transactions := []APITransaction{
+		{Request: RequestData{Method: "GET", Path: "/users"}},
+		{Request: RequestData{Method: "GET", Path: "/orders"}},
+	}
+	err := storage.StoreAll(transactions)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Expected the error of the failing storage, got %v", err)
+	}
+
+	// The others got the transactions anyway, in a single write each
+	for name, backend := range map[string]*memoryStorage{"primary": primary, "copy": copy} {
+		if len(backend.transactions) != 2 || backend.writes != 1 {
+			t.Errorf("Expected the %s storage to get 2 transactions in 1 write, got %d in %d", name, len(backend.transactions), backend.writes)
+		}
+	}
+
+	// Transactions are read and cleared in the primary storage only
+	stored, err := storage.GetAll()
+	if err != nil || len(stored) != 2 {
+		t.Errorf("Expected the 2 transactions of the primary storage, got %d, %v", len(stored), err)
+	}
+	if err := storage.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if len(primary.transactions) != 0 || len(copy.transactions) != 2 {
+		t.Errorf("Expected only the primary storage to be cleared, got %d and %d", len(primary.transactions), len(copy.transactions))
+	}
+}
+
+func TestTeeStorageLossyCopy(t *testing.T) {
+	// A copy that never finishes a write doesn't hold up the primary storage
+	primary := &memoryStorage{}
+	stuck := &memoryStorage{release: make(chan struct{})}
+	copy := NewLossyAsyncStorage(stuck, 1, nil)
+	storage := NewTeeStorage(primary, copy)
+
+	done := make(chan error)
+	go func() {
+		for i := 0; i < 5; i++ {
+			if err := storage.Store(APITransaction{}); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Failed to store: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the primary storage not to wait for the copy")
+	}
+	if len(primary.transactions) != 5 {
+		t.Errorf("Expected 5 transactions in the primary storage, got %d", len(primary.transactions))
+	}
+	close(stuck.release)
+	copy.Close()
+}
@@ -0,0 +1,425 @@
+// Package publish pushes generated OpenAPI documents to API catalogs: SwaggerHub through its
+// registry API, Stoplight through the Stoplight CLI, and Backstage as an API entity file, and
+// uploads them to object storage or any URL.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/parnexcodes/swag-doc/pkg/update"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// Providers documents can be published to
+const (
+	ProviderSwaggerHub = "swaggerhub"
+	ProviderStoplight  = "stoplight"
+	ProviderBackstage  = "backstage"
+)
+
+// Providers lists the supported providers
+var Providers = []string{ProviderSwaggerHub, ProviderStoplight, ProviderBackstage}
+
+// SwaggerHubURL is the registry API of SwaggerHub SaaS
+const SwaggerHubURL = "https://api.swaggerhub.com"
+
+// StoplightCommand is the Stoplight CLI, installed with npm install -g @stoplight/cli
+const StoplightCommand = "stoplight"
+
+// DefaultBackstageOutput is the entity file written for Backstage by default
+const DefaultBackstageOutput = "catalog-info.yaml"
+
+// Options configures a publication
+type Options struct {
+	Name      string // name of the API in the catalog (default: from the title of the document)
+	Owner     string // SwaggerHub owner, or the owner of the Backstage entity
+	APIKey    string // SwaggerHub API key or Stoplight CI token
+	Bump      string // patch, minor, or major: publish the next version after the latest published one
+	Private   bool   // publish a private SwaggerHub API
+	URL       string // registry API of an on-premise SwaggerHub (default: SwaggerHubURL)
+	Branch    string // Stoplight branch to push to (default: the project's default branch)
+	Output    string // Backstage entity file (default: DefaultBackstageOutput)
+	Lifecycle string // lifecycle of the Backstage entity (default: production)
+	System    string // system the Backstage entity belongs to, if any
+}
+
+// Result describes a publication
+type Result struct {
+	Version  string // version of the API that was published
+	Location string // where the published document can be found
+}
+
+// Publish publishes a document to a provider. With Options.Bump, the version of the document is
+// set to the next version after the latest published one.
+func Publish(ctx context.Context, client *http.Client, provider string, doc *openapi3.T, opts Options) (Result, error) {
+	if doc.Info == nil {
+		return Result{}, fmt.Errorf("document has no info")
+	}
+	if opts.Name == "" {
+		opts.Name = Slug(doc.Info.Title)
+	}
+	if opts.Name == "" {
+		return Result{}, fmt.Errorf("API name is empty, the document needs a title")
+	}
+	if opts.Bump != "" {
+		if _, err := BumpVersion("0.0.0", opts.Bump); err != nil {
+			return Result{}, err
+		}
+	}
+
+	switch provider {
+	case ProviderSwaggerHub:
+		return publishSwaggerHub(ctx, client, doc, opts)
+	case ProviderStoplight:
+		return publishStoplight(ctx, doc, opts)
+	case ProviderBackstage:
+		return publishBackstage(doc, opts)
+	default:
+		return Result{}, fmt.Errorf("unknown provider %q, expected %s", provider, strings.Join(Providers, ", "))
+	}
+}
+
+// BumpVersion returns the next patch, minor, or major version after a version such as 1.2.3 or
+// v1.2, keeping a leading v
+func BumpVersion(version, part string) (string, error) {
+	prefix := ""
+	if strings.HasPrefix(version, "v") {
+		prefix = "v"
+	}
+	core := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+
+	numbers := [3]int{}
+	for i, field := range strings.Split(core, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil || i >= len(numbers) {
+			return "", fmt.Errorf("version %q is not of the form major.minor.patch", version)
+		}
+		numbers[i] = n
+	}
+
+	switch part {
+	case "major":
+		numbers = [3]int{numbers[0] + 1, 0, 0}
+	case "minor":
+		numbers = [3]int{numbers[0], numbers[1] + 1, 0}
+	case "patch":
+		numbers[2]++
+	default:
+		return "", fmt.Errorf("invalid version bump %q, expected patch, minor, or major", part)
+	}
+	return fmt.Sprintf("%s%d.%d.%d", prefix, numbers[0], numbers[1], numbers[2]), nil
+}
+
+// slugPattern matches the runs of characters catalogs don't allow in names
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug turns a title such as "Orders API" into a catalog name such as orders-api
+func Slug(title string) string {
+	return strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(title), "-"), "-")
+}
+
+// applyBump sets the version of the document to the next one after latest, or leaves it when
+// nothing was published yet
+func applyBump(doc *openapi3.T, latest string, bump string) error {
+	if bump == "" || latest == "" {
+		return nil
+	}
+	next, err := BumpVersion(latest, bump)
+	if err != nil {
+		return err
+	}
+	doc.Info.Version = next
+	return nil
+}
+
+// swaggerHubAPI is the APIs.json listing of the versions of a SwaggerHub API
+type swaggerHubAPI struct {
+	APIs []struct {
+		Properties []struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"properties"`
+	} `json:"apis"`
+}
+
+// swaggerHubError is the body of a failed SwaggerHub request
+type swaggerHubError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// publishSwaggerHub saves the document as a version of an API in the SwaggerHub registry
+func publishSwaggerHub(ctx context.Context, client *http.Client, doc *openapi3.T, opts Options) (Result, error) {
+	if opts.Owner == "" {
+		return Result{}, fmt.Errorf("SwaggerHub needs the owner of the API")
+	}
+	if opts.APIKey == "" {
+		return Result{}, fmt.Errorf("SwaggerHub needs an API key")
+	}
+	base := strings.TrimSuffix(opts.URL, "/")
+	if base == "" {
+		base = SwaggerHubURL
+	}
+	apiURL := fmt.Sprintf("%s/apis/%s/%s", base, url.PathEscape(opts.Owner), url.PathEscape(opts.Name))
+
+	if opts.Bump != "" {
+		latest, err := swaggerHubLatestVersion(ctx, client, apiURL, opts.APIKey)
+		if err != nil {
+			return Result{}, err
+		}
+		if err := applyBump(doc, latest, opts.Bump); err != nil {
+			return Result{}, err
+		}
+	}
+
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return Result{}, err
+	}
+	query := url.Values{}
+	query.Set("isPrivate", strconv.FormatBool(opts.Private))
+	query.Set("version", doc.Info.Version)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+"?"+query.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Authorization", opts.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return Result{}, swaggerHubFailure(resp, doc.Info.Version)
+	}
+
+	location := fmt.Sprintf("https://app.swaggerhub.com/apis/%s/%s/%s", opts.Owner, opts.Name, doc.Info.Version)
+	if base != SwaggerHubURL {
+		location = apiURL + "/" + url.PathEscape(doc.Info.Version)
+	}
+	return Result{Version: doc.Info.Version, Location: location}, nil
+}
+
+// swaggerHubLatestVersion returns the latest version of a SwaggerHub API, or "" when it doesn't
+// exist yet
+func swaggerHubLatestVersion(ctx context.Context, client *http.Client, apiURL string, apiKey string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", swaggerHubFailure(resp, "")
+	}
+
+	var api swaggerHubAPI
+	if err := json.NewDecoder(resp.Body).Decode(&api); err != nil {
+		return "", fmt.Errorf("invalid SwaggerHub API listing: %v", err)
+	}
+	latest := ""
+	for _, version := range api.APIs {
+		for _, property := range version.Properties {
+			if property.Type == "X-Version" && (latest == "" || update.IsNewer(property.Value, latest)) {
+				latest = property.Value
+			}
+		}
+	}
+	return latest, nil
+}
+
+// swaggerHubFailure describes a failed SwaggerHub request, pointing out --bump for versions that
+// were published and became read-only
+func swaggerHubFailure(resp *http.Response, version string) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	message := strings.TrimSpace(string(data))
+	var failure swaggerHubError
+	if json.Unmarshal(data, &failure) == nil && failure.Message != "" {
+		message = failure.Message
+	}
+	if resp.StatusCode == http.StatusForbidden && version != "" {
+		return fmt.Errorf("SwaggerHub returned %s: %s (published versions are read-only, publish a new one with a version bump)", resp.Status, message)
+	}
+	if message != "" {
+		return fmt.Errorf("SwaggerHub returned %s: %s", resp.Status, message)
+	}
+	return fmt.Errorf("SwaggerHub returned %s", resp.Status)
+}
+
+// publishStoplight pushes the document to the Stoplight project of a CI token with the
+// Stoplight CLI. Stoplight keeps no versions to bump, the document's version is published.
+func publishStoplight(ctx context.Context, doc *openapi3.T, opts Options) (Result, error) {
+	if opts.APIKey == "" {
+		return Result{}, fmt.Errorf("Stoplight needs the CI token of the project")
+	}
+	if opts.Bump != "" {
+		return Result{}, fmt.Errorf("Stoplight doesn't report published versions to bump, set the version of the document instead")
+	}
+	if _, err := exec.LookPath(StoplightCommand); err != nil {
+		return Result{}, fmt.Errorf("the Stoplight CLI (%s) is not installed: npm install -g @stoplight/cli", StoplightCommand)
+	}
+
+	dir, err := os.MkdirTemp("", "swagdoc-stoplight-")
+	if err != nil {
+		return Result{}, err
+	}
+	defer os.RemoveAll(dir)
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return Result{}, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, opts.Name+".json"), data, 0644); err != nil {
+		return Result{}, err
+	}
+
+	args := []string{"push", "--ci-token", opts.APIKey, "--directory", dir}
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch)
+	}
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, StoplightCommand, args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return Result{}, fmt.Errorf("stoplight push failed: %v: %s", err, strings.TrimSpace(output.String()))
+	}
+	return Result{Version: doc.Info.Version, Location: "the Stoplight project of the CI token"}, nil
+}
+
+// backstageEntity is a Backstage API entity
+type backstageEntity struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   backstageMetadata `yaml:"metadata"`
+	Spec       backstageSpec     `yaml:"spec"`
+}
+
+type backstageMetadata struct {
+	Name        string `yaml:"name"`
+	Title       string `yaml:"title,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type backstageSpec struct {
+	Type       string `yaml:"type"`
+	Lifecycle  string `yaml:"lifecycle"`
+	Owner      string `yaml:"owner"`
+	System     string `yaml:"system,omitempty"`
+	Definition string `yaml:"definition"`
+}
+
+// publishBackstage writes the document as the definition of a Backstage API entity, to be
+// committed next to the code or registered with the catalog. The version of the entity file
+// written before is the latest published one.
+func publishBackstage(doc *openapi3.T, opts Options) (Result, error) {
+	if opts.Owner == "" {
+		return Result{}, fmt.Errorf("Backstage entities need an owner")
+	}
+	output := opts.Output
+	if output == "" {
+		output = DefaultBackstageOutput
+	}
+	lifecycle := opts.Lifecycle
+	if lifecycle == "" {
+		lifecycle = "production"
+	}
+
+	if opts.Bump != "" {
+		latest, err := backstageVersion(output)
+		if err != nil {
+			return Result{}, err
+		}
+		if err := applyBump(doc, latest, opts.Bump); err != nil {
+			return Result{}, err
+		}
+	}
+
+	definition, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return Result{}, err
+	}
+	entity := backstageEntity{
+		APIVersion: "backstage.io/v1alpha1",
+		Kind:       "API",
+		Metadata: backstageMetadata{
+			Name:        opts.Name,
+			Title:       doc.Info.Title,
+			Description: doc.Info.Description,
+		},
+		Spec: backstageSpec{
+			Type:       "openapi",
+			Lifecycle:  lifecycle,
+			Owner:      opts.Owner,
+			System:     opts.System,
+			Definition: string(definition) + "\n",
+		},
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(entity); err != nil {
+		return Result{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return Result{}, err
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		return Result{}, err
+	}
+	return Result{Version: doc.Info.Version, Location: output}, nil
+}
+
+// backstageVersion returns the version of the definition of an existing entity file, or "" when
+// there is none
+func backstageVersion(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var entity backstageEntity
+	if err := yaml.Unmarshal(data, &entity); err != nil {
+		return "", fmt.Errorf("invalid Backstage entity %s: %v", path, err)
+	}
+	var definition struct {
+		Info struct {
+			Version string `yaml:"version"`
+		} `yaml:"info"`
+	}
+	if err := yaml.Unmarshal([]byte(entity.Spec.Definition), &definition); err != nil {
+		return "", fmt.Errorf("invalid definition in Backstage entity %s: %v", path, err)
+	}
+	return definition.Info.Version, nil
+}
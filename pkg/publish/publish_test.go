@@ -0,0 +1,216 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+func testDoc() *openapi3.T {
+	return &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "Orders API", Description: "Orders", Version: "1.0.0"},
+		Paths:   openapi3.NewPaths(),
+	}
+}
+
+func TestBumpVersion(t *testing.T) {
+	tests := []struct {
+		version, part, expected string
+	}{
+		{"1.2.3", "patch", "1.2.4"},
+		{"1.2.3", "minor", "1.3.0"},
+		{"1.2.3", "major", "2.0.0"},
+		{"v1.2", "patch", "v1.2.1"},
+		{"2", "minor", "2.1.0"},
+		{"1.2.3-rc.1", "patch", "1.2.4"},
+	}
+	for _, test := range tests {
+		got, err := BumpVersion(test.version, test.part)
+		if err != nil {
+			t.Errorf("BumpVersion(%q, %q) failed: %v", test.version, test.part, err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("BumpVersion(%q, %q) = %q, expected %q", test.version, test.part, got, test.expected)
+		}
+	}
+
+	if _, err := BumpVersion("latest", "patch"); err == nil {
+		t.Error("Expected an error for a version that isn't numeric")
+	}
+	if _, err := BumpVersion("1.0.0", "huge"); err == nil {
+		t.Error("Expected an error for an unknown bump")
+	}
+}
+
+func TestSlug(t *testing.T) {
+	if got := Slug("  Orders & Payments API v2 "); got != "orders-payments-api-v2" {
+		t.Errorf("Unexpected slug %q", got)
+	}
+}
+
+func TestPublishSwaggerHub(t *testing.T) {
+	var saved struct {
+		query string
+		auth  string
+		title string
+	}
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/acme/orders-api" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"apis":[
+				{"properties":[{"type":"Swagger","url":"..."},{"type":"X-Version","value":"1.9.0"}]},
+				{"properties":[{"type":"X-Version","value":"1.10.2"}]}
+			]}`))
+		case http.MethodPost:
+			var doc openapi3.T
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &doc); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			saved.query, saved.auth, saved.title = r.URL.RawQuery, r.Header.Get("Authorization"), doc.Info.Title
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer registry.Close()
+
+	doc := testDoc()
+	result, err := Publish(context.Background(), registry.Client(), ProviderSwaggerHub, doc, Options{
+		Owner:  "acme",
+		APIKey: "secret",
+		Bump:   "minor",
+		URL:    registry.URL,
+	})
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	// 1.10.2 is the latest version, not 1.9.0
+	if result.Version != "1.11.0" || doc.Info.Version != "1.11.0" {
+		t.Errorf("Expected version 1.11.0, got %s", result.Version)
+	}
+	if saved.auth != "secret" || saved.title != "Orders API" {
+		t.Errorf("Unexpected request: %+v", saved)
+	}
+	if !strings.Contains(saved.query, "version=1.11.0") || !strings.Contains(saved.query, "isPrivate=false") {
+		t.Errorf("Unexpected query %q", saved.query)
+	}
+	if result.Location != registry.URL+"/apis/acme/orders-api/1.11.0" {
+		t.Errorf("Unexpected location %q", result.Location)
+	}
+}
+
+func TestPublishSwaggerHubErrors(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"code":403,"message":"Cannot modify a published API version"}`))
+	}))
+	defer registry.Close()
+
+	_, err := Publish(context.Background(), registry.Client(), ProviderSwaggerHub, testDoc(), Options{
+		Owner:  "acme",
+		APIKey: "secret",
+		URL:    registry.URL,
+	})
+	if err == nil || !strings.Contains(err.Error(), "Cannot modify a published API version") || !strings.Contains(err.Error(), "version bump") {
+		t.Errorf("Expected the SwaggerHub message and a hint, got %v", err)
+	}
+
+	if _, err := Publish(context.Background(), registry.Client(), ProviderSwaggerHub, testDoc(), Options{APIKey: "secret"}); err == nil {
+		t.Error("Expected an error without an owner")
+	}
+	if _, err := Publish(context.Background(), registry.Client(), "apiary", testDoc(), Options{}); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+}
+
+func TestPublishBackstage(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "catalog-info.yaml")
+	opts := Options{Owner: "team-orders", System: "commerce", Output: output, Bump: "patch"}
+
+	// Nothing was published yet, so the document's version is kept
+	result, err := Publish(context.Background(), http.DefaultClient, ProviderBackstage, testDoc(), opts)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if result.Version != "1.0.0" || result.Location != output {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entity backstageEntity
+	if err := yaml.Unmarshal(data, &entity); err != nil {
+		t.Fatalf("Invalid entity: %v", err)
+	}
+	if entity.Kind != "API" || entity.Metadata.Name != "orders-api" || entity.Spec.Type != "openapi" ||
+		entity.Spec.Owner != "team-orders" || entity.Spec.System != "commerce" || entity.Spec.Lifecycle != "production" {
+		t.Errorf("Unexpected entity %+v", entity)
+	}
+	if !strings.Contains(entity.Spec.Definition, `"title": "Orders API"`) {
+		t.Errorf("Expected the document as the definition, got %s", entity.Spec.Definition)
+	}
+
+	// Publishing again bumps the version of the entity file
+	result, err = Publish(context.Background(), http.DefaultClient, ProviderBackstage, testDoc(), opts)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if result.Version != "1.0.1" {
+		t.Errorf("Expected version 1.0.1, got %s", result.Version)
+	}
+}
+
+func TestPublishStoplight(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake Stoplight CLI is a shell script")
+	}
+
+	// A fake Stoplight CLI recording its arguments and the pushed files
+	dir := t.TempDir()
+	record := filepath.Join(dir, "record")
+	script := "#!/bin/sh\necho \"$@\" > " + record + "\nwhile [ \"$1\" != --directory ]; do shift; done\nls \"$2\" >> " + record + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "stoplight"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result, err := Publish(context.Background(), http.DefaultClient, ProviderStoplight, testDoc(), Options{APIKey: "token", Branch: "main"})
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if result.Version != "1.0.0" {
+		t.Errorf("Expected version 1.0.0, got %s", result.Version)
+	}
+
+	data, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "push --ci-token token --directory") || !strings.Contains(string(data), "--branch main") ||
+		!strings.Contains(string(data), "orders-api.json") {
+		t.Errorf("Unexpected Stoplight CLI invocation:\n%s", data)
+	}
+
+	if _, err := Publish(context.Background(), http.DefaultClient, ProviderStoplight, testDoc(), Options{APIKey: "token", Bump: "patch"}); err == nil {
+		t.Error("Expected an error for a version bump on Stoplight")
+	}
+}
@@ -0,0 +1,244 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"golang.org/x/oauth2/google"
+)
+
+// Object storage destinations of generated documents
+const (
+	SchemeS3  = "s3://"
+	SchemeGCS = "gs://"
+)
+
+// DefaultS3Region is the region of S3 uploads when the AWS configuration sets none
+const DefaultS3Region = "us-east-1"
+
+// GCSURL is the Cloud Storage endpoint, replaced with the emulator given by STORAGE_EMULATOR_HOST
+const GCSURL = "https://storage.googleapis.com"
+
+// GCSScope is the OAuth scope of the access tokens uploads to Cloud Storage use
+const GCSScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// IsRemote checks whether an output is an object storage destination, s3://bucket/key or
+// gs://bucket/key, rather than a local file
+func IsRemote(output string) bool {
+	return strings.HasPrefix(output, SchemeS3) || strings.HasPrefix(output, SchemeGCS)
+}
+
+// Upload stores data at an object storage destination:
+//
+//   - s3://bucket/key, signed with the credentials and region of the AWS SDK's default chain:
+//     the AWS_* environment variables, the shared config and credentials files with AWS_PROFILE,
+//     SSO, web identity tokens, and container or instance metadata. AWS_ENDPOINT_URL_S3 or
+//     AWS_ENDPOINT_URL point it at S3-compatible storage such as MinIO.
+//   - gs://bucket/key, authorized with the GOOGLE_OAUTH_ACCESS_TOKEN access token, or else with
+//     Google's Application Default Credentials: GOOGLE_APPLICATION_CREDENTIALS, the file of
+//     gcloud auth application-default login, and the metadata server on Google Cloud
+func Upload(ctx context.Context, client *http.Client, destination string, data []byte, contentType string) error {
+	switch {
+	case strings.HasPrefix(destination, SchemeS3):
+		bucket, key, err := splitObject(destination, SchemeS3)
+		if err != nil {
+			return err
+		}
+		return uploadS3(ctx, client, bucket, key, data, contentType)
+	case strings.HasPrefix(destination, SchemeGCS):
+		bucket, key, err := splitObject(destination, SchemeGCS)
+		if err != nil {
+			return err
+		}
+		return uploadGCS(ctx, client, bucket, key, data, contentType)
+	default:
+		return fmt.Errorf("unsupported destination %s, expected s3://bucket/key or gs://bucket/key", destination)
+	}
+}
+
+// Put uploads data to a URL with an HTTP PUT, sending the given headers, e.g. Authorization
+func Put(ctx context.Context, client *http.Client, target string, data []byte, contentType string, header http.Header) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid upload URL %q, expected an http or https URL", target)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return send(client, req, u.Redacted())
+}
+
+// splitObject splits an object storage destination into its bucket and key
+func splitObject(destination string, scheme string) (string, string, error) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(destination, scheme), "/")
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return "", "", fmt.Errorf("invalid destination %s, expected %sbucket/key", destination, scheme)
+	}
+	return bucket, key, nil
+}
+
+// send sends an upload request and fails unless it succeeded, with the response body, which
+// explains why for S3, Cloud Storage, and most servers
+func send(client *http.Client, req *http.Request, destination string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if message := strings.TrimSpace(string(body)); message != "" {
+		return fmt.Errorf("upload to %s failed with %s: %s", destination, resp.Status, message)
+	}
+	return fmt.Errorf("upload to %s failed with %s", destination, resp.Status)
+}
+
+// uploadS3 puts an object into an S3 bucket, with a virtual-hosted-style URL on AWS and a
+// path-style one on a custom endpoint
+func uploadS3(ctx context.Context, client *http.Client, bucket string, key string, data []byte, contentType string) error {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load the AWS configuration: %v", err)
+	}
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("S3 uploads found no AWS credentials in the environment, the shared config and credentials files, SSO, web identity, or instance metadata: %v", err)
+	}
+	region := cfg.Region
+	if region == "" {
+		region = DefaultS3Region
+	}
+
+	endpoint := fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, region)
+	path := "/" + key
+	if custom := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"); custom != "" {
+		endpoint = strings.TrimSuffix(custom, "/")
+		path = "/" + bucket + "/" + key
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	u.Path += path
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if err := signS3(ctx, req, data, creds, region, time.Now()); err != nil {
+		return err
+	}
+	return send(client, req, SchemeS3+bucket+"/"+key)
+}
+
+// signS3 signs an S3 request with AWS Signature Version 4, covering the host and every header
+// of the request. Paths are escaped once, by awsEscapePath, the way S3 expects.
+func signS3(ctx context.Context, req *http.Request, payload []byte, creds aws.Credentials, region string, now time.Time) error {
+	payloadHash := sha256.Sum256(payload)
+	hash := hex.EncodeToString(payloadHash[:])
+	req.Header.Set("X-Amz-Content-Sha256", hash)
+	req.URL.RawPath = awsEscapePath(req.URL.Path)
+	signer := v4.NewSigner(func(options *v4.SignerOptions) {
+		options.DisableURIPathEscaping = true
+	})
+	return signer.SignHTTP(ctx, creds, req, hash, "s3", region, now)
+}
+
+// awsEscapePath escapes a path the way AWS signatures expect: every byte except unreserved
+// characters and slashes percent-encoded
+func awsEscapePath(path string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+	return escaped.String()
+}
+
+// uploadGCS uploads an object to a Cloud Storage bucket with the JSON API's media upload, which
+// the emulators support as well
+func uploadGCS(ctx context.Context, client *http.Client, bucket string, key string, data []byte, contentType string) error {
+	endpoint := GCSURL
+	emulator := os.Getenv("STORAGE_EMULATOR_HOST")
+	if emulator != "" {
+		endpoint = strings.TrimSuffix(emulator, "/")
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+	}
+
+	query := url.Values{}
+	query.Set("uploadType", "media")
+	query.Set("name", key)
+	target := endpoint + "/upload/storage/v1/b/" + url.PathEscape(bucket) + "/o?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	// Emulators don't check credentials
+	if emulator == "" {
+		token, err := gcsToken(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return send(client, req, SchemeGCS+bucket+"/"+key)
+}
+
+// gcsToken returns the access token for Cloud Storage, from GOOGLE_OAUTH_ACCESS_TOKEN or the
+// Application Default Credentials
+func gcsToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	creds, err := google.FindDefaultCredentials(ctx, GCSScope)
+	if err != nil {
+		return "", fmt.Errorf("Cloud Storage uploads found no access token in GOOGLE_OAUTH_ACCESS_TOKEN and no Application Default Credentials, set GOOGLE_APPLICATION_CREDENTIALS or run gcloud auth application-default login: %v", err)
+	}
+	token, err := creds.TokenSource.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to get a Cloud Storage access token: %v", err)
+	}
+	return token.AccessToken, nil
+}
+
+// firstEnv returns the first of the environment variables that is set
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
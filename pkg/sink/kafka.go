@@ -0,0 +1,116 @@
+package sink
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+)
+
+// kafkaClientID identifies the sink in broker logs and quotas
+const kafkaClientID = "swagdoc"
+
+// kafkaAttempts is how many times a batch is sent before publishing fails, with the partition
+// leaders looked up again in between
+const kafkaAttempts = 3
+
+// KafkaSink publishes transactions to a Kafka topic, spreading them over its partitions by
+// method and path. Messages are acknowledged by all in-sync replicas.
+type KafkaSink struct {
+	TLS  *tls.Config    // TLS configuration, nil for a plaintext connection
+	SASL sasl.Mechanism // authentication, nil for none
+
+	brokers []string // bootstrap brokers, host:port
+	topic   string
+	source  string
+
+	mutex  sync.Mutex
+	writer *kafka.Writer
+}
+
+// NewKafkaSink creates a sink publishing to a topic through the given bootstrap brokers. Messages
+// get a Swagdoc-Source header with source, unless it is empty.
+func NewKafkaSink(brokers []string, topic string, source string) *KafkaSink {
+	return &KafkaSink{brokers: brokers, topic: topic, source: source}
+}
+
+// Store publishes a transaction
+func (k *KafkaSink) Store(transaction proxy.APITransaction) error {
+	return k.StoreAll([]proxy.APITransaction{transaction})
+}
+
+// StoreAll publishes several transactions, and waits until they are acknowledged
+func (k *KafkaSink) StoreAll(transactions []proxy.APITransaction) error {
+	messages := make([]kafka.Message, len(transactions))
+	for i, transaction := range transactions {
+		value, err := encode(transaction)
+		if err != nil {
+			return err
+		}
+		messages[i] = kafka.Message{Key: []byte(messageKey(transaction)), Value: value}
+		if k.source != "" {
+			messages[i].Headers = []kafka.Header{{Key: SourceHeader, Value: []byte(k.source)}}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), kafkaAttempts*Timeout)
+	defer cancel()
+	if err := k.getWriter().WriteMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("failed to publish to Kafka topic %s: %v", k.topic, err)
+	}
+	return nil
+}
+
+// GetAll fails, since transactions are read from the topic
+func (k *KafkaSink) GetAll() ([]proxy.APITransaction, error) {
+	return nil, ErrWriteOnly
+}
+
+// Clear fails, since transactions are read from the topic
+func (k *KafkaSink) Clear() error {
+	return ErrWriteOnly
+}
+
+// Close closes the connections to the brokers
+func (k *KafkaSink) Close() error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if k.writer == nil {
+		return nil
+	}
+	err := k.writer.Close()
+	k.writer = nil
+	return err
+}
+
+// getWriter returns the writer publishing to the topic, creating it on first use
+func (k *KafkaSink) getWriter() *kafka.Writer {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if k.writer == nil {
+		k.writer = &kafka.Writer{
+			Addr:         kafka.TCP(k.brokers...),
+			Topic:        k.topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			MaxAttempts:  kafkaAttempts,
+			BatchTimeout: 10 * time.Millisecond,
+			ReadTimeout:  Timeout,
+			WriteTimeout: Timeout,
+			Transport: &kafka.Transport{
+				Dial:     (&net.Dialer{Timeout: Timeout}).DialContext,
+				ClientID: kafkaClientID,
+				TLS:      k.TLS,
+				SASL:     k.SASL,
+			},
+		}
+	}
+	return k.writer
+}
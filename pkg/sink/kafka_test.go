@@ -0,0 +1,279 @@
+package sink
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/segmentio/kafka-go/protocol/apiversions"
+	"github.com/segmentio/kafka-go/protocol/metadata"
+	"github.com/segmentio/kafka-go/protocol/produce"
+)
+
+// kafkaRecord is a message received by fakeKafka
+type kafkaRecord struct {
+	key     []byte
+	value   []byte
+	headers []protocol.Header
+}
+
+// fakeKafka is a single broker answering ApiVersions, Metadata, and Produce requests for a topic
+type fakeKafka struct {
+	t          *testing.T
+	listener   net.Listener
+	topic      string
+	partitions int32
+
+	mutex         sync.Mutex
+	records       map[int32][]kafkaRecord // by partition
+	produces      int
+	notLeaderOnce bool // fail the first produce as if leadership moved
+}
+
+func newFakeKafka(t *testing.T, topic string, partitions int32) *fakeKafka {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serveFakeKafka(t, listener, topic, partitions)
+}
+
+// newFakeKafkaTLS starts a broker accepting TLS connections, and returns the configuration of
+// clients trusting it
+func newFakeKafkaTLS(t *testing.T, topic string, partitions int32) (*fakeKafka, *tls.Config) {
+	// The test server only lends its certificate
+	server := httptest.NewTLSServer(nil)
+	server.Close()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: server.TLS.Certificates})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	return serveFakeKafka(t, listener, topic, partitions), &tls.Config{RootCAs: roots}
+}
+
+func serveFakeKafka(t *testing.T, listener net.Listener, topic string, partitions int32) *fakeKafka {
+	broker := &fakeKafka{t: t, listener: listener, topic: topic, partitions: partitions, records: make(map[int32][]kafkaRecord)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go broker.serve(conn)
+		}
+	}()
+	return broker
+}
+
+func (f *fakeKafka) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		version, correlation, _, request, err := protocol.ReadRequest(conn)
+		if err != nil {
+			return
+		}
+		var response protocol.Message
+		switch request := request.(type) {
+		case *apiversions.Request:
+			response = &apiversions.Response{ApiKeys: []apiversions.ApiKeyResponse{
+				{ApiKey: int16(protocol.ApiVersions), MaxVersion: 2},
+				{ApiKey: int16(protocol.Metadata), MaxVersion: 8},
+				{ApiKey: int16(protocol.Produce), MaxVersion: 8},
+			}}
+		case *metadata.Request:
+			response = f.answerMetadata()
+		case *produce.Request:
+			response = f.answerProduce(request)
+		default:
+			f.t.Errorf("Unexpected request %T", request)
+			return
+		}
+		if err := protocol.WriteResponse(conn, version, correlation, response); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeKafka) answerMetadata() *metadata.Response {
+	host, port, _ := net.SplitHostPort(f.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	topic := metadata.ResponseTopic{Name: f.topic}
+	for partition := int32(0); partition < f.partitions; partition++ {
+		topic.Partitions = append(topic.Partitions, metadata.ResponsePartition{
+			PartitionIndex: partition,
+			LeaderID:       1,
+			ReplicaNodes:   []int32{1},
+			IsrNodes:       []int32{1},
+		})
+	}
+	return &metadata.Response{
+		Brokers:      []metadata.ResponseBroker{{NodeID: 1, Host: host, Port: int32(portNumber)}},
+		ControllerID: 1,
+		Topics:       []metadata.ResponseTopic{topic},
+	}
+}
+
+func (f *fakeKafka) answerProduce(request *produce.Request) *produce.Response {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.produces++
+	if request.Acks != -1 {
+		f.t.Errorf("Expected acknowledgements by all replicas, got %d", request.Acks)
+	}
+
+	response := &produce.Response{}
+	for _, topic := range request.Topics {
+		answer := produce.ResponseTopic{Topic: topic.Topic}
+		for _, partition := range topic.Partitions {
+			result := produce.ResponsePartition{Partition: partition.Partition}
+			if f.notLeaderOnce {
+				f.notLeaderOnce = false
+				result.ErrorCode = 6 // NOT_LEADER_OR_FOLLOWER
+			} else {
+				f.records[partition.Partition] = append(f.records[partition.Partition], f.readRecords(partition.RecordSet)...)
+			}
+			answer.Partitions = append(answer.Partitions, result)
+		}
+		response.Topics = append(response.Topics, answer)
+	}
+	return response
+}
+
+// readRecords reads the records of a record set
+func (f *fakeKafka) readRecords(set protocol.RecordSet) []kafkaRecord {
+	var records []kafkaRecord
+	for {
+		record, err := set.Records.ReadRecord()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		if err != nil {
+			f.t.Errorf("Invalid record: %v", err)
+			return records
+		}
+		key, _ := protocol.ReadAll(record.Key)
+		value, _ := protocol.ReadAll(record.Value)
+		records = append(records, kafkaRecord{key: key, value: value, headers: append([]protocol.Header(nil), record.Headers...)})
+	}
+}
+
+func testTransactions() []proxy.APITransaction {
+	return []proxy.APITransaction{
+		{Request: proxy.RequestData{Method: "GET", Path: "/users"}, Response: proxy.ResponseData{StatusCode: 200}},
+		{Request: proxy.RequestData{Method: "POST", Path: "/users"}, Response: proxy.ResponseData{StatusCode: 201}},
+		{Request: proxy.RequestData{Method: "GET", Path: "/orders"}, Response: proxy.ResponseData{StatusCode: 200}},
+	}
+}
+
+func TestKafkaSink(t *testing.T) {
+	broker := newFakeKafka(t, "captures", 3)
+	sink := NewKafkaSink([]string{broker.listener.Addr().String()}, "captures", "staging")
+	defer sink.Close()
+
+	if err := sink.StoreAll(testTransactions()); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Store(testTransactions()[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	keys := make(map[string]int32)
+	count := 0
+	for partition, records := range broker.records {
+		for _, record := range records {
+			count++
+			key := string(record.key)
+			if previous, ok := keys[key]; ok && previous != partition {
+				t.Errorf("Expected the messages of %s in a single partition, got %d and %d", key, previous, partition)
+			}
+			keys[key] = partition
+
+			var transaction proxy.APITransaction
+			if err := json.Unmarshal(record.value, &transaction); err != nil {
+				t.Errorf("Expected a transaction as message, got %s", record.value)
+			} else if messageKey(transaction) != key {
+				t.Errorf("Expected the key of %s to be %s, got %s", record.value, messageKey(transaction), key)
+			}
+			if len(record.headers) != 1 || record.headers[0].Key != SourceHeader || string(record.headers[0].Value) != "staging" {
+				t.Errorf("Expected the source header, got %v", record.headers)
+			}
+		}
+	}
+	if count != 4 || len(keys) != 3 {
+		t.Errorf("Expected 4 messages with 3 keys, got %d with %v", count, keys)
+	}
+}
+
+func TestKafkaSinkTLS(t *testing.T) {
+	broker, config := newFakeKafkaTLS(t, "captures", 1)
+	sink := NewKafkaSink([]string{broker.listener.Addr().String()}, "captures", "")
+	defer sink.Close()
+
+	// The broker's certificate is only trusted with the configuration
+	if err := sink.Store(testTransactions()[0]); err == nil {
+		t.Fatal("Expected a plaintext connection to a TLS broker to fail")
+	}
+	sink.Close()
+	sink.TLS = config
+	if err := sink.Store(testTransactions()[0]); err != nil {
+		t.Fatal(err)
+	}
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	if len(broker.records[0]) != 1 {
+		t.Errorf("Expected 1 message over TLS, got %d", len(broker.records[0]))
+	}
+}
+
+func TestKafkaSinkLeaderMoved(t *testing.T) {
+	broker := newFakeKafka(t, "captures", 1)
+	broker.notLeaderOnce = true
+	sink := NewKafkaSink([]string{broker.listener.Addr().String()}, "captures", "")
+	defer sink.Close()
+
+	if err := sink.StoreAll(testTransactions()); err != nil {
+		t.Fatal(err)
+	}
+
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	if broker.produces != 2 || len(broker.records[0]) != 3 {
+		t.Errorf("Expected the messages to be sent again, got %d messages after %d produce requests", len(broker.records[0]), broker.produces)
+	}
+	if headers := broker.records[0][0].headers; len(headers) != 0 {
+		t.Errorf("Expected no headers without a source, got %v", headers)
+	}
+}
+
+func TestKafkaSinkUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	sink := NewKafkaSink([]string{addr}, "captures", "")
+	defer sink.Close()
+	if err := sink.Store(testTransactions()[0]); err == nil {
+		t.Error("Expected an error without a broker")
+	}
+	if _, err := sink.GetAll(); err != ErrWriteOnly {
+		t.Errorf("Expected ErrWriteOnly, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package sink
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsReconnectWait is how long a dropped connection waits before reconnecting
+const natsReconnectWait = 250 * time.Millisecond
+
+// NATSSink publishes transactions to a NATS subject. Every batch is flushed, so that publishing
+// returns once the server has processed the messages.
+type NATSSink struct {
+	Addr            string // host:port
+	Subject         string
+	Source          string
+	User            string
+	Password        string
+	Token           string
+	CredentialsFile string      // user JWT and NKey seed file, instead of a user or token
+	TLS             *tls.Config // TLS configuration, nil for a plaintext connection
+
+	mutex sync.Mutex
+	conn  *nats.Conn
+}
+
+// NewNATSSink creates a sink publishing to a subject on the server at addr. Messages get a
+// Swagdoc-Source header with source, unless it is empty or the server doesn't support headers.
+func NewNATSSink(addr string, subject string, source string) *NATSSink {
+	return &NATSSink{Addr: addr, Subject: subject, Source: source}
+}
+
+// Store publishes a transaction
+func (n *NATSSink) Store(transaction proxy.APITransaction) error {
+	return n.StoreAll([]proxy.APITransaction{transaction})
+}
+
+// StoreAll publishes several transactions, connecting first if needed. Dropped connections are
+// reconnected in the background, with the messages published meanwhile buffered.
+func (n *NATSSink) StoreAll(transactions []proxy.APITransaction) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	err := n.publish(transactions)
+	if errors.Is(err, nats.ErrConnectionClosed) && n.conn != nil && !n.conn.IsClosed() {
+		// The connection dropped before the server processed the messages, which are published
+		// again on the reconnected one
+		err = n.publish(transactions)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to publish to NATS subject %s on %s: %v", n.Subject, n.Addr, err)
+	}
+	return nil
+}
+
+// GetAll fails, since transactions are read from the subject
+func (n *NATSSink) GetAll() ([]proxy.APITransaction, error) {
+	return nil, ErrWriteOnly
+}
+
+// Clear fails, since transactions are read from the subject
+func (n *NATSSink) Clear() error {
+	return ErrWriteOnly
+}
+
+// Close closes the connection to the server
+func (n *NATSSink) Close() error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.conn != nil {
+		n.conn.Close()
+		n.conn = nil
+	}
+	return nil
+}
+
+// publish publishes transactions and waits for the server to process them. The source header is
+// left out for servers that don't support headers.
+func (n *NATSSink) publish(transactions []proxy.APITransaction) error {
+	if n.conn == nil {
+		conn, err := nats.Connect("nats://"+n.Addr, n.options()...)
+		if err != nil {
+			return err
+		}
+		n.conn = conn
+	}
+
+	for _, transaction := range transactions {
+		payload, err := encode(transaction)
+		if err != nil {
+			return err
+		}
+		message := nats.NewMsg(n.Subject)
+		message.Data = payload
+		if n.Source != "" && n.conn.HeadersSupported() {
+			message.Header.Set(SourceHeader, n.Source)
+		}
+		if err := n.conn.PublishMsg(message); err != nil {
+			return err
+		}
+	}
+	return n.conn.FlushTimeout(Timeout)
+}
+
+// options returns the connection options of the sink
+func (n *NATSSink) options() []nats.Option {
+	options := []nats.Option{
+		nats.Name("swagdoc"),
+		nats.Timeout(Timeout),
+		nats.ReconnectWait(natsReconnectWait),
+		nats.MaxReconnects(-1),
+	}
+	if n.TLS != nil {
+		options = append(options, nats.Secure(n.TLS))
+	}
+	switch {
+	case n.CredentialsFile != "":
+		options = append(options, nats.UserCredentials(n.CredentialsFile))
+	case n.User != "":
+		options = append(options, nats.UserInfo(n.User, n.Password))
+	case n.Token != "":
+		options = append(options, nats.Token(n.Token))
+	}
+	return options
+}
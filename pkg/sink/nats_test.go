@@ -0,0 +1,201 @@
+package sink
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// natsMessage is a message received by fakeNATS
+type natsMessage struct {
+	subject string
+	header  string
+	payload []byte
+}
+
+// natsConnect is the part of a client's CONNECT message the tests check
+type natsConnect struct {
+	Verbose bool   `json:"verbose"`
+	Headers bool   `json:"headers"`
+	User    string `json:"user"`
+	Pass    string `json:"pass"`
+	Token   string `json:"auth_token"`
+}
+
+// fakeNATS is a server accepting publishes, and rejecting connections with the wrong token
+type fakeNATS struct {
+	t        *testing.T
+	listener net.Listener
+	headers  bool
+	token    string
+
+	mutex    sync.Mutex
+	connects []natsConnect
+	messages []natsMessage
+	conns    []net.Conn
+}
+
+func newFakeNATS(t *testing.T, headers bool) *fakeNATS {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeNATS{t: t, listener: listener, headers: headers}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mutex.Lock()
+			server.conns = append(server.conns, conn)
+			server.mutex.Unlock()
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (f *fakeNATS) serve(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"fake\",\"proto\":1,\"headers\":%t,\"max_payload\":1048576}\r\n", f.headers)
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "CONNECT":
+			var connect natsConnect
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "CONNECT ")), &connect); err != nil {
+				f.t.Errorf("Invalid CONNECT: %v", err)
+			}
+			f.mutex.Lock()
+			f.connects = append(f.connects, connect)
+			f.mutex.Unlock()
+			if f.token != "" && connect.Token != f.token {
+				fmt.Fprint(conn, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case "PUB", "HPUB":
+			message := natsMessage{subject: fields[1]}
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return
+			}
+			headerSize := 0
+			if fields[0] == "HPUB" {
+				headerSize, _ = strconv.Atoi(fields[2])
+			}
+			message.header, message.payload = string(data[:headerSize]), data[headerSize:size]
+			f.mutex.Lock()
+			f.messages = append(f.messages, message)
+			f.mutex.Unlock()
+		default:
+			f.t.Errorf("Unexpected operation %q", line)
+		}
+	}
+}
+
+// dropConnections closes the connections of the clients
+func (f *fakeNATS) dropConnections() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, conn := range f.conns {
+		conn.Close()
+	}
+	f.conns = nil
+}
+
+func TestNATSSink(t *testing.T) {
+	server := newFakeNATS(t, true)
+	sink := NewNATSSink(server.listener.Addr().String(), "swagdoc.captures", "staging")
+	sink.User, sink.Password = "ada", "secret"
+	defer sink.Close()
+
+	if err := sink.StoreAll(testTransactions()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A dropped connection is reconnected
+	server.dropConnections()
+	if err := sink.Store(testTransactions()[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if len(server.connects) != 2 {
+		t.Fatalf("Expected 2 connections, got %d", len(server.connects))
+	}
+	if connect := server.connects[0]; connect.User != "ada" || connect.Pass != "secret" || !connect.Headers || connect.Verbose {
+		t.Errorf("Unexpected CONNECT %+v", connect)
+	}
+	if len(server.messages) != 4 {
+		t.Fatalf("Expected 4 messages, got %d", len(server.messages))
+	}
+	for i, message := range server.messages {
+		if message.subject != "swagdoc.captures" {
+			t.Errorf("Expected subject swagdoc.captures, got %s", message.subject)
+		}
+		if message.header != "NATS/1.0\r\nSwagdoc-Source: staging\r\n\r\n" {
+			t.Errorf("Expected the source header, got %q", message.header)
+		}
+		var transaction proxy.APITransaction
+		if err := json.Unmarshal(message.payload, &transaction); err != nil {
+			t.Errorf("Expected a transaction as message, got %s", message.payload)
+		} else if i < 3 && messageKey(transaction) != messageKey(testTransactions()[i]) {
+			t.Errorf("Expected message %d to be %s, got %s", i, messageKey(testTransactions()[i]), messageKey(transaction))
+		}
+	}
+}
+
+func TestNATSSinkWithoutHeaders(t *testing.T) {
+	server := newFakeNATS(t, false)
+	sink := NewNATSSink(server.listener.Addr().String(), "captures", "staging")
+	defer sink.Close()
+
+	if err := sink.Store(testTransactions()[0]); err != nil {
+		t.Fatal(err)
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if len(server.messages) != 1 || server.messages[0].header != "" {
+		t.Errorf("Expected a message without headers, got %+v", server.messages)
+	}
+}
+
+func TestNATSSinkRejected(t *testing.T) {
+	server := newFakeNATS(t, true)
+	server.token = "secret"
+	sink := NewNATSSink(server.listener.Addr().String(), "captures", "")
+	sink.Token = "wrong"
+	defer sink.Close()
+
+	err := sink.Store(testTransactions()[0])
+	if err == nil || !strings.Contains(strings.ToLower(err.Error()), "authorization violation") {
+		t.Errorf("Expected the server's error, got %v", err)
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if len(server.connects) != 1 {
+		t.Errorf("Expected a rejected connection not to be retried, got %d connections", len(server.connects))
+	}
+}
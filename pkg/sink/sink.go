@@ -0,0 +1,176 @@
+// Package sink publishes captured transactions to message brokers, so that traffic captured in
+// many environments can be aggregated into a central documentation pipeline. Sinks are
+// write-only storages: every transaction is published as a message holding its JSON encoding,
+// the encoding of the transactions in session files, and reading them back is left to the
+// consumers of the topic or subject.
+//
+// Destinations are URLs:
+//
+//   - kafka://[user:password@]broker1:9092,broker2:9092/topic publishes to a Kafka topic, keyed
+//     by the method and path of the transaction. Credentials are sent with SASL/PLAIN, or
+//     SASL/SCRAM with ?sasl=scram-sha-256 or ?sasl=scram-sha-512.
+//   - nats://[user:password@]host:4222/subject publishes to a NATS subject; a user without a
+//     password is sent as the token, and ?creds=file.creds authenticates with a credentials
+//     file instead.
+//
+// ?tls=true connects over TLS, verified against the system roots or the PEM certificates of
+// ?ca=file, and ?cert=file&key=file adds a client certificate. Credentials are only ever sent
+// over TLS: destinations with credentials but without TLS are refused.
+package sink
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// SourceHeader is the message header naming the environment transactions were captured in, when
+// a source is given
+const SourceHeader = "Swagdoc-Source"
+
+// Timeout bounds connecting to a broker and every request to it
+const Timeout = 10 * time.Second
+
+// ErrWriteOnly is returned when transactions are read from or cleared in a sink
+var ErrWriteOnly = errors.New("sinks only publish transactions, read them from the topic or subject")
+
+// Sink is a storage publishing the transactions it stores, which holds connections to be closed
+type Sink interface {
+	proxy.Storage
+	StoreAll(transactions []proxy.APITransaction) error
+	Close() error
+}
+
+// Open creates the sink of a destination URL, kafka://brokers/topic or nats://host/subject.
+// Messages are labeled with source, unless it is empty. No connection is made until the first
+// transaction is published.
+func Open(destination string, source string) (Sink, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		// The error quotes the URL, credentials included
+		return nil, errors.New("invalid sink URL")
+	}
+	name := strings.TrimPrefix(u.Path, "/")
+	query := u.Query()
+	tlsConfig, err := tlsConfigFor(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sink %s: %v", u.Redacted(), err)
+	}
+	if tlsConfig == nil && (u.User != nil || query.Get("creds") != "") {
+		return nil, fmt.Errorf("refusing to send the credentials of sink %s over a plaintext connection, add ?tls=true", u.Redacted())
+	}
+
+	switch u.Scheme {
+	case "kafka":
+		if u.Host == "" || name == "" {
+			return nil, fmt.Errorf("invalid sink %s, expected kafka://broker:9092/topic", u.Redacted())
+		}
+		sink := NewKafkaSink(strings.Split(u.Host, ","), name, source)
+		sink.TLS = tlsConfig
+		if u.User != nil {
+			password, _ := u.User.Password()
+			if sink.SASL, err = saslMechanism(query.Get("sasl"), u.User.Username(), password); err != nil {
+				return nil, fmt.Errorf("invalid sink %s: %v", u.Redacted(), err)
+			}
+		}
+		return sink, nil
+	case "nats":
+		if u.Host == "" || name == "" {
+			return nil, fmt.Errorf("invalid sink %s, expected nats://host:4222/subject", u.Redacted())
+		}
+		sink := NewNATSSink(u.Host, name, source)
+		sink.TLS = tlsConfig
+		sink.CredentialsFile = query.Get("creds")
+		if u.User != nil {
+			password, ok := u.User.Password()
+			if ok {
+				sink.User, sink.Password = u.User.Username(), password
+			} else {
+				sink.Token = u.User.Username()
+			}
+		}
+		return sink, nil
+	default:
+		return nil, fmt.Errorf("unsupported sink %s, expected kafka://brokers/topic or nats://host/subject", u.Redacted())
+	}
+}
+
+// tlsConfigFor returns the TLS configuration of the tls, ca, cert, and key query parameters of a
+// destination, or nil for a plaintext connection
+func tlsConfigFor(query url.Values) (*tls.Config, error) {
+	enabled := query.Get("ca") != "" || query.Get("cert") != ""
+	if value := query.Get("tls"); value != "" {
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls %q, expected true or false", value)
+		}
+		if !on && enabled {
+			return nil, errors.New("ca and cert need tls")
+		}
+		enabled = on
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if ca := query.Get("ca"); ca != "" {
+		pem, err := os.ReadFile(ca)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificates: %v", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates in %s", ca)
+		}
+	}
+	if cert, key := query.Get("cert"), query.Get("key"); cert != "" || key != "" {
+		if cert == "" || key == "" {
+			return nil, errors.New("a client certificate needs both cert and key")
+		}
+		certificate, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+// saslMechanism returns the Kafka SASL mechanism of the sasl query parameter, PLAIN by default
+func saslMechanism(name string, user string, password string) (sasl.Mechanism, error) {
+	switch strings.ToLower(name) {
+	case "", "plain":
+		return plain.Mechanism{Username: user, Password: password}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, user, password)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, user, password)
+	default:
+		return nil, fmt.Errorf("unsupported sasl %q, expected plain, scram-sha-256, or scram-sha-512", name)
+	}
+}
+
+// encode returns the message of a transaction
+func encode(transaction proxy.APITransaction) ([]byte, error) {
+	return json.Marshal(transaction)
+}
+
+// messageKey returns the key of a transaction's message, so that the transactions of an
+// endpoint stay in order
+func messageKey(transaction proxy.APITransaction) string {
+	return transaction.Request.Method + " " + transaction.Request.Path
+}
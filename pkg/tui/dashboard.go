@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// refreshInterval is how often the dashboard redraws the endpoint table
+const refreshInterval = 500 * time.Millisecond
+
+// Options configures the dashboard
+type Options struct {
+	Port      int
+	Target    string
+	DataDir   string
+	Stats     *Stats
+	Capturing *atomic.Bool // whether captured transactions are stored, toggled with c
+
+	// Generate writes the documentation from the captured transactions, triggered with g, and
+	// returns where it was written
+	Generate func() (string, error)
+
+	// ServerErrors delivers the error the proxy server stopped with, which closes the dashboard
+	ServerErrors <-chan error
+}
+
+// Run shows the dashboard until the user quits with q or Ctrl+C, or the proxy server fails
+func Run(options Options) error {
+	result, err := tea.NewProgram(newModel(options), tea.WithAltScreen()).Run()
+	if err != nil {
+		return err
+	}
+	return result.(model).err
+}
+
+// Messages handled by the dashboard
+type (
+	tickMsg      struct{}
+	generatedMsg struct {
+		output string
+		err    error
+	}
+	serverErrorMsg struct{ err error }
+)
+
+// model is the state of the dashboard
+type model struct {
+	options    Options
+	endpoints  []Endpoint
+	total      int
+	offset     int // first endpoint row shown
+	height     int
+	status     string
+	generating bool
+	err        error
+}
+
+func newModel(options Options) model {
+	return model{options: options, height: 24}
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(tick(), m.waitForServerError())
+}
+
+// tick schedules the next refresh of the endpoint table
+func tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+// waitForServerError waits for the proxy server to stop
+func (m model) waitForServerError() tea.Cmd {
+	if m.options.ServerErrors == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return serverErrorMsg{<-m.options.ServerErrors}
+	}
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tickMsg:
+		m.endpoints = m.options.Stats.Endpoints()
+		m.total = m.options.Stats.Total()
+		return m, tick()
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case generatedMsg:
+		m.generating = false
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Generation failed: %v", msg.err)
+		} else {
+			m.status = fmt.Sprintf("Documentation written to %s at %s", msg.output, time.Now().Format("15:04:05"))
+		}
+	case serverErrorMsg:
+		m.err = fmt.Errorf("proxy server error: %v", msg.err)
+		return m, tea.Quit
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "c":
+			capturing := !m.options.Capturing.Load()
+			m.options.Capturing.Store(capturing)
+			if capturing {
+				m.status = "Capture resumed"
+			} else {
+				m.status = "Capture paused, requests are still proxied but not stored"
+			}
+		case "g":
+			if m.generating || m.options.Generate == nil {
+				break
+			}
+			m.generating = true
+			m.status = "Generating documentation..."
+			generate := m.options.Generate
+			return m, func() tea.Msg {
+				output, err := generate()
+				return generatedMsg{output: output, err: err}
+			}
+		case "up", "k":
+			if m.offset > 0 {
+				m.offset--
+			}
+		case "down", "j":
+			if m.offset < len(m.endpoints)-1 {
+				m.offset++
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m model) View() string {
+	var view strings.Builder
+
+	state := "capturing"
+	if !m.options.Capturing.Load() {
+		state = "paused"
+	}
+	fmt.Fprintf(&view, " swagdoc  http://localhost:%d → %s  (%s)\n", m.options.Port, m.options.Target, state)
+	fmt.Fprintf(&view, " %d transactions, %d endpoints, saved to %s\n\n", m.total, len(m.endpoints), m.options.DataDir)
+
+	fmt.Fprintf(&view, " %-7s %-50s %6s %6s  %s\n", "METHOD", "PATH", "HITS", "STATUS", "AUTH")
+
+	// Leave room for the header, the status line, and the key help
+	rows := m.height - 8
+	if rows < 1 {
+		rows = 1
+	}
+	offset := m.offset
+	if offset > len(m.endpoints) {
+		offset = len(m.endpoints)
+	}
+	shown := m.endpoints[offset:]
+	if len(shown) > rows {
+		shown = shown[:rows]
+	}
+	for _, endpoint := range shown {
+		fmt.Fprintf(&view, " %-7s %-50s %6d %6d  %s\n", endpoint.Method, endpoint.Path, endpoint.Hits, endpoint.LastStatus, endpoint.Auth)
+	}
+	if len(m.endpoints) == 0 {
+		view.WriteString(" Waiting for API traffic...\n")
+	}
+
+	fmt.Fprintf(&view, "\n %s\n", m.status)
+	view.WriteString(" c pause/resume capture · g generate documentation · ↑/↓ scroll · q quit\n")
+	return view.String()
+}
@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboard(t *testing.T) {
+	stats := NewStats()
+	stats.Add(newTransaction("GET", "/orders", 200, nil))
+
+	var capturing atomic.Bool
+	capturing.Store(true)
+	generated := 0
+	var m tea.Model = newModel(Options{
+		Port:      8080,
+		Target:    "http://api.example.com",
+		DataDir:   "./swagdoc-data",
+		Stats:     stats,
+		Capturing: &capturing,
+		Generate: func() (string, error) {
+			generated++
+			return "swagger.json", nil
+		},
+	})
+
+	m, _ = m.Update(tickMsg{})
+	view := m.View()
+	assert.Contains(t, view, "http://localhost:8080 → http://api.example.com  (capturing)")
+	assert.Contains(t, view, "/orders")
+
+	// c pauses and resumes capturing
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	assert.False(t, capturing.Load())
+	assert.Contains(t, m.View(), "(paused)")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	assert.True(t, capturing.Load())
+
+	// g generates in the background and reports where the documentation went
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	require.NotNil(t, cmd)
+	m, _ = m.Update(cmd())
+	assert.Equal(t, 1, generated)
+	assert.Contains(t, m.View(), "Documentation written to swagger.json")
+
+	// A failing proxy server closes the dashboard with its error
+	m, cmd = m.Update(serverErrorMsg{errors.New("address already in use")})
+	require.NotNil(t, cmd)
+	assert.ErrorContains(t, m.(model).err, "address already in use")
+}
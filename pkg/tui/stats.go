@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/parnexcodes/swag-doc/pkg/parser"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+)
+
+// Endpoint is a templated endpoint shown on the dashboard
+type Endpoint struct {
+	Method     string
+	Path       string // templated, e.g. /users/{userId}
+	Hits       int
+	LastStatus int
+	Auth       string // detected auth scheme, e.g. "bearer" or "X-API-Key", or ""
+}
+
+// requestStats are the observations of one concrete method and path
+type requestStats struct {
+	method     string
+	path       string
+	hits       int
+	lastStatus int
+	lastSeen   int
+	auth       string
+}
+
+// Stats aggregates captured transactions per endpoint for the dashboard. It is safe for
+// concurrent use by the proxy handlers and the dashboard
+type Stats struct {
+	mutex    sync.Mutex
+	requests map[string]*requestStats
+	total    int
+}
+
+// NewStats creates empty capture statistics
+func NewStats() *Stats {
+	return &Stats{requests: make(map[string]*requestStats)}
+}
+
+// Add records a captured transaction
+func (s *Stats) Add(tx proxy.APITransaction) {
+	auth := authScheme(tx)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.total++
+	key := tx.Request.Method + " " + tx.Request.Path
+	request, ok := s.requests[key]
+	if !ok {
+		request = &requestStats{method: tx.Request.Method, path: tx.Request.Path}
+		s.requests[key] = request
+	}
+	request.hits++
+	request.lastStatus = tx.Response.StatusCode
+	request.lastSeen = s.total
+	if auth != "" {
+		request.auth = auth
+	}
+}
+
+// Total returns the number of captured transactions
+func (s *Stats) Total() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.total
+}
+
+// Endpoints returns the captured endpoints sorted by path and method, with concrete paths
+// templated the same way as in the generated documentation
+func (s *Stats) Endpoints() []Endpoint {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	detector := parser.NewPathPatternDetector()
+	for _, request := range s.requests {
+		detector.AddPath(request.path)
+	}
+	detector.AnalyzePatterns()
+
+	endpoints := make(map[string]*Endpoint)
+	lastSeen := make(map[string]int)
+	for _, request := range s.requests {
+		path := detector.TemplatizePath(request.path)
+		if path == "" {
+			path = request.path
+		}
+		key := request.method + " " + path
+		endpoint, ok := endpoints[key]
+		if !ok {
+			endpoint = &Endpoint{Method: request.method, Path: path}
+			endpoints[key] = endpoint
+		}
+		endpoint.Hits += request.hits
+		if request.lastSeen > lastSeen[key] {
+			lastSeen[key] = request.lastSeen
+			endpoint.LastStatus = request.lastStatus
+		}
+		if request.auth != "" {
+			endpoint.Auth = request.auth
+		}
+	}
+
+	result := make([]Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		result = append(result, *endpoint)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
+
+// authScheme returns the auth scheme of the credentials a captured request carried, if any
+func authScheme(tx proxy.APITransaction) string {
+	header := make(http.Header)
+	for k, v := range tx.Request.Headers {
+		if len(v) > 0 {
+			header.Set(k, v[0])
+		}
+	}
+
+	detector := parser.NewAuthDetector()
+	detector.AnalyzeTransaction(&http.Request{Header: header, URL: &url.URL{Path: tx.Request.Path, RawQuery: tx.Request.QueryParams.Encode()}}, nil)
+	for _, scheme := range detector.GetAuthSchemes() {
+		if scheme.Type == "http" {
+			return scheme.Scheme
+		}
+		return scheme.Name
+	}
+	return ""
+}
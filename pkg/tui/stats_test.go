@@ -0,0 +1,35 @@
+package tui
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTransaction(method, path string, statusCode int, headers http.Header) proxy.APITransaction {
+	if headers == nil {
+		headers = http.Header{}
+	}
+	return proxy.APITransaction{
+		Request:  proxy.RequestData{Method: method, Path: path, Headers: headers},
+		Response: proxy.ResponseData{StatusCode: statusCode},
+	}
+}
+
+func TestStatsEndpoints(t *testing.T) {
+	stats := NewStats()
+	stats.Add(newTransaction("GET", "/users", 200, nil))
+	stats.Add(newTransaction("GET", "/users/123", 200, http.Header{"Authorization": {"Bearer __token__"}}))
+	stats.Add(newTransaction("GET", "/users/456", 404, nil))
+	stats.Add(newTransaction("DELETE", "/users/123", 204, http.Header{"X-Api-Key": {"__token__"}}))
+
+	assert.Equal(t, 4, stats.Total())
+	assert.Equal(t, []Endpoint{
+		{Method: "GET", Path: "/users", Hits: 1, LastStatus: 200},
+		{Method: "DELETE", Path: "/users/{userId}", Hits: 1, LastStatus: 204, Auth: "X-API-Key"},
+		{Method: "GET", Path: "/users/{userId}", Hits: 2, LastStatus: 404, Auth: "bearer"},
+	}, stats.Endpoints())
+}
@@ -0,0 +1,228 @@
+package update
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// LatestReleaseURL is the GitHub API endpoint of the latest swagdoc release
+const LatestReleaseURL = "https://api.github.com/repos/parnexcodes/swag-doc/releases/latest"
+
+// ChecksumsAsset is the release asset listing the SHA-256 checksums of the binaries, in the
+// format of sha256sum
+const ChecksumsAsset = "checksums.txt"
+
+// Release is a published release of swagdoc
+type Release struct {
+	TagName string  `json:"tag_name"` // e.g. v1.2.0
+	HTMLURL string  `json:"html_url"` // release page with the notes
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release, e.g. the binary of a platform
+type Asset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+}
+
+// Version returns the version of a release without the leading v
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Asset returns the asset with a name
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// LatestRelease fetches the latest release from the GitHub releases API. A GITHUB_TOKEN in the
+// environment is sent along to lift the rate limit of anonymous requests.
+func LatestRelease(ctx context.Context, client *http.Client, url string) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub returned %s", resp.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("invalid release: %v", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("invalid release: no tag name")
+	}
+	return &release, nil
+}
+
+// IsNewer reports whether version is newer than current, comparing dot-separated numbers, e.g.
+// 1.10.0 is newer than 1.9.2. A leading v and pre-release or build suffixes are ignored.
+func IsNewer(version, current string) bool {
+	a, b := versionNumbers(version), versionNumbers(current)
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+// versionNumbers parses the numbers of a version such as v1.2.3-rc.1
+func versionNumbers(version string) []int {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	var numbers []int
+	for _, part := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+// AssetName returns the name of the release asset built for a platform, matching the release
+// workflow: swagdoc for Linux, swagdoc.exe for Windows, and swagdoc-mac for macOS on Apple
+// silicon. Other platforms have no prebuilt binary.
+func AssetName(goos, goarch string) (string, bool) {
+	switch {
+	case goos == "linux" && goarch == "amd64":
+		return "swagdoc", true
+	case goos == "windows" && goarch == "amd64":
+		return "swagdoc.exe", true
+	case goos == "darwin" && goarch == "arm64":
+		return "swagdoc-mac", true
+	default:
+		return "", false
+	}
+}
+
+// Checksum returns the SHA-256 checksum of a release asset, as listed in the checksums asset of
+// the release. Releases without checksums, or without one for the asset, can't be verified and
+// fail.
+func Checksum(ctx context.Context, client *http.Client, release *Release, name string) (string, error) {
+	checksums, ok := release.Asset(ChecksumsAsset)
+	if !ok {
+		return "", fmt.Errorf("release %s has no %s to verify the download with", release.TagName, ChecksumsAsset)
+	}
+	resp, err := download(ctx, client, checksums.DownloadURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// Lines are "<hex>  <name>", with a * before the name for files hashed in binary mode
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("download failed: %v", err)
+	}
+	return "", fmt.Errorf("%s of release %s has no checksum for %s", ChecksumsAsset, release.TagName, name)
+}
+
+// Install downloads a binary and replaces the executable at path with it, once the SHA-256
+// checksum of the download matches checksum, in hex. The download goes to a temporary file next
+// to the executable first, so a failed, truncated, or tampered download leaves it untouched.
+func Install(ctx context.Context, client *http.Client, url string, path string, checksum string) error {
+	if checksum == "" {
+		return fmt.Errorf("no checksum to verify the download with")
+	}
+	resp, err := download(ctx, client, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".new-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("download failed: %v", err)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != strings.ToLower(checksum) {
+		tmp.Close()
+		return fmt.Errorf("checksum mismatch: the download has SHA-256 %s, the release lists %s", sum, checksum)
+	}
+	if err := tmp.Chmod(0755); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// Windows can't replace a running executable, but it can rename it out of the way
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// download starts a GET request, failing unless it succeeds
+func download(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download failed: %s", resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,154 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		version string
+		current string
+		newer   bool
+	}{
+		{"v1.1.0", "1.0.0", true},
+		{"1.10.0", "1.9.2", true},
+		{"v1.0.0", "1.0.0", false},
+		{"v0.9.0", "1.0.0", false},
+		{"v1.0.1-rc.1", "1.0.0", true},
+		{"v1.0", "1.0.0", false},
+		{"v2", "1.9.9", true},
+	}
+
+	for _, tt := range tests {
+		if got := IsNewer(tt.version, tt.current); got != tt.newer {
+			t.Errorf("IsNewer(%q, %q) = %v, expected %v", tt.version, tt.current, got, tt.newer)
+		}
+	}
+}
+
+func TestAssetName(t *testing.T) {
+	if name, ok := AssetName("linux", "amd64"); !ok || name != "swagdoc" {
+		t.Errorf("Expected swagdoc for linux/amd64, got %q", name)
+	}
+	if name, ok := AssetName("windows", "amd64"); !ok || name != "swagdoc.exe" {
+		t.Errorf("Expected swagdoc.exe for windows/amd64, got %q", name)
+	}
+	if name, ok := AssetName("darwin", "arm64"); !ok || name != "swagdoc-mac" {
+		t.Errorf("Expected swagdoc-mac for darwin/arm64, got %q", name)
+	}
+	if _, ok := AssetName("linux", "arm64"); ok {
+		t.Error("Expected no asset for linux/arm64")
+	}
+}
+
+func TestLatestRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"tag_name":"v1.2.0","html_url":"https://example.com/v1.2.0","assets":[{"name":"swagdoc","browser_download_url":"https://example.com/swagdoc"}]}`))
+	}))
+	defer server.Close()
+
+	release, err := LatestRelease(context.Background(), server.Client(), server.URL+"/latest")
+	if err != nil {
+		t.Fatalf("Failed to fetch release: %v", err)
+	}
+	if release.Version() != "1.2.0" {
+		t.Errorf("Expected version 1.2.0, got %s", release.Version())
+	}
+	if asset, ok := release.Asset("swagdoc"); !ok || asset.DownloadURL != "https://example.com/swagdoc" {
+		t.Errorf("Expected the swagdoc asset, got %+v", asset)
+	}
+	if _, ok := release.Asset("swagdoc.exe"); ok {
+		t.Error("Expected no swagdoc.exe asset")
+	}
+
+	if _, err := LatestRelease(context.Background(), server.Client(), server.URL+"/missing"); err == nil {
+		t.Error("Expected an error for a missing release")
+	}
+}
+
+func TestInstall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			http.Error(w, "gone", http.StatusGone)
+			return
+		}
+		w.Write([]byte("new binary"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "swagdoc")
+	if err := os.WriteFile(path, []byte("old binary"), 0755); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte("new binary"))
+	checksum := hex.EncodeToString(sum[:])
+	truncated := sha256.Sum256([]byte("new"))
+
+	// A failed, unverified, or tampered download leaves the executable untouched
+	for _, test := range []struct {
+		url      string
+		checksum string
+	}{
+		{server.URL + "/broken", checksum},
+		{server.URL + "/swagdoc", ""},
+		{server.URL + "/swagdoc", hex.EncodeToString(truncated[:])},
+	} {
+		if err := Install(context.Background(), server.Client(), test.url, path, test.checksum); err == nil {
+			t.Errorf("Expected an error for %s with checksum %q", test.url, test.checksum)
+		}
+		if data, _ := os.ReadFile(path); string(data) != "old binary" {
+			t.Errorf("Expected the old binary to be kept, got %q", data)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("Expected no temporary files to be left, got %d entries", len(entries))
+		}
+	}
+
+	if err := Install(context.Background(), server.Client(), server.URL+"/swagdoc", path, strings.ToUpper(checksum)); err != nil {
+		t.Fatalf("Failed to install: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new binary" {
+		t.Errorf("Expected the new binary, got %q", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected an executable file, got %v, %v", info, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left, got %d entries", len(entries))
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0123abcd  swagdoc\nABCDEF01 *swagdoc.exe\n"))
+	}))
+	defer server.Close()
+
+	release := &Release{TagName: "v1.2.0", Assets: []Asset{{Name: ChecksumsAsset, DownloadURL: server.URL}}}
+	for name, expected := range map[string]string{"swagdoc": "0123abcd", "swagdoc.exe": "abcdef01"} {
+		checksum, err := Checksum(context.Background(), server.Client(), release, name)
+		if err != nil || checksum != expected {
+			t.Errorf("Expected checksum %s for %s, got %q, %v", expected, name, checksum, err)
+		}
+	}
+	if _, err := Checksum(context.Background(), server.Client(), release, "swagdoc-mac"); err == nil {
+		t.Error("Expected an error for an asset without a checksum")
+	}
+	if _, err := Checksum(context.Background(), server.Client(), &Release{TagName: "v1.2.0"}, "swagdoc"); err == nil {
+		t.Error("Expected an error for a release without checksums")
+	}
+}
@@ -0,0 +1,297 @@
+// Package swagdoc embeds swag-doc in other Go programs: a Recorder captures API traffic, from a
+// proxy in front of a server or from middleware inside it, and generates OpenAPI documentation
+// from it without shelling out to the swagdoc CLI.
+//
+//	recorder, err := swagdoc.NewRecorder(swagdoc.WithRedactHeaders("X-Tenant-Token"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer recorder.Close()
+//
+//	http.ListenAndServe(":8080", recorder.Middleware(mux))
+//	...
+//	spec, err := recorder.GenerateSpec(swagdoc.WithTitle("Orders API"))
+package swagdoc
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/parnexcodes/swag-doc/pkg/openapi"
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Recorder captures API transactions, sanitized so that no real values are kept, stores them,
+// and generates OpenAPI documentation from them. It is safe for concurrent use.
+type Recorder struct {
+	storage       proxy.Storage
+	redactHeaders []string
+	filter        func(*http.Request) bool
+	onError       func(error)
+}
+
+// RecorderOption configures a Recorder
+type RecorderOption func(*Recorder) error
+
+// WithStorage stores the captured transactions in storage, e.g. one shared with a swagdoc proxy.
+// By default they are kept in memory.
+func WithStorage(storage proxy.Storage) RecorderOption {
+	return func(r *Recorder) error {
+		r.storage = storage
+		return nil
+	}
+}
+
+// WithDataDir stores the captured transactions in session files of a data directory, like the
+// swagdoc proxy does, so that the swagdoc CLI can generate documentation from them too. They are
+// written in the background, and Close writes the ones still queued.
+func WithDataDir(dir string) RecorderOption {
+	return func(r *Recorder) error {
+		storage, err := proxy.NewFileStorage(dir)
+		if err != nil {
+			return err
+		}
+		r.storage = proxy.NewAsyncStorage(storage, proxy.DefaultQueueSize, func(err error) {
+			r.reportError(err)
+		})
+		return nil
+	}
+}
+
+// WithRedactHeaders redacts the values of more request and response headers, e.g. a custom
+// X-Tenant-Token, in addition to the built-in credential headers
+func WithRedactHeaders(names ...string) RecorderOption {
+	return func(r *Recorder) error {
+		r.redactHeaders = append(r.redactHeaders, names...)
+		return nil
+	}
+}
+
+// WithFilter only captures the requests filter returns true for, e.g. to leave out health checks
+func WithFilter(filter func(*http.Request) bool) RecorderOption {
+	return func(r *Recorder) error {
+		r.filter = filter
+		return nil
+	}
+}
+
+// WithErrorHandler is called with the errors of storing captured transactions, which happen
+// while serving requests and can't be returned. By default they are ignored.
+func WithErrorHandler(onError func(error)) RecorderOption {
+	return func(r *Recorder) error {
+		r.onError = onError
+		return nil
+	}
+}
+
+// NewRecorder creates a recorder
+func NewRecorder(options ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{}
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+	if r.storage == nil {
+		r.storage = &memoryStorage{}
+	}
+	return r, nil
+}
+
+// Record stores a transaction captured elsewhere, e.g. by a proxy server of your own. The filter
+// doesn't apply to it.
+func (r *Recorder) Record(transaction proxy.APITransaction) error {
+	return r.storage.Store(transaction)
+}
+
+// Middleware wraps an HTTP handler so that the requests it serves, and its responses, are
+// recorded
+func (r *Recorder) Middleware(next http.Handler) http.Handler {
+	capture := proxy.CaptureMiddleware(next, r.redactHeaders, r.store)
+	if r.filter == nil {
+		return capture
+	}
+	// Filtered requests aren't even captured
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !r.filter(req) {
+			next.ServeHTTP(w, req)
+			return
+		}
+		capture.ServeHTTP(w, req)
+	})
+}
+
+// Proxy creates a proxy server on port in front of target whose traffic is recorded. Start it
+// with Start, and stop it by canceling the context of Start or with Shutdown. Port 0 binds to a
+// free port, which Addr reports.
+func (r *Recorder) Proxy(port int, target string) (*proxy.ProxyServer, error) {
+	server, err := proxy.NewProxyServer(port, target, func(transaction proxy.APITransaction) {
+		if r.filter == nil || r.filter(transactionRequest(transaction)) {
+			r.store(transaction)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	server.SetRedactHeaders(r.redactHeaders)
+	return server, nil
+}
+
+// store records a transaction captured by the middleware or the proxy
+func (r *Recorder) store(transaction proxy.APITransaction) {
+	if err := r.Record(transaction); err != nil {
+		r.reportError(err)
+	}
+}
+
+// reportError passes an error of storing a transaction to the error handler
+func (r *Recorder) reportError(err error) {
+	if r.onError != nil {
+		r.onError(err)
+	}
+}
+
+// GenerateSpec generates an OpenAPI document from the transactions recorded so far. Recording
+// continues meanwhile.
+func (r *Recorder) GenerateSpec(options ...GenerateOption) (*openapi3.T, error) {
+	settings := generateSettings{
+		config: openapi.OpenAPIConfig{
+			Title:         "API Documentation",
+			Description:   "Generated API documentation",
+			Version:       "1.0.0",
+			UsePathGroups: true,
+			CodeSamples:   true,
+		},
+	}
+	for _, option := range options {
+		option(&settings)
+	}
+
+	generator := openapi.NewOpenAPIGenerator(settings.config)
+	for _, hook := range settings.hooks {
+		hook(generator)
+	}
+	if err := generator.AddTransactionsFrom(r.storage); err != nil {
+		return nil, err
+	}
+	return generator.GenerateSpec()
+}
+
+// Close writes the transactions still queued and releases the storage, if it needs closing
+func (r *Recorder) Close() error {
+	if closer, ok := r.storage.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// GenerateOption configures the document GenerateSpec generates
+type GenerateOption func(*generateSettings)
+
+// generateSettings holds the generator configuration and the hooks to register
+type generateSettings struct {
+	config openapi.OpenAPIConfig
+	hooks  []func(*openapi.OpenAPIGenerator)
+}
+
+// WithTitle sets the title of the document (default: API Documentation)
+func WithTitle(title string) GenerateOption {
+	return func(settings *generateSettings) {
+		settings.config.Title = title
+	}
+}
+
+// WithDescription sets the description of the document
+func WithDescription(description string) GenerateOption {
+	return func(settings *generateSettings) {
+		settings.config.Description = description
+	}
+}
+
+// WithVersion sets the API version of the document (default: 1.0.0)
+func WithVersion(version string) GenerateOption {
+	return func(settings *generateSettings) {
+		settings.config.Version = version
+	}
+}
+
+// WithServer adds a server URL to the document
+func WithServer(url, description string) GenerateOption {
+	return func(settings *generateSettings) {
+		settings.config.Servers = append(settings.config.Servers, openapi.OpenAPIServer{URL: url, Description: description})
+	}
+}
+
+// WithConfig changes any other setting of the generator, e.g. the path templates or the
+// response strategy, the way the swagdoc generate flags do
+func WithConfig(configure func(*openapi.OpenAPIConfig)) GenerateOption {
+	return func(settings *generateSettings) {
+		configure(&settings.config)
+	}
+}
+
+// WithOperationHook calls hook with every generated operation, e.g. to enforce organization
+// conventions on operationIds or extensions
+func WithOperationHook(hook openapi.OperationHook) GenerateOption {
+	return func(settings *generateSettings) {
+		settings.hooks = append(settings.hooks, func(g *openapi.OpenAPIGenerator) { g.OnOperation(hook) })
+	}
+}
+
+// WithSchemaHook calls hook with every generated schema
+func WithSchemaHook(hook openapi.SchemaHook) GenerateOption {
+	return func(settings *generateSettings) {
+		settings.hooks = append(settings.hooks, func(g *openapi.OpenAPIGenerator) { g.OnSchema(hook) })
+	}
+}
+
+// WithSpecHook calls hook with the complete document before GenerateSpec returns it
+func WithSpecHook(hook openapi.SpecHook) GenerateOption {
+	return func(settings *generateSettings) {
+		settings.hooks = append(settings.hooks, func(g *openapi.OpenAPIGenerator) { g.OnSpecComplete(hook) })
+	}
+}
+
+// transactionRequest rebuilds the parts of an HTTP request a filter looks at from a transaction
+// captured by the proxy
+func transactionRequest(transaction proxy.APITransaction) *http.Request {
+	req, err := http.NewRequest(transaction.Request.Method, transaction.Request.Path, nil)
+	if err != nil {
+		return &http.Request{Method: transaction.Request.Method, Header: transaction.Request.Headers}
+	}
+	req.Header = transaction.Request.Headers
+	req.URL.RawQuery = transaction.Request.QueryParams.Encode()
+	return req
+}
+
+// memoryStorage keeps the recorded transactions in memory
+type memoryStorage struct {
+	mutex        sync.Mutex
+	transactions []proxy.APITransaction
+}
+
+// Store keeps a transaction
+func (m *memoryStorage) Store(transaction proxy.APITransaction) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.transactions = append(m.transactions, transaction)
+	return nil
+}
+
+// GetAll returns the kept transactions
+func (m *memoryStorage) GetAll() ([]proxy.APITransaction, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]proxy.APITransaction(nil), m.transactions...), nil
+}
+
+// Clear removes the kept transactions
+func (m *memoryStorage) Clear() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.transactions = nil
+	return nil
+}
@@ -0,0 +1,162 @@
+package swagdoc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parnexcodes/swag-doc/pkg/proxy"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPI() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"name":"Ada","email":"ada@example.com"}`))
+	})
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	return mux
+}
+
+func get(t *testing.T, url string, headers map[string]string) {
+	req, err := http.NewRequest("GET", url, nil)
+	require.NoError(t, err)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestRecorderMiddleware(t *testing.T) {
+	recorder, err := NewRecorder(WithRedactHeaders("X-Tenant-Token"))
+	require.NoError(t, err)
+	defer recorder.Close()
+
+	server := httptest.NewServer(recorder.Middleware(newTestAPI()))
+	defer server.Close()
+
+	get(t, server.URL+"/users/1", map[string]string{"X-Tenant-Token": "secret"})
+	get(t, server.URL+"/users/2", nil)
+
+	transactions, err := recorder.storage.GetAll()
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.NotEqual(t, "secret", transactions[0].Request.Headers.Get("X-Tenant-Token"))
+	assert.NotContains(t, string(transactions[0].Response.Body), "Ada")
+
+	spec, err := recorder.GenerateSpec(
+		WithTitle("Users API"),
+		WithVersion("2.0.0"),
+		WithServer("https://api.example.com", "Production"),
+		WithOperationHook(func(method, path string, op *openapi3.Operation) error {
+			op.Extensions["x-internal"] = false
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "Users API", spec.Info.Title)
+	assert.Equal(t, "2.0.0", spec.Info.Version)
+	require.Len(t, spec.Servers, 1)
+	assert.Equal(t, "https://api.example.com", spec.Servers[0].URL)
+
+	item := spec.Paths.Find("/users/{id}")
+	require.NotNil(t, item)
+	require.NotNil(t, item.Get)
+	assert.Equal(t, false, item.Get.Extensions["x-internal"])
+}
+
+func TestRecorderFilter(t *testing.T) {
+	recorder, err := NewRecorder(WithFilter(func(r *http.Request) bool {
+		return !strings.HasPrefix(r.URL.Path, "/health")
+	}))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(recorder.Middleware(newTestAPI()))
+	defer server.Close()
+
+	get(t, server.URL+"/health", nil)
+	get(t, server.URL+"/users/1", nil)
+
+	transactions, err := recorder.storage.GetAll()
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "/users/1", transactions[0].Request.Path)
+}
+
+func TestRecorderProxy(t *testing.T) {
+	api := httptest.NewServer(newTestAPI())
+	defer api.Close()
+
+	recorder, err := NewRecorder(WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/health"
+	}))
+	require.NoError(t, err)
+
+	server, err := recorder.Proxy(0, api.URL)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Start(ctx)
+
+	addr := server.Addr()
+	require.NotNil(t, addr)
+	proxyURL := fmt.Sprintf("http://127.0.0.1:%d", addr.(*net.TCPAddr).Port)
+	get(t, proxyURL+"/health", nil)
+	get(t, proxyURL+"/users/1", nil)
+
+	transactions, err := recorder.storage.GetAll()
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "/users/1", transactions[0].Request.Path)
+}
+
+func TestRecorderDataDir(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewRecorder(WithDataDir(dir))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(recorder.Middleware(newTestAPI()))
+	get(t, server.URL+"/users/1", nil)
+	server.Close()
+	require.NoError(t, recorder.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// The CLI reads the same session files
+	storage, err := proxy.NewFileStorage(dir)
+	require.NoError(t, err)
+	transactions, err := storage.GetAll()
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "/users/1", transactions[0].Request.Path)
+}
+
+func TestRecorderRecordErrors(t *testing.T) {
+	var errs []error
+	recorder, err := NewRecorder(WithDataDir(t.TempDir()), WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	require.NoError(t, err)
+	require.NoError(t, recorder.Close())
+
+	handler := recorder.Middleware(newTestAPI())
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], proxy.ErrStorageClosed)
+}